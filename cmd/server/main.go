@@ -19,6 +19,7 @@ import (
 	"github.com/joho/godotenv"
 	configaccess "github.com/router-for-me/CLIProxyAPI/v7/internal/access/config_access"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/api"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/authcrypt"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/buildinfo"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/cmd"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
@@ -63,7 +64,7 @@ func shouldEnableExampleAPIKeySafeMode(cfg *config.Config, commandMode, tuiMode,
 	if tuiMode && !standalone {
 		return false
 	}
-	return safemode.HasExampleAPIKeys(cfg.APIKeys)
+	return safemode.HasExampleAPIKeys(config.ClientAPIKeyValues(cfg.APIKeys))
 }
 
 // main is the entry point of the application.
@@ -90,6 +91,12 @@ func main() {
 	var tuiMode bool
 	var standalone bool
 	var localModel bool
+	var encryptAuthDir bool
+	var decryptAuthDir bool
+	var validateConfig bool
+	var explainRoute string
+	var explainProtocol string
+	var allowSecretCommands bool
 
 	// Define command-line flags for different operation modes.
 	flag.BoolVar(&codexLogin, "codex-login", false, "Login to Codex using OAuth")
@@ -109,6 +116,12 @@ func main() {
 	flag.BoolVar(&tuiMode, "tui", false, "Start with terminal management UI")
 	flag.BoolVar(&standalone, "standalone", false, "In TUI mode, start an embedded local server")
 	flag.BoolVar(&localModel, "local-model", false, "Use embedded models.json and codex_client_models.json only, skip remote model catalog fetching")
+	flag.BoolVar(&encryptAuthDir, "encrypt-auth-dir", false, "Encrypt every auth file in the auth directory with AUTH_ENCRYPTION_KEYS and exit")
+	flag.BoolVar(&decryptAuthDir, "decrypt-auth-dir", false, "Decrypt every auth file in the auth directory with AUTH_ENCRYPTION_KEYS and exit")
+	flag.BoolVar(&validateConfig, "validate-config", false, "Validate the config file and auth directory offline, print the resulting model catalog and exit non-zero on errors")
+	flag.StringVar(&explainRoute, "explain-route", "", "Validate the config and explain which credentials and payload rules would serve the given model")
+	flag.StringVar(&explainProtocol, "explain-protocol", cmd.DefaultExplainProtocol, "Source protocol of the request explained by -explain-route (openai, responses, claude, gemini, interactions, ollama)")
+	flag.BoolVar(&allowSecretCommands, "allow-secret-commands", false, "Resolve ${cmd:...} secret references in the local config file by running the command")
 
 	flag.CommandLine.Usage = func() {
 		out := flag.CommandLine.Output()
@@ -145,6 +158,7 @@ func main() {
 
 	// Parse the command-line flags.
 	flag.Parse()
+	config.SetCommandSecretsEnabled(allowSecretCommands)
 
 	// Core application variables.
 	var err error
@@ -202,6 +216,42 @@ func main() {
 	}
 	writableBase := util.WritablePath()
 
+	// Auth files are encrypted at rest when AUTH_ENCRYPTION_KEYS (or a file named by
+	// AUTH_ENCRYPTION_KEYS_FILE) lists one or more keys; the first key seals new files.
+	authEncryptionKeys, _ := lookupEnv("AUTH_ENCRYPTION_KEYS", "auth_encryption_keys")
+	if authEncryptionKeys == "" {
+		if keysFile, ok := lookupEnv("AUTH_ENCRYPTION_KEYS_FILE", "auth_encryption_keys_file"); ok {
+			data, errReadKeys := os.ReadFile(keysFile)
+			if errReadKeys != nil {
+				log.Errorf("failed to read auth encryption keys file: %v", errReadKeys)
+				return
+			}
+			authEncryptionKeys = string(data)
+		}
+	}
+	if authEncryptionKeys != "" {
+		keyring, errKeyring := authcrypt.ParseKeyring(authEncryptionKeys)
+		if errKeyring != nil {
+			log.Errorf("invalid auth encryption keys: %v", errKeyring)
+			return
+		}
+		authcrypt.SetKeyring(keyring)
+	}
+
+	// Offline validation reads only the local config file and auth directory; it never
+	// touches Home, the remote token stores or any upstream.
+	if validateConfig || strings.TrimSpace(explainRoute) != "" {
+		validatePath := configPath
+		if validatePath == "" {
+			validatePath = filepath.Join(wd, "config.yaml")
+		}
+		os.Exit(cmd.DoValidateConfig(cmd.ValidateConfigOptions{
+			ConfigPath:     validatePath,
+			Model:          explainRoute,
+			SourceProtocol: explainProtocol,
+		}))
+	}
+
 	if strings.TrimSpace(homeJWT) == "" {
 		if v, ok := lookupEnv("HOME_JWT", "home_jwt"); ok {
 			homeJWT = v
@@ -588,13 +638,13 @@ func main() {
 		CallbackPort: oauthCallbackPort,
 	}
 
-	commandMode := vertexImport != "" || antigravityLogin || codexLogin || codexDeviceLogin || claudeLogin || kimiLogin || xaiLogin
+	commandMode := vertexImport != "" || encryptAuthDir || decryptAuthDir || antigravityLogin || codexLogin || codexDeviceLogin || claudeLogin || kimiLogin || xaiLogin
 	cloudConfigMissing := isCloudDeploy && !configFileExists
 	homeMode := configLoadedFromHome || (cfg != nil && cfg.Home.Enabled)
 	exampleAPIKeySafeMode := shouldEnableExampleAPIKeySafeMode(cfg, commandMode, tuiMode, standalone, cloudConfigMissing, homeMode)
 	serverOptions := []api.ServerOption(nil)
 	if exampleAPIKeySafeMode {
-		matches := safemode.ExampleAPIKeys(cfg.APIKeys)
+		matches := safemode.ExampleAPIKeys(config.ClientAPIKeyValues(cfg.APIKeys))
 		log.WithField("api_keys", strings.Join(matches, ",")).Error("unsafe example API key configured; proxy API endpoints disabled until api-keys is updated")
 		serverOptions = append(serverOptions, api.WithExampleAPIKeySafeMode())
 	}
@@ -646,6 +696,9 @@ func main() {
 	if vertexImport != "" {
 		// Handle Vertex service account import
 		cmd.DoVertexImport(cfg, vertexImport, vertexImportPrefix)
+	} else if encryptAuthDir || decryptAuthDir {
+		// Handle the one-shot auth file encryption migration
+		cmd.DoAuthFileEncryption(cfg, decryptAuthDir)
 	} else if antigravityLogin {
 		// Handle Antigravity login
 		cmd.DoAntigravityLogin(cfg, options)
@@ -672,6 +725,12 @@ func main() {
 		if localModel && (!tuiMode || standalone) {
 			log.Info("Local model mode: using embedded model catalogs, remote model updates disabled")
 		}
+		// Publish the catalog sources before the updater's startup refresh.
+		catalogSettings, errCatalog := cfg.ModelCatalog.Settings()
+		if errCatalog != nil {
+			log.Errorf("model-catalog: %v; catalog refreshes are blocked until the block is fixed", errCatalog)
+		}
+		registry.ConfigureModelCatalog(catalogSettings)
 		if tuiMode {
 			if standalone {
 				// Standalone mode: start an embedded local server and connect TUI client to it.
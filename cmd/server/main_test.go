@@ -9,12 +9,12 @@ import (
 func TestShouldEnableExampleAPIKeySafeMode(t *testing.T) {
 	cfgWithExampleKey := &config.Config{
 		SDKConfig: config.SDKConfig{
-			APIKeys: []string{"real-key", " your-api-key-1 "},
+			APIKeys: config.ClientAPIKeysFromStrings([]string{"real-key", " your-api-key-1 "}),
 		},
 	}
 	cfgWithRealKey := &config.Config{
 		SDKConfig: config.SDKConfig{
-			APIKeys: []string{"real-key"},
+			APIKeys: config.ClientAPIKeysFromStrings([]string{"real-key"}),
 		},
 	}
 
@@ -0,0 +1,94 @@
+// Package keypolicy enforces the scoping of structured api-keys entries.
+//
+// The access middleware refuses disabled and expired keys right after a client is
+// authenticated. Request handlers consult the same registry for the model
+// allow-list, provider allow-list and forced prefix of the calling key.
+package keypolicy
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+var (
+	// ErrKeyDisabled is returned by Admit for keys marked disabled.
+	ErrKeyDisabled = errors.New("API key is disabled")
+	// ErrKeyExpired is returned by Admit for keys past their expires-at.
+	ErrKeyExpired = errors.New("API key has expired")
+)
+
+// Registry maps client keys to their policy. Plain keys are not stored and
+// therefore have no restrictions.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]config.ClientAPIKey
+}
+
+// NewRegistry constructs an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]config.ClientAPIKey)}
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default returns the process-wide registry used by the access middleware and handlers.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// SetKeys replaces the registered policies. The first entry for a key wins.
+func (r *Registry) SetKeys(keys []config.ClientAPIKey) {
+	if r == nil {
+		return
+	}
+	entries := make(map[string]config.ClientAPIKey, len(keys))
+	for _, entry := range keys {
+		entry = config.NormalizeClientAPIKey(entry)
+		if entry.Key == "" {
+			continue
+		}
+		// Plain entries keep their slot too, so a later duplicate cannot attach restrictions.
+		if _, exists := entries[entry.Key]; exists {
+			continue
+		}
+		entries[entry.Key] = entry
+	}
+	r.mu.Lock()
+	r.entries = entries
+	r.mu.Unlock()
+}
+
+// Lookup returns the policy for apiKey. The boolean is false for unknown keys and
+// for keys without any restriction.
+func (r *Registry) Lookup(apiKey string) (config.ClientAPIKey, bool) {
+	apiKey = strings.TrimSpace(apiKey)
+	if r == nil || apiKey == "" {
+		return config.ClientAPIKey{}, false
+	}
+	r.mu.RLock()
+	entry, ok := r.entries[apiKey]
+	r.mu.RUnlock()
+	if !ok || entry.IsPlain() {
+		return config.ClientAPIKey{}, false
+	}
+	return entry, true
+}
+
+// Admit reports whether apiKey may be used at now.
+func (r *Registry) Admit(apiKey string, now time.Time) error {
+	entry, ok := r.Lookup(apiKey)
+	if !ok {
+		return nil
+	}
+	if entry.Disabled {
+		return ErrKeyDisabled
+	}
+	if entry.Expired(now) {
+		return ErrKeyExpired
+	}
+	return nil
+}
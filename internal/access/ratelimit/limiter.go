@@ -0,0 +1,350 @@
+// Package ratelimit enforces per-client API key request limits and token budgets.
+//
+// Request-rate and concurrency limits are checked when a request is admitted by
+// the access middleware. Token budgets are charged from completed usage records,
+// so the request that crosses a budget finishes and the next one is refused.
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+// Reason identifies the limit that refused a request.
+type Reason string
+
+const (
+	ReasonRequestsPerMinute  Reason = "requests_per_minute"
+	ReasonConcurrentRequests Reason = "concurrent_requests"
+	ReasonDailyTokenBudget   Reason = "daily_token_budget"
+	ReasonMonthlyTokenBudget Reason = "monthly_token_budget"
+)
+
+const (
+	requestWindow          = time.Minute
+	concurrencyRetryAfter  = time.Second
+	dayWindowLayout        = "2006-01-02"
+	monthWindowLayout      = "2006-01"
+	minimumRetryAfterValue = time.Second
+
+	// pruneInterval limits how often idle key states are dropped.
+	pruneInterval = time.Minute
+)
+
+// Rejection describes a refused request.
+type Rejection struct {
+	Reason     Reason
+	Limit      int64
+	RetryAfter time.Duration
+}
+
+// Message returns a client-facing description of the rejection.
+func (r *Rejection) Message() string {
+	if r == nil {
+		return ""
+	}
+	switch r.Reason {
+	case ReasonRequestsPerMinute:
+		return fmt.Sprintf("Rate limit reached for this API key: %d requests per minute", r.Limit)
+	case ReasonConcurrentRequests:
+		return fmt.Sprintf("Rate limit reached for this API key: %d concurrent requests", r.Limit)
+	case ReasonDailyTokenBudget:
+		return fmt.Sprintf("Daily token budget exhausted for this API key: %d tokens", r.Limit)
+	case ReasonMonthlyTokenBudget:
+		return fmt.Sprintf("Monthly token budget exhausted for this API key: %d tokens", r.Limit)
+	default:
+		return "Rate limit reached for this API key"
+	}
+}
+
+// IsBudget reports whether the rejection comes from a token budget rather than a rate limit.
+func (r *Rejection) IsBudget() bool {
+	return r != nil && (r.Reason == ReasonDailyTokenBudget || r.Reason == ReasonMonthlyTokenBudget)
+}
+
+// RetryAfterSeconds returns RetryAfter rounded up to whole seconds, at least one.
+func (r *Rejection) RetryAfterSeconds() int64 {
+	if r == nil || r.RetryAfter < minimumRetryAfterValue {
+		return 1
+	}
+	seconds := int64(r.RetryAfter / time.Second)
+	if r.RetryAfter%time.Second != 0 {
+		seconds++
+	}
+	return seconds
+}
+
+// Usage is a point-in-time view of one client key's counters.
+type Usage struct {
+	APIKey             string `json:"api-key"`
+	RequestsLastMinute int    `json:"requests-last-minute"`
+	InFlight           int    `json:"in-flight"`
+	DailyTokens        int64  `json:"daily-tokens"`
+	MonthlyTokens      int64  `json:"monthly-tokens"`
+}
+
+type keyState struct {
+	requests    []time.Time
+	inFlight    int
+	day         string
+	dayTokens   int64
+	month       string
+	monthTokens int64
+	updatedAt   time.Time
+}
+
+// Limiter tracks per-key counters in memory and optionally mirrors token
+// budget counters into a StateStore.
+type Limiter struct {
+	mu       sync.Mutex
+	limits   map[string]config.APIKeyLimit
+	fallback *config.APIKeyLimit
+	states   map[string]*keyState
+	store    StateStore
+	stop     chan struct{}
+	dirty    bool
+	pruned   time.Time
+}
+
+// NewLimiter constructs a limiter without limits.
+func NewLimiter() *Limiter {
+	return &Limiter{
+		limits: make(map[string]config.APIKeyLimit),
+		states: make(map[string]*keyState),
+	}
+}
+
+var defaultLimiter = NewLimiter()
+
+// Default returns the process-wide limiter used by the access middleware.
+func Default() *Limiter {
+	return defaultLimiter
+}
+
+// SetLimits replaces the configured limits. Counters are kept across updates, except
+// those of idle keys, so keys removed from the config do not linger.
+func (l *Limiter) SetLimits(entries []config.APIKeyLimit) {
+	if l == nil {
+		return
+	}
+	limits := make(map[string]config.APIKeyLimit, len(entries))
+	var fallback *config.APIKeyLimit
+	for _, entry := range config.NormalizeAPIKeyLimits(entries) {
+		if entry.APIKey == config.APIKeyLimitWildcard {
+			wildcard := entry
+			fallback = &wildcard
+			continue
+		}
+		limits[entry.APIKey] = entry
+	}
+	l.mu.Lock()
+	l.limits = limits
+	l.fallback = fallback
+	l.pruneLocked(time.Now())
+	l.mu.Unlock()
+}
+
+// Enabled reports whether any limit is configured.
+func (l *Limiter) Enabled() bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.limits) > 0 || l.fallback != nil
+}
+
+func (l *Limiter) limitForLocked(apiKey string) (config.APIKeyLimit, bool) {
+	if limit, ok := l.limits[apiKey]; ok {
+		return limit, true
+	}
+	if l.fallback != nil {
+		return *l.fallback, true
+	}
+	return config.APIKeyLimit{}, false
+}
+
+// Acquire admits one request for apiKey or reports the limit that refuses it.
+// The returned release function must be called once the request finishes; it is
+// safe to call more than once.
+func (l *Limiter) Acquire(apiKey string, now time.Time) (func(), *Rejection) {
+	apiKey = strings.TrimSpace(apiKey)
+	if l == nil || apiKey == "" {
+		return func() {}, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit, ok := l.limitForLocked(apiKey)
+	if !ok {
+		return func() {}, nil
+	}
+	if now.Sub(l.pruned) >= pruneInterval {
+		l.pruneLocked(now)
+	}
+	keyID := KeyID(apiKey)
+	state := l.stateLocked(keyID, now)
+
+	if limit.DailyTokenBudget > 0 && state.dayTokens >= limit.DailyTokenBudget {
+		return func() {}, &Rejection{Reason: ReasonDailyTokenBudget, Limit: limit.DailyTokenBudget, RetryAfter: nextDay(now).Sub(now)}
+	}
+	if limit.MonthlyTokenBudget > 0 && state.monthTokens >= limit.MonthlyTokenBudget {
+		return func() {}, &Rejection{Reason: ReasonMonthlyTokenBudget, Limit: limit.MonthlyTokenBudget, RetryAfter: nextMonth(now).Sub(now)}
+	}
+	if limit.MaxConcurrentRequests > 0 && state.inFlight >= limit.MaxConcurrentRequests {
+		return func() {}, &Rejection{Reason: ReasonConcurrentRequests, Limit: int64(limit.MaxConcurrentRequests), RetryAfter: concurrencyRetryAfter}
+	}
+	if limit.RequestsPerMinute > 0 {
+		state.pruneRequests(now)
+		if len(state.requests) >= limit.RequestsPerMinute {
+			retryAfter := state.requests[0].Add(requestWindow).Sub(now)
+			return func() {}, &Rejection{Reason: ReasonRequestsPerMinute, Limit: int64(limit.RequestsPerMinute), RetryAfter: retryAfter}
+		}
+		state.requests = append(state.requests, now)
+	}
+
+	state.inFlight++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			if current := l.states[keyID]; current != nil && current.inFlight > 0 {
+				current.inFlight--
+			}
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// RecordTokens charges tokens used by apiKey at the given time to its daily and monthly budgets.
+func (l *Limiter) RecordTokens(apiKey string, tokens int64, at time.Time) {
+	apiKey = strings.TrimSpace(apiKey)
+	if l == nil || apiKey == "" || tokens <= 0 {
+		return
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	state := l.stateLocked(KeyID(apiKey), at)
+	if state.day == dayWindow(at) {
+		state.dayTokens += tokens
+	}
+	if state.month == monthWindow(at) {
+		state.monthTokens += tokens
+	}
+	state.updatedAt = at
+	l.dirty = l.store != nil
+}
+
+// Usage reports the current counters for each of apiKeys.
+func (l *Limiter) Usage(apiKeys []string, now time.Time) []Usage {
+	if l == nil || len(apiKeys) == 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]Usage, 0, len(apiKeys))
+	day, month := dayWindow(now), monthWindow(now)
+	for _, apiKey := range apiKeys {
+		apiKey = strings.TrimSpace(apiKey)
+		if apiKey == "" || apiKey == config.APIKeyLimitWildcard {
+			continue
+		}
+		entry := Usage{APIKey: apiKey}
+		if state := l.states[KeyID(apiKey)]; state != nil {
+			cutoff := now.Add(-requestWindow)
+			for _, requestedAt := range state.requests {
+				if requestedAt.After(cutoff) {
+					entry.RequestsLastMinute++
+				}
+			}
+			entry.InFlight = state.inFlight
+			if state.day == day {
+				entry.DailyTokens = state.dayTokens
+			}
+			if state.month == month {
+				entry.MonthlyTokens = state.monthTokens
+			}
+		}
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].APIKey < out[j].APIKey })
+	return out
+}
+
+func (l *Limiter) stateLocked(keyID string, now time.Time) *keyState {
+	state := l.states[keyID]
+	if state == nil {
+		state = &keyState{}
+		l.states[keyID] = state
+	}
+	if day := dayWindow(now); state.day != day {
+		state.day = day
+		state.dayTokens = 0
+	}
+	if month := monthWindow(now); state.month != month {
+		state.month = month
+		state.monthTokens = 0
+	}
+	return state
+}
+
+// pruneLocked drops the states of keys with no request in flight, no request in the
+// current window and no tokens charged this month. Dropping them loses nothing.
+func (l *Limiter) pruneLocked(now time.Time) {
+	l.pruned = now
+	month := monthWindow(now)
+	for keyID, state := range l.states {
+		if state.inFlight > 0 || (state.month == month && state.monthTokens > 0) {
+			continue
+		}
+		state.pruneRequests(now)
+		if len(state.requests) == 0 {
+			delete(l.states, keyID)
+		}
+	}
+}
+
+func (s *keyState) pruneRequests(now time.Time) {
+	cutoff := now.Add(-requestWindow)
+	index := 0
+	for index < len(s.requests) && !s.requests[index].After(cutoff) {
+		index++
+	}
+	if index > 0 {
+		s.requests = append(s.requests[:0], s.requests[index:]...)
+	}
+}
+
+// KeyID returns the stable fingerprint used to persist counters without storing raw keys.
+func KeyID(apiKey string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(apiKey)))
+	return hex.EncodeToString(sum[:])
+}
+
+func dayWindow(t time.Time) string {
+	return t.UTC().Format(dayWindowLayout)
+}
+
+func monthWindow(t time.Time) string {
+	return t.UTC().Format(monthWindowLayout)
+}
+
+func nextDay(t time.Time) time.Time {
+	utc := t.UTC()
+	return time.Date(utc.Year(), utc.Month(), utc.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+func nextMonth(t time.Time) time.Time {
+	utc := t.UTC()
+	return time.Date(utc.Year(), utc.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,166 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+func TestLimiterAcquire_RequestsPerMinuteSlidingWindow(t *testing.T) {
+	limiter := NewLimiter()
+	limiter.SetLimits([]config.APIKeyLimit{{APIKey: "team-a", RequestsPerMinute: 2}})
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		release, rejection := limiter.Acquire("team-a", start.Add(time.Duration(i)*10*time.Second))
+		if rejection != nil {
+			t.Fatalf("request %d rejected: %+v", i, rejection)
+		}
+		release()
+	}
+
+	_, rejection := limiter.Acquire("team-a", start.Add(30*time.Second))
+	if rejection == nil || rejection.Reason != ReasonRequestsPerMinute {
+		t.Fatalf("third request rejection = %+v, want %s", rejection, ReasonRequestsPerMinute)
+	}
+	if got := rejection.RetryAfterSeconds(); got != 30 {
+		t.Fatalf("RetryAfterSeconds() = %d, want 30", got)
+	}
+
+	if _, rejection = limiter.Acquire("team-a", start.Add(61*time.Second)); rejection != nil {
+		t.Fatalf("request after oldest entry expired rejected: %+v", rejection)
+	}
+	if _, rejection = limiter.Acquire("unlimited", start); rejection != nil {
+		t.Fatalf("key without limits rejected: %+v", rejection)
+	}
+}
+
+func TestLimiterAcquire_ConcurrentRequestsReleasedOnce(t *testing.T) {
+	limiter := NewLimiter()
+	limiter.SetLimits([]config.APIKeyLimit{{APIKey: config.APIKeyLimitWildcard, MaxConcurrentRequests: 1}})
+	now := time.Now()
+
+	release, rejection := limiter.Acquire("any-key", now)
+	if rejection != nil {
+		t.Fatalf("first request rejected: %+v", rejection)
+	}
+	if _, rejection = limiter.Acquire("any-key", now); rejection == nil || rejection.Reason != ReasonConcurrentRequests {
+		t.Fatalf("second in-flight request rejection = %+v, want %s", rejection, ReasonConcurrentRequests)
+	}
+	release()
+	release()
+	if _, rejection = limiter.Acquire("any-key", now); rejection != nil {
+		t.Fatalf("request after release rejected: %+v", rejection)
+	}
+	if usage := limiter.Usage([]string{"any-key"}, now); len(usage) != 1 || usage[0].InFlight != 1 {
+		t.Fatalf("Usage() = %+v, want one in-flight request", usage)
+	}
+}
+
+func TestLimiterAcquire_TokenBudgetsResetPerWindow(t *testing.T) {
+	limiter := NewLimiter()
+	limiter.SetLimits([]config.APIKeyLimit{{APIKey: "team-a", DailyTokenBudget: 100, MonthlyTokenBudget: 150}})
+	day1 := time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC)
+
+	limiter.RecordTokens("team-a", 100, day1)
+	_, rejection := limiter.Acquire("team-a", day1)
+	if rejection == nil || rejection.Reason != ReasonDailyTokenBudget {
+		t.Fatalf("rejection = %+v, want %s", rejection, ReasonDailyTokenBudget)
+	}
+	if got := rejection.RetryAfterSeconds(); got != 3600 {
+		t.Fatalf("RetryAfterSeconds() = %d, want 3600", got)
+	}
+	if !rejection.IsBudget() {
+		t.Fatal("IsBudget() = false, want true")
+	}
+
+	day2 := time.Date(2026, 4, 1, 0, 30, 0, 0, time.UTC)
+	if _, rejection = limiter.Acquire("team-a", day2); rejection != nil {
+		t.Fatalf("request in next day and month rejected: %+v", rejection)
+	}
+
+	limiter.RecordTokens("team-a", 60, day2)
+	day3 := day2.Add(24 * time.Hour)
+	limiter.RecordTokens("team-a", 90, day3)
+	if _, rejection = limiter.Acquire("team-a", day3); rejection == nil || rejection.Reason != ReasonMonthlyTokenBudget {
+		t.Fatalf("rejection = %+v, want %s", rejection, ReasonMonthlyTokenBudget)
+	}
+}
+
+type memoryStateStore struct {
+	records []StateRecord
+	saves   int
+}
+
+func (s *memoryStateStore) Load(context.Context) ([]StateRecord, error) {
+	return append([]StateRecord(nil), s.records...), nil
+}
+
+func (s *memoryStateStore) Save(_ context.Context, records []StateRecord) error {
+	s.records = append([]StateRecord(nil), records...)
+	s.saves++
+	return nil
+}
+
+func TestLimiterStateStore_RestoresAndFlushesTokenCounters(t *testing.T) {
+	now := time.Now()
+	store := &memoryStateStore{records: []StateRecord{{
+		KeyID:       KeyID("team-a"),
+		Day:         dayWindow(now),
+		DayTokens:   40,
+		Month:       monthWindow(now),
+		MonthTokens: 400,
+	}}}
+
+	limiter := NewLimiter()
+	if errAttach := limiter.SetStateStore(context.Background(), store); errAttach != nil {
+		t.Fatalf("SetStateStore() error = %v", errAttach)
+	}
+	t.Cleanup(func() { _ = limiter.SetStateStore(context.Background(), nil) })
+
+	if errFlush := limiter.Flush(context.Background()); errFlush != nil || store.saves != 0 {
+		t.Fatalf("Flush() without changes saved %d times, err = %v", store.saves, errFlush)
+	}
+
+	limiter.RecordTokens("team-a", 10, now)
+	if errFlush := limiter.Flush(context.Background()); errFlush != nil {
+		t.Fatalf("Flush() error = %v", errFlush)
+	}
+	if len(store.records) != 1 {
+		t.Fatalf("saved records = %+v, want one", store.records)
+	}
+	saved := store.records[0]
+	if saved.KeyID != KeyID("team-a") || saved.DayTokens != 50 || saved.MonthTokens != 410 {
+		t.Fatalf("saved record = %+v, want restored counters plus 10 tokens", saved)
+	}
+}
+
+func TestLimiterPrunesIdleKeyStates(t *testing.T) {
+	limiter := NewLimiter()
+	limiter.SetLimits([]config.APIKeyLimit{{APIKey: config.APIKeyLimitWildcard, RequestsPerMinute: 10}})
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	release, _ := limiter.Acquire("rotated-key", start)
+	release()
+	busy, _ := limiter.Acquire("busy-key", start)
+	defer busy()
+	limiter.RecordTokens("budget-key", 100, start)
+
+	limiter.mu.Lock()
+	limiter.pruneLocked(start.Add(2 * requestWindow))
+	_, rotated := limiter.states[KeyID("rotated-key")]
+	_, inFlight := limiter.states[KeyID("busy-key")]
+	_, charged := limiter.states[KeyID("budget-key")]
+	limiter.mu.Unlock()
+	if rotated {
+		t.Fatal("idle key state survived pruning")
+	}
+	if !inFlight {
+		t.Fatal("state of a key with a request in flight was pruned")
+	}
+	if !charged {
+		t.Fatal("state with tokens charged this month was pruned")
+	}
+}
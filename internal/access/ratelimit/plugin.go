@@ -0,0 +1,35 @@
+package ratelimit
+
+import (
+	"context"
+
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+func init() {
+	coreusage.RegisterPlugin(&usagePlugin{})
+}
+
+// usagePlugin charges completed usage records to the client key's token budgets.
+type usagePlugin struct{}
+
+func (p *usagePlugin) HandleUsage(_ context.Context, record coreusage.Record) {
+	if record.Cached {
+		return
+	}
+	tokens := recordTokens(record)
+	if tokens <= 0 {
+		return
+	}
+	Default().RecordTokens(record.APIKey, tokens, record.RequestedAt)
+}
+
+func recordTokens(record coreusage.Record) int64 {
+	if breakdown := record.Detail.TokenBreakdown; breakdown.Valid() {
+		return breakdown.TotalTokens
+	}
+	if record.Detail.TotalTokens > 0 {
+		return record.Detail.TotalTokens
+	}
+	return record.Detail.InputTokens + record.Detail.OutputTokens
+}
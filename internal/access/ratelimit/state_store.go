@@ -0,0 +1,156 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// flushInterval controls how often dirty token counters are mirrored to the state store.
+const flushInterval = 30 * time.Second
+
+// StateRecord is the persisted token budget counter for one client key.
+// Keys are identified by KeyID so raw API keys never reach the store.
+type StateRecord struct {
+	KeyID       string    `json:"key_id"`
+	Day         string    `json:"day"`
+	DayTokens   int64     `json:"day_tokens"`
+	Month       string    `json:"month"`
+	MonthTokens int64     `json:"month_tokens"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// StateStore persists token budget counters across restarts and replicas.
+type StateStore interface {
+	Load(context.Context) ([]StateRecord, error)
+	Save(context.Context, []StateRecord) error
+}
+
+// StateStoreProvider exposes a backend-specific API key limit state store.
+type StateStoreProvider interface {
+	APIKeyLimitStateStore() StateStore
+}
+
+// SetStateStore attaches store, merges its persisted counters into memory and
+// starts the periodic flush loop. Passing nil detaches the current store.
+func (l *Limiter) SetStateStore(ctx context.Context, store StateStore) error {
+	if l == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	l.mu.Lock()
+	if l.stop != nil {
+		close(l.stop)
+		l.stop = nil
+	}
+	l.store = store
+	l.dirty = false
+	l.mu.Unlock()
+	if store == nil {
+		return nil
+	}
+
+	records, errLoad := store.Load(ctx)
+	if errLoad != nil {
+		return errLoad
+	}
+	l.mergeRecords(records, time.Now())
+
+	stop := make(chan struct{})
+	l.mu.Lock()
+	l.stop = stop
+	l.mu.Unlock()
+	go l.flushLoop(stop)
+	return nil
+}
+
+// Flush saves token counters to the attached store when they changed since the last save.
+func (l *Limiter) Flush(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	l.mu.Lock()
+	store := l.store
+	if store == nil || !l.dirty {
+		l.mu.Unlock()
+		return nil
+	}
+	records := l.recordsLocked(time.Now())
+	l.dirty = false
+	l.mu.Unlock()
+
+	if errSave := store.Save(ctx, records); errSave != nil {
+		l.mu.Lock()
+		l.dirty = l.store == store
+		l.mu.Unlock()
+		return errSave
+	}
+	return nil
+}
+
+func (l *Limiter) flushLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if errFlush := l.Flush(context.Background()); errFlush != nil {
+				log.Warnf("api key limits: failed to persist token counters: %v", errFlush)
+			}
+		}
+	}
+}
+
+// mergeRecords keeps the larger counter for each current window so a restart
+// or a second replica never lowers spend that was already charged.
+func (l *Limiter) mergeRecords(records []StateRecord, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	day, month := dayWindow(now), monthWindow(now)
+	for _, record := range records {
+		if record.KeyID == "" {
+			continue
+		}
+		state := l.stateLocked(record.KeyID, now)
+		if record.Day == day && record.DayTokens > state.dayTokens {
+			state.dayTokens = record.DayTokens
+		}
+		if record.Month == month && record.MonthTokens > state.monthTokens {
+			state.monthTokens = record.MonthTokens
+		}
+		if record.UpdatedAt.After(state.updatedAt) {
+			state.updatedAt = record.UpdatedAt
+		}
+	}
+}
+
+func (l *Limiter) recordsLocked(now time.Time) []StateRecord {
+	day, month := dayWindow(now), monthWindow(now)
+	records := make([]StateRecord, 0, len(l.states))
+	for keyID, state := range l.states {
+		record := StateRecord{KeyID: keyID, Day: day, Month: month, UpdatedAt: state.updatedAt}
+		if state.day == day {
+			record.DayTokens = state.dayTokens
+		}
+		if state.month == month {
+			record.MonthTokens = state.monthTokens
+		}
+		if record.DayTokens == 0 && record.MonthTokens == 0 {
+			continue
+		}
+		if record.UpdatedAt.IsZero() {
+			record.UpdatedAt = now
+		}
+		records = append(records, record)
+	}
+	return records
+}
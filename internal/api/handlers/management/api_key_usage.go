@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 )
 
@@ -70,6 +71,7 @@ func (h *Handler) GetAPIKeyUsage(c *gin.Context) {
 	}
 
 	now := time.Now()
+	redact := secretsRedacted(c)
 	out := make(map[string]map[string]apiKeyUsageEntry)
 	for _, auth := range manager.List() {
 		if auth == nil {
@@ -90,6 +92,9 @@ func (h *Handler) GetAPIKeyUsage(c *gin.Context) {
 				baseURL = strings.TrimSpace(auth.Attributes["base-url"])
 			}
 		}
+		if redact {
+			apiKey = util.HideAPIKey(apiKey)
+		}
 		compositeKey := baseURL + "|" + apiKey
 		provider := apiKeyUsageProviderKey(auth)
 
@@ -0,0 +1,142 @@
+package management
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/audit"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/watcher/diff"
+)
+
+// maxAuditQueryLimit caps the limit accepted by GetAudit.
+const maxAuditQueryLimit = 5000
+
+// beginAudit snapshots the config before a mutating management call and returns the
+// function that records the call once the handler has run. It returns nil when the call
+// does not mutate anything or the audit log is disabled.
+func (h *Handler) beginAudit(c *gin.Context, principal ManagementPrincipal) func() {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+	trail := audit.Default()
+	if !trail.Enabled() {
+		return nil
+	}
+	h.mu.Lock()
+	before := h.cfg.CloneForRuntime()
+	h.mu.Unlock()
+
+	return func() {
+		h.mu.Lock()
+		changes := diff.BuildConfigChangeDetails(before, h.cfg)
+		h.mu.Unlock()
+		trail.Record(c.Request.Context(), audit.Event{
+			Source:   audit.SourceManagement,
+			Actor:    principal.Name,
+			Role:     string(principal.Role),
+			RemoteIP: c.ClientIP(),
+			Method:   c.Request.Method,
+			Route:    c.FullPath(),
+			Path:     c.Request.URL.Path,
+			Params:   auditParams(c),
+			Status:   c.Writer.Status(),
+			Changes:  changes,
+		})
+	}
+}
+
+// auditParams collects route and query parameters, masking values whose names look secret.
+func auditParams(c *gin.Context) map[string]string {
+	params := make(map[string]string, len(c.Params))
+	add := func(name, value string) {
+		if isSecretFieldName(strings.ToLower(name)) {
+			value = util.HideAPIKey(value)
+		}
+		params[name] = value
+	}
+	for _, param := range c.Params {
+		add(param.Key, param.Value)
+	}
+	for name, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			add(name, strings.Join(values, ","))
+		}
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+// GetAudit lists audit events, newest first.
+//
+// Query parameters: from/to (RFC 3339 or YYYY-MM-DD), since (e.g. 24h or 7d, used when
+// from is absent; default all), the source, actor, method and route filters, and limit.
+func (h *Handler) GetAudit(c *gin.Context) {
+	query, errQuery := parseAuditQuery(c, time.Now())
+	if errQuery != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errQuery.Error()})
+		return
+	}
+	events, errRun := audit.Default().Query(c.Request.Context(), query)
+	if errRun != nil {
+		if errors.Is(errRun, audit.ErrDisabled) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": errRun.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errRun.Error()})
+		return
+	}
+	if events == nil {
+		events = []audit.Event{}
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+func parseAuditQuery(c *gin.Context, now time.Time) (audit.Query, error) {
+	var query audit.Query
+	from, errFrom := parseUsageQueryTime(c.Query("from"))
+	if errFrom != nil {
+		return query, fmt.Errorf("invalid from: %w", errFrom)
+	}
+	to, errTo := parseUsageQueryTime(c.Query("to"))
+	if errTo != nil {
+		return query, fmt.Errorf("invalid to: %w", errTo)
+	}
+	if from.IsZero() {
+		if since := strings.TrimSpace(c.Query("since")); since != "" {
+			window, errSince := parseUsageQueryWindow(since)
+			if errSince != nil {
+				return query, errSince
+			}
+			if window > 0 {
+				from = now.Add(-window)
+			}
+		}
+	}
+	if !to.IsZero() && !from.IsZero() && !to.After(from) {
+		return query, errors.New("to must be after from")
+	}
+	query.From, query.To = from, to
+
+	query.Source = strings.TrimSpace(c.Query("source"))
+	query.Actor = strings.TrimSpace(c.Query("actor"))
+	query.Method = strings.TrimSpace(c.Query("method"))
+	query.Route = strings.TrimSpace(c.Query("route"))
+
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		limit, errLimit := strconv.Atoi(raw)
+		if errLimit != nil || limit < 0 {
+			return query, errors.New("limit must be a non-negative integer")
+		}
+		query.Limit = min(limit, maxAuditQueryLimit)
+	}
+	return query, nil
+}
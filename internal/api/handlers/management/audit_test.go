@@ -0,0 +1,89 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/audit"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+func TestMiddlewareRecordsMutatingCallsInAuditLog(t *testing.T) {
+	audit.Default().Apply(config.AuditLogConfig{Enable: true}, t.TempDir())
+	t.Cleanup(func() { audit.Default().Apply(config.AuditLogConfig{}, "") })
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if errWrite := os.WriteFile(configPath, []byte("routing:\n  strategy: round-robin\n"), 0o600); errWrite != nil {
+		t.Fatalf("write config: %v", errWrite)
+	}
+	h := scopedKeyHandler(t,
+		config.ManagementKey{Name: "ops-lead", Key: "admin-key", Role: config.ManagementRoleAdmin},
+		config.ManagementKey{Name: "dash", Key: "viewer-key", Role: config.ManagementRoleViewer},
+	)
+	h.configFilePath = configPath
+	h.cfg.Routing.Strategy = "round-robin"
+
+	engine := gin.New()
+	group := engine.Group("/v0/management", h.Middleware())
+	group.GET("/audit", h.GetAudit)
+	group.PUT("/routing/strategy", func(c *gin.Context) {
+		h.mu.Lock()
+		h.cfg.Routing.Strategy = "fill-first"
+		h.mu.Unlock()
+		h.persist(c)
+	})
+
+	if rec := serveManagement(engine, http.MethodPut, "/v0/management/routing/strategy?api-key=sk-live-abcdef123456", "admin-key"); rec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveManagement(engine, http.MethodPut, "/v0/management/routing/strategy", "viewer-key"); rec.Code != http.StatusForbidden {
+		t.Fatalf("viewer PUT status = %d, want 403", rec.Code)
+	}
+
+	content, errRead := os.ReadFile(configPath)
+	if errRead != nil {
+		t.Fatalf("read config: %v", errRead)
+	}
+	if !audit.Default().ConsumeManagedConfig(content) {
+		t.Fatal("config written by the management API was not noted for the file watcher")
+	}
+
+	rec := serveManagement(engine, http.MethodGet, "/v0/management/audit", "viewer-key")
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("viewer audit status = %d, want 403", rec.Code)
+	}
+	rec = serveManagement(engine, http.MethodGet, "/v0/management/audit?actor=ops-lead", "admin-key")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("audit status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var payload struct {
+		Events []audit.Event `json:"events"`
+	}
+	if errDecode := json.Unmarshal(rec.Body.Bytes(), &payload); errDecode != nil {
+		t.Fatalf("decode audit: %v", errDecode)
+	}
+	if len(payload.Events) != 1 {
+		t.Fatalf("events = %+v, want the admin change only", payload.Events)
+	}
+	event := payload.Events[0]
+	if event.Role != "admin" || event.RemoteIP != "127.0.0.1" || event.Route != "/v0/management/routing/strategy" || event.Status != http.StatusOK {
+		t.Fatalf("event = %+v", event)
+	}
+	if strings.Join(event.Changes, "\n") != "routing.strategy: round-robin -> fill-first" {
+		t.Fatalf("changes = %v", event.Changes)
+	}
+	if strings.Contains(event.Params["api-key"], "abcdef123456") {
+		t.Fatalf("secret query parameter recorded verbatim: %v", event.Params)
+	}
+
+	events, _ := audit.Default().Query(context.Background(), audit.Query{})
+	if len(events) != 1 {
+		t.Fatalf("read-only calls must not be audited: %+v", events)
+	}
+}
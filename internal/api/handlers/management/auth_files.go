@@ -14,6 +14,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/auth/codex"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/authcrypt"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/credentialweight"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
@@ -207,10 +208,17 @@ func (h *Handler) GetAuthFileModels(c *gin.Context) {
 		if m.OwnedBy != "" {
 			entry["owned_by"] = m.OwnedBy
 		}
+		if m.Discovered {
+			entry["discovered"] = true
+		}
 		result = append(result, entry)
 	}
 
-	c.JSON(200, gin.H{"models": result})
+	response := gin.H{"models": result}
+	if status, ok := registry.GetModelDiscoveryStatus(authID); ok {
+		response["discovery"] = status
+	}
+	c.JSON(200, response)
 }
 
 // List auth files from disk when the auth manager is unavailable.
@@ -243,7 +251,7 @@ func (h *Handler) listAuthFilesFromDisk(c *gin.Context) {
 
 			// Read file to get type field
 			full := filepath.Join(h.cfg.AuthDir, name)
-			if data, errRead := os.ReadFile(full); errRead == nil {
+			if data, errRead := authcrypt.ReadFile(full); errRead == nil {
 				typeValue := gjson.GetBytes(data, "type").String()
 				emailValue := gjson.GetBytes(data, "email").String()
 				fileData["type"] = typeValue
@@ -368,6 +376,9 @@ func (h *Handler) buildAuthFileEntryLocked(auth *coreauth.Auth) gin.H {
 	if !auth.NextRetryAfter.IsZero() {
 		entry["next_retry_after"] = auth.NextRetryAfter
 	}
+	if len(auth.QuotaWindows) > 0 {
+		entry["quota_windows"] = auth.QuotaWindows
+	}
 	if path != "" {
 		entry["path"] = path
 		entry["source"] = "file"
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/authcrypt"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/credentialweight"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v7/sdk/auth"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
@@ -156,7 +157,7 @@ func setSourceAuthFileDisabled(path string, disabled bool) error {
 	if path == "" {
 		return fmt.Errorf("source auth path is empty")
 	}
-	data, errRead := os.ReadFile(path)
+	data, errRead := authcrypt.ReadFile(path)
 	if errRead != nil {
 		return errRead
 	}
@@ -174,7 +175,7 @@ func setSourceAuthFileDisabled(path string, disabled bool) error {
 	if errMarshal != nil {
 		return fmt.Errorf("marshal auth file: %w", errMarshal)
 	}
-	if errWrite := os.WriteFile(path, raw, 0o600); errWrite != nil {
+	if errWrite := authcrypt.WriteFile(path, raw, 0o600); errWrite != nil {
 		return errWrite
 	}
 	return nil
@@ -708,7 +709,7 @@ func (h *Handler) mergeExistingAuthFileMetadata(record *coreauth.Auth) {
 		}
 		if targetFile != "" {
 			fullPath := filepath.Join(h.cfg.AuthDir, targetFile)
-			if raw, errRead := os.ReadFile(fullPath); errRead == nil && len(raw) > 0 {
+			if raw, errRead := authcrypt.ReadFile(fullPath); errRead == nil && len(raw) > 0 {
 				_ = json.Unmarshal(raw, &existingMap)
 			}
 		}
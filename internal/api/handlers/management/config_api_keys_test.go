@@ -0,0 +1,133 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+func TestPatchAPIKeys_EditsLimitsAlongsideKeys(t *testing.T) {
+	t.Parallel()
+
+	h := &Handler{
+		cfg: &config.Config{SDKConfig: config.SDKConfig{
+			APIKeys:      config.ClientAPIKeysFromStrings([]string{"team-a", "team-b"}),
+			APIKeyLimits: []config.APIKeyLimit{{APIKey: "team-a", RequestsPerMinute: 10}},
+		}},
+		configFilePath: writeTestConfigFile(t),
+	}
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodPatch, "/v0/management/api-keys", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		h.PatchAPIKeys(c)
+		return rec
+	}
+
+	if rec := patch(`{"api-key":"team-b","limits":{"max-concurrent-requests":2,"daily-token-budget":5000}}`); rec.Code != http.StatusOK {
+		t.Fatalf("set limits status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if rec := patch(`{"old":"team-a","new":"team-c"}`); rec.Code != http.StatusOK {
+		t.Fatalf("rename status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if rec := patch(`{"api-key":"team-x","limits":{"requests-per-minute":1}}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown key status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := patch(`{"api-key":"team-b","limits":{"requests-per-minute":-1}}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("negative limit status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	limits := map[string]config.APIKeyLimit{}
+	for _, entry := range h.cfg.APIKeyLimits {
+		limits[entry.APIKey] = entry
+	}
+	if len(limits) != 2 || limits["team-c"].RequestsPerMinute != 10 {
+		t.Fatalf("limits after rename = %+v, want team-a limits carried to team-c", h.cfg.APIKeyLimits)
+	}
+	if got := limits["team-b"]; got.MaxConcurrentRequests != 2 || got.DailyTokenBudget != 5000 {
+		t.Fatalf("team-b limits = %+v", got)
+	}
+
+	if rec := patch(`{"api-key":"team-b","limits":null}`); rec.Code != http.StatusOK {
+		t.Fatalf("clear limits status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if len(h.cfg.APIKeyLimits) != 1 || h.cfg.APIKeyLimits[0].APIKey != "team-c" {
+		t.Fatalf("limits after clear = %+v", h.cfg.APIKeyLimits)
+	}
+}
+
+func TestPatchAPIKeys_EditsKeyPolicyFields(t *testing.T) {
+	t.Parallel()
+
+	h := &Handler{
+		cfg: &config.Config{SDKConfig: config.SDKConfig{
+			APIKeys: config.ClientAPIKeysFromStrings([]string{"team-a", "team-b"}),
+		}},
+		configFilePath: writeTestConfigFile(t),
+	}
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodPatch, "/v0/management/api-keys", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		h.PatchAPIKeys(c)
+		return rec
+	}
+
+	if rec := patch(`{"api-key":"team-a","label":"Team A","models":["gpt-5*"],"providers":["Codex"],"prefix":"team-a","disabled":true}`); rec.Code != http.StatusOK {
+		t.Fatalf("policy patch status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	got := h.cfg.APIKeys[0]
+	if got.Label != "Team A" || got.Models[0] != "gpt-5*" || got.Providers[0] != "codex" || got.Prefix != "team-a" || !got.Disabled {
+		t.Fatalf("team-a entry = %+v", got)
+	}
+	if rec := patch(`{"api-key":"team-a","expires-at":"soon"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid expiry status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := patch(`{"index":1,"value":{"key":"team-b2","models":["claude-*"]}}`); rec.Code != http.StatusOK {
+		t.Fatalf("object replace status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if entry := h.cfg.APIKeys[1]; entry.Key != "team-b2" || entry.Models[0] != "claude-*" {
+		t.Fatalf("team-b entry = %+v", entry)
+	}
+	if rec := patch(`{"api-key":"team-a","disabled":false,"label":""}`); rec.Code != http.StatusOK {
+		t.Fatalf("enable status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if h.cfg.APIKeys[0].Disabled || h.cfg.APIKeys[0].Label != "" {
+		t.Fatalf("team-a entry after enable = %+v", h.cfg.APIKeys[0])
+	}
+}
+
+func TestDeleteAPIKeys_PrunesLimitsOfRemovedKeys(t *testing.T) {
+	t.Parallel()
+
+	h := &Handler{
+		cfg: &config.Config{SDKConfig: config.SDKConfig{
+			APIKeys: config.ClientAPIKeysFromStrings([]string{"team-a", "team-b"}),
+			APIKeyLimits: []config.APIKeyLimit{
+				{APIKey: "team-a", RequestsPerMinute: 10},
+				{APIKey: config.APIKeyLimitWildcard, RequestsPerMinute: 5},
+			},
+		}},
+		configFilePath: writeTestConfigFile(t),
+	}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodDelete, "/v0/management/api-keys?value=team-a", nil)
+	h.DeleteAPIKeys(c)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if len(h.cfg.APIKeyLimits) != 1 || h.cfg.APIKeyLimits[0].APIKey != config.APIKeyLimitWildcard {
+		t.Fatalf("limits = %+v, want only the wildcard entry", h.cfg.APIKeyLimits)
+	}
+}
@@ -92,3 +92,20 @@ func toggleConfigAPIKeyExcludedAll(cfg *config.Config, auth *coreauth.Auth, disa
 
 	return false, nil
 }
+
+// setClientAPIKeyDisabled sets the disabled flag of every api-keys entry for key.
+// It reports whether an entry was found.
+func setClientAPIKeyDisabled(cfg *config.Config, key string, disable bool) bool {
+	if cfg == nil {
+		return false
+	}
+	key = strings.TrimSpace(key)
+	found := false
+	for i := range cfg.APIKeys {
+		if strings.TrimSpace(cfg.APIKeys[i].Key) == key {
+			cfg.APIKeys[i].Disabled = disable
+			found = true
+		}
+	}
+	return found
+}
@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -28,7 +27,13 @@ func (h *Handler) GetConfig(c *gin.Context) {
 		c.JSON(200, gin.H{})
 		return
 	}
-	c.JSON(200, new(*h.cfg))
+	// Serve secret references as written in config.yaml, not the resolved secrets.
+	view, err := h.cfg.WithSecretReferences()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "encode_failed", "message": err.Error()})
+		return
+	}
+	c.JSON(200, view)
 }
 
 type releaseInfo struct {
@@ -119,35 +124,27 @@ func (h *Handler) PutConfigYAML(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_yaml", "message": err.Error()})
 		return
 	}
-	// Validate config using LoadConfigOptional with optional=false to enforce parsing
-	tmpDir := filepath.Dir(h.configFilePath)
-	tmpFile, err := os.CreateTemp(tmpDir, "config-validate-*.yaml")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "write_failed", "message": err.Error()})
-		return
-	}
-	tempFile := tmpFile.Name()
-	if _, errWrite := tmpFile.Write(body); errWrite != nil {
-		_ = tmpFile.Close()
-		_ = os.Remove(tempFile)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "write_failed", "message": errWrite.Error()})
-		return
+	// Validate the payload without resolving secret references: it comes from a
+	// management client and must never read host files or run commands.
+	parsed, err := config.ParseConfigBytes(body)
+	if err == nil {
+		err = parsed.Codex.LiveMediaRelay.Validate()
 	}
-	if errClose := tmpFile.Close(); errClose != nil {
-		_ = os.Remove(tempFile)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "write_failed", "message": errClose.Error()})
-		return
-	}
-	defer func() {
-		_ = os.Remove(tempFile)
-	}()
-	_, err = config.LoadConfigOptional(tempFile, false)
 	if err != nil {
 		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid_config", "message": err.Error()})
 		return
 	}
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	current, errRead := os.ReadFile(h.configFilePath)
+	if errRead != nil && !os.IsNotExist(errRead) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "read_failed", "message": errRead.Error()})
+		return
+	}
+	if errHostSecrets := config.CheckNoNewHostSecrets(config.HostSecretReferences(current), config.HostSecretReferences(body)); errHostSecrets != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid_config", "message": errHostSecrets.Error()})
+		return
+	}
 	if WriteConfig(h.configFilePath, body) != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "write_failed", "message": "failed to write config"})
 		return
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/access/ratelimit"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
 )
 
@@ -47,17 +49,26 @@ func rejectInvalidFingerprintProfile(c *gin.Context, field, profile string) bool
 	return false
 }
 
-// Generic helpers for list[string]
-func (h *Handler) putStringList(c *gin.Context, set func([]string), after func()) {
+// api-keys
+func (h *Handler) GetAPIKeys(c *gin.Context) {
+	c.JSON(200, gin.H{
+		"api-keys":            h.cfg.APIKeys,
+		"api-key-limits":      h.cfg.APIKeyLimits,
+		"api-key-limit-usage": ratelimit.Default().Usage(config.ClientAPIKeyValues(h.cfg.APIKeys), time.Now()),
+	})
+}
+
+// PutAPIKeys replaces the api-keys list. Items may be plain key strings or key objects.
+func (h *Handler) PutAPIKeys(c *gin.Context) {
 	data, err := c.GetRawData()
 	if err != nil {
 		c.JSON(400, gin.H{"error": "failed to read body"})
 		return
 	}
-	var arr []string
+	var arr []config.ClientAPIKey
 	if err = json.Unmarshal(data, &arr); err != nil {
 		var obj struct {
-			Items []string `json:"items"`
+			Items []config.ClientAPIKey `json:"items"`
 		}
 		if err2 := json.Unmarshal(data, &obj); err2 != nil || len(obj.Items) == 0 {
 			c.JSON(400, gin.H{"error": "invalid body"})
@@ -65,95 +76,254 @@ func (h *Handler) putStringList(c *gin.Context, set func([]string), after func()
 		}
 		arr = obj.Items
 	}
-	set(arr)
-	if after != nil {
-		after()
+	for index := range arr {
+		if arr[index].IsPlain() {
+			continue
+		}
+		arr[index] = config.NormalizeClientAPIKey(arr[index])
+		if errValidate := config.ValidateClientAPIKey(arr[index]); errValidate != nil {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("api-keys[%d]: %v", index, errValidate)})
+			return
+		}
 	}
+	h.cfg.APIKeys = append([]config.ClientAPIKey(nil), arr...)
+	h.pruneAPIKeyLimits()
 	h.persist(c)
 }
 
-func (h *Handler) patchStringList(c *gin.Context, target *[]string, after func()) {
-	var body struct {
-		Old   *string `json:"old"`
-		New   *string `json:"new"`
-		Index *int    `json:"index"`
-		Value *string `json:"value"`
+// apiKeyPolicyPatch carries the optional key object fields of a PatchAPIKeys body.
+type apiKeyPolicyPatch struct {
+	Label     *string   `json:"label"`
+	Models    *[]string `json:"models"`
+	Providers *[]string `json:"providers"`
+	Prefix    *string   `json:"prefix"`
+	ExpiresAt *string   `json:"expires-at"`
+	Disabled  *bool     `json:"disabled"`
+}
+
+func (p apiKeyPolicyPatch) empty() bool {
+	return p.Label == nil && p.Models == nil && p.Providers == nil && p.Prefix == nil && p.ExpiresAt == nil && p.Disabled == nil
+}
+
+func (p apiKeyPolicyPatch) apply(entry config.ClientAPIKey) config.ClientAPIKey {
+	if p.Label != nil {
+		entry.Label = *p.Label
+	}
+	if p.Models != nil {
+		entry.Models = append([]string(nil), (*p.Models)...)
+	}
+	if p.Providers != nil {
+		entry.Providers = append([]string(nil), (*p.Providers)...)
+	}
+	if p.Prefix != nil {
+		entry.Prefix = *p.Prefix
+	}
+	if p.ExpiresAt != nil {
+		entry.ExpiresAt = *p.ExpiresAt
+	}
+	return config.NormalizeClientAPIKey(entry)
+}
+
+// PatchAPIKeys edits one api-keys entry and, through the optional "limits" field,
+// the api-key-limits entry of the edited key.
+//
+// Entries are replaced with {"old","new"} or {"index","value"}, where new and value
+// may be a key string or a key object. A body with "api-key" and no replacement
+// edits that key in place through any of label, models, providers, prefix,
+// expires-at, disabled and limits; "limits": null clears its limits.
+func (h *Handler) PatchAPIKeys(c *gin.Context) {
+	data, errRead := c.GetRawData()
+	if errRead != nil {
+		c.JSON(400, gin.H{"error": "failed to read body"})
+		return
 	}
-	if err := c.ShouldBindJSON(&body); err != nil {
+	var body struct {
+		apiKeyPolicyPatch
+		APIKey *string              `json:"api-key"`
+		Old    *string              `json:"old"`
+		New    *config.ClientAPIKey `json:"new"`
+		Index  *int                 `json:"index"`
+		Value  *config.ClientAPIKey `json:"value"`
+		Limits json.RawMessage      `json:"limits"`
+	}
+	if errUnmarshal := json.Unmarshal(data, &body); errUnmarshal != nil {
 		c.JSON(400, gin.H{"error": "invalid body"})
 		return
 	}
-	if body.Index != nil && body.Value != nil && *body.Index >= 0 && *body.Index < len(*target) {
-		(*target)[*body.Index] = *body.Value
-		if after != nil {
-			after()
+	var limits *config.APIKeyLimit
+	hasLimits := len(body.Limits) > 0
+	if hasLimits && !bytes.Equal(bytes.TrimSpace(body.Limits), []byte("null")) {
+		var parsed config.APIKeyLimit
+		if errUnmarshal := json.Unmarshal(body.Limits, &parsed); errUnmarshal != nil {
+			c.JSON(400, gin.H{"error": "invalid limits"})
+			return
 		}
-		h.persist(c)
-		return
+		if errValidate := config.ValidateAPIKeyLimit(parsed); errValidate != nil {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("limits: %v", errValidate)})
+			return
+		}
+		limits = &parsed
 	}
-	if body.Old != nil && body.New != nil {
-		for i := range *target {
-			if (*target)[i] == *body.Old {
-				(*target)[i] = *body.New
-				if after != nil {
-					after()
-				}
-				h.persist(c)
-				return
-			}
+
+	if body.APIKey != nil && body.Old == nil && body.New == nil && body.Index == nil && body.Value == nil {
+		if !hasLimits && body.apiKeyPolicyPatch.empty() {
+			c.JSON(400, gin.H{"error": "missing fields"})
+			return
+		}
+		key := strings.TrimSpace(*body.APIKey)
+		if key == config.APIKeyLimitWildcard && body.apiKeyPolicyPatch.empty() {
+			h.setAPIKeyLimit(key, limits)
+			h.persist(c)
+			return
 		}
-		*target = append(*target, *body.New)
-		if after != nil {
-			after()
+		targetIndex := clientAPIKeyIndex(h.cfg.APIKeys, key)
+		if targetIndex < 0 {
+			c.JSON(404, gin.H{"error": "api key not found"})
+			return
+		}
+		entry := body.apiKeyPolicyPatch.apply(h.cfg.APIKeys[targetIndex])
+		if errValidate := config.ValidateClientAPIKey(entry); errValidate != nil {
+			c.JSON(400, gin.H{"error": errValidate.Error()})
+			return
+		}
+		h.cfg.APIKeys[targetIndex] = entry
+		if body.Disabled != nil {
+			setClientAPIKeyDisabled(h.cfg, key, *body.Disabled)
+		}
+		if hasLimits {
+			h.setAPIKeyLimit(key, limits)
 		}
 		h.persist(c)
 		return
 	}
-	c.JSON(400, gin.H{"error": "missing fields"})
+
+	var renamedFrom string
+	var replacement config.ClientAPIKey
+	targetIndex := -1
+	switch {
+	case body.Index != nil && body.Value != nil && *body.Index >= 0 && *body.Index < len(h.cfg.APIKeys):
+		targetIndex = *body.Index
+		renamedFrom, replacement = h.cfg.APIKeys[targetIndex].Key, *body.Value
+	case body.Old != nil && body.New != nil:
+		targetIndex = clientAPIKeyIndexExact(h.cfg.APIKeys, *body.Old)
+		renamedFrom, replacement = *body.Old, *body.New
+	default:
+		c.JSON(400, gin.H{"error": "missing fields"})
+		return
+	}
+	if !replacement.IsPlain() {
+		replacement = config.NormalizeClientAPIKey(replacement)
+		if errValidate := config.ValidateClientAPIKey(replacement); errValidate != nil {
+			c.JSON(400, gin.H{"error": errValidate.Error()})
+			return
+		}
+	}
+	if targetIndex >= 0 {
+		h.cfg.APIKeys[targetIndex] = replacement
+	} else {
+		h.cfg.APIKeys = append(h.cfg.APIKeys, replacement)
+	}
+	h.renameAPIKeyLimit(renamedFrom, replacement.Key)
+	if hasLimits {
+		h.setAPIKeyLimit(replacement.Key, limits)
+	}
+	h.persist(c)
 }
 
-func (h *Handler) deleteFromStringList(c *gin.Context, target *[]string, after func()) {
+// DeleteAPIKeys removes an api-keys entry by ?index= or by ?value= key.
+func (h *Handler) DeleteAPIKeys(c *gin.Context) {
 	if idxStr := c.Query("index"); idxStr != "" {
 		var idx int
 		_, err := fmt.Sscanf(idxStr, "%d", &idx)
-		if err == nil && idx >= 0 && idx < len(*target) {
-			*target = append((*target)[:idx], (*target)[idx+1:]...)
-			if after != nil {
-				after()
-			}
+		if err == nil && idx >= 0 && idx < len(h.cfg.APIKeys) {
+			h.cfg.APIKeys = append(h.cfg.APIKeys[:idx], h.cfg.APIKeys[idx+1:]...)
+			h.pruneAPIKeyLimits()
 			h.persist(c)
 			return
 		}
 	}
 	if val := strings.TrimSpace(c.Query("value")); val != "" {
-		out := make([]string, 0, len(*target))
-		for _, v := range *target {
-			if strings.TrimSpace(v) != val {
-				out = append(out, v)
+		out := make([]config.ClientAPIKey, 0, len(h.cfg.APIKeys))
+		for _, entry := range h.cfg.APIKeys {
+			if strings.TrimSpace(entry.Key) != val {
+				out = append(out, entry)
 			}
 		}
-		*target = out
-		if after != nil {
-			after()
-		}
+		h.cfg.APIKeys = out
+		h.pruneAPIKeyLimits()
 		h.persist(c)
 		return
 	}
 	c.JSON(400, gin.H{"error": "missing index or value"})
 }
 
-// api-keys
-func (h *Handler) GetAPIKeys(c *gin.Context) { c.JSON(200, gin.H{"api-keys": h.cfg.APIKeys}) }
-func (h *Handler) PutAPIKeys(c *gin.Context) {
-	h.putStringList(c, func(v []string) {
-		h.cfg.APIKeys = append([]string(nil), v...)
-	}, nil)
+// setAPIKeyLimit replaces the api-key-limits entry for key; nil removes it.
+func (h *Handler) setAPIKeyLimit(key string, limits *config.APIKeyLimit) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return
+	}
+	out := make([]config.APIKeyLimit, 0, len(h.cfg.APIKeyLimits)+1)
+	for _, entry := range h.cfg.APIKeyLimits {
+		if strings.TrimSpace(entry.APIKey) != key {
+			out = append(out, entry)
+		}
+	}
+	if limits != nil {
+		entry := *limits
+		entry.APIKey = key
+		out = append(out, entry)
+	}
+	h.cfg.APIKeyLimits = config.NormalizeAPIKeyLimits(out)
 }
-func (h *Handler) PatchAPIKeys(c *gin.Context) {
-	h.patchStringList(c, &h.cfg.APIKeys, func() {})
+
+// renameAPIKeyLimit carries limits over when a key is replaced in place.
+func (h *Handler) renameAPIKeyLimit(from, to string) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == "" || to == "" || from == to || clientAPIKeyIndex(h.cfg.APIKeys, from) >= 0 {
+		return
+	}
+	for i := range h.cfg.APIKeyLimits {
+		if strings.TrimSpace(h.cfg.APIKeyLimits[i].APIKey) == from {
+			h.cfg.APIKeyLimits[i].APIKey = to
+		}
+	}
+	h.cfg.APIKeyLimits = config.NormalizeAPIKeyLimits(h.cfg.APIKeyLimits)
 }
-func (h *Handler) DeleteAPIKeys(c *gin.Context) {
-	h.deleteFromStringList(c, &h.cfg.APIKeys, func() {})
+
+// pruneAPIKeyLimits drops limits whose key is no longer listed in api-keys.
+func (h *Handler) pruneAPIKeyLimits() {
+	if len(h.cfg.APIKeyLimits) == 0 {
+		return
+	}
+	out := make([]config.APIKeyLimit, 0, len(h.cfg.APIKeyLimits))
+	for _, entry := range h.cfg.APIKeyLimits {
+		key := strings.TrimSpace(entry.APIKey)
+		if key == config.APIKeyLimitWildcard || clientAPIKeyIndex(h.cfg.APIKeys, key) >= 0 {
+			out = append(out, entry)
+		}
+	}
+	h.cfg.APIKeyLimits = config.NormalizeAPIKeyLimits(out)
+}
+
+// clientAPIKeyIndex returns the index of the first entry whose trimmed key is target, or -1.
+func clientAPIKeyIndex(entries []config.ClientAPIKey, target string) int {
+	for i := range entries {
+		if strings.TrimSpace(entries[i].Key) == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// clientAPIKeyIndexExact returns the index of the first entry whose key equals target, or -1.
+func clientAPIKeyIndexExact(entries []config.ClientAPIKey, target string) int {
+	for i := range entries {
+		if entries[i].Key == target {
+			return i
+		}
+	}
+	return -1
 }
 
 // gemini-api-key: []GeminiKey
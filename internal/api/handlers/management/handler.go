@@ -3,7 +3,9 @@
 package management
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/subtle"
 	"fmt"
 	"net/http"
@@ -14,6 +16,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/audit"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/buildinfo"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/pluginhost"
@@ -46,6 +49,8 @@ type Handler struct {
 	appliedReloadGeneration uint64
 	attemptsMu              sync.Mutex
 	failedAttempts          map[string]*attemptInfo // keyed by client IP
+	keyMatchMu              sync.Mutex
+	keyMatches              map[[sha256.Size]byte]string // key digest -> matched bcrypt hash
 	authManager             *coreauth.Manager
 	tokenStore              coreauth.Store
 	localPassword           string
@@ -176,7 +181,7 @@ func (h *Handler) reloadSnapshotConfigLocked() configReloadSnapshot {
 // saveConfigAndSnapshotLocked saves h.cfg and returns a full runtime config snapshot.
 // Callers must hold h.mu.
 func (h *Handler) saveConfigAndSnapshotLocked(c *gin.Context) (configReloadSnapshot, bool) {
-	if errSave := config.SaveConfigPreserveComments(h.configFilePath, h.cfg); errSave != nil {
+	if errSave := h.saveConfigLocked(); errSave != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to save config: %v", errSave)})
 		return configReloadSnapshot{}, false
 	}
@@ -286,33 +291,53 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 			provided = c.GetHeader("X-Management-Key")
 		}
 
-		allowed, statusCode, errMsg := h.AuthenticateManagementKey(clientIP, localClient, provided)
+		principal, allowed, statusCode, errMsg := h.AuthenticateManagementPrincipal(clientIP, localClient, provided)
 		if !allowed {
 			c.AbortWithStatusJSON(statusCode, gin.H{"error": errMsg})
 			return
 		}
+		if !authorizeManagementRoute(c, principal) {
+			return
+		}
+		recordAudit := h.beginAudit(c, principal)
 		c.Next()
+		if writer, ok := c.Writer.(*redactingResponseWriter); ok {
+			writer.finish()
+		}
+		if recordAudit != nil {
+			recordAudit()
+		}
 	}
 }
 
 // AuthenticateManagementKey verifies the provided management key for the given client.
 // It mirrors the behaviour of Middleware() so non-HTTP callers can reuse the same logic.
 func (h *Handler) AuthenticateManagementKey(clientIP string, localClient bool, provided string) (bool, int, string) {
+	_, allowed, statusCode, errMsg := h.AuthenticateManagementPrincipal(clientIP, localClient, provided)
+	return allowed, statusCode, errMsg
+}
+
+// AuthenticateManagementPrincipal verifies the provided management key and returns the
+// principal it identifies. The secret key, MANAGEMENT_PASSWORD and the local password
+// grant admin; remote-management.keys entries grant their configured role.
+func (h *Handler) AuthenticateManagementPrincipal(clientIP string, localClient bool, provided string) (ManagementPrincipal, bool, int, string) {
 	const maxFailures = 5
 	const banDuration = 30 * time.Minute
 
 	if h == nil {
-		return false, http.StatusForbidden, "remote management disabled"
+		return ManagementPrincipal{}, false, http.StatusForbidden, "remote management disabled"
 	}
 
 	cfg := h.cfg
 	var (
 		allowRemote bool
 		secretHash  string
+		scopedKeys  []config.ManagementKey
 	)
 	if cfg != nil {
 		allowRemote = cfg.RemoteManagement.AllowRemote
 		secretHash = cfg.RemoteManagement.SecretKey
+		scopedKeys = cfg.RemoteManagement.Keys
 	}
 	if h.allowRemoteOverride {
 		allowRemote = true
@@ -326,7 +351,7 @@ func (h *Handler) AuthenticateManagementKey(clientIP string, localClient bool, p
 		if now.Before(ai.blockedUntil) {
 			remaining := ai.blockedUntil.Sub(now).Round(time.Second)
 			h.attemptsMu.Unlock()
-			return false, http.StatusForbidden, fmt.Sprintf("IP banned due to too many failed attempts. Try again in %s", remaining)
+			return ManagementPrincipal{}, false, http.StatusForbidden, fmt.Sprintf("IP banned due to too many failed attempts. Try again in %s", remaining)
 		}
 		// Ban expired, reset state
 		ai.blockedUntil = time.Time{}
@@ -335,7 +360,7 @@ func (h *Handler) AuthenticateManagementKey(clientIP string, localClient bool, p
 	h.attemptsMu.Unlock()
 
 	if !localClient && !allowRemote {
-		return false, http.StatusForbidden, "remote management disabled"
+		return ManagementPrincipal{}, false, http.StatusForbidden, "remote management disabled"
 	}
 
 	fail := func() {
@@ -363,37 +388,108 @@ func (h *Handler) AuthenticateManagementKey(clientIP string, localClient bool, p
 		h.attemptsMu.Unlock()
 	}
 
-	if secretHash == "" && envSecret == "" {
-		return false, http.StatusForbidden, "remote management key not set"
+	if secretHash == "" && envSecret == "" && len(scopedKeys) == 0 {
+		return ManagementPrincipal{}, false, http.StatusForbidden, "remote management key not set"
 	}
 
 	if provided == "" {
 		fail()
-		return false, http.StatusUnauthorized, "missing management key"
+		return ManagementPrincipal{}, false, http.StatusUnauthorized, "missing management key"
 	}
 
 	if localClient {
 		if lp := h.localPassword; lp != "" {
 			if subtle.ConstantTimeCompare([]byte(provided), []byte(lp)) == 1 {
 				reset()
-				return true, 0, ""
+				return adminPrincipal("local"), true, 0, ""
 			}
 		}
 	}
 
 	if envSecret != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(envSecret)) == 1 {
 		reset()
-		return true, 0, ""
+		return adminPrincipal("env"), true, 0, ""
+	}
+
+	if secretHash != "" && bcrypt.CompareHashAndPassword([]byte(secretHash), []byte(provided)) == nil {
+		reset()
+		return adminPrincipal("secret-key"), true, 0, ""
 	}
 
-	if secretHash == "" || bcrypt.CompareHashAndPassword([]byte(secretHash), []byte(provided)) != nil {
+	key, matched := h.matchScopedManagementKey(scopedKeys, provided)
+	if !matched {
 		fail()
-		return false, http.StatusUnauthorized, "invalid management key"
+		return ManagementPrincipal{}, false, http.StatusUnauthorized, "invalid management key"
+	}
+	if key.Expired(now) {
+		return ManagementPrincipal{}, false, http.StatusUnauthorized, "management key expired"
+	}
+	if !key.AllowsIP(clientIP) {
+		return ManagementPrincipal{}, false, http.StatusForbidden, "management key not allowed from this address"
 	}
 
 	reset()
 
-	return true, 0, ""
+	return ManagementPrincipal{Name: key.Name, Role: key.Role, ExpiresAt: key.ExpiresAt}, true, 0, ""
+}
+
+// matchScopedManagementKey finds the remote-management.keys entry whose hash matches
+// provided. Successful matches are remembered by digest so repeat requests skip bcrypt;
+// the memo only points at a hash, which must still be configured to be honoured.
+func (h *Handler) matchScopedManagementKey(keys []config.ManagementKey, provided string) (config.ManagementKey, bool) {
+	if len(keys) == 0 {
+		return config.ManagementKey{}, false
+	}
+	digest := sha256.Sum256([]byte(provided))
+	h.keyMatchMu.Lock()
+	remembered := h.keyMatches[digest]
+	h.keyMatchMu.Unlock()
+	if remembered != "" {
+		for _, key := range keys {
+			if key.Key == remembered {
+				return key, true
+			}
+		}
+	}
+	for _, key := range keys {
+		if bcrypt.CompareHashAndPassword([]byte(key.Key), []byte(provided)) != nil {
+			continue
+		}
+		h.keyMatchMu.Lock()
+		if h.keyMatches == nil {
+			h.keyMatches = make(map[[sha256.Size]byte]string)
+		}
+		h.keyMatches[digest] = key.Key
+		h.keyMatchMu.Unlock()
+		return key, true
+	}
+	return config.ManagementKey{}, false
+}
+
+// saveConfigLocked writes h.cfg to the config file, preserving comments. When the content
+// changes, it is noted as a management write before the file is written, so the file
+// watcher never sees the new file first and records the change again.
+// It expects the caller to hold h.mu.
+func (h *Handler) saveConfigLocked() error {
+	data, errRender := config.RenderConfigPreserveComments(h.configFilePath, h.cfg)
+	if errRender != nil {
+		return errRender
+	}
+	trail := audit.Default()
+	noted := false
+	if trail.Enabled() {
+		if current, errRead := os.ReadFile(h.configFilePath); errRead != nil || !bytes.Equal(current, data) {
+			trail.NoteManagedConfig(data)
+			noted = true
+		}
+	}
+	if errWrite := os.WriteFile(h.configFilePath, data, 0o666); errWrite != nil {
+		if noted {
+			trail.ConsumeManagedConfig(data)
+		}
+		return errWrite
+	}
+	return nil
 }
 
 // persist saves the current in-memory config to disk.
@@ -407,7 +503,7 @@ func (h *Handler) persist(c *gin.Context) bool {
 // It expects the caller to hold h.mu.
 func (h *Handler) persistLocked(c *gin.Context) bool {
 	// Preserve comments when writing
-	if err := config.SaveConfigPreserveComments(h.configFilePath, h.cfg); err != nil {
+	if err := h.saveConfigLocked(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to save config: %v", err)})
 		return false
 	}
@@ -0,0 +1,25 @@
+package management
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/metrics"
+)
+
+// GetMetrics serves the Prometheus/OpenMetrics exposition when metrics.listener is "management".
+func (h *Handler) GetMetrics(c *gin.Context) {
+	metrics.Handler(config.MetricsListenerManagement, h.metricsAuthSource).ServeHTTP(c.Writer, c.Request)
+}
+
+func (h *Handler) metricsAuthSource() metrics.AuthSource {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	manager := h.authManager
+	h.mu.Unlock()
+	if manager == nil {
+		return nil
+	}
+	return manager
+}
@@ -0,0 +1,14 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+)
+
+// GetModelCatalog returns the version and source of the active models catalog, its
+// overlays and signature state, and the outcome of the last refresh.
+func (h *Handler) GetModelCatalog(c *gin.Context) {
+	c.JSON(http.StatusOK, registry.GetModelCatalogStatus())
+}
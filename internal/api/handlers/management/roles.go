@@ -0,0 +1,246 @@
+package management
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/proxyutil"
+)
+
+// managementPrincipalContextKey stores the authenticated ManagementPrincipal on the gin context.
+const managementPrincipalContextKey = "managementPrincipal"
+
+// managementRoleHeader tells management clients which role the presented key holds.
+const managementRoleHeader = "X-CPA-MANAGEMENT-ROLE"
+
+// ManagementPrincipal identifies the key that authenticated a management request.
+type ManagementPrincipal struct {
+	// Name is the configured key name, or "secret-key", "env" or "local" for the
+	// unscoped keys.
+	Name string
+	// Role is the access level granted to the key.
+	Role config.ManagementRole
+	// ExpiresAt is the configured expiry of a scoped key, if any.
+	ExpiresAt string
+}
+
+func adminPrincipal(name string) ManagementPrincipal {
+	return ManagementPrincipal{Name: name, Role: config.ManagementRoleAdmin}
+}
+
+// secretsRedacted reports whether responses to c must not reveal secrets verbatim.
+// Handlers use it for secrets that the generic JSON redaction cannot see, such as map keys.
+func secretsRedacted(c *gin.Context) bool {
+	principal, ok := PrincipalFromContext(c)
+	return ok && principal.Role != config.ManagementRoleAdmin
+}
+
+// PrincipalFromContext returns the principal stored by Middleware.
+func PrincipalFromContext(c *gin.Context) (ManagementPrincipal, bool) {
+	if c == nil {
+		return ManagementPrincipal{}, false
+	}
+	value, exists := c.Get(managementPrincipalContextKey)
+	if !exists {
+		return ManagementPrincipal{}, false
+	}
+	principal, ok := value.(ManagementPrincipal)
+	return principal, ok
+}
+
+// operatorRoutes are the non-read routes operators may call, and the log readers they
+// may open. Keys are "METHOD route" with gin route patterns.
+var operatorRoutes = map[string]struct{}{
+	"PATCH /v0/management/auth-files/status":      {},
+	"POST /v0/management/reset-quota":             {},
+	"GET /v0/management/logs":                     {},
+	"GET /v0/management/request-error-logs":       {},
+	"GET /v0/management/request-error-logs/:name": {},
+	"GET /v0/management/request-log-by-id/:id":    {},
+	"GET /v0/management/audit":                    {},
+}
+
+// adminReadRoutes are read routes that expose secrets verbatim or have side effects.
+var adminReadRoutes = map[string]struct{}{
+	"GET /v0/management/config.yaml":         {},
+	"GET /v0/management/auth-files/download": {},
+	"GET /v0/management/plugins/:id/config":  {},
+	"GET /v0/management/usage-queue":         {},
+	"GET /v0/management/get-auth-status":     {},
+}
+
+// requiredManagementRole returns the least role allowed to call method on route. Reads
+// default to viewer and everything else to admin; routes without a registered pattern,
+// such as plugin-owned routes, require admin.
+func requiredManagementRole(method, route string) config.ManagementRole {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	if route == "" {
+		return config.ManagementRoleAdmin
+	}
+	key := method + " " + route
+	if _, ok := operatorRoutes[key]; ok {
+		return config.ManagementRoleOperator
+	}
+	if method != http.MethodGet {
+		return config.ManagementRoleAdmin
+	}
+	if _, ok := adminReadRoutes[key]; ok || strings.HasSuffix(route, "-auth-url") {
+		return config.ManagementRoleAdmin
+	}
+	return config.ManagementRoleViewer
+}
+
+// authorizeManagementRoute stores principal on c and aborts with 403 when its role may
+// not call the matched route. Responses to non-admin keys have secrets redacted.
+func authorizeManagementRoute(c *gin.Context, principal ManagementPrincipal) bool {
+	c.Set(managementPrincipalContextKey, principal)
+	c.Header(managementRoleHeader, string(principal.Role))
+	required := requiredManagementRole(c.Request.Method, c.FullPath())
+	if !principal.Role.Allows(required) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":         "management key role does not permit this request",
+			"role":          principal.Role,
+			"required-role": required,
+		})
+		return false
+	}
+	if principal.Role != config.ManagementRoleAdmin {
+		c.Writer = &redactingResponseWriter{ResponseWriter: c.Writer}
+	}
+	return true
+}
+
+// GetWhoAmI describes the management key used for the request and what it may do, so
+// the TUI and control panel can hide actions the key cannot perform.
+func (h *Handler) GetWhoAmI(c *gin.Context) {
+	principal, ok := PrincipalFromContext(c)
+	if !ok {
+		principal = adminPrincipal("local")
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"name":       principal.Name,
+		"role":       principal.Role,
+		"expires-at": principal.ExpiresAt,
+		"capabilities": gin.H{
+			"read":             true,
+			"secrets-redacted": principal.Role != config.ManagementRoleAdmin,
+			"view-logs":        principal.Role.Allows(config.ManagementRoleOperator),
+			"toggle-auth":      principal.Role.Allows(config.ManagementRoleOperator),
+			"reset-quota":      principal.Role.Allows(config.ManagementRoleOperator),
+			"write-config":     principal.Role.Allows(config.ManagementRoleAdmin),
+			"plugin-store":     principal.Role.Allows(config.ManagementRoleAdmin),
+			"download-auth":    principal.Role.Allows(config.ManagementRoleAdmin),
+		},
+	})
+}
+
+// redactingResponseWriter buffers JSON bodies so secrets can be masked before they are
+// written. Other content types pass through unchanged.
+type redactingResponseWriter struct {
+	gin.ResponseWriter
+	buf       bytes.Buffer
+	buffering *bool
+}
+
+func (w *redactingResponseWriter) isJSON() bool {
+	if w.buffering == nil {
+		buffering := strings.Contains(strings.ToLower(w.Header().Get("Content-Type")), "json")
+		w.buffering = &buffering
+	}
+	return *w.buffering
+}
+
+func (w *redactingResponseWriter) Write(data []byte) (int, error) {
+	if !w.isJSON() {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.buf.Write(data)
+}
+
+func (w *redactingResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush is deferred until the redacted body is written.
+func (w *redactingResponseWriter) Flush() {
+	if w.buffering != nil && !*w.buffering {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// finish writes the buffered body with secrets masked.
+func (w *redactingResponseWriter) finish() {
+	if w.buffering == nil || !*w.buffering {
+		return
+	}
+	_, _ = w.ResponseWriter.Write(redactSecretsJSON(w.buf.Bytes()))
+}
+
+// redactSecretsJSON masks string values held under secret-looking member names, including
+// strings in arrays under such a member. Object members are judged by their own name, so
+// the label of an entry in "api-keys" stays readable while its "key" is masked. Bodies
+// that are not valid JSON are replaced with an error object rather than passed through.
+func redactSecretsJSON(body []byte) []byte {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if errDecode := decoder.Decode(&value); errDecode != nil {
+		return []byte(`{"error":"response withheld: unable to redact secrets"}`)
+	}
+	out, errMarshal := json.Marshal(redactSecretsValue(value, false))
+	if errMarshal != nil {
+		return []byte(`{"error":"response withheld: unable to redact secrets"}`)
+	}
+	return out
+}
+
+func redactSecretsValue(value any, secret bool) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for name, child := range typed {
+			lower := strings.ToLower(name)
+			if lower == "proxy-url" || lower == "proxy_url" {
+				if raw, ok := child.(string); ok {
+					typed[name] = proxyutil.Redact(raw)
+					continue
+				}
+			}
+			typed[name] = redactSecretsValue(child, isSecretFieldName(lower))
+		}
+		return typed
+	case []any:
+		for i, child := range typed {
+			typed[i] = redactSecretsValue(child, secret)
+		}
+		return typed
+	case string:
+		if secret && typed != "" {
+			return util.HideAPIKey(typed)
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+var secretFieldMarkers = []string{"key", "secret", "token", "password", "authorization", "cookie", "credential"}
+
+func isSecretFieldName(lower string) bool {
+	for _, marker := range secretFieldMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package management
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/usageledger"
+)
+
+// defaultUsageQueryWindow is the lookback used when neither from nor since is given.
+const defaultUsageQueryWindow = 24 * time.Hour
+
+// GetUsageQuery aggregates the persistent usage ledger.
+//
+// Query parameters: from/to (RFC 3339 or YYYY-MM-DD), since (e.g. 24h or 7d, used
+// when from is absent), group-by (comma-separated api-key, model, provider, auth,
+// bucket), bucket (hour, day, month), the api-key/model/provider/auth filters,
+// sort (tokens or cost), limit, and format (json or csv).
+func (h *Handler) GetUsageQuery(c *gin.Context) {
+	query, errQuery := parseUsageLedgerQuery(c, time.Now())
+	if errQuery != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errQuery.Error()})
+		return
+	}
+	writeUsageLedgerResult(c, query, "usage.csv")
+}
+
+// GetUsageSpend reports priced spend from the persistent usage ledger for chargeback.
+//
+// It accepts the parameters of GetUsageQuery, groups by api-key when group-by is
+// absent and orders rows by cost unless sort is given. Rows carry cost_usd and
+// unpriced_requests, the count of requests whose model had no known price.
+func (h *Handler) GetUsageSpend(c *gin.Context) {
+	query, errQuery := parseUsageLedgerQuery(c, time.Now())
+	if errQuery != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errQuery.Error()})
+		return
+	}
+	if len(query.GroupBy) == 0 && c.Query("group-by") == "" && c.Query("group_by") == "" {
+		query.GroupBy = []string{usageledger.GroupAPIKey}
+	}
+	if strings.TrimSpace(c.Query("sort")) == "" {
+		query.Sort = usageledger.SortCost
+	}
+	writeUsageLedgerResult(c, query, "spend.csv")
+}
+
+func writeUsageLedgerResult(c *gin.Context, query usageledger.Query, filename string) {
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "json")))
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	result, errRun := usageledger.Default().Query(c.Request.Context(), query)
+	if errRun != nil {
+		if errors.Is(errRun, usageledger.ErrDisabled) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": errRun.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errRun.Error()})
+		return
+	}
+
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Status(http.StatusOK)
+		if errWrite := result.WriteCSV(c.Writer); errWrite != nil {
+			_ = c.Error(errWrite)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func parseUsageLedgerQuery(c *gin.Context, now time.Time) (usageledger.Query, error) {
+	var query usageledger.Query
+
+	groupBy := c.Query("group-by")
+	if groupBy == "" {
+		groupBy = c.Query("group_by")
+	}
+	groups, errGroups := usageledger.ParseGroupBy(groupBy)
+	if errGroups != nil {
+		return query, errGroups
+	}
+	query.GroupBy = groups
+	bucket, errBucket := usageledger.NormalizeBucket(c.Query("bucket"))
+	if errBucket != nil {
+		return query, errBucket
+	}
+	query.Bucket = bucket
+	order, errSort := usageledger.NormalizeSort(c.Query("sort"))
+	if errSort != nil {
+		return query, errSort
+	}
+	query.Sort = order
+
+	from, errFrom := parseUsageQueryTime(c.Query("from"))
+	if errFrom != nil {
+		return query, fmt.Errorf("invalid from: %w", errFrom)
+	}
+	to, errTo := parseUsageQueryTime(c.Query("to"))
+	if errTo != nil {
+		return query, fmt.Errorf("invalid to: %w", errTo)
+	}
+	if from.IsZero() {
+		window := defaultUsageQueryWindow
+		if since := strings.TrimSpace(c.Query("since")); since != "" {
+			parsed, errSince := parseUsageQueryWindow(since)
+			if errSince != nil {
+				return query, errSince
+			}
+			window = parsed
+		}
+		if window > 0 {
+			from = now.Add(-window)
+		}
+	}
+	if !to.IsZero() && !from.IsZero() && !to.After(from) {
+		return query, errors.New("to must be after from")
+	}
+	query.From, query.To = from, to
+
+	query.APIKey = strings.TrimSpace(c.Query("api-key"))
+	query.Model = strings.TrimSpace(c.Query("model"))
+	query.Provider = strings.TrimSpace(c.Query("provider"))
+	query.Auth = strings.TrimSpace(c.Query("auth"))
+
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		limit, errLimit := strconv.Atoi(raw)
+		if errLimit != nil || limit < 0 {
+			return query, errors.New("limit must be a non-negative integer")
+		}
+		query.Limit = limit
+	}
+	return query, nil
+}
+
+func parseUsageQueryTime(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if parsed, errParse := time.Parse(time.RFC3339, raw); errParse == nil {
+		return parsed, nil
+	}
+	parsed, errParse := time.Parse("2006-01-02", raw)
+	if errParse != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 timestamp or YYYY-MM-DD, got %q", raw)
+	}
+	return parsed, nil
+}
+
+// parseUsageQueryWindow accepts Go durations plus a "d" day suffix. "0" and "all" select every record.
+func parseUsageQueryWindow(raw string) (time.Duration, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "0" || raw == "all" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, errAtoi := strconv.Atoi(days)
+		if errAtoi != nil || n < 0 {
+			return 0, fmt.Errorf("invalid since %q", raw)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	window, errParse := time.ParseDuration(raw)
+	if errParse != nil || window < 0 {
+		return 0, fmt.Errorf("invalid since %q", raw)
+	}
+	return window, nil
+}
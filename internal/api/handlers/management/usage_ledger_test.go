@@ -0,0 +1,104 @@
+package management
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/usageledger"
+)
+
+func performUsageQuery(t *testing.T, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	ginCtx, _ := gin.CreateTestContext(rec)
+	ginCtx.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	h := &Handler{}
+	h.GetUsageQuery(ginCtx)
+	return rec
+}
+
+func TestGetUsageQueryDisabledLedger(t *testing.T) {
+	rec := performUsageQuery(t, "/v0/management/usage/query")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d body=%s", rec.Code, http.StatusServiceUnavailable, rec.Body.String())
+	}
+}
+
+func TestGetUsageQueryGroupsAndExports(t *testing.T) {
+	dir := t.TempDir()
+	ledger := usageledger.Default()
+	ledger.Apply(config.UsageLedgerConfig{Enable: true}, dir)
+	t.Cleanup(func() { ledger.Apply(config.UsageLedgerConfig{}, dir) })
+
+	now := time.Now().UTC()
+	ledger.Record(usageledger.Entry{Timestamp: now.Add(-time.Hour), APIKey: "team-a", Model: "gpt-5", Provider: "codex", TotalTokens: 20})
+	ledger.Record(usageledger.Entry{Timestamp: now.Add(-2 * time.Hour), APIKey: "team-b", Model: "gpt-5", Provider: "codex", TotalTokens: 5})
+	ledger.Record(usageledger.Entry{Timestamp: now.Add(-72 * time.Hour), APIKey: "team-a", Model: "gpt-5", Provider: "codex", TotalTokens: 100})
+
+	rec := performUsageQuery(t, "/v0/management/usage/query?group-by=api-key")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var result usageledger.Result
+	if errUnmarshal := json.Unmarshal(rec.Body.Bytes(), &result); errUnmarshal != nil {
+		t.Fatalf("unmarshal response: %v", errUnmarshal)
+	}
+	if len(result.Rows) != 2 || result.Rows[0].APIKey != "team-a" || result.Totals.TotalTokens != 25 {
+		t.Fatalf("default 24h result = %#v", result)
+	}
+
+	rec = performUsageQuery(t, "/v0/management/usage/query?since=7d&group-by=model&format=csv")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("csv status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") || !strings.Contains(rec.Body.String(), "gpt-5,3,0,0,0,0,0,125,") {
+		t.Fatalf("csv response = %q (%s)", rec.Body.String(), rec.Header().Get("Content-Type"))
+	}
+
+	for _, target := range []string{
+		"/v0/management/usage/query?group-by=region",
+		"/v0/management/usage/query?bucket=week&group-by=bucket",
+		"/v0/management/usage/query?since=yesterday",
+		"/v0/management/usage/query?format=xml",
+	} {
+		if rec = performUsageQuery(t, target); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want 400", target, rec.Code)
+		}
+	}
+}
+
+func TestGetUsageSpendGroupsByKeyAndSortsByCost(t *testing.T) {
+	dir := t.TempDir()
+	ledger := usageledger.Default()
+	ledger.Apply(config.UsageLedgerConfig{Enable: true}, dir)
+	t.Cleanup(func() { ledger.Apply(config.UsageLedgerConfig{}, dir) })
+
+	now := time.Now().UTC()
+	ledger.Record(usageledger.Entry{Timestamp: now.Add(-time.Hour), APIKey: "team-a", Model: "gpt-5", TotalTokens: 900, CostUSD: 0.25})
+	ledger.Record(usageledger.Entry{Timestamp: now.Add(-time.Hour), APIKey: "team-b", Model: "claude-opus", TotalTokens: 100, CostUSD: 1.5})
+	ledger.Record(usageledger.Entry{Timestamp: now.Add(-time.Hour), APIKey: "team-b", Model: "local", TotalTokens: 50, UnpricedRequests: 1})
+
+	rec := httptest.NewRecorder()
+	ginCtx, _ := gin.CreateTestContext(rec)
+	ginCtx.Request = httptest.NewRequest(http.MethodGet, "/v0/management/usage/spend", nil)
+	(&Handler{}).GetUsageSpend(ginCtx)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var result usageledger.Result
+	if errUnmarshal := json.Unmarshal(rec.Body.Bytes(), &result); errUnmarshal != nil {
+		t.Fatalf("unmarshal response: %v", errUnmarshal)
+	}
+	if len(result.Rows) != 2 || result.Rows[0].APIKey != "team-b" || result.Rows[0].CostUSD != 1.5 || result.Rows[0].UnpricedRequests != 1 {
+		t.Fatalf("spend rows = %#v", result.Rows)
+	}
+	if result.Totals.CostUSD != 1.75 {
+		t.Fatalf("total spend = %v, want 1.75", result.Totals.CostUSD)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/redisqueue"
 	log "github.com/sirupsen/logrus"
 )
@@ -131,7 +132,11 @@ func (s *Server) handleRedisConnection(conn net.Conn, reader *bufio.Reader) {
 				}
 				continue
 			}
-			allowed, _, errMsg := s.mgmt.AuthenticateManagementKey(clientIP, localClient, password)
+			principal, allowed, _, errMsg := s.mgmt.AuthenticateManagementPrincipal(clientIP, localClient, password)
+			if allowed && !principal.Role.Allows(config.ManagementRoleAdmin) {
+				// Consuming the usage queue removes records and exposes client keys.
+				allowed, errMsg = false, "management key role does not permit usage queue access"
+			}
 			if !allowed {
 				_ = writeRedisError(writer, "ERR "+errMsg)
 				if !flush() {
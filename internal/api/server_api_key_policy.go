@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/access/keypolicy"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+// applyClientAPIKeysConfig publishes the api-keys policies to the process-wide registry.
+func applyClientAPIKeysConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	keypolicy.Default().SetKeys(cfg.APIKeys)
+}
+
+// writeAPIKeyPolicyRejection answers a request whose key is disabled or expired
+// in the same shape as other authentication failures.
+func writeAPIKeyPolicyRejection(c *gin.Context, errPolicy error, realtimeError bool) {
+	if realtimeError {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": gin.H{
+			"message": errPolicy.Error(),
+			"type":    "authentication_error",
+			"param":   nil,
+			"code":    "invalid_api_key",
+		}})
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": errPolicy.Error()})
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/access/keypolicy"
+	proxyconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+func TestAuthMiddlewareRefusesDisabledAndExpiredKeys(t *testing.T) {
+	server := newTestServer(t)
+	t.Cleanup(func() { keypolicy.Default().SetKeys(nil) })
+
+	cases := []struct {
+		name  string
+		entry proxyconfig.ClientAPIKey
+		want  string
+	}{
+		{name: "disabled", entry: proxyconfig.ClientAPIKey{Key: "test-key", Disabled: true}, want: keypolicy.ErrKeyDisabled.Error()},
+		{name: "expired", entry: proxyconfig.ClientAPIKey{Key: "test-key", ExpiresAt: "2020-01-01T00:00:00Z"}, want: keypolicy.ErrKeyExpired.Error()},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			applyClientAPIKeysConfig(&proxyconfig.Config{SDKConfig: proxyconfig.SDKConfig{
+				APIKeys: []proxyconfig.ClientAPIKey{tc.entry},
+			}})
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{}`))
+			req.Header.Set("Authorization", "Bearer test-key")
+			rec := httptest.NewRecorder()
+			server.engine.ServeHTTP(rec, req)
+			if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), tc.want) {
+				t.Fatalf("status = %d body = %s, want 401 with %q", rec.Code, rec.Body.String(), tc.want)
+			}
+		})
+	}
+}
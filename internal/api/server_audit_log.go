@@ -0,0 +1,40 @@
+package api
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/audit"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// applyAuditLogConfig publishes the audit-log block to the process-wide audit trail.
+func applyAuditLogConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	audit.Default().Apply(cfg.AuditLog, resolveAuditLogDir(cfg))
+}
+
+// resolveAuditLogDir returns the file backend directory: audit-log.dir when set,
+// otherwise "audit" under WRITABLE_PATH or the auth directory.
+func resolveAuditLogDir(cfg *config.Config) string {
+	if dir := strings.TrimSpace(cfg.AuditLog.Dir); dir != "" {
+		resolved, errResolve := util.ResolveAuthDir(dir)
+		if errResolve != nil {
+			log.Warnf("audit log: failed to resolve dir %q: %v", dir, errResolve)
+			return dir
+		}
+		return resolved
+	}
+	if base := util.WritablePath(); base != "" {
+		return filepath.Join(base, "audit")
+	}
+	authDir, errResolve := util.ResolveAuthDir(cfg.AuthDir)
+	if errResolve != nil || authDir == "" {
+		return "audit"
+	}
+	return filepath.Join(authDir, "audit")
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/batch"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// applyBatchConfig publishes the batch block to the process-wide batch manager.
+func applyBatchConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	batch.Default().Apply(cfg.Batch, resolveBatchDir(cfg))
+}
+
+// resolveBatchDir returns the file store directory: batch.dir when set, otherwise
+// "batches" under the auth directory so git and object token stores can mirror it.
+func resolveBatchDir(cfg *config.Config) string {
+	if dir := strings.TrimSpace(cfg.Batch.Dir); dir != "" {
+		resolved, errResolve := util.ResolveAuthDir(dir)
+		if errResolve != nil {
+			log.Warnf("batch: failed to resolve dir %q: %v", dir, errResolve)
+			return dir
+		}
+		return resolved
+	}
+	authDir, errResolve := util.ResolveAuthDir(cfg.AuthDir)
+	if errResolve != nil || authDir == "" {
+		return "batches"
+	}
+	return filepath.Join(authDir, "batches")
+}
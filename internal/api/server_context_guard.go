@@ -0,0 +1,14 @@
+package api
+
+import (
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/contextguard"
+)
+
+// applyContextGuardConfig publishes the context-guard block to the process-wide guard.
+func applyContextGuardConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	contextguard.Default().Apply(cfg.ContextGuard)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/metrics"
+)
+
+// metricsAvailabilityMiddleware hides the metrics route unless metrics are enabled
+// for the listener, so disabled deployments answer 404 before client authentication.
+func (s *Server) metricsAvailabilityMiddleware(listener string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !metrics.Serves(listener) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.Next()
+	}
+}
+
+// metricsAuthSource returns the core auth manager sampled by scrape-time metrics.
+func (s *Server) metricsAuthSource() metrics.AuthSource {
+	if s == nil || s.handlers == nil || s.handlers.AuthManager == nil {
+		return nil
+	}
+	return s.handlers.AuthManager
+}
+
+// applyMetricsConfig publishes the metrics block to the metrics package.
+func applyMetricsConfig(oldCfg, newCfg *config.Config) {
+	if newCfg == nil {
+		return
+	}
+	if oldCfg != nil && oldCfg.Metrics == newCfg.Metrics {
+		return
+	}
+	metrics.Apply(newCfg.Metrics)
+}
@@ -3,15 +3,20 @@ package api
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/access/keypolicy"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/access/ratelimit"
 	codexlive "github.com/router-for-me/CLIProxyAPI/v7/internal/client/codex/live"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/home"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/safemode"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/tracing"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v7/sdk/access"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var corsExposedResponseHeaders = []string{
@@ -60,7 +65,7 @@ func (s *Server) homeHeartbeatMiddleware() gin.HandlerFunc {
 }
 
 func (s *Server) exampleAPIKeySafeModeRequired(cfg *config.Config) bool {
-	return s != nil && s.exampleAPIKeySafeModeEnabled && cfg != nil && safemode.HasExampleAPIKeys(cfg.APIKeys)
+	return s != nil && s.exampleAPIKeySafeModeEnabled && cfg != nil && safemode.HasExampleAPIKeys(config.ClientAPIKeyValues(cfg.APIKeys))
 }
 
 func (s *Server) exampleAPIKeySafeModeMiddleware() gin.HandlerFunc {
@@ -96,7 +101,7 @@ func (s *Server) serveExampleAPIKeyWarningPage(c *gin.Context) {
 	cfg := s.cfg
 	var keys []string
 	if cfg != nil {
-		keys = safemode.ExampleAPIKeys(cfg.APIKeys)
+		keys = safemode.ExampleAPIKeys(config.ClientAPIKeyValues(cfg.APIKeys))
 	}
 	c.Header("Content-Type", "text/html; charset=utf-8")
 	c.Header("Cache-Control", "no-store")
@@ -163,15 +168,38 @@ func accessAuthMiddleware(manager *sdkaccess.Manager, realtimeError bool) gin.Ha
 			return
 		}
 
-		result, err := manager.Authenticate(c.Request.Context(), c.Request)
-		if err == nil {
+		// The span covers authentication and the key policy and rate limit checks.
+		// Later handler spans stay linked to it through the request context.
+		ctx, span := tracing.Start(c.Request.Context(), "access.authenticate")
+		c.Request = c.Request.WithContext(ctx)
+		result, err := manager.Authenticate(ctx, c.Request)
+		if err != nil {
+			span.SetAttributes(attribute.Int("http.response.status_code", err.HTTPStatusCode()))
+			tracing.End(span, err)
+		} else {
 			if result != nil {
+				span.SetAttributes(attribute.String("cliproxy.access.provider", result.Provider))
 				c.Set("userApiKey", result.Principal)
 				c.Set("accessProvider", result.Provider)
 				if len(result.Metadata) > 0 {
 					c.Set("accessMetadata", result.Metadata)
 				}
+				now := time.Now()
+				if errPolicy := keypolicy.Default().Admit(result.Principal, now); errPolicy != nil {
+					tracing.End(span, errPolicy)
+					writeAPIKeyPolicyRejection(c, errPolicy, realtimeError)
+					return
+				}
+				release, rejection := ratelimit.Default().Acquire(result.Principal, now)
+				if rejection != nil {
+					span.SetAttributes(attribute.Int("http.response.status_code", http.StatusTooManyRequests))
+					span.End()
+					writeAPIKeyLimitRejection(c, rejection, realtimeError)
+					return
+				}
+				defer release()
 			}
+			span.End()
 			c.Next()
 			return
 		}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	log "github.com/sirupsen/logrus"
+)
+
+// applyModelCatalogConfig publishes the model-catalog block to the models catalog updater.
+func applyModelCatalogConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	settings, errSettings := cfg.ModelCatalog.Settings()
+	if errSettings != nil {
+		log.Errorf("model-catalog: %v; catalog refreshes are blocked until the block is fixed", errSettings)
+	}
+	registry.ConfigureModelCatalog(settings)
+}
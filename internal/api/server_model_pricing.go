@@ -0,0 +1,14 @@
+package api
+
+import (
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/pricing"
+)
+
+// applyModelPricingConfig publishes the model-pricing overrides to the process-wide price table.
+func applyModelPricingConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	pricing.Default().Apply(cfg)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/access/ratelimit"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+// applyAPIKeyLimitsConfig publishes the api-key-limits block to the process-wide limiter.
+func applyAPIKeyLimitsConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	ratelimit.Default().SetLimits(cfg.APIKeyLimits)
+}
+
+// writeAPIKeyLimitRejection answers a refused request with a 429 shaped like the
+// error body of the API family the client is speaking.
+func writeAPIKeyLimitRejection(c *gin.Context, rejection *ratelimit.Rejection, realtimeError bool) {
+	c.Header("Retry-After", strconv.FormatInt(rejection.RetryAfterSeconds(), 10))
+	message := rejection.Message()
+
+	path := ""
+	if c.Request != nil && c.Request.URL != nil {
+		path = c.Request.URL.Path
+	}
+	switch {
+	case !realtimeError && isClaudeAPIPath(path):
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"type": "error",
+			"error": gin.H{
+				"type":    "rate_limit_error",
+				"message": message,
+			},
+		})
+	case !realtimeError && isGeminiAPIPath(path):
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": gin.H{
+			"code":    http.StatusTooManyRequests,
+			"message": message,
+			"status":  "RESOURCE_EXHAUSTED",
+		}})
+	default:
+		errorType, code := "rate_limit_error", "rate_limit_exceeded"
+		if rejection.IsBudget() {
+			errorType, code = "insufficient_quota", "insufficient_quota"
+		}
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": gin.H{
+			"message": message,
+			"type":    errorType,
+			"param":   nil,
+			"code":    code,
+		}})
+	}
+}
+
+func isClaudeAPIPath(path string) bool {
+	return path == "/v1/messages" || strings.HasPrefix(path, "/v1/messages/")
+}
+
+func isGeminiAPIPath(path string) bool {
+	return path == "/v1beta" || strings.HasPrefix(path, "/v1beta/")
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/access/ratelimit"
+	proxyconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+func TestAuthMiddlewareAPIKeyLimitRejectionsAreProviderShaped(t *testing.T) {
+	server := newTestServer(t)
+	applyAPIKeyLimitsConfig(&proxyconfig.Config{SDKConfig: proxyconfig.SDKConfig{
+		APIKeyLimits: []proxyconfig.APIKeyLimit{{APIKey: "test-key", RequestsPerMinute: 1}},
+	}})
+	t.Cleanup(func() { ratelimit.Default().SetLimits(nil) })
+
+	cases := []struct {
+		name       string
+		path       string
+		assertBody func(t *testing.T, body map[string]any)
+	}{
+		{
+			name: "claude",
+			path: "/v1/messages",
+			assertBody: func(t *testing.T, body map[string]any) {
+				errBody, _ := body["error"].(map[string]any)
+				if body["type"] != "error" || errBody["type"] != "rate_limit_error" {
+					t.Fatalf("claude body = %v", body)
+				}
+			},
+		},
+		{
+			name: "gemini",
+			path: "/v1beta/models/gemini-2.5-pro:generateContent",
+			assertBody: func(t *testing.T, body map[string]any) {
+				errBody, _ := body["error"].(map[string]any)
+				if errBody["status"] != "RESOURCE_EXHAUSTED" || errBody["code"] != float64(http.StatusTooManyRequests) {
+					t.Fatalf("gemini body = %v", body)
+				}
+			},
+		},
+		{
+			name: "openai",
+			path: "/v1/chat/completions",
+			assertBody: func(t *testing.T, body map[string]any) {
+				errBody, _ := body["error"].(map[string]any)
+				if errBody["type"] != "rate_limit_error" || errBody["code"] != "rate_limit_exceeded" {
+					t.Fatalf("openai body = %v", body)
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var last *httptest.ResponseRecorder
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(`{}`))
+				req.Header.Set("Authorization", "Bearer test-key")
+				last = httptest.NewRecorder()
+				server.engine.ServeHTTP(last, req)
+			}
+			if last.Code != http.StatusTooManyRequests {
+				t.Fatalf("status = %d, want %d; body=%s", last.Code, http.StatusTooManyRequests, last.Body.String())
+			}
+			if last.Header().Get("Retry-After") == "" {
+				t.Fatal("Retry-After header missing")
+			}
+			var body map[string]any
+			if errUnmarshal := json.Unmarshal(last.Body.Bytes(), &body); errUnmarshal != nil {
+				t.Fatalf("decode body: %v; body=%s", errUnmarshal, last.Body.String())
+			}
+			tc.assertBody(t, body)
+		})
+	}
+}
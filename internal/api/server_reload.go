@@ -110,6 +110,18 @@ func (s *Server) UpdateClientsContext(ctx context.Context, cfg *config.Config) b
 	}
 
 	applySignatureCacheConfig(oldCfg, cfg)
+	applyMetricsConfig(oldCfg, cfg)
+	applyTracingConfig(cfg)
+	applyClientAPIKeysConfig(cfg)
+	applyUsageLedgerConfig(cfg)
+	applyModelPricingConfig(cfg)
+	applyModelCatalogConfig(cfg)
+	applyAuditLogConfig(cfg)
+	applyBatchConfig(cfg)
+	applyResponseCacheConfig(cfg)
+	applyResponsesStoreConfig(cfg)
+	applyContextGuardConfig(cfg)
+	applyAPIKeyLimitsConfig(cfg)
 
 	if s.handlers != nil && s.handlers.AuthManager != nil {
 		s.handlers.AuthManager.SetRetryConfig(cfg.RequestRetry, time.Duration(cfg.MaxRetryInterval)*time.Second, cfg.MaxRetryCredentials)
@@ -122,9 +134,9 @@ func (s *Server) UpdateClientsContext(ctx context.Context, cfg *config.Config) b
 
 	prevSecretEmpty := true
 	if oldCfg != nil {
-		prevSecretEmpty = oldCfg.RemoteManagement.SecretKey == ""
+		prevSecretEmpty = !oldCfg.RemoteManagement.HasManagementKey()
 	}
-	newSecretEmpty := cfg.RemoteManagement.SecretKey == ""
+	newSecretEmpty := !cfg.RemoteManagement.HasManagementKey()
 	if s.envManagementSecret {
 		s.registerManagementRoutes()
 		if s.managementRoutesEnabled.CompareAndSwap(false, true) {
@@ -210,6 +222,8 @@ func (s *Server) UpdateClientsContext(ctx context.Context, cfg *config.Config) b
 	codexAPIKeyCount := len(cfg.CodexKey)
 	xaiAPIKeyCount := len(cfg.XAIKey)
 	vertexAICompatCount := len(cfg.VertexCompatAPIKey)
+	bedrockKeyCount := len(cfg.BedrockKey)
+	azureOpenAICount := len(cfg.AzureOpenAIKey)
 	openAICompatCount := 0
 	for i := range cfg.OpenAICompatibility {
 		entry := cfg.OpenAICompatibility[i]
@@ -219,8 +233,8 @@ func (s *Server) UpdateClientsContext(ctx context.Context, cfg *config.Config) b
 		openAICompatCount += len(entry.APIKeyEntries)
 	}
 
-	total := authEntries + geminiAPIKeyCount + interactionsAPIKeyCount + claudeAPIKeyCount + codexAPIKeyCount + xaiAPIKeyCount + vertexAICompatCount + openAICompatCount
-	fmt.Printf("server clients and configuration updated: %d clients (%d auth entries + %d Gemini API keys + %d Interactions API keys + %d Claude API keys + %d Codex keys + %d xAI keys + %d Vertex-compat + %d OpenAI-compat)\n",
+	total := authEntries + geminiAPIKeyCount + interactionsAPIKeyCount + claudeAPIKeyCount + codexAPIKeyCount + xaiAPIKeyCount + vertexAICompatCount + bedrockKeyCount + azureOpenAICount + openAICompatCount
+	fmt.Printf("server clients and configuration updated: %d clients (%d auth entries + %d Gemini API keys + %d Interactions API keys + %d Claude API keys + %d Codex keys + %d xAI keys + %d Vertex-compat + %d Bedrock + %d Azure OpenAI + %d OpenAI-compat)\n",
 		total,
 		authEntries,
 		geminiAPIKeyCount,
@@ -229,6 +243,8 @@ func (s *Server) UpdateClientsContext(ctx context.Context, cfg *config.Config) b
 		codexAPIKeyCount,
 		xaiAPIKeyCount,
 		vertexAICompatCount,
+		bedrockKeyCount,
+		azureOpenAICount,
 		openAICompatCount,
 	)
 	return ctx.Err() == nil
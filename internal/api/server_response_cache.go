@@ -0,0 +1,14 @@
+package api
+
+import (
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/responsecache"
+)
+
+// applyResponseCacheConfig publishes the response-cache block to the process-wide cache.
+func applyResponseCacheConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	responsecache.Default().Apply(cfg.ResponseCache)
+}
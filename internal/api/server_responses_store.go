@@ -0,0 +1,40 @@
+package api
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/responsestore"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// applyResponsesStoreConfig publishes the responses-store block to the process-wide store.
+func applyResponsesStoreConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	responsestore.Default().Apply(cfg.ResponsesStore, resolveResponsesStoreDir(cfg))
+}
+
+// resolveResponsesStoreDir returns the file backend directory: responses-store.dir when
+// set, otherwise "responses" under WRITABLE_PATH or the auth directory.
+func resolveResponsesStoreDir(cfg *config.Config) string {
+	if dir := strings.TrimSpace(cfg.ResponsesStore.Dir); dir != "" {
+		resolved, errResolve := util.ResolveAuthDir(dir)
+		if errResolve != nil {
+			log.Warnf("responses store: failed to resolve dir %q: %v", dir, errResolve)
+			return dir
+		}
+		return resolved
+	}
+	if base := util.WritablePath(); base != "" {
+		return filepath.Join(base, "responses")
+	}
+	authDir, errResolve := util.ResolveAuthDir(cfg.AuthDir)
+	if errResolve != nil || authDir == "" {
+		return "responses"
+	}
+	return filepath.Join(authDir, "responses")
+}
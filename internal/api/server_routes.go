@@ -14,18 +14,22 @@ import (
 
 	"github.com/gin-gonic/gin"
 	managementHandlers "github.com/router-for-me/CLIProxyAPI/v7/internal/api/handlers/management"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/batch"
 	claudemodels "github.com/router-for-me/CLIProxyAPI/v7/internal/client/claude/models"
 	codexlive "github.com/router-for-me/CLIProxyAPI/v7/internal/client/codex/live"
 	codexmodels "github.com/router-for-me/CLIProxyAPI/v7/internal/client/codex/models"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/client/grokbuild"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/clienterror"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/home"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/metrics"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/runtime/executor/helps"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers/claude"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers/gemini"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers/ollama"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers/openai"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
@@ -51,12 +55,18 @@ func (s *Server) setupRoutes() {
 	s.engine.GET("/healthz", healthzHandler)
 	s.engine.HEAD("/healthz", healthzHandler)
 
+	metricsHandler := gin.WrapH(metrics.Handler(config.MetricsListenerAPI, s.metricsAuthSource))
+	s.engine.GET("/metrics", s.metricsAvailabilityMiddleware(config.MetricsListenerAPI), AuthMiddleware(s.accessManager), metricsHandler)
+	s.engine.HEAD("/metrics", s.metricsAvailabilityMiddleware(config.MetricsListenerAPI), AuthMiddleware(s.accessManager), metricsHandler)
+
 	s.engine.GET("/management.html", s.serveManagementControlPanel)
 	openaiHandlers := openai.NewOpenAIAPIHandler(s.handlers)
 	geminiHandlers := gemini.NewGeminiAPIHandler(s.handlers)
 	claudeCodeHandlers := claude.NewClaudeCodeAPIHandler(s.handlers)
 	openaiResponsesHandlers := openai.NewOpenAIResponsesAPIHandler(s.handlers)
+	ollamaHandlers := ollama.NewOllamaAPIHandler(s.handlers)
 	s.codexLiveHandler = codexlive.NewHandler(s.handlers.AuthManager, s.cfg)
+	batch.Default().SetExecutor(openaiHandlers)
 
 	// OpenAI compatible API routes
 	v1 := s.engine.Group("/v1")
@@ -65,6 +75,19 @@ func (s *Server) setupRoutes() {
 		v1.GET("/models", s.unifiedModelsHandler(openaiHandlers, claudeCodeHandlers))
 		v1.POST("/chat/completions", openaiHandlers.ChatCompletions)
 		v1.POST("/completions", openaiHandlers.Completions)
+		v1.POST("/embeddings", openaiHandlers.Embeddings)
+		v1.POST("/audio/transcriptions", openaiHandlers.AudioTranscriptions)
+		v1.POST("/audio/translations", openaiHandlers.AudioTranslations)
+		v1.POST("/audio/speech", openaiHandlers.AudioSpeech)
+		v1.POST("/files", openaiHandlers.FilesUpload)
+		v1.GET("/files", openaiHandlers.FilesList)
+		v1.GET("/files/:file_id", openaiHandlers.FilesRetrieve)
+		v1.GET("/files/:file_id/content", openaiHandlers.FilesContent)
+		v1.DELETE("/files/:file_id", openaiHandlers.FilesDelete)
+		v1.POST("/batches", openaiHandlers.BatchesCreate)
+		v1.GET("/batches", openaiHandlers.BatchesList)
+		v1.GET("/batches/:batch_id", openaiHandlers.BatchesRetrieve)
+		v1.POST("/batches/:batch_id/cancel", openaiHandlers.BatchesCancel)
 		v1.POST("/images/generations", openaiHandlers.ImagesGenerations)
 		v1.POST("/images/edits", openaiHandlers.ImagesEdits)
 		v1.POST("/videos", openaiHandlers.XAIVideosGenerations)
@@ -77,6 +100,9 @@ func (s *Server) setupRoutes() {
 		v1.GET("/responses", openaiResponsesHandlers.ResponsesWebsocket)
 		v1.POST("/responses", openaiResponsesHandlers.Responses)
 		v1.POST("/responses/compact", openaiResponsesHandlers.Compact)
+		v1.GET("/responses/:id", openaiResponsesHandlers.GetResponse)
+		v1.DELETE("/responses/:id", openaiResponsesHandlers.DeleteResponse)
+		v1.GET("/responses/:id/input_items", openaiResponsesHandlers.ResponseInputItems)
 		v1.POST("/alpha/search", s.codexAlphaSearch)
 		v1.POST("/live", s.codexLiveHandler.Handle)
 		v1.GET("/live/:call_id", s.codexLiveHandler.HandleSideband)
@@ -127,6 +153,17 @@ func (s *Server) setupRoutes() {
 		v1beta.GET("/models/*action", s.geminiGetHandler(geminiHandlers))
 	}
 
+	// Ollama compatible API routes
+	ollamaAPI := s.engine.Group("/api")
+	ollamaAPI.Use(AuthMiddleware(s.accessManager))
+	{
+		ollamaAPI.POST("/chat", ollamaHandlers.Chat)
+		ollamaAPI.POST("/generate", ollamaHandlers.Generate)
+		ollamaAPI.GET("/tags", ollamaHandlers.Tags)
+		ollamaAPI.POST("/show", ollamaHandlers.Show)
+		ollamaAPI.GET("/version", ollamaHandlers.Version)
+	}
+
 	// Root endpoint
 	s.engine.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -646,7 +683,7 @@ func (s *Server) handleGrokModels(c *gin.Context) {
 		}
 		models = grokModelsFromHomeEntries(entries)
 	} else {
-		models = grokModelsFromRegistryInfos(registry.GetGlobalRegistry().GetAvailableModelInfos())
+		models = grokModelsFromRegistryInfos(s.handlers.FilterModelInfosForAPIKey(c, registry.GetGlobalRegistry().GetAvailableModelInfos()))
 	}
 	c.JSON(http.StatusOK, grokbuild.BuildResponse(models))
 }
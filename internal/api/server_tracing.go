@@ -0,0 +1,50 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/tracing"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// tracingMiddleware opens the server span of each request, continuing any trace named
+// by an incoming W3C traceparent header. Requests pass through untouched while tracing
+// is disabled.
+func tracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !tracing.Enabled() {
+			c.Next()
+			return
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		ctx, span := tracing.StartRequest(c.Request.Context(), c.Request.Header, c.Request.Method+" "+route,
+			attribute.String("http.request.method", c.Request.Method),
+			attribute.String("http.route", route),
+		)
+		c.Request = c.Request.WithContext(ctx)
+		defer span.End()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, "")
+		}
+	}
+}
+
+// applyTracingConfig publishes the tracing block to the tracing package.
+func applyTracingConfig(newCfg *config.Config) {
+	if newCfg == nil {
+		return
+	}
+	if errApply := tracing.Apply(newCfg.Tracing); errApply != nil {
+		log.Errorf("tracing: failed to apply config: %v", errApply)
+	}
+}
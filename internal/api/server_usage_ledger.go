@@ -0,0 +1,40 @@
+package api
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/usageledger"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// applyUsageLedgerConfig publishes the usage-ledger block to the process-wide ledger.
+func applyUsageLedgerConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	usageledger.Default().Apply(cfg.UsageLedger, resolveUsageLedgerDir(cfg))
+}
+
+// resolveUsageLedgerDir returns the file backend directory: usage-ledger.dir when set,
+// otherwise "usage" under WRITABLE_PATH or the auth directory.
+func resolveUsageLedgerDir(cfg *config.Config) string {
+	if dir := strings.TrimSpace(cfg.UsageLedger.Dir); dir != "" {
+		resolved, errResolve := util.ResolveAuthDir(dir)
+		if errResolve != nil {
+			log.Warnf("usage ledger: failed to resolve dir %q: %v", dir, errResolve)
+			return dir
+		}
+		return resolved
+	}
+	if base := util.WritablePath(); base != "" {
+		return filepath.Join(base, "usage")
+	}
+	authDir, errResolve := util.ResolveAuthDir(cfg.AuthDir)
+	if errResolve != nil || authDir == "" {
+		return "usage"
+	}
+	return filepath.Join(authDir, "usage")
+}
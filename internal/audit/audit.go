@@ -0,0 +1,250 @@
+// Package audit keeps an append-only record of configuration and credential changes.
+//
+// Every mutating management API call is recorded with the identity of the management
+// key that made it, and config file edits made outside the management API are recorded
+// when the file watcher picks them up. Events are written straight to the configured
+// store so a crash never loses an acknowledged change.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// SourceManagement marks events recorded for management API calls.
+	SourceManagement = "management"
+	// SourceFileWatcher marks config edits detected by the file watcher.
+	SourceFileWatcher = "file-watcher"
+
+	// maxManagedConfigs bounds the digests of config files written by the management API.
+	maxManagedConfigs = 16
+)
+
+// ErrDisabled is returned by Query when the audit log is not enabled.
+var ErrDisabled = errors.New("audit log is disabled")
+
+// Event is one audit record.
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"`
+	// Actor is the name of the management key that made the change.
+	Actor    string `json:"actor,omitempty"`
+	Role     string `json:"role,omitempty"`
+	RemoteIP string `json:"remote_ip,omitempty"`
+	Method   string `json:"method,omitempty"`
+	// Route is the matched route pattern; Path is the requested path.
+	Route  string            `json:"route,omitempty"`
+	Path   string            `json:"path,omitempty"`
+	Params map[string]string `json:"params,omitempty"`
+	Status int               `json:"status,omitempty"`
+	// Changes lists the redacted config changes, one "field: old -> new" line each.
+	Changes []string `json:"changes,omitempty"`
+}
+
+// Store persists audit events.
+type Store interface {
+	// Append persists events.
+	Append(ctx context.Context, events []Event) error
+	// Scan calls fn for every event with from <= Timestamp < to in timestamp order.
+	Scan(ctx context.Context, from, to time.Time, fn func(Event) error) error
+	// DeleteBefore drops every event older than cutoff.
+	DeleteBefore(ctx context.Context, cutoff time.Time) error
+}
+
+// StoreProvider exposes a backend-specific audit store.
+type StoreProvider interface {
+	AuditLogStore() Store
+}
+
+// Trail records audit events into the selected store.
+type Trail struct {
+	mu            sync.Mutex
+	cfg           config.AuditLogConfig
+	dir           string
+	external      Store
+	file          *FileStore
+	store         Store
+	prunedDay     time.Time
+	warnedBackend bool
+	managed       [][sha256.Size]byte
+}
+
+// New constructs a disabled trail.
+func New() *Trail {
+	return &Trail{}
+}
+
+var defaultTrail = New()
+
+// Default returns the process-wide trail.
+func Default() *Trail {
+	return defaultTrail
+}
+
+// Apply publishes the audit-log block. dir is the resolved directory of the file backend.
+func (t *Trail) Apply(cfg config.AuditLogConfig, dir string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.cfg = cfg
+	t.dir = dir
+	t.selectStoreLocked()
+	t.mu.Unlock()
+}
+
+// SetExternalStore attaches the store used by the postgres backend. Passing nil detaches it.
+func (t *Trail) SetExternalStore(store Store) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.external = store
+	t.warnedBackend = false
+	t.selectStoreLocked()
+	t.mu.Unlock()
+}
+
+// Enabled reports whether events are currently recorded.
+func (t *Trail) Enabled() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.store != nil
+}
+
+// Record appends event to the store. Failures are logged rather than returned so an
+// unavailable store never fails the change being recorded.
+func (t *Trail) Record(ctx context.Context, event Event) {
+	if t == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	event.Timestamp = event.Timestamp.UTC()
+
+	t.mu.Lock()
+	store := t.store
+	cfg := t.cfg
+	today := startOfDay(event.Timestamp)
+	prune := store != nil && cfg.RetentionDays > 0 && !t.prunedDay.Equal(today)
+	if prune {
+		t.prunedDay = today
+	}
+	t.mu.Unlock()
+	if store == nil {
+		return
+	}
+
+	if errAppend := store.Append(ctx, []Event{event}); errAppend != nil {
+		log.Warnf("audit log: failed to persist event for %s %s: %v", event.Method, event.Path, errAppend)
+	}
+	if prune {
+		if errDelete := store.DeleteBefore(ctx, today.AddDate(0, 0, -cfg.RetentionDays)); errDelete != nil {
+			log.Warnf("audit log: retention failed: %v", errDelete)
+		}
+	}
+	if cfg.ForwardToHome {
+		forward(event)
+	}
+}
+
+// NoteManagedConfig remembers content as a config file the management API is about to
+// write, so the file watcher does not record the same change a second time. Call it
+// before writing the file; the watcher may read the new content as soon as it lands.
+func (t *Trail) NoteManagedConfig(content []byte) {
+	if t == nil || len(content) == 0 {
+		return
+	}
+	sum := sha256.Sum256(content)
+	t.mu.Lock()
+	if len(t.managed) >= maxManagedConfigs {
+		t.managed = t.managed[1:]
+	}
+	t.managed = append(t.managed, sum)
+	t.mu.Unlock()
+}
+
+// ConsumeManagedConfig reports whether content was noted by NoteManagedConfig and forgets it.
+func (t *Trail) ConsumeManagedConfig(content []byte) bool {
+	if t == nil || len(content) == 0 {
+		return false
+	}
+	sum := sha256.Sum256(content)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.managed {
+		if t.managed[i] == sum {
+			t.managed = append(t.managed[:i], t.managed[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// forward writes event to the application log, from where the Home app-log forwarder
+// picks it up.
+func forward(event Event) {
+	actor := event.Actor
+	if actor == "" {
+		actor = event.Source
+	}
+	target := event.Path
+	if target == "" {
+		target = "config"
+	}
+	action := strings.TrimSpace(event.Method + " " + target)
+	fields := log.Fields{"audit": true, "source": event.Source, "actor": actor}
+	if event.RemoteIP != "" {
+		fields["remote_ip"] = event.RemoteIP
+	}
+	summary := strings.Join(event.Changes, "; ")
+	if summary == "" {
+		summary = "no config changes"
+	}
+	if event.Status != 0 {
+		fields["status"] = event.Status
+	}
+	log.WithFields(fields).Infof("audit: %s by %s: %s", action, actor, summary)
+}
+
+func (t *Trail) selectStoreLocked() {
+	var next Store
+	if t.cfg.Enable {
+		if t.cfg.EffectiveBackend() == config.AuditLogBackendPostgres && t.external != nil {
+			next = t.external
+		} else {
+			if t.cfg.EffectiveBackend() == config.AuditLogBackendPostgres && !t.warnedBackend {
+				log.Warn("audit log: postgres backend requested but the Postgres store is not in use; falling back to local files")
+				t.warnedBackend = true
+			}
+			if t.file == nil || t.file.Dir() != t.dir {
+				t.file = NewFileStore(t.dir)
+			}
+			next = t.file
+		}
+	}
+	if next != t.store {
+		t.prunedDay = time.Time{}
+	}
+	t.store = next
+}
+
+func startOfDay(t time.Time) time.Time {
+	utc := t.UTC()
+	return time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+func TestTrailRecordAndQueryNewestFirst(t *testing.T) {
+	dir := t.TempDir()
+	trail := New()
+	if _, errQuery := trail.Query(context.Background(), Query{}); !errors.Is(errQuery, ErrDisabled) {
+		t.Fatalf("Query() on disabled trail error = %v, want ErrDisabled", errQuery)
+	}
+	trail.Apply(config.AuditLogConfig{Enable: true}, dir)
+
+	base := time.Now().UTC().Add(-48 * time.Hour)
+	trail.Record(context.Background(), Event{Timestamp: base, Source: SourceManagement, Actor: "ops", Method: "PUT", Route: "/v0/management/routing/strategy", Changes: []string{"routing.strategy: round-robin -> fill-first"}})
+	trail.Record(context.Background(), Event{Timestamp: base.Add(24 * time.Hour), Source: SourceFileWatcher, Changes: []string{"debug: false -> true"}})
+	trail.Record(context.Background(), Event{Timestamp: base.Add(25 * time.Hour), Source: SourceManagement, Actor: "dash", Method: "DELETE", Route: "/v0/management/auth-files"})
+
+	events, errQuery := trail.Query(context.Background(), Query{})
+	if errQuery != nil {
+		t.Fatalf("Query() error = %v", errQuery)
+	}
+	if len(events) != 3 || events[0].Actor != "dash" || events[2].Actor != "ops" {
+		t.Fatalf("events = %+v, want newest first", events)
+	}
+
+	limited, _ := trail.Query(context.Background(), Query{Limit: 2})
+	if len(limited) != 2 || limited[0].Actor != "dash" || limited[1].Source != SourceFileWatcher {
+		t.Fatalf("limited events = %+v", limited)
+	}
+	filtered, _ := trail.Query(context.Background(), Query{Route: "routing", Method: "put"})
+	if len(filtered) != 1 || filtered[0].Actor != "ops" {
+		t.Fatalf("filtered events = %+v", filtered)
+	}
+	windowed, _ := trail.Query(context.Background(), Query{From: base.Add(time.Hour)})
+	if len(windowed) != 2 {
+		t.Fatalf("windowed events = %+v", windowed)
+	}
+}
+
+func TestTrailRetentionRemovesOldSegments(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+	old := time.Now().UTC().AddDate(0, 0, -10)
+	if errAppend := store.Append(context.Background(), []Event{{Timestamp: old, Source: SourceManagement}}); errAppend != nil {
+		t.Fatalf("Append() error = %v", errAppend)
+	}
+
+	trail := New()
+	trail.Apply(config.AuditLogConfig{Enable: true, RetentionDays: 3}, dir)
+	trail.Record(context.Background(), Event{Source: SourceManagement, Actor: "ops"})
+
+	if _, errStat := os.Stat(filepath.Join(dir, segmentPrefix+old.Format(segmentDateLayout)+segmentSuffix)); !os.IsNotExist(errStat) {
+		t.Fatalf("expired segment still present: %v", errStat)
+	}
+	events, _ := trail.Query(context.Background(), Query{})
+	if len(events) != 1 || events[0].Actor != "ops" {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestTrailManagedConfigIsConsumedOnce(t *testing.T) {
+	trail := New()
+	content := []byte("debug: true\n")
+	trail.NoteManagedConfig(content)
+	if trail.ConsumeManagedConfig([]byte("debug: false\n")) {
+		t.Fatal("unrelated content reported as managed")
+	}
+	if !trail.ConsumeManagedConfig(content) {
+		t.Fatal("noted content not reported as managed")
+	}
+	if trail.ConsumeManagedConfig(content) {
+		t.Fatal("managed content reported twice")
+	}
+}
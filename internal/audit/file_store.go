@@ -0,0 +1,218 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	segmentPrefix     = "audit-"
+	segmentSuffix     = ".jsonl"
+	segmentDateLayout = "2006-01-02"
+)
+
+var _ Store = (*FileStore)(nil)
+
+// FileStore keeps audit events in one append-only JSONL segment per UTC day, so
+// retention rotates whole files instead of rewriting them.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore returns a store that writes segments under dir. The directory is
+// created on the first append.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Dir returns the segment directory.
+func (s *FileStore) Dir() string {
+	if s == nil {
+		return ""
+	}
+	return s.dir
+}
+
+// Append writes events to the segments of their days.
+func (s *FileStore) Append(_ context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if errMkdir := os.MkdirAll(s.dir, 0o700); errMkdir != nil {
+		return fmt.Errorf("audit log: create directory: %w", errMkdir)
+	}
+	for start := 0; start < len(events); {
+		day := startOfDay(events[start].Timestamp)
+		end := start + 1
+		for end < len(events) && startOfDay(events[end].Timestamp).Equal(day) {
+			end++
+		}
+		if errWrite := s.appendSegment(day, events[start:end]); errWrite != nil {
+			return errWrite
+		}
+		start = end
+	}
+	return nil
+}
+
+// Scan reads every segment overlapping [from, to) in day order.
+func (s *FileStore) Scan(ctx context.Context, from, to time.Time, fn func(Event) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days, errDays := s.segmentDays()
+	if errDays != nil {
+		return errDays
+	}
+	for _, day := range days {
+		if !to.IsZero() && !day.Before(to) {
+			break
+		}
+		if !from.IsZero() && !day.AddDate(0, 0, 1).After(from) {
+			continue
+		}
+		if ctx != nil {
+			if errCtx := ctx.Err(); errCtx != nil {
+				return errCtx
+			}
+		}
+		errRead := s.readSegment(day, func(event Event) error {
+			if !from.IsZero() && event.Timestamp.Before(from) {
+				return nil
+			}
+			if !to.IsZero() && !event.Timestamp.Before(to) {
+				return nil
+			}
+			return fn(event)
+		})
+		if errRead != nil {
+			return errRead
+		}
+	}
+	return nil
+}
+
+// DeleteBefore removes the segments of days that end on or before cutoff's day. Events of
+// the day containing cutoff are kept until that whole day falls out of retention.
+func (s *FileStore) DeleteBefore(_ context.Context, cutoff time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days, errDays := s.segmentDays()
+	if errDays != nil {
+		return errDays
+	}
+	cutoffDay := startOfDay(cutoff)
+	for _, day := range days {
+		if !day.Before(cutoffDay) {
+			break
+		}
+		if errRemove := os.Remove(s.segmentPath(day)); errRemove != nil && !errors.Is(errRemove, fs.ErrNotExist) {
+			return fmt.Errorf("audit log: remove segment: %w", errRemove)
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) segmentPath(day time.Time) string {
+	return filepath.Join(s.dir, segmentPrefix+day.Format(segmentDateLayout)+segmentSuffix)
+}
+
+func (s *FileStore) segmentDays() ([]time.Time, error) {
+	items, errRead := os.ReadDir(s.dir)
+	if errRead != nil {
+		if errors.Is(errRead, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("audit log: list segments: %w", errRead)
+	}
+	days := make([]time.Time, 0, len(items))
+	for _, item := range items {
+		name := item.Name()
+		if item.IsDir() || !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		day, errParse := time.Parse(segmentDateLayout, strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix))
+		if errParse != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days, nil
+}
+
+func (s *FileStore) appendSegment(day time.Time, events []Event) (err error) {
+	file, errOpen := os.OpenFile(s.segmentPath(day), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if errOpen != nil {
+		return fmt.Errorf("audit log: open segment: %w", errOpen)
+	}
+	defer func() {
+		if errClose := file.Close(); errClose != nil && err == nil {
+			err = fmt.Errorf("audit log: close segment: %w", errClose)
+		}
+	}()
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for i := range events {
+		if errEncode := encoder.Encode(events[i]); errEncode != nil {
+			return fmt.Errorf("audit log: encode event: %w", errEncode)
+		}
+	}
+	if errFlush := writer.Flush(); errFlush != nil {
+		return fmt.Errorf("audit log: write segment: %w", errFlush)
+	}
+	if errSync := file.Sync(); errSync != nil {
+		return fmt.Errorf("audit log: sync segment: %w", errSync)
+	}
+	return nil
+}
+
+func (s *FileStore) readSegment(day time.Time, fn func(Event) error) (err error) {
+	file, errOpen := os.Open(s.segmentPath(day))
+	if errOpen != nil {
+		if errors.Is(errOpen, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("audit log: open segment: %w", errOpen)
+	}
+	defer func() {
+		if errClose := file.Close(); errClose != nil && err == nil {
+			err = fmt.Errorf("audit log: close segment: %w", errClose)
+		}
+	}()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var event Event
+		// A torn trailing line from a crash is skipped rather than failing the whole segment.
+		if errUnmarshal := json.Unmarshal(line, &event); errUnmarshal != nil {
+			continue
+		}
+		if errFn := fn(event); errFn != nil {
+			return errFn
+		}
+	}
+	if errScan := scanner.Err(); errScan != nil {
+		return fmt.Errorf("audit log: read segment: %w", errScan)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// DefaultQueryLimit caps the number of events returned when Query.Limit is zero.
+const DefaultQueryLimit = 200
+
+// Query selects audit events.
+type Query struct {
+	// From and To bound the event timestamps as [From, To). Zero values are open.
+	From time.Time
+	To   time.Time
+	// Filters restrict events to exact values. Empty filters match everything.
+	Source string
+	Actor  string
+	Method string
+	// Route matches events whose route pattern or path contains it.
+	Route string
+	// Limit caps the number of returned events, newest first. 0 uses DefaultQueryLimit.
+	Limit int
+}
+
+// Query returns the newest events matching q, newest first.
+func (t *Trail) Query(ctx context.Context, q Query) ([]Event, error) {
+	if t == nil {
+		return nil, ErrDisabled
+	}
+	t.mu.Lock()
+	store := t.store
+	t.mu.Unlock()
+	if store == nil {
+		return nil, ErrDisabled
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+	// Events arrive oldest first; keep the last limit matches in a ring.
+	ring := make([]Event, 0, limit)
+	next := 0
+	errScan := store.Scan(ctx, q.From, q.To, func(event Event) error {
+		if !q.matches(event) {
+			return nil
+		}
+		if len(ring) < limit {
+			ring = append(ring, event)
+			return nil
+		}
+		ring[next] = event
+		next = (next + 1) % limit
+		return nil
+	})
+	if errScan != nil {
+		return nil, errScan
+	}
+	out := make([]Event, 0, len(ring))
+	for i := len(ring) - 1; i >= 0; i-- {
+		out = append(out, ring[(next+i)%len(ring)])
+	}
+	return out, nil
+}
+
+func (q Query) matches(event Event) bool {
+	if q.Source != "" && !strings.EqualFold(q.Source, event.Source) {
+		return false
+	}
+	if q.Actor != "" && q.Actor != event.Actor {
+		return false
+	}
+	if q.Method != "" && !strings.EqualFold(q.Method, event.Method) {
+		return false
+	}
+	if q.Route != "" && !strings.Contains(event.Route, q.Route) && !strings.Contains(event.Path, q.Route) {
+		return false
+	}
+	return true
+}
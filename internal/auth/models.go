@@ -2,6 +2,15 @@
 // It includes interfaces and implementations for token storage and authentication methods.
 package auth
 
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/authcrypt"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/misc"
+)
+
 // TokenStorage defines the interface for storing authentication tokens.
 // Implementations of this interface should provide methods to persist
 // authentication tokens to a file system location.
@@ -15,3 +24,34 @@ type TokenStorage interface {
 	//   - error: An error if the save operation fails, nil otherwise
 	SaveTokenToFile(authFilePath string) error
 }
+
+// TokenMarshaler is implemented by token storages that can render their auth file
+// content in memory, so stores can seal it before anything reaches the disk.
+type TokenMarshaler interface {
+	// MarshalToken returns the content SaveTokenToFile would write.
+	MarshalToken() ([]byte, error)
+}
+
+// SaveToken writes the auth file of storage to path in the configured at-rest form.
+// Content of a TokenMarshaler is sealed in memory and written atomically; other
+// storages write through SaveTokenToFile and the file is sealed afterwards.
+func SaveToken(storage TokenStorage, path string) error {
+	if marshaler, ok := storage.(TokenMarshaler); ok {
+		misc.LogSavingCredentials(path)
+		data, errMarshal := marshaler.MarshalToken()
+		if errMarshal != nil {
+			return errMarshal
+		}
+		if errMkdir := os.MkdirAll(filepath.Dir(path), 0o700); errMkdir != nil {
+			return fmt.Errorf("create auth directory: %w", errMkdir)
+		}
+		return authcrypt.WriteFile(path, data, 0o600)
+	}
+	if errSave := storage.SaveTokenToFile(path); errSave != nil {
+		return errSave
+	}
+	if _, errSeal := authcrypt.SealFile(path); errSeal != nil {
+		return fmt.Errorf("encrypt auth file: %w", errSeal)
+	}
+	return nil
+}
@@ -9,7 +9,6 @@ import (
 	"path/filepath"
 
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/misc"
-	log "github.com/sirupsen/logrus"
 )
 
 // VertexCredentialStorage stores the service account JSON for Vertex AI access.
@@ -48,37 +47,37 @@ func (s *VertexCredentialStorage) SetMetadata(meta map[string]any) {
 // It ensures the parent directory exists and logs the operation for transparency.
 func (s *VertexCredentialStorage) SaveTokenToFile(authFilePath string) error {
 	misc.LogSavingCredentials(authFilePath)
+	data, err := s.MarshalToken()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(authFilePath), 0o700); err != nil {
+		return fmt.Errorf("vertex credential: create directory failed: %w", err)
+	}
+	if err = os.WriteFile(authFilePath, data, 0o600); err != nil {
+		return fmt.Errorf("vertex credential: write file failed: %w", err)
+	}
+	return nil
+}
+
+// MarshalToken returns the credential file content with the injected metadata merged in.
+func (s *VertexCredentialStorage) MarshalToken() ([]byte, error) {
 	if s == nil {
-		return fmt.Errorf("vertex credential: storage is nil")
+		return nil, fmt.Errorf("vertex credential: storage is nil")
 	}
 	if s.ServiceAccount == nil {
-		return fmt.Errorf("vertex credential: service account content is empty")
+		return nil, fmt.Errorf("vertex credential: service account content is empty")
 	}
 	// Ensure we tag the file with the provider type.
 	s.Type = "vertex"
 
-	if err := os.MkdirAll(filepath.Dir(authFilePath), 0o700); err != nil {
-		return fmt.Errorf("vertex credential: create directory failed: %w", err)
-	}
-
 	data, errMerge := misc.MergeMetadata(s, s.Metadata)
 	if errMerge != nil {
-		return fmt.Errorf("vertex credential: merge metadata failed: %w", errMerge)
+		return nil, fmt.Errorf("vertex credential: merge metadata failed: %w", errMerge)
 	}
-
-	f, err := os.Create(authFilePath)
-	if err != nil {
-		return fmt.Errorf("vertex credential: create file failed: %w", err)
-	}
-	defer func() {
-		if errClose := f.Close(); errClose != nil {
-			log.Errorf("vertex credential: failed to close file: %v", errClose)
-		}
-	}()
-	enc := json.NewEncoder(f)
-	enc.SetIndent("", "  ")
-	if err = enc.Encode(data); err != nil {
-		return fmt.Errorf("vertex credential: encode failed: %w", err)
+	raw, errMarshal := json.MarshalIndent(data, "", "  ")
+	if errMarshal != nil {
+		return nil, fmt.Errorf("vertex credential: encode failed: %w", errMarshal)
 	}
-	return nil
+	return append(raw, '\n'), nil
 }
@@ -0,0 +1,407 @@
+// Package authcrypt implements envelope encryption at rest for OAuth auth files.
+//
+// Each file is sealed with a fresh random data key using AES-256-GCM, and the data
+// key is wrapped with the primary key of the configured keyring. Sealed files stay
+// JSON objects so every token store can mirror them unchanged; readers open them
+// transparently and plaintext files keep working. Older keys remain in the keyring
+// for decryption, which makes key rotation a matter of adding a new primary key and
+// re-sealing the directory.
+package authcrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// envelopeVersion marks sealed auth files and versions the envelope layout.
+const envelopeVersion = "cpa-auth-v1"
+
+// keySize is the AES-256 key length required for every keyring entry.
+const keySize = 32
+
+var (
+	errNoKeyring  = errors.New("authcrypt: auth file is encrypted but no encryption key is configured")
+	errUnknownKey = errors.New("authcrypt: auth file is encrypted with an unknown key")
+)
+
+// envelope is the on-disk JSON form of a sealed auth file.
+type envelope struct {
+	Version    string `json:"cpa_encrypted"`
+	KeyID      string `json:"kid"`
+	WrappedKey string `json:"wrapped_key"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// Key is one keyring entry.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Keyring holds the keys used to seal and open auth files. The first key is the
+// primary key used for sealing; every key can open files sealed with it.
+type Keyring struct {
+	keys []Key
+	// openOnly keeps files in plaintext while still opening sealed ones.
+	openOnly bool
+}
+
+var current atomic.Pointer[Keyring]
+
+// ParseKeyring parses keys separated by commas or newlines. Each entry is either
+// "id:base64-key" or a bare base64 key, whose id is derived from the key. Keys
+// must decode to 32 bytes. Blank lines and lines starting with '#' are ignored.
+func ParseKeyring(spec string) (*Keyring, error) {
+	fields := strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' })
+	ring := &Keyring{}
+	seen := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" || strings.HasPrefix(field, "#") {
+			continue
+		}
+		id, encoded, hasID := strings.Cut(field, ":")
+		if !hasID {
+			id, encoded = "", field
+		}
+		secret, errDecode := decodeKey(strings.TrimSpace(encoded))
+		if errDecode != nil {
+			return nil, errDecode
+		}
+		id = strings.TrimSpace(id)
+		if id == "" {
+			sum := sha256.Sum256(secret)
+			id = hex.EncodeToString(sum[:4])
+		}
+		if _, exists := seen[id]; exists {
+			return nil, fmt.Errorf("authcrypt: duplicate key id %q", id)
+		}
+		seen[id] = struct{}{}
+		ring.keys = append(ring.keys, Key{ID: id, Secret: secret})
+	}
+	if len(ring.keys) == 0 {
+		return nil, fmt.Errorf("authcrypt: no encryption keys configured")
+	}
+	return ring, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if secret, errDecode := encoding.DecodeString(encoded); errDecode == nil {
+			if len(secret) != keySize {
+				return nil, fmt.Errorf("authcrypt: encryption key must be %d bytes, got %d", keySize, len(secret))
+			}
+			return secret, nil
+		}
+	}
+	return nil, fmt.Errorf("authcrypt: encryption key is not valid base64")
+}
+
+// OpenOnly returns a copy of the keyring that opens sealed files but writes plaintext.
+func (k *Keyring) OpenOnly() *Keyring {
+	if k == nil {
+		return nil
+	}
+	return &Keyring{keys: k.keys, openOnly: true}
+}
+
+// PrimaryKeyID returns the id of the key used for sealing.
+func (k *Keyring) PrimaryKeyID() string {
+	if k == nil || len(k.keys) == 0 {
+		return ""
+	}
+	return k.keys[0].ID
+}
+
+func (k *Keyring) sealing() bool {
+	return k != nil && !k.openOnly && len(k.keys) > 0
+}
+
+func (k *Keyring) key(id string) ([]byte, bool) {
+	if k == nil {
+		return nil, false
+	}
+	for _, key := range k.keys {
+		if key.ID == id {
+			return key.Secret, true
+		}
+	}
+	return nil, false
+}
+
+// SetKeyring installs the process-wide keyring. A nil keyring disables encryption.
+func SetKeyring(k *Keyring) {
+	current.Store(k)
+}
+
+// CurrentKeyring returns the process-wide keyring, or nil when none is configured.
+func CurrentKeyring() *Keyring {
+	return current.Load()
+}
+
+// Enabled reports whether new auth files are sealed.
+func Enabled() bool {
+	return current.Load().sealing()
+}
+
+// IsSealed reports whether data is a sealed auth file.
+func IsSealed(data []byte) bool {
+	_, ok := parseEnvelope(data)
+	return ok
+}
+
+func parseEnvelope(data []byte) (envelope, bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' || !bytes.Contains(trimmed, []byte(`"cpa_encrypted"`)) {
+		return envelope{}, false
+	}
+	var env envelope
+	if errUnmarshal := json.Unmarshal(trimmed, &env); errUnmarshal != nil || env.Version != envelopeVersion {
+		return envelope{}, false
+	}
+	return env, true
+}
+
+// Seal encrypts plain with the primary key. It returns plain unchanged when
+// encryption is disabled or plain is already sealed.
+func Seal(plain []byte) ([]byte, error) {
+	ring := current.Load()
+	if !ring.sealing() || len(bytes.TrimSpace(plain)) == 0 || IsSealed(plain) {
+		return plain, nil
+	}
+	primary := ring.keys[0]
+	dataKey := make([]byte, keySize)
+	if _, errRand := rand.Read(dataKey); errRand != nil {
+		return nil, fmt.Errorf("authcrypt: generate data key: %w", errRand)
+	}
+	wrapped, errWrap := sealGCM(primary.Secret, dataKey, []byte(envelopeVersion+":"+primary.ID))
+	if errWrap != nil {
+		return nil, errWrap
+	}
+	nonce, ciphertext, errSeal := sealGCMParts(dataKey, plain, []byte(envelopeVersion))
+	if errSeal != nil {
+		return nil, errSeal
+	}
+	return json.Marshal(envelope{
+		Version:    envelopeVersion,
+		KeyID:      primary.ID,
+		WrappedKey: base64.StdEncoding.EncodeToString(wrapped),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+	})
+}
+
+// Open decrypts a sealed auth file. Plaintext data is returned unchanged.
+func Open(data []byte) ([]byte, error) {
+	env, ok := parseEnvelope(data)
+	if !ok {
+		return data, nil
+	}
+	ring := current.Load()
+	if ring == nil {
+		return nil, errNoKeyring
+	}
+	secret, found := ring.key(env.KeyID)
+	if !found {
+		return nil, fmt.Errorf("%w %q", errUnknownKey, env.KeyID)
+	}
+	wrapped, errWrapped := base64.StdEncoding.DecodeString(env.WrappedKey)
+	nonce, errNonce := base64.StdEncoding.DecodeString(env.Nonce)
+	ciphertext, errCiphertext := base64.StdEncoding.DecodeString(env.Ciphertext)
+	if errDecode := errors.Join(errWrapped, errNonce, errCiphertext); errDecode != nil {
+		return nil, fmt.Errorf("authcrypt: malformed envelope: %w", errDecode)
+	}
+	dataKey, errUnwrap := openGCM(secret, wrapped, []byte(envelopeVersion+":"+env.KeyID))
+	if errUnwrap != nil {
+		return nil, fmt.Errorf("authcrypt: unwrap data key: %w", errUnwrap)
+	}
+	aead, errAEAD := newGCM(dataKey)
+	if errAEAD != nil {
+		return nil, errAEAD
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("authcrypt: malformed envelope nonce")
+	}
+	plain, errOpen := aead.Open(nil, nonce, ciphertext, []byte(envelopeVersion))
+	if errOpen != nil {
+		return nil, fmt.Errorf("authcrypt: decrypt auth file: %w", errOpen)
+	}
+	return plain, nil
+}
+
+// Current reports whether data is stored in the configured form: sealed with the
+// primary key when encryption is enabled, plaintext otherwise.
+func Current(data []byte) bool {
+	env, sealed := parseEnvelope(data)
+	ring := current.Load()
+	if !ring.sealing() {
+		return !sealed
+	}
+	return sealed && env.KeyID == ring.PrimaryKeyID()
+}
+
+// UpToDate reports whether existing already holds plain in the configured form, so
+// a store can skip rewriting it. equal compares the opened bytes with plain.
+func UpToDate(existing, plain []byte, equal func(a, b []byte) bool) bool {
+	if !Current(existing) {
+		return false
+	}
+	opened, errOpen := Open(existing)
+	return errOpen == nil && equal(opened, plain)
+}
+
+// ReadFile reads an auth file and opens it when sealed.
+func ReadFile(path string) ([]byte, error) {
+	data, errRead := os.ReadFile(path)
+	if errRead != nil {
+		return nil, errRead
+	}
+	return Open(data)
+}
+
+// WriteFile seals plain in memory when encryption is enabled and replaces path with
+// the result through a temporary file, so plaintext never reaches the disk and
+// readers never see a partial file.
+func WriteFile(path string, plain []byte, perm os.FileMode) error {
+	data, errSeal := Seal(plain)
+	if errSeal != nil {
+		return errSeal
+	}
+	return writeFileAtomic(path, data, perm)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it over path.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, errCreate := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if errCreate != nil {
+		return errCreate
+	}
+	tmpPath := tmp.Name()
+	_, errWrite := tmp.Write(data)
+	if errClose := tmp.Close(); errWrite == nil {
+		errWrite = errClose
+	}
+	if errWrite == nil {
+		errWrite = os.Chmod(tmpPath, perm)
+	}
+	if errWrite == nil {
+		errWrite = os.Rename(tmpPath, path)
+	}
+	if errWrite != nil {
+		_ = os.Remove(tmpPath)
+		return errWrite
+	}
+	return nil
+}
+
+// SealFile rewrites an auth file written in plaintext by a token storage so it is
+// stored in the configured form. It returns false when the file was already current.
+func SealFile(path string) (bool, error) {
+	data, errRead := os.ReadFile(path)
+	if errRead != nil {
+		return false, errRead
+	}
+	if len(bytes.TrimSpace(data)) == 0 || Current(data) {
+		return false, nil
+	}
+	plain, errOpen := Open(data)
+	if errOpen != nil {
+		return false, errOpen
+	}
+	converted, errSeal := Seal(plain)
+	if errSeal != nil {
+		return false, errSeal
+	}
+	info, errStat := os.Stat(path)
+	if errStat != nil {
+		return false, errStat
+	}
+	if errWrite := writeFileAtomic(path, converted, info.Mode().Perm()); errWrite != nil {
+		return false, errWrite
+	}
+	return true, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, errCipher := aes.NewCipher(key)
+	if errCipher != nil {
+		return nil, fmt.Errorf("authcrypt: %w", errCipher)
+	}
+	aead, errGCM := cipher.NewGCM(block)
+	if errGCM != nil {
+		return nil, fmt.Errorf("authcrypt: %w", errGCM)
+	}
+	return aead, nil
+}
+
+func sealGCMParts(key, plain, additional []byte) ([]byte, []byte, error) {
+	aead, errAEAD := newGCM(key)
+	if errAEAD != nil {
+		return nil, nil, errAEAD
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, errRand := rand.Read(nonce); errRand != nil {
+		return nil, nil, fmt.Errorf("authcrypt: generate nonce: %w", errRand)
+	}
+	return nonce, aead.Seal(nil, nonce, plain, additional), nil
+}
+
+// sealGCM returns nonce||ciphertext.
+func sealGCM(key, plain, additional []byte) ([]byte, error) {
+	nonce, ciphertext, errSeal := sealGCMParts(key, plain, additional)
+	if errSeal != nil {
+		return nil, errSeal
+	}
+	return append(nonce, ciphertext...), nil
+}
+
+func openGCM(key, sealed, additional []byte) ([]byte, error) {
+	aead, errAEAD := newGCM(key)
+	if errAEAD != nil {
+		return nil, errAEAD
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("sealed key too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additional)
+}
+
+// ConvertDir rewrites the auth JSON files directly in dir into the configured form:
+// sealed with the primary key when encryption is enabled, plaintext when the
+// keyring is open-only. Subdirectories belong to other subsystems and are left
+// alone, matching the files the watcher loads. It returns the paths it rewrote.
+func ConvertDir(dir string) ([]string, error) {
+	entries, errRead := os.ReadDir(dir)
+	if errRead != nil {
+		return nil, errRead
+	}
+	var changed []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		rewritten, errSeal := SealFile(path)
+		if errSeal != nil {
+			return changed, fmt.Errorf("%s: %w", path, errSeal)
+		}
+		if rewritten {
+			changed = append(changed, path)
+		}
+	}
+	return changed, nil
+}
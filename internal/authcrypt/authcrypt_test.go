@@ -0,0 +1,135 @@
+package authcrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKeyring(t *testing.T, spec string) *Keyring {
+	t.Helper()
+	ring, errParse := ParseKeyring(spec)
+	if errParse != nil {
+		t.Fatalf("ParseKeyring() error = %v", errParse)
+	}
+	SetKeyring(ring)
+	t.Cleanup(func() { SetKeyring(nil) })
+	return ring
+}
+
+func testKey(fill byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, keySize))
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	testKeyring(t, "k1:"+testKey(1))
+	plain := []byte(`{"type":"claude","access_token":"secret-token"}`)
+
+	sealed, errSeal := Seal(plain)
+	if errSeal != nil {
+		t.Fatalf("Seal() error = %v", errSeal)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, []byte("secret-token")) {
+		t.Fatalf("Seal() = %s, want an envelope without the token", sealed)
+	}
+	opened, errOpen := Open(sealed)
+	if errOpen != nil {
+		t.Fatalf("Open() error = %v", errOpen)
+	}
+	if !bytes.Equal(opened, plain) {
+		t.Fatalf("Open() = %s, want %s", opened, plain)
+	}
+	if passthrough, _ := Open(plain); !bytes.Equal(passthrough, plain) {
+		t.Fatalf("Open(plaintext) = %s, want it unchanged", passthrough)
+	}
+
+	SetKeyring(nil)
+	if _, errOpen = Open(sealed); errOpen == nil {
+		t.Fatal("Open() without a keyring succeeded")
+	}
+}
+
+func TestSealFileRotatesToPrimaryKey(t *testing.T) {
+	testKeyring(t, "old:"+testKey(1))
+	path := filepath.Join(t.TempDir(), "claude.json")
+	plain := []byte(`{"type":"claude"}`)
+	if errWrite := WriteFile(path, plain, 0o600); errWrite != nil {
+		t.Fatalf("WriteFile() error = %v", errWrite)
+	}
+
+	testKeyring(t, "new:"+testKey(2)+"\nold:"+testKey(1))
+	data, errRead := os.ReadFile(path)
+	if errRead != nil {
+		t.Fatalf("read auth file: %v", errRead)
+	}
+	if Current(data) {
+		t.Fatal("file sealed with the old key is reported as current")
+	}
+	rewritten, errSealFile := SealFile(path)
+	if errSealFile != nil || !rewritten {
+		t.Fatalf("SealFile() = %v, %v; want true, nil", rewritten, errSealFile)
+	}
+	data, _ = os.ReadFile(path)
+	if !Current(data) {
+		t.Fatalf("SealFile() left %s, want it sealed with the new key", data)
+	}
+	opened, errOpen := ReadFile(path)
+	if errOpen != nil || !bytes.Equal(opened, plain) {
+		t.Fatalf("ReadFile() = %s, %v; want %s", opened, errOpen, plain)
+	}
+}
+
+func TestConvertDirDecryptsWithOpenOnlyKeyring(t *testing.T) {
+	ring := testKeyring(t, testKey(3))
+	dir := t.TempDir()
+	plain := []byte(`{"type":"codex"}`)
+	if errWrite := WriteFile(filepath.Join(dir, "codex.json"), plain, 0o600); errWrite != nil {
+		t.Fatalf("WriteFile() error = %v", errWrite)
+	}
+	if errWrite := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0o600); errWrite != nil {
+		t.Fatalf("write notes: %v", errWrite)
+	}
+
+	SetKeyring(ring.OpenOnly())
+	changed, errConvert := ConvertDir(dir)
+	if errConvert != nil {
+		t.Fatalf("ConvertDir() error = %v", errConvert)
+	}
+	if len(changed) != 1 {
+		t.Fatalf("ConvertDir() changed %v, want one file", changed)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "codex.json"))
+	if !bytes.Equal(data, plain) {
+		t.Fatalf("decrypted file = %s, want %s", data, plain)
+	}
+}
+
+func TestConvertDirSkipsSubdirectories(t *testing.T) {
+	testKeyring(t, testKey(4))
+	dir := t.TempDir()
+	plain := []byte(`{"type":"codex"}`)
+	if errWrite := os.WriteFile(filepath.Join(dir, "codex.json"), plain, 0o600); errWrite != nil {
+		t.Fatalf("write auth file: %v", errWrite)
+	}
+	nested := filepath.Join(dir, "responses", "record.json")
+	if errMkdir := os.MkdirAll(filepath.Dir(nested), 0o700); errMkdir != nil {
+		t.Fatalf("create subdirectory: %v", errMkdir)
+	}
+	if errWrite := os.WriteFile(nested, plain, 0o600); errWrite != nil {
+		t.Fatalf("write nested file: %v", errWrite)
+	}
+
+	changed, errConvert := ConvertDir(dir)
+	if errConvert != nil {
+		t.Fatalf("ConvertDir() error = %v", errConvert)
+	}
+	if len(changed) != 1 || changed[0] != filepath.Join(dir, "codex.json") {
+		t.Fatalf("ConvertDir() changed %v, want only codex.json", changed)
+	}
+	data, _ := os.ReadFile(nested)
+	if !bytes.Equal(data, plain) {
+		t.Fatalf("nested file = %s, want it untouched", data)
+	}
+}
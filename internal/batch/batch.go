@@ -0,0 +1,201 @@
+// Package batch implements the OpenAI Files and Batch APIs on top of the proxy's own
+// request path.
+//
+// Uploaded JSONL files and batch jobs are kept in a Store. Running jobs replay every
+// input line through an Executor on a pool of workers sized from the number of
+// credentials able to serve the job's models. Throttled lines (cooling credentials,
+// API key limits) pause the whole job until the reported retry time and are retried,
+// so jobs slow down with the auth manager instead of filling the error file.
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Batch job statuses, as reported by the OpenAI Batch API.
+const (
+	StatusValidating = "validating"
+	StatusFailed     = "failed"
+	StatusInProgress = "in_progress"
+	StatusFinalizing = "finalizing"
+	StatusCompleted  = "completed"
+	StatusExpired    = "expired"
+	StatusCancelling = "cancelling"
+	StatusCancelled  = "cancelled"
+)
+
+// File purposes.
+const (
+	PurposeBatch       = "batch"
+	PurposeBatchOutput = "batch_output"
+)
+
+const (
+	// DefaultCompletionWindow is used when a batch is created without a completion window.
+	DefaultCompletionWindow = "24h"
+	// maxCompletionWindow bounds the accepted completion windows.
+	maxCompletionWindow = 7 * 24 * time.Hour
+	// maxRequestsPerBatch matches the OpenAI limit on input lines.
+	maxRequestsPerBatch = 50000
+)
+
+var (
+	// ErrDisabled is returned when the batch subsystem is not enabled.
+	ErrDisabled = errors.New("batch API is disabled")
+	// ErrNotFound is returned for unknown or foreign files and batches.
+	ErrNotFound = errors.New("not found")
+)
+
+// supportedEndpoints lists the endpoints batch lines may target.
+var supportedEndpoints = map[string]struct{}{
+	"/v1/chat/completions": {},
+	"/v1/responses":        {},
+	"/v1/embeddings":       {},
+}
+
+// SupportedEndpoint reports whether batches may target endpoint.
+func SupportedEndpoint(endpoint string) bool {
+	_, ok := supportedEndpoints[endpoint]
+	return ok
+}
+
+// File is an uploaded or generated file.
+type File struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	// Owner is the client API key that uploaded the file or created its batch.
+	Owner string `json:"owner,omitempty"`
+}
+
+// Job is a batch job in the OpenAI Batch object shape.
+type Job struct {
+	ID               string            `json:"id"`
+	Object           string            `json:"object"`
+	Endpoint         string            `json:"endpoint"`
+	Errors           *JobErrors        `json:"errors,omitempty"`
+	InputFileID      string            `json:"input_file_id"`
+	CompletionWindow string            `json:"completion_window"`
+	Status           string            `json:"status"`
+	OutputFileID     string            `json:"output_file_id,omitempty"`
+	ErrorFileID      string            `json:"error_file_id,omitempty"`
+	CreatedAt        int64             `json:"created_at"`
+	InProgressAt     int64             `json:"in_progress_at,omitempty"`
+	ExpiresAt        int64             `json:"expires_at,omitempty"`
+	FinalizingAt     int64             `json:"finalizing_at,omitempty"`
+	CompletedAt      int64             `json:"completed_at,omitempty"`
+	FailedAt         int64             `json:"failed_at,omitempty"`
+	ExpiredAt        int64             `json:"expired_at,omitempty"`
+	CancellingAt     int64             `json:"cancelling_at,omitempty"`
+	CancelledAt      int64             `json:"cancelled_at,omitempty"`
+	RequestCounts    RequestCounts     `json:"request_counts"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+	// Owner is the client API key that created the batch. Lines are executed on its behalf.
+	Owner string `json:"owner,omitempty"`
+}
+
+// RequestCounts tracks the progress of a job.
+type RequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// JobErrors lists the validation errors of a failed job.
+type JobErrors struct {
+	Object string     `json:"object"`
+	Data   []JobError `json:"data"`
+}
+
+// JobError is one validation error of the input file.
+type JobError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Param   string `json:"param,omitempty"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// Terminal reports whether the job reached a final status.
+func (j Job) Terminal() bool {
+	switch j.Status {
+	case StatusFailed, StatusCompleted, StatusExpired, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// Result is one line of a job's output or error file.
+type Result struct {
+	CustomID string          `json:"custom_id"`
+	Error    bool            `json:"error,omitempty"`
+	Line     json.RawMessage `json:"line"`
+}
+
+// Store persists files, jobs and per-job results. Lookups of unknown ids return ErrNotFound.
+type Store interface {
+	// SaveFile creates or replaces a file and its content.
+	SaveFile(ctx context.Context, file File, content []byte) error
+	// GetFile returns the metadata of a file.
+	GetFile(ctx context.Context, id string) (File, error)
+	// FileContent returns the content of a file.
+	FileContent(ctx context.Context, id string) ([]byte, error)
+	// ListFiles returns every file.
+	ListFiles(ctx context.Context) ([]File, error)
+	// DeleteFile removes a file and its content.
+	DeleteFile(ctx context.Context, id string) error
+	// SaveJob creates or replaces a job.
+	SaveJob(ctx context.Context, job Job) error
+	// GetJob returns a job.
+	GetJob(ctx context.Context, id string) (Job, error)
+	// ListJobs returns every job.
+	ListJobs(ctx context.Context) ([]Job, error)
+	// AppendResults records finished lines of a running job.
+	AppendResults(ctx context.Context, jobID string, results []Result) error
+	// Results returns the finished lines of a job in completion order.
+	Results(ctx context.Context, jobID string) ([]Result, error)
+	// DeleteResults drops the finished lines of a job once its output files exist.
+	DeleteResults(ctx context.Context, jobID string) error
+}
+
+// StoreProvider exposes a backend-specific batch store.
+type StoreProvider interface {
+	BatchStore() Store
+}
+
+// Persister mirrors files written by the FileStore to a remote token store.
+type Persister interface {
+	PersistAuthFiles(ctx context.Context, message string, paths ...string) error
+}
+
+// Outcome is the result of executing one batch line.
+type Outcome struct {
+	// StatusCode is the HTTP status the request would have returned.
+	StatusCode int
+	// Body is the response body, or the error body for failed requests.
+	Body []byte
+	// RequestID identifies the request in the proxy logs.
+	RequestID string
+	// Throttled marks requests refused because credentials are cooling down or the
+	// API key is rate limited. They are retried after RetryAfter.
+	Throttled  bool
+	RetryAfter time.Duration
+}
+
+// Executor runs batch lines through the proxy's request path on behalf of owner.
+type Executor interface {
+	ExecuteBatchRequest(ctx context.Context, owner, endpoint string, body []byte) Outcome
+}
+
+// ExecutorFunc adapts a function to Executor.
+type ExecutorFunc func(ctx context.Context, owner, endpoint string, body []byte) Outcome
+
+// ExecuteBatchRequest calls f.
+func (f ExecutorFunc) ExecuteBatchRequest(ctx context.Context, owner, endpoint string, body []byte) Outcome {
+	return f(ctx, owner, endpoint, body)
+}
@@ -0,0 +1,346 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// File names deliberately avoid the .json extension so token stores and the auth
+// watcher never mistake them for credentials when dir lives under the auth directory.
+const (
+	filesDir      = "files"
+	jobsDir       = "jobs"
+	metaSuffix    = ".meta"
+	contentSuffix = ".jsonl"
+	stateSuffix   = ".state"
+	resultsSuffix = ".results.jsonl"
+)
+
+var _ Store = (*FileStore)(nil)
+
+// FileStore keeps files and jobs as plain files under a directory.
+type FileStore struct {
+	dir   string
+	mu    sync.Mutex
+	dirty map[string]struct{}
+}
+
+// NewFileStore returns a store rooted at dir. Directories are created on first write.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir, dirty: make(map[string]struct{})}
+}
+
+// Dir returns the root directory.
+func (s *FileStore) Dir() string {
+	if s == nil {
+		return ""
+	}
+	return s.dir
+}
+
+// TakeDirty returns the paths written or removed since the previous call.
+func (s *FileStore) TakeDirty() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.dirty) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(s.dirty))
+	for path := range s.dirty {
+		paths = append(paths, path)
+	}
+	s.dirty = make(map[string]struct{})
+	sort.Strings(paths)
+	return paths
+}
+
+// SaveFile writes the metadata and content of file.
+func (s *FileStore) SaveFile(_ context.Context, file File, content []byte) error {
+	if errID := validateID(file.ID); errID != nil {
+		return errID
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	meta, errMarshal := json.Marshal(file)
+	if errMarshal != nil {
+		return fmt.Errorf("batch: encode file: %w", errMarshal)
+	}
+	if errWrite := s.writeLocked(s.path(filesDir, file.ID+contentSuffix), content); errWrite != nil {
+		return errWrite
+	}
+	return s.writeLocked(s.path(filesDir, file.ID+metaSuffix), meta)
+}
+
+// GetFile reads the metadata of a file.
+func (s *FileStore) GetFile(_ context.Context, id string) (File, error) {
+	var file File
+	if errID := validateID(id); errID != nil {
+		return file, ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	errRead := s.readJSONLocked(s.path(filesDir, id+metaSuffix), &file)
+	return file, errRead
+}
+
+// FileContent reads the content of a file.
+func (s *FileStore) FileContent(_ context.Context, id string) ([]byte, error) {
+	if errID := validateID(id); errID != nil {
+		return nil, ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, errRead := os.ReadFile(s.path(filesDir, id+contentSuffix))
+	if errRead != nil {
+		if errors.Is(errRead, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("batch: read file content: %w", errRead)
+	}
+	return data, nil
+}
+
+// ListFiles reads the metadata of every file.
+func (s *FileStore) ListFiles(_ context.Context) ([]File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files := make([]File, 0)
+	errList := s.listLocked(filesDir, metaSuffix, func(path string) error {
+		var file File
+		if errRead := s.readJSONLocked(path, &file); errRead != nil {
+			return errRead
+		}
+		files = append(files, file)
+		return nil
+	})
+	return files, errList
+}
+
+// DeleteFile removes the metadata and content of a file.
+func (s *FileStore) DeleteFile(_ context.Context, id string) error {
+	if errID := validateID(id); errID != nil {
+		return ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metaPath := s.path(filesDir, id+metaSuffix)
+	if _, errStat := os.Stat(metaPath); errors.Is(errStat, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	if errRemove := s.removeLocked(s.path(filesDir, id+contentSuffix)); errRemove != nil {
+		return errRemove
+	}
+	return s.removeLocked(metaPath)
+}
+
+// SaveJob writes the state of job.
+func (s *FileStore) SaveJob(_ context.Context, job Job) error {
+	if errID := validateID(job.ID); errID != nil {
+		return errID
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, errMarshal := json.Marshal(job)
+	if errMarshal != nil {
+		return fmt.Errorf("batch: encode job: %w", errMarshal)
+	}
+	return s.writeLocked(s.path(jobsDir, job.ID+stateSuffix), state)
+}
+
+// GetJob reads the state of a job.
+func (s *FileStore) GetJob(_ context.Context, id string) (Job, error) {
+	var job Job
+	if errID := validateID(id); errID != nil {
+		return job, ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	errRead := s.readJSONLocked(s.path(jobsDir, id+stateSuffix), &job)
+	return job, errRead
+}
+
+// ListJobs reads the state of every job.
+func (s *FileStore) ListJobs(_ context.Context) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]Job, 0)
+	errList := s.listLocked(jobsDir, stateSuffix, func(path string) error {
+		var job Job
+		if errRead := s.readJSONLocked(path, &job); errRead != nil {
+			return errRead
+		}
+		jobs = append(jobs, job)
+		return nil
+	})
+	return jobs, errList
+}
+
+// AppendResults appends results to the job's result log.
+func (s *FileStore) AppendResults(_ context.Context, jobID string, results []Result) error {
+	if len(results) == 0 {
+		return nil
+	}
+	if errID := validateID(jobID); errID != nil {
+		return errID
+	}
+	var buf bytes.Buffer
+	for _, result := range results {
+		line, errMarshal := json.Marshal(result)
+		if errMarshal != nil {
+			return fmt.Errorf("batch: encode result: %w", errMarshal)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := s.path(jobsDir, jobID+resultsSuffix)
+	if errMkdir := os.MkdirAll(filepath.Dir(path), 0o700); errMkdir != nil {
+		return fmt.Errorf("batch: create directory: %w", errMkdir)
+	}
+	f, errOpen := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if errOpen != nil {
+		return fmt.Errorf("batch: open results: %w", errOpen)
+	}
+	_, errWrite := f.Write(buf.Bytes())
+	errClose := f.Close()
+	if errWrite != nil {
+		return fmt.Errorf("batch: append results: %w", errWrite)
+	}
+	if errClose != nil {
+		return fmt.Errorf("batch: close results: %w", errClose)
+	}
+	s.dirty[path] = struct{}{}
+	return nil
+}
+
+// Results reads the job's result log. A torn final line left by a crash is skipped.
+func (s *FileStore) Results(_ context.Context, jobID string) ([]Result, error) {
+	if errID := validateID(jobID); errID != nil {
+		return nil, ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, errOpen := os.Open(s.path(jobsDir, jobID+resultsSuffix))
+	if errOpen != nil {
+		if errors.Is(errOpen, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("batch: open results: %w", errOpen)
+	}
+	defer func() { _ = f.Close() }()
+
+	results := make([]Result, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var result Result
+		if errUnmarshal := json.Unmarshal(line, &result); errUnmarshal != nil {
+			continue
+		}
+		results = append(results, result)
+	}
+	if errScan := scanner.Err(); errScan != nil {
+		return nil, fmt.Errorf("batch: read results: %w", errScan)
+	}
+	return results, nil
+}
+
+// DeleteResults removes the job's result log.
+func (s *FileStore) DeleteResults(_ context.Context, jobID string) error {
+	if errID := validateID(jobID); errID != nil {
+		return ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.removeLocked(s.path(jobsDir, jobID+resultsSuffix))
+}
+
+func (s *FileStore) path(kind, name string) string {
+	return filepath.Join(s.dir, kind, name)
+}
+
+// writeLocked replaces path atomically.
+func (s *FileStore) writeLocked(path string, data []byte) error {
+	if errMkdir := os.MkdirAll(filepath.Dir(path), 0o700); errMkdir != nil {
+		return fmt.Errorf("batch: create directory: %w", errMkdir)
+	}
+	tmp := path + ".tmp"
+	if errWrite := os.WriteFile(tmp, data, 0o600); errWrite != nil {
+		return fmt.Errorf("batch: write %s: %w", filepath.Base(path), errWrite)
+	}
+	if errRename := os.Rename(tmp, path); errRename != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("batch: replace %s: %w", filepath.Base(path), errRename)
+	}
+	s.dirty[path] = struct{}{}
+	return nil
+}
+
+func (s *FileStore) removeLocked(path string) error {
+	if errRemove := os.Remove(path); errRemove != nil {
+		if errors.Is(errRemove, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("batch: remove %s: %w", filepath.Base(path), errRemove)
+	}
+	s.dirty[path] = struct{}{}
+	return nil
+}
+
+func (s *FileStore) readJSONLocked(path string, dst any) error {
+	data, errRead := os.ReadFile(path)
+	if errRead != nil {
+		if errors.Is(errRead, fs.ErrNotExist) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("batch: read %s: %w", filepath.Base(path), errRead)
+	}
+	if errUnmarshal := json.Unmarshal(data, dst); errUnmarshal != nil {
+		return fmt.Errorf("batch: decode %s: %w", filepath.Base(path), errUnmarshal)
+	}
+	return nil
+}
+
+func (s *FileStore) listLocked(kind, suffix string, fn func(path string) error) error {
+	entries, errRead := os.ReadDir(filepath.Join(s.dir, kind))
+	if errRead != nil {
+		if errors.Is(errRead, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("batch: list %s: %w", kind, errRead)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		if errFn := fn(filepath.Join(s.dir, kind, name)); errFn != nil {
+			return errFn
+		}
+	}
+	return nil
+}
+
+// validateID rejects ids that could escape the store directory.
+func validateID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return fmt.Errorf("batch: invalid id %q", id)
+	}
+	return nil
+}
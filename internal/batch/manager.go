@@ -0,0 +1,614 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// persistInterval controls how often FileStore changes are mirrored to the token store.
+	persistInterval = 30 * time.Second
+	// checkpointInterval controls how often the progress of running jobs is saved.
+	checkpointInterval = 5 * time.Second
+	// maxWorkersPerJob caps the worker pool of a single job.
+	maxWorkersPerJob = 256
+)
+
+// InvalidRequestError describes a request the Files or Batch API rejects with 400.
+type InvalidRequestError struct {
+	Message string
+	Param   string
+}
+
+func (e *InvalidRequestError) Error() string {
+	return e.Message
+}
+
+func invalidRequest(param, format string, args ...any) error {
+	return &InvalidRequestError{Message: fmt.Sprintf(format, args...), Param: param}
+}
+
+// CreateParams are the fields of a POST /v1/batches request.
+type CreateParams struct {
+	InputFileID      string            `json:"input_file_id"`
+	Endpoint         string            `json:"endpoint"`
+	CompletionWindow string            `json:"completion_window"`
+	Metadata         map[string]string `json:"metadata"`
+}
+
+// Manager owns the batch store and the worker pools of running jobs.
+type Manager struct {
+	mu        sync.Mutex
+	cfg       config.BatchConfig
+	dir       string
+	external  Store
+	persister Persister
+	authDir   string
+	file      *FileStore
+	store     Store
+	executor  Executor
+	runs      map[string]*run
+	stop      chan struct{}
+	kick      chan struct{}
+}
+
+// New constructs a disabled manager.
+func New() *Manager {
+	return &Manager{runs: make(map[string]*run), kick: make(chan struct{}, 1)}
+}
+
+var defaultManager = New()
+
+// Default returns the process-wide manager behind /v1/files and /v1/batches.
+func Default() *Manager {
+	return defaultManager
+}
+
+// Apply publishes the batch block. dir is the resolved directory of the file store.
+func (m *Manager) Apply(cfg config.BatchConfig, dir string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cfg = cfg
+	m.dir = dir
+	m.selectStoreLocked()
+}
+
+// SetExternalStore attaches the store of the Postgres token store. Passing nil detaches it.
+func (m *Manager) SetExternalStore(store Store) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.external = store
+	m.selectStoreLocked()
+}
+
+// SetPersister mirrors the file store to a git or object token store whose local
+// workspace is authDir. Only directories inside authDir are mirrored.
+func (m *Manager) SetPersister(persister Persister, authDir string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.persister = persister
+	m.authDir = authDir
+}
+
+// SetExecutor installs the executor of batch lines and resumes interrupted jobs.
+func (m *Manager) SetExecutor(executor Executor) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executor = executor
+	m.resumeLocked()
+}
+
+// Enabled reports whether the Files and Batch APIs are served.
+func (m *Manager) Enabled() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store != nil
+}
+
+// MaxFileSize returns the upload size limit in bytes.
+func (m *Manager) MaxFileSize() int64 {
+	if m == nil {
+		return config.DefaultBatchMaxFileSizeMB << 20
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cfg.EffectiveMaxFileSize()
+}
+
+// Stop pauses running jobs, saves their progress and mirrors pending changes.
+// Paused jobs resume when the manager is enabled again.
+func (m *Manager) Stop(ctx context.Context) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	runs := m.detachRunsLocked()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+	m.mu.Unlock()
+	waitRuns(runs)
+	m.persist(ctx)
+}
+
+// CreateFile stores an uploaded file.
+func (m *Manager) CreateFile(ctx context.Context, owner, filename, purpose string, content []byte) (File, error) {
+	store, errStore := m.activeStore()
+	if errStore != nil {
+		return File{}, errStore
+	}
+	if purpose != PurposeBatch {
+		return File{}, invalidRequest("purpose", "Invalid purpose %q: only %q files can be uploaded", purpose, PurposeBatch)
+	}
+	if len(content) == 0 {
+		return File{}, invalidRequest("file", "The uploaded file is empty")
+	}
+	file := File{
+		ID:        newID("file-"),
+		Object:    "file",
+		Bytes:     int64(len(content)),
+		CreatedAt: time.Now().Unix(),
+		Filename:  filename,
+		Purpose:   purpose,
+		Owner:     owner,
+	}
+	if errSave := store.SaveFile(ctx, file, content); errSave != nil {
+		return File{}, errSave
+	}
+	m.requestPersist()
+	return file, nil
+}
+
+// ListFiles returns the files of owner, newest first, optionally filtered by purpose.
+func (m *Manager) ListFiles(ctx context.Context, owner, purpose string) ([]File, error) {
+	store, errStore := m.activeStore()
+	if errStore != nil {
+		return nil, errStore
+	}
+	files, errList := store.ListFiles(ctx)
+	if errList != nil {
+		return nil, errList
+	}
+	out := make([]File, 0, len(files))
+	for _, file := range files {
+		if file.Owner != owner || (purpose != "" && file.Purpose != purpose) {
+			continue
+		}
+		out = append(out, file)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt != out[j].CreatedAt {
+			return out[i].CreatedAt > out[j].CreatedAt
+		}
+		return out[i].ID > out[j].ID
+	})
+	return out, nil
+}
+
+// GetFile returns a file of owner.
+func (m *Manager) GetFile(ctx context.Context, owner, id string) (File, error) {
+	store, errStore := m.activeStore()
+	if errStore != nil {
+		return File{}, errStore
+	}
+	file, errGet := store.GetFile(ctx, id)
+	if errGet != nil {
+		return File{}, errGet
+	}
+	if file.Owner != owner {
+		return File{}, ErrNotFound
+	}
+	return file, nil
+}
+
+// FileContent returns the content of a file of owner.
+func (m *Manager) FileContent(ctx context.Context, owner, id string) (File, []byte, error) {
+	file, errGet := m.GetFile(ctx, owner, id)
+	if errGet != nil {
+		return File{}, nil, errGet
+	}
+	store, errStore := m.activeStore()
+	if errStore != nil {
+		return File{}, nil, errStore
+	}
+	content, errContent := store.FileContent(ctx, id)
+	return file, content, errContent
+}
+
+// DeleteFile removes a file of owner. Input files of running jobs cannot be deleted.
+func (m *Manager) DeleteFile(ctx context.Context, owner, id string) error {
+	if _, errGet := m.GetFile(ctx, owner, id); errGet != nil {
+		return errGet
+	}
+	m.mu.Lock()
+	for _, r := range m.runs {
+		if r.inputFileID() == id {
+			m.mu.Unlock()
+			return invalidRequest("file_id", "File %s is the input of a running batch", id)
+		}
+	}
+	store := m.store
+	m.mu.Unlock()
+	if store == nil {
+		return ErrDisabled
+	}
+	if errDelete := store.DeleteFile(ctx, id); errDelete != nil {
+		return errDelete
+	}
+	m.requestPersist()
+	return nil
+}
+
+// CreateJob validates the input file and queues a batch for owner. Input files with
+// malformed lines produce a job in the failed status, as the OpenAI API does.
+func (m *Manager) CreateJob(ctx context.Context, owner string, params CreateParams) (Job, error) {
+	store, errStore := m.activeStore()
+	if errStore != nil {
+		return Job{}, errStore
+	}
+	endpoint := strings.TrimSpace(params.Endpoint)
+	if !SupportedEndpoint(endpoint) {
+		return Job{}, invalidRequest("endpoint", "Unsupported endpoint %q: batches support /v1/chat/completions, /v1/responses and /v1/embeddings", params.Endpoint)
+	}
+	window := strings.TrimSpace(params.CompletionWindow)
+	if window == "" {
+		window = DefaultCompletionWindow
+	}
+	duration, errWindow := time.ParseDuration(window)
+	if errWindow != nil || duration <= 0 || duration > maxCompletionWindow {
+		return Job{}, invalidRequest("completion_window", "Invalid completion_window %q: expected a duration such as \"24h\", at most 168h", params.CompletionWindow)
+	}
+	inputID := strings.TrimSpace(params.InputFileID)
+	if inputID == "" {
+		return Job{}, invalidRequest("input_file_id", "input_file_id is required")
+	}
+	file, errFile := m.GetFile(ctx, owner, inputID)
+	if errFile != nil {
+		if errors.Is(errFile, ErrNotFound) {
+			return Job{}, invalidRequest("input_file_id", "No such file: %s", inputID)
+		}
+		return Job{}, errFile
+	}
+	if file.Purpose != PurposeBatch {
+		return Job{}, invalidRequest("input_file_id", "File %s does not have purpose %q", inputID, PurposeBatch)
+	}
+	content, errContent := store.FileContent(ctx, inputID)
+	if errContent != nil {
+		return Job{}, errContent
+	}
+
+	now := time.Now()
+	job := Job{
+		ID:               newID("batch_"),
+		Object:           "batch",
+		Endpoint:         endpoint,
+		InputFileID:      inputID,
+		CompletionWindow: window,
+		CreatedAt:        now.Unix(),
+		ExpiresAt:        now.Add(duration).Unix(),
+		Metadata:         params.Metadata,
+		Owner:            owner,
+	}
+	lines, validationErrors := parseInput(content, endpoint)
+	if len(validationErrors) > 0 {
+		job.Status = StatusFailed
+		job.FailedAt = now.Unix()
+		job.Errors = &JobErrors{Object: "list", Data: validationErrors}
+	} else {
+		job.Status = StatusInProgress
+		job.InProgressAt = now.Unix()
+		job.RequestCounts.Total = len(lines)
+	}
+	if errSave := store.SaveJob(ctx, job); errSave != nil {
+		return Job{}, errSave
+	}
+	if job.Status == StatusInProgress {
+		m.mu.Lock()
+		if m.store == store {
+			m.startLocked(job)
+		}
+		m.mu.Unlock()
+	}
+	m.requestPersist()
+	return job, nil
+}
+
+// GetJob returns a batch of owner with live progress for running jobs.
+func (m *Manager) GetJob(ctx context.Context, owner, id string) (Job, error) {
+	store, errStore := m.activeStore()
+	if errStore != nil {
+		return Job{}, errStore
+	}
+	m.mu.Lock()
+	r := m.runs[id]
+	m.mu.Unlock()
+	var job Job
+	if r != nil {
+		job = r.snapshot()
+	} else {
+		var errGet error
+		if job, errGet = store.GetJob(ctx, id); errGet != nil {
+			return Job{}, errGet
+		}
+	}
+	if job.Owner != owner {
+		return Job{}, ErrNotFound
+	}
+	return job, nil
+}
+
+// ListJobs returns the batches of owner, newest first. after is the id of the last
+// batch of the previous page; hasMore reports whether more batches follow.
+func (m *Manager) ListJobs(ctx context.Context, owner, after string, limit int) ([]Job, bool, error) {
+	store, errStore := m.activeStore()
+	if errStore != nil {
+		return nil, false, errStore
+	}
+	jobs, errList := store.ListJobs(ctx)
+	if errList != nil {
+		return nil, false, errList
+	}
+	m.mu.Lock()
+	for i := range jobs {
+		if r := m.runs[jobs[i].ID]; r != nil {
+			jobs[i] = r.snapshot()
+		}
+	}
+	m.mu.Unlock()
+
+	owned := make([]Job, 0, len(jobs))
+	for _, job := range jobs {
+		if job.Owner == owner {
+			owned = append(owned, job)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		if owned[i].CreatedAt != owned[j].CreatedAt {
+			return owned[i].CreatedAt > owned[j].CreatedAt
+		}
+		return owned[i].ID > owned[j].ID
+	})
+	if after != "" {
+		for i := range owned {
+			if owned[i].ID == after {
+				owned = owned[i+1:]
+				break
+			}
+		}
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if len(owned) > limit {
+		return owned[:limit], true, nil
+	}
+	return owned, false, nil
+}
+
+// CancelJob stops a batch of owner. Finished lines are kept in the output file and
+// the remaining lines are reported with the batch_cancelled error code.
+func (m *Manager) CancelJob(ctx context.Context, owner, id string) (Job, error) {
+	job, errGet := m.GetJob(ctx, owner, id)
+	if errGet != nil {
+		return Job{}, errGet
+	}
+	if job.Terminal() || job.Status == StatusCancelling {
+		if job.Terminal() {
+			return Job{}, invalidRequest("batch_id", "Cannot cancel a batch with status %q", job.Status)
+		}
+		return job, nil
+	}
+
+	m.mu.Lock()
+	r := m.runs[id]
+	if r != nil {
+		r.markCancelling(time.Now())
+		m.mu.Unlock()
+		return r.snapshot(), nil
+	}
+	store := m.store
+	m.mu.Unlock()
+	if store == nil {
+		return Job{}, ErrDisabled
+	}
+	// No worker pool is attached (no executor yet); persist the request so the job
+	// finalizes as cancelled once it is resumed.
+	job.Status = StatusCancelling
+	job.CancellingAt = time.Now().Unix()
+	if errSave := store.SaveJob(ctx, job); errSave != nil {
+		return Job{}, errSave
+	}
+	m.requestPersist()
+	return job, nil
+}
+
+func (m *Manager) activeStore() (Store, error) {
+	if m == nil {
+		return nil, ErrDisabled
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.store == nil {
+		return nil, ErrDisabled
+	}
+	return m.store, nil
+}
+
+func (m *Manager) selectStoreLocked() {
+	var next Store
+	if m.cfg.Enable {
+		if m.external != nil {
+			next = m.external
+		} else {
+			if m.file == nil || m.file.Dir() != m.dir {
+				m.file = NewFileStore(m.dir)
+			}
+			next = m.file
+		}
+	}
+	if next == m.store {
+		return
+	}
+
+	// Paused jobs save their progress to the store they were started from.
+	m.detachRunsLocked()
+	m.store = next
+
+	switch {
+	case next == nil && m.stop != nil:
+		close(m.stop)
+		m.stop = nil
+	case next != nil && m.stop == nil:
+		m.stop = make(chan struct{})
+		go m.loop(m.stop)
+	}
+	m.resumeLocked()
+}
+
+// resumeLocked restarts every unfinished job of the selected store.
+func (m *Manager) resumeLocked() {
+	if m.store == nil || m.executor == nil {
+		return
+	}
+	jobs, errList := m.store.ListJobs(context.Background())
+	if errList != nil {
+		log.Warnf("batch: failed to list jobs for resume: %v", errList)
+		return
+	}
+	for _, job := range jobs {
+		if job.Terminal() || m.runs[job.ID] != nil {
+			continue
+		}
+		m.startLocked(job)
+	}
+}
+
+func (m *Manager) startLocked(job Job) {
+	if m.executor == nil {
+		return
+	}
+	r := newRun(m, m.store, m.executor, job, m.cfg.EffectiveConcurrencyPerCredential())
+	m.runs[job.ID] = r
+	go r.execute()
+}
+
+// detachRunsLocked pauses every running job and forgets it.
+func (m *Manager) detachRunsLocked() []*run {
+	runs := make([]*run, 0, len(m.runs))
+	for id, r := range m.runs {
+		r.pause()
+		runs = append(runs, r)
+		delete(m.runs, id)
+	}
+	return runs
+}
+
+func waitRuns(runs []*run) {
+	for _, r := range runs {
+		<-r.done
+	}
+}
+
+// finished removes r from the running set once it has been finalized.
+func (m *Manager) finished(r *run) {
+	m.mu.Lock()
+	if m.runs[r.id] == r {
+		delete(m.runs, r.id)
+	}
+	m.mu.Unlock()
+	m.requestPersist()
+}
+
+func (m *Manager) requestPersist() {
+	select {
+	case m.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) loop(stop <-chan struct{}) {
+	ticker := time.NewTicker(persistInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.persist(context.Background())
+		case <-m.kick:
+			m.persist(context.Background())
+		}
+	}
+}
+
+// persist mirrors FileStore changes to the git or object token store.
+func (m *Manager) persist(ctx context.Context) {
+	m.mu.Lock()
+	persister := m.persister
+	authDir := m.authDir
+	file := m.file
+	usingFile := file != nil && m.store == Store(file)
+	m.mu.Unlock()
+	if persister == nil || !usingFile {
+		return
+	}
+	paths := file.TakeDirty()
+	if len(paths) == 0 {
+		return
+	}
+	if !withinDir(authDir, file.Dir()) {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if errPersist := persister.PersistAuthFiles(ctx, "Sync batch jobs", paths...); errPersist != nil {
+		log.Warnf("batch: failed to sync jobs to the token store: %v", errPersist)
+	}
+}
+
+func withinDir(root, path string) bool {
+	if strings.TrimSpace(root) == "" {
+		return false
+	}
+	absRoot, errRoot := filepath.Abs(root)
+	absPath, errPath := filepath.Abs(path)
+	if errRoot != nil || errPath != nil {
+		return false
+	}
+	rel, errRel := filepath.Rel(absRoot, absPath)
+	if errRel != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func newID(prefix string) string {
+	return prefix + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
@@ -0,0 +1,320 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+func batchInput(n int) []byte {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `{"custom_id":"req-%d","method":"POST","url":"/v1/chat/completions","body":{"model":"m","messages":[{"role":"user","content":"%d"}]}}`+"\n", i, i)
+	}
+	return []byte(b.String())
+}
+
+func newTestManager(t *testing.T, executor Executor) *Manager {
+	t.Helper()
+	m := New()
+	m.Apply(config.BatchConfig{Enable: true, ConcurrencyPerCredential: 2}, t.TempDir())
+	if executor != nil {
+		m.SetExecutor(executor)
+	}
+	t.Cleanup(func() { m.Stop(context.Background()) })
+	return m
+}
+
+func waitForStatus(t *testing.T, m *Manager, owner, id string, statuses ...string) Job {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		job, err := m.GetJob(context.Background(), owner, id)
+		if err != nil {
+			t.Fatalf("GetJob() error = %v", err)
+		}
+		for _, status := range statuses {
+			if job.Status == status {
+				return job
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job status = %q, want one of %v", job.Status, statuses)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestManagerRunsBatchAndWritesOutputFiles(t *testing.T) {
+	executor := ExecutorFunc(func(_ context.Context, owner, endpoint string, body []byte) Outcome {
+		if owner != "key-a" || endpoint != "/v1/chat/completions" {
+			t.Errorf("executor got owner=%q endpoint=%q", owner, endpoint)
+		}
+		if gjson.GetBytes(body, "messages.0.content").String() == "3" {
+			return Outcome{StatusCode: http.StatusBadRequest, Body: []byte(`{"error":{"message":"bad"}}`)}
+		}
+		return Outcome{StatusCode: http.StatusOK, Body: []byte(`{"id":"chatcmpl-1"}`), RequestID: "rid"}
+	})
+	m := newTestManager(t, executor)
+	ctx := context.Background()
+
+	file, err := m.CreateFile(ctx, "key-a", "input.jsonl", PurposeBatch, batchInput(5))
+	if err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	job, err := m.CreateJob(ctx, "key-a", CreateParams{InputFileID: file.ID, Endpoint: "/v1/chat/completions"})
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	if _, errGet := m.GetJob(ctx, "key-b", job.ID); !errors.Is(errGet, ErrNotFound) {
+		t.Fatalf("GetJob() for another key error = %v, want ErrNotFound", errGet)
+	}
+
+	job = waitForStatus(t, m, "key-a", job.ID, StatusCompleted)
+	if job.RequestCounts != (RequestCounts{Total: 5, Completed: 4, Failed: 1}) {
+		t.Fatalf("request counts = %+v", job.RequestCounts)
+	}
+	_, output, err := m.FileContent(ctx, "key-a", job.OutputFileID)
+	if err != nil {
+		t.Fatalf("FileContent(output) error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("output lines = %d, want 4", len(lines))
+	}
+	if got := gjson.Get(lines[0], "response.body.id").String(); got != "chatcmpl-1" {
+		t.Fatalf("output body id = %q, line = %s", got, lines[0])
+	}
+	_, errorsContent, err := m.FileContent(ctx, "key-a", job.ErrorFileID)
+	if err != nil {
+		t.Fatalf("FileContent(errors) error = %v", err)
+	}
+	if got := gjson.GetBytes(errorsContent, "custom_id").String(); got != "req-3" {
+		t.Fatalf("error line custom_id = %q", got)
+	}
+	if got := gjson.GetBytes(errorsContent, "response.status_code").Int(); got != http.StatusBadRequest {
+		t.Fatalf("error line status = %d", got)
+	}
+}
+
+func TestManagerThrottledLinesAreRetried(t *testing.T) {
+	var calls atomic.Int32
+	executor := ExecutorFunc(func(context.Context, string, string, []byte) Outcome {
+		if calls.Add(1) == 1 {
+			return Outcome{StatusCode: http.StatusTooManyRequests, Throttled: true, RetryAfter: 50 * time.Millisecond}
+		}
+		return Outcome{StatusCode: http.StatusOK, Body: []byte(`{}`)}
+	})
+	m := newTestManager(t, executor)
+	ctx := context.Background()
+	file, _ := m.CreateFile(ctx, "", "input.jsonl", PurposeBatch, batchInput(1))
+	job, err := m.CreateJob(ctx, "", CreateParams{InputFileID: file.ID, Endpoint: "/v1/chat/completions"})
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	job = waitForStatus(t, m, "", job.ID, StatusCompleted)
+	if job.RequestCounts.Completed != 1 || job.ErrorFileID != "" {
+		t.Fatalf("job = %+v, want one completed line and no error file", job)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("executor calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestManagerCancelReportsUnfinishedLines(t *testing.T) {
+	release := make(chan struct{})
+	var once sync.Once
+	executor := ExecutorFunc(func(ctx context.Context, _, _ string, _ []byte) Outcome {
+		select {
+		case <-release:
+			return Outcome{StatusCode: http.StatusOK, Body: []byte(`{}`)}
+		case <-ctx.Done():
+			return Outcome{StatusCode: http.StatusRequestTimeout}
+		}
+	})
+	m := newTestManager(t, executor)
+	ctx := context.Background()
+	file, _ := m.CreateFile(ctx, "", "input.jsonl", PurposeBatch, batchInput(3))
+	job, err := m.CreateJob(ctx, "", CreateParams{InputFileID: file.ID, Endpoint: "/v1/chat/completions"})
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	cancelled, err := m.CancelJob(ctx, "", job.ID)
+	if err != nil {
+		t.Fatalf("CancelJob() error = %v", err)
+	}
+	if cancelled.Status != StatusCancelling {
+		t.Fatalf("status after cancel = %q, want %q", cancelled.Status, StatusCancelling)
+	}
+	once.Do(func() { close(release) })
+
+	job = waitForStatus(t, m, "", job.ID, StatusCancelled)
+	if job.RequestCounts.Total != 3 || job.RequestCounts.Completed+job.RequestCounts.Failed != 3 {
+		t.Fatalf("request counts = %+v", job.RequestCounts)
+	}
+	_, errorsContent, err := m.FileContent(ctx, "", job.ErrorFileID)
+	if err != nil {
+		t.Fatalf("FileContent(errors) error = %v", err)
+	}
+	if !strings.Contains(string(errorsContent), `"code":"batch_cancelled"`) {
+		t.Fatalf("error file = %s, want batch_cancelled lines", errorsContent)
+	}
+}
+
+func TestManagerResumesFromRecordedResults(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	store := NewFileStore(dir)
+	input := batchInput(3)
+	file := File{ID: "file-in", Object: "file", Bytes: int64(len(input)), Filename: "in.jsonl", Purpose: PurposeBatch}
+	if err := store.SaveFile(ctx, file, input); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	job := Job{ID: "batch_1", Object: "batch", Endpoint: "/v1/chat/completions", InputFileID: file.ID, Status: StatusInProgress, ExpiresAt: time.Now().Add(time.Hour).Unix()}
+	if err := store.SaveJob(ctx, job); err != nil {
+		t.Fatalf("SaveJob() error = %v", err)
+	}
+	done := Result{CustomID: "req-0", Line: responseLine("req-0", Outcome{StatusCode: http.StatusOK, Body: []byte(`{}`)})}
+	if err := store.AppendResults(ctx, job.ID, []Result{done}); err != nil {
+		t.Fatalf("AppendResults() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	executed := make(map[string]bool)
+	m := New()
+	m.SetExecutor(ExecutorFunc(func(_ context.Context, _, _ string, body []byte) Outcome {
+		mu.Lock()
+		executed[gjson.GetBytes(body, "messages.0.content").String()] = true
+		mu.Unlock()
+		return Outcome{StatusCode: http.StatusOK, Body: []byte(`{}`)}
+	}))
+	m.Apply(config.BatchConfig{Enable: true}, dir)
+	t.Cleanup(func() { m.Stop(ctx) })
+
+	job = waitForStatus(t, m, "", job.ID, StatusCompleted)
+	if job.RequestCounts.Completed != 3 {
+		t.Fatalf("request counts = %+v", job.RequestCounts)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if executed["0"] || !executed["1"] || !executed["2"] {
+		t.Fatalf("executed lines = %v, want only the unfinished ones", executed)
+	}
+}
+
+func TestManagerCreateJobValidation(t *testing.T) {
+	m := newTestManager(t, nil)
+	ctx := context.Background()
+
+	var invalid *InvalidRequestError
+	if _, err := m.CreateFile(ctx, "", "in.jsonl", "fine-tune", []byte("{}")); !errors.As(err, &invalid) {
+		t.Fatalf("CreateFile(fine-tune) error = %v, want InvalidRequestError", err)
+	}
+	input := `{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{"model":"m"}}
+{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{"model":"m"}}
+{"custom_id":"b","method":"POST","url":"/v1/embeddings","body":{"model":"m"}}
+not json
+`
+	file, err := m.CreateFile(ctx, "", "in.jsonl", PurposeBatch, []byte(input))
+	if err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	if _, err = m.CreateJob(ctx, "", CreateParams{InputFileID: file.ID, Endpoint: "/v1/images/generations"}); !errors.As(err, &invalid) {
+		t.Fatalf("CreateJob(unsupported endpoint) error = %v, want InvalidRequestError", err)
+	}
+	job, err := m.CreateJob(ctx, "", CreateParams{InputFileID: file.ID, Endpoint: "/v1/chat/completions"})
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	if job.Status != StatusFailed || job.Errors == nil {
+		t.Fatalf("job = %+v, want failed with errors", job)
+	}
+	codes := make([]string, 0, len(job.Errors.Data))
+	for _, item := range job.Errors.Data {
+		codes = append(codes, item.Code)
+	}
+	if got := strings.Join(codes, ","); got != "duplicate_custom_id,mismatched_endpoint,invalid_json_line" {
+		t.Fatalf("validation codes = %s", got)
+	}
+}
+
+func TestFileStoreUsesNonJSONExtensions(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+	ctx := context.Background()
+	if err := store.SaveFile(ctx, File{ID: "file-1"}, []byte("x")); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	if err := store.SaveJob(ctx, Job{ID: "batch_1"}); err != nil {
+		t.Fatalf("SaveJob() error = %v", err)
+	}
+	for _, path := range store.TakeDirty() {
+		if strings.HasSuffix(path, ".json") {
+			t.Fatalf("store wrote %s; .json files are reserved for credentials", path)
+		}
+	}
+	if err := store.SaveJob(ctx, Job{ID: "../escape"}); err == nil {
+		t.Fatal("SaveJob() with a path id succeeded, want error")
+	}
+}
+
+// flakyResultsStore fails the first Results calls of a job.
+type flakyResultsStore struct {
+	Store
+	failures atomic.Int32
+}
+
+func (s *flakyResultsStore) Results(ctx context.Context, jobID string) ([]Result, error) {
+	if s.failures.Add(-1) >= 0 {
+		return nil, errors.New("results unavailable")
+	}
+	return s.Store.Results(ctx, jobID)
+}
+
+func TestRunRetriesStoreFailures(t *testing.T) {
+	previous := storeRetryInitialDelay
+	storeRetryInitialDelay = time.Millisecond
+	t.Cleanup(func() { storeRetryInitialDelay = previous })
+
+	ctx := context.Background()
+	store := &flakyResultsStore{Store: NewFileStore(t.TempDir())}
+	input := batchInput(2)
+	file := File{ID: "file-in", Object: "file", Bytes: int64(len(input)), Filename: "in.jsonl", Purpose: PurposeBatch}
+	if err := store.SaveFile(ctx, file, input); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	job := Job{ID: "batch_1", Object: "batch", Endpoint: "/v1/chat/completions", InputFileID: file.ID, Status: StatusInProgress}
+	if err := store.SaveJob(ctx, job); err != nil {
+		t.Fatalf("SaveJob() error = %v", err)
+	}
+	// Fail loading the results three times before the lines run.
+	store.failures.Store(3)
+
+	executor := ExecutorFunc(func(context.Context, string, string, []byte) Outcome {
+		return Outcome{StatusCode: http.StatusOK, Body: []byte(`{}`)}
+	})
+	r := newRun(New(), store, executor, job, 2)
+	go r.execute()
+	select {
+	case <-r.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("run did not finish")
+	}
+
+	saved, err := store.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetJob() error = %v", err)
+	}
+	if saved.Status != StatusCompleted || saved.RequestCounts.Completed != 2 || saved.OutputFileID == "" {
+		t.Fatalf("job = %+v, want completed with an output file", saved)
+	}
+}
@@ -0,0 +1,615 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// defaultThrottleDelay is used when a throttled line carries no retry time.
+	defaultThrottleDelay = 5 * time.Second
+	// maxReportedErrors caps the validation errors attached to a failed job.
+	maxReportedErrors = 100
+	// storeRetryMaxDelay caps the backoff between attempts of a failed store operation.
+	storeRetryMaxDelay = time.Minute
+)
+
+// storeRetryInitialDelay is the first backoff after a failed store operation.
+var storeRetryInitialDelay = time.Second
+
+var (
+	errCancelled = errors.New("batch cancelled")
+	errExpired   = errors.New("batch expired")
+	errPaused    = errors.New("batch paused")
+)
+
+// requestLine is one validated line of an input file.
+type requestLine struct {
+	CustomID string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// parseInput validates an input file against endpoint.
+func parseInput(content []byte, endpoint string) ([]requestLine, []JobError) {
+	lines := make([]requestLine, 0)
+	var validationErrors []JobError
+	report := func(lineNo int, code, param, format string, args ...any) {
+		if len(validationErrors) < maxReportedErrors {
+			validationErrors = append(validationErrors, JobError{Code: code, Message: fmt.Sprintf(format, args...), Param: param, Line: lineNo})
+		}
+	}
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		var line requestLine
+		if !gjson.ValidBytes(raw) || json.Unmarshal(raw, &line) != nil {
+			report(lineNo, "invalid_json_line", "", "Line %d is not a valid JSON object", lineNo)
+			continue
+		}
+		switch {
+		case strings.TrimSpace(line.CustomID) == "":
+			report(lineNo, "missing_required_parameter", "custom_id", "Line %d is missing custom_id", lineNo)
+			continue
+		case !strings.EqualFold(strings.TrimSpace(line.Method), http.MethodPost):
+			report(lineNo, "invalid_method", "method", "Line %d uses method %q; only POST is supported", lineNo, line.Method)
+			continue
+		case strings.TrimSpace(line.URL) != endpoint:
+			report(lineNo, "mismatched_endpoint", "url", "Line %d targets %q but the batch endpoint is %q", lineNo, line.URL, endpoint)
+			continue
+		case !gjson.ParseBytes(line.Body).IsObject():
+			report(lineNo, "missing_required_parameter", "body", "Line %d is missing a JSON object body", lineNo)
+			continue
+		case strings.TrimSpace(gjson.GetBytes(line.Body, "model").String()) == "":
+			report(lineNo, "missing_required_parameter", "body.model", "Line %d is missing body.model", lineNo)
+			continue
+		}
+		if _, dup := seen[line.CustomID]; dup {
+			report(lineNo, "duplicate_custom_id", "custom_id", "Line %d reuses custom_id %q", lineNo, line.CustomID)
+			continue
+		}
+		seen[line.CustomID] = struct{}{}
+		lines = append(lines, line)
+	}
+	if errScan := scanner.Err(); errScan != nil {
+		report(lineNo+1, "invalid_json_line", "", "Failed to read input file: %v", errScan)
+	}
+	if len(validationErrors) == 0 && len(lines) == 0 {
+		report(0, "empty_file", "input_file_id", "The input file contains no requests")
+	}
+	if len(lines) > maxRequestsPerBatch {
+		report(0, "too_many_requests", "input_file_id", "The input file contains %d requests; the limit is %d", len(lines), maxRequestsPerBatch)
+	}
+	return lines, validationErrors
+}
+
+// run is the worker pool of one job.
+type run struct {
+	id          string
+	manager     *Manager
+	store       Store
+	executor    Executor
+	concurrency int
+	slots       *coreauth.CredentialSlots
+	ctx         context.Context
+	cancel      context.CancelCauseFunc
+	done        chan struct{}
+	paused      chan struct{}
+	pauseOnce   sync.Once
+
+	mu         sync.Mutex
+	job        Job
+	dirty      bool
+	pauseUntil time.Time
+}
+
+func newRun(m *Manager, store Store, executor Executor, job Job, concurrency int) *run {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	r := &run{
+		id:          job.ID,
+		manager:     m,
+		store:       store,
+		executor:    executor,
+		concurrency: concurrency,
+		slots:       coreauth.NewCredentialSlots(concurrency),
+		ctx:         ctx,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+		paused:      make(chan struct{}),
+		job:         job,
+	}
+	// A job interrupted while cancelling, or while finalizing a cancellation, finishes as cancelled.
+	if job.Status == StatusCancelling || (job.Status == StatusFinalizing && job.CancellingAt > 0) {
+		cancel(errCancelled)
+	}
+	return r
+}
+
+func (r *run) snapshot() Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.job
+}
+
+func (r *run) inputFileID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.job.InputFileID
+}
+
+func (r *run) markCancelling(now time.Time) {
+	r.mu.Lock()
+	if r.job.Status == StatusInProgress {
+		r.job.Status = StatusCancelling
+		r.job.CancellingAt = now.Unix()
+		r.dirty = true
+	}
+	r.mu.Unlock()
+	r.cancel(errCancelled)
+}
+
+// pause stops the workers without finalizing; the job resumes from its results.
+func (r *run) pause() {
+	r.cancel(errPaused)
+	r.pauseOnce.Do(func() { close(r.paused) })
+}
+
+// retryStore runs op until it succeeds, backing off between failures. It returns
+// false when the run was paused first; the paused job resumes from its saved state.
+func (r *run) retryStore(what string, op func() error) bool {
+	delay := storeRetryInitialDelay
+	for {
+		errOp := op()
+		if errOp == nil {
+			return true
+		}
+		log.Warnf("batch %s: failed to %s, retrying in %s: %v", r.id, what, delay, errOp)
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.paused:
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+		if delay *= 2; delay > storeRetryMaxDelay {
+			delay = storeRetryMaxDelay
+		}
+	}
+}
+
+// execute replays the unfinished lines of the job and finalizes it.
+func (r *run) execute() {
+	defer close(r.done)
+	defer r.manager.finished(r)
+
+	ctx := context.Background()
+	job := r.snapshot()
+	content, errContent := r.store.FileContent(ctx, job.InputFileID)
+	if errContent != nil {
+		r.fail(ctx, "input_file_unavailable", fmt.Sprintf("Input file %s is unavailable: %v", job.InputFileID, errContent))
+		return
+	}
+	lines, validationErrors := parseInput(content, job.Endpoint)
+	if len(validationErrors) > 0 {
+		r.fail(ctx, validationErrors[0].Code, validationErrors[0].Message)
+		return
+	}
+	var results []Result
+	if !r.retryStore("load results", func() error {
+		var errResults error
+		results, errResults = r.store.Results(ctx, r.id)
+		return errResults
+	}) {
+		return
+	}
+
+	finished := make(map[string]struct{}, len(results))
+	counts := RequestCounts{Total: len(lines)}
+	for _, result := range results {
+		finished[result.CustomID] = struct{}{}
+		if result.Error {
+			counts.Failed++
+		} else {
+			counts.Completed++
+		}
+	}
+	pending := make([]requestLine, 0, len(lines)-len(finished))
+	for _, line := range lines {
+		if _, ok := finished[line.CustomID]; !ok {
+			pending = append(pending, line)
+		}
+	}
+	r.mu.Lock()
+	r.job.RequestCounts = counts
+	r.dirty = true
+	r.mu.Unlock()
+
+	if len(pending) > 0 && r.ctx.Err() == nil {
+		r.work(lines, pending)
+	}
+
+	switch cause := context.Cause(r.ctx); {
+	case errors.Is(cause, errPaused):
+		r.checkpoint(ctx)
+		return
+	case errors.Is(cause, errCancelled):
+		r.finalize(ctx, lines, StatusCancelled, "batch_cancelled", "This request was not executed because the batch was cancelled.")
+	case errors.Is(cause, errExpired):
+		r.finalize(ctx, lines, StatusExpired, "batch_expired", "This request could not be executed before the completion window expired.")
+	default:
+		r.finalize(ctx, lines, StatusCompleted, "", "")
+	}
+}
+
+// work runs pending on the worker pool until every line finished or the run stopped.
+func (r *run) work(lines, pending []requestLine) {
+	job := r.snapshot()
+	var workCtx context.Context
+	var stopWork context.CancelFunc
+	if job.ExpiresAt > 0 {
+		workCtx, stopWork = context.WithDeadlineCause(r.ctx, time.Unix(job.ExpiresAt, 0), errExpired)
+	} else {
+		workCtx, stopWork = context.WithCancel(r.ctx)
+	}
+	defer stopWork()
+	workCtx = coreauth.WithCredentialSlots(workCtx, r.slots)
+
+	queue := make(chan requestLine, len(pending))
+	for _, line := range pending {
+		queue <- line
+	}
+	close(queue)
+
+	workers := r.workerCount(lines)
+	if workers > len(pending) {
+		workers = len(pending)
+	}
+	stopCheckpoint := make(chan struct{})
+	go r.checkpointLoop(stopCheckpoint)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for line := range queue {
+				if !r.process(workCtx, job, line) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(stopCheckpoint)
+	// Surface expiry on r.ctx so execute can tell why the workers stopped.
+	if errors.Is(context.Cause(workCtx), errExpired) {
+		r.cancel(errExpired)
+	}
+}
+
+// workerCount sizes the pool from the credentials able to serve the job's models. The
+// credential slots passed to the executor keep each credential at r.concurrency requests.
+func (r *run) workerCount(lines []requestLine) int {
+	credentials := 1
+	modelRegistry := registry.GetGlobalRegistry()
+	seen := make(map[string]struct{})
+	for _, line := range lines {
+		model := strings.TrimSpace(thinking.ParseSuffix(gjson.GetBytes(line.Body, "model").String()).ModelName)
+		if _, ok := seen[model]; ok {
+			continue
+		}
+		seen[model] = struct{}{}
+		if count := modelRegistry.GetModelCount(model); count > credentials {
+			credentials = count
+		}
+	}
+	workers := credentials * r.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > maxWorkersPerJob {
+		workers = maxWorkersPerJob
+	}
+	return workers
+}
+
+// process executes line until it produces a result. It returns false when the run stopped.
+func (r *run) process(ctx context.Context, job Job, line requestLine) bool {
+	for {
+		if !r.waitThrottle(ctx) {
+			return false
+		}
+		outcome := r.executor.ExecuteBatchRequest(ctx, job.Owner, job.Endpoint, line.Body)
+		if ctx.Err() != nil {
+			// The line is retried when a paused job resumes and reported as
+			// unfinished when the job was cancelled or expired.
+			return false
+		}
+		if outcome.Throttled {
+			r.throttle(outcome.RetryAfter)
+			continue
+		}
+		r.record(ctx, line, outcome)
+		return true
+	}
+}
+
+func (r *run) waitThrottle(ctx context.Context) bool {
+	for {
+		r.mu.Lock()
+		wait := time.Until(r.pauseUntil)
+		r.mu.Unlock()
+		if wait <= 0 {
+			return ctx.Err() == nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+	}
+}
+
+// throttle pauses every worker of the job for delay.
+func (r *run) throttle(delay time.Duration) {
+	if delay <= 0 {
+		delay = defaultThrottleDelay
+	}
+	until := time.Now().Add(delay)
+	r.mu.Lock()
+	if until.After(r.pauseUntil) {
+		r.pauseUntil = until
+		log.Debugf("batch %s: throttled, pausing workers for %s", r.id, delay.Round(time.Second))
+	}
+	r.mu.Unlock()
+}
+
+func (r *run) record(ctx context.Context, line requestLine, outcome Outcome) {
+	failed := outcome.StatusCode < 200 || outcome.StatusCode >= 300
+	result := Result{CustomID: line.CustomID, Error: failed, Line: responseLine(line.CustomID, outcome)}
+	// The result is recorded even when the job is cancelled or expires meanwhile.
+	ctx = context.WithoutCancel(ctx)
+	if !r.retryStore("record result of "+line.CustomID, func() error {
+		return r.store.AppendResults(ctx, r.id, []Result{result})
+	}) {
+		// The line runs again when the paused job resumes.
+		return
+	}
+	r.mu.Lock()
+	if failed {
+		r.job.RequestCounts.Failed++
+	} else {
+		r.job.RequestCounts.Completed++
+	}
+	r.dirty = true
+	r.mu.Unlock()
+}
+
+func (r *run) checkpointLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.checkpoint(context.Background())
+		}
+	}
+}
+
+// checkpoint saves the job when its progress changed.
+func (r *run) checkpoint(ctx context.Context) {
+	r.mu.Lock()
+	if !r.dirty {
+		r.mu.Unlock()
+		return
+	}
+	job := r.job
+	r.dirty = false
+	r.mu.Unlock()
+	if errSave := r.store.SaveJob(ctx, job); errSave != nil {
+		log.Warnf("batch %s: failed to save progress: %v", r.id, errSave)
+		r.mu.Lock()
+		r.dirty = true
+		r.mu.Unlock()
+	}
+}
+
+// finalize writes the output and error files and moves the job to status. Lines without a
+// result are reported in the error file with code unfinishedCode. Store failures are
+// retried until they succeed or the job is paused.
+func (r *run) finalize(ctx context.Context, lines []requestLine, status, unfinishedCode, unfinishedMessage string) {
+	now := time.Now()
+	r.mu.Lock()
+	r.job.Status = StatusFinalizing
+	r.job.FinalizingAt = now.Unix()
+	r.dirty = true
+	r.mu.Unlock()
+	r.checkpoint(ctx)
+
+	if !r.retryStore("finalize", func() error {
+		return r.writeFinalState(ctx, lines, now, status, unfinishedCode, unfinishedMessage)
+	}) {
+		r.checkpoint(ctx)
+		return
+	}
+	if errDelete := r.store.DeleteResults(ctx, r.id); errDelete != nil {
+		log.Warnf("batch %s: failed to drop intermediate results: %v", r.id, errDelete)
+	}
+	job := r.snapshot()
+	log.Infof("batch %s %s: %d completed, %d failed", r.id, status, job.RequestCounts.Completed, job.RequestCounts.Failed)
+}
+
+// writeFinalState writes the output and error files and saves the job as status. Files
+// written by an earlier attempt are kept on the job and not written again.
+func (r *run) writeFinalState(ctx context.Context, lines []requestLine, now time.Time, status, unfinishedCode, unfinishedMessage string) error {
+	results, errResults := r.store.Results(ctx, r.id)
+	if errResults != nil {
+		return fmt.Errorf("load results: %w", errResults)
+	}
+	finished := make(map[string]struct{}, len(results))
+	var output, errorOutput bytes.Buffer
+	failed := 0
+	for _, result := range results {
+		finished[result.CustomID] = struct{}{}
+		if result.Error {
+			failed++
+			errorOutput.Write(result.Line)
+			errorOutput.WriteByte('\n')
+		} else {
+			output.Write(result.Line)
+			output.WriteByte('\n')
+		}
+	}
+	for _, line := range lines {
+		if _, ok := finished[line.CustomID]; ok {
+			continue
+		}
+		failed++
+		errorOutput.Write(errorLine(line.CustomID, unfinishedCode, unfinishedMessage))
+		errorOutput.WriteByte('\n')
+	}
+
+	job := r.snapshot()
+	if output.Len() > 0 && job.OutputFileID == "" {
+		id, errSave := r.saveOutput(ctx, job, "output", output.Bytes())
+		if errSave != nil {
+			return fmt.Errorf("write output file: %w", errSave)
+		}
+		job.OutputFileID = id
+		r.mu.Lock()
+		r.job.OutputFileID = id
+		r.dirty = true
+		r.mu.Unlock()
+	}
+	if errorOutput.Len() > 0 && job.ErrorFileID == "" {
+		id, errSave := r.saveOutput(ctx, job, "errors", errorOutput.Bytes())
+		if errSave != nil {
+			return fmt.Errorf("write error file: %w", errSave)
+		}
+		job.ErrorFileID = id
+		r.mu.Lock()
+		r.job.ErrorFileID = id
+		r.dirty = true
+		r.mu.Unlock()
+	}
+
+	job.RequestCounts = RequestCounts{Total: len(lines), Completed: len(results) - countErrors(results), Failed: failed}
+	job.Status = status
+	switch status {
+	case StatusCompleted:
+		job.CompletedAt = now.Unix()
+	case StatusExpired:
+		job.ExpiredAt = now.Unix()
+	case StatusCancelled:
+		job.CancelledAt = now.Unix()
+	}
+	if errSave := r.store.SaveJob(ctx, job); errSave != nil {
+		return fmt.Errorf("save final state: %w", errSave)
+	}
+	r.mu.Lock()
+	r.job = job
+	r.dirty = false
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *run) saveOutput(ctx context.Context, job Job, kind string, content []byte) (string, error) {
+	file := File{
+		ID:        newID("file-"),
+		Object:    "file",
+		Bytes:     int64(len(content)),
+		CreatedAt: time.Now().Unix(),
+		Filename:  fmt.Sprintf("%s_%s.jsonl", job.ID, kind),
+		Purpose:   PurposeBatchOutput,
+		Owner:     job.Owner,
+	}
+	if errSave := r.store.SaveFile(ctx, file, content); errSave != nil {
+		return "", errSave
+	}
+	return file.ID, nil
+}
+
+// fail moves a job whose input can no longer be read to the failed status.
+func (r *run) fail(ctx context.Context, code, message string) {
+	r.mu.Lock()
+	r.job.Status = StatusFailed
+	r.job.FailedAt = time.Now().Unix()
+	r.job.Errors = &JobErrors{Object: "list", Data: []JobError{{Code: code, Message: message}}}
+	r.dirty = true
+	r.mu.Unlock()
+	r.checkpoint(ctx)
+}
+
+func countErrors(results []Result) int {
+	n := 0
+	for _, result := range results {
+		if result.Error {
+			n++
+		}
+	}
+	return n
+}
+
+// outputLine is one line of an output or error file in the OpenAI batch format.
+type outputLine struct {
+	ID       string          `json:"id"`
+	CustomID string          `json:"custom_id"`
+	Response *outputResponse `json:"response"`
+	Error    *JobError       `json:"error"`
+}
+
+type outputResponse struct {
+	StatusCode int             `json:"status_code"`
+	RequestID  string          `json:"request_id"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// responseLine renders the output line of an executed request.
+func responseLine(customID string, outcome Outcome) json.RawMessage {
+	body := json.RawMessage(bytes.TrimSpace(outcome.Body))
+	if !json.Valid(body) {
+		body, _ = json.Marshal(string(outcome.Body))
+	}
+	line, _ := json.Marshal(outputLine{
+		ID:       newID("batch_req_"),
+		CustomID: customID,
+		Response: &outputResponse{StatusCode: outcome.StatusCode, RequestID: outcome.RequestID, Body: body},
+	})
+	return line
+}
+
+// errorLine renders the error file line of a request that was never executed.
+func errorLine(customID, code, message string) json.RawMessage {
+	line, _ := json.Marshal(outputLine{
+		ID:       newID("batch_req_"),
+		CustomID: customID,
+		Error:    &JobError{Code: code, Message: message},
+	})
+	return line
+}
@@ -65,6 +65,45 @@ func (cache *BoundedLRU[K, V]) GetOrAdd(key K, create func() V) V {
 	return value
 }
 
+// Add stores value under key, replacing any existing value, and evicts the least
+// recently used value when the bound is crossed.
+func (cache *BoundedLRU[K, V]) Add(key K, value V) {
+	cache.mu.Lock()
+	if element, ok := cache.entries[key]; ok {
+		element.Value = boundedLRUEntry[K, V]{key: key, value: value}
+		cache.order.MoveToFront(element)
+		cache.mu.Unlock()
+		return
+	}
+	element := cache.order.PushFront(boundedLRUEntry[K, V]{key: key, value: value})
+	cache.entries[key] = element
+
+	var evicted boundedLRUEntry[K, V]
+	didEvict := false
+	if cache.order.Len() > cache.capacity {
+		oldest := cache.order.Back()
+		evicted = oldest.Value.(boundedLRUEntry[K, V])
+		delete(cache.entries, evicted.key)
+		cache.order.Remove(oldest)
+		didEvict = true
+	}
+	cache.mu.Unlock()
+
+	if didEvict && cache.onEvict != nil {
+		cache.onEvict(evicted.key, evicted.value)
+	}
+}
+
+// Remove drops key without running the eviction callback.
+func (cache *BoundedLRU[K, V]) Remove(key K) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if element, ok := cache.entries[key]; ok {
+		delete(cache.entries, key)
+		cache.order.Remove(element)
+	}
+}
+
 func (cache *BoundedLRU[K, V]) Get(key K) (V, bool) {
 	cache.mu.Lock()
 	defer cache.mu.Unlock()
@@ -55,3 +55,31 @@ func TestBoundedLRUCreatesOneValuePerKeyConcurrently(t *testing.T) {
 		t.Fatalf("create calls = %d, want 1", got)
 	}
 }
+
+func TestBoundedLRUAddReplacesAndRemoveDrops(t *testing.T) {
+	var evicted []string
+	cache := NewBoundedLRU[string, string](2, func(key, value string) {
+		evicted = append(evicted, key+"="+value)
+	})
+
+	cache.Add("a", "A")
+	cache.Add("b", "B")
+	cache.Add("a", "A2")
+	if got, found := cache.Get("a"); !found || got != "A2" {
+		t.Fatalf("Get(a) = %q/%t, want A2/true", got, found)
+	}
+	cache.Add("c", "C")
+	if _, found := cache.Get("b"); found {
+		t.Fatal("least recently used entry b was not evicted")
+	}
+	cache.Remove("a")
+	if _, found := cache.Get("a"); found {
+		t.Fatal("removed entry a is still present")
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("Len() = %d, want 1", got)
+	}
+	if len(evicted) != 1 || evicted[0] != "b=B" {
+		t.Fatalf("evicted = %v, want [b=B]", evicted)
+	}
+}
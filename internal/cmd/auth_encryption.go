@@ -0,0 +1,59 @@
+// Package cmd contains CLI helpers. This file implements the one-shot migration
+// that encrypts or decrypts every auth file in the auth directory.
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/authcrypt"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v7/sdk/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// DoAuthFileEncryption rewrites the auth files in the configured auth directory.
+// With decrypt false, plaintext files and files sealed with an older key are sealed
+// with the primary key; with decrypt true, sealed files are written back as
+// plaintext. Remote token stores receive the rewritten files.
+func DoAuthFileEncryption(cfg *config.Config, decrypt bool) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	authDir, errResolve := util.ResolveAuthDir(cfg.AuthDir)
+	if errResolve != nil {
+		log.Errorf("auth encryption: resolve auth directory: %v", errResolve)
+		return
+	}
+	action := "encrypted"
+	if decrypt {
+		action = "decrypted"
+		if !authcrypt.Enabled() {
+			log.Error("auth encryption: AUTH_ENCRYPTION_KEYS is required to decrypt auth files")
+			return
+		}
+		authcrypt.SetKeyring(authcrypt.CurrentKeyring().OpenOnly())
+	} else if !authcrypt.Enabled() {
+		log.Error("auth encryption: AUTH_ENCRYPTION_KEYS is required to encrypt auth files")
+		return
+	}
+
+	changed, errConvert := authcrypt.ConvertDir(authDir)
+	if len(changed) > 0 {
+		if persister, ok := sdkAuth.GetTokenStore().(interface {
+			PersistAuthFiles(ctx context.Context, message string, paths ...string) error
+		}); ok {
+			message := fmt.Sprintf("%s %d auth files", action, len(changed))
+			if errPersist := persister.PersistAuthFiles(context.Background(), message, changed...); errPersist != nil {
+				log.Errorf("auth encryption: persist auth files: %v", errPersist)
+				return
+			}
+		}
+	}
+	if errConvert != nil {
+		log.Errorf("auth encryption: %v", errConvert)
+		return
+	}
+	fmt.Printf("Auth files %s: %d in %s\n", action, len(changed), authDir)
+}
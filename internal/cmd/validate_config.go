@@ -0,0 +1,468 @@
+// Package cmd contains CLI helpers. This file implements the offline config validation
+// behind -validate-config and the routing dry run behind -explain-route.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/runtime/executor/helps"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/watcher/synthesizer"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultExplainProtocol is the source protocol assumed by -explain-route.
+const DefaultExplainProtocol = "openai"
+
+// explainProtocols lists the client protocols a request can arrive in.
+var explainProtocols = map[string]string{
+	"openai":          "openai",
+	"responses":       "responses",
+	"openai-response": "responses",
+	"claude":          "claude",
+	"gemini":          "gemini",
+	"interactions":    "interactions",
+	"ollama":          "ollama",
+}
+
+// ValidateConfigOptions configures DoValidateConfig.
+type ValidateConfigOptions struct {
+	// ConfigPath is the YAML file to validate.
+	ConfigPath string
+	// Model, when set, explains which credentials a request for it would be routed to.
+	Model string
+	// SourceProtocol is the client protocol of the explained request. Empty means openai.
+	SourceProtocol string
+	// Out receives the report. Nil means stdout.
+	Out io.Writer
+}
+
+// DoValidateConfig loads the config file the way the server does, synthesizes the
+// credentials from it and the auth directory, and prints the resulting model catalog,
+// model mappings and exclusions without contacting any upstream. It returns the process
+// exit code: 0 when the config is valid and 1 when any error was found, so the command
+// can gate config changes.
+func DoValidateConfig(opts ValidateConfigOptions) int {
+	out := opts.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	_, _ = fmt.Fprintf(out, "Config: %s\n", opts.ConfigPath)
+
+	var report config.ValidationReport
+	var cfg *config.Config
+	var auths []*coreauth.Auth
+	var authDir string
+	protocol, knownProtocol := explainProtocols[strings.ToLower(strings.TrimSpace(opts.SourceProtocol))]
+	if strings.TrimSpace(opts.SourceProtocol) == "" {
+		protocol, knownProtocol = DefaultExplainProtocol, true
+	}
+
+	logged := captureValidationLogs(func() {
+		data, errRead := os.ReadFile(opts.ConfigPath)
+		if errRead != nil {
+			report.AddError("read config: %v", errRead)
+			return
+		}
+		cfg, report = config.ValidateConfigBytes(data)
+		if cfg == nil {
+			return
+		}
+		resolved, errResolve := util.ResolveAuthDir(cfg.AuthDir)
+		if errResolve != nil {
+			report.AddError("resolve auth-dir: %v", errResolve)
+			return
+		}
+		authDir = resolved
+		cfg.AuthDir = resolved
+		auths = synthesizeValidationAuths(cfg)
+		cliproxy.RegisterModelsOffline(context.Background(), cfg, auths)
+	})
+	defer func() {
+		for _, a := range auths {
+			registry.GetGlobalRegistry().UnregisterClient(a.ID)
+		}
+	}()
+	for _, entry := range logged {
+		if entry.level <= log.ErrorLevel {
+			report.AddError("%s", entry.message)
+		} else {
+			report.AddWarning("%s", entry.message)
+		}
+	}
+
+	if cfg != nil {
+		_, _ = fmt.Fprintf(out, "Auth directory: %s\n", authDir)
+		printCredentialCatalog(out, auths)
+		printModelMappings(out, cfg, auths)
+		printExcludedModels(out, cfg)
+		if model := strings.TrimSpace(opts.Model); model != "" {
+			if !knownProtocol {
+				report.AddError("unknown source protocol %q", opts.SourceProtocol)
+			} else {
+				explainRoute(out, cfg, auths, model, protocol, &report)
+			}
+		}
+	}
+
+	printValidationIssues(out, "Warnings", report.Warnings)
+	printValidationIssues(out, "Errors", report.Errors)
+	if !report.OK() {
+		_, _ = fmt.Fprintln(out, "\nResult: INVALID")
+		return 1
+	}
+	_, _ = fmt.Fprintln(out, "\nResult: OK")
+	return 0
+}
+
+// synthesizeValidationAuths builds the runtime auths from the config and auth directory,
+// in the same order the watcher does.
+func synthesizeValidationAuths(cfg *config.Config) []*coreauth.Auth {
+	ctx := &synthesizer.SynthesisContext{
+		Config:      cfg,
+		AuthDir:     cfg.AuthDir,
+		Now:         time.Now(),
+		IDGenerator: synthesizer.NewStableIDGenerator(),
+	}
+	var out []*coreauth.Auth
+	if generated, errSynthesize := synthesizer.NewConfigSynthesizer().Synthesize(ctx); errSynthesize == nil {
+		out = append(out, generated...)
+	} else {
+		log.Errorf("synthesize config credentials: %v", errSynthesize)
+	}
+	if generated, errSynthesize := synthesizer.NewFileSynthesizer().Synthesize(ctx); errSynthesize == nil {
+		out = append(out, generated...)
+	} else {
+		log.Errorf("synthesize auth files: %v", errSynthesize)
+	}
+	return out
+}
+
+type validationLogEntry struct {
+	level   log.Level
+	message string
+}
+
+// validationLogHook collects warnings and errors logged while the config is processed,
+// since most normalization steps report problems only through the log.
+type validationLogHook struct {
+	mu      sync.Mutex
+	entries []validationLogEntry
+}
+
+func (h *validationLogHook) Levels() []log.Level {
+	return []log.Level{log.PanicLevel, log.FatalLevel, log.ErrorLevel, log.WarnLevel}
+}
+
+func (h *validationLogHook) Fire(entry *log.Entry) error {
+	message := entry.Message
+	if len(entry.Data) > 0 {
+		keys := make([]string, 0, len(entry.Data))
+		for key := range entry.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			parts = append(parts, fmt.Sprintf("%s=%v", key, entry.Data[key]))
+		}
+		message += " (" + strings.Join(parts, " ") + ")"
+	}
+	h.mu.Lock()
+	h.entries = append(h.entries, validationLogEntry{level: entry.Level, message: message})
+	h.mu.Unlock()
+	return nil
+}
+
+// captureValidationLogs runs fn with the standard logger silenced and returns the
+// warnings and errors it logged.
+func captureValidationLogs(fn func()) []validationLogEntry {
+	logger := log.StandardLogger()
+	hook := &validationLogHook{}
+	previousHooks := logger.ReplaceHooks(make(log.LevelHooks))
+	previousOut := logger.Out
+	previousLevel := logger.GetLevel()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+	if previousLevel < log.WarnLevel {
+		logger.SetLevel(log.WarnLevel)
+	}
+	defer func() {
+		logger.ReplaceHooks(previousHooks)
+		logger.SetOutput(previousOut)
+		logger.SetLevel(previousLevel)
+	}()
+	fn()
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+	return hook.entries
+}
+
+func describeValidationAuth(a *coreauth.Auth) string {
+	parts := []string{a.ID, "provider=" + a.ExecutorKey()}
+	if kind := a.AuthKind(); kind != "" {
+		parts = append(parts, "kind="+kind)
+	}
+	if source := strings.TrimSpace(a.Attributes["source"]); source != "" {
+		parts = append(parts, "source="+source)
+	} else if a.FileName != "" {
+		parts = append(parts, "file="+a.FileName)
+	}
+	if a.Prefix != "" {
+		parts = append(parts, "prefix="+a.Prefix)
+	}
+	if a.Disabled {
+		parts = append(parts, "disabled")
+	}
+	return strings.Join(parts, " ")
+}
+
+func printCredentialCatalog(out io.Writer, auths []*coreauth.Auth) {
+	_, _ = fmt.Fprintf(out, "\nCredentials (%d):\n", len(auths))
+	for _, a := range auths {
+		_, _ = fmt.Fprintf(out, "  %s\n", describeValidationAuth(a))
+		if a.Disabled {
+			continue
+		}
+		ids := clientModelIDs(a.ID)
+		if len(ids) == 0 {
+			_, _ = fmt.Fprintln(out, "    models: none")
+		} else {
+			_, _ = fmt.Fprintf(out, "    models (%d): %s\n", len(ids), strings.Join(ids, ", "))
+		}
+		if excluded := strings.TrimSpace(a.Attributes["excluded_models"]); excluded != "" {
+			_, _ = fmt.Fprintf(out, "    excluded: %s\n", strings.ReplaceAll(excluded, ",", ", "))
+		}
+	}
+}
+
+func clientModelIDs(authID string) []string {
+	models := registry.GetGlobalRegistry().GetModelsForClient(authID)
+	ids := make([]string, 0, len(models))
+	for _, model := range models {
+		if model != nil && model.ID != "" {
+			ids = append(ids, model.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func printModelMappings(out io.Writer, cfg *config.Config, auths []*coreauth.Auth) {
+	_, _ = fmt.Fprintln(out, "\nModel aliases:")
+	channels := make([]string, 0, len(cfg.OAuthModelAlias))
+	for channel := range cfg.OAuthModelAlias {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	if len(channels) == 0 {
+		_, _ = fmt.Fprintln(out, "  none")
+	}
+	for _, channel := range channels {
+		for _, alias := range cfg.OAuthModelAlias[channel] {
+			line := fmt.Sprintf("  %s: %s -> %s", channel, alias.Name, alias.Alias)
+			if alias.Fork {
+				line += " (fork, original kept)"
+			}
+			if alias.ForceMapping {
+				line += " (force-mapping)"
+			}
+			_, _ = fmt.Fprintln(out, line)
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "\nModel prefixes (force-model-prefix: %t):\n", cfg.ForceModelPrefix)
+	prefixes, counts := credentialPrefixes(auths)
+	if len(prefixes) == 0 {
+		_, _ = fmt.Fprintln(out, "  none")
+	}
+	for _, prefix := range prefixes {
+		_, _ = fmt.Fprintf(out, "  %s/ (credentials: %d)\n", prefix, counts[prefix])
+	}
+
+	_, _ = fmt.Fprintln(out, "\nModel fallbacks:")
+	if len(cfg.ModelFallbacks) == 0 {
+		_, _ = fmt.Fprintln(out, "  none")
+	}
+	for _, chain := range cfg.ModelFallbacks {
+		targets := make([]string, 0, len(chain.Targets))
+		for _, target := range chain.Targets {
+			targets = append(targets, describeFallbackTarget(target))
+		}
+		_, _ = fmt.Fprintf(out, "  %s -> %s\n", chain.Model, strings.Join(targets, " -> "))
+	}
+}
+
+// credentialPrefixes counts the enabled credentials namespacing their models under each prefix.
+func credentialPrefixes(auths []*coreauth.Auth) ([]string, map[string]int) {
+	counts := make(map[string]int)
+	for _, a := range auths {
+		if prefix := strings.TrimSpace(a.Prefix); prefix != "" && !a.Disabled {
+			counts[prefix]++
+		}
+	}
+	prefixes := make([]string, 0, len(counts))
+	for prefix := range counts {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes, counts
+}
+
+func describeFallbackTarget(target config.ModelFallbackTarget) string {
+	model := target.Model
+	if model == "" {
+		model = "(same model)"
+	}
+	if target.Provider != "" {
+		model += " via " + target.Provider
+	}
+	if target.AuthKind != "" {
+		model += " (" + target.AuthKind + ")"
+	}
+	return model
+}
+
+func printExcludedModels(out io.Writer, cfg *config.Config) {
+	_, _ = fmt.Fprintln(out, "\nExcluded models (oauth-excluded-models):")
+	providers := make([]string, 0, len(cfg.OAuthExcludedModels))
+	for provider := range cfg.OAuthExcludedModels {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	if len(providers) == 0 {
+		_, _ = fmt.Fprintln(out, "  none")
+	}
+	for _, provider := range providers {
+		_, _ = fmt.Fprintf(out, "  %s: %s\n", provider, strings.Join(cfg.OAuthExcludedModels[provider], ", "))
+	}
+}
+
+// routeCandidates returns the enabled auths whose registered catalog contains model,
+// optionally restricted to providers and an auth kind.
+func routeCandidates(auths []*coreauth.Auth, model string, providers []string, authKind string) []*coreauth.Auth {
+	reg := registry.GetGlobalRegistry()
+	var out []*coreauth.Auth
+	for _, a := range auths {
+		if a.Disabled || !reg.ClientSupportsModel(a.ID, model) {
+			continue
+		}
+		if len(providers) > 0 && !containsFold(providers, a.ExecutorKey()) {
+			continue
+		}
+		if authKind != "" && a.AuthKind() != authKind {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// explainRoute prints the providers, credentials, fallback hops and payload rules that
+// a request for model arriving in protocol would involve.
+func explainRoute(out io.Writer, cfg *config.Config, auths []*coreauth.Auth, model, protocol string, report *config.ValidationReport) {
+	base := strings.TrimSpace(thinking.ParseSuffix(model).ModelName)
+	_, _ = fmt.Fprintf(out, "\nRoute for %s from %s:\n", model, protocol)
+	providers := registry.GetGlobalRegistry().GetModelProviders(base)
+	if len(providers) == 0 {
+		_, _ = fmt.Fprintln(out, "  providers: none")
+	} else {
+		_, _ = fmt.Fprintf(out, "  providers: %s\n", strings.Join(providers, ", "))
+	}
+	candidates := routeCandidates(auths, base, nil, "")
+	total := len(candidates)
+	printRouteCandidates(out, "  ", candidates)
+
+	chain, hasChain := cfg.ModelFallbackChain(model)
+	if !hasChain {
+		chain, hasChain = cfg.ModelFallbackChain(base)
+	}
+	if hasChain {
+		_, _ = fmt.Fprintln(out, "  fallbacks:")
+		for i, target := range chain.Targets {
+			hopModel := target.Model
+			if hopModel == "" {
+				hopModel = base
+			}
+			hopModel = strings.TrimSpace(thinking.ParseSuffix(hopModel).ModelName)
+			var hopProviders []string
+			if target.Provider != "" {
+				hopProviders = []string{target.Provider}
+			}
+			hopCandidates := routeCandidates(auths, hopModel, hopProviders, target.AuthKind)
+			total += len(hopCandidates)
+			_, _ = fmt.Fprintf(out, "    hop %d: %s\n", i+1, describeFallbackTarget(target))
+			printRouteCandidates(out, "      ", hopCandidates)
+		}
+	}
+	if total == 0 {
+		report.AddError("no enabled credential serves model %s", model)
+	}
+
+	matches := helps.MatchingPayloadRules(cfg, base, model, protocol)
+	if len(matches) == 0 {
+		_, _ = fmt.Fprintln(out, "  payload rules: none")
+		return
+	}
+	_, _ = fmt.Fprintln(out, "  payload rules:")
+	for _, match := range matches {
+		line := fmt.Sprintf("    %s[%d] model=%s params=%s", match.Section, match.Index, match.Entry.Name, strings.Join(match.Params, ","))
+		var gates []string
+		if match.Entry.Protocol != "" {
+			gates = append(gates, "protocol="+match.Entry.Protocol)
+		}
+		if len(match.Entry.Headers) > 0 {
+			gates = append(gates, "headers")
+		}
+		if len(match.Entry.Match)+len(match.Entry.NotMatch)+len(match.Entry.Exist)+len(match.Entry.NotExist) > 0 {
+			gates = append(gates, "payload conditions")
+		}
+		if len(gates) > 0 {
+			line += " (only with " + strings.Join(gates, ", ") + ")"
+		}
+		_, _ = fmt.Fprintln(out, line)
+	}
+}
+
+func printRouteCandidates(out io.Writer, indent string, candidates []*coreauth.Auth) {
+	if len(candidates) == 0 {
+		_, _ = fmt.Fprintf(out, "%scandidates: none\n", indent)
+		return
+	}
+	_, _ = fmt.Fprintf(out, "%scandidates (%d):\n", indent, len(candidates))
+	for _, a := range candidates {
+		_, _ = fmt.Fprintf(out, "%s  %s\n", indent, describeValidationAuth(a))
+	}
+}
+
+func printValidationIssues(out io.Writer, title string, issues []string) {
+	if len(issues) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(out, "\n%s (%d):\n", title, len(issues))
+	for _, issue := range issues {
+		_, _ = fmt.Fprintf(out, "  - %s\n", issue)
+	}
+}
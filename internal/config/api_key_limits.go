@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// APIKeyLimitWildcard is the api-key value of the default limit entry.
+const APIKeyLimitWildcard = "*"
+
+// IsZero reports whether the entry configures no limit at all.
+func (l APIKeyLimit) IsZero() bool {
+	return l.RequestsPerMinute <= 0 && l.MaxConcurrentRequests <= 0 && l.DailyTokenBudget <= 0 && l.MonthlyTokenBudget <= 0
+}
+
+// ValidateAPIKeyLimit rejects negative limit values.
+func ValidateAPIKeyLimit(limit APIKeyLimit) error {
+	switch {
+	case limit.RequestsPerMinute < 0:
+		return fmt.Errorf("requests-per-minute must not be negative")
+	case limit.MaxConcurrentRequests < 0:
+		return fmt.Errorf("max-concurrent-requests must not be negative")
+	case limit.DailyTokenBudget < 0:
+		return fmt.Errorf("daily-token-budget must not be negative")
+	case limit.MonthlyTokenBudget < 0:
+		return fmt.Errorf("monthly-token-budget must not be negative")
+	}
+	return nil
+}
+
+// SanitizeAPIKeyLimits trims keys, clamps negative values to zero and drops
+// entries without a key or without any limit. The first entry for a key wins.
+func (cfg *Config) SanitizeAPIKeyLimits() {
+	if cfg == nil {
+		return
+	}
+	cfg.APIKeyLimits = NormalizeAPIKeyLimits(cfg.APIKeyLimits)
+}
+
+// NormalizeAPIKeyLimits returns the sanitized form of entries.
+func NormalizeAPIKeyLimits(entries []APIKeyLimit) []APIKeyLimit {
+	if len(entries) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(entries))
+	out := make([]APIKeyLimit, 0, len(entries))
+	for _, entry := range entries {
+		entry.APIKey = strings.TrimSpace(entry.APIKey)
+		if entry.APIKey == "" {
+			continue
+		}
+		if _, exists := seen[entry.APIKey]; exists {
+			continue
+		}
+		entry.RequestsPerMinute = max(entry.RequestsPerMinute, 0)
+		entry.MaxConcurrentRequests = max(entry.MaxConcurrentRequests, 0)
+		entry.DailyTokenBudget = max(entry.DailyTokenBudget, 0)
+		entry.MonthlyTokenBudget = max(entry.MonthlyTokenBudget, 0)
+		if entry.IsZero() {
+			continue
+		}
+		seen[entry.APIKey] = struct{}{}
+		out = append(out, entry)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
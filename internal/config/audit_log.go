@@ -0,0 +1,26 @@
+package config
+
+import "strings"
+
+const (
+	// AuditLogBackendFile stores audit events as daily JSONL segments on local disk.
+	AuditLogBackendFile = "file"
+	// AuditLogBackendPostgres stores audit events in the Postgres token store.
+	AuditLogBackendPostgres = "postgres"
+)
+
+// NormalizeAuditLogBackend returns a supported audit log backend name.
+// Empty and unknown values fall back to AuditLogBackendFile.
+func NormalizeAuditLogBackend(backend string) string {
+	switch strings.ToLower(strings.TrimSpace(backend)) {
+	case AuditLogBackendPostgres, "postgresql", "pg":
+		return AuditLogBackendPostgres
+	default:
+		return AuditLogBackendFile
+	}
+}
+
+// EffectiveBackend returns the normalized backend for the audit log.
+func (c AuditLogConfig) EffectiveBackend() string {
+	return NormalizeAuditLogBackend(c.Backend)
+}
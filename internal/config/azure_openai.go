@@ -0,0 +1,200 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+)
+
+const (
+	// DefaultAzureOpenAIAPIVersion is used when an Azure OpenAI credential does not set an
+	// api-version. It is the oldest version that serves both Chat Completions and Responses.
+	DefaultAzureOpenAIAPIVersion = "2025-04-01-preview"
+
+	// DefaultAzureOpenAIScope is the Entra ID scope requested for Azure OpenAI bearer tokens.
+	DefaultAzureOpenAIScope = "https://cognitiveservices.azure.com/.default"
+
+	// DefaultAzureAuthorityHost is the Entra ID authority for the public Azure cloud.
+	DefaultAzureAuthorityHost = "https://login.microsoftonline.com"
+)
+
+// Azure OpenAI wire APIs a deployment can be served through.
+const (
+	AzureOpenAIWireAPIChat      = "chat"
+	AzureOpenAIWireAPIResponses = "responses"
+)
+
+// AzureOpenAIKey represents the configuration for an Azure OpenAI resource.
+// Requests are authenticated either with a resource key sent in the "api-key" header,
+// or with an Entra ID bearer token obtained through the client-credentials flow from
+// TenantID/ClientID/ClientSecret.
+type AzureOpenAIKey struct {
+	// Endpoint is the resource endpoint, e.g. "https://my-resource.openai.azure.com".
+	Endpoint string `yaml:"endpoint" json:"endpoint"`
+
+	// APIVersion is the api-version query parameter. Defaults to DefaultAzureOpenAIAPIVersion.
+	APIVersion string `yaml:"api-version,omitempty" json:"api-version,omitempty"`
+
+	// APIKey is the resource key sent as the "api-key" header. When set, Entra ID is not used.
+	APIKey string `yaml:"api-key,omitempty" json:"api-key,omitempty"`
+
+	// TenantID is the Entra ID tenant used for client-credentials token requests.
+	TenantID string `yaml:"tenant-id,omitempty" json:"tenant-id,omitempty"`
+
+	// ClientID is the Entra ID application (client) ID.
+	ClientID string `yaml:"client-id,omitempty" json:"client-id,omitempty"`
+
+	// ClientSecret is the Entra ID application secret.
+	ClientSecret string `yaml:"client-secret,omitempty" json:"client-secret,omitempty"`
+
+	// AuthorityHost optionally overrides the Entra ID authority, e.g. for sovereign clouds.
+	// Defaults to DefaultAzureAuthorityHost.
+	AuthorityHost string `yaml:"authority-host,omitempty" json:"authority-host,omitempty"`
+
+	// Scope optionally overrides the Entra ID token scope. Defaults to DefaultAzureOpenAIScope.
+	Scope string `yaml:"scope,omitempty" json:"scope,omitempty"`
+
+	// Priority controls selection preference when multiple credentials match.
+	// Higher values are preferred; defaults to 0.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Weight controls proportional selection under weighted-round-robin.
+	// An omitted value defaults to 1; non-positive values exclude this credential; maximum 1,000,000.
+	Weight *int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
+	// Prefix optionally namespaces model aliases for this credential (e.g., "teamA/gpt-4o").
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// ProxyURL optionally overrides the global proxy for this credential.
+	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
+
+	// Headers optionally adds extra HTTP headers for requests sent with this credential.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// Deployments maps Azure deployment names to the model names exposed to clients.
+	Deployments []AzureOpenAIDeployment `yaml:"deployments" json:"deployments"`
+
+	// ExcludedModels lists model IDs that should be excluded for this provider.
+	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// DisableCooling overrides the global cooling policy for this credential when set.
+	// True disables auth/model cooldowns; false explicitly enables them.
+	DisableCooling *bool `yaml:"disable-cooling,omitempty" json:"disable-cooling,omitempty"`
+
+	// RequestRetry optionally overrides the global request-retry for this credential.
+	// Nil or a negative value means "use the global request-retry". 0 disables retries.
+	RequestRetry *int `yaml:"request-retry,omitempty" json:"request-retry,omitempty"`
+
+	// RequestScopedErrors configures custom classification rules for upstream errors.
+	// Azure content-filter rejections are request-scoped by default; a matching rule
+	// here takes precedence, e.g. to let another region's filter policy try the request.
+	RequestScopedErrors []RequestScopedErrorRule `yaml:"request-scoped-errors,omitempty" json:"request-scoped-errors,omitempty"`
+}
+
+// AzureOpenAIDeployment maps one Azure deployment to a client-visible model name.
+type AzureOpenAIDeployment struct {
+	// Name is the Azure deployment name used in the request path.
+	Name string `yaml:"name" json:"name"`
+
+	// Alias is the model name clients request. Defaults to Name.
+	Alias string `yaml:"alias,omitempty" json:"alias,omitempty"`
+
+	// WireAPI selects the upstream API: "chat" (Chat Completions) or "responses".
+	// When empty, Responses API clients use "responses" and everything else uses "chat".
+	WireAPI string `yaml:"wire-api,omitempty" json:"wire-api,omitempty"`
+
+	// DisplayName is the optional human-readable name shown in model catalogs.
+	DisplayName string `yaml:"display-name,omitempty" json:"display-name,omitempty"`
+
+	// MaxContextLength overrides the context window advertised to Codex clients.
+	MaxContextLength int `yaml:"max-context-length,omitempty" json:"max-context-length,omitempty"`
+
+	// Thinking configures the reasoning capability of the deployed model.
+	Thinking *registry.ThinkingSupport `yaml:"thinking,omitempty" json:"thinking,omitempty"`
+}
+
+func (m AzureOpenAIDeployment) GetName() string                        { return m.Name }
+func (m AzureOpenAIDeployment) GetAlias() string                       { return m.Alias }
+func (m AzureOpenAIDeployment) GetDisplayName() string                 { return m.DisplayName }
+func (m AzureOpenAIDeployment) GetMaxContextLength() int               { return m.MaxContextLength }
+func (m AzureOpenAIDeployment) GetThinking() *registry.ThinkingSupport { return m.Thinking }
+
+// UsesEntraID reports whether the credential authenticates with Entra ID bearer tokens.
+func (k AzureOpenAIKey) UsesEntraID() bool {
+	return k.APIKey == "" && k.TenantID != "" && k.ClientID != "" && k.ClientSecret != ""
+}
+
+// GetAPIKey returns the identity used to match auths back to this entry: the resource
+// key when set, otherwise the Entra ID client ID.
+func (k AzureOpenAIKey) GetAPIKey() string {
+	if k.APIKey != "" {
+		return k.APIKey
+	}
+	return k.ClientID
+}
+func (k AzureOpenAIKey) GetBaseURL() string  { return k.Endpoint }
+func (k AzureOpenAIKey) GetPrefix() string   { return k.Prefix }
+func (k AzureOpenAIKey) GetProxyURL() string { return k.ProxyURL }
+
+// SanitizeAzureOpenAIKeys deduplicates and normalizes Azure OpenAI credentials, dropping
+// entries without an endpoint or without either a resource key or complete Entra ID
+// client credentials.
+func (cfg *Config) SanitizeAzureOpenAIKeys() {
+	if cfg == nil {
+		return
+	}
+
+	seen := make(map[string]struct{}, len(cfg.AzureOpenAIKey))
+	out := cfg.AzureOpenAIKey[:0]
+	for i := range cfg.AzureOpenAIKey {
+		entry := cfg.AzureOpenAIKey[i]
+		entry.Endpoint = strings.TrimRight(strings.TrimSpace(entry.Endpoint), "/")
+		entry.APIKey = strings.TrimSpace(entry.APIKey)
+		entry.TenantID = strings.TrimSpace(entry.TenantID)
+		entry.ClientID = strings.TrimSpace(entry.ClientID)
+		entry.ClientSecret = strings.TrimSpace(entry.ClientSecret)
+		if entry.Endpoint == "" || (entry.APIKey == "" && !entry.UsesEntraID()) {
+			continue
+		}
+		entry.APIVersion = strings.TrimSpace(entry.APIVersion)
+		if entry.APIVersion == "" {
+			entry.APIVersion = DefaultAzureOpenAIAPIVersion
+		}
+		entry.AuthorityHost = strings.TrimRight(strings.TrimSpace(entry.AuthorityHost), "/")
+		entry.Scope = strings.TrimSpace(entry.Scope)
+		entry.Prefix = normalizeModelPrefix(entry.Prefix)
+		entry.ProxyURL = strings.TrimSpace(entry.ProxyURL)
+		entry.Headers = NormalizeHeaders(entry.Headers)
+		entry.ExcludedModels = NormalizeExcludedModels(entry.ExcludedModels)
+
+		deployments := make([]AzureOpenAIDeployment, 0, len(entry.Deployments))
+		for _, deployment := range entry.Deployments {
+			deployment.Name = strings.TrimSpace(deployment.Name)
+			deployment.Alias = strings.TrimSpace(deployment.Alias)
+			if deployment.Name == "" {
+				continue
+			}
+			if deployment.Alias == "" {
+				deployment.Alias = deployment.Name
+			}
+			switch wireAPI := strings.ToLower(strings.TrimSpace(deployment.WireAPI)); wireAPI {
+			case AzureOpenAIWireAPIChat, AzureOpenAIWireAPIResponses:
+				deployment.WireAPI = wireAPI
+			case "chat-completions", "chat_completions":
+				deployment.WireAPI = AzureOpenAIWireAPIChat
+			default:
+				deployment.WireAPI = ""
+			}
+			deployments = append(deployments, deployment)
+		}
+		entry.Deployments = deployments
+
+		uniqueKey := entry.GetAPIKey() + "|" + entry.TenantID + "|" + entry.Endpoint
+		if _, exists := seen[uniqueKey]; exists {
+			continue
+		}
+		seen[uniqueKey] = struct{}{}
+		out = append(out, entry)
+	}
+	cfg.AzureOpenAIKey = out
+}
@@ -0,0 +1,55 @@
+package config
+
+import "testing"
+
+func TestParseConfigBytesAzureOpenAIKeys(t *testing.T) {
+	cfg, errParse := ParseConfigBytes([]byte(`azure-openai:
+  - endpoint: " https://res.openai.azure.com/ "
+    api-key: " key-1 "
+    deployments:
+      - name: prod-gpt4o
+        alias: gpt-4o
+      - name: prod-gpt5
+        wire-api: Chat-Completions
+      - name: prod-o3
+        wire-api: RESPONSES
+      - alias: missing-name
+  - endpoint: https://res.openai.azure.com
+    api-key: key-1
+  - endpoint: https://entra.openai.azure.com
+    tenant-id: tenant
+    client-id: client
+    client-secret: secret
+  - endpoint: https://incomplete.openai.azure.com
+    tenant-id: tenant
+    client-id: client
+`))
+	if errParse != nil {
+		t.Fatalf("ParseConfigBytes() error = %v", errParse)
+	}
+	if len(cfg.AzureOpenAIKey) != 2 {
+		t.Fatalf("len(azure-openai) = %d, want 2 (duplicate and incomplete entra entry dropped): %+v", len(cfg.AzureOpenAIKey), cfg.AzureOpenAIKey)
+	}
+
+	keyed := cfg.AzureOpenAIKey[0]
+	if keyed.Endpoint != "https://res.openai.azure.com" || keyed.APIKey != "key-1" || keyed.UsesEntraID() {
+		t.Fatalf("keyed entry = %+v", keyed)
+	}
+	if keyed.APIVersion != DefaultAzureOpenAIAPIVersion {
+		t.Fatalf("api-version = %q, want default", keyed.APIVersion)
+	}
+	if len(keyed.Deployments) != 3 {
+		t.Fatalf("deployments = %+v, want nameless deployment dropped", keyed.Deployments)
+	}
+	if got := keyed.Deployments[1]; got.Alias != "prod-gpt5" || got.WireAPI != AzureOpenAIWireAPIChat {
+		t.Fatalf("deployment = %+v, want alias defaulted and wire-api chat", got)
+	}
+	if got := keyed.Deployments[2].WireAPI; got != AzureOpenAIWireAPIResponses {
+		t.Fatalf("wire-api = %q, want responses", got)
+	}
+
+	entra := cfg.AzureOpenAIKey[1]
+	if !entra.UsesEntraID() || entra.GetAPIKey() != "client" {
+		t.Fatalf("entra entry = %+v", entra)
+	}
+}
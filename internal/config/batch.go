@@ -0,0 +1,24 @@
+package config
+
+const (
+	// DefaultBatchConcurrencyPerCredential is used when batch.concurrency-per-credential is unset.
+	DefaultBatchConcurrencyPerCredential = 2
+	// DefaultBatchMaxFileSizeMB is used when batch.max-file-size-mb is unset.
+	DefaultBatchMaxFileSizeMB = 100
+)
+
+// EffectiveConcurrencyPerCredential returns the per-credential worker count of batch jobs.
+func (c BatchConfig) EffectiveConcurrencyPerCredential() int {
+	if c.ConcurrencyPerCredential <= 0 {
+		return DefaultBatchConcurrencyPerCredential
+	}
+	return c.ConcurrencyPerCredential
+}
+
+// EffectiveMaxFileSize returns the upload size limit in bytes.
+func (c BatchConfig) EffectiveMaxFileSize() int64 {
+	if c.MaxFileSizeMB <= 0 {
+		return DefaultBatchMaxFileSizeMB << 20
+	}
+	return int64(c.MaxFileSizeMB) << 20
+}
@@ -0,0 +1,129 @@
+package config
+
+import "strings"
+
+// DefaultBedrockRegion is used when a Bedrock credential does not set a region.
+const DefaultBedrockRegion = "us-east-1"
+
+// BedrockKey represents the configuration for an AWS Bedrock credential.
+// Requests are either signed with SigV4 using AccessKeyID/SecretAccessKey (and an
+// optional SessionToken), or authenticated with a Bedrock API key sent as a bearer token.
+type BedrockKey struct {
+	// APIKey is an optional Bedrock API key sent as "Authorization: Bearer".
+	// When set, SigV4 signing is skipped.
+	APIKey string `yaml:"api-key,omitempty" json:"api-key,omitempty"`
+
+	// AccessKeyID is the AWS access key ID used for SigV4 signing.
+	AccessKeyID string `yaml:"access-key-id,omitempty" json:"access-key-id,omitempty"`
+
+	// SecretAccessKey is the AWS secret access key used for SigV4 signing.
+	SecretAccessKey string `yaml:"secret-access-key,omitempty" json:"secret-access-key,omitempty"`
+
+	// SessionToken is the optional STS session token for temporary credentials.
+	SessionToken string `yaml:"session-token,omitempty" json:"session-token,omitempty"`
+
+	// Region is the AWS region hosting the Bedrock runtime endpoint. Defaults to us-east-1.
+	Region string `yaml:"region,omitempty" json:"region,omitempty"`
+
+	// Priority controls selection preference when multiple credentials match.
+	// Higher values are preferred; defaults to 0.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Weight controls proportional selection under weighted-round-robin.
+	// An omitted value defaults to 1; non-positive values exclude this credential; maximum 1,000,000.
+	Weight *int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
+	// Prefix optionally namespaces model aliases for this credential (e.g., "teamA/claude-sonnet-4").
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// BaseURL optionally overrides the Bedrock runtime endpoint
+	// (default https://bedrock-runtime.{region}.amazonaws.com), e.g. for VPC endpoints.
+	BaseURL string `yaml:"base-url,omitempty" json:"base-url,omitempty"`
+
+	// ProxyURL optionally overrides the global proxy for this credential.
+	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
+
+	// Headers optionally adds extra HTTP headers for requests sent with this credential.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// Models maps Bedrock model or inference-profile IDs (Name, e.g.
+	// "us.anthropic.claude-sonnet-4-20250514-v1:0") to client aliases (Alias).
+	// When empty, the built-in Claude models are exposed and mapped to their
+	// "anthropic.<model>-v1:0" Bedrock IDs.
+	Models []ClaudeModel `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// ExcludedModels lists model IDs that should be excluded for this provider.
+	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// DisableCooling overrides the global cooling policy for this credential when set.
+	// True disables auth/model cooldowns; false explicitly enables them.
+	DisableCooling *bool `yaml:"disable-cooling,omitempty" json:"disable-cooling,omitempty"`
+
+	// RequestRetry optionally overrides the global request-retry for this credential.
+	// Nil or a negative value means "use the global request-retry". 0 disables retries.
+	RequestRetry *int `yaml:"request-retry,omitempty" json:"request-retry,omitempty"`
+}
+
+// GetAPIKey returns the identity used to match auths back to this entry: the
+// bearer API key when set, otherwise the access key ID.
+func (k BedrockKey) GetAPIKey() string {
+	if k.APIKey != "" {
+		return k.APIKey
+	}
+	return k.AccessKeyID
+}
+func (k BedrockKey) GetBaseURL() string  { return k.BaseURL }
+func (k BedrockKey) GetPrefix() string   { return k.Prefix }
+func (k BedrockKey) GetProxyURL() string { return k.ProxyURL }
+
+// SanitizeBedrockKeys deduplicates and normalizes Bedrock credentials, dropping
+// entries that carry neither an API key nor a complete access key pair.
+func (cfg *Config) SanitizeBedrockKeys() {
+	if cfg == nil {
+		return
+	}
+
+	seen := make(map[string]struct{}, len(cfg.BedrockKey))
+	out := cfg.BedrockKey[:0]
+	for i := range cfg.BedrockKey {
+		entry := cfg.BedrockKey[i]
+		entry.APIKey = strings.TrimSpace(entry.APIKey)
+		entry.AccessKeyID = strings.TrimSpace(entry.AccessKeyID)
+		entry.SecretAccessKey = strings.TrimSpace(entry.SecretAccessKey)
+		entry.SessionToken = strings.TrimSpace(entry.SessionToken)
+		if entry.APIKey == "" && (entry.AccessKeyID == "" || entry.SecretAccessKey == "") {
+			continue
+		}
+		entry.Region = strings.ToLower(strings.TrimSpace(entry.Region))
+		if entry.Region == "" {
+			entry.Region = DefaultBedrockRegion
+		}
+		entry.Prefix = normalizeModelPrefix(entry.Prefix)
+		entry.BaseURL = strings.TrimRight(strings.TrimSpace(entry.BaseURL), "/")
+		entry.ProxyURL = strings.TrimSpace(entry.ProxyURL)
+		entry.Headers = NormalizeHeaders(entry.Headers)
+		entry.ExcludedModels = NormalizeExcludedModels(entry.ExcludedModels)
+
+		sanitizedModels := make([]ClaudeModel, 0, len(entry.Models))
+		for _, model := range entry.Models {
+			model.Alias = strings.TrimSpace(model.Alias)
+			model.Name = strings.TrimSpace(model.Name)
+			if model.Name == "" {
+				continue
+			}
+			if model.Alias == "" {
+				model.Alias = model.Name
+			}
+			sanitizedModels = append(sanitizedModels, model)
+		}
+		entry.Models = sanitizedModels
+
+		uniqueKey := entry.GetAPIKey() + "|" + entry.Region + "|" + entry.BaseURL
+		if _, exists := seen[uniqueKey]; exists {
+			continue
+		}
+		seen[uniqueKey] = struct{}{}
+		out = append(out, entry)
+	}
+	cfg.BedrockKey = out
+}
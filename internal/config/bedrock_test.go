@@ -0,0 +1,46 @@
+package config
+
+import "testing"
+
+func TestParseConfigBytesBedrockKeys(t *testing.T) {
+	cfg, errParse := ParseConfigBytes([]byte(`bedrock-api-key:
+  - access-key-id: " AKIA1 "
+    secret-access-key: " secret "
+    session-token: " token "
+    region: " US-WEST-2 "
+    base-url: " https://vpce.example.com/ "
+    prefix: " aws "
+    models:
+      - name: us.anthropic.claude-sonnet-4-20250514-v1:0
+        alias: claude-sonnet-4
+      - name: meta.llama3-70b-instruct-v1:0
+  - access-key-id: AKIA2
+  - api-key: bedrock-key
+  - api-key: bedrock-key
+`))
+	if errParse != nil {
+		t.Fatalf("ParseConfigBytes() error = %v", errParse)
+	}
+	if len(cfg.BedrockKey) != 2 {
+		t.Fatalf("len(bedrock-api-key) = %d, want 2 (incomplete key pair and duplicate dropped): %+v", len(cfg.BedrockKey), cfg.BedrockKey)
+	}
+
+	signed := cfg.BedrockKey[0]
+	if signed.AccessKeyID != "AKIA1" || signed.SecretAccessKey != "secret" || signed.SessionToken != "token" {
+		t.Fatalf("credentials not trimmed: %+v", signed)
+	}
+	if signed.Region != "us-west-2" || signed.BaseURL != "https://vpce.example.com" || signed.Prefix != "aws" {
+		t.Fatalf("region/base-url/prefix = %q/%q/%q", signed.Region, signed.BaseURL, signed.Prefix)
+	}
+	if signed.GetAPIKey() != "AKIA1" {
+		t.Fatalf("GetAPIKey() = %q, want access key id", signed.GetAPIKey())
+	}
+	if len(signed.Models) != 2 || signed.Models[1].Alias != "meta.llama3-70b-instruct-v1:0" {
+		t.Fatalf("models = %+v, want alias to default to name", signed.Models)
+	}
+
+	bearer := cfg.BedrockKey[1]
+	if bearer.GetAPIKey() != "bedrock-key" || bearer.Region != DefaultBedrockRegion {
+		t.Fatalf("bearer entry = %+v", bearer)
+	}
+}
@@ -0,0 +1,291 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const clientAPIKeyExpiryDateLayout = "2006-01-02"
+
+// MarshalYAML writes entries without restrictions as plain strings so configs
+// that never use the object form keep their original shape.
+func (k ClientAPIKey) MarshalYAML() (any, error) {
+	if k.IsPlain() {
+		return k.Key, nil
+	}
+	type plain ClientAPIKey
+	return plain(k), nil
+}
+
+// UnmarshalYAML accepts either a plain key string or a key object.
+func (k *ClientAPIKey) UnmarshalYAML(value *yaml.Node) error {
+	if value == nil {
+		return nil
+	}
+	if value.Kind == yaml.ScalarNode {
+		var key string
+		if errDecode := value.Decode(&key); errDecode != nil {
+			return errDecode
+		}
+		*k = ClientAPIKey{Key: key}
+		return nil
+	}
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("api-keys entry must be a string or a mapping")
+	}
+	type plain ClientAPIKey
+	var decoded plain
+	if errDecode := value.Decode(&decoded); errDecode != nil {
+		return errDecode
+	}
+	*k = ClientAPIKey(decoded)
+	return nil
+}
+
+// MarshalJSON mirrors MarshalYAML so management clients that only know the
+// string form keep working.
+func (k ClientAPIKey) MarshalJSON() ([]byte, error) {
+	if k.IsPlain() {
+		return json.Marshal(k.Key)
+	}
+	type plain ClientAPIKey
+	return json.Marshal(plain(k))
+}
+
+// UnmarshalJSON accepts either a plain key string or a key object.
+func (k *ClientAPIKey) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		*k = ClientAPIKey{}
+		return nil
+	}
+	if trimmed[0] == '"' {
+		var key string
+		if errUnmarshal := json.Unmarshal(trimmed, &key); errUnmarshal != nil {
+			return errUnmarshal
+		}
+		*k = ClientAPIKey{Key: key}
+		return nil
+	}
+	type plain ClientAPIKey
+	var decoded plain
+	if errUnmarshal := json.Unmarshal(trimmed, &decoded); errUnmarshal != nil {
+		return errUnmarshal
+	}
+	*k = ClientAPIKey(decoded)
+	return nil
+}
+
+// IsPlain reports whether the entry carries nothing but the key itself.
+func (k ClientAPIKey) IsPlain() bool {
+	return k.Label == "" && len(k.Models) == 0 && len(k.Providers) == 0 && k.Prefix == "" && k.ExpiresAt == "" && !k.Disabled
+}
+
+// ExpiryTime parses ExpiresAt. The zero time is returned when no expiry is set.
+func (k ClientAPIKey) ExpiryTime() (time.Time, error) {
+	return parseKeyExpiry(k.ExpiresAt)
+}
+
+// parseKeyExpiry parses an RFC 3339 timestamp or YYYY-MM-DD date. The zero time is
+// returned for an empty value.
+func parseKeyExpiry(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if parsed, errParse := time.Parse(time.RFC3339, raw); errParse == nil {
+		return parsed, nil
+	}
+	parsed, errParse := time.Parse(clientAPIKeyExpiryDateLayout, raw)
+	if errParse != nil {
+		return time.Time{}, fmt.Errorf("invalid expires-at %q: expected RFC 3339 timestamp or YYYY-MM-DD", raw)
+	}
+	return parsed, nil
+}
+
+// Expired reports whether the key is past its expiry at now. An unparsable
+// expiry counts as expired so a typo never grants open-ended access.
+func (k ClientAPIKey) Expired(now time.Time) bool {
+	expiry, errExpiry := k.ExpiryTime()
+	if errExpiry != nil {
+		return true
+	}
+	return !expiry.IsZero() && !now.Before(expiry)
+}
+
+// AllowsModel reports whether model matches one of the Models globs. Matching is
+// case-insensitive and '*' matches any run of characters.
+func (k ClientAPIKey) AllowsModel(model string) bool {
+	if len(k.Models) == 0 {
+		return true
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+	for _, pattern := range k.Models {
+		if matchModelGlob(strings.ToLower(strings.TrimSpace(pattern)), model) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsProvider reports whether requests may be routed to provider.
+func (k ClientAPIKey) AllowsProvider(provider string) bool {
+	if len(k.Providers) == 0 {
+		return true
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	for _, allowed := range k.Providers {
+		if strings.ToLower(strings.TrimSpace(allowed)) == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeModel applies the forced prefix to model. It returns the routed model name
+// and the name with the prefix removed, which is what Models globs are matched against.
+func (k ClientAPIKey) ScopeModel(model string) (routed string, unscoped string) {
+	model = strings.TrimSpace(model)
+	prefix := strings.Trim(strings.TrimSpace(k.Prefix), "/")
+	if prefix == "" || model == "" {
+		return model, model
+	}
+	if rest, ok := strings.CutPrefix(model, prefix+"/"); ok {
+		return model, rest
+	}
+	return prefix + "/" + model, model
+}
+
+// ValidateClientAPIKey rejects entries that cannot be enforced.
+func ValidateClientAPIKey(entry ClientAPIKey) error {
+	if strings.TrimSpace(entry.Key) == "" {
+		return fmt.Errorf("key is required")
+	}
+	if _, errExpiry := entry.ExpiryTime(); errExpiry != nil {
+		return errExpiry
+	}
+	return nil
+}
+
+// NormalizeClientAPIKey trims the entry's fields, lowercases providers and drops
+// empty list items.
+func NormalizeClientAPIKey(entry ClientAPIKey) ClientAPIKey {
+	entry.Key = strings.TrimSpace(entry.Key)
+	entry.Label = strings.TrimSpace(entry.Label)
+	entry.Prefix = strings.Trim(strings.TrimSpace(entry.Prefix), "/")
+	entry.ExpiresAt = strings.TrimSpace(entry.ExpiresAt)
+	entry.Models = normalizeTrimmedList(entry.Models, false)
+	entry.Providers = normalizeTrimmedList(entry.Providers, true)
+	return entry
+}
+
+// SanitizeClientAPIKeys normalizes every api-keys entry and warns about
+// expiry values that will make a key unusable.
+func (cfg *Config) SanitizeClientAPIKeys() {
+	if cfg == nil {
+		return
+	}
+	for i := range cfg.APIKeys {
+		entry := cfg.APIKeys[i]
+		if entry.IsPlain() {
+			continue
+		}
+		entry = NormalizeClientAPIKey(entry)
+		if _, errExpiry := entry.ExpiryTime(); errExpiry != nil {
+			label := entry.Label
+			if label == "" {
+				label = fmt.Sprintf("#%d", i)
+			}
+			log.Warnf("api-keys entry %s: %v; the key will be refused", label, errExpiry)
+		}
+		cfg.APIKeys[i] = entry
+	}
+}
+
+// ClientAPIKeyValues returns the key strings of entries in order.
+func ClientAPIKeyValues(entries []ClientAPIKey) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, entry.Key)
+	}
+	return out
+}
+
+// ClientAPIKeysFromStrings wraps plain key strings as unrestricted entries.
+func ClientAPIKeysFromStrings(keys []string) []ClientAPIKey {
+	if keys == nil {
+		return nil
+	}
+	out := make([]ClientAPIKey, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, ClientAPIKey{Key: key})
+	}
+	return out
+}
+
+// normalizeTrimmedList trims values, optionally lowercases them and drops empty
+// items and duplicates. It returns nil when nothing is left.
+func normalizeTrimmedList(values []string, lower bool) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if lower {
+			value = strings.ToLower(value)
+		}
+		if value == "" {
+			continue
+		}
+		if _, exists := seen[value]; exists {
+			continue
+		}
+		seen[value] = struct{}{}
+		out = append(out, value)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// matchModelGlob matches value against pattern where '*' matches zero or more characters.
+func matchModelGlob(pattern, value string) bool {
+	if pattern == "" {
+		return false
+	}
+	pi, vi := 0, 0
+	starIdx, matchIdx := -1, 0
+	for vi < len(value) {
+		switch {
+		case pi < len(pattern) && pattern[pi] == value[vi]:
+			pi++
+			vi++
+		case pi < len(pattern) && pattern[pi] == '*':
+			starIdx = pi
+			matchIdx = vi
+			pi++
+		case starIdx != -1:
+			pi = starIdx + 1
+			matchIdx++
+			vi = matchIdx
+		default:
+			return false
+		}
+	}
+	for pi < len(pattern) && pattern[pi] == '*' {
+		pi++
+	}
+	return pi == len(pattern)
+}
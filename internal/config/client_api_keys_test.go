@@ -0,0 +1,114 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientAPIKeysParseStringAndObjectEntries(t *testing.T) {
+	cfg, errParse := ParseConfigBytes([]byte(`api-keys:
+  - plain-key
+  - key: scoped-key
+    label: " team-a "
+    models: ["gpt-5*", " "]
+    providers: ["Codex"]
+    prefix: "/team-a/"
+    expires-at: "2030-01-02"
+`))
+	if errParse != nil {
+		t.Fatalf("ParseConfigBytes() error = %v", errParse)
+	}
+	if len(cfg.APIKeys) != 2 {
+		t.Fatalf("api-keys = %#v, want 2 entries", cfg.APIKeys)
+	}
+	if !cfg.APIKeys[0].IsPlain() || cfg.APIKeys[0].Key != "plain-key" {
+		t.Fatalf("api-keys[0] = %#v, want plain key", cfg.APIKeys[0])
+	}
+	scoped := cfg.APIKeys[1]
+	if scoped.Label != "team-a" || scoped.Prefix != "team-a" || len(scoped.Models) != 1 || scoped.Providers[0] != "codex" {
+		t.Fatalf("api-keys[1] = %#v, want normalized entry", scoped)
+	}
+	expiry, errExpiry := scoped.ExpiryTime()
+	if errExpiry != nil || !expiry.Equal(time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("ExpiryTime() = %v, %v", expiry, errExpiry)
+	}
+}
+
+func TestClientAPIKeysJSONKeepsStringForm(t *testing.T) {
+	data, errMarshal := json.Marshal([]ClientAPIKey{{Key: "plain"}, {Key: "scoped", Disabled: true}})
+	if errMarshal != nil {
+		t.Fatalf("Marshal() error = %v", errMarshal)
+	}
+	if string(data) != `["plain",{"key":"scoped","disabled":true}]` {
+		t.Fatalf("Marshal() = %s", data)
+	}
+	var decoded []ClientAPIKey
+	if errUnmarshal := json.Unmarshal(data, &decoded); errUnmarshal != nil {
+		t.Fatalf("Unmarshal() error = %v", errUnmarshal)
+	}
+	if decoded[0].Key != "plain" || decoded[1].Key != "scoped" || !decoded[1].Disabled {
+		t.Fatalf("Unmarshal() = %#v", decoded)
+	}
+}
+
+func TestClientAPIKeysSavePreservesMixedForms(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if errWrite := os.WriteFile(configPath, []byte("# keys\napi-keys:\n  - first\n  - second\n"), 0644); errWrite != nil {
+		t.Fatalf("WriteFile() error = %v", errWrite)
+	}
+	cfg := &Config{}
+	cfg.APIKeys = []ClientAPIKey{{Key: "first"}, {Key: "second", Models: []string{"gpt-5*"}}}
+	if errSave := SaveConfigPreserveComments(configPath, cfg); errSave != nil {
+		t.Fatalf("SaveConfigPreserveComments() error = %v", errSave)
+	}
+	saved, errRead := os.ReadFile(configPath)
+	if errRead != nil {
+		t.Fatalf("ReadFile() error = %v", errRead)
+	}
+	for _, want := range []string{"# keys", "- first", "- key: second", "- gpt-5*"} {
+		if !strings.Contains(string(saved), want) {
+			t.Fatalf("saved config missing %q:\n%s", want, saved)
+		}
+	}
+
+	reloaded, errParse := ParseConfigBytes(saved)
+	if errParse != nil {
+		t.Fatalf("ParseConfigBytes() error = %v", errParse)
+	}
+	if len(reloaded.APIKeys) != 2 || reloaded.APIKeys[1].Models[0] != "gpt-5*" {
+		t.Fatalf("reloaded api-keys = %#v", reloaded.APIKeys)
+	}
+}
+
+func TestClientAPIKeyPolicyChecks(t *testing.T) {
+	key := ClientAPIKey{Key: "k", Models: []string{"GPT-5*", "claude-*-sonnet"}, Providers: []string{"codex"}, Prefix: "team-a"}
+	for model, want := range map[string]bool{"gpt-5": true, "gpt-5-codex": true, "claude-4-sonnet": true, "gemini-2.5-pro": false} {
+		if got := key.AllowsModel(model); got != want {
+			t.Errorf("AllowsModel(%q) = %v, want %v", model, got, want)
+		}
+	}
+	if !key.AllowsProvider("Codex") || key.AllowsProvider("gemini") {
+		t.Fatalf("AllowsProvider() mismatch")
+	}
+	if routed, unscoped := key.ScopeModel("gpt-5"); routed != "team-a/gpt-5" || unscoped != "gpt-5" {
+		t.Fatalf("ScopeModel(gpt-5) = %q, %q", routed, unscoped)
+	}
+	if routed, unscoped := key.ScopeModel("team-a/gpt-5"); routed != "team-a/gpt-5" || unscoped != "gpt-5" {
+		t.Fatalf("ScopeModel(team-a/gpt-5) = %q, %q", routed, unscoped)
+	}
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	if !(ClientAPIKey{ExpiresAt: "2026-05-01T11:59:59Z"}).Expired(now) {
+		t.Fatal("past expiry should be expired")
+	}
+	if (ClientAPIKey{ExpiresAt: "2026-05-02"}).Expired(now) {
+		t.Fatal("future expiry should not be expired")
+	}
+	if !(ClientAPIKey{ExpiresAt: "next tuesday"}).Expired(now) {
+		t.Fatal("unparsable expiry should count as expired")
+	}
+}
@@ -19,6 +19,10 @@ type Config struct {
 	// Home config is runtime-only and is populated from -home-jwt.
 	Home HomeConfig `yaml:"-" json:"-"`
 
+	// SecretReferences records, by YAML path, each value resolved from a ${env:...},
+	// ${file:...} or ${cmd:...} reference. It is runtime-only.
+	SecretReferences map[string]SecretReference `yaml:"-" json:"-"`
+
 	// CredentialConcurrency contains Home-authoritative credential lifecycle settings.
 	CredentialConcurrency CredentialConcurrencyConfig `yaml:"credential-concurrency" json:"credential-concurrency"`
 
@@ -40,6 +44,12 @@ type Config struct {
 	// Pprof config controls the optional pprof HTTP debug server.
 	Pprof PprofConfig `yaml:"pprof" json:"pprof"`
 
+	// Metrics config controls the optional Prometheus/OpenMetrics endpoint.
+	Metrics MetricsConfig `yaml:"metrics" json:"metrics"`
+
+	// Tracing config controls OpenTelemetry span export over OTLP/HTTP.
+	Tracing TracingConfig `yaml:"tracing" json:"tracing"`
+
 	// CommercialMode disables high-overhead request logging and HTTP middleware features to minimize per-request memory usage.
 	CommercialMode bool `yaml:"commercial-mode" json:"commercial-mode"`
 
@@ -54,6 +64,21 @@ type Config struct {
 	// When exceeded, the oldest error log files are deleted. Default is 10. Set to 0 to disable cleanup.
 	ErrorLogsMaxFiles int `yaml:"error-logs-max-files" json:"error-logs-max-files"`
 
+	// UsageLedger config controls the optional persistent usage ledger.
+	UsageLedger UsageLedgerConfig `yaml:"usage-ledger" json:"usage-ledger"`
+
+	// AuditLog config controls the append-only log of management and config changes.
+	AuditLog AuditLogConfig `yaml:"audit-log" json:"audit-log"`
+
+	// Batch config controls the local Files and Batch API.
+	Batch BatchConfig `yaml:"batch" json:"batch"`
+
+	// ResponseCache config controls the optional response cache.
+	ResponseCache ResponseCacheConfig `yaml:"response-cache" json:"response-cache"`
+
+	// ResponsesStore config controls stored responses for the Responses API.
+	ResponsesStore ResponsesStoreConfig `yaml:"responses-store" json:"responses-store"`
+
 	// UsageStatisticsEnabled toggles in-memory usage aggregation; when false, usage data is discarded.
 	UsageStatisticsEnabled bool `yaml:"usage-statistics-enabled" json:"usage-statistics-enabled"`
 
@@ -143,6 +168,12 @@ type Config struct {
 	// OpenAICompatibility defines OpenAI API compatibility configurations for external providers.
 	OpenAICompatibility []OpenAICompatibility `yaml:"openai-compatibility" json:"openai-compatibility"`
 
+	// BedrockKey defines AWS Bedrock credentials for Claude and other Converse models.
+	BedrockKey []BedrockKey `yaml:"bedrock-api-key" json:"bedrock-api-key"`
+
+	// AzureOpenAIKey defines Azure OpenAI resources and their deployment-to-model mappings.
+	AzureOpenAIKey []AzureOpenAIKey `yaml:"azure-openai" json:"azure-openai"`
+
 	// VertexCompatAPIKey defines Vertex AI-compatible API key configurations for third-party providers.
 	// Used for services that use Vertex AI-style paths but with simple API key authentication.
 	VertexCompatAPIKey []VertexCompatKey `yaml:"vertex-api-key" json:"vertex-api-key"`
@@ -158,6 +189,22 @@ type Config struct {
 	// gemini-api-key, interactions-api-key, codex-api-key, xai-api-key, claude-api-key, openai-compatibility, and vertex-api-key.
 	OAuthModelAlias map[string][]OAuthModelAlias `yaml:"oauth-model-alias,omitempty" json:"oauth-model-alias,omitempty"`
 
+	// ModelFallbacks defines ordered cross-provider fallback chains for client-facing models.
+	ModelFallbacks []ModelFallback `yaml:"model-fallbacks,omitempty" json:"model-fallbacks,omitempty"`
+
+	// StreamHedging opts streaming requests in to hedged attempts on a second credential.
+	StreamHedging []StreamHedgingRule `yaml:"stream-hedging,omitempty" json:"stream-hedging,omitempty"`
+
+	// ModelCatalog selects the sources, overlays and signing keys of the models catalog.
+	ModelCatalog ModelCatalogConfig `yaml:"model-catalog,omitempty" json:"model-catalog"`
+
+	// ModelPricing overrides the catalog prices used for usage cost accounting.
+	ModelPricing []ModelPriceOverride `yaml:"model-pricing,omitempty" json:"model-pricing,omitempty"`
+
+	// ContextGuard checks requests against the context window of their model before
+	// they are sent upstream.
+	ContextGuard ContextGuardConfig `yaml:"context-guard,omitempty" json:"context-guard"`
+
 	// Payload defines default and override rules for provider payload parameters.
 	Payload PayloadConfig `yaml:"payload" json:"payload"`
 }
@@ -88,6 +88,11 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	// Resolve ${env:...}, ${file:...} and, when enabled, ${cmd:...} secret references.
+	if errResolve := cfg.ResolveSecretReferences(); errResolve != nil {
+		return nil, errResolve
+	}
+
 	cfg.CredentialConcurrency = cfg.CredentialConcurrency.WithDefaults()
 	if errValidate := cfg.CredentialInFlight.Validate(); errValidate != nil {
 		return nil, errValidate
@@ -106,11 +111,22 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 		if errHash != nil {
 			return nil, fmt.Errorf("failed to hash remote management key: %w", errHash)
 		}
+		// A key loaded from a reference keeps the reference in the file; saving the
+		// config writes it back for the hash.
+		fromReference := cfg.rebindSecretReference("remote-management.secret-key", cfg.RemoteManagement.SecretKey, hashed)
 		cfg.RemoteManagement.SecretKey = hashed
 
-		// Persist the hashed value back to the config file to avoid re-hashing on next startup.
-		// Preserve YAML comments and ordering; update only the nested key.
-		_ = SaveConfigPreserveCommentsUpdateNestedScalar(configFile, []string{"remote-management", "secret-key"}, hashed)
+		if !fromReference {
+			// Persist the hashed value back to the config file to avoid re-hashing on next startup.
+			// Preserve YAML comments and ordering; update only the nested key.
+			_ = SaveConfigPreserveCommentsUpdateNestedScalar(configFile, []string{"remote-management", "secret-key"}, hashed)
+		}
+	}
+
+	// Hash plaintext scoped management keys in memory; saving the config writes the hashes back.
+	cfg.SanitizeManagementKeys()
+	if errHash := cfg.hashManagementKeys(); errHash != nil {
+		return nil, errHash
 	}
 
 	cfg.RemoteManagement.PanelGitHubRepository = strings.TrimSpace(cfg.RemoteManagement.PanelGitHubRepository)
@@ -155,6 +171,8 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 
 	// Sanitize Vertex-compatible API keys.
 	cfg.SanitizeVertexCompatKeys()
+	cfg.SanitizeBedrockKeys()
+	cfg.SanitizeAzureOpenAIKeys()
 
 	// Sanitize Codex keys: drop entries without base-url
 	cfg.SanitizeCodexKeys()
@@ -180,9 +198,24 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Normalize global OAuth model name aliases.
 	cfg.SanitizeOAuthModelAlias()
 
+	// Normalize cross-provider model fallback chains.
+	cfg.SanitizeModelFallbacks()
+
+	// Drop stream hedging rules that match nothing.
+	cfg.SanitizeStreamHedging()
+
+	// Drop model price overrides without a model or with invalid prices.
+	cfg.SanitizeModelPricing()
+
 	// Validate raw payload rules and drop invalid entries.
 	cfg.SanitizePayloadRules()
 
+	// Normalize structured api-keys entries.
+	cfg.SanitizeClientAPIKeys()
+
+	// Drop empty or duplicate per-client API key limits.
+	cfg.SanitizeAPIKeyLimits()
+
 	// Return the populated configuration struct.
 	return &cfg, nil
 }
@@ -190,6 +190,122 @@ type PprofConfig struct {
 	Addr string `yaml:"addr" json:"addr"`
 }
 
+// MetricsConfig holds Prometheus/OpenMetrics exposition settings.
+type MetricsConfig struct {
+	// Enable toggles the /metrics exposition endpoint.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Listener selects where metrics are served: "api" (default, /metrics on the main
+	// listener behind client API keys), "pprof" (/metrics on the pprof listener) or
+	// "management" (/v0/management/metrics behind the management key).
+	Listener string `yaml:"listener,omitempty" json:"listener,omitempty"`
+}
+
+// TracingConfig holds OpenTelemetry trace export settings.
+type TracingConfig struct {
+	// Enable toggles span creation and OTLP/HTTP export.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Endpoint is the OTLP/HTTP traces endpoint. A bare scheme and host gets the
+	// standard "/v1/traces" path. Defaults to DefaultTracingEndpoint.
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	// ServiceName is reported as the service.name resource attribute. Defaults to
+	// DefaultTracingServiceName.
+	ServiceName string `yaml:"service-name,omitempty" json:"service-name,omitempty"`
+	// SampleRatio is the fraction of new traces that are sampled, between 0 and 1.
+	// Requests carrying a W3C traceparent follow the caller's sampling decision.
+	// Omitted samples every trace.
+	SampleRatio *float64 `yaml:"sample-ratio,omitempty" json:"sample-ratio,omitempty"`
+	// Headers are sent with every export request, e.g. collector credentials.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// UsageLedgerConfig holds the persistent usage ledger settings.
+type UsageLedgerConfig struct {
+	// Enable toggles recording of usage records into the ledger.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Backend selects the storage: "file" (default, daily JSONL segments under Dir)
+	// or "postgres" (the Postgres token store, falling back to "file" when it is not in use).
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+	// Dir overrides the directory used by the file backend.
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+	// RetentionDays drops records older than this many days. 0 keeps records forever.
+	RetentionDays int `yaml:"retention-days" json:"retention-days"`
+	// CompactAfterDays rolls records older than this many days up into hourly rows.
+	// 0 disables compaction.
+	CompactAfterDays int `yaml:"compact-after-days" json:"compact-after-days"`
+}
+
+// AuditLogConfig holds the management audit log settings.
+type AuditLogConfig struct {
+	// Enable toggles recording of mutating management calls and external config edits.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Backend selects the storage: "file" (default, daily JSONL segments under Dir)
+	// or "postgres" (the Postgres token store, falling back to "file" when it is not in use).
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+	// Dir overrides the directory used by the file backend.
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+	// RetentionDays drops events older than this many days. 0 keeps events forever.
+	RetentionDays int `yaml:"retention-days" json:"retention-days"`
+	// ForwardToHome also writes every event to the application log, which the Home
+	// app-log channel forwards while Home is connected.
+	ForwardToHome bool `yaml:"forward-to-home,omitempty" json:"forward-to-home,omitempty"`
+}
+
+// BatchConfig holds the local Files and Batch API settings.
+type BatchConfig struct {
+	// Enable exposes /v1/files and /v1/batches and runs queued batch jobs.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Dir overrides the directory holding uploaded files and job state.
+	// Defaults to "batches" under the auth directory.
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+	// ConcurrencyPerCredential is the number of in-flight requests a job may run for each
+	// credential able to serve its models. Defaults to 2.
+	ConcurrencyPerCredential int `yaml:"concurrency-per-credential" json:"concurrency-per-credential"`
+	// MaxFileSizeMB caps the size of uploaded files. Defaults to 100.
+	MaxFileSizeMB int `yaml:"max-file-size-mb" json:"max-file-size-mb"`
+}
+
+// ResponseCacheConfig holds the response cache settings.
+type ResponseCacheConfig struct {
+	// Enable serves repeated deterministic requests from the cache.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Backend selects the storage: "memory" (default), "redis" or "postgres"
+	// (the Postgres token store, falling back to "memory" when it is not in use).
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+	// RedisURL is the redis:// or rediss:// URL used by the redis backend.
+	RedisURL string `yaml:"redis-url,omitempty" json:"redis-url,omitempty"`
+	// TTLSeconds controls how long responses stay cached. Defaults to 600.
+	TTLSeconds int `yaml:"ttl-seconds" json:"ttl-seconds"`
+	// MaxEntries caps the number of responses held by the memory backend. Defaults to 1000.
+	MaxEntries int `yaml:"max-entries" json:"max-entries"`
+	// MaxEntrySizeKB skips caching responses larger than this. Defaults to 1024.
+	MaxEntrySizeKB int `yaml:"max-entry-size-kb" json:"max-entry-size-kb"`
+	// ShareAcrossKeys lets different client API keys hit each other's cached responses.
+	// By default entries are scoped to the key that produced them.
+	ShareAcrossKeys bool `yaml:"share-across-keys" json:"share-across-keys"`
+}
+
+// ResponsesStoreConfig holds the stored-response settings of the Responses API.
+type ResponsesStoreConfig struct {
+	// Enable keeps the input and output items of stored responses so previous_response_id
+	// and GET/DELETE /v1/responses/{id} work against every provider.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Backend selects the storage: "memory" (default), "file" (one JSON file per response
+	// under Dir) or "postgres" (the Postgres token store, falling back to "memory" when
+	// it is not in use).
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+	// Dir overrides the directory used by the file backend.
+	// Defaults to "responses" under WRITABLE_PATH or the auth directory.
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+	// TTLSeconds controls how long stored responses stay retrievable. Defaults to 86400.
+	TTLSeconds int `yaml:"ttl-seconds" json:"ttl-seconds"`
+	// MaxEntries caps the number of responses held by the memory and file backends.
+	// Defaults to 10000.
+	MaxEntries int `yaml:"max-entries" json:"max-entries"`
+	// MaxEntrySizeKB skips storing responses whose conversation is larger than this.
+	// Defaults to 4096.
+	MaxEntrySizeKB int `yaml:"max-entry-size-kb" json:"max-entry-size-kb"`
+}
+
 // RemoteManagement holds management API configuration under 'remote-management'.
 type RemoteManagement struct {
 	// AllowRemote toggles remote (non-localhost) access to management API.
@@ -204,6 +320,23 @@ type RemoteManagement struct {
 	// PanelGitHubRepository overrides the GitHub repository used to fetch the management panel asset.
 	// Accepts either a repository URL (https://github.com/org/repo) or an API releases endpoint.
 	PanelGitHubRepository string `yaml:"panel-github-repository"`
+	// Keys lists additional named management keys, each limited to a role. SecretKey keeps
+	// full admin access.
+	Keys []ManagementKey `yaml:"keys,omitempty"`
+}
+
+// ManagementKey is a named management key limited to a role.
+type ManagementKey struct {
+	// Name identifies the key in logs and in the management UI.
+	Name string `yaml:"name"`
+	// Key is the key value (plaintext or bcrypt hashed). Plaintext values are hashed at load.
+	Key string `yaml:"key"`
+	// Role is one of "viewer", "operator" or "admin". Empty or unknown values mean "viewer".
+	Role ManagementRole `yaml:"role,omitempty"`
+	// AllowedIPs restricts the key to client addresses or CIDR ranges. Empty allows any address.
+	AllowedIPs []string `yaml:"allowed-ips,omitempty"`
+	// ExpiresAt is an RFC 3339 timestamp or YYYY-MM-DD date (UTC midnight) after which the key is refused.
+	ExpiresAt string `yaml:"expires-at,omitempty"`
 }
 
 // QuotaExceeded defines the behavior when API quota limits are exceeded.
@@ -237,6 +370,12 @@ type RoutingConfig struct {
 	// SessionAffinityTTL specifies how long session-to-auth bindings are retained.
 	// Default: 1h. Accepts duration strings like "30m", "1h", "2h30m".
 	SessionAffinityTTL string `yaml:"session-affinity-ttl,omitempty" json:"session-affinity-ttl,omitempty"`
+
+	// QuotaWindowThreshold deprioritizes credentials whose upstream usage window, as
+	// reported by rate-limit response headers, is at or above this percentage used.
+	// Such credentials are only selected when no other credential is available.
+	// 0 disables the behavior.
+	QuotaWindowThreshold float64 `yaml:"quota-window-threshold,omitempty" json:"quota-window-threshold,omitempty"`
 }
 
 // OAuthModelAlias defines a model ID alias for a specific channel.
@@ -362,6 +501,9 @@ type ClaudeKey struct {
 	// ExcludedModels lists model IDs that should be excluded for this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
 
+	// DiscoverModels periodically fetches the upstream model catalog when BaseURL is set.
+	DiscoverModels *DiscoverModelsConfig `yaml:"discover-models,omitempty" json:"discover-models,omitempty"`
+
 	// RebuildMidSystemMessage moves Claude messages with role "system" into the top-level system field.
 	RebuildMidSystemMessage bool `yaml:"rebuild-mid-system-message,omitempty" json:"rebuild-mid-system-message,omitempty"`
 
@@ -483,6 +625,9 @@ type CodexKey struct {
 	// ExcludedModels lists model IDs that should be excluded for this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
 
+	// DiscoverModels periodically fetches the upstream model catalog when BaseURL is set.
+	DiscoverModels *DiscoverModelsConfig `yaml:"discover-models,omitempty" json:"discover-models,omitempty"`
+
 	// DisableCooling overrides the global cooling policy for this credential when set.
 	// True disables auth/model cooldowns; false explicitly enables them.
 	DisableCooling *bool `yaml:"disable-cooling,omitempty" json:"disable-cooling,omitempty"`
@@ -621,6 +766,12 @@ type GeminiModel struct {
 	// Default false keeps the normal signature validation behavior.
 	IsCompat bool `yaml:"is-compat,omitempty" json:"is-compat,omitempty"`
 
+	// InputModalities declares supported input modalities (e.g. text, image, audio).
+	InputModalities []string `yaml:"input-modalities,omitempty" json:"input-modalities,omitempty"`
+
+	// OutputModalities declares supported output modalities (e.g. text, audio).
+	OutputModalities []string `yaml:"output-modalities,omitempty" json:"output-modalities,omitempty"`
+
 	// Thinking configures the thinking/reasoning capability for this model.
 	Thinking *registry.ThinkingSupport `yaml:"thinking,omitempty" json:"thinking,omitempty"`
 }
@@ -629,10 +780,12 @@ func (m GeminiModel) GetName() string { return m.Name }
 
 func (m GeminiModel) GetAlias() string { return m.Alias }
 
-func (m GeminiModel) GetDisplayName() string   { return m.DisplayName }
-func (m GeminiModel) GetMaxContextLength() int { return m.MaxContextLength }
-func (m GeminiModel) GetForceMapping() bool    { return m.ForceMapping }
-func (m GeminiModel) GetIsCompat() bool        { return m.IsCompat }
+func (m GeminiModel) GetDisplayName() string        { return m.DisplayName }
+func (m GeminiModel) GetMaxContextLength() int      { return m.MaxContextLength }
+func (m GeminiModel) GetForceMapping() bool         { return m.ForceMapping }
+func (m GeminiModel) GetIsCompat() bool             { return m.IsCompat }
+func (m GeminiModel) GetInputModalities() []string  { return m.InputModalities }
+func (m GeminiModel) GetOutputModalities() []string { return m.OutputModalities }
 
 func (m GeminiModel) GetThinking() *registry.ThinkingSupport { return m.Thinking }
 
@@ -664,6 +817,10 @@ type OpenAICompatibility struct {
 	// Headers optionally adds extra HTTP headers for requests sent to this provider.
 	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
 
+	// DiscoverModels periodically fetches the upstream model catalog and registers it
+	// alongside Models.
+	DiscoverModels *DiscoverModelsConfig `yaml:"discover-models,omitempty" json:"discover-models,omitempty"`
+
 	// SupportPromptCacheKey enables derived prompt_cache_key injection for supported requests.
 	SupportPromptCacheKey bool `yaml:"support-prompt-cache-key,omitempty" json:"support-prompt-cache-key,omitempty"`
 
@@ -713,6 +870,9 @@ type OpenAICompatibilityModel struct {
 	// Image marks this model as callable through /v1/images/generations and /v1/images/edits.
 	Image bool `yaml:"image,omitempty" json:"image,omitempty"`
 
+	// Embedding marks this model as callable through /v1/embeddings.
+	Embedding bool `yaml:"embedding,omitempty" json:"embedding,omitempty"`
+
 	// InputModalities declares chat/responses input capabilities (e.g. text, image) for Codex and other clients.
 	// This is separate from Image, which only enables /v1/images/* endpoints.
 	InputModalities []string `yaml:"input-modalities,omitempty" json:"input-modalities,omitempty"`
@@ -12,38 +12,53 @@ import (
 // SaveConfigPreserveComments writes the config back to YAML while preserving existing comments
 // and key ordering by loading the original file into a yaml.Node tree and updating values in-place.
 func SaveConfigPreserveComments(configFile string, cfg *Config) error {
+	data, err := RenderConfigPreserveComments(configFile, cfg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configFile, data, 0o666)
+}
+
+// RenderConfigPreserveComments returns the content SaveConfigPreserveComments would write
+// to configFile for cfg without writing it.
+func RenderConfigPreserveComments(configFile string, cfg *Config) ([]byte, error) {
 	persistCfg := cfg
 	// Load original YAML as a node tree to preserve comments and ordering.
 	data, err := os.ReadFile(configFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var original yaml.Node
 	if err = yaml.Unmarshal(data, &original); err != nil {
-		return err
+		return nil, err
 	}
 	if original.Kind != yaml.DocumentNode || len(original.Content) == 0 {
-		return fmt.Errorf("invalid yaml document structure")
+		return nil, fmt.Errorf("invalid yaml document structure")
 	}
 	if original.Content[0] == nil || original.Content[0].Kind != yaml.MappingNode {
-		return fmt.Errorf("expected root mapping node")
+		return nil, fmt.Errorf("expected root mapping node")
 	}
 
 	// Marshal the current cfg to YAML, then unmarshal to a yaml.Node we can merge from.
 	rendered, err := yaml.Marshal(persistCfg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	var generated yaml.Node
 	if err = yaml.Unmarshal(rendered, &generated); err != nil {
-		return err
+		return nil, err
 	}
 	if generated.Kind != yaml.DocumentNode || len(generated.Content) == 0 || generated.Content[0] == nil {
-		return fmt.Errorf("invalid generated yaml structure")
+		return nil, fmt.Errorf("invalid generated yaml structure")
 	}
 	if generated.Content[0].Kind != yaml.MappingNode {
-		return fmt.Errorf("expected generated root mapping node")
+		return nil, fmt.Errorf("expected generated root mapping node")
+	}
+	// Write secret references back instead of the secrets they resolved to.
+	restoreSecretReferencesInNode(generated.Content[0], cfg.SecretReferences)
+	if errHostSecrets := CheckNoNewHostSecrets(hostSecretReferencesInNode(&original), hostSecretReferencesInNode(generated.Content[0])); errHostSecrets != nil {
+		return nil, errHostSecrets
 	}
 
 	// Remove deprecated sections before merging back the sanitized config.
@@ -60,25 +75,17 @@ func SaveConfigPreserveComments(configFile string, cfg *Config) error {
 	mergeMappingPreserve(original.Content[0], generated.Content[0])
 	normalizeCollectionNodeStyles(original.Content[0])
 
-	// Write back.
-	f, err := os.Create(configFile)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = f.Close() }()
 	var buf bytes.Buffer
 	enc := yaml.NewEncoder(&buf)
 	enc.SetIndent(2)
 	if err = enc.Encode(&original); err != nil {
 		_ = enc.Close()
-		return err
+		return nil, err
 	}
 	if err = enc.Close(); err != nil {
-		return err
+		return nil, err
 	}
-	data = NormalizeCommentIndentation(buf.Bytes())
-	_, err = f.Write(data)
-	return err
+	return NormalizeCommentIndentation(buf.Bytes()), nil
 }
 
 // SaveConfigPreserveCommentsUpdateNestedScalar updates a nested scalar key path like ["a","b"]
@@ -0,0 +1,99 @@
+package config
+
+import "strings"
+
+const (
+	// ContextGuardStrategyReject answers over-limit requests with a protocol-native
+	// context length error instead of sending them upstream.
+	ContextGuardStrategyReject = "reject"
+	// ContextGuardStrategyDropOldest drops the oldest conversation turns until the
+	// request fits. Tool calls and their results are always dropped together.
+	ContextGuardStrategyDropOldest = "drop-oldest"
+	// ContextGuardStrategySummarize replaces the oldest turns with a summary written
+	// by summary-model.
+	ContextGuardStrategySummarize = "summarize"
+
+	// DefaultContextGuardSummaryMaxTokens is used when summary-max-tokens is unset.
+	DefaultContextGuardSummaryMaxTokens = 1024
+)
+
+// ContextGuardConfig enables the pre-flight context window check. The input tokens of
+// a request are estimated locally and compared with the context window of the target
+// model (max-context-length, then the catalog context length); requests over the limit
+// are handled by Strategy before any upstream call.
+type ContextGuardConfig struct {
+	// Enable turns the guard on.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// Strategy is "reject" (default), "drop-oldest" or "summarize".
+	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// Models are client-requested model names the guard applies to; '*' matches any
+	// characters. An empty list applies to every model.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// ReserveTokens is kept free for the response when the request sets no maximum
+	// output tokens. When the request sets one, that value is reserved instead.
+	ReserveTokens int `yaml:"reserve-tokens,omitempty" json:"reserve-tokens,omitempty"`
+
+	// SummaryModel writes the summary of the dropped turns for the summarize strategy.
+	// A cheaper model is recommended. Without it, summarize behaves like drop-oldest.
+	SummaryModel string `yaml:"summary-model,omitempty" json:"summary-model,omitempty"`
+
+	// SummaryMaxTokens bounds the summary length. Defaults to 1024.
+	SummaryMaxTokens int `yaml:"summary-max-tokens,omitempty" json:"summary-max-tokens,omitempty"`
+}
+
+// NormalizeContextGuardStrategy returns a supported strategy name, or "" when strategy
+// is not one. Empty selects ContextGuardStrategyReject.
+func NormalizeContextGuardStrategy(strategy string) string {
+	switch strings.ToLower(strings.TrimSpace(strategy)) {
+	case "", ContextGuardStrategyReject:
+		return ContextGuardStrategyReject
+	case ContextGuardStrategyDropOldest, "drop", "drop_oldest":
+		return ContextGuardStrategyDropOldest
+	case ContextGuardStrategySummarize, "summarise", "summary":
+		return ContextGuardStrategySummarize
+	default:
+		return ""
+	}
+}
+
+// EffectiveStrategy returns the normalized strategy. Unknown values reject, so a typo
+// never silently drops conversation history.
+func (c ContextGuardConfig) EffectiveStrategy() string {
+	if strategy := NormalizeContextGuardStrategy(c.Strategy); strategy != "" {
+		return strategy
+	}
+	return ContextGuardStrategyReject
+}
+
+// EffectiveSummaryMaxTokens returns the summary length bound with its default applied.
+func (c ContextGuardConfig) EffectiveSummaryMaxTokens() int {
+	if c.SummaryMaxTokens <= 0 {
+		return DefaultContextGuardSummaryMaxTokens
+	}
+	return c.SummaryMaxTokens
+}
+
+// AppliesTo reports whether the guard is enabled for any of the given model names.
+func (c ContextGuardConfig) AppliesTo(models ...string) bool {
+	if !c.Enable {
+		return false
+	}
+	if len(c.Models) == 0 {
+		return true
+	}
+	for _, model := range models {
+		model = strings.ToLower(strings.TrimSpace(model))
+		if model == "" {
+			continue
+		}
+		for _, pattern := range c.Models {
+			if matchModelGlob(strings.ToLower(strings.TrimSpace(pattern)), model) {
+				return true
+			}
+		}
+	}
+	return false
+}
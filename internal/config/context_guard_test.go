@@ -0,0 +1,47 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContextGuardConfigStrategyAndModels(t *testing.T) {
+	for raw, want := range map[string]string{
+		"":             ContextGuardStrategyReject,
+		" Drop_Oldest": ContextGuardStrategyDropOldest,
+		"summarise":    ContextGuardStrategySummarize,
+		"truncate":     ContextGuardStrategyReject,
+	} {
+		if got := (ContextGuardConfig{Strategy: raw}).EffectiveStrategy(); got != want {
+			t.Errorf("EffectiveStrategy(%q) = %q, want %q", raw, got, want)
+		}
+	}
+
+	guard := ContextGuardConfig{Enable: true, Models: []string{"Claude-*"}}
+	if !guard.AppliesTo("gpt-5", "claude-sonnet-4-5") {
+		t.Error("guard does not apply to a matching resolved model")
+	}
+	if guard.AppliesTo("gpt-5") {
+		t.Error("guard applies to an unlisted model")
+	}
+	guard.Enable = false
+	if guard.AppliesTo("claude-sonnet-4-5") {
+		t.Error("disabled guard applies")
+	}
+}
+
+func TestValidateContextGuard(t *testing.T) {
+	_, report := ValidateConfigBytes([]byte("context-guard:\n  enable: true\n  strategy: truncate\n"))
+	if len(report.Errors) != 1 || !strings.Contains(report.Errors[0], "context-guard.strategy") {
+		t.Fatalf("errors = %v", report.Errors)
+	}
+
+	_, report = ValidateConfigBytes([]byte("context-guard:\n  enable: true\n  strategy: summarize\n"))
+	found := false
+	for _, warning := range report.Warnings {
+		found = found || strings.Contains(warning, "summary-model")
+	}
+	if !found {
+		t.Fatalf("warnings = %v, want a summary-model warning", report.Warnings)
+	}
+}
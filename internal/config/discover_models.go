@@ -0,0 +1,115 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultDiscoverModelsIntervalSeconds is how often an upstream model catalog is re-fetched.
+	DefaultDiscoverModelsIntervalSeconds = 3600
+	// MinDiscoverModelsIntervalSeconds bounds interval-seconds so a misconfigured
+	// provider cannot hammer its upstream.
+	MinDiscoverModelsIntervalSeconds = 60
+	// DefaultDiscoverModelsPath is the catalog path appended to the provider base URL.
+	DefaultDiscoverModelsPath = "/models"
+)
+
+// DiscoverModelsConfig enables periodic discovery of the models an upstream lists
+// under GET {base-url}/models. Discovered models are registered alongside the
+// configured models list; configured entries win when both name the same model.
+type DiscoverModelsConfig struct {
+	// Enable turns discovery on for the provider or credential.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// IntervalSeconds is the refresh interval. Defaults to 3600; values below 60 are raised to 60.
+	IntervalSeconds int `yaml:"interval-seconds,omitempty" json:"interval-seconds,omitempty"`
+
+	// Path overrides the catalog path appended to the base URL (default "/models").
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+
+	// Include lists glob patterns ('*' wildcard, case-insensitive) a discovered model ID
+	// must match. An empty list includes every model.
+	Include []string `yaml:"include,omitempty" json:"include,omitempty"`
+
+	// Exclude lists glob patterns of discovered model IDs to drop. Exclusion wins over inclusion.
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+
+	// Alias is an optional template for the client-facing model name. "{id}" expands to
+	// the upstream model ID and "{provider}" to the provider name, e.g. "{provider}-{id}".
+	Alias string `yaml:"alias,omitempty" json:"alias,omitempty"`
+}
+
+// Enabled reports whether discovery is configured and turned on.
+func (c *DiscoverModelsConfig) Enabled() bool {
+	return c != nil && c.Enable
+}
+
+// EffectiveInterval returns the refresh interval with defaults and bounds applied.
+func (c *DiscoverModelsConfig) EffectiveInterval() time.Duration {
+	seconds := DefaultDiscoverModelsIntervalSeconds
+	if c != nil && c.IntervalSeconds > 0 {
+		seconds = c.IntervalSeconds
+	}
+	if seconds < MinDiscoverModelsIntervalSeconds {
+		seconds = MinDiscoverModelsIntervalSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// EffectivePath returns the catalog path, falling back to fallback and then to "/models".
+func (c *DiscoverModelsConfig) EffectivePath(fallback string) string {
+	path := ""
+	if c != nil {
+		path = strings.TrimSpace(c.Path)
+	}
+	if path == "" {
+		path = strings.TrimSpace(fallback)
+	}
+	if path == "" {
+		path = DefaultDiscoverModelsPath
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// Matches reports whether a discovered model ID passes the include and exclude globs.
+func (c *DiscoverModelsConfig) Matches(id string) bool {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == "" {
+		return false
+	}
+	if c == nil {
+		return true
+	}
+	for _, pattern := range c.Exclude {
+		if matchModelGlob(strings.ToLower(strings.TrimSpace(pattern)), id) {
+			return false
+		}
+	}
+	if len(c.Include) == 0 {
+		return true
+	}
+	for _, pattern := range c.Include {
+		if matchModelGlob(strings.ToLower(strings.TrimSpace(pattern)), id) {
+			return true
+		}
+	}
+	return false
+}
+
+// AliasFor expands the alias template for a discovered model. It returns id unchanged
+// when no template is configured.
+func (c *DiscoverModelsConfig) AliasFor(id, provider string) string {
+	id = strings.TrimSpace(id)
+	if c == nil || strings.TrimSpace(c.Alias) == "" {
+		return id
+	}
+	alias := strings.NewReplacer("{id}", id, "{provider}", strings.TrimSpace(provider)).Replace(strings.TrimSpace(c.Alias))
+	if strings.TrimSpace(alias) == "" {
+		return id
+	}
+	return alias
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiscoverModelsConfigMatchesAndAlias(t *testing.T) {
+	cfg := &DiscoverModelsConfig{
+		Enable:  true,
+		Include: []string{"GPT-*", "o3"},
+		Exclude: []string{"*-preview"},
+		Alias:   "{provider}/{id}",
+	}
+	cases := map[string]bool{
+		"gpt-5":         true,
+		"gpt-5-preview": false,
+		"o3":            true,
+		"o3-mini":       false,
+		"":              false,
+	}
+	for id, want := range cases {
+		if got := cfg.Matches(id); got != want {
+			t.Errorf("Matches(%q) = %t, want %t", id, got, want)
+		}
+	}
+	if got := cfg.AliasFor("gpt-5", "acme"); got != "acme/gpt-5" {
+		t.Fatalf("AliasFor = %q", got)
+	}
+	if got := (&DiscoverModelsConfig{}).AliasFor("gpt-5", "acme"); got != "gpt-5" {
+		t.Fatalf("AliasFor without template = %q", got)
+	}
+}
+
+func TestDiscoverModelsConfigDefaults(t *testing.T) {
+	var unset *DiscoverModelsConfig
+	if unset.Enabled() {
+		t.Fatal("nil config reported enabled")
+	}
+	if got := unset.EffectiveInterval(); got != time.Hour {
+		t.Fatalf("default interval = %s", got)
+	}
+	if got := (&DiscoverModelsConfig{IntervalSeconds: 5}).EffectiveInterval(); got != time.Minute {
+		t.Fatalf("clamped interval = %s", got)
+	}
+	if got := unset.EffectivePath("/v1/models"); got != "/v1/models" {
+		t.Fatalf("fallback path = %q", got)
+	}
+	if got := (&DiscoverModelsConfig{Path: "catalog"}).EffectivePath("/v1/models"); got != "/catalog" {
+		t.Fatalf("configured path = %q", got)
+	}
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ManagementRole is the access level of a management key.
+type ManagementRole string
+
+// Management roles, from least to most privileged.
+const (
+	// ManagementRoleViewer may read configuration and state with secrets redacted.
+	ManagementRoleViewer ManagementRole = "viewer"
+	// ManagementRoleOperator may additionally toggle auth status, reset quota and read logs.
+	ManagementRoleOperator ManagementRole = "operator"
+	// ManagementRoleAdmin has unrestricted access, including config writes, the plugin
+	// store and auth file downloads.
+	ManagementRoleAdmin ManagementRole = "admin"
+)
+
+// ParseManagementRole returns the role named by raw and whether it is a known role.
+func ParseManagementRole(raw string) (ManagementRole, bool) {
+	switch role := ManagementRole(strings.ToLower(strings.TrimSpace(raw))); role {
+	case ManagementRoleViewer, ManagementRoleOperator, ManagementRoleAdmin:
+		return role, true
+	default:
+		return ManagementRoleViewer, false
+	}
+}
+
+func (r ManagementRole) level() int {
+	switch r {
+	case ManagementRoleAdmin:
+		return 3
+	case ManagementRoleOperator:
+		return 2
+	case ManagementRoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Allows reports whether r grants at least the access of required.
+func (r ManagementRole) Allows(required ManagementRole) bool {
+	return r.level() > 0 && r.level() >= required.level()
+}
+
+// ExpiryTime parses ExpiresAt. The zero time is returned when no expiry is set.
+func (k ManagementKey) ExpiryTime() (time.Time, error) {
+	return parseKeyExpiry(k.ExpiresAt)
+}
+
+// Expired reports whether the key is past its expiry at now. An unparsable expiry
+// counts as expired so a typo never grants open-ended access.
+func (k ManagementKey) Expired(now time.Time) bool {
+	expiry, errExpiry := k.ExpiryTime()
+	if errExpiry != nil {
+		return true
+	}
+	return !expiry.IsZero() && !now.Before(expiry)
+}
+
+// AllowsIP reports whether clientIP matches one of AllowedIPs. Entries may be single
+// addresses or CIDR ranges; unparsable entries never match.
+func (k ManagementKey) AllowsIP(clientIP string) bool {
+	if len(k.AllowedIPs) == 0 {
+		return true
+	}
+	addr, errAddr := netip.ParseAddr(strings.TrimSpace(clientIP))
+	if errAddr != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, entry := range k.AllowedIPs {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			if prefix, errPrefix := netip.ParsePrefix(entry); errPrefix == nil && prefix.Contains(addr) {
+				return true
+			}
+			continue
+		}
+		if allowed, errAllowed := netip.ParseAddr(entry); errAllowed == nil && allowed.Unmap() == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// HasManagementKey reports whether any management key is configured in the file.
+func (r RemoteManagement) HasManagementKey() bool {
+	return r.SecretKey != "" || len(r.Keys) > 0
+}
+
+// SanitizeManagementKeys trims remote-management.keys entries, drops entries without a
+// key, names unnamed entries and downgrades unknown roles to viewer.
+func (cfg *Config) SanitizeManagementKeys() {
+	if cfg == nil || len(cfg.RemoteManagement.Keys) == 0 {
+		return
+	}
+	out := cfg.RemoteManagement.Keys[:0]
+	for i := range cfg.RemoteManagement.Keys {
+		entry := cfg.RemoteManagement.Keys[i]
+		entry.Key = strings.TrimSpace(entry.Key)
+		if entry.Key == "" {
+			continue
+		}
+		entry.Name = strings.TrimSpace(entry.Name)
+		if entry.Name == "" {
+			entry.Name = fmt.Sprintf("key-%d", i+1)
+		}
+		role, known := ParseManagementRole(string(entry.Role))
+		if !known && strings.TrimSpace(string(entry.Role)) != "" {
+			log.Warnf("remote-management.keys entry %s: unknown role %q; using %q", entry.Name, entry.Role, ManagementRoleViewer)
+		}
+		entry.Role = role
+		entry.ExpiresAt = strings.TrimSpace(entry.ExpiresAt)
+		if _, errExpiry := entry.ExpiryTime(); errExpiry != nil {
+			log.Warnf("remote-management.keys entry %s: %v; the key will be refused", entry.Name, errExpiry)
+		}
+		entry.AllowedIPs = normalizeTrimmedList(entry.AllowedIPs, false)
+		for _, allowed := range entry.AllowedIPs {
+			if _, errPrefix := netip.ParsePrefix(allowed); errPrefix == nil {
+				continue
+			}
+			if _, errAddr := netip.ParseAddr(allowed); errAddr != nil {
+				log.Warnf("remote-management.keys entry %s: invalid allowed-ips entry %q is ignored", entry.Name, allowed)
+			}
+		}
+		out = append(out, entry)
+	}
+	cfg.RemoteManagement.Keys = out
+}
+
+// hashManagementKeys replaces plaintext remote-management.keys values with bcrypt
+// hashes, keeping secret references attached to the hashed value.
+func (cfg *Config) hashManagementKeys() error {
+	if cfg == nil {
+		return nil
+	}
+	for i := range cfg.RemoteManagement.Keys {
+		key := cfg.RemoteManagement.Keys[i].Key
+		if key == "" || looksLikeBcrypt(key) {
+			continue
+		}
+		hashed, errHash := hashSecret(key)
+		if errHash != nil {
+			return fmt.Errorf("failed to hash management key %s: %w", cfg.RemoteManagement.Keys[i].Name, errHash)
+		}
+		cfg.rebindSecretReference(fmt.Sprintf("remote-management.keys[%d].key", i), key, hashed)
+		cfg.RemoteManagement.Keys[i].Key = hashed
+	}
+	return nil
+}
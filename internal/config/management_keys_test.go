@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseConfigBytesManagementKeys(t *testing.T) {
+	cfg, errParse := ParseConfigBytes([]byte(`remote-management:
+  keys:
+    - name: " on-call "
+      key: operator-key
+      role: Operator
+      allowed-ips: ["10.0.0.0/8", " 192.168.1.10 "]
+    - key: viewer-key
+      role: superuser
+    - name: empty
+      key: "  "
+`))
+	if errParse != nil {
+		t.Fatalf("ParseConfigBytes() error = %v", errParse)
+	}
+	keys := cfg.RemoteManagement.Keys
+	if len(keys) != 2 {
+		t.Fatalf("keys = %+v, want 2 entries", keys)
+	}
+	if keys[0].Name != "on-call" || keys[0].Role != ManagementRoleOperator || !looksLikeBcrypt(keys[0].Key) {
+		t.Fatalf("first key = %+v", keys[0])
+	}
+	if keys[1].Name != "key-2" || keys[1].Role != ManagementRoleViewer {
+		t.Fatalf("unknown role must fall back to viewer: %+v", keys[1])
+	}
+	if !keys[0].AllowsIP("10.20.30.40") || !keys[0].AllowsIP("192.168.1.10") || keys[0].AllowsIP("192.168.1.11") {
+		t.Fatalf("allow-list = %v", keys[0].AllowedIPs)
+	}
+	if !cfg.RemoteManagement.HasManagementKey() {
+		t.Fatal("HasManagementKey() = false with scoped keys configured")
+	}
+}
+
+func TestManagementKeyExpiredAndRoleOrder(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	if !(ManagementKey{ExpiresAt: "2026-05-31"}).Expired(now) {
+		t.Fatal("past date must be expired")
+	}
+	if (ManagementKey{ExpiresAt: "2026-06-02T00:00:00Z"}).Expired(now) {
+		t.Fatal("future timestamp must not be expired")
+	}
+	if !(ManagementKey{ExpiresAt: "next week"}).Expired(now) {
+		t.Fatal("unparsable expiry must be treated as expired")
+	}
+	if !ManagementRoleAdmin.Allows(ManagementRoleOperator) || ManagementRoleViewer.Allows(ManagementRoleOperator) || ManagementRole("").Allows(ManagementRoleViewer) {
+		t.Fatal("role ordering is wrong")
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+import "strings"
+
+const (
+	// MetricsListenerAPI serves /metrics on the main API listener behind client API keys.
+	MetricsListenerAPI = "api"
+	// MetricsListenerPprof serves /metrics on the pprof debug listener.
+	MetricsListenerPprof = "pprof"
+	// MetricsListenerManagement serves /v0/management/metrics behind the management key.
+	MetricsListenerManagement = "management"
+)
+
+// NormalizeMetricsListener returns a supported metrics listener name.
+// Empty and unknown values fall back to MetricsListenerAPI.
+func NormalizeMetricsListener(listener string) string {
+	switch strings.ToLower(strings.TrimSpace(listener)) {
+	case MetricsListenerPprof:
+		return MetricsListenerPprof
+	case MetricsListenerManagement:
+		return MetricsListenerManagement
+	default:
+		return MetricsListenerAPI
+	}
+}
+
+// EffectiveListener returns the normalized listener for the metrics endpoint.
+func (c MetricsConfig) EffectiveListener() string {
+	return NormalizeMetricsListener(c.Listener)
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+)
+
+// MinModelCatalogRefreshIntervalSeconds bounds refresh-interval-seconds.
+const MinModelCatalogRefreshIntervalSeconds = 60
+
+// ModelCatalogConfig selects where the models catalog (models.json) is loaded from.
+// An empty block keeps the default upstream sources. The -local-model flag still
+// disables catalog refreshes entirely.
+type ModelCatalogConfig struct {
+	// RefreshIntervalSeconds is the periodic refresh interval. 0 keeps the default of
+	// three hours; values below 60 are raised to 60.
+	RefreshIntervalSeconds int `yaml:"refresh-interval-seconds,omitempty" json:"refresh-interval-seconds,omitempty"`
+
+	// Sources are tried in order; the first one that loads, verifies and validates
+	// becomes the base catalog. Empty keeps the default upstream URLs.
+	Sources []ModelCatalogSourceConfig `yaml:"sources,omitempty" json:"sources,omitempty"`
+
+	// Overlays are merged on top of the base catalog in order. A refresh fails and
+	// keeps the active catalog when an overlay cannot be loaded.
+	Overlays []ModelCatalogSourceConfig `yaml:"overlays,omitempty" json:"overlays,omitempty"`
+
+	// PublicKeys are ed25519 public keys, base64 encoded (raw 32 bytes or PKIX DER)
+	// or PEM. When set, every source and overlay must carry a detached signature
+	// made by one of the keys.
+	PublicKeys []string `yaml:"public-keys,omitempty" json:"public-keys,omitempty"`
+}
+
+// ModelCatalogSourceConfig locates one catalog document by URL or file.
+type ModelCatalogSourceConfig struct {
+	// URL is fetched with an HTTP GET.
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+	// File is read from the local filesystem.
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+	// Signature is the URL or file of the detached ed25519 signature (raw or base64).
+	// Empty uses the document location with ".sig" appended.
+	Signature string `yaml:"signature,omitempty" json:"signature,omitempty"`
+	// Mode is the overlay merge mode: "merge" (default) merges models by ID field by
+	// field, "replace" replaces every section the overlay contains. Ignored for sources.
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+}
+
+// Settings converts the block into registry catalog settings. Invalid entries are
+// reported through the returned error and settings.Error, which makes every refresh
+// fail so a broken verification setup never falls back to unverified catalogs.
+func (c ModelCatalogConfig) Settings() (registry.ModelCatalogSettings, error) {
+	var settings registry.ModelCatalogSettings
+	var errs []error
+	if c.RefreshIntervalSeconds > 0 {
+		seconds := max(c.RefreshIntervalSeconds, MinModelCatalogRefreshIntervalSeconds)
+		settings.RefreshInterval = time.Duration(seconds) * time.Second
+	}
+	for i, source := range c.Sources {
+		converted, errSource := source.catalogSource(false)
+		if errSource != nil {
+			errs = append(errs, fmt.Errorf("sources[%d]: %w", i, errSource))
+			continue
+		}
+		settings.Sources = append(settings.Sources, converted)
+	}
+	for i, overlay := range c.Overlays {
+		converted, errOverlay := overlay.catalogSource(true)
+		if errOverlay != nil {
+			errs = append(errs, fmt.Errorf("overlays[%d]: %w", i, errOverlay))
+			continue
+		}
+		settings.Overlays = append(settings.Overlays, converted)
+	}
+	for i, raw := range c.PublicKeys {
+		key, errKey := ParseModelCatalogPublicKey(raw)
+		if errKey != nil {
+			errs = append(errs, fmt.Errorf("public-keys[%d]: %w", i, errKey))
+			continue
+		}
+		settings.PublicKeys = append(settings.PublicKeys, key)
+	}
+	if errJoined := errors.Join(errs...); errJoined != nil {
+		settings.Error = errJoined.Error()
+		return settings, errJoined
+	}
+	return settings, nil
+}
+
+func (s ModelCatalogSourceConfig) catalogSource(overlay bool) (registry.ModelCatalogSource, error) {
+	source := registry.ModelCatalogSource{
+		URL:       strings.TrimSpace(s.URL),
+		File:      strings.TrimSpace(s.File),
+		Signature: strings.TrimSpace(s.Signature),
+	}
+	if (source.URL == "") == (source.File == "") {
+		return source, errors.New("exactly one of url and file must be set")
+	}
+	if source.URL != "" && !strings.HasPrefix(source.URL, "http://") && !strings.HasPrefix(source.URL, "https://") {
+		return source, fmt.Errorf("url %q must use http or https", source.URL)
+	}
+	mode := strings.ToLower(strings.TrimSpace(s.Mode))
+	switch {
+	case mode == "":
+	case !overlay:
+		return source, errors.New("mode only applies to overlays")
+	case mode == registry.ModelCatalogOverlayMerge || mode == registry.ModelCatalogOverlayReplace:
+		source.Mode = mode
+	default:
+		return source, fmt.Errorf("unsupported mode %q: expected merge or replace", s.Mode)
+	}
+	return source, nil
+}
+
+// ParseModelCatalogPublicKey parses an ed25519 public key given as PEM, as base64
+// encoded PKIX DER, or as base64 encoded raw key bytes.
+func ParseModelCatalogPublicKey(raw string) (ed25519.PublicKey, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, errors.New("empty public key")
+	}
+	var der []byte
+	if block, _ := pem.Decode([]byte(raw)); block != nil {
+		der = block.Bytes
+	} else {
+		decoded, errDecode := base64.StdEncoding.DecodeString(raw)
+		if errDecode != nil {
+			return nil, fmt.Errorf("decode public key: %w", errDecode)
+		}
+		if len(decoded) == ed25519.PublicKeySize {
+			return ed25519.PublicKey(decoded), nil
+		}
+		der = decoded
+	}
+	parsed, errParse := x509.ParsePKIXPublicKey(der)
+	if errParse != nil {
+		return nil, fmt.Errorf("parse public key: %w", errParse)
+	}
+	key, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an ed25519 key")
+	}
+	return key, nil
+}
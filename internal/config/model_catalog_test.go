@@ -0,0 +1,75 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseModelCatalogPublicKeyFormats(t *testing.T) {
+	public, _, errKey := ed25519.GenerateKey(rand.Reader)
+	if errKey != nil {
+		t.Fatalf("generate key: %v", errKey)
+	}
+	der, errMarshal := x509.MarshalPKIXPublicKey(public)
+	if errMarshal != nil {
+		t.Fatalf("marshal key: %v", errMarshal)
+	}
+	for name, raw := range map[string]string{
+		"raw":  base64.StdEncoding.EncodeToString(public),
+		"pkix": base64.StdEncoding.EncodeToString(der),
+		"pem":  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	} {
+		key, errParse := ParseModelCatalogPublicKey(raw)
+		if errParse != nil || !key.Equal(public) {
+			t.Errorf("%s: key = %x, err = %v", name, key, errParse)
+		}
+	}
+	if _, errParse := ParseModelCatalogPublicKey("bm90LWEta2V5"); errParse == nil {
+		t.Error("malformed key parsed without error")
+	}
+}
+
+func TestModelCatalogConfigSettings(t *testing.T) {
+	public, _, errKey := ed25519.GenerateKey(rand.Reader)
+	if errKey != nil {
+		t.Fatalf("generate key: %v", errKey)
+	}
+	settings, errSettings := ModelCatalogConfig{
+		RefreshIntervalSeconds: 10,
+		Sources: []ModelCatalogSourceConfig{
+			{URL: " https://mirror.example.com/models.json "},
+			{File: "/etc/cliproxy/models.json", Signature: "/etc/cliproxy/models.sig"},
+		},
+		Overlays:   []ModelCatalogSourceConfig{{File: "/etc/cliproxy/overlay.json", Mode: "Replace"}},
+		PublicKeys: []string{base64.StdEncoding.EncodeToString(public)},
+	}.Settings()
+	if errSettings != nil {
+		t.Fatalf("Settings() error = %v", errSettings)
+	}
+	if settings.RefreshInterval != time.Minute || len(settings.Sources) != 2 || settings.Sources[0].URL != "https://mirror.example.com/models.json" {
+		t.Fatalf("settings = %+v", settings)
+	}
+	if settings.Overlays[0].Mode != "replace" || len(settings.PublicKeys) != 1 || settings.Error != "" {
+		t.Fatalf("settings = %+v", settings)
+	}
+
+	settings, errSettings = ModelCatalogConfig{
+		Sources:    []ModelCatalogSourceConfig{{URL: "ftp://mirror/models.json"}, {URL: "https://a", File: "/b"}, {File: "/c", Mode: "merge"}},
+		Overlays:   []ModelCatalogSourceConfig{{File: "/d", Mode: "append"}},
+		PublicKeys: []string{"not base64!"},
+	}.Settings()
+	if errSettings == nil || settings.Error == "" || len(settings.Sources) != 0 || len(settings.Overlays) != 0 {
+		t.Fatalf("invalid block: settings = %+v, err = %v", settings, errSettings)
+	}
+	for _, want := range []string{"sources[0]", "sources[1]", "sources[2]", "overlays[0]", "public-keys[0]"} {
+		if !strings.Contains(errSettings.Error(), want) {
+			t.Errorf("error %q does not mention %s", errSettings, want)
+		}
+	}
+}
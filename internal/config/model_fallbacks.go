@@ -0,0 +1,106 @@
+package config
+
+import "strings"
+
+const (
+	// ModelFallbackAuthKindOAuth restricts a fallback target to OAuth/file-backed credentials.
+	ModelFallbackAuthKindOAuth = "oauth"
+	// ModelFallbackAuthKindAPIKey restricts a fallback target to API key credentials.
+	ModelFallbackAuthKindAPIKey = "api-key"
+)
+
+// ModelFallback is an ordered chain of targets tried for one client-facing model.
+// When a request names Model, the targets are tried in order instead of the
+// default routing, moving on when every credential of a target is cooling down,
+// over quota or failing with a retryable error.
+type ModelFallback struct {
+	// Model is the client-requested model the chain applies to.
+	Model string `yaml:"model" json:"model"`
+	// Targets are the hops of the chain, tried in order.
+	Targets []ModelFallbackTarget `yaml:"targets" json:"targets"`
+}
+
+// ModelFallbackTarget is one hop of a fallback chain.
+type ModelFallbackTarget struct {
+	// Model is the upstream model of the hop. Empty keeps the requested model.
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+	// Provider limits the hop to one provider (e.g. "claude", "antigravity" or an
+	// openai-compatibility name). Empty uses every provider serving Model.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+	// AuthKind limits the hop to "oauth" or "api-key" credentials. Empty allows both.
+	AuthKind string `yaml:"auth-kind,omitempty" json:"auth-kind,omitempty"`
+}
+
+// NormalizeModelFallbackAuthKind returns a supported auth kind, or "" when kind
+// is empty or unknown.
+func NormalizeModelFallbackAuthKind(kind string) string {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case ModelFallbackAuthKindOAuth, "oauth2":
+		return ModelFallbackAuthKindOAuth
+	case ModelFallbackAuthKindAPIKey, "apikey", "api_key":
+		return ModelFallbackAuthKindAPIKey
+	default:
+		return ""
+	}
+}
+
+// SanitizeModelFallbacks trims chains, normalizes providers and auth kinds, and
+// drops chains without a model or without targets. The first chain for a model wins.
+func (cfg *Config) SanitizeModelFallbacks() {
+	if cfg == nil {
+		return
+	}
+	cfg.ModelFallbacks = NormalizeModelFallbacks(cfg.ModelFallbacks)
+}
+
+// NormalizeModelFallbacks returns the sanitized form of chains.
+func NormalizeModelFallbacks(chains []ModelFallback) []ModelFallback {
+	if len(chains) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(chains))
+	out := make([]ModelFallback, 0, len(chains))
+	for _, chain := range chains {
+		model := strings.TrimSpace(chain.Model)
+		key := strings.ToLower(model)
+		if model == "" {
+			continue
+		}
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		targets := make([]ModelFallbackTarget, 0, len(chain.Targets))
+		for _, target := range chain.Targets {
+			target.Model = strings.TrimSpace(target.Model)
+			target.Provider = strings.ToLower(strings.TrimSpace(target.Provider))
+			target.AuthKind = NormalizeModelFallbackAuthKind(target.AuthKind)
+			if target.Model == "" && target.Provider == "" && target.AuthKind == "" {
+				continue
+			}
+			targets = append(targets, target)
+		}
+		if len(targets) == 0 {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, ModelFallback{Model: model, Targets: targets})
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// ModelFallbackChain returns the chain configured for model, matched case-insensitively.
+func (cfg *Config) ModelFallbackChain(model string) (ModelFallback, bool) {
+	model = strings.TrimSpace(model)
+	if cfg == nil || model == "" {
+		return ModelFallback{}, false
+	}
+	for _, chain := range cfg.ModelFallbacks {
+		if strings.EqualFold(chain.Model, model) {
+			return chain, true
+		}
+	}
+	return ModelFallback{}, false
+}
@@ -0,0 +1,17 @@
+package config
+
+import "testing"
+
+func TestNormalizeModelFallbacksDropsEmptyAndDuplicateChains(t *testing.T) {
+	got := NormalizeModelFallbacks([]ModelFallback{
+		{Model: " Opus ", Targets: []ModelFallbackTarget{{Provider: " Claude ", AuthKind: "API_KEY"}, {}}},
+		{Model: "opus", Targets: []ModelFallbackTarget{{Model: "other"}}},
+		{Model: "empty"},
+	})
+	if len(got) != 1 || got[0].Model != "Opus" || len(got[0].Targets) != 1 {
+		t.Fatalf("NormalizeModelFallbacks() = %+v", got)
+	}
+	if target := got[0].Targets[0]; target.Provider != "claude" || target.AuthKind != ModelFallbackAuthKindAPIKey {
+		t.Fatalf("target = %+v", target)
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+)
+
+// ModelPriceOverride sets the prices of a model, replacing the prices of the remote
+// models catalog. Prices are USD per one million tokens.
+type ModelPriceOverride struct {
+	// Model is the client-facing alias or upstream model the prices apply to.
+	// '*' matches any sequence of characters; matching is case-insensitive.
+	Model string `yaml:"model" json:"model"`
+	// Provider limits the override to one provider (e.g. "claude", "codex" or an
+	// openai-compatibility name). Empty applies to every provider.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+	// Input is the price of uncached input tokens.
+	Input float64 `yaml:"input" json:"input"`
+	// CachedInput is the price of cache reads. Defaults to Input.
+	CachedInput *float64 `yaml:"cached-input,omitempty" json:"cached-input,omitempty"`
+	// CacheWrite is the price of cache writes. Defaults to Input.
+	CacheWrite *float64 `yaml:"cache-write,omitempty" json:"cache-write,omitempty"`
+	// Output is the price of non-reasoning output tokens.
+	Output float64 `yaml:"output" json:"output"`
+	// Reasoning is the price of reasoning tokens. Defaults to Output.
+	Reasoning *float64 `yaml:"reasoning,omitempty" json:"reasoning,omitempty"`
+}
+
+// Pricing returns the override as catalog pricing.
+func (o ModelPriceOverride) Pricing() *registry.ModelPricing {
+	pricing := &registry.ModelPricing{
+		Input:       o.Input,
+		CachedInput: o.CachedInput,
+		CacheWrite:  o.CacheWrite,
+		Output:      o.Output,
+		Reasoning:   o.Reasoning,
+	}
+	return pricing.Clone()
+}
+
+// Matches reports whether the override applies to model served by provider.
+func (o ModelPriceOverride) Matches(provider, model string) bool {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" {
+		return false
+	}
+	if o.Provider != "" && !strings.EqualFold(o.Provider, strings.TrimSpace(provider)) {
+		return false
+	}
+	return matchModelGlob(strings.ToLower(o.Model), model)
+}
+
+// SanitizeModelPricing trims overrides and drops entries without a model or with
+// negative prices.
+func (cfg *Config) SanitizeModelPricing() {
+	if cfg == nil {
+		return
+	}
+	cfg.ModelPricing = NormalizeModelPricing(cfg.ModelPricing)
+}
+
+// NormalizeModelPricing returns the sanitized form of overrides.
+func NormalizeModelPricing(overrides []ModelPriceOverride) []ModelPriceOverride {
+	if len(overrides) == 0 {
+		return nil
+	}
+	out := make([]ModelPriceOverride, 0, len(overrides))
+	for _, override := range overrides {
+		override.Model = strings.TrimSpace(override.Model)
+		override.Provider = strings.ToLower(strings.TrimSpace(override.Provider))
+		if override.Model == "" {
+			continue
+		}
+		if errValidate := override.Pricing().Validate(); errValidate != nil {
+			continue
+		}
+		out = append(out, override)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// LookupModelPriceOverride returns the prices of the first override matching provider
+// and one of models, tried in order. Callers pass the client-facing alias before the
+// upstream model so an alias override wins.
+func LookupModelPriceOverride(overrides []ModelPriceOverride, provider string, models ...string) *registry.ModelPricing {
+	for _, model := range models {
+		for _, override := range overrides {
+			if override.Matches(provider, model) {
+				return override.Pricing()
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package config
+
+import "testing"
+
+func TestNormalizeModelPricingAndLookup(t *testing.T) {
+	negative := -1.0
+	overrides := NormalizeModelPricing([]ModelPriceOverride{
+		{Model: "  ", Input: 1, Output: 1},
+		{Model: "bad", Input: 1, Output: 1, Reasoning: &negative},
+		{Model: " gpt-5* ", Provider: " Codex ", Input: 1.25, Output: 10},
+		{Model: "team-fast", Input: 0.5, Output: 2},
+	})
+	if len(overrides) != 2 || overrides[0].Model != "gpt-5*" || overrides[0].Provider != "codex" {
+		t.Fatalf("normalized overrides = %+v", overrides)
+	}
+
+	if pricing := LookupModelPriceOverride(overrides, "claude", "gpt-5.4"); pricing != nil {
+		t.Fatalf("provider-scoped override matched another provider: %+v", pricing)
+	}
+	pricing := LookupModelPriceOverride(overrides, "CODEX", "GPT-5.4")
+	if pricing == nil || pricing.Input != 1.25 || pricing.ReasoningPrice() != 10 {
+		t.Fatalf("codex pricing = %+v", pricing)
+	}
+	// The alias is tried before the upstream model.
+	pricing = LookupModelPriceOverride(overrides, "codex", "team-fast", "gpt-5.4")
+	if pricing == nil || pricing.Input != 0.5 {
+		t.Fatalf("alias pricing = %+v", pricing)
+	}
+}
@@ -11,6 +11,8 @@ import (
 
 // ParseConfigBytes parses a YAML configuration payload into Config and applies the same
 // in-memory normalizations as LoadConfigOptional, without persisting any changes to disk.
+// Payloads come from Home or other remote sources, so secret references are kept as
+// written and never resolved.
 func ParseConfigBytes(data []byte) (*Config, error) {
 	if len(data) == 0 {
 		return nil, fmt.Errorf("config payload is empty")
@@ -59,6 +61,12 @@ func ParseConfigBytes(data []byte) (*Config, error) {
 		cfg.RemoteManagement.SecretKey = string(hashed)
 	}
 
+	// Hash plaintext scoped management keys in memory; saving the config writes the hashes back.
+	cfg.SanitizeManagementKeys()
+	if errHash := cfg.hashManagementKeys(); errHash != nil {
+		return nil, errHash
+	}
+
 	cfg.RemoteManagement.PanelGitHubRepository = strings.TrimSpace(cfg.RemoteManagement.PanelGitHubRepository)
 	if cfg.RemoteManagement.PanelGitHubRepository == "" {
 		cfg.RemoteManagement.PanelGitHubRepository = DefaultPanelGitHubRepository
@@ -97,6 +105,8 @@ func ParseConfigBytes(data []byte) (*Config, error) {
 	cfg.SanitizeGeminiKeys()
 	cfg.SanitizeInteractionsKeys()
 	cfg.SanitizeVertexCompatKeys()
+	cfg.SanitizeBedrockKeys()
+	cfg.SanitizeAzureOpenAIKeys()
 	cfg.SanitizeCodexKeys()
 	cfg.SanitizeXAIKeys()
 	cfg.SanitizeCodexHeaderDefaults()
@@ -105,7 +115,12 @@ func ParseConfigBytes(data []byte) (*Config, error) {
 	cfg.SanitizeOpenAICompatibility()
 	cfg.OAuthExcludedModels = NormalizeOAuthExcludedModels(cfg.OAuthExcludedModels)
 	cfg.SanitizeOAuthModelAlias()
+	cfg.SanitizeModelFallbacks()
+	cfg.SanitizeStreamHedging()
+	cfg.SanitizeModelPricing()
 	cfg.SanitizePayloadRules()
+	cfg.SanitizeClientAPIKeys()
+	cfg.SanitizeAPIKeyLimits()
 
 	return &cfg, nil
 }
@@ -0,0 +1,61 @@
+package config
+
+import "strings"
+
+const (
+	// ResponseCacheBackendMemory keeps cached responses in a bounded in-process LRU.
+	ResponseCacheBackendMemory = "memory"
+	// ResponseCacheBackendRedis shares cached responses through Redis.
+	ResponseCacheBackendRedis = "redis"
+	// ResponseCacheBackendPostgres shares cached responses through the Postgres token store.
+	ResponseCacheBackendPostgres = "postgres"
+
+	// DefaultResponseCacheTTLSeconds is used when response-cache.ttl-seconds is unset.
+	DefaultResponseCacheTTLSeconds = 600
+	// DefaultResponseCacheMaxEntries is used when response-cache.max-entries is unset.
+	DefaultResponseCacheMaxEntries = 1000
+	// DefaultResponseCacheMaxEntrySizeKB is used when response-cache.max-entry-size-kb is unset.
+	DefaultResponseCacheMaxEntrySizeKB = 1024
+)
+
+// NormalizeResponseCacheBackend returns a supported response cache backend name.
+// Empty and unknown values fall back to ResponseCacheBackendMemory.
+func NormalizeResponseCacheBackend(backend string) string {
+	switch strings.ToLower(strings.TrimSpace(backend)) {
+	case ResponseCacheBackendRedis:
+		return ResponseCacheBackendRedis
+	case ResponseCacheBackendPostgres, "postgresql", "pg":
+		return ResponseCacheBackendPostgres
+	default:
+		return ResponseCacheBackendMemory
+	}
+}
+
+// EffectiveBackend returns the normalized backend for the response cache.
+func (c ResponseCacheConfig) EffectiveBackend() string {
+	return NormalizeResponseCacheBackend(c.Backend)
+}
+
+// EffectiveTTLSeconds returns the lifetime of cached responses in seconds.
+func (c ResponseCacheConfig) EffectiveTTLSeconds() int {
+	if c.TTLSeconds <= 0 {
+		return DefaultResponseCacheTTLSeconds
+	}
+	return c.TTLSeconds
+}
+
+// EffectiveMaxEntries returns the capacity of the memory backend.
+func (c ResponseCacheConfig) EffectiveMaxEntries() int {
+	if c.MaxEntries <= 0 {
+		return DefaultResponseCacheMaxEntries
+	}
+	return c.MaxEntries
+}
+
+// EffectiveMaxEntrySize returns the largest cacheable response in bytes.
+func (c ResponseCacheConfig) EffectiveMaxEntrySize() int {
+	if c.MaxEntrySizeKB <= 0 {
+		return DefaultResponseCacheMaxEntrySizeKB << 10
+	}
+	return c.MaxEntrySizeKB << 10
+}
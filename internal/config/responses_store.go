@@ -0,0 +1,61 @@
+package config
+
+import "strings"
+
+const (
+	// ResponsesStoreBackendMemory keeps stored responses in a bounded in-process LRU.
+	ResponsesStoreBackendMemory = "memory"
+	// ResponsesStoreBackendFile keeps stored responses as JSON files on local disk.
+	ResponsesStoreBackendFile = "file"
+	// ResponsesStoreBackendPostgres keeps stored responses in the Postgres token store.
+	ResponsesStoreBackendPostgres = "postgres"
+
+	// DefaultResponsesStoreTTLSeconds is used when responses-store.ttl-seconds is unset.
+	DefaultResponsesStoreTTLSeconds = 86400
+	// DefaultResponsesStoreMaxEntries is used when responses-store.max-entries is unset.
+	DefaultResponsesStoreMaxEntries = 10000
+	// DefaultResponsesStoreMaxEntrySizeKB is used when responses-store.max-entry-size-kb is unset.
+	DefaultResponsesStoreMaxEntrySizeKB = 4096
+)
+
+// NormalizeResponsesStoreBackend returns a supported responses store backend name.
+// Empty and unknown values fall back to ResponsesStoreBackendMemory.
+func NormalizeResponsesStoreBackend(backend string) string {
+	switch strings.ToLower(strings.TrimSpace(backend)) {
+	case ResponsesStoreBackendFile, "disk":
+		return ResponsesStoreBackendFile
+	case ResponsesStoreBackendPostgres, "postgresql", "pg":
+		return ResponsesStoreBackendPostgres
+	default:
+		return ResponsesStoreBackendMemory
+	}
+}
+
+// EffectiveBackend returns the normalized backend for the responses store.
+func (c ResponsesStoreConfig) EffectiveBackend() string {
+	return NormalizeResponsesStoreBackend(c.Backend)
+}
+
+// EffectiveTTLSeconds returns the lifetime of stored responses in seconds.
+func (c ResponsesStoreConfig) EffectiveTTLSeconds() int {
+	if c.TTLSeconds <= 0 {
+		return DefaultResponsesStoreTTLSeconds
+	}
+	return c.TTLSeconds
+}
+
+// EffectiveMaxEntries returns the capacity of the memory and file backends.
+func (c ResponsesStoreConfig) EffectiveMaxEntries() int {
+	if c.MaxEntries <= 0 {
+		return DefaultResponsesStoreMaxEntries
+	}
+	return c.MaxEntries
+}
+
+// EffectiveMaxEntrySize returns the largest storable response in bytes.
+func (c ResponsesStoreConfig) EffectiveMaxEntrySize() int {
+	if c.MaxEntrySizeKB <= 0 {
+		return DefaultResponsesStoreMaxEntrySizeKB << 10
+	}
+	return c.MaxEntrySizeKB << 10
+}
@@ -49,7 +49,13 @@ type SDKConfig struct {
 	ClaudeCode ClaudeCodeConfig `yaml:"claude-code" json:"claude-code"`
 
 	// APIKeys is a list of keys for authenticating clients to this proxy server.
-	APIKeys []string `yaml:"api-keys" json:"api-keys"`
+	// Each entry is either a plain key string or an object that scopes the key.
+	// Use ClientAPIKeysFromStrings to build it from plain key strings.
+	APIKeys []ClientAPIKey `yaml:"api-keys" json:"api-keys"`
+
+	// APIKeyLimits configures per-client request rate limits and token budgets.
+	// An entry whose api-key is "*" applies to every client key without its own entry.
+	APIKeyLimits []APIKeyLimit `yaml:"api-key-limits,omitempty" json:"api-key-limits,omitempty"`
 
 	// PassthroughHeaders controls whether upstream response headers are forwarded to downstream clients.
 	// Default is false (disabled).
@@ -69,6 +75,52 @@ type ClaudeCodeConfig struct {
 	DisableCloakingModelList bool `yaml:"disable-cloaking-model-list" json:"disable-cloaking-model-list"`
 }
 
+// ClientAPIKey is one api-keys entry. A plain string entry only sets Key and
+// leaves the key unrestricted.
+type ClientAPIKey struct {
+	// Key is the secret clients present.
+	Key string `yaml:"key" json:"key"`
+
+	// Label is a human-readable name for the key.
+	Label string `yaml:"label,omitempty" json:"label,omitempty"`
+
+	// Models lists model name globs (e.g. "gpt-5*") the key may request. Empty allows every model.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// Providers lists the providers (e.g. "codex", "gemini") requests may be routed to.
+	// Empty allows every provider.
+	Providers []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+
+	// Prefix forces requests onto credentials registered with this model prefix, so
+	// "gpt-5" is routed as "<prefix>/gpt-5".
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// ExpiresAt is an RFC 3339 timestamp or YYYY-MM-DD date (UTC midnight) after which the key is refused.
+	ExpiresAt string `yaml:"expires-at,omitempty" json:"expires-at,omitempty"`
+
+	// Disabled refuses the key without removing it from the list.
+	Disabled bool `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// APIKeyLimit holds the request and token limits for one client API key.
+// Zero values leave the corresponding limit disabled.
+type APIKeyLimit struct {
+	// APIKey is the client key the limits apply to, or "*" for the default entry.
+	APIKey string `yaml:"api-key" json:"api-key"`
+
+	// RequestsPerMinute caps admitted requests over a sliding one-minute window.
+	RequestsPerMinute int `yaml:"requests-per-minute,omitempty" json:"requests-per-minute,omitempty"`
+
+	// MaxConcurrentRequests caps in-flight requests, including open streams.
+	MaxConcurrentRequests int `yaml:"max-concurrent-requests,omitempty" json:"max-concurrent-requests,omitempty"`
+
+	// DailyTokenBudget caps total tokens per UTC calendar day.
+	DailyTokenBudget int64 `yaml:"daily-token-budget,omitempty" json:"daily-token-budget,omitempty"`
+
+	// MonthlyTokenBudget caps total tokens per UTC calendar month.
+	MonthlyTokenBudget int64 `yaml:"monthly-token-budget,omitempty" json:"monthly-token-budget,omitempty"`
+}
+
 // StreamingConfig holds server streaming behavior configuration.
 type StreamingConfig struct {
 	// KeepAliveSeconds controls how often the server emits SSE heartbeats (": keep-alive\n\n").
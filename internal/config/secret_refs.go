@@ -0,0 +1,498 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// secretCommandTimeout bounds how long a ${cmd:...} reference may run.
+const secretCommandTimeout = 10 * time.Second
+
+// commandSecretScheme is the scheme of references that run a command.
+const commandSecretScheme = "cmd"
+
+// fileSecretScheme is the scheme of references that read a file.
+const fileSecretScheme = "file"
+
+// secretRefPattern matches ${scheme:value} references embedded in string values.
+var secretRefPattern = regexp.MustCompile(`\$\{([A-Za-z][A-Za-z0-9_-]*):([^}]*)\}`)
+
+// SecretResolver returns the secret named by the value of a ${scheme:value} reference.
+type SecretResolver func(ctx context.Context, value string) (string, error)
+
+var secretResolvers = struct {
+	sync.RWMutex
+	byScheme map[string]SecretResolver
+}{byScheme: map[string]SecretResolver{
+	"env":  resolveEnvSecret,
+	"file": resolveFileSecret,
+}}
+
+// commandSecretsEnabled gates ${cmd:...} references. They run programs on the host,
+// so they are off unless the operator starts the server with -allow-secret-commands.
+var commandSecretsEnabled atomic.Bool
+
+// SetCommandSecretsEnabled allows or forbids ${cmd:...} references in the local
+// config file. Payloads from Home and the management API never resolve references.
+func SetCommandSecretsEnabled(enabled bool) {
+	commandSecretsEnabled.Store(enabled)
+}
+
+// RegisterSecretResolver installs resolver for ${scheme:...} references in config
+// values, replacing any resolver already registered for scheme. A nil resolver
+// removes the scheme. References with an unregistered scheme are left as-is.
+// The cmd scheme is reserved for SetCommandSecretsEnabled.
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	scheme = strings.ToLower(strings.TrimSpace(scheme))
+	if scheme == "" || scheme == commandSecretScheme {
+		return
+	}
+	secretResolvers.Lock()
+	defer secretResolvers.Unlock()
+	if resolver == nil {
+		delete(secretResolvers.byScheme, scheme)
+		return
+	}
+	secretResolvers.byScheme[scheme] = resolver
+}
+
+func secretResolverFor(scheme string) SecretResolver {
+	scheme = strings.ToLower(scheme)
+	if scheme == commandSecretScheme {
+		if commandSecretsEnabled.Load() {
+			return resolveCommandSecret
+		}
+		return refuseCommandSecret
+	}
+	secretResolvers.RLock()
+	defer secretResolvers.RUnlock()
+	return secretResolvers.byScheme[scheme]
+}
+
+func resolveEnvSecret(_ context.Context, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+func resolveFileSecret(_ context.Context, path string) (string, error) {
+	data, err := os.ReadFile(strings.TrimSpace(path))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// refuseCommandSecret fails ${cmd:...} references while they are disabled, so the
+// reference text is never used as the secret itself.
+func refuseCommandSecret(context.Context, string) (string, error) {
+	return "", fmt.Errorf("command secret references are disabled; start the server with -allow-secret-commands to resolve them")
+}
+
+// resolveCommandSecret runs the command without a shell and returns its standard output.
+func resolveCommandSecret(ctx context.Context, command string) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", fmt.Errorf("empty command")
+	}
+	ctx, cancel := context.WithTimeout(ctx, secretCommandTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("run %s: %w", args[0], err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// SecretReference records where a resolved secret came from. YAMLPath and JSONPath
+// locate the value in the YAML file and in the management JSON view; JSONPath is
+// nil when the value is not part of the JSON view. Value is the value currently
+// held at that location, so a value replaced after loading is never overwritten.
+type SecretReference struct {
+	Ref      string
+	Value    string
+	YAMLPath []string
+	JSONPath []string
+}
+
+// secretPath locates a config value while it is walked.
+type secretPath struct {
+	yaml   []string
+	json   []string
+	inJSON bool
+}
+
+func (p secretPath) String() string {
+	var b strings.Builder
+	for _, segment := range p.yaml {
+		if strings.HasPrefix(segment, "[") {
+			b.WriteString(segment)
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+func (p secretPath) field(yamlName, jsonName string, inJSON bool) secretPath {
+	next := secretPath{yaml: p.yaml, json: p.json, inJSON: p.inJSON && inJSON}
+	if yamlName != "" {
+		next.yaml = appendSegment(p.yaml, yamlName)
+	}
+	if jsonName != "" {
+		next.json = appendSegment(p.json, jsonName)
+	}
+	return next
+}
+
+func (p secretPath) index(i int) secretPath {
+	segment := "[" + strconv.Itoa(i) + "]"
+	return secretPath{yaml: appendSegment(p.yaml, segment), json: appendSegment(p.json, segment), inJSON: p.inJSON}
+}
+
+func appendSegment(path []string, segment string) []string {
+	out := make([]string, len(path), len(path)+1)
+	copy(out, path)
+	return append(out, segment)
+}
+
+// ResolveSecretReferences replaces ${env:NAME}, ${file:PATH} and other registered
+// references in every string value of the config; ${cmd:COMMAND} is resolved only
+// after SetCommandSecretsEnabled(true). The reference behind each resolved value is
+// kept in SecretReferences, keyed by its YAML path, so the config can be written
+// back and served by the management API without the secrets.
+func (cfg *Config) ResolveSecretReferences() error {
+	if cfg == nil {
+		return nil
+	}
+	refs := make(map[string]SecretReference)
+	if err := resolveSecretValue(context.Background(), reflect.ValueOf(cfg).Elem(), secretPath{inJSON: true}, refs); err != nil {
+		return err
+	}
+	cfg.SecretReferences = nil
+	if len(refs) > 0 {
+		cfg.SecretReferences = refs
+	}
+	return nil
+}
+
+// rebindSecretReference follows a value loaded from a reference when it is
+// rewritten in memory, such as a management key replaced by its hash.
+func (cfg *Config) rebindSecretReference(path, from, to string) bool {
+	ref, ok := cfg.SecretReferences[path]
+	if !ok || ref.Value != from {
+		return false
+	}
+	ref.Value = to
+	cfg.SecretReferences[path] = ref
+	return true
+}
+
+func resolveSecretValue(ctx context.Context, v reflect.Value, path secretPath, refs map[string]SecretReference) error {
+	switch v.Kind() {
+	case reflect.String:
+		resolved, changed, err := resolveSecretString(ctx, v.String())
+		if err != nil {
+			return fmt.Errorf("resolve secret reference at %s: %w", path, err)
+		}
+		if changed && v.CanSet() {
+			recordSecretReference(refs, path, v.String(), resolved)
+			v.SetString(resolved)
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return resolveSecretValue(ctx, v.Elem(), path, refs)
+		}
+	case reflect.Struct:
+		if v.Type() == yamlNodeType {
+			return nil
+		}
+		// A plain api-keys entry is written as the bare key, so its key lives at the entry's path.
+		if key, ok := v.Interface().(ClientAPIKey); ok && key.IsPlain() {
+			return resolveSecretValue(ctx, v.FieldByName("Key"), path, refs)
+		}
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			yamlName, yamlFlags, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if yamlName == "-" {
+				continue
+			}
+			if yamlName == "" && !strings.Contains(yamlFlags, "inline") {
+				yamlName = strings.ToLower(field.Name)
+			}
+			jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			inJSON := jsonName != "-"
+			if jsonName == "" && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+				jsonName = field.Name
+			}
+			if err := resolveSecretValue(ctx, v.Field(i), path.field(yamlName, jsonName, inJSON), refs); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := resolveSecretValue(ctx, v.Index(i), path.index(i), refs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			iter := v.MapRange()
+			for iter.Next() {
+				if iter.Value().Kind() == reflect.Pointer {
+					key := fmt.Sprint(iter.Key())
+					if err := resolveSecretValue(ctx, iter.Value(), path.field(key, key, true), refs); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key())
+			raw := iter.Value().String()
+			resolved, changed, err := resolveSecretString(ctx, raw)
+			if err != nil {
+				return fmt.Errorf("resolve secret reference at %s: %w", path.field(key, key, true), err)
+			}
+			if changed {
+				recordSecretReference(refs, path.field(key, key, true), raw, resolved)
+				v.SetMapIndex(iter.Key(), reflect.ValueOf(resolved).Convert(v.Type().Elem()))
+			}
+		}
+	}
+	return nil
+}
+
+func recordSecretReference(refs map[string]SecretReference, path secretPath, ref, resolved string) {
+	entry := SecretReference{Ref: ref, Value: resolved, YAMLPath: path.yaml}
+	if path.inJSON {
+		entry.JSONPath = path.json
+	}
+	refs[path.String()] = entry
+}
+
+// resolveSecretString resolves every registered reference in value.
+func resolveSecretString(ctx context.Context, value string) (string, bool, error) {
+	if !strings.Contains(value, "${") {
+		return value, false, nil
+	}
+	var errResolve error
+	changed := false
+	resolved := secretRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		if errResolve != nil {
+			return ref
+		}
+		match := secretRefPattern.FindStringSubmatch(ref)
+		resolver := secretResolverFor(match[1])
+		if resolver == nil {
+			return ref
+		}
+		secret, err := resolver(ctx, match[2])
+		if err != nil {
+			errResolve = fmt.Errorf("%s: %w", ref, err)
+			return ref
+		}
+		changed = true
+		return secret
+	})
+	if errResolve != nil {
+		return "", false, errResolve
+	}
+	return resolved, changed, nil
+}
+
+// restoreSecretReferencesInNode puts the original reference text back at the
+// path of every resolved secret whose value is unchanged in node.
+func restoreSecretReferencesInNode(node *yaml.Node, refs map[string]SecretReference) {
+	for _, ref := range refs {
+		target := lookupYAMLPath(node, ref.YAMLPath)
+		if target == nil || target.Kind != yaml.ScalarNode || target.Value != ref.Value {
+			continue
+		}
+		target.Value = ref.Ref
+		target.Style = 0
+		target.Tag = "!!str"
+	}
+}
+
+func lookupYAMLPath(node *yaml.Node, path []string) *yaml.Node {
+	for _, segment := range path {
+		if node == nil {
+			return nil
+		}
+		switch node.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == segment {
+					next = node.Content[i+1]
+					break
+				}
+			}
+			node = next
+		case yaml.SequenceNode:
+			i, ok := segmentIndex(segment)
+			if !ok || i >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[i]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+func segmentIndex(segment string) (int, bool) {
+	if !strings.HasPrefix(segment, "[") || !strings.HasSuffix(segment, "]") {
+		return 0, false
+	}
+	i, err := strconv.Atoi(segment[1 : len(segment)-1])
+	return i, err == nil && i >= 0
+}
+
+// HostSecretReferences returns every ${cmd:...} and ${file:...} reference written in data.
+func HostSecretReferences(data []byte) map[string]struct{} {
+	var root yaml.Node
+	if errParse := yaml.Unmarshal(data, &root); errParse != nil {
+		return nil
+	}
+	return hostSecretReferencesInNode(&root)
+}
+
+func hostSecretReferencesInNode(node *yaml.Node) map[string]struct{} {
+	refs := make(map[string]struct{})
+	walkYAMLScalars(node, nil, func(_ []string, scalar *yaml.Node) {
+		for _, match := range secretRefPattern.FindAllStringSubmatch(scalar.Value, -1) {
+			if isHostSecretScheme(match[1]) {
+				refs[match[0]] = struct{}{}
+			}
+		}
+	})
+	return refs
+}
+
+// isHostSecretScheme reports whether references of scheme read host state that the
+// management API must not reach: commands run programs and files expose any path
+// readable by the server, whose resolved values the config endpoints return.
+func isHostSecretScheme(scheme string) bool {
+	return strings.EqualFold(scheme, commandSecretScheme) || strings.EqualFold(scheme, fileSecretScheme)
+}
+
+// CheckNoNewHostSecrets fails when updated contains a ${cmd:...} or ${file:...}
+// reference that current does not. Only the operator may add them, by editing the
+// config file directly.
+func CheckNoNewHostSecrets(current, updated map[string]struct{}) error {
+	for ref := range updated {
+		if _, ok := current[ref]; !ok {
+			return fmt.Errorf("%s: command and file secret references can only be added by editing the config file on the host", ref)
+		}
+	}
+	return nil
+}
+
+func walkYAMLScalars(node *yaml.Node, path []string, fn func(path []string, node *yaml.Node)) {
+	if node == nil {
+		return
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			walkYAMLScalars(child, path, fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			walkYAMLScalars(node.Content[i+1], appendSegment(path, node.Content[i].Value), fn)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			walkYAMLScalars(child, appendSegment(path, "["+strconv.Itoa(i)+"]"), fn)
+		}
+	case yaml.ScalarNode:
+		fn(path, node)
+	}
+}
+
+// WithSecretReferences returns the JSON form of the config with every resolved
+// secret replaced by the reference it was loaded from.
+func (cfg *Config) WithSecretReferences() (any, error) {
+	if cfg == nil {
+		return map[string]any{}, nil
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.SecretReferences) == 0 {
+		return json.RawMessage(data), nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	var out any
+	if err = decoder.Decode(&out); err != nil {
+		return nil, err
+	}
+	for _, ref := range cfg.SecretReferences {
+		if ref.JSONPath != nil {
+			restoreSecretReferenceInJSON(out, ref.JSONPath, ref)
+		}
+	}
+	return out, nil
+}
+
+func restoreSecretReferenceInJSON(value any, path []string, ref SecretReference) {
+	if len(path) == 0 {
+		return
+	}
+	segment, last := path[0], len(path) == 1
+	switch typed := value.(type) {
+	case map[string]any:
+		item, ok := typed[segment]
+		if !ok {
+			return
+		}
+		if last {
+			if text, isString := item.(string); isString && text == ref.Value {
+				typed[segment] = ref.Ref
+			}
+			return
+		}
+		restoreSecretReferenceInJSON(item, path[1:], ref)
+	case []any:
+		i, ok := segmentIndex(segment)
+		if !ok || i >= len(typed) {
+			return
+		}
+		if last {
+			if text, isString := typed[i].(string); isString && text == ref.Value {
+				typed[i] = ref.Ref
+			}
+			return
+		}
+		restoreSecretReferenceInJSON(typed[i], path[1:], ref)
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultStreamHedgingDelayMS is used when a stream-hedging rule leaves delay-ms unset.
+const DefaultStreamHedgingDelayMS = 1500
+
+// StreamHedgingRule opts matching streaming requests in to hedging. When no first
+// chunk arrives within the delay, a second attempt is started on a different
+// credential and whichever attempt streams first is kept.
+type StreamHedgingRule struct {
+	// Models are client-requested model names; '*' matches any characters.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+	// Routes are request paths such as "/v1/chat/completions"; '*' matches any characters.
+	Routes []string `yaml:"routes,omitempty" json:"routes,omitempty"`
+	// DelayMS is how long to wait for the first chunk before hedging.
+	DelayMS int `yaml:"delay-ms,omitempty" json:"delay-ms,omitempty"`
+}
+
+// matches reports whether the rule applies to model and route. Empty lists match anything.
+func (r StreamHedgingRule) matches(model, route string) bool {
+	return matchStreamHedgingPatterns(r.Models, strings.ToLower(strings.TrimSpace(model))) &&
+		matchStreamHedgingPatterns(r.Routes, strings.TrimSpace(route))
+}
+
+func matchStreamHedgingPatterns(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matchModelGlob(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// SanitizeStreamHedging trims patterns, lowercases model patterns and drops rules
+// that match neither a model nor a route.
+func (cfg *Config) SanitizeStreamHedging() {
+	if cfg == nil {
+		return
+	}
+	cfg.StreamHedging = NormalizeStreamHedgingRules(cfg.StreamHedging)
+}
+
+// NormalizeStreamHedgingRules returns the sanitized form of rules.
+func NormalizeStreamHedgingRules(rules []StreamHedgingRule) []StreamHedgingRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	out := make([]StreamHedgingRule, 0, len(rules))
+	for _, rule := range rules {
+		models := make([]string, 0, len(rule.Models))
+		for _, model := range rule.Models {
+			if model = strings.ToLower(strings.TrimSpace(model)); model != "" {
+				models = append(models, model)
+			}
+		}
+		routes := make([]string, 0, len(rule.Routes))
+		for _, route := range rule.Routes {
+			if route = strings.TrimSpace(route); route != "" {
+				routes = append(routes, route)
+			}
+		}
+		if len(models) == 0 && len(routes) == 0 {
+			continue
+		}
+		rule.Models, rule.Routes = nil, nil
+		if len(models) > 0 {
+			rule.Models = models
+		}
+		if len(routes) > 0 {
+			rule.Routes = routes
+		}
+		rule.DelayMS = max(rule.DelayMS, 0)
+		out = append(out, rule)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// StreamHedgingDelay returns the hedging delay of the first rule matching the
+// requested model and the request route. The boolean is false when no rule matches.
+func (cfg *Config) StreamHedgingDelay(model, route string) (time.Duration, bool) {
+	if cfg == nil {
+		return 0, false
+	}
+	for _, rule := range cfg.StreamHedging {
+		if !rule.matches(model, route) {
+			continue
+		}
+		delayMS := rule.DelayMS
+		if delayMS <= 0 {
+			delayMS = DefaultStreamHedgingDelayMS
+		}
+		return time.Duration(delayMS) * time.Millisecond, true
+	}
+	return 0, false
+}
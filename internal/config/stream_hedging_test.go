@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreamHedgingDelayMatchesModelAndRoute(t *testing.T) {
+	cfg := &Config{StreamHedging: []StreamHedgingRule{
+		{},
+		{Models: []string{" Claude-* "}, Routes: []string{"/v1/messages"}, DelayMS: 800},
+		{Routes: []string{"/v1/chat/completions"}},
+	}}
+	cfg.SanitizeStreamHedging()
+	if len(cfg.StreamHedging) != 2 {
+		t.Fatalf("rules = %+v, want the empty rule dropped", cfg.StreamHedging)
+	}
+
+	if delay, ok := cfg.StreamHedgingDelay("claude-sonnet", "/v1/messages"); !ok || delay != 800*time.Millisecond {
+		t.Fatalf("StreamHedgingDelay(claude, messages) = %s/%t, want 800ms/true", delay, ok)
+	}
+	if _, ok := cfg.StreamHedgingDelay("claude-sonnet", "/v1/responses"); ok {
+		t.Fatal("rule matched a route outside its routes")
+	}
+	want := time.Duration(DefaultStreamHedgingDelayMS) * time.Millisecond
+	if delay, ok := cfg.StreamHedgingDelay("gpt-5", "/v1/chat/completions"); !ok || delay != want {
+		t.Fatalf("StreamHedgingDelay(gpt-5, chat) = %s/%t, want %s/true", delay, ok, want)
+	}
+}
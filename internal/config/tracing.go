@@ -0,0 +1,56 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	// DefaultTracingEndpoint is the OTLP/HTTP traces endpoint of a local collector.
+	DefaultTracingEndpoint = "http://localhost:4318/v1/traces"
+	// DefaultTracingServiceName is the service.name reported on exported spans.
+	DefaultTracingServiceName = "cli-proxy-api"
+)
+
+// EffectiveEndpoint returns the OTLP/HTTP traces endpoint, adding the standard
+// "/v1/traces" path when the configured value has none.
+func (c TracingConfig) EffectiveEndpoint() string {
+	endpoint := strings.TrimSpace(c.Endpoint)
+	if endpoint == "" {
+		return DefaultTracingEndpoint
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	parsed, errParse := url.Parse(endpoint)
+	if errParse != nil || parsed.Host == "" {
+		return DefaultTracingEndpoint
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		parsed.Path = "/v1/traces"
+	}
+	return parsed.String()
+}
+
+// EffectiveServiceName returns the service.name reported on exported spans.
+func (c TracingConfig) EffectiveServiceName() string {
+	if name := strings.TrimSpace(c.ServiceName); name != "" {
+		return name
+	}
+	return DefaultTracingServiceName
+}
+
+// EffectiveSampleRatio returns the sampling ratio for new traces, clamped to [0, 1].
+func (c TracingConfig) EffectiveSampleRatio() float64 {
+	if c.SampleRatio == nil {
+		return 1
+	}
+	ratio := *c.SampleRatio
+	if ratio < 0 {
+		return 0
+	}
+	if ratio > 1 {
+		return 1
+	}
+	return ratio
+}
@@ -0,0 +1,51 @@
+package config
+
+import "testing"
+
+func TestTracingConfigEffectiveEndpoint(t *testing.T) {
+	cases := map[string]string{
+		"":                                     DefaultTracingEndpoint,
+		"collector:4318":                       "http://collector:4318/v1/traces",
+		"https://otel.example.com/":            "https://otel.example.com/v1/traces",
+		"https://otel.example.com/otlp/traces": "https://otel.example.com/otlp/traces",
+	}
+	for endpoint, want := range cases {
+		if got := (TracingConfig{Endpoint: endpoint}).EffectiveEndpoint(); got != want {
+			t.Fatalf("EffectiveEndpoint(%q) = %q, want %q", endpoint, got, want)
+		}
+	}
+}
+
+func TestTracingConfigEffectiveSampleRatio(t *testing.T) {
+	ratio := func(v float64) *float64 { return &v }
+	cases := []struct {
+		in   *float64
+		want float64
+	}{
+		{nil, 1},
+		{ratio(0.25), 0.25},
+		{ratio(-1), 0},
+		{ratio(2), 1},
+	}
+	for _, tc := range cases {
+		if got := (TracingConfig{SampleRatio: tc.in}).EffectiveSampleRatio(); got != tc.want {
+			t.Fatalf("EffectiveSampleRatio(%v) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseConfigBytesTracing(t *testing.T) {
+	cfg, errParse := ParseConfigBytes([]byte(`tracing:
+  enable: true
+  endpoint: collector:4318
+  sample-ratio: 0.1
+  headers:
+    x-honeycomb-team: key
+`))
+	if errParse != nil {
+		t.Fatalf("ParseConfigBytes() error = %v", errParse)
+	}
+	if !cfg.Tracing.Enable || cfg.Tracing.EffectiveSampleRatio() != 0.1 || cfg.Tracing.Headers["x-honeycomb-team"] != "key" {
+		t.Fatalf("tracing = %+v", cfg.Tracing)
+	}
+}
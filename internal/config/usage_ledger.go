@@ -0,0 +1,26 @@
+package config
+
+import "strings"
+
+const (
+	// UsageLedgerBackendFile stores ledger records as daily JSONL segments on local disk.
+	UsageLedgerBackendFile = "file"
+	// UsageLedgerBackendPostgres stores ledger records in the Postgres token store.
+	UsageLedgerBackendPostgres = "postgres"
+)
+
+// NormalizeUsageLedgerBackend returns a supported ledger backend name.
+// Empty and unknown values fall back to UsageLedgerBackendFile.
+func NormalizeUsageLedgerBackend(backend string) string {
+	switch strings.ToLower(strings.TrimSpace(backend)) {
+	case UsageLedgerBackendPostgres, "postgresql", "pg":
+		return UsageLedgerBackendPostgres
+	default:
+		return UsageLedgerBackendFile
+	}
+}
+
+// EffectiveBackend returns the normalized backend for the usage ledger.
+func (c UsageLedgerConfig) EffectiveBackend() string {
+	return NormalizeUsageLedgerBackend(c.Backend)
+}
@@ -0,0 +1,259 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationReport collects the problems found while validating a config offline.
+// Errors make the config unfit to deploy; warnings describe settings that load but
+// probably do not do what the author intended.
+type ValidationReport struct {
+	Errors   []string
+	Warnings []string
+}
+
+// AddError records an error.
+func (r *ValidationReport) AddError(format string, args ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+// AddWarning records a warning.
+func (r *ValidationReport) AddWarning(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// OK reports whether no errors were recorded.
+func (r *ValidationReport) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// legacyConfigKeys lists keys the loader still tolerates and drops on the next save,
+// keyed by the Go type they appear in.
+var legacyConfigKeys = map[string]map[string]struct{}{
+	"config.Config": {
+		"auth":                                 {},
+		"generative-language-api-key":          {},
+		"ampcode":                              {},
+		"amp-upstream-url":                     {},
+		"amp-upstream-api-key":                 {},
+		"amp-restrict-management-to-localhost": {},
+		"amp-model-mappings":                   {},
+	},
+	"config.OpenAICompatibility": {
+		"api-keys": {},
+	},
+}
+
+var unknownFieldPattern = regexp.MustCompile(`^(line \d+): field (\S+) not found in type (\S+)$`)
+
+// ValidateConfigBytes parses data like ParseConfigBytes and additionally reports what
+// the loader silently tolerates: unknown keys, entries dropped by normalization and
+// values that fall back to defaults. Secret references are checked for syntax only
+// and never resolved, so validation needs none of the secrets and runs no commands;
+// the returned config holds the reference text in their place. When data cannot be
+// loaded at all, the returned config is nil and the report carries the load error.
+func ValidateConfigBytes(data []byte) (*Config, ValidationReport) {
+	var report ValidationReport
+	cfg, errParse := ParseConfigBytes(data)
+	if errParse == nil {
+		errParse = cfg.Codex.LiveMediaRelay.Validate()
+	}
+	if errParse != nil {
+		report.AddError("%v", errParse)
+		return nil, report
+	}
+
+	validateConfigKeys(data, &report)
+	validateSecretReferences(data, &report)
+
+	var raw Config
+	if errRaw := yaml.Unmarshal(data, &raw); errRaw == nil {
+		validateDroppedEntries(&raw, cfg, &report)
+	}
+
+	if cfg.Port <= 0 {
+		report.AddWarning("port is not set; the server cannot start without one")
+	}
+	switch strings.ToLower(strings.TrimSpace(cfg.Routing.Strategy)) {
+	case "", "round-robin", "roundrobin", "rr",
+		"weighted-round-robin", "weightedroundrobin", "wrr",
+		"fill-first", "fillfirst", "ff":
+	default:
+		report.AddError("routing.strategy %q is not supported; round-robin would be used", cfg.Routing.Strategy)
+	}
+	if ttl := strings.TrimSpace(cfg.Routing.SessionAffinityTTL); ttl != "" {
+		if parsed, errTTL := time.ParseDuration(ttl); errTTL != nil || parsed <= 0 {
+			report.AddWarning("routing.session-affinity-ttl %q is not a positive duration; 1h would be used", ttl)
+		}
+	}
+	validateClientAPIKeys(cfg, &report)
+	validateDiscoverModels(cfg, &report)
+	validateModelCatalog(cfg, &report)
+	validateContextGuard(cfg, &report)
+	return cfg, report
+}
+
+// validateSecretReferences checks the syntax of ${scheme:value} references without
+// resolving them. A reference naming nothing fails to load; a malformed reference or
+// an unregistered scheme is kept as literal text, which is rarely what was meant.
+func validateSecretReferences(data []byte, report *ValidationReport) {
+	var root yaml.Node
+	if errParse := yaml.Unmarshal(data, &root); errParse != nil {
+		return
+	}
+	walkYAMLScalars(&root, nil, func(path []string, node *yaml.Node) {
+		location := fmt.Sprintf("line %d: %s", node.Line, secretPath{yaml: path})
+		rest := node.Value
+		for {
+			start := strings.Index(rest, "${")
+			if start < 0 {
+				return
+			}
+			rest = rest[start:]
+			loc := secretRefPattern.FindStringIndex(rest)
+			if loc == nil || loc[0] != 0 {
+				report.AddWarning("%s: %q is not a ${scheme:value} secret reference and is used literally", location, secretReferencePrefix(rest))
+				rest = rest[2:]
+				continue
+			}
+			match := secretRefPattern.FindStringSubmatch(rest[:loc[1]])
+			switch {
+			case strings.TrimSpace(match[2]) == "":
+				report.AddError("%s: secret reference %q names nothing", location, match[0])
+			case secretResolverFor(match[1]) == nil:
+				report.AddWarning("%s: secret reference %q has no registered resolver and is used literally", location, match[0])
+			}
+			rest = rest[loc[1]:]
+		}
+	})
+}
+
+// secretReferencePrefix shortens a malformed reference for display.
+func secretReferencePrefix(value string) string {
+	if end := strings.IndexByte(value, '}'); end >= 0 {
+		return value[:end+1]
+	}
+	if len(value) > 32 {
+		return value[:32] + "..."
+	}
+	return value
+}
+
+// validateClientAPIKeys reports structured api-keys entries that refuse their key.
+func validateClientAPIKeys(cfg *Config, report *ValidationReport) {
+	for i, entry := range cfg.APIKeys {
+		if _, errExpiry := entry.ExpiryTime(); errExpiry != nil {
+			report.AddError("api-keys[%d]: %v; the key is refused", i, errExpiry)
+		}
+	}
+}
+
+// validateModelCatalog reports model-catalog entries that block catalog refreshes.
+func validateModelCatalog(cfg *Config, report *ValidationReport) {
+	if _, errSettings := cfg.ModelCatalog.Settings(); errSettings != nil {
+		for _, line := range strings.Split(errSettings.Error(), "\n") {
+			report.AddError("model-catalog.%s; catalog refreshes fail until it is fixed", line)
+		}
+		return
+	}
+	if len(cfg.ModelCatalog.PublicKeys) > 0 && len(cfg.ModelCatalog.Sources) == 0 {
+		report.AddWarning("model-catalog.public-keys is set without sources; the default upstream catalog is unsigned and will be rejected")
+	}
+}
+
+// validateContextGuard reports context-guard settings the guard would ignore.
+func validateContextGuard(cfg *Config, report *ValidationReport) {
+	guard := cfg.ContextGuard
+	if strings.TrimSpace(guard.Strategy) != "" && NormalizeContextGuardStrategy(guard.Strategy) == "" {
+		report.AddError("context-guard.strategy %q is not one of reject, drop-oldest or summarize; requests over the limit are rejected", guard.Strategy)
+	}
+	if guard.EffectiveStrategy() == ContextGuardStrategySummarize && strings.TrimSpace(guard.SummaryModel) == "" {
+		report.AddWarning("context-guard.strategy is summarize without summary-model; older turns are dropped instead")
+	}
+	if guard.ReserveTokens < 0 {
+		report.AddWarning("context-guard.reserve-tokens is negative; it is treated as 0")
+	}
+}
+
+// validateDiscoverModels warns about discover-models blocks that can never run.
+func validateDiscoverModels(cfg *Config, report *ValidationReport) {
+	for i := range cfg.ClaudeKey {
+		if cfg.ClaudeKey[i].DiscoverModels.Enabled() && strings.TrimSpace(cfg.ClaudeKey[i].BaseURL) == "" {
+			report.AddWarning("claude-api-key[%d].discover-models requires base-url; discovery is skipped", i)
+		}
+	}
+	for _, section := range []struct {
+		name    string
+		entries []CodexKey
+	}{
+		{"codex-api-key", cfg.CodexKey},
+		{"xai-api-key", cfg.XAIKey},
+	} {
+		for i := range section.entries {
+			if section.entries[i].DiscoverModels.Enabled() && strings.TrimSpace(section.entries[i].BaseURL) == "" {
+				report.AddWarning("%s[%d].discover-models requires base-url; discovery is skipped", section.name, i)
+			}
+		}
+	}
+}
+
+// validateConfigKeys decodes data strictly to find keys that no config field consumes.
+func validateConfigKeys(data []byte, report *ValidationReport) {
+	var strict Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	errDecode := decoder.Decode(&strict)
+	var typeErr *yaml.TypeError
+	if !errors.As(errDecode, &typeErr) {
+		return
+	}
+	for _, message := range typeErr.Errors {
+		match := unknownFieldPattern.FindStringSubmatch(message)
+		if match == nil {
+			continue
+		}
+		line, key, owner := match[1], match[2], match[3]
+		if _, legacy := legacyConfigKeys[owner][key]; legacy {
+			report.AddWarning("%s: legacy key %q is ignored and removed on the next save", line, key)
+			continue
+		}
+		report.AddError("%s: unknown key %q", line, key)
+	}
+}
+
+// validateDroppedEntries compares list sections before and after normalization.
+func validateDroppedEntries(raw, sanitized *Config, report *ValidationReport) {
+	sections := []struct {
+		name  string
+		count func(*Config) int
+	}{
+		{"gemini-api-key", func(c *Config) int { return len(c.GeminiKey) }},
+		{"interactions-api-key", func(c *Config) int { return len(c.InteractionsKey) }},
+		{"vertex-api-key", func(c *Config) int { return len(c.VertexCompatAPIKey) }},
+		{"bedrock-api-key", func(c *Config) int { return len(c.BedrockKey) }},
+		{"azure-openai", func(c *Config) int { return len(c.AzureOpenAIKey) }},
+		{"codex-api-key", func(c *Config) int { return len(c.CodexKey) }},
+		{"xai-api-key", func(c *Config) int { return len(c.XAIKey) }},
+		{"claude-api-key", func(c *Config) int { return len(c.ClaudeKey) }},
+		{"openai-compatibility", func(c *Config) int { return len(c.OpenAICompatibility) }},
+		{"model-fallbacks", func(c *Config) int { return len(c.ModelFallbacks) }},
+		{"stream-hedging", func(c *Config) int { return len(c.StreamHedging) }},
+		{"model-pricing", func(c *Config) int { return len(c.ModelPricing) }},
+		{"payload.default-raw", func(c *Config) int { return len(c.Payload.DefaultRaw) }},
+		{"payload.override-raw", func(c *Config) int { return len(c.Payload.OverrideRaw) }},
+		{"api-key-limits", func(c *Config) int { return len(c.APIKeyLimits) }},
+	}
+	for _, section := range sections {
+		before, after := section.count(raw), section.count(sanitized)
+		if after < before {
+			report.AddError("%s: %d of %d entries dropped during normalization (missing required fields or duplicates)", section.name, before-after, before)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigBytesReportsToleratedProblems(t *testing.T) {
+	data := []byte(`port: 8317
+routing:
+  strategy: fill-frist
+gemini-api-key:
+  - api-key: g1
+  - api-key: g1
+openai-compatibility:
+  - name: missing-base-url
+generative-language-api-key: ["legacy"]
+typo-key: true
+`)
+	cfg, report := ValidateConfigBytes(data)
+	if cfg == nil {
+		t.Fatalf("ValidateConfigBytes() returned no config: %+v", report)
+	}
+	errors := strings.Join(report.Errors, "\n")
+	for _, want := range []string{
+		`line 10: unknown key "typo-key"`,
+		"gemini-api-key: 1 of 2 entries dropped",
+		"openai-compatibility: 1 of 1 entries dropped",
+		`routing.strategy "fill-frist" is not supported`,
+	} {
+		if !strings.Contains(errors, want) {
+			t.Errorf("errors missing %q:\n%s", want, errors)
+		}
+	}
+	if len(report.Errors) != 4 {
+		t.Errorf("errors = %v, want 4", report.Errors)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], `legacy key "generative-language-api-key"`) {
+		t.Errorf("warnings = %v", report.Warnings)
+	}
+	if report.OK() {
+		t.Error("OK() = true with errors")
+	}
+}
+
+func TestValidateConfigBytesAcceptsCleanConfig(t *testing.T) {
+	cfg, report := ValidateConfigBytes([]byte("port: 8317\nrouting:\n  strategy: ff\n"))
+	if cfg == nil || !report.OK() || len(report.Warnings) != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	cfg, report = ValidateConfigBytes([]byte("port: [\n"))
+	if cfg != nil || report.OK() {
+		t.Fatalf("malformed YAML: cfg = %v, report = %+v", cfg, report)
+	}
+}
+
+func TestValidateConfigBytesChecksSecretReferencesWithoutResolving(t *testing.T) {
+	data := []byte(`port: 8317
+proxy-url: ${env:CPA_TEST_VALIDATE_UNSET}
+claude-api-key:
+  - api-key: ${cmd:false}
+  - api-key: ${env:}
+  - api-key: ${vault:kv/claude}
+  - api-key: ${env:CPA_TEST_VALIDATE_UNSET
+`)
+	cfg, report := ValidateConfigBytes(data)
+	if cfg == nil {
+		t.Fatalf("ValidateConfigBytes() returned no config: %+v", report)
+	}
+	if cfg.ProxyURL != "${env:CPA_TEST_VALIDATE_UNSET}" || cfg.ClaudeKey[0].APIKey != "${cmd:false}" {
+		t.Fatalf("references were resolved: proxy-url = %q, api-key = %q", cfg.ProxyURL, cfg.ClaudeKey[0].APIKey)
+	}
+	if len(report.Errors) != 1 || !strings.Contains(report.Errors[0], `line 5: claude-api-key[1].api-key: secret reference "${env:}" names nothing`) {
+		t.Errorf("errors = %v", report.Errors)
+	}
+	warnings := strings.Join(report.Warnings, "\n")
+	for _, want := range []string{`"${vault:kv/claude}" has no registered resolver`, `line 7: claude-api-key[3].api-key: "${env:CPA_TEST_VALIDATE_UNSET" is not a ${scheme:value} secret reference`} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("warnings missing %q:\n%s", want, warnings)
+		}
+	}
+}
@@ -75,14 +75,22 @@ type VertexCompatModel struct {
 	// ForceMapping rewrites upstream response model fields back to Alias.
 	ForceMapping bool `yaml:"force-mapping,omitempty" json:"force-mapping,omitempty"`
 
+	// InputModalities declares supported input modalities (e.g. text, image, audio).
+	InputModalities []string `yaml:"input-modalities,omitempty" json:"input-modalities,omitempty"`
+
+	// OutputModalities declares supported output modalities (e.g. text, audio).
+	OutputModalities []string `yaml:"output-modalities,omitempty" json:"output-modalities,omitempty"`
+
 	// Thinking configures the thinking/reasoning capability for this model.
 	Thinking *registry.ThinkingSupport `yaml:"thinking,omitempty" json:"thinking,omitempty"`
 }
 
-func (m VertexCompatModel) GetName() string        { return m.Name }
-func (m VertexCompatModel) GetAlias() string       { return m.Alias }
-func (m VertexCompatModel) GetDisplayName() string { return m.DisplayName }
-func (m VertexCompatModel) GetForceMapping() bool  { return m.ForceMapping }
+func (m VertexCompatModel) GetName() string               { return m.Name }
+func (m VertexCompatModel) GetAlias() string              { return m.Alias }
+func (m VertexCompatModel) GetDisplayName() string        { return m.DisplayName }
+func (m VertexCompatModel) GetForceMapping() bool         { return m.ForceMapping }
+func (m VertexCompatModel) GetInputModalities() []string  { return m.InputModalities }
+func (m VertexCompatModel) GetOutputModalities() []string { return m.OutputModalities }
 func (m VertexCompatModel) GetThinking() *registry.ThinkingSupport {
 	return m.Thinking
 }
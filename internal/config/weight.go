@@ -30,7 +30,8 @@ func validateCredentialWeightYAML(data []byte) error {
 	root := document.Content[0]
 	families := map[string]struct{}{
 		"gemini-api-key": {}, "interactions-api-key": {}, "claude-api-key": {},
-		"vertex-api-key": {}, "codex-api-key": {}, "xai-api-key": {},
+		"vertex-api-key": {}, "codex-api-key": {}, "xai-api-key": {}, "bedrock-api-key": {},
+		"azure-openai": {},
 	}
 	for index := 0; root != nil && root.Kind == yaml.MappingNode && index+1 < len(root.Content); index += 2 {
 		name := root.Content[index].Value
@@ -131,6 +132,16 @@ func (cfg *Config) ValidateCredentialWeights() error {
 			return fmt.Errorf("vertex-api-key[%d].weight: %w", index, errValidate)
 		}
 	}
+	for index := range cfg.BedrockKey {
+		if errValidate := ValidateCredentialWeight(cfg.BedrockKey[index].Weight); errValidate != nil {
+			return fmt.Errorf("bedrock-api-key[%d].weight: %w", index, errValidate)
+		}
+	}
+	for index := range cfg.AzureOpenAIKey {
+		if errValidate := ValidateCredentialWeight(cfg.AzureOpenAIKey[index].Weight); errValidate != nil {
+			return fmt.Errorf("azure-openai[%d].weight: %w", index, errValidate)
+		}
+	}
 	for index := range cfg.CodexKey {
 		if errValidate := ValidateCredentialWeight(cfg.CodexKey[index].Weight); errValidate != nil {
 			return fmt.Errorf("codex-api-key[%d].weight: %w", index, errValidate)
@@ -22,9 +22,18 @@ const (
 	// OpenaiResponse represents the OpenAI response format identifier.
 	OpenaiResponse = "openai-response"
 
+	// OpenAIAudio represents the OpenAI audio endpoints request envelope format identifier.
+	OpenAIAudio = "openai-audio"
+
 	// Antigravity represents the Antigravity response format identifier.
 	Antigravity = "antigravity"
 
 	// Interactions represents the Google Interactions API format identifier.
 	Interactions = "interactions"
+
+	// Ollama represents the Ollama native API format identifier.
+	Ollama = "ollama"
+
+	// BedrockConverse represents the AWS Bedrock Converse API format identifier.
+	BedrockConverse = "bedrock-converse"
 )
@@ -0,0 +1,288 @@
+package contextguard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	translatorcommon "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/common"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	// summaryPreamble introduces the summary inserted in place of the dropped turns.
+	summaryPreamble = "Summary of the earlier part of this conversation, which was shortened to fit the model's context window:\n\n"
+	// maxTranscriptBytes bounds the transcript sent to the summary model. Older
+	// content is cut first.
+	maxTranscriptBytes = 256 << 10
+)
+
+// conversation is a request split into pinned items (system and developer messages,
+// which are always kept) and turns. A turn starts at a user message that is not just
+// a tool result and runs until the next such message, so an assistant tool call and
+// the results answering it always share a turn.
+type conversation struct {
+	format  sdktranslator.Format
+	payload []byte
+	// path is the JSON path of the item array: "messages", "contents" or "input".
+	path  string
+	items []gjson.Result
+	// pinned marks items kept regardless of the turns dropped.
+	pinned []bool
+	// turn maps each unpinned item to its turn index.
+	turn  []int
+	turns int
+}
+
+func splitConversation(format sdktranslator.Format, payload []byte) (*conversation, error) {
+	conv := &conversation{format: format, payload: payload}
+	switch format {
+	case sdktranslator.FormatOpenAI, sdktranslator.FormatClaude:
+		conv.path = "messages"
+	case sdktranslator.FormatGemini:
+		conv.path = "contents"
+	case sdktranslator.FormatOpenAIResponse:
+		conv.path = "input"
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+	list := gjson.GetBytes(payload, conv.path)
+	if !list.IsArray() {
+		return nil, fmt.Errorf("%s is not an array", conv.path)
+	}
+	conv.items = list.Array()
+	conv.pinned = make([]bool, len(conv.items))
+	conv.turn = make([]int, len(conv.items))
+	current := -1
+	for i, item := range conv.items {
+		if conv.isPinned(item) {
+			conv.pinned[i] = true
+			continue
+		}
+		// Items ahead of the first user turn belong to it.
+		if current < 0 || conv.startsTurn(item) {
+			current++
+		}
+		conv.turn[i] = current
+	}
+	conv.turns = current + 1
+	return conv, nil
+}
+
+func (c *conversation) isPinned(item gjson.Result) bool {
+	switch c.format {
+	case sdktranslator.FormatOpenAI, sdktranslator.FormatOpenAIResponse:
+		role := item.Get("role").String()
+		itemType := item.Get("type").String()
+		return (itemType == "" || itemType == "message") && (role == "system" || role == "developer")
+	default:
+		return false
+	}
+}
+
+func (c *conversation) startsTurn(item gjson.Result) bool {
+	switch c.format {
+	case sdktranslator.FormatOpenAI:
+		return item.Get("role").String() == "user"
+	case sdktranslator.FormatOpenAIResponse:
+		itemType := item.Get("type").String()
+		return (itemType == "" || itemType == "message") && item.Get("role").String() == "user"
+	case sdktranslator.FormatClaude:
+		if item.Get("role").String() != "user" {
+			return false
+		}
+		content := item.Get("content")
+		if !content.IsArray() {
+			return true
+		}
+		for _, block := range content.Array() {
+			if block.Get("type").String() != "tool_result" {
+				return true
+			}
+		}
+		return false
+	case sdktranslator.FormatGemini:
+		if role := item.Get("role").String(); role != "" && role != "user" {
+			return false
+		}
+		for _, part := range item.Get("parts").Array() {
+			if part.Get("functionResponse").Exists() || part.Get("function_response").Exists() {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// droppableTurns returns how many turns may be dropped. The latest turn is always kept.
+func (c *conversation) droppableTurns() int {
+	return max(c.turns-1, 0)
+}
+
+// keptItems returns the raw items left after dropping the first n turns.
+func (c *conversation) keptItems(n int) [][]byte {
+	kept := make([][]byte, 0, len(c.items))
+	for i, item := range c.items {
+		if c.pinned[i] || c.turn[i] >= n {
+			kept = append(kept, []byte(item.Raw))
+		}
+	}
+	return kept
+}
+
+// withoutTurns returns the payload without its first n turns.
+func (c *conversation) withoutTurns(n int) ([]byte, error) {
+	return sjson.SetRawBytes(c.payload, c.path, translatorcommon.JoinRawArray(c.keptItems(n)))
+}
+
+// withSummary returns the payload with its first n turns replaced by summary. Claude
+// and Gemini carry the summary in the system instruction; OpenAI formats insert it as
+// a system (Chat Completions) or developer (Responses) message ahead of the kept turns.
+func (c *conversation) withSummary(n int, summary string) ([]byte, error) {
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return nil, fmt.Errorf("empty summary")
+	}
+	text := summaryPreamble + summary
+	switch c.format {
+	case sdktranslator.FormatClaude:
+		payload, errTrim := c.withoutTurns(n)
+		if errTrim != nil {
+			return nil, errTrim
+		}
+		system := gjson.GetBytes(payload, "system")
+		switch {
+		case system.IsArray():
+			block, _ := sjson.SetBytes([]byte(`{"type":"text"}`), "text", text)
+			return sjson.SetRawBytes(payload, "system.-1", block)
+		case system.Type == gjson.String && strings.TrimSpace(system.String()) != "":
+			return sjson.SetBytes(payload, "system", system.String()+"\n\n"+text)
+		default:
+			return sjson.SetBytes(payload, "system", text)
+		}
+	case sdktranslator.FormatGemini:
+		payload, errTrim := c.withoutTurns(n)
+		if errTrim != nil {
+			return nil, errTrim
+		}
+		path := "systemInstruction"
+		if !gjson.GetBytes(payload, path).Exists() && gjson.GetBytes(payload, "system_instruction").Exists() {
+			path = "system_instruction"
+		}
+		part, _ := sjson.SetBytes([]byte(`{}`), "text", text)
+		return sjson.SetRawBytes(payload, path+".parts.-1", part)
+	}
+
+	var message []byte
+	if c.format == sdktranslator.FormatOpenAIResponse {
+		message, _ = sjson.SetBytes([]byte(`{"type":"message","role":"developer","content":[{"type":"input_text"}]}`), "content.0.text", text)
+	} else {
+		message, _ = sjson.SetBytes([]byte(`{"role":"system"}`), "content", text)
+	}
+	kept := make([][]byte, 0, len(c.items)+1)
+	inserted := false
+	for i, item := range c.items {
+		if !c.pinned[i] && c.turn[i] < n {
+			continue
+		}
+		if !inserted && !c.pinned[i] {
+			kept = append(kept, message)
+			inserted = true
+		}
+		kept = append(kept, []byte(item.Raw))
+	}
+	return sjson.SetRawBytes(c.payload, c.path, translatorcommon.JoinRawArray(kept))
+}
+
+// transcript renders the first n turns as plain text for the summary model.
+func (c *conversation) transcript(n int) string {
+	var out strings.Builder
+	for i, item := range c.items {
+		if c.pinned[i] || c.turn[i] >= n {
+			continue
+		}
+		var segments []string
+		collectTranscriptText(item, &segments)
+		if len(segments) == 0 {
+			continue
+		}
+		role := item.Get("role").String()
+		if role == "" {
+			role = item.Get("type").String()
+		}
+		if role == "" {
+			role = "message"
+		}
+		out.WriteString(role)
+		out.WriteString(": ")
+		out.WriteString(strings.Join(segments, "\n"))
+		out.WriteString("\n\n")
+	}
+	text := strings.TrimSpace(out.String())
+	if len(text) > maxTranscriptBytes {
+		cut := len(text) - maxTranscriptBytes
+		for cut < len(text) && !utf8.RuneStart(text[cut]) {
+			cut++
+		}
+		text = "[earlier content omitted]\n" + text[cut:]
+	}
+	return text
+}
+
+// collectTranscriptText gathers the readable content of one conversation item across
+// formats: text, tool names, tool call arguments and tool results. Binary parts,
+// signatures and encrypted reasoning are skipped.
+func collectTranscriptText(value gjson.Result, segments *[]string) {
+	if value.IsArray() {
+		for _, element := range value.Array() {
+			collectTranscriptText(element, segments)
+		}
+		return
+	}
+	if value.Type == gjson.String {
+		appendTranscriptText(segments, value.String())
+		return
+	}
+	if !value.IsObject() {
+		return
+	}
+	value.ForEach(func(key, field gjson.Result) bool {
+		switch key.String() {
+		case "text", "name", "arguments", "refusal":
+			if field.Type == gjson.String {
+				appendTranscriptText(segments, field.String())
+			}
+		case "input", "args", "response":
+			appendTranscriptJSON(segments, field)
+		case "content", "parts", "output", "tool_calls", "function", "functionCall", "function_call",
+			"functionResponse", "function_response":
+			collectTranscriptText(field, segments)
+		}
+		return true
+	})
+}
+
+func appendTranscriptText(segments *[]string, value string) {
+	if trimmed := strings.TrimSpace(value); trimmed != "" {
+		*segments = append(*segments, trimmed)
+	}
+}
+
+func appendTranscriptJSON(segments *[]string, value gjson.Result) {
+	if value.Type == gjson.String {
+		appendTranscriptText(segments, value.String())
+		return
+	}
+	var compact bytes.Buffer
+	if errCompact := json.Compact(&compact, []byte(value.Raw)); errCompact == nil {
+		appendTranscriptText(segments, compact.String())
+		return
+	}
+	appendTranscriptText(segments, value.Raw)
+}
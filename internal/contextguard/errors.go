@@ -0,0 +1,55 @@
+package contextguard
+
+import (
+	"fmt"
+	"net/http"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	"github.com/tidwall/sjson"
+)
+
+// OverflowError reports a request whose estimated input exceeds the context window of
+// its model and could not be shortened.
+type OverflowError struct {
+	Format    sdktranslator.Format
+	Model     string
+	Estimated int64
+	Limit     int64
+}
+
+// Error returns the client-facing message.
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("This model's maximum context length is %d tokens, but the request is estimated at %d tokens. Reduce the length of the messages or the maximum output tokens.", e.Limit, e.Estimated)
+}
+
+// StatusCode returns the HTTP status of the error.
+func (e *OverflowError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// IsRequestScoped marks the error as caused by the request rather than the credential.
+func (e *OverflowError) IsRequestScoped() bool {
+	return true
+}
+
+// Body returns the error response body in the protocol of the request, matching the
+// context length errors the upstream APIs return.
+func (e *OverflowError) Body() []byte {
+	message := e.Error()
+	var body []byte
+	switch e.Format {
+	case sdktranslator.FormatClaude:
+		body = []byte(`{"type":"error","error":{"type":"invalid_request_error"}}`)
+		body, _ = sjson.SetBytes(body, "error.message", fmt.Sprintf("prompt is too long: %d tokens > %d maximum", e.Estimated, e.Limit))
+	case sdktranslator.FormatGemini:
+		body = []byte(`{"error":{"code":400,"status":"INVALID_ARGUMENT"}}`)
+		body, _ = sjson.SetBytes(body, "error.message", fmt.Sprintf("The input token count (%d) exceeds the maximum number of tokens allowed (%d).", e.Estimated, e.Limit))
+	case sdktranslator.FormatOpenAIResponse:
+		body = []byte(`{"error":{"type":"invalid_request_error","param":"input","code":"context_length_exceeded"}}`)
+		body, _ = sjson.SetBytes(body, "error.message", message)
+	default:
+		body = []byte(`{"error":{"type":"invalid_request_error","param":"messages","code":"context_length_exceeded"}}`)
+		body, _ = sjson.SetBytes(body, "error.message", message)
+	}
+	return body
+}
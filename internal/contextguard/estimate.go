@@ -0,0 +1,109 @@
+package contextguard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/runtime/executor/helps"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+// Supported reports whether the guard understands requests in format.
+func Supported(format sdktranslator.Format) bool {
+	switch format {
+	case sdktranslator.FormatOpenAI, sdktranslator.FormatOpenAIResponse, sdktranslator.FormatClaude, sdktranslator.FormatGemini:
+		return true
+	default:
+		return false
+	}
+}
+
+// EstimateInputTokens estimates the input tokens of a request with the local
+// tokenizers the executors use for count-tokens requests: OpenAI Chat Completions
+// bodies are counted with the model's tiktoken encoding, every other format is
+// translated to a Claude Messages body and counted like a Claude request.
+func EstimateInputTokens(format sdktranslator.Format, model string, payload []byte) (int64, error) {
+	if !gjson.ValidBytes(payload) {
+		return 0, fmt.Errorf("invalid %s request JSON", format)
+	}
+	baseModel := thinking.ParseSuffix(model).ModelName
+	switch format {
+	case sdktranslator.FormatOpenAI:
+		enc, errTokenizer := helps.TokenizerForModel(baseModel)
+		if errTokenizer != nil {
+			return 0, fmt.Errorf("tokenizer init failed: %w", errTokenizer)
+		}
+		return helps.CountOpenAIChatTokens(enc, payload)
+	case sdktranslator.FormatClaude:
+		return helps.CountClaudeInputTokens(payload)
+	}
+	if !sdktranslator.HasRequestTransformer(format, sdktranslator.FormatClaude) {
+		return 0, fmt.Errorf("no token estimator for %s requests", format)
+	}
+	// Translate as a stream request so function calls are preserved, as the Claude
+	// executor does when counting tokens.
+	translated := sdktranslator.TranslateRequest(format, sdktranslator.FormatClaude, baseModel, payload, true)
+	return helps.CountClaudeInputTokens(translated)
+}
+
+// ContextWindow returns the smallest known context window of model across providers:
+// the configured max-context-length, else the catalog context length, else the input
+// token limit. It returns 0 when no provider knows the window.
+func ContextWindow(model string, providers []string) int64 {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return 0
+	}
+	candidates := []string{model}
+	if base := thinking.ParseSuffix(model).ModelName; base != model {
+		candidates = append(candidates, base)
+	}
+	if len(providers) == 0 {
+		providers = []string{""}
+	}
+	var window int64
+	for _, provider := range providers {
+		for _, candidate := range candidates {
+			info := registry.LookupModelInfo(candidate, provider)
+			if info == nil {
+				continue
+			}
+			limit := int64(info.MaxContextLength)
+			if limit <= 0 {
+				limit = int64(info.ContextLength)
+			}
+			if limit <= 0 {
+				limit = int64(info.InputTokenLimit)
+			}
+			if limit > 0 && (window == 0 || limit < window) {
+				window = limit
+			}
+			break
+		}
+	}
+	return window
+}
+
+// RequestedOutputTokens returns the maximum output tokens a request asks for, or 0.
+func RequestedOutputTokens(format sdktranslator.Format, payload []byte) int64 {
+	var paths []string
+	switch format {
+	case sdktranslator.FormatOpenAI:
+		paths = []string{"max_completion_tokens", "max_tokens"}
+	case sdktranslator.FormatOpenAIResponse:
+		paths = []string{"max_output_tokens"}
+	case sdktranslator.FormatClaude:
+		paths = []string{"max_tokens"}
+	case sdktranslator.FormatGemini:
+		paths = []string{"generationConfig.maxOutputTokens", "generation_config.max_output_tokens"}
+	}
+	for _, path := range paths {
+		if value := gjson.GetBytes(payload, path).Int(); value > 0 {
+			return value
+		}
+	}
+	return 0
+}
@@ -0,0 +1,212 @@
+// Package contextguard keeps requests inside the context window of their model.
+//
+// Before a request is sent upstream its input tokens are estimated locally and
+// compared with the context window of the target model minus the tokens reserved for
+// the response. Requests over the limit are rejected with a protocol-native error,
+// trimmed by dropping their oldest conversation turns, or have those turns replaced by
+// a summary written by a cheaper model. OpenAI Chat Completions, OpenAI Responses,
+// Claude Messages and Gemini generateContent requests are supported.
+package contextguard
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	log "github.com/sirupsen/logrus"
+)
+
+// Header reports on the client response that the guard rewrote the request.
+const Header = "X-CPA-Context-Guard"
+
+// Actions reported by Outcome.Action.
+const (
+	// ActionDropped reports that the oldest turns were dropped.
+	ActionDropped = "dropped"
+	// ActionSummarized reports that the oldest turns were replaced by a summary.
+	ActionSummarized = "summarized"
+)
+
+// Guard holds the context-guard settings.
+type Guard struct {
+	mu  sync.RWMutex
+	cfg config.ContextGuardConfig
+}
+
+// New constructs a disabled guard.
+func New() *Guard {
+	return &Guard{}
+}
+
+var defaultGuard = New()
+
+// Default returns the process-wide guard used by the API handlers.
+func Default() *Guard {
+	return defaultGuard
+}
+
+// Apply replaces the guard settings.
+func (g *Guard) Apply(cfg config.ContextGuardConfig) {
+	if g == nil {
+		return
+	}
+	cfg.Models = append([]string(nil), cfg.Models...)
+	g.mu.Lock()
+	g.cfg = cfg
+	g.mu.Unlock()
+}
+
+// Settings returns a snapshot of the guard settings.
+func (g *Guard) Settings() config.ContextGuardConfig {
+	if g == nil {
+		return config.ContextGuardConfig{}
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.cfg
+}
+
+// Request is one request to check.
+type Request struct {
+	// Format is the client protocol of Payload.
+	Format sdktranslator.Format
+	// Model is the resolved model the request is executed with.
+	Model string
+	// RequestedModel is the model named by the client, matched against the models list.
+	RequestedModel string
+	// Providers are the candidate providers of Model, used to find its context window.
+	Providers []string
+	// Payload is the client request body.
+	Payload []byte
+}
+
+// Summarizer writes a summary of an earlier conversation transcript in at most
+// maxTokens tokens.
+type Summarizer func(ctx context.Context, model, transcript string, maxTokens int) (string, error)
+
+// Outcome describes a checked request.
+type Outcome struct {
+	// Payload is the request body to send: the original body or the rewritten one.
+	Payload []byte
+	// Action is "", ActionDropped or ActionSummarized.
+	Action string
+	// Turns counts the conversation turns that were dropped or summarized.
+	Turns int
+	// Estimated is the estimated input token count of the original request.
+	Estimated int64
+	// Limit is the input token budget of the request.
+	Limit int64
+}
+
+// HeaderValue returns the Header value describing a rewritten request.
+func (o Outcome) HeaderValue() string {
+	if o.Action == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s; turns=%d", o.Action, o.Turns)
+}
+
+// Check estimates the input tokens of req and applies the configured strategy when
+// they exceed the model's budget. It returns an *OverflowError when the request must
+// be rejected. Requests the guard cannot measure (unknown context window, unsupported
+// format, unparsable body) pass through unchanged and are left to the upstream.
+func (g *Guard) Check(ctx context.Context, req Request, summarize Summarizer) (Outcome, error) {
+	outcome, errCheck := g.check(ctx, req, summarize)
+	if errCheck == nil && outcome.Action != "" {
+		log.Infof("context guard: %s %d turns of %s request for %s (estimated %d tokens, budget %d)",
+			outcome.Action, outcome.Turns, req.Format, req.Model, outcome.Estimated, outcome.Limit)
+	}
+	return outcome, errCheck
+}
+
+func (g *Guard) check(ctx context.Context, req Request, summarize Summarizer) (Outcome, error) {
+	outcome := Outcome{Payload: req.Payload}
+	cfg := g.Settings()
+	if !cfg.AppliesTo(req.RequestedModel, req.Model) || !Supported(req.Format) {
+		return outcome, nil
+	}
+	window := ContextWindow(req.Model, req.Providers)
+	if window <= 0 {
+		return outcome, nil
+	}
+	reserve := RequestedOutputTokens(req.Format, req.Payload)
+	if reserve <= 0 {
+		reserve = int64(max(cfg.ReserveTokens, 0))
+	}
+	outcome.Limit = window - reserve
+	if outcome.Limit <= 0 {
+		// The requested output alone fills the window; the upstream reports that better.
+		return outcome, nil
+	}
+	estimated, errEstimate := EstimateInputTokens(req.Format, req.Model, req.Payload)
+	if errEstimate != nil {
+		log.Debugf("context guard: skip %s request for %s: %v", req.Format, req.Model, errEstimate)
+		return outcome, nil
+	}
+	outcome.Estimated = estimated
+	if estimated <= outcome.Limit {
+		return outcome, nil
+	}
+	overflow := &OverflowError{Format: req.Format, Model: req.Model, Estimated: estimated, Limit: outcome.Limit}
+
+	strategy := cfg.EffectiveStrategy()
+	if strategy == config.ContextGuardStrategyReject {
+		return outcome, overflow
+	}
+	conv, errSplit := splitConversation(req.Format, req.Payload)
+	if errSplit != nil || conv.droppableTurns() == 0 {
+		return outcome, overflow
+	}
+	summaryReserve := int64(0)
+	if strategy == config.ContextGuardStrategySummarize && cfg.SummaryModel != "" && summarize != nil {
+		summaryReserve = int64(cfg.EffectiveSummaryMaxTokens())
+	}
+
+	// Dropping turns never grows the request, so the fewest turns to drop is found by
+	// binary search over the estimate of each trimmed body.
+	droppable := conv.droppableTurns()
+	trimmed := make(map[int][]byte)
+	fits := func(turns int) bool {
+		payload, errTrim := conv.withoutTurns(turns)
+		if errTrim != nil {
+			return false
+		}
+		count, errCount := EstimateInputTokens(req.Format, req.Model, payload)
+		if errCount != nil || count+summaryReserve > outcome.Limit {
+			return false
+		}
+		trimmed[turns] = payload
+		return true
+	}
+	turns := sort.Search(droppable, func(i int) bool { return fits(i + 1) }) + 1
+	if turns > droppable {
+		return outcome, overflow
+	}
+	payload, ok := trimmed[turns]
+	if !ok {
+		return outcome, overflow
+	}
+	outcome.Payload, outcome.Action, outcome.Turns = payload, ActionDropped, turns
+
+	if summaryReserve > 0 {
+		summary, errSummary := summarize(ctx, cfg.SummaryModel, conv.transcript(turns), cfg.EffectiveSummaryMaxTokens())
+		if errSummary != nil {
+			log.Warnf("context guard: summarize %d turns with %s failed, dropping them instead: %v", turns, cfg.SummaryModel, errSummary)
+			return outcome, nil
+		}
+		summarized, errSplice := conv.withSummary(turns, summary)
+		if errSplice != nil {
+			log.Warnf("context guard: insert summary into %s request failed, dropping turns instead: %v", req.Format, errSplice)
+			return outcome, nil
+		}
+		if count, errCount := EstimateInputTokens(req.Format, req.Model, summarized); errCount != nil || count > outcome.Limit {
+			log.Warnf("context guard: summary of %d turns does not fit the %d token budget, dropping them instead", turns, outcome.Limit)
+			return outcome, nil
+		}
+		outcome.Payload, outcome.Action = summarized, ActionSummarized
+	}
+	return outcome, nil
+}
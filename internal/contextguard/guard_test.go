@@ -0,0 +1,379 @@
+package contextguard
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+var filler = strings.Repeat("The quick brown fox jumps over the lazy dog. ", 60)
+
+// registerWindow registers model with the given context window for the test.
+func registerWindow(t *testing.T, model string, window int) {
+	t.Helper()
+	clientID := "contextguard-test-" + model
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient(clientID, "openai", []*registry.ModelInfo{{ID: model, ContextLength: window}})
+	t.Cleanup(func() { reg.UnregisterClient(clientID) })
+}
+
+// guardFor returns a guard with cfg and registers model with a window that fits the
+// request only after its first turn is dropped, leaving extra tokens of headroom.
+func guardFor(t *testing.T, cfg config.ContextGuardConfig, format sdktranslator.Format, model string, payload []byte, extra int) *Guard {
+	t.Helper()
+	conv, err := splitConversation(format, payload)
+	if err != nil {
+		t.Fatalf("split conversation: %v", err)
+	}
+	trimmed, err := conv.withoutTurns(1)
+	if err != nil {
+		t.Fatalf("drop first turn: %v", err)
+	}
+	count, err := EstimateInputTokens(format, model, trimmed)
+	if err != nil {
+		t.Fatalf("estimate trimmed request: %v", err)
+	}
+	registerWindow(t, model, int(count)+extra)
+	guard := New()
+	cfg.Enable = true
+	guard.Apply(cfg)
+	return guard
+}
+
+func rawItems(t *testing.T, payload []byte, path string) []gjson.Result {
+	t.Helper()
+	list := gjson.GetBytes(payload, path)
+	if !list.IsArray() {
+		t.Fatalf("%s is not an array in %s", path, payload)
+	}
+	return list.Array()
+}
+
+func TestCheckDropOldestKeepsToolCallPairs(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  sdktranslator.Format
+		payload string
+		path    string
+		check   func(t *testing.T, items []gjson.Result)
+	}{
+		{
+			name:   "openai",
+			format: sdktranslator.FormatOpenAI,
+			payload: `{"model":"m","messages":[
+				{"role":"system","content":"be brief"},
+				{"role":"user","content":"FILLER"},
+				{"role":"assistant","content":"FILLER"},
+				{"role":"user","content":"weather?"},
+				{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"weather","arguments":"{}"}}]},
+				{"role":"tool","tool_call_id":"call_1","content":"sunny"},
+				{"role":"assistant","content":"It is sunny."},
+				{"role":"user","content":"thanks"}]}`,
+			path: "messages",
+			check: func(t *testing.T, items []gjson.Result) {
+				roles := make([]string, 0, len(items))
+				for _, item := range items {
+					roles = append(roles, item.Get("role").String())
+				}
+				if got := strings.Join(roles, ","); got != "system,user,assistant,tool,assistant,user" {
+					t.Fatalf("roles = %s", got)
+				}
+			},
+		},
+		{
+			name:   "claude",
+			format: sdktranslator.FormatClaude,
+			payload: `{"model":"m","max_tokens":0,"messages":[
+				{"role":"user","content":"FILLER"},
+				{"role":"assistant","content":"FILLER"},
+				{"role":"user","content":[{"type":"text","text":"weather?"}]},
+				{"role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"weather","input":{}}]},
+				{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"sunny"}]},
+				{"role":"assistant","content":"It is sunny."},
+				{"role":"user","content":"thanks"}]}`,
+			path: "messages",
+			check: func(t *testing.T, items []gjson.Result) {
+				if len(items) != 5 {
+					t.Fatalf("kept %d messages, want 5", len(items))
+				}
+				if items[1].Get("content.0.type").String() != "tool_use" || items[2].Get("content.0.type").String() != "tool_result" {
+					t.Fatalf("tool pair not kept: %s %s", items[1].Raw, items[2].Raw)
+				}
+			},
+		},
+		{
+			name:   "gemini",
+			format: sdktranslator.FormatGemini,
+			payload: `{"contents":[
+				{"role":"user","parts":[{"text":"FILLER"}]},
+				{"role":"model","parts":[{"text":"FILLER"}]},
+				{"role":"user","parts":[{"text":"weather?"}]},
+				{"role":"model","parts":[{"functionCall":{"name":"weather","args":{}}}]},
+				{"role":"user","parts":[{"functionResponse":{"name":"weather","response":{"result":"sunny"}}}]},
+				{"role":"model","parts":[{"text":"It is sunny."}]},
+				{"role":"user","parts":[{"text":"thanks"}]}]}`,
+			path: "contents",
+			check: func(t *testing.T, items []gjson.Result) {
+				if len(items) != 5 {
+					t.Fatalf("kept %d contents, want 5", len(items))
+				}
+				if !items[1].Get("parts.0.functionCall").Exists() || !items[2].Get("parts.0.functionResponse").Exists() {
+					t.Fatalf("function pair not kept: %s %s", items[1].Raw, items[2].Raw)
+				}
+			},
+		},
+		{
+			name:   "responses",
+			format: sdktranslator.FormatOpenAIResponse,
+			payload: `{"model":"m","input":[
+				{"type":"message","role":"developer","content":[{"type":"input_text","text":"be brief"}]},
+				{"type":"message","role":"user","content":[{"type":"input_text","text":"FILLER"}]},
+				{"type":"message","role":"assistant","content":[{"type":"output_text","text":"FILLER"}]},
+				{"type":"message","role":"user","content":[{"type":"input_text","text":"weather?"}]},
+				{"type":"function_call","call_id":"call_1","name":"weather","arguments":"{}"},
+				{"type":"function_call_output","call_id":"call_1","output":"sunny"},
+				{"role":"user","content":"thanks"}]}`,
+			path: "input",
+			check: func(t *testing.T, items []gjson.Result) {
+				types := make([]string, 0, len(items))
+				for _, item := range items {
+					types = append(types, item.Get("type").String()+":"+item.Get("role").String())
+				}
+				if got := strings.Join(types, ","); got != "message:developer,message:user,function_call:,function_call_output:,:user" {
+					t.Fatalf("items = %s", got)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := "contextguard-drop-" + tt.name
+			payload := []byte(strings.ReplaceAll(tt.payload, "FILLER", filler))
+			// Translated tool call IDs are random, so leave a little headroom.
+			guard := guardFor(t, config.ContextGuardConfig{Strategy: "drop-oldest"}, tt.format, model, payload, 10)
+
+			outcome, err := guard.Check(context.Background(), Request{Format: tt.format, Model: model, Payload: payload}, nil)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if outcome.Action != ActionDropped || outcome.Turns != 1 {
+				t.Fatalf("outcome = %s/%d, want dropped/1", outcome.Action, outcome.Turns)
+			}
+			if outcome.HeaderValue() != "dropped; turns=1" {
+				t.Fatalf("header = %q", outcome.HeaderValue())
+			}
+			tt.check(t, rawItems(t, outcome.Payload, tt.path))
+		})
+	}
+}
+
+func TestCheckRejectReturnsNativeError(t *testing.T) {
+	tests := []struct {
+		format  sdktranslator.Format
+		payload string
+		path    string
+		want    string
+	}{
+		{sdktranslator.FormatOpenAI, `{"messages":[{"role":"user","content":"FILLER"},{"role":"user","content":"hi"}]}`, "error.code", "context_length_exceeded"},
+		{sdktranslator.FormatOpenAIResponse, `{"input":[{"role":"user","content":"FILLER"},{"role":"user","content":"hi"}]}`, "error.param", "input"},
+		{sdktranslator.FormatClaude, `{"messages":[{"role":"user","content":"FILLER"},{"role":"user","content":"hi"}]}`, "error.type", "invalid_request_error"},
+		{sdktranslator.FormatGemini, `{"contents":[{"role":"user","parts":[{"text":"FILLER"}]},{"role":"user","parts":[{"text":"hi"}]}]}`, "error.status", "INVALID_ARGUMENT"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			model := "contextguard-reject-" + string(tt.format)
+			payload := []byte(strings.ReplaceAll(tt.payload, "FILLER", filler))
+			guard := guardFor(t, config.ContextGuardConfig{}, tt.format, model, payload, 0)
+
+			_, err := guard.Check(context.Background(), Request{Format: tt.format, Model: model, Payload: payload}, nil)
+			var overflow *OverflowError
+			if !errors.As(err, &overflow) {
+				t.Fatalf("Check error = %v, want *OverflowError", err)
+			}
+			if overflow.StatusCode() != 400 || !overflow.IsRequestScoped() {
+				t.Fatalf("status = %d, request scoped = %t", overflow.StatusCode(), overflow.IsRequestScoped())
+			}
+			if got := gjson.GetBytes(overflow.Body(), tt.path).String(); got != tt.want {
+				t.Fatalf("%s = %q, want %q in %s", tt.path, got, tt.want, overflow.Body())
+			}
+		})
+	}
+}
+
+func TestCheckRejectsWhenLatestTurnDoesNotFit(t *testing.T) {
+	model := "contextguard-latest-turn"
+	registerWindow(t, model, 50)
+	guard := New()
+	guard.Apply(config.ContextGuardConfig{Enable: true, Strategy: "drop-oldest"})
+	payload := []byte(`{"messages":[{"role":"user","content":"hi"},{"role":"user","content":""}]}`)
+	payload, _ = sjson.SetBytes(payload, "messages.1.content", filler)
+
+	_, err := guard.Check(context.Background(), Request{Format: sdktranslator.FormatOpenAI, Model: model, Payload: payload}, nil)
+	var overflow *OverflowError
+	if !errors.As(err, &overflow) {
+		t.Fatalf("Check error = %v, want *OverflowError", err)
+	}
+}
+
+func TestCheckSummarizesOldestTurns(t *testing.T) {
+	tests := []struct {
+		format  sdktranslator.Format
+		payload string
+		check   func(t *testing.T, payload []byte)
+	}{
+		{
+			format:  sdktranslator.FormatOpenAI,
+			payload: `{"messages":[{"role":"system","content":"be brief"},{"role":"user","content":"FILLER"},{"role":"user","content":"hi"}]}`,
+			check: func(t *testing.T, payload []byte) {
+				if got := gjson.GetBytes(payload, "messages.1.role").String(); got != "system" {
+					t.Fatalf("summary role = %q in %s", got, payload)
+				}
+				if !strings.Contains(gjson.GetBytes(payload, "messages.1.content").String(), "SUMMARY") {
+					t.Fatalf("summary missing in %s", payload)
+				}
+			},
+		},
+		{
+			format:  sdktranslator.FormatClaude,
+			payload: `{"system":"be brief","messages":[{"role":"user","content":"FILLER"},{"role":"user","content":"hi"}]}`,
+			check: func(t *testing.T, payload []byte) {
+				system := gjson.GetBytes(payload, "system").String()
+				if !strings.HasPrefix(system, "be brief") || !strings.Contains(system, "SUMMARY") {
+					t.Fatalf("system = %q", system)
+				}
+			},
+		},
+		{
+			format:  sdktranslator.FormatGemini,
+			payload: `{"contents":[{"role":"user","parts":[{"text":"FILLER"}]},{"role":"user","parts":[{"text":"hi"}]}]}`,
+			check: func(t *testing.T, payload []byte) {
+				if !strings.Contains(gjson.GetBytes(payload, "systemInstruction.parts.0.text").String(), "SUMMARY") {
+					t.Fatalf("summary missing in %s", payload)
+				}
+			},
+		},
+		{
+			format:  sdktranslator.FormatOpenAIResponse,
+			payload: `{"input":[{"role":"user","content":"FILLER"},{"role":"user","content":"hi"}]}`,
+			check: func(t *testing.T, payload []byte) {
+				if got := gjson.GetBytes(payload, "input.0.role").String(); got != "developer" {
+					t.Fatalf("summary role = %q in %s", got, payload)
+				}
+				if !strings.Contains(gjson.GetBytes(payload, "input.0.content.0.text").String(), "SUMMARY") {
+					t.Fatalf("summary missing in %s", payload)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			model := "contextguard-summary-" + string(tt.format)
+			payload := []byte(strings.ReplaceAll(tt.payload, "FILLER", filler))
+			cfg := config.ContextGuardConfig{Strategy: "summarize", SummaryModel: "cheap", SummaryMaxTokens: 200}
+			guard := guardFor(t, cfg, tt.format, model, payload, 200)
+
+			var transcript string
+			summarize := func(_ context.Context, model, text string, maxTokens int) (string, error) {
+				if model != "cheap" || maxTokens != 200 {
+					t.Fatalf("summarize called with %s/%d", model, maxTokens)
+				}
+				transcript = text
+				return "SUMMARY", nil
+			}
+			outcome, err := guard.Check(context.Background(), Request{Format: tt.format, Model: model, Payload: payload}, summarize)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if outcome.Action != ActionSummarized || outcome.Turns != 1 {
+				t.Fatalf("outcome = %s/%d, want summarized/1", outcome.Action, outcome.Turns)
+			}
+			if !strings.Contains(transcript, "quick brown fox") || strings.Contains(transcript, "hi\n") {
+				t.Fatalf("transcript = %.80q", transcript)
+			}
+			tt.check(t, outcome.Payload)
+		})
+	}
+}
+
+func TestCheckSummaryFailureDropsTurns(t *testing.T) {
+	model := "contextguard-summary-failure"
+	payload := []byte(`{"messages":[{"role":"user","content":""},{"role":"user","content":"hi"}]}`)
+	payload, _ = sjson.SetBytes(payload, "messages.0.content", filler)
+	cfg := config.ContextGuardConfig{Strategy: "summarize", SummaryModel: "cheap", SummaryMaxTokens: 100}
+	guard := guardFor(t, cfg, sdktranslator.FormatOpenAI, model, payload, 100)
+
+	outcome, err := guard.Check(context.Background(), Request{Format: sdktranslator.FormatOpenAI, Model: model, Payload: payload},
+		func(context.Context, string, string, int) (string, error) { return "", errors.New("upstream down") })
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if outcome.Action != ActionDropped {
+		t.Fatalf("action = %q, want %q", outcome.Action, ActionDropped)
+	}
+	if got := len(rawItems(t, outcome.Payload, "messages")); got != 1 {
+		t.Fatalf("kept %d messages, want 1", got)
+	}
+}
+
+func TestCheckPassesThrough(t *testing.T) {
+	payload := []byte(`{"max_tokens":10,"messages":[{"role":"user","content":"hi"}]}`)
+
+	t.Run("unknown window", func(t *testing.T) {
+		guard := New()
+		guard.Apply(config.ContextGuardConfig{Enable: true})
+		outcome, err := guard.Check(context.Background(), Request{Format: sdktranslator.FormatOpenAI, Model: "contextguard-unknown", Payload: payload}, nil)
+		if err != nil || outcome.Action != "" || string(outcome.Payload) != string(payload) {
+			t.Fatalf("outcome = %+v, err = %v", outcome, err)
+		}
+	})
+
+	t.Run("model not listed", func(t *testing.T) {
+		model := "contextguard-unlisted"
+		registerWindow(t, model, 5)
+		guard := New()
+		guard.Apply(config.ContextGuardConfig{Enable: true, Models: []string{"claude-*"}})
+		_, err := guard.Check(context.Background(), Request{Format: sdktranslator.FormatOpenAI, Model: model, RequestedModel: model, Payload: payload}, nil)
+		if err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+	})
+
+	t.Run("within budget", func(t *testing.T) {
+		model := "contextguard-fits"
+		registerWindow(t, model, 1000)
+		guard := New()
+		guard.Apply(config.ContextGuardConfig{Enable: true})
+		outcome, err := guard.Check(context.Background(), Request{Format: sdktranslator.FormatOpenAI, Model: model, Payload: payload}, nil)
+		if err != nil || outcome.Action != "" || outcome.Limit != 990 {
+			t.Fatalf("outcome = %+v, err = %v", outcome, err)
+		}
+	})
+}
+
+func TestContextWindowPrefersMaxContextLength(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("contextguard-window-a", "openai", []*registry.ModelInfo{{ID: "contextguard-window", ContextLength: 200000, MaxContextLength: 32000}})
+	reg.RegisterClient("contextguard-window-b", "claude", []*registry.ModelInfo{{ID: "contextguard-window", ContextLength: 100000}})
+	t.Cleanup(func() {
+		reg.UnregisterClient("contextguard-window-a")
+		reg.UnregisterClient("contextguard-window-b")
+	})
+
+	if got := ContextWindow("contextguard-window", []string{"openai"}); got != 32000 {
+		t.Fatalf("openai window = %d, want 32000", got)
+	}
+	if got := ContextWindow("contextguard-window", []string{"openai", "claude"}); got != 32000 {
+		t.Fatalf("combined window = %d, want 32000", got)
+	}
+	if got := ContextWindow("contextguard-window", []string{"claude"}); got != 100000 {
+		t.Fatalf("claude window = %d, want 100000", got)
+	}
+}
@@ -0,0 +1,49 @@
+package contextguard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// summaryInstructions is the system prompt of summary requests.
+const summaryInstructions = "You condense the earlier part of a conversation between a user and an AI assistant so the conversation can continue without it. " +
+	"Keep the user's goals, decisions, constraints, open questions, file names, identifiers, tool results and any facts the assistant will need later. " +
+	"Write a concise neutral summary in the language of the conversation. Do not address the user and do not continue the conversation."
+
+// SummaryRequest builds the OpenAI Chat Completions request that asks model to
+// summarize transcript in at most maxTokens tokens.
+func SummaryRequest(model, transcript string, maxTokens int) []byte {
+	body := []byte(`{"stream":false,"messages":[{"role":"system"},{"role":"user"}]}`)
+	body, _ = sjson.SetBytes(body, "model", model)
+	body, _ = sjson.SetBytes(body, "messages.0.content", summaryInstructions)
+	body, _ = sjson.SetBytes(body, "messages.1.content", "Conversation so far:\n\n"+transcript)
+	if maxTokens > 0 {
+		body, _ = sjson.SetBytes(body, "max_tokens", maxTokens)
+	}
+	return body
+}
+
+// ParseSummaryResponse extracts the summary text from a Chat Completions response.
+func ParseSummaryResponse(body []byte) (string, error) {
+	content := gjson.GetBytes(body, "choices.0.message.content")
+	var summary string
+	if content.IsArray() {
+		var parts []string
+		for _, part := range content.Array() {
+			if text := part.Get("text").String(); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		summary = strings.Join(parts, "\n")
+	} else {
+		summary = content.String()
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return "", fmt.Errorf("summary response has no content")
+	}
+	return summary, nil
+}
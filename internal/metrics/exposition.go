@@ -0,0 +1,281 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+)
+
+// Format selects the exposition wire format.
+type Format int
+
+const (
+	// FormatText is the Prometheus text exposition format 0.0.4.
+	FormatText Format = iota
+	// FormatOpenMetrics is the OpenMetrics 1.0.0 text format.
+	FormatOpenMetrics
+)
+
+const (
+	contentTypeText        = "text/plain; version=0.0.4; charset=utf-8"
+	contentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// ContentType returns the HTTP Content-Type for the format.
+func (f Format) ContentType() string {
+	if f == FormatOpenMetrics {
+		return contentTypeOpenMetrics
+	}
+	return contentTypeText
+}
+
+// NegotiateFormat picks OpenMetrics when the Accept header asks for it and falls back to text.
+func NegotiateFormat(accept string) Format {
+	if strings.Contains(strings.ToLower(accept), "application/openmetrics-text") {
+		return FormatOpenMetrics
+	}
+	return FormatText
+}
+
+// AuthSource exposes the credential and scheduler state sampled at scrape time.
+// *coreauth.Manager satisfies this interface.
+type AuthSource interface {
+	List() []*coreauth.Auth
+	SchedulerStats() []coreauth.SchedulerShardStats
+}
+
+// Write renders all metric families, including scrape-time credential gauges from source.
+func (r *Registry) Write(w io.Writer, source AuthSource, format Format) error {
+	var buf bytes.Buffer
+	enc := &encoder{buf: &buf, format: format}
+	if r != nil {
+		enc.counter(r.requests)
+		enc.counter(r.tokens)
+		enc.histogram(r.latency)
+		enc.histogram(r.ttfb)
+	}
+	if source != nil {
+		writeAuthGauges(enc, source, time.Now())
+	}
+	if format == FormatOpenMetrics {
+		buf.WriteString("# EOF\n")
+	}
+	_, errWrite := w.Write(buf.Bytes())
+	return errWrite
+}
+
+func writeAuthGauges(enc *encoder, source AuthSource, now time.Time) {
+	auths := source.List()
+	sort.Slice(auths, func(i, j int) bool {
+		if auths[i] == nil || auths[j] == nil {
+			return auths[j] == nil
+		}
+		return auths[i].ID < auths[j].ID
+	})
+
+	info := gaugeFamily{name: "cliproxy_auth_info", help: "Registered credentials labelled by provider, auth index and status.",
+		labelNames: []string{"provider", "auth_index", "status", "disabled"}}
+	unavailable := gaugeFamily{name: "cliproxy_auth_unavailable", help: "Whether the credential is temporarily unavailable (1) or not (0).",
+		labelNames: []string{"provider", "auth_index"}}
+	quotaExceeded := gaugeFamily{name: "cliproxy_auth_quota_exceeded", help: "Whether the credential has a credential-wide quota block (1) or not (0).",
+		labelNames: []string{"provider", "auth_index"}}
+	modelCooldown := gaugeFamily{name: "cliproxy_auth_model_cooldown", help: "Whether the credential is cooling down for the model (1) or not (0).",
+		labelNames: []string{"provider", "auth_index", "model"}}
+	modelQuota := gaugeFamily{name: "cliproxy_auth_model_quota_exceeded", help: "Whether the credential exceeded its quota for the model (1) or not (0).",
+		labelNames: []string{"provider", "auth_index", "model"}}
+	modelRetry := gaugeFamily{name: "cliproxy_auth_model_retry_after_seconds", help: "Seconds until the credential becomes selectable again for the model.",
+		labelNames: []string{"provider", "auth_index", "model"}}
+
+	for _, auth := range auths {
+		if auth == nil {
+			continue
+		}
+		// Listed auths may be shared with the manager, so the scrape reads the index
+		// assigned at registration instead of assigning one.
+		authIndex := strings.TrimSpace(auth.Index)
+		if authIndex == "" {
+			continue
+		}
+		provider := labelOrUnknown(auth.Provider)
+		info.add(1, provider, authIndex, labelOrUnknown(string(auth.Status)), strconv.FormatBool(auth.Disabled))
+		unavailable.add(boolGauge(auth.Unavailable && auth.NextRetryAfter.After(now)), provider, authIndex)
+		quotaExceeded.add(boolGauge(auth.Quota.Exceeded && auth.Quota.NextRecoverAt.After(now)), provider, authIndex)
+
+		models := make([]string, 0, len(auth.ModelStates))
+		for model := range auth.ModelStates {
+			models = append(models, model)
+		}
+		sort.Strings(models)
+		for _, model := range models {
+			state := auth.ModelStates[model]
+			if state == nil {
+				continue
+			}
+			cooling := state.Unavailable && state.NextRetryAfter.After(now)
+			quota := state.Quota.Exceeded && state.Quota.NextRecoverAt.After(now)
+			retryAt := state.NextRetryAfter
+			if state.Quota.NextRecoverAt.After(retryAt) {
+				retryAt = state.Quota.NextRecoverAt
+			}
+			modelCooldown.add(boolGauge(cooling), provider, authIndex, model)
+			modelQuota.add(boolGauge(quota), provider, authIndex, model)
+			modelRetry.add(secondsUntil(retryAt, now), provider, authIndex, model)
+		}
+	}
+
+	scheduler := gaugeFamily{name: "cliproxy_scheduler_auths", help: "Credentials per scheduler shard grouped by state.",
+		labelNames: []string{"provider", "model", "state"}}
+	for _, shard := range source.SchedulerStats() {
+		scheduler.add(float64(shard.Ready), shard.Provider, shard.Model, "ready")
+		scheduler.add(float64(shard.Cooldown), shard.Provider, shard.Model, "cooldown")
+		scheduler.add(float64(shard.Blocked), shard.Provider, shard.Model, "blocked")
+		scheduler.add(float64(shard.Disabled), shard.Provider, shard.Model, "disabled")
+	}
+
+	for _, family := range []*gaugeFamily{&info, &unavailable, &quotaExceeded, &modelCooldown, &modelQuota, &modelRetry, &scheduler} {
+		enc.gauge(family)
+	}
+}
+
+func boolGauge(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// gaugeFamily is a scrape-time gauge that is rebuilt for every exposition.
+type gaugeFamily struct {
+	name       string
+	help       string
+	labelNames []string
+	series     []series
+}
+
+func (g *gaugeFamily) add(value float64, labelValues ...string) {
+	g.series = append(g.series, series{labels: labelValues, value: value})
+}
+
+type encoder struct {
+	buf    *bytes.Buffer
+	format Format
+}
+
+func (e *encoder) header(familyName, help, kind string) {
+	e.buf.WriteString("# HELP ")
+	e.buf.WriteString(familyName)
+	e.buf.WriteByte(' ')
+	e.buf.WriteString(escapeHelp(help))
+	e.buf.WriteString("\n# TYPE ")
+	e.buf.WriteString(familyName)
+	e.buf.WriteByte(' ')
+	e.buf.WriteString(kind)
+	e.buf.WriteByte('\n')
+}
+
+func (e *encoder) counter(c *counterVec) {
+	if c == nil {
+		return
+	}
+	samples := c.snapshot()
+	if len(samples) == 0 {
+		return
+	}
+	familyName := c.name + "_total"
+	if e.format == FormatOpenMetrics {
+		familyName = c.name
+	}
+	e.header(familyName, c.help, "counter")
+	for _, sample := range samples {
+		e.sample(c.name+"_total", c.labelNames, sample.labels, "", "", sample.value)
+	}
+}
+
+func (e *encoder) gauge(g *gaugeFamily) {
+	if g == nil || len(g.series) == 0 {
+		return
+	}
+	e.header(g.name, g.help, "gauge")
+	for _, sample := range g.series {
+		e.sample(g.name, g.labelNames, sample.labels, "", "", sample.value)
+	}
+}
+
+func (e *encoder) histogram(h *histogramVec) {
+	if h == nil {
+		return
+	}
+	samples := h.snapshot()
+	if len(samples) == 0 {
+		return
+	}
+	e.header(h.name, h.help, "histogram")
+	for _, sample := range samples {
+		for i, bound := range h.bounds {
+			e.sample(h.name+"_bucket", h.labelNames, sample.labels, "le", formatFloat(bound), float64(sample.buckets[i]))
+		}
+		e.sample(h.name+"_bucket", h.labelNames, sample.labels, "le", "+Inf", float64(sample.count))
+		e.sample(h.name+"_sum", h.labelNames, sample.labels, "", "", sample.value)
+		e.sample(h.name+"_count", h.labelNames, sample.labels, "", "", float64(sample.count))
+	}
+}
+
+func (e *encoder) sample(name string, labelNames, labelValues []string, extraName, extraValue string, value float64) {
+	e.buf.WriteString(name)
+	wroteLabel := false
+	for i, labelName := range labelNames {
+		if i >= len(labelValues) || labelValues[i] == "" {
+			continue
+		}
+		e.label(&wroteLabel, labelName, labelValues[i])
+	}
+	if extraName != "" {
+		e.label(&wroteLabel, extraName, extraValue)
+	}
+	if wroteLabel {
+		e.buf.WriteByte('}')
+	}
+	e.buf.WriteByte(' ')
+	e.buf.WriteString(formatFloat(value))
+	e.buf.WriteByte('\n')
+}
+
+func (e *encoder) label(wrote *bool, name, value string) {
+	if *wrote {
+		e.buf.WriteByte(',')
+	} else {
+		e.buf.WriteByte('{')
+		*wrote = true
+	}
+	e.buf.WriteString(name)
+	e.buf.WriteString(`="`)
+	e.buf.WriteString(escapeLabelValue(value))
+	e.buf.WriteByte('"')
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(value string) string { return labelValueEscaper.Replace(value) }
+
+func escapeHelp(value string) string { return helpEscaper.Replace(value) }
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Serves reports whether metrics are enabled and routed to the given listener.
+func Serves(listenerName string) bool {
+	return Enabled() && Listener() == listenerName
+}
+
+// Handler serves the default registry when metrics are enabled for the given listener.
+// Requests are answered with 404 while metrics are disabled or routed to another listener,
+// so routes can be registered once and follow config reloads.
+func Handler(listenerName string, source func() AuthSource) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Serves(listenerName) {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var authSource AuthSource
+		if source != nil {
+			authSource = source()
+		}
+		format := NegotiateFormat(r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if errWrite := Default().Write(w, authSource, format); errWrite != nil {
+			log.Debugf("metrics: failed to write exposition: %v", errWrite)
+		}
+	})
+}
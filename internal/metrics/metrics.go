@@ -0,0 +1,251 @@
+// Package metrics exposes proxy, credential and upstream health in the Prometheus
+// text exposition and OpenMetrics formats. Request counters, latency histograms and
+// token totals are fed from usage records; credential cooldown and scheduler state is
+// collected from the core auth manager at scrape time.
+package metrics
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+var (
+	enabled  atomic.Bool
+	listener atomic.Value
+)
+
+func init() {
+	listener.Store(config.MetricsListenerAPI)
+}
+
+// Apply updates the exposition state from the metrics config block.
+// Disabling metrics keeps already collected series so a re-enable does not reset counters.
+func Apply(cfg config.MetricsConfig) {
+	listener.Store(cfg.EffectiveListener())
+	enabled.Store(cfg.Enable)
+}
+
+// Enabled reports whether metrics collection and exposition are enabled.
+func Enabled() bool { return enabled.Load() }
+
+// Listener returns the normalized listener that serves the metrics endpoint.
+func Listener() string {
+	if value, ok := listener.Load().(string); ok && value != "" {
+		return value
+	}
+	return config.MetricsListenerAPI
+}
+
+var (
+	// latencyBuckets covers whole-request latency for streaming and non-streaming calls (seconds).
+	latencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+	// ttfbBuckets covers time-to-first-byte of upstream responses (seconds).
+	ttfbBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30, 60}
+)
+
+// Registry stores the request-driven metric families.
+type Registry struct {
+	requests *counterVec
+	tokens   *counterVec
+	latency  *histogramVec
+	ttfb     *histogramVec
+}
+
+// NewRegistry constructs an empty metrics registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		requests: newCounterVec("cliproxy_requests", "Upstream requests completed by the proxy.",
+			"provider", "model", "alias", "auth_index", "auth_type", "outcome", "status_code"),
+		tokens: newCounterVec("cliproxy_tokens", "Tokens reported by upstream responses, split into non-overlapping buckets.",
+			"provider", "model", "auth_index", "type"),
+		latency: newHistogramVec("cliproxy_request_duration_seconds", "Total upstream request latency in seconds.",
+			latencyBuckets, "provider", "model", "auth_index"),
+		ttfb: newHistogramVec("cliproxy_request_ttfb_seconds", "Upstream time to first byte in seconds.",
+			ttfbBuckets, "provider", "model", "auth_index"),
+	}
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default returns the process-wide metrics registry fed by the usage plugin.
+func Default() *Registry { return defaultRegistry }
+
+// Observe records a single usage record into the request, latency and token families.
+func (r *Registry) Observe(record coreusage.Record) {
+	if r == nil {
+		return
+	}
+	provider := labelOrUnknown(record.Provider)
+	model := labelOrUnknown(record.Model)
+	alias := strings.TrimSpace(record.Alias)
+	if alias == "" {
+		alias = model
+	}
+	authIndex := strings.TrimSpace(record.AuthIndex)
+	authType := labelOrUnknown(record.AuthType)
+
+	outcome := "success"
+	statusCode := ""
+	if record.Failed {
+		outcome = "failure"
+		if record.Fail.StatusCode > 0 {
+			statusCode = strconv.Itoa(record.Fail.StatusCode)
+		}
+	}
+	r.requests.add(1, provider, model, alias, authIndex, authType, outcome, statusCode)
+
+	if record.Latency > 0 {
+		r.latency.observe(record.Latency.Seconds(), provider, model, authIndex)
+	}
+	if record.TTFT > 0 {
+		r.ttfb.observe(record.TTFT.Seconds(), provider, model, authIndex)
+	}
+
+	detail := coreusage.EnsureTokenBreakdownForProvider(record.Detail, record.Provider, record.ExecutorType)
+	breakdown := detail.TokenBreakdown
+	if !breakdown.Valid() {
+		return
+	}
+	for _, bucket := range []struct {
+		kind  string
+		value int64
+	}{
+		{"input_uncached", breakdown.Input.UncachedTokens},
+		{"input_cache_read", breakdown.Input.CacheReadTokens},
+		{"input_cache_write", breakdown.Input.CacheWriteTokens},
+		{"output", breakdown.Output.NonReasoningTokens},
+		{"output_reasoning", breakdown.Output.ReasoningTokens},
+		{"unclassified", breakdown.UnclassifiedTokens},
+	} {
+		if bucket.value <= 0 {
+			continue
+		}
+		r.tokens.add(float64(bucket.value), provider, model, authIndex, bucket.kind)
+	}
+}
+
+func labelOrUnknown(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
+// series is one labelled sample set inside a metric family.
+type series struct {
+	labels []string
+	value  float64
+	// histogram-only fields
+	buckets []uint64
+	count   uint64
+}
+
+type counterVec struct {
+	name       string
+	help       string
+	labelNames []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+func newCounterVec(name, help string, labelNames ...string) *counterVec {
+	return &counterVec{name: name, help: help, labelNames: labelNames, series: make(map[string]*series)}
+}
+
+func (c *counterVec) add(delta float64, labelValues ...string) {
+	key := seriesKey(labelValues)
+	c.mu.Lock()
+	entry, ok := c.series[key]
+	if !ok {
+		entry = &series{labels: append([]string(nil), labelValues...)}
+		c.series[key] = entry
+	}
+	entry.value += delta
+	c.mu.Unlock()
+}
+
+func (c *counterVec) snapshot() []series {
+	c.mu.Lock()
+	out := make([]series, 0, len(c.series))
+	for _, entry := range c.series {
+		out = append(out, series{labels: entry.labels, value: entry.value})
+	}
+	c.mu.Unlock()
+	sortSeries(out)
+	return out
+}
+
+type histogramVec struct {
+	name       string
+	help       string
+	labelNames []string
+	bounds     []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+func newHistogramVec(name, help string, bounds []float64, labelNames ...string) *histogramVec {
+	return &histogramVec{name: name, help: help, labelNames: labelNames, bounds: bounds, series: make(map[string]*series)}
+}
+
+func (h *histogramVec) observe(value float64, labelValues ...string) {
+	key := seriesKey(labelValues)
+	h.mu.Lock()
+	entry, ok := h.series[key]
+	if !ok {
+		entry = &series{labels: append([]string(nil), labelValues...), buckets: make([]uint64, len(h.bounds))}
+		h.series[key] = entry
+	}
+	for i, bound := range h.bounds {
+		if value <= bound {
+			entry.buckets[i]++
+		}
+	}
+	entry.count++
+	entry.value += value
+	h.mu.Unlock()
+}
+
+func (h *histogramVec) snapshot() []series {
+	h.mu.Lock()
+	out := make([]series, 0, len(h.series))
+	for _, entry := range h.series {
+		out = append(out, series{
+			labels:  entry.labels,
+			value:   entry.value,
+			count:   entry.count,
+			buckets: append([]uint64(nil), entry.buckets...),
+		})
+	}
+	h.mu.Unlock()
+	sortSeries(out)
+	return out
+}
+
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func sortSeries(items []series) {
+	sort.Slice(items, func(i, j int) bool {
+		return seriesKey(items[i].labels) < seriesKey(items[j].labels)
+	})
+}
+
+// secondsUntil returns the non-negative number of seconds between now and t.
+func secondsUntil(t, now time.Time) float64 {
+	if t.IsZero() || !t.After(now) {
+		return 0
+	}
+	return t.Sub(now).Seconds()
+}
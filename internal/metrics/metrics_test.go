@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+type stubAuthSource struct {
+	auths  []*coreauth.Auth
+	shards []coreauth.SchedulerShardStats
+}
+
+func (s stubAuthSource) List() []*coreauth.Auth { return s.auths }
+
+func (s stubAuthSource) SchedulerStats() []coreauth.SchedulerShardStats { return s.shards }
+
+func TestRegistryWrite_RequestTokenAndLatencyFamilies(t *testing.T) {
+	registry := NewRegistry()
+	registry.Observe(coreusage.Record{
+		Provider:  "claude",
+		Model:     "claude-sonnet-4",
+		AuthIndex: "abc123",
+		AuthType:  "oauth",
+		APIKey:    "sk-secret-client-key",
+		Latency:   1500 * time.Millisecond,
+		TTFT:      200 * time.Millisecond,
+		Detail: coreusage.Detail{
+			TokenBreakdown: coreusage.NewIndependentTokenBreakdown(100, 40, 0, 30, 10, 0),
+		},
+	})
+	registry.Observe(coreusage.Record{
+		Provider:  "claude",
+		Model:     "claude-sonnet-4",
+		AuthIndex: "abc123",
+		AuthType:  "oauth",
+		Failed:    true,
+		Fail:      coreusage.Failure{StatusCode: 429},
+	})
+
+	var buf bytes.Buffer
+	if errWrite := registry.Write(&buf, nil, FormatText); errWrite != nil {
+		t.Fatalf("Write() error = %v", errWrite)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE cliproxy_requests_total counter",
+		`cliproxy_requests_total{provider="claude",model="claude-sonnet-4",alias="claude-sonnet-4",auth_index="abc123",auth_type="oauth",outcome="success"} 1`,
+		`cliproxy_requests_total{provider="claude",model="claude-sonnet-4",alias="claude-sonnet-4",auth_index="abc123",auth_type="oauth",outcome="failure",status_code="429"} 1`,
+		`cliproxy_tokens_total{provider="claude",model="claude-sonnet-4",auth_index="abc123",type="input_uncached"} 100`,
+		`cliproxy_tokens_total{provider="claude",model="claude-sonnet-4",auth_index="abc123",type="input_cache_read"} 40`,
+		`cliproxy_tokens_total{provider="claude",model="claude-sonnet-4",auth_index="abc123",type="output_reasoning"} 10`,
+		`cliproxy_request_duration_seconds_bucket{provider="claude",model="claude-sonnet-4",auth_index="abc123",le="1"} 0`,
+		`cliproxy_request_duration_seconds_bucket{provider="claude",model="claude-sonnet-4",auth_index="abc123",le="2.5"} 1`,
+		`cliproxy_request_duration_seconds_count{provider="claude",model="claude-sonnet-4",auth_index="abc123"} 1`,
+		`cliproxy_request_ttfb_seconds_bucket{provider="claude",model="claude-sonnet-4",auth_index="abc123",le="0.25"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("exposition missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "sk-secret-client-key") {
+		t.Fatalf("exposition leaked client API key:\n%s", out)
+	}
+	if strings.Contains(out, "# EOF") {
+		t.Fatalf("text exposition must not contain OpenMetrics EOF marker")
+	}
+}
+
+func TestRegistryWrite_AuthAndSchedulerGauges(t *testing.T) {
+	now := time.Now()
+	source := stubAuthSource{
+		auths: []*coreauth.Auth{{
+			ID:       "auth-1",
+			Index:    "idx-1",
+			Provider: "gemini",
+			Status:   coreauth.StatusActive,
+			Attributes: map[string]string{
+				"api_key": "AIza-secret",
+			},
+			ModelStates: map[string]*coreauth.ModelState{
+				"gemini-2.5-pro": {
+					Unavailable:    true,
+					NextRetryAfter: now.Add(time.Minute),
+					Quota:          coreauth.QuotaState{Exceeded: true, NextRecoverAt: now.Add(time.Minute)},
+				},
+			},
+		}, {
+			ID:       "auth-unindexed",
+			Provider: "gemini",
+			Status:   coreauth.StatusActive,
+		}},
+		shards: []coreauth.SchedulerShardStats{{Provider: "gemini", Model: "gemini-2.5-pro", Cooldown: 1}},
+	}
+
+	var buf bytes.Buffer
+	if errWrite := NewRegistry().Write(&buf, source, FormatOpenMetrics); errWrite != nil {
+		t.Fatalf("Write() error = %v", errWrite)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`cliproxy_auth_info{provider="gemini",auth_index="idx-1",status="active",disabled="false"} 1`,
+		`cliproxy_auth_model_cooldown{provider="gemini",auth_index="idx-1",model="gemini-2.5-pro"} 1`,
+		`cliproxy_auth_model_quota_exceeded{provider="gemini",auth_index="idx-1",model="gemini-2.5-pro"} 1`,
+		`cliproxy_scheduler_auths{provider="gemini",model="gemini-2.5-pro",state="cooldown"} 1`,
+		`cliproxy_scheduler_auths{provider="gemini",model="gemini-2.5-pro",state="ready"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("exposition missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "AIza-secret") || strings.Contains(out, "auth-1") {
+		t.Fatalf("exposition leaked credential identity:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "# EOF\n") {
+		t.Fatalf("OpenMetrics exposition must end with EOF marker:\n%s", out)
+	}
+	if index := source.auths[1].Index; index != "" {
+		t.Fatalf("scrape assigned auth index %q", index)
+	}
+}
+
+func TestHandler_FollowsListenerConfig(t *testing.T) {
+	t.Cleanup(func() { Apply(config.MetricsConfig{}) })
+
+	handler := Handler(config.MetricsListenerAPI, nil)
+
+	Apply(config.MetricsConfig{Enable: false})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("disabled status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	Apply(config.MetricsConfig{Enable: true, Listener: "pprof"})
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("other listener status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	Apply(config.MetricsConfig{Enable: true})
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enabled status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/openmetrics-text") {
+		t.Fatalf("Content-Type = %q, want OpenMetrics", got)
+	}
+}
@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"context"
+
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+func init() {
+	coreusage.RegisterPlugin(&usagePlugin{})
+}
+
+// usagePlugin feeds usage records into the default registry while metrics are enabled.
+type usagePlugin struct{}
+
+func (p *usagePlugin) HandleUsage(_ context.Context, record coreusage.Record) {
+	if p == nil || !Enabled() {
+		return
+	}
+	Default().Observe(record)
+}
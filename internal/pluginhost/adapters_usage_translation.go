@@ -170,11 +170,29 @@ func (a *usageAdapter) HandleUsage(ctx context.Context, record coreusage.Record)
 			CacheReadTokens:     record.Detail.CacheReadTokens,
 			CacheCreationTokens: record.Detail.CacheCreationTokens,
 			TotalTokens:         record.Detail.TotalTokens,
+			InputAudioTokens:    record.Detail.InputAudioTokens,
+			OutputAudioTokens:   record.Detail.OutputAudioTokens,
 		},
+		Cost:            usageCostToPluginCost(record.Cost),
 		ResponseHeaders: cloneHeader(record.ResponseHeaders),
 	})
 }
 
+func usageCostToPluginCost(cost *coreusage.Cost) *pluginapi.UsageCost {
+	if cost == nil {
+		return nil
+	}
+	return &pluginapi.UsageCost{
+		Input:       cost.Input,
+		CachedInput: cost.CachedInput,
+		CacheWrite:  cost.CacheWrite,
+		Output:      cost.Output,
+		Reasoning:   cost.Reasoning,
+		Total:       cost.Total,
+		Source:      cost.Source,
+	}
+}
+
 func (a *thinkingAdapter) Apply(body []byte, config thinking.ThinkingConfig, modelInfo *registry.ModelInfo) (out []byte, err error) {
 	if a == nil || a.applier == nil || a.host == nil || a.host.isPluginFused(a.pluginID) || !a.host.pluginIdentityCurrent(a.pluginID, a.path, a.version) {
 		return bytes.Clone(body), nil
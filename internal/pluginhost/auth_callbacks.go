@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/authcrypt"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
 )
@@ -178,7 +179,7 @@ func (h *Host) listAuthFilesFromDisk() ([]pluginapi.HostAuthFileEntry, error) {
 			fileEntry.Size = info.Size()
 			fileEntry.ModTime = info.ModTime()
 		}
-		if data, errRead := os.ReadFile(full); errRead == nil {
+		if data, errRead := authcrypt.ReadFile(full); errRead == nil {
 			var metadata map[string]any
 			if errUnmarshal := json.Unmarshal(data, &metadata); errUnmarshal == nil {
 				if provider, ok := metadata["type"].(string); ok {
@@ -242,7 +243,7 @@ func (h *Host) authPhysicalJSONByIndex(authIndex string) (*coreauth.Auth, []byte
 	if path == "" {
 		return nil, nil, fmt.Errorf("auth file path not found for auth_index %s", authIndex)
 	}
-	data, errRead := os.ReadFile(path)
+	data, errRead := authcrypt.ReadFile(path)
 	if errRead != nil {
 		if os.IsNotExist(errRead) {
 			return nil, nil, fmt.Errorf("auth file not found for auth_index %s", authIndex)
@@ -289,11 +290,15 @@ func (h *Host) saveAuthFile(ctx context.Context, name string, data []byte) (stri
 			dst = abs
 		}
 	}
+	data, errOpen := authcrypt.Open(data)
+	if errOpen != nil {
+		return "", fmt.Errorf("invalid auth file: %w", errOpen)
+	}
 	auth, errBuild := h.buildAuthFromFileData(dst, data)
 	if errBuild != nil {
 		return "", errBuild
 	}
-	if errWrite := os.WriteFile(dst, data, 0o600); errWrite != nil {
+	if errWrite := authcrypt.WriteFile(dst, data, 0o600); errWrite != nil {
 		return "", fmt.Errorf("failed to write auth file: %w", errWrite)
 	}
 	if errUpsert := h.upsertAuthRecord(ctx, auth); errUpsert != nil {
@@ -308,7 +313,7 @@ func (h *Host) buildAuthFromFileData(path string, data []byte) (*coreauth.Auth,
 	}
 	if data == nil {
 		var errRead error
-		data, errRead = os.ReadFile(path)
+		data, errRead = authcrypt.ReadFile(path)
 		if errRead != nil {
 			return nil, fmt.Errorf("failed to read auth file: %w", errRead)
 		}
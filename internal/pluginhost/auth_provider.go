@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/authcrypt"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
@@ -449,7 +450,11 @@ func (s *pluginTokenStorage) SaveTokenToFile(path string) error {
 	if pluginTokenStorageFileCurrent(path, payload) {
 		return nil
 	}
-	return atomicWriteFile(path, payload)
+	sealed, errSeal := authcrypt.Seal(payload)
+	if errSeal != nil {
+		return errSeal
+	}
+	return atomicWriteFile(path, sealed)
 }
 
 func pluginTokenStorageFileCurrent(path string, payload []byte) bool {
@@ -460,7 +465,7 @@ func pluginTokenStorageFileCurrent(path string, payload []byte) bool {
 	if errRead != nil {
 		return false
 	}
-	return jsonPayloadEqual(current, payload)
+	return authcrypt.UpToDate(current, payload, jsonPayloadEqual)
 }
 
 func jsonPayloadEqual(left, right []byte) bool {
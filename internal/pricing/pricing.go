@@ -0,0 +1,133 @@
+// Package pricing prices usage records for cost accounting.
+//
+// Prices come from the model-pricing config overrides first and from the pricing
+// metadata of the models catalog otherwise. The default table is installed as the
+// usage cost calculator, so every published usage record carries its cost.
+package pricing
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+// tokensPerPriceUnit is the token count catalog prices are quoted for.
+const tokensPerPriceUnit = 1_000_000
+
+func init() {
+	coreusage.SetCostCalculator(func(record coreusage.Record) *coreusage.Cost {
+		return Default().Cost(record)
+	})
+}
+
+// Table resolves model prices from config overrides and the models catalog.
+type Table struct {
+	mu        sync.RWMutex
+	overrides []config.ModelPriceOverride
+}
+
+// New constructs a table without overrides.
+func New() *Table {
+	return &Table{}
+}
+
+var defaultTable = New()
+
+// Default returns the process-wide table used by the usage cost calculator.
+func Default() *Table {
+	return defaultTable
+}
+
+// Apply publishes the model-pricing overrides of cfg.
+func (t *Table) Apply(cfg *config.Config) {
+	if t == nil {
+		return
+	}
+	var overrides []config.ModelPriceOverride
+	if cfg != nil {
+		overrides = append(overrides, cfg.ModelPricing...)
+	}
+	t.mu.Lock()
+	t.overrides = overrides
+	t.mu.Unlock()
+}
+
+// Lookup returns the prices of a model and the cost source they came from. alias is
+// the client-facing model and model the upstream model; either may be empty.
+func (t *Table) Lookup(provider, alias, model string) (*registry.ModelPricing, string) {
+	if t == nil {
+		return nil, ""
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	names := candidateNames(alias, model)
+	if len(names) == 0 {
+		return nil, ""
+	}
+	t.mu.RLock()
+	pricing := config.LookupModelPriceOverride(t.overrides, provider, names...)
+	t.mu.RUnlock()
+	if pricing != nil {
+		return pricing, coreusage.CostSourceConfig
+	}
+	for _, name := range names {
+		if pricing = registry.LookupModelPricing(name, provider); pricing != nil {
+			return pricing, coreusage.CostSourceCatalog
+		}
+	}
+	return nil, ""
+}
+
+// Cost prices record. Records served from the response cache cost nothing; records
+// of unpriced models return nil.
+func (t *Table) Cost(record coreusage.Record) *coreusage.Cost {
+	if record.Cached {
+		return &coreusage.Cost{Source: coreusage.CostSourceResponseCache}
+	}
+	pricing, source := t.Lookup(record.Provider, record.Alias, record.Model)
+	if pricing == nil {
+		return nil
+	}
+	cost := Compute(coreusage.EnsureTokenBreakdownForProvider(record.Detail, record.Provider, record.ExecutorType).TokenBreakdown, pricing)
+	cost.Source = source
+	return &cost
+}
+
+// Compute prices a token breakdown. Tokens the breakdown could not classify are
+// billed at the input price.
+func Compute(breakdown coreusage.TokenBreakdown, pricing *registry.ModelPricing) coreusage.Cost {
+	if pricing == nil {
+		return coreusage.Cost{}
+	}
+	cost := coreusage.Cost{
+		Input:       price(breakdown.Input.UncachedTokens+breakdown.UnclassifiedTokens, pricing.Input),
+		CachedInput: price(breakdown.Input.CacheReadTokens, pricing.CachedInputPrice()),
+		CacheWrite:  price(breakdown.Input.CacheWriteTokens, pricing.CacheWritePrice()),
+		Output:      price(breakdown.Output.NonReasoningTokens, pricing.Output),
+		Reasoning:   price(breakdown.Output.ReasoningTokens, pricing.ReasoningPrice()),
+	}
+	cost.Total = cost.Input + cost.CachedInput + cost.CacheWrite + cost.Output + cost.Reasoning
+	return cost
+}
+
+func price(tokens int64, perUnit float64) float64 {
+	if tokens <= 0 || perUnit <= 0 {
+		return 0
+	}
+	return float64(tokens) * perUnit / tokensPerPriceUnit
+}
+
+func candidateNames(alias, model string) []string {
+	names := make([]string, 0, 2)
+	alias = strings.TrimSpace(alias)
+	model = strings.TrimSpace(model)
+	if alias != "" {
+		names = append(names, alias)
+	}
+	if model != "" && !strings.EqualFold(model, alias) {
+		names = append(names, model)
+	}
+	return names
+}
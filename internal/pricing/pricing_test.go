@@ -0,0 +1,79 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+func TestComputeAppliesFallbackPrices(t *testing.T) {
+	cacheRead := 0.3
+	pricing := &registry.ModelPricing{Input: 3, CachedInput: &cacheRead, Output: 15}
+	breakdown := coreusage.NewSubsetTokenBreakdown(1_000_000, 200_000, 100_000, 500_000, 100_000, 1_500_000)
+
+	cost := Compute(breakdown, pricing)
+	want := coreusage.Cost{
+		Input:       2.1,  // 700k uncached at $3
+		CachedInput: 0.06, // 200k cache reads at $0.30
+		CacheWrite:  0.3,  // 100k cache writes at the input price
+		Output:      6,    // 400k output at $15
+		Reasoning:   1.5,  // 100k reasoning at the output price
+	}
+	want.Total = want.Input + want.CachedInput + want.CacheWrite + want.Output + want.Reasoning
+	for name, pair := range map[string][2]float64{
+		"input":        {cost.Input, want.Input},
+		"cached_input": {cost.CachedInput, want.CachedInput},
+		"cache_write":  {cost.CacheWrite, want.CacheWrite},
+		"output":       {cost.Output, want.Output},
+		"reasoning":    {cost.Reasoning, want.Reasoning},
+		"total":        {cost.Total, want.Total},
+	} {
+		if math.Abs(pair[0]-pair[1]) > 1e-9 {
+			t.Errorf("%s = %v, want %v", name, pair[0], pair[1])
+		}
+	}
+}
+
+func TestTableCostPrefersConfigOverridesOverCatalog(t *testing.T) {
+	const clientID = "pricing-test-client"
+	modelRegistry := registry.GetGlobalRegistry()
+	modelRegistry.RegisterClient(clientID, "acme", []*registry.ModelInfo{{
+		ID:      "acme-large",
+		Pricing: &registry.ModelPricing{Input: 2, Output: 8},
+	}})
+	t.Cleanup(func() { modelRegistry.UnregisterClient(clientID) })
+
+	table := New()
+	record := coreusage.Record{
+		Provider:     "acme",
+		ExecutorType: "OpenAICompatExecutor",
+		Model:        "acme-large-2026",
+		Alias:        "acme-large",
+		Detail:       coreusage.Detail{InputTokens: 500_000, OutputTokens: 250_000, TotalTokens: 750_000},
+	}
+	cost := table.Cost(record)
+	if cost == nil || cost.Source != coreusage.CostSourceCatalog || math.Abs(cost.Total-3) > 1e-9 {
+		t.Fatalf("catalog cost = %+v, want total 3 from the catalog", cost)
+	}
+
+	table.Apply(&config.Config{ModelPricing: []config.ModelPriceOverride{
+		{Model: "other-*", Input: 100, Output: 100},
+		{Model: "ACME-*", Provider: "acme", Input: 1, Output: 4},
+	}})
+	cost = table.Cost(record)
+	if cost == nil || cost.Source != coreusage.CostSourceConfig || math.Abs(cost.Total-1.5) > 1e-9 {
+		t.Fatalf("override cost = %+v, want total 1.5 from config", cost)
+	}
+
+	record.Cached = true
+	if cost = table.Cost(record); cost == nil || cost.Total != 0 || cost.Source != coreusage.CostSourceResponseCache {
+		t.Fatalf("cached cost = %+v, want zero", cost)
+	}
+
+	if cost = table.Cost(coreusage.Record{Provider: "acme", Model: "unknown-model"}); cost != nil {
+		t.Fatalf("unpriced model cost = %+v, want nil", cost)
+	}
+}
@@ -76,6 +76,8 @@ func (p *usageQueuePlugin) HandleUsage(ctx context.Context, record coreusage.Rec
 		CacheReadTokensPresent: true,
 		CacheCreationTokens:    usageDetail.CacheCreationTokens,
 		TotalTokens:            usageDetail.TotalTokens,
+		InputAudioTokens:       usageDetail.InputAudioTokens,
+		OutputAudioTokens:      usageDetail.OutputAudioTokens,
 	}
 
 	failed := record.Failed
@@ -97,6 +99,9 @@ func (p *usageQueuePlugin) HandleUsage(ctx context.Context, record coreusage.Rec
 		Tokens:          tokens,
 		Failed:          failed,
 		Generate:        coreusage.GenerateEnabled(record.Generate),
+		Cached:          record.Cached,
+		FallbackHop:     record.FallbackHop,
+		HedgeAttempt:    record.HedgeAttempt,
 		Fail:            fail,
 		ResponseHeaders: record.ResponseHeaders,
 	}
@@ -105,6 +110,7 @@ func (p *usageQueuePlugin) HandleUsage(ctx context.Context, record coreusage.Rec
 		requestDetail:       detail,
 		AccountingVersion:   coreusage.TokenAccountingSchemaVersion,
 		TokenBreakdown:      usageDetail.TokenBreakdown,
+		Cost:                record.Cost,
 		Provider:            provider,
 		ExecutorType:        executorType,
 		Model:               modelName,
@@ -127,6 +133,7 @@ type queuedUsageDetail struct {
 	requestDetail
 	AccountingVersion   int                      `json:"accounting_version"`
 	TokenBreakdown      coreusage.TokenBreakdown `json:"token_breakdown"`
+	Cost                *coreusage.Cost          `json:"cost,omitempty"`
 	Provider            string                   `json:"provider"`
 	ExecutorType        string                   `json:"executor_type"`
 	Model               string                   `json:"model"`
@@ -153,6 +160,9 @@ type requestDetail struct {
 	Tokens          tokenStats  `json:"tokens"`
 	Failed          bool        `json:"failed"`
 	Generate        bool        `json:"generate"`
+	Cached          bool        `json:"cached,omitempty"`
+	FallbackHop     int         `json:"fallback_hop,omitempty"`
+	HedgeAttempt    int         `json:"hedge_attempt,omitempty"`
 	Fail            failDetail  `json:"fail"`
 	ResponseHeaders http.Header `json:"response_headers,omitempty"`
 }
@@ -166,6 +176,8 @@ type tokenStats struct {
 	CacheReadTokensPresent bool  `json:"cache_read_tokens_present"`
 	CacheCreationTokens    int64 `json:"cache_creation_tokens"`
 	TotalTokens            int64 `json:"total_tokens"`
+	InputAudioTokens       int64 `json:"input_audio_tokens,omitempty"`
+	OutputAudioTokens      int64 `json:"output_audio_tokens,omitempty"`
 }
 
 type failDetail struct {
@@ -154,6 +154,35 @@ func TestUsageQueuePluginPayloadDefaultsGenerateTrueWhenOmitted(t *testing.T) {
 	})
 }
 
+func TestUsageQueuePluginPayloadIncludesCost(t *testing.T) {
+	withEnabledQueue(t, func() {
+		ctx := internallogging.WithResponseStatusHolder(context.Background())
+		internallogging.SetResponseStatus(ctx, http.StatusOK)
+
+		(&usageQueuePlugin{}).HandleUsage(ctx, coreusage.Record{
+			Provider: "openai",
+			Model:    "gpt-5.4",
+			Detail:   coreusage.Detail{InputTokens: 1000, TotalTokens: 1000},
+			Cost:     &coreusage.Cost{Input: 0.002, Total: 0.002, Source: coreusage.CostSourceConfig},
+		})
+		payload := popSinglePayload(t)
+		var cost coreusage.Cost
+		if errUnmarshal := json.Unmarshal(payload["cost"], &cost); errUnmarshal != nil {
+			t.Fatalf("decode cost: %v", errUnmarshal)
+		}
+		if cost.Total != 0.002 || cost.Input != 0.002 || cost.Source != coreusage.CostSourceConfig {
+			t.Fatalf("cost = %+v", cost)
+		}
+
+		(&usageQueuePlugin{}).HandleUsage(ctx, coreusage.Record{
+			Provider: "openai",
+			Model:    "unpriced",
+			Detail:   coreusage.Detail{InputTokens: 1, TotalTokens: 1},
+		})
+		requireMissingField(t, popSinglePayload(t), "cost")
+	})
+}
+
 func TestUsageQueuePluginPreservesLegacyCachedOnlyUsage(t *testing.T) {
 	withEnabledQueue(t, func() {
 		ctx := internallogging.WithResponseStatusHolder(context.Background())
@@ -0,0 +1,500 @@
+package registry
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Overlay merge modes accepted by ModelCatalogSource.Mode.
+const (
+	// ModelCatalogOverlayMerge merges overlay models into the base catalog by ID:
+	// top-level fields of an overlay model replace the fields of the base model with
+	// the same ID, and models the base does not list are appended.
+	ModelCatalogOverlayMerge = "merge"
+	// ModelCatalogOverlayReplace replaces every section the overlay contains.
+	ModelCatalogOverlayReplace = "replace"
+)
+
+const (
+	// maxModelsCatalogSize bounds one catalog or signature document.
+	maxModelsCatalogSize = 32 << 20
+	// modelCatalogSignatureSuffix is appended to a document location to find its
+	// detached signature when no signature location is configured.
+	modelCatalogSignatureSuffix = ".sig"
+	// modelCatalogEmbeddedSource names the catalog compiled into the binary.
+	modelCatalogEmbeddedSource = "embedded"
+)
+
+// ModelCatalogSource locates one models catalog document. Exactly one of URL and File is set.
+type ModelCatalogSource struct {
+	// URL is fetched with an HTTP GET.
+	URL string
+	// File is read from the local filesystem.
+	File string
+	// Signature locates the detached ed25519 signature of the document, as a URL or a
+	// file path. Empty uses the document location with ".sig" appended.
+	Signature string
+	// Mode is the merge mode of an overlay. It is ignored for base sources.
+	Mode string
+}
+
+// Location returns the URL or file path of the document.
+func (s ModelCatalogSource) Location() string {
+	if s.URL != "" {
+		return s.URL
+	}
+	return s.File
+}
+
+// ModelCatalogSettings configures where the models catalog is loaded from.
+type ModelCatalogSettings struct {
+	// Sources are tried in order; the first one that loads, verifies and validates
+	// becomes the base catalog. Empty uses the default upstream URLs.
+	Sources []ModelCatalogSource
+	// Overlays are applied on top of the base catalog in order. A refresh fails when
+	// any overlay cannot be loaded, so the catalog is never served without them.
+	Overlays []ModelCatalogSource
+	// PublicKeys verify detached catalog signatures. When set, every source and
+	// overlay must carry a valid signature from one of the keys.
+	PublicKeys []ed25519.PublicKey
+	// RefreshInterval is the periodic refresh interval. Zero uses three hours.
+	RefreshInterval time.Duration
+	// Error reports invalid settings. Every refresh fails with it and the active
+	// catalog is kept, so a broken verification setup never loads unverified catalogs.
+	Error string
+}
+
+// ModelCatalogLayer describes one document of the active catalog.
+type ModelCatalogLayer struct {
+	// Source is the URL or file the document was loaded from, or "embedded".
+	Source string `json:"source"`
+	// Version is the "version" field of the document, or a digest of its content.
+	Version string `json:"version"`
+	// Mode is the merge mode of an overlay.
+	Mode string `json:"mode,omitempty"`
+	// Verified reports whether a detached signature was checked.
+	Verified bool `json:"verified"`
+	// KeyID identifies the public key that verified the signature.
+	KeyID string `json:"key_id,omitempty"`
+}
+
+// ModelCatalogStatus describes the active models catalog and its refresh state.
+type ModelCatalogStatus struct {
+	// Version identifies the active catalog including its overlays.
+	Version string `json:"version"`
+	// Base is the document the catalog was built from.
+	Base ModelCatalogLayer `json:"base"`
+	// Overlays are the documents merged on top of Base, in order.
+	Overlays []ModelCatalogLayer `json:"overlays,omitempty"`
+	// Models counts the model definitions of the active catalog.
+	Models int `json:"models"`
+	// LoadedAt is when the active catalog was loaded.
+	LoadedAt time.Time `json:"loaded_at"`
+	// AutoRefresh reports whether the background updater is running.
+	AutoRefresh bool `json:"auto_refresh"`
+	// RefreshIntervalSeconds is the periodic refresh interval.
+	RefreshIntervalSeconds int64 `json:"refresh_interval_seconds"`
+	// Sources lists the configured base source locations in order.
+	Sources []string `json:"sources"`
+	// SignatureRequired reports whether catalogs must carry a valid signature.
+	SignatureRequired bool `json:"signature_required"`
+	// SettingsError reports invalid model-catalog settings that block refreshes.
+	SettingsError string `json:"settings_error,omitempty"`
+	// LastAttemptAt is when a refresh was last attempted.
+	LastAttemptAt time.Time `json:"last_attempt_at,omitzero"`
+	// NextRefreshAt is when the next periodic refresh is due.
+	NextRefreshAt time.Time `json:"next_refresh_at,omitzero"`
+	// LastError holds the error of the last failed refresh. The active catalog is kept.
+	LastError string `json:"last_error,omitempty"`
+}
+
+type modelCatalogState struct {
+	mu       sync.Mutex
+	settings ModelCatalogSettings
+	status   ModelCatalogStatus
+	// wake is signalled when the settings change so the updater refreshes at once.
+	wake chan struct{}
+}
+
+var modelCatalog = &modelCatalogState{wake: make(chan struct{}, 1)}
+
+// ConfigureModelCatalog replaces the catalog settings. When the updater is running
+// and the settings changed, the catalog is refreshed from the new sources at once.
+func ConfigureModelCatalog(settings ModelCatalogSettings) {
+	modelCatalog.mu.Lock()
+	if reflect.DeepEqual(modelCatalog.settings, settings) {
+		modelCatalog.mu.Unlock()
+		return
+	}
+	modelCatalog.settings = settings
+	modelCatalog.mu.Unlock()
+	select {
+	case modelCatalog.wake <- struct{}{}:
+	default:
+	}
+}
+
+// GetModelCatalogStatus returns the state of the active models catalog.
+func GetModelCatalogStatus() ModelCatalogStatus {
+	modelCatalog.mu.Lock()
+	defer modelCatalog.mu.Unlock()
+	status := modelCatalog.status
+	status.Overlays = append([]ModelCatalogLayer(nil), status.Overlays...)
+	status.RefreshIntervalSeconds = int64(modelCatalog.settings.refreshInterval() / time.Second)
+	status.SignatureRequired = len(modelCatalog.settings.PublicKeys) > 0
+	status.SettingsError = modelCatalog.settings.Error
+	status.Sources = make([]string, 0, len(modelCatalog.settings.Sources))
+	for _, source := range modelCatalog.settings.sources() {
+		status.Sources = append(status.Sources, source.Location())
+	}
+	return status
+}
+
+func currentModelCatalogSettings() ModelCatalogSettings {
+	modelCatalog.mu.Lock()
+	defer modelCatalog.mu.Unlock()
+	return modelCatalog.settings
+}
+
+func (s ModelCatalogSettings) refreshInterval() time.Duration {
+	if s.RefreshInterval > 0 {
+		return s.RefreshInterval
+	}
+	return modelsRefreshInterval
+}
+
+func (s ModelCatalogSettings) sources() []ModelCatalogSource {
+	if len(s.Sources) > 0 {
+		return s.Sources
+	}
+	sources := make([]ModelCatalogSource, 0, len(modelsURLs))
+	for _, url := range modelsURLs {
+		sources = append(sources, ModelCatalogSource{URL: url})
+	}
+	return sources
+}
+
+// loadedModelCatalog is a catalog assembled from its base and overlay documents.
+type loadedModelCatalog struct {
+	data     *staticModelsJSON
+	base     ModelCatalogLayer
+	overlays []ModelCatalogLayer
+}
+
+func (c *loadedModelCatalog) version() string {
+	if len(c.overlays) == 0 {
+		return c.base.Version
+	}
+	sum := sha256.New()
+	for _, overlay := range c.overlays {
+		_, _ = io.WriteString(sum, overlay.Source+"\x00"+overlay.Version+"\x00")
+	}
+	return c.base.Version + "+" + hex.EncodeToString(sum.Sum(nil))[:12]
+}
+
+// loadModelCatalog builds a catalog from the configured sources and overlays. When
+// every base source fails, overlays are applied to the embedded catalog as long as
+// no source was loaded before; otherwise the refresh fails and the current catalog stays.
+func loadModelCatalog(ctx context.Context, settings ModelCatalogSettings) (*loadedModelCatalog, error) {
+	if settings.Error != "" {
+		return nil, fmt.Errorf("invalid model-catalog settings: %s", settings.Error)
+	}
+	client := &http.Client{Timeout: modelsFetchTimeout}
+	loaded := &loadedModelCatalog{}
+	var errs []error
+	for _, source := range settings.sources() {
+		data, layer, errRead := readModelCatalogDocument(ctx, client, source, settings.PublicKeys)
+		if errRead != nil {
+			errs = append(errs, errRead)
+			continue
+		}
+		var parsed staticModelsJSON
+		if errDecode := json.Unmarshal(data, &parsed); errDecode != nil {
+			errs = append(errs, fmt.Errorf("%s: decode models catalog: %w", layer.Source, errDecode))
+			continue
+		}
+		if errValidate := validateModelsCatalog(&parsed); errValidate != nil {
+			errs = append(errs, fmt.Errorf("%s: validate models catalog: %w", layer.Source, errValidate))
+			continue
+		}
+		loaded.data, loaded.base = &parsed, layer
+		break
+	}
+	if loaded.data == nil {
+		errSources := fmt.Errorf("all model catalog sources failed: %w", errors.Join(errs...))
+		if len(settings.Overlays) == 0 || GetModelCatalogStatus().Base.Source != modelCatalogEmbeddedSource {
+			return nil, errSources
+		}
+		var parsed staticModelsJSON
+		if errDecode := json.Unmarshal(embeddedModelsJSON, &parsed); errDecode != nil {
+			return nil, errors.Join(errSources, fmt.Errorf("embedded: decode models catalog: %w", errDecode))
+		}
+		loaded.data = &parsed
+		loaded.base = ModelCatalogLayer{Source: modelCatalogEmbeddedSource, Version: modelCatalogDocumentVersion(embeddedModelsJSON)}
+	}
+
+	for _, overlay := range settings.Overlays {
+		data, layer, errRead := readModelCatalogDocument(ctx, client, overlay, settings.PublicKeys)
+		if errRead != nil {
+			return nil, fmt.Errorf("overlay %w", errRead)
+		}
+		layer.Mode = normalizeModelCatalogOverlayMode(overlay.Mode)
+		if errApply := applyModelCatalogOverlay(loaded.data, data, layer.Mode); errApply != nil {
+			return nil, fmt.Errorf("overlay %s: %w", layer.Source, errApply)
+		}
+		loaded.overlays = append(loaded.overlays, layer)
+	}
+	if len(loaded.overlays) > 0 {
+		if errValidate := validateModelsCatalog(loaded.data); errValidate != nil {
+			return nil, fmt.Errorf("validate catalog with overlays: %w", errValidate)
+		}
+	}
+	return loaded, nil
+}
+
+// readModelCatalogDocument reads a document and, when keys are configured, verifies
+// its detached signature.
+func readModelCatalogDocument(ctx context.Context, client *http.Client, source ModelCatalogSource, keys []ed25519.PublicKey) ([]byte, ModelCatalogLayer, error) {
+	layer := ModelCatalogLayer{Source: source.Location()}
+	data, errRead := readModelCatalogLocation(ctx, client, source.URL, source.File)
+	if errRead != nil {
+		return nil, layer, fmt.Errorf("%s: %w", layer.Source, errRead)
+	}
+	if len(keys) > 0 {
+		signatureURL, signatureFile := modelCatalogSignatureLocation(source)
+		signature, errSignature := readModelCatalogLocation(ctx, client, signatureURL, signatureFile)
+		if errSignature != nil {
+			return nil, layer, fmt.Errorf("%s: read signature: %w", layer.Source, errSignature)
+		}
+		keyID, errVerify := verifyModelCatalogSignature(data, signature, keys)
+		if errVerify != nil {
+			return nil, layer, fmt.Errorf("%s: %w", layer.Source, errVerify)
+		}
+		layer.Verified, layer.KeyID = true, keyID
+	}
+	layer.Version = modelCatalogDocumentVersion(data)
+	return data, layer, nil
+}
+
+func readModelCatalogLocation(ctx context.Context, client *http.Client, url, file string) ([]byte, error) {
+	var reader io.Reader
+	if url != "" {
+		reqCtx, cancel := context.WithTimeout(ctx, modelsFetchTimeout)
+		defer cancel()
+		req, errRequest := http.NewRequestWithContext(reqCtx, http.MethodGet, url, nil)
+		if errRequest != nil {
+			return nil, errRequest
+		}
+		resp, errDo := client.Do(req)
+		if errDo != nil {
+			return nil, errDo
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		reader = resp.Body
+	} else {
+		f, errOpen := os.Open(file)
+		if errOpen != nil {
+			return nil, errOpen
+		}
+		defer func() { _ = f.Close() }()
+		reader = f
+	}
+	data, errRead := io.ReadAll(io.LimitReader(reader, maxModelsCatalogSize+1))
+	if errRead != nil {
+		return nil, errRead
+	}
+	if len(data) > maxModelsCatalogSize {
+		return nil, fmt.Errorf("document exceeds %d bytes", maxModelsCatalogSize)
+	}
+	return data, nil
+}
+
+func modelCatalogSignatureLocation(source ModelCatalogSource) (url, file string) {
+	signature := strings.TrimSpace(source.Signature)
+	if signature == "" {
+		if source.URL != "" {
+			return source.URL + modelCatalogSignatureSuffix, ""
+		}
+		return "", source.File + modelCatalogSignatureSuffix
+	}
+	if strings.HasPrefix(signature, "http://") || strings.HasPrefix(signature, "https://") {
+		return signature, ""
+	}
+	return "", signature
+}
+
+// verifyModelCatalogSignature checks a detached ed25519 signature, given raw or
+// base64 encoded, against every key and returns the ID of the key that matched.
+func verifyModelCatalogSignature(data, signature []byte, keys []ed25519.PublicKey) (string, error) {
+	if len(signature) != ed25519.SignatureSize {
+		decoded, errDecode := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if errDecode != nil || len(decoded) != ed25519.SignatureSize {
+			return "", errors.New("malformed signature: expected 64 raw or base64 encoded bytes")
+		}
+		signature = decoded
+	}
+	for _, key := range keys {
+		if len(key) == ed25519.PublicKeySize && ed25519.Verify(key, data, signature) {
+			return ModelCatalogKeyID(key), nil
+		}
+	}
+	return "", errors.New("signature verification failed")
+}
+
+// ModelCatalogKeyID returns a short fingerprint of a catalog public key.
+func ModelCatalogKeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// modelCatalogDocumentVersion returns the "version" field of a catalog document, or
+// a digest of its content when the document has none.
+func modelCatalogDocumentVersion(data []byte) string {
+	var header struct {
+		Version any `json:"version"`
+	}
+	if errDecode := json.Unmarshal(data, &header); errDecode == nil && header.Version != nil {
+		if version := strings.TrimSpace(fmt.Sprint(header.Version)); version != "" {
+			return version
+		}
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])[:16]
+}
+
+func normalizeModelCatalogOverlayMode(mode string) string {
+	if strings.EqualFold(strings.TrimSpace(mode), ModelCatalogOverlayReplace) {
+		return ModelCatalogOverlayReplace
+	}
+	return ModelCatalogOverlayMerge
+}
+
+// applyModelCatalogOverlay merges an overlay document into data. Sections the overlay
+// does not contain are left untouched.
+func applyModelCatalogOverlay(data *staticModelsJSON, overlay []byte, mode string) error {
+	var sections map[string]json.RawMessage
+	if errDecode := json.Unmarshal(overlay, &sections); errDecode != nil {
+		return fmt.Errorf("decode overlay: %w", errDecode)
+	}
+	for _, section := range data.sectionRefs() {
+		raw, ok := sections[section.name]
+		if !ok {
+			continue
+		}
+		var items []json.RawMessage
+		if errDecode := json.Unmarshal(raw, &items); errDecode != nil {
+			return fmt.Errorf("decode %s: %w", section.name, errDecode)
+		}
+		if mode == ModelCatalogOverlayReplace {
+			models := make([]*ModelInfo, 0, len(items))
+			for i, item := range items {
+				var model ModelInfo
+				if errDecode := json.Unmarshal(item, &model); errDecode != nil {
+					return fmt.Errorf("decode %s[%d]: %w", section.name, i, errDecode)
+				}
+				models = append(models, &model)
+			}
+			*section.models = models
+			continue
+		}
+		for i, item := range items {
+			merged, errMerge := mergeModelCatalogEntry(*section.models, item)
+			if errMerge != nil {
+				return fmt.Errorf("%s[%d]: %w", section.name, i, errMerge)
+			}
+			*section.models = merged
+		}
+	}
+	return nil
+}
+
+// mergeModelCatalogEntry overlays one raw model onto models by ID.
+func mergeModelCatalogEntry(models []*ModelInfo, item json.RawMessage) ([]*ModelInfo, error) {
+	var fields map[string]json.RawMessage
+	if errDecode := json.Unmarshal(item, &fields); errDecode != nil {
+		return nil, fmt.Errorf("decode model: %w", errDecode)
+	}
+	var id string
+	if errID := json.Unmarshal(fields["id"], &id); errID != nil || strings.TrimSpace(id) == "" {
+		return nil, errors.New("overlay model has no id")
+	}
+	for i, model := range models {
+		if model == nil || model.ID != id {
+			continue
+		}
+		base, errEncode := json.Marshal(model)
+		if errEncode != nil {
+			return nil, fmt.Errorf("encode %q: %w", id, errEncode)
+		}
+		var baseFields map[string]json.RawMessage
+		if errDecode := json.Unmarshal(base, &baseFields); errDecode != nil {
+			return nil, fmt.Errorf("decode %q: %w", id, errDecode)
+		}
+		for key, value := range fields {
+			baseFields[key] = value
+		}
+		mergedRaw, errEncode := json.Marshal(baseFields)
+		if errEncode != nil {
+			return nil, fmt.Errorf("encode %q: %w", id, errEncode)
+		}
+		var merged ModelInfo
+		if errDecode := json.Unmarshal(mergedRaw, &merged); errDecode != nil {
+			return nil, fmt.Errorf("decode %q: %w", id, errDecode)
+		}
+		models[i] = &merged
+		return models, nil
+	}
+	var added ModelInfo
+	if errDecode := json.Unmarshal(item, &added); errDecode != nil {
+		return nil, fmt.Errorf("decode %q: %w", id, errDecode)
+	}
+	return append(models, &added), nil
+}
+
+// recordModelCatalogLoad publishes the status of a successfully loaded catalog.
+func recordModelCatalogLoad(loaded *loadedModelCatalog, now time.Time) {
+	models := 0
+	for _, section := range loaded.data.sectionRefs() {
+		models += len(*section.models)
+	}
+	modelCatalog.mu.Lock()
+	modelCatalog.status.Version = loaded.version()
+	modelCatalog.status.Base = loaded.base
+	modelCatalog.status.Overlays = loaded.overlays
+	modelCatalog.status.Models = models
+	modelCatalog.status.LoadedAt = now
+	modelCatalog.status.LastAttemptAt = now
+	modelCatalog.status.LastError = ""
+	modelCatalog.mu.Unlock()
+}
+
+// recordModelCatalogFailure publishes a failed refresh. The active catalog is kept.
+func recordModelCatalogFailure(errRefresh error, now time.Time) {
+	modelCatalog.mu.Lock()
+	modelCatalog.status.LastAttemptAt = now
+	modelCatalog.status.LastError = errRefresh.Error()
+	modelCatalog.mu.Unlock()
+}
+
+func setModelCatalogRefreshSchedule(autoRefresh bool, next time.Time) {
+	modelCatalog.mu.Lock()
+	modelCatalog.status.AutoRefresh = autoRefresh
+	modelCatalog.status.NextRefreshAt = next
+	modelCatalog.mu.Unlock()
+}
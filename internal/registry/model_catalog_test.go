@@ -0,0 +1,149 @@
+package registry
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSignedCatalogFile(t *testing.T, dir, name string, data []byte, key ed25519.PrivateKey) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if errWrite := os.WriteFile(path, data, 0o600); errWrite != nil {
+		t.Fatalf("write %s: %v", name, errWrite)
+	}
+	if key != nil {
+		signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+		if errWrite := os.WriteFile(path+modelCatalogSignatureSuffix, []byte(signature+"\n"), 0o600); errWrite != nil {
+			t.Fatalf("write %s signature: %v", name, errWrite)
+		}
+	}
+	return path
+}
+
+func TestLoadModelCatalogVerifiesSignaturesInSourceOrder(t *testing.T) {
+	public, private, errKey := ed25519.GenerateKey(rand.Reader)
+	if errKey != nil {
+		t.Fatalf("generate key: %v", errKey)
+	}
+	_, otherPrivate, errKey := ed25519.GenerateKey(rand.Reader)
+	if errKey != nil {
+		t.Fatalf("generate key: %v", errKey)
+	}
+	dir := t.TempDir()
+	forged := writeSignedCatalogFile(t, dir, "forged.json", embeddedModelsJSON, otherPrivate)
+	unsigned := writeSignedCatalogFile(t, dir, "unsigned.json", embeddedModelsJSON, nil)
+	signed := writeSignedCatalogFile(t, dir, "signed.json", embeddedModelsJSON, private)
+
+	loaded, errLoad := loadModelCatalog(context.Background(), ModelCatalogSettings{
+		Sources:    []ModelCatalogSource{{File: forged}, {File: unsigned}, {File: signed}},
+		PublicKeys: []ed25519.PublicKey{public},
+	})
+	if errLoad != nil {
+		t.Fatalf("loadModelCatalog() error = %v", errLoad)
+	}
+	if loaded.base.Source != signed || !loaded.base.Verified || loaded.base.KeyID != ModelCatalogKeyID(public) {
+		t.Fatalf("base layer = %+v, want verified %s", loaded.base, signed)
+	}
+
+	if _, errLoad = loadModelCatalog(context.Background(), ModelCatalogSettings{
+		Sources:    []ModelCatalogSource{{File: forged}},
+		PublicKeys: []ed25519.PublicKey{public},
+	}); errLoad == nil || !strings.Contains(errLoad.Error(), "signature verification failed") {
+		t.Fatalf("forged catalog error = %v, want signature verification failure", errLoad)
+	}
+}
+
+func TestLoadModelCatalogFromURLWithSignatureLocation(t *testing.T) {
+	public, private, errKey := ed25519.GenerateKey(rand.Reader)
+	if errKey != nil {
+		t.Fatalf("generate key: %v", errKey)
+	}
+	document := []byte(`{"version":"mirror-42","claude":[{"id":"claude-mirror","object":"model"}]}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/models.json":
+			_, _ = w.Write(document)
+		case "/sigs/models.sig":
+			_, _ = w.Write(ed25519.Sign(private, document))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	loaded, errLoad := loadModelCatalog(context.Background(), ModelCatalogSettings{
+		Sources:    []ModelCatalogSource{{URL: server.URL + "/models.json", Signature: server.URL + "/sigs/models.sig"}},
+		PublicKeys: []ed25519.PublicKey{public},
+	})
+	if errLoad != nil {
+		t.Fatalf("loadModelCatalog() error = %v", errLoad)
+	}
+	if loaded.version() != "mirror-42" || len(loaded.data.Claude) != 1 || loaded.data.Claude[0].ID != "claude-mirror" {
+		t.Fatalf("loaded catalog version = %q, claude = %+v", loaded.version(), loaded.data.Claude)
+	}
+}
+
+func TestLoadModelCatalogAppliesOverlays(t *testing.T) {
+	dir := t.TempDir()
+	base := writeSignedCatalogFile(t, dir, "base.json", []byte(`{
+		"claude":[{"id":"claude-a","object":"model","display_name":"A","context_length":1000},{"id":"claude-b","object":"model"}],
+		"gemini":[{"id":"gemini-a","object":"model"}]
+	}`), nil)
+	merge := writeSignedCatalogFile(t, dir, "merge.json", []byte(`{
+		"claude":[{"id":"claude-a","display_name":"A (internal)"},{"id":"claude-internal","object":"model"}]
+	}`), nil)
+	replace := writeSignedCatalogFile(t, dir, "replace.json", []byte(`{"gemini":[{"id":"gemini-internal","object":"model"}]}`), nil)
+
+	loaded, errLoad := loadModelCatalog(context.Background(), ModelCatalogSettings{
+		Sources: []ModelCatalogSource{{File: base}},
+		Overlays: []ModelCatalogSource{
+			{File: merge},
+			{File: replace, Mode: ModelCatalogOverlayReplace},
+		},
+	})
+	if errLoad != nil {
+		t.Fatalf("loadModelCatalog() error = %v", errLoad)
+	}
+	claude := loaded.data.Claude
+	if len(claude) != 3 || claude[0].DisplayName != "A (internal)" || claude[0].ContextLength != 1000 || claude[2].ID != "claude-internal" {
+		t.Fatalf("merged claude section = %+v", claude)
+	}
+	if gemini := loaded.data.Gemini; len(gemini) != 1 || gemini[0].ID != "gemini-internal" {
+		t.Fatalf("replaced gemini section = %+v", gemini)
+	}
+	if len(loaded.overlays) != 2 || loaded.overlays[0].Mode != ModelCatalogOverlayMerge || loaded.overlays[1].Mode != ModelCatalogOverlayReplace {
+		t.Fatalf("overlay layers = %+v", loaded.overlays)
+	}
+	if !strings.HasPrefix(loaded.version(), loaded.base.Version+"+") {
+		t.Fatalf("version = %q, want base version with overlay digest", loaded.version())
+	}
+
+	if _, errLoad = loadModelCatalog(context.Background(), ModelCatalogSettings{
+		Sources:  []ModelCatalogSource{{File: base}},
+		Overlays: []ModelCatalogSource{{File: filepath.Join(dir, "missing.json")}},
+	}); errLoad == nil {
+		t.Fatal("missing overlay loaded without error")
+	}
+
+	duplicate := writeSignedCatalogFile(t, dir, "duplicate.json", []byte(`{"claude":[{"id":"claude-a"},{"id":"claude-a"}]}`), nil)
+	if _, errLoad = loadModelCatalog(context.Background(), ModelCatalogSettings{
+		Sources:  []ModelCatalogSource{{File: base}},
+		Overlays: []ModelCatalogSource{{File: duplicate, Mode: ModelCatalogOverlayReplace}},
+	}); errLoad == nil {
+		t.Fatal("overlay producing duplicate model IDs loaded without error")
+	}
+}
+
+func TestLoadModelCatalogRejectsInvalidSettings(t *testing.T) {
+	if _, errLoad := loadModelCatalog(context.Background(), ModelCatalogSettings{Error: "public-keys[0]: bad key"}); errLoad == nil {
+		t.Fatal("invalid settings loaded a catalog")
+	}
+}
@@ -15,8 +15,12 @@ const (
 	xaiBuiltinVideoModelID        = "grok-imagine-video"
 	xaiBuiltinVideo15ModelID      = "grok-imagine-video-1.5"
 	xaiBuiltinVideo15PreviewID    = "grok-imagine-video-1.5-preview"
+	geminiBuiltinEmbeddingModelID = "gemini-embedding-001"
 )
 
+// embeddingGenerationMethods are the Gemini generation methods advertised by embedding models.
+var embeddingGenerationMethods = []string{"embedContent", "batchEmbedContents"}
+
 // staticModelsJSON mirrors the top-level structure of models.json.
 type staticModelsJSON struct {
 	Claude      []*ModelInfo `json:"claude"`
@@ -39,12 +43,12 @@ func GetClaudeModels() []*ModelInfo {
 
 // GetGeminiModels returns the standard Gemini model definitions.
 func GetGeminiModels() []*ModelInfo {
-	return cloneModelInfos(getModels().Gemini)
+	return WithGeminiEmbeddingBuiltins(cloneModelInfos(getModels().Gemini))
 }
 
 // GetGeminiVertexModels returns Gemini model definitions for Vertex AI.
 func GetGeminiVertexModels() []*ModelInfo {
-	return cloneModelInfos(getModels().Vertex)
+	return WithGeminiEmbeddingBuiltins(cloneModelInfos(getModels().Vertex))
 }
 
 // GetAIStudioModels returns model definitions for AI Studio.
@@ -125,6 +129,41 @@ func WithXAIBuiltins(models []*ModelInfo) []*ModelInfo {
 	return upsertModelInfos(models, xaiBuiltinImageModelInfo(), xaiBuiltinImageQualityModelInfo(), xaiBuiltinImage20ModelInfo(), xaiBuiltinVideoModelInfo(), xaiBuiltinVideo15ModelInfo(), xaiBuiltinVideo15PreviewModelInfo())
 }
 
+// WithGeminiEmbeddingBuiltins injects the Gemini embedding model served by the
+// Gemini API and Vertex AI into the provided slice.
+func WithGeminiEmbeddingBuiltins(models []*ModelInfo) []*ModelInfo {
+	return upsertModelInfos(models, geminiBuiltinEmbeddingModelInfo())
+}
+
+// IsEmbeddingModel reports whether info describes a model that can serve /v1/embeddings.
+func IsEmbeddingModel(info *ModelInfo) bool {
+	if info == nil {
+		return false
+	}
+	if info.Type == OpenAIEmbeddingModelType {
+		return true
+	}
+	for _, method := range info.SupportedGenerationMethods {
+		for _, embedMethod := range embeddingGenerationMethods {
+			if strings.EqualFold(method, embedMethod) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsEmbeddingModelName reports whether an upstream Gemini or Vertex model identifier
+// names a text embedding model (e.g. gemini-embedding-001, text-embedding-005).
+func IsEmbeddingModelName(name string) bool {
+	return strings.Contains(strings.ToLower(strings.TrimSpace(name)), "embedding")
+}
+
+// EmbeddingGenerationMethods returns the generation methods used to mark embedding models.
+func EmbeddingGenerationMethods() []string {
+	return append([]string(nil), embeddingGenerationMethods...)
+}
+
 func normalizeAntigravityCapabilityModelID(modelID string) string {
 	modelID = strings.ToLower(strings.TrimSpace(modelID))
 	if open := strings.LastIndex(modelID, "("); open >= 0 && strings.HasSuffix(modelID, ")") {
@@ -157,6 +196,23 @@ func codexBuiltinImageModelInfo() *ModelInfo {
 	}
 }
 
+func geminiBuiltinEmbeddingModelInfo() *ModelInfo {
+	return &ModelInfo{
+		ID:                         geminiBuiltinEmbeddingModelID,
+		Object:                     "model",
+		Created:                    1752019200, // 2025-07-09
+		OwnedBy:                    "google",
+		Type:                       "gemini",
+		DisplayName:                "Gemini Embedding 001",
+		Name:                       "models/" + geminiBuiltinEmbeddingModelID,
+		Version:                    "001",
+		Description:                "Gemini text embedding model.",
+		InputTokenLimit:            2048,
+		OutputTokenLimit:           1,
+		SupportedGenerationMethods: EmbeddingGenerationMethods(),
+	}
+}
+
 func xaiBuiltinImageModelInfo() *ModelInfo {
 	return &ModelInfo{
 		ID:          xaiBuiltinImageModelID,
@@ -354,6 +410,9 @@ func LookupStaticModelInfo(modelID string) *ModelInfo {
 			}
 		}
 	}
+	if modelID == geminiBuiltinEmbeddingModelID {
+		return geminiBuiltinEmbeddingModelInfo()
+	}
 
 	return nil
 }
@@ -35,6 +35,30 @@ func TestGeminiVertexModelsUseFlashLiteReleaseID(t *testing.T) {
 	t.Fatalf("Vertex models do not contain %q", releaseID)
 }
 
+func TestGeminiAndVertexModelsIncludeEmbeddingBuiltin(t *testing.T) {
+	for name, models := range map[string][]*ModelInfo{"gemini": GetGeminiModels(), "vertex": GetGeminiVertexModels()} {
+		found := false
+		for _, model := range models {
+			if model == nil || !IsEmbeddingModel(model) {
+				continue
+			}
+			if model.ID != geminiBuiltinEmbeddingModelID {
+				t.Fatalf("%s: unexpected embedding model %q", name, model.ID)
+			}
+			found = true
+		}
+		if !found {
+			t.Fatalf("%s models do not contain %q", name, geminiBuiltinEmbeddingModelID)
+		}
+	}
+	if !IsEmbeddingModel(&ModelInfo{Type: OpenAIEmbeddingModelType}) {
+		t.Fatal("openai-embedding typed model should be an embedding model")
+	}
+	if IsEmbeddingModel(LookupStaticModelInfo("gemini-2.5-flash-lite")) {
+		t.Fatal("gemini-2.5-flash-lite should not be an embedding model")
+	}
+}
+
 func TestWithXAIBuiltinsIncludesImage20(t *testing.T) {
 	models := WithXAIBuiltins(nil)
 	for _, model := range models {
@@ -0,0 +1,55 @@
+package registry
+
+import (
+	"sync"
+	"time"
+)
+
+// ModelDiscoveryStatus describes the latest upstream catalog fetch of a client with
+// discover-models enabled.
+type ModelDiscoveryStatus struct {
+	// Endpoint is the catalog URL that was fetched.
+	Endpoint string `json:"endpoint"`
+	// FetchedAt is when the last fetch finished, successful or not.
+	FetchedAt time.Time `json:"fetched_at"`
+	// SucceededAt is when the catalog was last fetched successfully.
+	SucceededAt time.Time `json:"succeeded_at,omitempty"`
+	// NextFetchAt is when the catalog is due to be fetched again.
+	NextFetchAt time.Time `json:"next_fetch_at,omitempty"`
+	// Upstream counts the models the upstream listed before include/exclude filtering.
+	Upstream int `json:"upstream"`
+	// Models counts the discovered models registered for the client.
+	Models int `json:"models"`
+	// Error holds the last fetch error. The previously discovered set stays registered.
+	Error string `json:"error,omitempty"`
+}
+
+var (
+	modelDiscoveryMu     sync.RWMutex
+	modelDiscoveryStatus = make(map[string]ModelDiscoveryStatus)
+)
+
+// SetModelDiscoveryStatus records the discovery status of clientID.
+func SetModelDiscoveryStatus(clientID string, status ModelDiscoveryStatus) {
+	if clientID == "" {
+		return
+	}
+	modelDiscoveryMu.Lock()
+	modelDiscoveryStatus[clientID] = status
+	modelDiscoveryMu.Unlock()
+}
+
+// GetModelDiscoveryStatus returns the discovery status of clientID.
+func GetModelDiscoveryStatus(clientID string) (ModelDiscoveryStatus, bool) {
+	modelDiscoveryMu.RLock()
+	status, ok := modelDiscoveryStatus[clientID]
+	modelDiscoveryMu.RUnlock()
+	return status, ok
+}
+
+// ClearModelDiscoveryStatus forgets the discovery status of clientID.
+func ClearModelDiscoveryStatus(clientID string) {
+	modelDiscoveryMu.Lock()
+	delete(modelDiscoveryStatus, clientID)
+	modelDiscoveryMu.Unlock()
+}
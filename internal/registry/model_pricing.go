@@ -0,0 +1,114 @@
+package registry
+
+import (
+	"fmt"
+	"math"
+)
+
+// ModelPricing lists the prices of a model in USD per one million tokens.
+// Optional buckets fall back to a related price when omitted: cached input and
+// cache writes are billed at the input price and reasoning at the output price.
+type ModelPricing struct {
+	// Input is the price of uncached input tokens.
+	Input float64 `json:"input" yaml:"input"`
+	// CachedInput is the price of input tokens read from the prompt cache.
+	CachedInput *float64 `json:"cached_input,omitempty" yaml:"cached-input,omitempty"`
+	// CacheWrite is the price of input tokens written to the prompt cache.
+	CacheWrite *float64 `json:"cache_write,omitempty" yaml:"cache-write,omitempty"`
+	// Output is the price of non-reasoning output tokens.
+	Output float64 `json:"output" yaml:"output"`
+	// Reasoning is the price of reasoning output tokens.
+	Reasoning *float64 `json:"reasoning,omitempty" yaml:"reasoning,omitempty"`
+}
+
+// Clone returns a deep copy of p.
+func (p *ModelPricing) Clone() *ModelPricing {
+	if p == nil {
+		return nil
+	}
+	out := *p
+	out.CachedInput = cloneFloat(p.CachedInput)
+	out.CacheWrite = cloneFloat(p.CacheWrite)
+	out.Reasoning = cloneFloat(p.Reasoning)
+	return &out
+}
+
+// Validate rejects negative and non-finite prices. A nil pricing is valid.
+func (p *ModelPricing) Validate() error {
+	if p == nil {
+		return nil
+	}
+	prices := []struct {
+		name  string
+		value *float64
+	}{
+		{name: "input", value: &p.Input},
+		{name: "cached_input", value: p.CachedInput},
+		{name: "cache_write", value: p.CacheWrite},
+		{name: "output", value: &p.Output},
+		{name: "reasoning", value: p.Reasoning},
+	}
+	for _, price := range prices {
+		if price.value == nil {
+			continue
+		}
+		if *price.value < 0 || math.IsNaN(*price.value) || math.IsInf(*price.value, 0) {
+			return fmt.Errorf("pricing %s must be a non-negative number", price.name)
+		}
+	}
+	return nil
+}
+
+// CachedInputPrice returns the cache read price, defaulting to the input price.
+func (p *ModelPricing) CachedInputPrice() float64 {
+	if p == nil {
+		return 0
+	}
+	if p.CachedInput != nil {
+		return *p.CachedInput
+	}
+	return p.Input
+}
+
+// CacheWritePrice returns the cache write price, defaulting to the input price.
+func (p *ModelPricing) CacheWritePrice() float64 {
+	if p == nil {
+		return 0
+	}
+	if p.CacheWrite != nil {
+		return *p.CacheWrite
+	}
+	return p.Input
+}
+
+// ReasoningPrice returns the reasoning token price, defaulting to the output price.
+func (p *ModelPricing) ReasoningPrice() float64 {
+	if p == nil {
+		return 0
+	}
+	if p.Reasoning != nil {
+		return *p.Reasoning
+	}
+	return p.Output
+}
+
+// LookupModelPricing returns the catalog pricing of a model. The dynamic registry is
+// consulted first so provider-specific definitions win; static catalog definitions
+// are the fallback when the registered model carries no prices.
+func LookupModelPricing(modelID, provider string) *ModelPricing {
+	if info := LookupModelInfo(modelID, provider); info != nil && info.Pricing != nil {
+		return info.Pricing
+	}
+	if info := LookupStaticModelInfo(modelID); info != nil && info.Pricing != nil {
+		return info.Pricing
+	}
+	return nil
+}
+
+func cloneFloat(value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	out := *value
+	return &out
+}
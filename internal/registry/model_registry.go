@@ -18,6 +18,9 @@ import (
 // OpenAIImageModelType marks models that are callable through OpenAI-compatible image endpoints.
 const OpenAIImageModelType = "openai-image"
 
+// OpenAIEmbeddingModelType marks models that are callable through the OpenAI-compatible embeddings endpoint.
+const OpenAIEmbeddingModelType = "openai-embedding"
+
 const (
 	DefaultClaudeMaxInputTokens  = 200000
 	DefaultClaudeMaxOutputTokens = 64000
@@ -73,6 +76,9 @@ type ModelInfo struct {
 	// Config holds model-specific runtime overrides loaded from models.json.
 	Config *ModelConfig `json:"config,omitempty"`
 
+	// Pricing holds the list prices of the model used for usage cost accounting.
+	Pricing *ModelPricing `json:"pricing,omitempty"`
+
 	// UserDefined indicates this model was defined through config file's models[]
 	// array (e.g., openai-compatibility.*.models[], *-api-key.models[]).
 	// UserDefined models have thinking configuration passed through without validation.
@@ -81,6 +87,10 @@ type ModelInfo struct {
 	// IsCompat enables compatibility handling for this configured API-key model.
 	// It is internal metadata and is not exposed in model listings.
 	IsCompat bool `json:"-"`
+
+	// Discovered marks a model registered from the upstream catalog by discover-models
+	// rather than from the configured models list. It is internal metadata.
+	Discovered bool `json:"-"`
 }
 
 // ModelConfig holds optional runtime overrides for a model definition.
@@ -613,6 +623,9 @@ func cloneModelInfo(model *ModelInfo) *ModelInfo {
 		}
 		copyModel.Config = &copyConfig
 	}
+	if model.Pricing != nil {
+		copyModel.Pricing = model.Pricing.Clone()
+	}
 	return &copyModel
 }
 
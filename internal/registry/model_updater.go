@@ -5,8 +5,6 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -68,11 +66,17 @@ func init() {
 	// Load embedded data as fallback on startup.
 	if err := loadModelsFromBytes(embeddedModelsJSON, "embed"); err != nil {
 		log.Warnf("registry: failed to parse embedded models.json (embedded catalog may be incomplete or invalid; continuing startup and will rely on remote model refresh): %v", err)
+	} else {
+		recordModelCatalogLoad(&loadedModelCatalog{
+			data: getModels(),
+			base: ModelCatalogLayer{Source: modelCatalogEmbeddedSource, Version: modelCatalogDocumentVersion(embeddedModelsJSON)},
+		}, time.Now())
 	}
 }
 
-// StartModelsUpdater starts a background updater that fetches models
-// immediately on startup and then refreshes the model catalog every 3 hours.
+// StartModelsUpdater starts a background updater that loads the model catalog from
+// the sources set by ConfigureModelCatalog immediately on startup and then refreshes
+// it periodically (every 3 hours by default) and whenever the settings change.
 // Safe to call multiple times; only one updater will run.
 func StartModelsUpdater(ctx context.Context) {
 	updaterOnce.Do(func() {
@@ -81,21 +85,34 @@ func StartModelsUpdater(ctx context.Context) {
 }
 
 func runModelsUpdater(ctx context.Context) {
+	// Settings configured before startup are covered by the startup refresh.
+	select {
+	case <-modelCatalog.wake:
+	default:
+	}
 	tryStartupRefresh(ctx)
 	periodicRefresh(ctx)
 }
 
 func periodicRefresh(ctx context.Context) {
-	ticker := time.NewTicker(modelsRefreshInterval)
-	defer ticker.Stop()
-	log.Infof("periodic model refresh started (interval=%s)", modelsRefreshInterval)
+	interval := currentModelCatalogSettings().refreshInterval()
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	setModelCatalogRefreshSchedule(true, time.Now().Add(interval))
+	defer setModelCatalogRefreshSchedule(false, time.Time{})
+	log.Infof("periodic model refresh started (interval=%s)", interval)
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case <-timer.C:
 			tryPeriodicRefresh(ctx)
+		case <-modelCatalog.wake:
+			tryRefreshModels(ctx, "model catalog reconfiguration refresh")
 		}
+		interval = currentModelCatalogSettings().refreshInterval()
+		timer.Reset(interval)
+		setModelCatalogRefreshSchedule(true, time.Now().Add(interval))
 	}
 }
 
@@ -115,11 +132,16 @@ func tryStartupRefresh(ctx context.Context) {
 func tryRefreshModels(ctx context.Context, label string) {
 	oldData := getModels()
 
-	parsed, url := fetchModelsFromRemote(ctx)
-	if parsed == nil {
-		log.Warnf("%s: fetch failed from all URLs, keeping current data", label)
+	loaded, errLoad := loadModelCatalog(ctx, currentModelCatalogSettings())
+	if errLoad != nil {
+		recordModelCatalogFailure(errLoad, time.Now())
+		log.Warnf("%s: %v, keeping current data", label, errLoad)
 		return
 	}
+	parsed, url := loaded.data, loaded.base.Source
+	if len(loaded.overlays) > 0 {
+		url = fmt.Sprintf("%s with %d overlay(s)", url, len(loaded.overlays))
+	}
 
 	// Detect changes before updating store.
 	changed := detectChangedProviders(oldData, parsed)
@@ -128,6 +150,7 @@ func tryRefreshModels(ctx context.Context, label string) {
 	modelsCatalogStore.mu.Lock()
 	modelsCatalogStore.data = parsed
 	modelsCatalogStore.mu.Unlock()
+	recordModelCatalogLoad(loaded, time.Now())
 
 	if len(changed) == 0 {
 		log.Infof("%s completed from %s, no changes detected", label, url)
@@ -138,57 +161,6 @@ func tryRefreshModels(ctx context.Context, label string) {
 	notifyModelRefresh(changed)
 }
 
-// fetchModelsFromRemote tries all remote URLs and returns the parsed model catalog
-// along with the URL it was fetched from. Returns (nil, "") if all fetches fail.
-func fetchModelsFromRemote(ctx context.Context) (*staticModelsJSON, string) {
-	client := &http.Client{Timeout: modelsFetchTimeout}
-	for _, url := range modelsURLs {
-		reqCtx, cancel := context.WithTimeout(ctx, modelsFetchTimeout)
-		req, err := http.NewRequestWithContext(reqCtx, "GET", url, nil)
-		if err != nil {
-			cancel()
-			log.Debugf("models fetch request creation failed for %s: %v", url, err)
-			continue
-		}
-
-		resp, err := client.Do(req)
-		if err != nil {
-			cancel()
-			log.Debugf("models fetch failed from %s: %v", url, err)
-			continue
-		}
-
-		if resp.StatusCode != 200 {
-			resp.Body.Close()
-			cancel()
-			log.Debugf("models fetch returned %d from %s", resp.StatusCode, url)
-			continue
-		}
-
-		data, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		cancel()
-
-		if err != nil {
-			log.Debugf("models fetch read error from %s: %v", url, err)
-			continue
-		}
-
-		var parsed staticModelsJSON
-		if err := json.Unmarshal(data, &parsed); err != nil {
-			log.Warnf("models parse failed from %s: %v", url, err)
-			continue
-		}
-		if err := validateModelsCatalog(&parsed); err != nil {
-			log.Warnf("models validate failed from %s: %v", url, err)
-			continue
-		}
-
-		return &parsed, url
-	}
-	return nil, ""
-}
-
 // detectChangedProviders compares two model catalogs and returns provider names
 // whose model definitions differ. Codex tiers (free/team/plus/pro) are grouped
 // under a single "codex" provider.
@@ -320,31 +292,37 @@ func validateModelsCatalog(data *staticModelsJSON) error {
 		return fmt.Errorf("catalog is nil")
 	}
 
-	requiredSections := []struct {
-		name   string
-		models []*ModelInfo
-	}{
-		{name: "claude", models: data.Claude},
-		{name: "gemini", models: data.Gemini},
-		{name: "vertex", models: data.Vertex},
-		{name: "aistudio", models: data.AIStudio},
-		{name: "codex-free", models: data.CodexFree},
-		{name: "codex-team", models: data.CodexTeam},
-		{name: "codex-plus", models: data.CodexPlus},
-		{name: "codex-pro", models: data.CodexPro},
-		{name: "kimi", models: data.Kimi},
-		{name: "antigravity", models: data.Antigravity},
-		{name: "xai", models: data.XAI},
-	}
-
-	for _, section := range requiredSections {
-		if err := validateModelSection(section.name, section.models); err != nil {
+	for _, section := range data.sectionRefs() {
+		if err := validateModelSection(section.name, *section.models); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// modelCatalogSectionRef points at one provider section of a catalog.
+type modelCatalogSectionRef struct {
+	name   string
+	models *[]*ModelInfo
+}
+
+// sectionRefs lists the provider sections of the catalog under their models.json keys.
+func (d *staticModelsJSON) sectionRefs() []modelCatalogSectionRef {
+	return []modelCatalogSectionRef{
+		{name: "claude", models: &d.Claude},
+		{name: "gemini", models: &d.Gemini},
+		{name: "vertex", models: &d.Vertex},
+		{name: "aistudio", models: &d.AIStudio},
+		{name: "codex-free", models: &d.CodexFree},
+		{name: "codex-team", models: &d.CodexTeam},
+		{name: "codex-plus", models: &d.CodexPlus},
+		{name: "codex-pro", models: &d.CodexPro},
+		{name: "kimi", models: &d.Kimi},
+		{name: "antigravity", models: &d.Antigravity},
+		{name: "xai", models: &d.XAI},
+	}
+}
+
 func validateModelSection(section string, models []*ModelInfo) error {
 	if len(models) == 0 {
 		log.Warnf("models catalog: %s section is empty, continuing without those model definitions", section)
@@ -364,6 +342,9 @@ func validateModelSection(section string, models []*ModelInfo) error {
 			return fmt.Errorf("%s contains duplicate model id %q", section, modelID)
 		}
 		seen[modelID] = struct{}{}
+		if err := model.Pricing.Validate(); err != nil {
+			return fmt.Errorf("%s model %q: %w", section, modelID, err)
+		}
 	}
 	return nil
 }
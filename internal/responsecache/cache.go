@@ -0,0 +1,252 @@
+// Package responsecache stores upstream responses of deterministic requests so
+// identical requests can be answered without calling a provider again.
+//
+// Entries are keyed on the canonical form of the request after API-key policy,
+// model routing and request interceptors have been applied. Non-streaming
+// requests cache the response payload; streaming requests cache the ordered
+// stream chunks and are replayed chunk by chunk on a hit.
+package responsecache
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// backendTimeout bounds every backend call so a slow shared backend degrades to a miss.
+const backendTimeout = 2 * time.Second
+
+// Entry is one cached response.
+type Entry struct {
+	// Payload is the non-streaming response body.
+	Payload []byte `json:"payload,omitempty"`
+	// Chunks are the ordered stream chunks of a streaming response.
+	Chunks [][]byte `json:"chunks,omitempty"`
+	// Headers are the upstream response headers captured with the response.
+	Headers   http.Header `json:"headers,omitempty"`
+	StoredAt  time.Time   `json:"stored_at"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// Size returns the number of body bytes held by the entry.
+func (e Entry) Size() int {
+	size := len(e.Payload)
+	for _, chunk := range e.Chunks {
+		size += len(chunk)
+	}
+	return size
+}
+
+// Expired reports whether the entry is past its expiry at now.
+func (e Entry) Expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// Backend stores cache entries. Implementations must ignore expired entries on Get.
+type Backend interface {
+	// Get returns the entry stored under key. The boolean is false on a miss.
+	Get(ctx context.Context, key string) (Entry, bool, error)
+	// Set stores entry under key until entry.ExpiresAt.
+	Set(ctx context.Context, key string, entry Entry) error
+}
+
+// StoreProvider exposes a backend-specific response cache store.
+type StoreProvider interface {
+	ResponseCacheStore() Backend
+}
+
+// Cache selects the configured backend and applies the size and TTL limits.
+type Cache struct {
+	mu            sync.RWMutex
+	cfg           config.ResponseCacheConfig
+	external      Backend
+	memory        *memoryBackend
+	redis         *redisBackend
+	backend       Backend
+	warnedBackend bool
+}
+
+// New constructs a disabled cache.
+func New() *Cache {
+	return &Cache{}
+}
+
+var defaultCache = New()
+
+// Default returns the process-wide cache used by the API handlers.
+func Default() *Cache {
+	return defaultCache
+}
+
+// Apply publishes the response-cache block.
+func (c *Cache) Apply(cfg config.ResponseCacheConfig) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.cfg = cfg
+	closed := c.selectBackendLocked()
+	c.mu.Unlock()
+	closeRedis(closed)
+}
+
+// SetExternalStore attaches the store used by the postgres backend. Passing nil detaches it.
+func (c *Cache) SetExternalStore(store Backend) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.external = store
+	c.warnedBackend = false
+	closed := c.selectBackendLocked()
+	c.mu.Unlock()
+	closeRedis(closed)
+}
+
+// Stop releases backend connections.
+func (c *Cache) Stop() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	closed := c.redis
+	c.redis = nil
+	if _, ok := c.backend.(*redisBackend); ok {
+		c.backend = nil
+	}
+	c.mu.Unlock()
+	closeRedis(closed)
+}
+
+// Enabled reports whether lookups can be served.
+func (c *Cache) Enabled() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.backend != nil
+}
+
+// ShareAcrossKeys reports whether entries are shared between client API keys.
+func (c *Cache) ShareAcrossKeys() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cfg.ShareAcrossKeys
+}
+
+// MaxEntrySize returns the largest cacheable response in bytes.
+func (c *Cache) MaxEntrySize() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cfg.EffectiveMaxEntrySize()
+}
+
+// Get returns the live entry stored under key. Backend errors are logged and reported as misses.
+func (c *Cache) Get(ctx context.Context, key string) (Entry, bool) {
+	backend := c.currentBackend()
+	if backend == nil || key == "" {
+		return Entry{}, false
+	}
+	ctx, cancel := backendContext(ctx)
+	defer cancel()
+	entry, ok, errGet := backend.Get(ctx, key)
+	if errGet != nil {
+		log.Warnf("response cache: lookup failed: %v", errGet)
+		return Entry{}, false
+	}
+	if !ok || entry.Expired(time.Now()) {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Set stores entry under key with the configured TTL. Entries larger than the size
+// limit are skipped.
+func (c *Cache) Set(ctx context.Context, key string, entry Entry) {
+	if c == nil || key == "" {
+		return
+	}
+	c.mu.RLock()
+	backend := c.backend
+	cfg := c.cfg
+	c.mu.RUnlock()
+	if backend == nil || entry.Size() > cfg.EffectiveMaxEntrySize() {
+		return
+	}
+	now := time.Now()
+	entry.StoredAt = now
+	entry.ExpiresAt = now.Add(time.Duration(cfg.EffectiveTTLSeconds()) * time.Second)
+	ctx, cancel := backendContext(ctx)
+	defer cancel()
+	if errSet := backend.Set(ctx, key, entry); errSet != nil {
+		log.Warnf("response cache: store failed: %v", errSet)
+	}
+}
+
+func (c *Cache) currentBackend() Backend {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.backend
+}
+
+// selectBackendLocked picks the backend for the current config. It returns a redis
+// backend that is no longer used so the caller can close it outside the lock.
+func (c *Cache) selectBackendLocked() *redisBackend {
+	var closed *redisBackend
+	if !c.cfg.Enable {
+		c.backend = nil
+		closed, c.redis = c.redis, nil
+		return closed
+	}
+	switch c.cfg.EffectiveBackend() {
+	case config.ResponseCacheBackendRedis:
+		if c.redis == nil || c.redis.url != c.cfg.RedisURL {
+			next, errRedis := newRedisBackend(c.cfg.RedisURL)
+			if errRedis != nil {
+				log.Warnf("response cache: redis backend unavailable, falling back to memory: %v", errRedis)
+				break
+			}
+			closed, c.redis = c.redis, next
+		}
+		c.backend = c.redis
+		return closed
+	case config.ResponseCacheBackendPostgres:
+		if c.external != nil {
+			c.backend = c.external
+			closed, c.redis = c.redis, nil
+			return closed
+		}
+		if !c.warnedBackend {
+			log.Warn("response cache: postgres backend requested but the Postgres store is not in use; falling back to memory")
+			c.warnedBackend = true
+		}
+	}
+	capacity := c.cfg.EffectiveMaxEntries()
+	if c.memory == nil || c.memory.capacity != capacity {
+		c.memory = newMemoryBackend(capacity)
+	}
+	c.backend = c.memory
+	closed, c.redis = c.redis, nil
+	return closed
+}
+
+func backendContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, backendTimeout)
+}
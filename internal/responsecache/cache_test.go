@@ -0,0 +1,112 @@
+package responsecache
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+func TestKeyIgnoresFieldOrderAndVolatileFields(t *testing.T) {
+	base := Request{EntryProtocol: "openai", ResponseProtocol: "openai", Provider: "codex", Model: "m"}
+
+	first := base
+	first.Body = []byte(`{"model":"m","temperature":0,"messages":[{"role":"user","content":"hi"}],"stream":false}`)
+	second := base
+	second.Body = []byte(`{"user":"ci","messages":[{"content":"hi","role":"user"}],"temperature":0}`)
+
+	firstKey, ok := Key(first)
+	if !ok {
+		t.Fatal("Key(first) not ok")
+	}
+	if secondKey, _ := Key(second); firstKey != secondKey {
+		t.Fatal("reordered request with volatile fields produced a different key")
+	}
+
+	scoped := second
+	scoped.Scope = "caller"
+	if scopedKey, _ := Key(scoped); scopedKey == firstKey {
+		t.Fatal("scoped request shares the unscoped key")
+	}
+	streamed := second
+	streamed.Stream = true
+	if streamKey, _ := Key(streamed); streamKey == firstKey {
+		t.Fatal("streaming request shares the non-streaming key")
+	}
+	aliased := second
+	aliased.Model = "m-upstream"
+	if aliasedKey, _ := Key(aliased); aliasedKey == firstKey {
+		t.Fatal("request for another upstream model shares the key")
+	}
+	ruled := second
+	ruled.Rules = "rules"
+	if ruledKey, _ := Key(ruled); ruledKey == firstKey {
+		t.Fatal("request under other payload rules shares the key")
+	}
+	if _, ok = Key(Request{Body: []byte(`[1,2]`)}); ok {
+		t.Fatal("Key() accepted a non-object body")
+	}
+}
+
+func TestEligible(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		header http.Header
+		body   string
+		want   bool
+	}{
+		{name: "temperature zero", body: `{"temperature":0}`, want: true},
+		{name: "gemini temperature zero", body: `{"generationConfig":{"temperature":0}}`, want: true},
+		{name: "non-zero temperature", body: `{"temperature":0.2}`},
+		{name: "no temperature", body: `{}`},
+		{name: "opt in", header: http.Header{Header: {"on"}}, body: `{"temperature":1}`, want: true},
+		{name: "opt out", header: http.Header{Header: {"off"}}, body: `{"temperature":0}`},
+		{name: "no-cache", header: http.Header{"Cache-Control": {"no-cache"}}, body: `{"temperature":0}`},
+	} {
+		headers := http.Header{}
+		for key, values := range tc.header {
+			headers.Set(key, values[0])
+		}
+		if got := Eligible(headers, []byte(tc.body)); got != tc.want {
+			t.Errorf("%s: Eligible() = %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCacheAppliesTTLAndSizeLimits(t *testing.T) {
+	cache := New()
+	ctx := context.Background()
+	cache.Set(ctx, "k", Entry{Payload: []byte("x")})
+	if _, ok := cache.Get(ctx, "k"); ok {
+		t.Fatal("disabled cache served an entry")
+	}
+
+	cache.Apply(config.ResponseCacheConfig{Enable: true, MaxEntrySizeKB: 1, MaxEntries: 2})
+	cache.Set(ctx, "big", Entry{Payload: make([]byte, 2048)})
+	if _, ok := cache.Get(ctx, "big"); ok {
+		t.Fatal("entry over the size limit was cached")
+	}
+	cache.Set(ctx, "k", Entry{Payload: []byte("x")})
+	entry, ok := cache.Get(ctx, "k")
+	if !ok || string(entry.Payload) != "x" {
+		t.Fatalf("Get(k) = %+v/%t, want x/true", entry, ok)
+	}
+	if entry.ExpiresAt.Sub(entry.StoredAt) != time.Duration(config.DefaultResponseCacheTTLSeconds)*time.Second {
+		t.Fatalf("entry ttl = %s", entry.ExpiresAt.Sub(entry.StoredAt))
+	}
+
+	expired := Entry{Payload: []byte("old"), ExpiresAt: time.Now().Add(-time.Second)}
+	if errSet := cache.currentBackend().Set(ctx, "old", expired); errSet != nil {
+		t.Fatalf("backend Set() error = %v", errSet)
+	}
+	if _, ok = cache.Get(ctx, "old"); ok {
+		t.Fatal("expired entry was served")
+	}
+
+	cache.Apply(config.ResponseCacheConfig{})
+	if cache.Enabled() {
+		t.Fatal("cache still enabled after disabling")
+	}
+}
@@ -0,0 +1,110 @@
+package responsecache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Header is the request header that opts in to or out of the cache and the
+// response header that reports the cache outcome.
+const Header = "X-CPA-Cache"
+
+// Values reported in the Header response header.
+const (
+	StatusHit  = "hit"
+	StatusMiss = "miss"
+)
+
+// volatileFields are top-level request fields that do not change the generated
+// response and are dropped before hashing.
+var volatileFields = []string{"stream", "stream_options", "user", "metadata", "model"}
+
+// Request describes the upstream request of one attempt for key derivation.
+type Request struct {
+	// Scope separates entries of different callers. Empty when entries are shared.
+	Scope string
+	// EntryProtocol and ResponseProtocol are the handler formats of the request and response.
+	EntryProtocol    string
+	ResponseProtocol string
+	// Path is the inbound request path, which some payload settings depend on.
+	Path string
+	// Provider serves the attempt.
+	Provider string
+	// Model is the upstream model after credential aliasing and RequestedModel the
+	// client-visible model; payload rules may match either.
+	Model          string
+	RequestedModel string
+	// Rules fingerprints the payload rules applied to Body.
+	Rules  string
+	Alt    string
+	Stream bool
+	// Body is the request body after request interceptors.
+	Body []byte
+}
+
+// Key returns the cache key of req. The boolean is false when the body is not a
+// JSON object and therefore cannot be canonicalized.
+func Key(req Request) (string, bool) {
+	body, ok := canonicalBody(req.Body)
+	if !ok {
+		return "", false
+	}
+	stream := "0"
+	if req.Stream {
+		stream = "1"
+	}
+	hash := sha256.New()
+	for _, part := range []string{"v2", req.Scope, req.EntryProtocol, req.ResponseProtocol, req.Path, strings.ToLower(strings.TrimSpace(req.Provider)), strings.ToLower(strings.TrimSpace(req.Model)), strings.ToLower(strings.TrimSpace(req.RequestedModel)), req.Rules, req.Alt, stream} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
+
+// canonicalBody re-encodes body with sorted object keys and without volatile fields.
+func canonicalBody(body []byte) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var parsed map[string]any
+	if errDecode := decoder.Decode(&parsed); errDecode != nil || parsed == nil {
+		return nil, false
+	}
+	for _, field := range volatileFields {
+		delete(parsed, field)
+	}
+	canonical, errMarshal := json.Marshal(parsed)
+	if errMarshal != nil {
+		return nil, false
+	}
+	return canonical, true
+}
+
+// Eligible reports whether a request may be served from or stored in the cache.
+// The Header request header forces the decision ("on" or "off"); Cache-Control
+// no-cache and no-store bypass the cache; otherwise only requests that set
+// temperature to 0 are cached.
+func Eligible(headers http.Header, body []byte) bool {
+	switch strings.ToLower(strings.TrimSpace(headers.Get(Header))) {
+	case "on", "true", "1", "force":
+		return true
+	case "off", "false", "0", "bypass":
+		return false
+	}
+	cacheControl := strings.ToLower(headers.Get("Cache-Control"))
+	if strings.Contains(cacheControl, "no-cache") || strings.Contains(cacheControl, "no-store") {
+		return false
+	}
+	for _, path := range []string{"temperature", "generationConfig.temperature", "generation_config.temperature"} {
+		if temperature := gjson.GetBytes(body, path); temperature.Exists() {
+			return temperature.Type == gjson.Number && temperature.Float() == 0
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package responsecache
+
+import (
+	"context"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/cache"
+)
+
+// memoryBackend keeps entries in a bounded in-process LRU.
+type memoryBackend struct {
+	capacity int
+	entries  *cache.BoundedLRU[string, Entry]
+}
+
+func newMemoryBackend(capacity int) *memoryBackend {
+	return &memoryBackend{
+		capacity: capacity,
+		entries:  cache.NewBoundedLRU[string, Entry](capacity, nil),
+	}
+}
+
+func (b *memoryBackend) Get(_ context.Context, key string) (Entry, bool, error) {
+	entry, ok := b.entries.Get(key)
+	if !ok {
+		return Entry{}, false, nil
+	}
+	if entry.Expired(time.Now()) {
+		b.entries.Remove(key)
+		return Entry{}, false, nil
+	}
+	return entry, true, nil
+}
+
+func (b *memoryBackend) Set(_ context.Context, key string, entry Entry) error {
+	b.entries.Add(key, entry)
+	return nil
+}
@@ -0,0 +1,74 @@
+package responsecache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+// redisKeyPrefix namespaces cache entries inside a shared Redis database.
+const redisKeyPrefix = "cliproxy:response-cache:"
+
+// redisBackend shares entries between instances through Redis.
+type redisBackend struct {
+	url    string
+	client *redis.Client
+}
+
+func newRedisBackend(url string) (*redisBackend, error) {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return nil, errors.New("response-cache.redis-url is empty")
+	}
+	options, errParse := redis.ParseURL(url)
+	if errParse != nil {
+		return nil, fmt.Errorf("parse redis-url: %w", errParse)
+	}
+	options.ContextTimeoutEnabled = true
+	return &redisBackend{url: url, client: redis.NewClient(options)}, nil
+}
+
+func (b *redisBackend) Get(ctx context.Context, key string) (Entry, bool, error) {
+	raw, errGet := b.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if errors.Is(errGet, redis.Nil) {
+		return Entry{}, false, nil
+	}
+	if errGet != nil {
+		return Entry{}, false, fmt.Errorf("redis get: %w", errGet)
+	}
+	var entry Entry
+	if errUnmarshal := json.Unmarshal(raw, &entry); errUnmarshal != nil {
+		return Entry{}, false, fmt.Errorf("decode entry: %w", errUnmarshal)
+	}
+	return entry, true, nil
+}
+
+func (b *redisBackend) Set(ctx context.Context, key string, entry Entry) error {
+	ttl := time.Until(entry.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	raw, errMarshal := json.Marshal(entry)
+	if errMarshal != nil {
+		return fmt.Errorf("encode entry: %w", errMarshal)
+	}
+	if errSet := b.client.Set(ctx, redisKeyPrefix+key, raw, ttl).Err(); errSet != nil {
+		return fmt.Errorf("redis set: %w", errSet)
+	}
+	return nil
+}
+
+func closeRedis(backend *redisBackend) {
+	if backend == nil || backend.client == nil {
+		return
+	}
+	if errClose := backend.client.Close(); errClose != nil {
+		log.Debugf("response cache: failed to close redis client: %v", errClose)
+	}
+}
@@ -0,0 +1,54 @@
+package responsestore
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// InputItems normalizes the input field of a Responses request to an item array.
+// A string input becomes a single user message and a missing input an empty array.
+func InputItems(input gjson.Result) ([]byte, error) {
+	switch {
+	case !input.Exists() || input.Type == gjson.Null:
+		return []byte("[]"), nil
+	case input.Type == gjson.String:
+		message := []byte(`{"type":"message","role":"user","content":[{"type":"input_text","text":""}]}`)
+		message, _ = sjson.SetBytes(message, "content.0.text", input.String())
+		return append(append([]byte{'['}, message...), ']'), nil
+	case input.IsArray():
+		return []byte(input.Raw), nil
+	default:
+		return nil, fmt.Errorf("input must be a string or an array of items")
+	}
+}
+
+// Conversation returns the input item array of a request that continues record: the
+// record's input and output items followed by the request's own input.
+func Conversation(record Record, input gjson.Result) ([]byte, error) {
+	next, errInput := InputItems(input)
+	if errInput != nil {
+		return nil, errInput
+	}
+	var out bytes.Buffer
+	out.WriteByte('[')
+	written := 0
+	for _, items := range [][]byte{record.Input, record.Output, next} {
+		parsed := gjson.ParseBytes(items)
+		if !parsed.IsArray() {
+			continue
+		}
+		parsed.ForEach(func(_, item gjson.Result) bool {
+			if written > 0 {
+				out.WriteByte(',')
+			}
+			out.WriteString(item.Raw)
+			written++
+			return true
+		})
+	}
+	out.WriteByte(']')
+	return out.Bytes(), nil
+}
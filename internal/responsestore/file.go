@@ -0,0 +1,162 @@
+package responsestore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// recordSuffix deliberately avoids .json so token stores and the auth watcher never
+	// mistake records for credentials when the directory lives under the auth directory.
+	recordSuffix = ".record"
+
+	// filePurgeInterval limits how often expired and surplus files are deleted on write.
+	filePurgeInterval = time.Minute
+)
+
+// fileBackend keeps one JSON-encoded file per record. Each file's modification time is set to
+// the record's expiry so purging never has to read file contents.
+type fileBackend struct {
+	dir      string
+	capacity int
+
+	mu         sync.Mutex
+	lastPurged time.Time
+}
+
+func newFileBackend(dir string, capacity int) *fileBackend {
+	return &fileBackend{dir: dir, capacity: capacity}
+}
+
+// path maps id to a file name that cannot escape the directory.
+func (b *fileBackend) path(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return filepath.Join(b.dir, hex.EncodeToString(sum[:])+recordSuffix)
+}
+
+func (b *fileBackend) Get(_ context.Context, id string) (Record, bool, error) {
+	path := b.path(id)
+	content, errRead := os.ReadFile(path)
+	if errRead != nil {
+		if errors.Is(errRead, fs.ErrNotExist) {
+			return Record{}, false, nil
+		}
+		return Record{}, false, fmt.Errorf("read record: %w", errRead)
+	}
+	var record Record
+	if errUnmarshal := json.Unmarshal(content, &record); errUnmarshal != nil {
+		return Record{}, false, fmt.Errorf("decode record: %w", errUnmarshal)
+	}
+	if record.ID != id {
+		return Record{}, false, nil
+	}
+	if record.Expired(time.Now()) {
+		_ = os.Remove(path)
+		return Record{}, false, nil
+	}
+	return record, true, nil
+}
+
+func (b *fileBackend) Put(_ context.Context, record Record) error {
+	content, errMarshal := json.Marshal(record)
+	if errMarshal != nil {
+		return fmt.Errorf("encode record: %w", errMarshal)
+	}
+	if errMkdir := os.MkdirAll(b.dir, 0o700); errMkdir != nil {
+		return fmt.Errorf("create directory: %w", errMkdir)
+	}
+	path := b.path(record.ID)
+	tmp, errCreate := os.CreateTemp(b.dir, ".record-*")
+	if errCreate != nil {
+		return fmt.Errorf("create record: %w", errCreate)
+	}
+	tmpName := tmp.Name()
+	_, errWrite := tmp.Write(content)
+	errClose := tmp.Close()
+	if errWrite == nil {
+		errWrite = errClose
+	}
+	if errWrite == nil {
+		errWrite = os.Chtimes(tmpName, record.ExpiresAt, record.ExpiresAt)
+	}
+	if errWrite == nil {
+		errWrite = os.Rename(tmpName, path)
+	}
+	if errWrite != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write record: %w", errWrite)
+	}
+	if b.purgeDue(time.Now()) {
+		return b.purge(time.Now())
+	}
+	return nil
+}
+
+func (b *fileBackend) Delete(_ context.Context, id string) (bool, error) {
+	errRemove := os.Remove(b.path(id))
+	if errRemove == nil {
+		return true, nil
+	}
+	if errors.Is(errRemove, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, fmt.Errorf("delete record: %w", errRemove)
+}
+
+func (b *fileBackend) purgeDue(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if now.Sub(b.lastPurged) < filePurgeInterval {
+		return false
+	}
+	b.lastPurged = now
+	return true
+}
+
+// purge removes expired records and then the records closest to expiry until at most
+// capacity remain.
+func (b *fileBackend) purge(now time.Time) error {
+	entries, errRead := os.ReadDir(b.dir)
+	if errRead != nil {
+		return fmt.Errorf("list records: %w", errRead)
+	}
+	type recordFile struct {
+		name    string
+		expires time.Time
+	}
+	live := make([]recordFile, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, recordSuffix) {
+			continue
+		}
+		info, errInfo := entry.Info()
+		if errInfo != nil {
+			continue
+		}
+		if !now.Before(info.ModTime()) {
+			_ = os.Remove(filepath.Join(b.dir, name))
+			continue
+		}
+		live = append(live, recordFile{name: name, expires: info.ModTime()})
+	}
+	if b.capacity <= 0 || len(live) <= b.capacity {
+		return nil
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].expires.Before(live[j].expires) })
+	for _, file := range live[:len(live)-b.capacity] {
+		_ = os.Remove(filepath.Join(b.dir, file.name))
+	}
+	return nil
+}
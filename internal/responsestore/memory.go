@@ -0,0 +1,46 @@
+package responsestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/cache"
+)
+
+// memoryBackend keeps records in a bounded in-process LRU.
+type memoryBackend struct {
+	capacity int
+	records  *cache.BoundedLRU[string, Record]
+}
+
+func newMemoryBackend(capacity int) *memoryBackend {
+	return &memoryBackend{
+		capacity: capacity,
+		records:  cache.NewBoundedLRU[string, Record](capacity, nil),
+	}
+}
+
+func (b *memoryBackend) Get(_ context.Context, id string) (Record, bool, error) {
+	record, ok := b.records.Get(id)
+	if !ok {
+		return Record{}, false, nil
+	}
+	if record.Expired(time.Now()) {
+		b.records.Remove(id)
+		return Record{}, false, nil
+	}
+	return record, true, nil
+}
+
+func (b *memoryBackend) Put(_ context.Context, record Record) error {
+	b.records.Add(record.ID, record)
+	return nil
+}
+
+func (b *memoryBackend) Delete(_ context.Context, id string) (bool, error) {
+	if _, ok := b.records.Get(id); !ok {
+		return false, nil
+	}
+	b.records.Remove(id)
+	return true, nil
+}
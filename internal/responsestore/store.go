@@ -0,0 +1,237 @@
+// Package responsestore keeps stored Responses API responses so previous_response_id
+// and the /v1/responses/{id} endpoints work against providers without server-side
+// conversation state.
+//
+// Every record carries the full input item chain that produced the response next to
+// its output items, so continuing a conversation needs a single lookup and does not
+// depend on older records still being present.
+package responsestore
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// backendTimeout bounds every backend call so a slow shared backend degrades to a miss.
+const backendTimeout = 2 * time.Second
+
+// Record is one stored response.
+type Record struct {
+	ID string `json:"id"`
+	// Scope is the caller scope of the client API key that created the response.
+	// Records are only visible to the same scope.
+	Scope string `json:"scope,omitempty"`
+	Model string `json:"model,omitempty"`
+	// Input is the input item array of the request, including the items inherited
+	// through previous_response_id.
+	Input json.RawMessage `json:"input"`
+	// Output is the output item array of the response.
+	Output json.RawMessage `json:"output"`
+	// Response is the response object returned to the client.
+	Response  json.RawMessage `json:"response"`
+	CreatedAt time.Time       `json:"created_at"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// Size returns the number of JSON bytes held by the record.
+func (r Record) Size() int {
+	return len(r.Input) + len(r.Output) + len(r.Response)
+}
+
+// Expired reports whether the record is past its expiry at now.
+func (r Record) Expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// Backend stores records by response ID. Implementations must ignore expired records on Get.
+type Backend interface {
+	// Get returns the record stored under id. The boolean is false on a miss.
+	Get(ctx context.Context, id string) (Record, bool, error)
+	// Put stores record under record.ID until record.ExpiresAt.
+	Put(ctx context.Context, record Record) error
+	// Delete removes the record stored under id and reports whether one existed.
+	Delete(ctx context.Context, id string) (bool, error)
+}
+
+// StoreProvider exposes a backend-specific responses store.
+type StoreProvider interface {
+	ResponsesStore() Backend
+}
+
+// Store selects the configured backend and applies the size and TTL limits.
+type Store struct {
+	mu            sync.RWMutex
+	cfg           config.ResponsesStoreConfig
+	dir           string
+	external      Backend
+	memory        *memoryBackend
+	file          *fileBackend
+	backend       Backend
+	warnedBackend bool
+}
+
+// New constructs a disabled store.
+func New() *Store {
+	return &Store{}
+}
+
+var defaultStore = New()
+
+// Default returns the process-wide store used by the API handlers.
+func Default() *Store {
+	return defaultStore
+}
+
+// Apply publishes the responses-store block. dir is the resolved directory of the file backend.
+func (s *Store) Apply(cfg config.ResponsesStoreConfig, dir string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.cfg = cfg
+	s.dir = dir
+	s.selectBackendLocked()
+	s.mu.Unlock()
+}
+
+// SetExternalStore attaches the store used by the postgres backend. Passing nil detaches it.
+func (s *Store) SetExternalStore(store Backend) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.external = store
+	s.warnedBackend = false
+	s.selectBackendLocked()
+	s.mu.Unlock()
+}
+
+// Enabled reports whether responses are currently stored.
+func (s *Store) Enabled() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.backend != nil
+}
+
+// Get returns the live record stored under id for scope. Backend errors are logged
+// and reported as misses.
+func (s *Store) Get(ctx context.Context, scope, id string) (Record, bool) {
+	backend := s.currentBackend()
+	if backend == nil || id == "" {
+		return Record{}, false
+	}
+	ctx, cancel := backendContext(ctx)
+	defer cancel()
+	record, ok, errGet := backend.Get(ctx, id)
+	if errGet != nil {
+		log.Warnf("responses store: lookup of %s failed: %v", id, errGet)
+		return Record{}, false
+	}
+	if !ok || record.Expired(time.Now()) || record.Scope != scope {
+		return Record{}, false
+	}
+	return record, true
+}
+
+// Put stores record with the configured TTL and reports whether it was kept. Records
+// larger than the size limit are skipped.
+func (s *Store) Put(ctx context.Context, record Record) bool {
+	if s == nil || record.ID == "" {
+		return false
+	}
+	s.mu.RLock()
+	backend := s.backend
+	cfg := s.cfg
+	s.mu.RUnlock()
+	if backend == nil {
+		return false
+	}
+	if size := record.Size(); size > cfg.EffectiveMaxEntrySize() {
+		log.Debugf("responses store: skipping %s: %d bytes exceeds the size limit", record.ID, size)
+		return false
+	}
+	now := time.Now()
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = now
+	}
+	record.ExpiresAt = now.Add(time.Duration(cfg.EffectiveTTLSeconds()) * time.Second)
+	ctx, cancel := backendContext(ctx)
+	defer cancel()
+	if errPut := backend.Put(ctx, record); errPut != nil {
+		log.Warnf("responses store: store of %s failed: %v", record.ID, errPut)
+		return false
+	}
+	return true
+}
+
+// Delete removes the record stored under id for scope and reports whether one existed.
+func (s *Store) Delete(ctx context.Context, scope, id string) bool {
+	if _, ok := s.Get(ctx, scope, id); !ok {
+		return false
+	}
+	backend := s.currentBackend()
+	if backend == nil {
+		return false
+	}
+	ctx, cancel := backendContext(ctx)
+	defer cancel()
+	deleted, errDelete := backend.Delete(ctx, id)
+	if errDelete != nil {
+		log.Warnf("responses store: delete of %s failed: %v", id, errDelete)
+		return false
+	}
+	return deleted
+}
+
+func (s *Store) currentBackend() Backend {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.backend
+}
+
+func (s *Store) selectBackendLocked() {
+	if !s.cfg.Enable {
+		s.backend = nil
+		return
+	}
+	capacity := s.cfg.EffectiveMaxEntries()
+	switch s.cfg.EffectiveBackend() {
+	case config.ResponsesStoreBackendFile:
+		if s.file == nil || s.file.dir != s.dir || s.file.capacity != capacity {
+			s.file = newFileBackend(s.dir, capacity)
+		}
+		s.backend = s.file
+		return
+	case config.ResponsesStoreBackendPostgres:
+		if s.external != nil {
+			s.backend = s.external
+			return
+		}
+		if !s.warnedBackend {
+			log.Warn("responses store: postgres backend requested but the Postgres store is not in use; falling back to memory")
+			s.warnedBackend = true
+		}
+	}
+	if s.memory == nil || s.memory.capacity != capacity {
+		s.memory = newMemoryBackend(capacity)
+	}
+	s.backend = s.memory
+}
+
+func backendContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, backendTimeout)
+}
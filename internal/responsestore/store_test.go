@@ -0,0 +1,95 @@
+package responsestore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestStoreScopesRecordsAndAppliesLimits(t *testing.T) {
+	for _, backend := range []string{config.ResponsesStoreBackendMemory, config.ResponsesStoreBackendFile} {
+		t.Run(backend, func(t *testing.T) {
+			store := New()
+			if store.Put(context.Background(), Record{ID: "resp_1"}) {
+				t.Fatal("Put() on a disabled store kept the record")
+			}
+			store.Apply(config.ResponsesStoreConfig{Enable: true, Backend: backend, MaxEntrySizeKB: 1}, t.TempDir())
+
+			record := Record{ID: "resp_1", Scope: "a", Model: "m", Input: []byte(`[{"type":"message"}]`), Output: []byte(`[]`)}
+			if !store.Put(context.Background(), record) {
+				t.Fatal("Put() rejected a small record")
+			}
+			got, ok := store.Get(context.Background(), "a", "resp_1")
+			if !ok || got.Model != "m" || got.ExpiresAt.Sub(got.CreatedAt) != time.Duration(config.DefaultResponsesStoreTTLSeconds)*time.Second {
+				t.Fatalf("Get() = %+v, %v", got, ok)
+			}
+			if _, ok = store.Get(context.Background(), "b", "resp_1"); ok {
+				t.Fatal("record visible to another scope")
+			}
+			if store.Delete(context.Background(), "b", "resp_1") {
+				t.Fatal("Delete() removed a record of another scope")
+			}
+
+			large := Record{ID: "resp_2", Scope: "a", Input: []byte(`["` + strings.Repeat("x", 2048) + `"]`)}
+			if store.Put(context.Background(), large) {
+				t.Fatal("Put() kept a record above the size limit")
+			}
+
+			if !store.Delete(context.Background(), "a", "resp_1") {
+				t.Fatal("Delete() did not report the removed record")
+			}
+			if _, ok = store.Get(context.Background(), "a", "resp_1"); ok {
+				t.Fatal("deleted record still returned")
+			}
+		})
+	}
+}
+
+func TestFileBackendPurgesExpiredAndSurplusRecords(t *testing.T) {
+	dir := t.TempDir()
+	backend := newFileBackend(dir, 2)
+	now := time.Now()
+	for i, expires := range []time.Time{now.Add(-time.Minute), now.Add(time.Hour), now.Add(2 * time.Hour), now.Add(3 * time.Hour)} {
+		record := Record{ID: string(rune('a' + i)), ExpiresAt: expires}
+		if errPut := backend.Put(context.Background(), record); errPut != nil {
+			t.Fatalf("Put() error = %v", errPut)
+		}
+	}
+	if errPurge := backend.purge(now); errPurge != nil {
+		t.Fatalf("purge() error = %v", errPurge)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+recordSuffix))
+	if len(files) != 2 {
+		t.Fatalf("files after purge = %d, want 2", len(files))
+	}
+	if _, errStat := os.Stat(backend.path("b")); !os.IsNotExist(errStat) {
+		t.Fatalf("record closest to expiry survived the capacity purge: %v", errStat)
+	}
+	if _, ok, _ := backend.Get(context.Background(), "d"); !ok {
+		t.Fatal("newest record was purged")
+	}
+}
+
+func TestConversationAppendsOutputAndNormalizesStringInput(t *testing.T) {
+	record := Record{
+		Input:  []byte(`[{"type":"message","role":"user","content":"hi"}]`),
+		Output: []byte(`[{"type":"function_call","call_id":"c1"}]`),
+	}
+	conversation, errConversation := Conversation(record, gjson.Parse(`"next"`))
+	if errConversation != nil {
+		t.Fatalf("Conversation() error = %v", errConversation)
+	}
+	items := gjson.ParseBytes(conversation).Array()
+	if len(items) != 3 || items[1].Get("call_id").String() != "c1" || items[2].Get("content.0.text").String() != "next" || items[2].Get("role").String() != "user" {
+		t.Fatalf("conversation = %s", conversation)
+	}
+	if _, errConversation = Conversation(record, gjson.Parse(`{"bad":true}`)); errConversation == nil {
+		t.Fatal("Conversation() accepted an object input")
+	}
+}
@@ -0,0 +1,175 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/runtime/executor/helps"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+// azureEntraTokenSkew is how long before expiry a cached Entra ID token stops being used.
+const azureEntraTokenSkew = time.Minute
+
+// azureEntraToken is a bearer token issued by Entra ID for an Azure OpenAI auth.
+type azureEntraToken struct {
+	AccessToken string
+	ExpiresAt   time.Time
+}
+
+func (t azureEntraToken) valid(now time.Time) bool {
+	return t.AccessToken != "" && now.Add(azureEntraTokenSkew).Before(t.ExpiresAt)
+}
+
+// azureEntraCredentials holds the client-credentials grant parameters of an auth.
+type azureEntraCredentials struct {
+	tenantID      string
+	clientID      string
+	clientSecret  string
+	authorityHost string
+	scope         string
+}
+
+func azureEntraCreds(auth *cliproxyauth.Auth) (azureEntraCredentials, bool) {
+	if auth == nil || auth.Attributes == nil {
+		return azureEntraCredentials{}, false
+	}
+	attrs := auth.Attributes
+	creds := azureEntraCredentials{
+		tenantID:      strings.TrimSpace(attrs["tenant_id"]),
+		clientID:      strings.TrimSpace(attrs["client_id"]),
+		clientSecret:  attrs["client_secret"],
+		authorityHost: strings.TrimRight(strings.TrimSpace(attrs["authority_host"]), "/"),
+		scope:         strings.TrimSpace(attrs["scope"]),
+	}
+	if creds.tenantID == "" || creds.clientID == "" || creds.clientSecret == "" {
+		return azureEntraCredentials{}, false
+	}
+	if creds.authorityHost == "" {
+		creds.authorityHost = config.DefaultAzureAuthorityHost
+	}
+	if creds.scope == "" {
+		creds.scope = config.DefaultAzureOpenAIScope
+	}
+	return creds, true
+}
+
+// azureEntraTokenFromMetadata returns the token the refresh loop last stored on auth.
+func azureEntraTokenFromMetadata(auth *cliproxyauth.Auth) azureEntraToken {
+	if auth == nil || auth.Metadata == nil {
+		return azureEntraToken{}
+	}
+	token, _ := auth.Metadata["access_token"].(string)
+	expired, _ := auth.Metadata["expired"].(string)
+	expiresAt, errParse := time.Parse(time.RFC3339, expired)
+	if errParse != nil {
+		return azureEntraToken{}
+	}
+	return azureEntraToken{AccessToken: token, ExpiresAt: expiresAt}
+}
+
+// entraAccessToken returns a usable bearer token for auth. The token kept on the auth by
+// the refresh loop is preferred; requests that arrive before the first refresh, or after
+// a failed one, fetch a token themselves and share it through the executor cache.
+func (e *AzureOpenAIExecutor) entraAccessToken(ctx context.Context, auth *cliproxyauth.Auth, creds azureEntraCredentials) (string, error) {
+	now := time.Now()
+	if token := azureEntraTokenFromMetadata(auth); token.valid(now) {
+		return token.AccessToken, nil
+	}
+	e.tokenMu.Lock()
+	defer e.tokenMu.Unlock()
+	if token, ok := e.tokens[auth.ID]; ok && token.valid(now) {
+		return token.AccessToken, nil
+	}
+	token, err := e.requestEntraToken(ctx, auth, creds)
+	if err != nil {
+		return "", err
+	}
+	e.tokens[auth.ID] = token
+	return token.AccessToken, nil
+}
+
+// requestEntraToken performs the OAuth 2.0 client-credentials grant against Entra ID.
+// Rejected client credentials surface as 401 so the auth manager marks the auth unusable
+// instead of retrying it.
+func (e *AzureOpenAIExecutor) requestEntraToken(ctx context.Context, auth *cliproxyauth.Auth, creds azureEntraCredentials) (azureEntraToken, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", creds.clientID)
+	form.Set("client_secret", creds.clientSecret)
+	form.Set("scope", creds.scope)
+	tokenURL := creds.authorityHost + "/" + url.PathEscape(creds.tenantID) + "/oauth2/v2.0/token"
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return azureEntraToken{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpClient := helps.NewProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return azureEntraToken{}, fmt.Errorf("azure openai executor: entra token request failed: %w", err)
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("azure openai executor: close token response body error: %v", errClose)
+		}
+	}()
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return azureEntraToken{}, err
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		code := httpResp.StatusCode
+		switch gjson.GetBytes(body, "error").String() {
+		case "invalid_client", "unauthorized_client", "invalid_grant":
+			code = http.StatusUnauthorized
+		}
+		return azureEntraToken{}, statusErr{code: code, msg: string(body)}
+	}
+
+	accessToken := gjson.GetBytes(body, "access_token").String()
+	if accessToken == "" {
+		return azureEntraToken{}, statusErr{code: http.StatusBadGateway, msg: "azure openai executor: entra token response has no access_token"}
+	}
+	expiresIn := gjson.GetBytes(body, "expires_in").Int()
+	if expiresIn <= 0 {
+		expiresIn = 3600
+	}
+	return azureEntraToken{AccessToken: accessToken, ExpiresAt: time.Now().Add(time.Duration(expiresIn) * time.Second)}, nil
+}
+
+// Refresh fetches a new Entra ID token for Entra-authenticated auths and stores it in the
+// auth metadata, where the auto-refresh loop reads its expiry. Resource-key auths are
+// returned unchanged.
+func (e *AzureOpenAIExecutor) Refresh(ctx context.Context, auth *cliproxyauth.Auth) (*cliproxyauth.Auth, error) {
+	creds, ok := azureEntraCreds(auth)
+	if !ok {
+		return auth, nil
+	}
+	token, err := e.requestEntraToken(ctx, auth, creds)
+	if err != nil {
+		return nil, err
+	}
+	e.tokenMu.Lock()
+	e.tokens[auth.ID] = token
+	e.tokenMu.Unlock()
+
+	if auth.Metadata == nil {
+		auth.Metadata = make(map[string]any)
+	}
+	auth.Metadata["access_token"] = token.AccessToken
+	auth.Metadata["token_type"] = "Bearer"
+	auth.Metadata["expired"] = token.ExpiresAt.UTC().Format(time.RFC3339)
+	return auth, nil
+}
@@ -0,0 +1,553 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/runtime/executor/helps"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// AzureOpenAIExecutor is an executor for Azure OpenAI resources. Chat Completions go to
+// /openai/deployments/{deployment}/chat/completions and Responses API calls go to
+// /openai/responses with the deployment as the model; both carry the configured
+// api-version. Requests authenticate with the resource key in the "api-key" header, or
+// with an Entra ID bearer token obtained through the client-credentials grant.
+type AzureOpenAIExecutor struct {
+	cfg *config.Config
+
+	tokenMu sync.Mutex
+	tokens  map[string]azureEntraToken
+}
+
+// NewAzureOpenAIExecutor creates a new Azure OpenAI executor.
+func NewAzureOpenAIExecutor(cfg *config.Config) *AzureOpenAIExecutor {
+	return &AzureOpenAIExecutor{cfg: cfg, tokens: make(map[string]azureEntraToken)}
+}
+
+// Identifier returns the executor identifier.
+func (e *AzureOpenAIExecutor) Identifier() string { return "azure-openai" }
+
+// azureOpenAIEndpoint returns the resource endpoint and api-version of auth.
+func azureOpenAIEndpoint(auth *cliproxyauth.Auth) (endpoint, apiVersion string) {
+	apiVersion = config.DefaultAzureOpenAIAPIVersion
+	if auth == nil || auth.Attributes == nil {
+		return "", apiVersion
+	}
+	endpoint = strings.TrimRight(strings.TrimSpace(auth.Attributes["base_url"]), "/")
+	if version := strings.TrimSpace(auth.Attributes["api_version"]); version != "" {
+		apiVersion = version
+	}
+	return endpoint, apiVersion
+}
+
+// authorize adds the Entra ID bearer token or the resource key to req.
+func (e *AzureOpenAIExecutor) authorize(ctx context.Context, auth *cliproxyauth.Auth, req *http.Request) error {
+	if creds, ok := azureEntraCreds(auth); ok {
+		token, err := e.entraAccessToken(ctx, auth, creds)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+	if auth != nil && auth.Attributes != nil {
+		if apiKey := auth.Attributes["api_key"]; apiKey != "" {
+			req.Header.Set("api-key", apiKey)
+		}
+	}
+	return nil
+}
+
+// PrepareRequest injects Azure OpenAI credentials into the outgoing HTTP request.
+func (e *AzureOpenAIExecutor) PrepareRequest(req *http.Request, auth *cliproxyauth.Auth) error {
+	if req == nil {
+		return nil
+	}
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	util.ApplyCustomHeadersFromAttrs(req, attrs)
+	return e.authorize(req.Context(), auth, req)
+}
+
+// HttpRequest injects Azure OpenAI credentials into the request and executes it.
+func (e *AzureOpenAIExecutor) HttpRequest(ctx context.Context, auth *cliproxyauth.Auth, req *http.Request) (*http.Response, error) {
+	if req == nil {
+		return nil, fmt.Errorf("azure openai executor: request is nil")
+	}
+	if ctx == nil {
+		ctx = req.Context()
+	}
+	httpReq := req.WithContext(ctx)
+	if err := e.PrepareRequest(httpReq, auth); err != nil {
+		return nil, err
+	}
+	httpClient := helps.NewProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	return httpClient.Do(httpReq)
+}
+
+// resolveAzureConfig returns the azure-openai entry auth was synthesized from.
+func (e *AzureOpenAIExecutor) resolveAzureConfig(auth *cliproxyauth.Auth) *config.AzureOpenAIKey {
+	if auth == nil || auth.Attributes == nil || e.cfg == nil {
+		return nil
+	}
+	index, errIndex := strconv.Atoi(strings.TrimSpace(auth.Attributes[cliproxyauth.AttributeConfigIndex]))
+	if errIndex != nil || index < 0 || index >= len(e.cfg.AzureOpenAIKey) {
+		return nil
+	}
+	entry := &e.cfg.AzureOpenAIKey[index]
+	endpoint, _ := azureOpenAIEndpoint(auth)
+	if !strings.EqualFold(entry.Endpoint, endpoint) {
+		return nil
+	}
+	return entry
+}
+
+// azureOpenAIDeployment finds the deployment serving model. Aliases are matched first,
+// then deployment names; an unmapped model is used as the deployment name.
+func azureOpenAIDeployment(entry *config.AzureOpenAIKey, model string) config.AzureOpenAIDeployment {
+	if entry != nil {
+		for _, deployment := range entry.Deployments {
+			if strings.EqualFold(deployment.Alias, model) {
+				return deployment
+			}
+		}
+		for _, deployment := range entry.Deployments {
+			if strings.EqualFold(deployment.Name, model) {
+				return deployment
+			}
+		}
+	}
+	return config.AzureOpenAIDeployment{Name: model, Alias: model}
+}
+
+// azureOpenAIUsesResponses reports whether a request goes to the Responses API. A
+// deployment's wire-api wins; otherwise Responses API and Codex clients use it.
+func azureOpenAIUsesResponses(deployment config.AzureOpenAIDeployment, from, responseFormat sdktranslator.Format) bool {
+	switch deployment.WireAPI {
+	case config.AzureOpenAIWireAPIResponses:
+		return true
+	case config.AzureOpenAIWireAPIChat:
+		return false
+	}
+	return responseFormat == sdktranslator.FormatOpenAIResponse || from == sdktranslator.FormatCodex
+}
+
+// azureOpenAIRequest is a translated request ready to send.
+type azureOpenAIRequest struct {
+	url       string
+	to        sdktranslator.Format
+	responses bool
+	body      []byte
+}
+
+// buildRequest translates the client payload for the deployment's wire API. Responses
+// API requests use the Codex format, which is what that API accepts, and always stream
+// from upstream so non-streaming calls can be assembled from response.completed.
+func (e *AzureOpenAIExecutor) buildRequest(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, reporter *helps.UsageReporter, stream bool) (azureOpenAIRequest, error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+	endpoint, apiVersion := azureOpenAIEndpoint(auth)
+	if endpoint == "" {
+		return azureOpenAIRequest{}, statusErr{code: http.StatusUnauthorized, msg: "missing azure openai endpoint"}
+	}
+	from := opts.SourceFormat
+	responseFormat := cliproxyexecutor.ResponseFormatOrSource(opts)
+	deployment := azureOpenAIDeployment(e.resolveAzureConfig(auth), baseModel)
+
+	out := azureOpenAIRequest{to: sdktranslator.FormatOpenAI}
+	if azureOpenAIUsesResponses(deployment, from, responseFormat) {
+		out.to = sdktranslator.FormatCodex
+		out.responses = true
+		stream = true
+	}
+	originalPayloadSource := req.Payload
+	if len(opts.OriginalRequest) > 0 {
+		originalPayloadSource = opts.OriginalRequest
+	}
+	originalTranslated := helps.TranslateRequestWithCodexMultiAgentV2(ctx, opts.Headers, e.cfg, from, out.to, baseModel, bytes.Clone(originalPayloadSource), stream)
+	body := helps.TranslateRequestWithCodexMultiAgentV2(ctx, opts.Headers, e.cfg, from, out.to, baseModel, bytes.Clone(req.Payload), stream)
+
+	body, err := helps.ApplyRequestThinking(body, req, opts, from.String(), out.to.String(), e.Identifier())
+	if err != nil {
+		return azureOpenAIRequest{}, err
+	}
+	requestedModel := helps.PayloadRequestedModel(opts, req.Model)
+	requestPath := helps.PayloadRequestPath(opts)
+	body = helps.ApplyPayloadConfigWithRequest(e.cfg, baseModel, out.to.String(), from.String(), "", body, originalTranslated, requestedModel, requestPath, opts.Headers)
+
+	query := "?api-version=" + url.QueryEscape(apiVersion)
+	if out.responses {
+		body = helps.SetStringIfDifferent(body, "model", deployment.Name)
+		body = helps.SetBoolIfDifferent(body, "stream", true)
+		body, _ = sjson.DeleteBytes(body, "stream_options")
+		body = sanitizeOpenAIResponsesReasoningEncryptedContent(ctx, "azure openai executor", body)
+		out.url = endpoint + "/openai/responses" + query
+	} else {
+		if stream {
+			body = helps.SetBoolIfDifferent(body, "stream_options.include_usage", true)
+		}
+		out.url = endpoint + "/openai/deployments/" + url.PathEscape(deployment.Name) + "/chat/completions" + query
+	}
+	reporter.SetTranslatedReasoningEffort(body, out.to.String())
+	out.body = body
+	return out, nil
+}
+
+// send authorizes, logs and executes the upstream request.
+func (e *AzureOpenAIExecutor) send(ctx context.Context, auth *cliproxyauth.Auth, reporter *helps.UsageReporter, built azureOpenAIRequest, stream bool) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, built.url, bytes.NewReader(built.body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if stream || built.responses {
+		httpReq.Header.Set("Accept", "text/event-stream")
+		httpReq.Header.Set("Cache-Control", "no-cache")
+	} else {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	util.ApplyCustomHeadersFromAttrs(httpReq, attrs)
+	if err = e.authorize(ctx, auth, httpReq); err != nil {
+		return nil, err
+	}
+
+	var authID, authLabel, authType, authValue string
+	if auth != nil {
+		authID = auth.ID
+		authLabel = auth.Label
+		authType, authValue = auth.AccountInfo()
+	}
+	helps.RecordAPIRequest(ctx, e.cfg, helps.UpstreamRequestLog{
+		URL:       built.url,
+		Method:    http.MethodPost,
+		Headers:   httpReq.Header.Clone(),
+		Body:      built.body,
+		Provider:  e.Identifier(),
+		AuthID:    authID,
+		AuthLabel: authLabel,
+		AuthType:  authType,
+		AuthValue: authValue,
+	})
+
+	httpClient := helps.NewProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	httpClient = reporter.TrackHTTPClient(httpClient)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		helps.RecordAPIResponseError(ctx, e.cfg, err)
+		return nil, err
+	}
+	helps.RecordAPIResponseMetadata(ctx, e.cfg, httpResp.StatusCode, httpResp.Header.Clone())
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		b, _ := io.ReadAll(httpResp.Body)
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("azure openai executor: close response body error: %v", errClose)
+		}
+		helps.AppendAPIResponseChunk(ctx, e.cfg, b)
+		helps.LogWithRequestID(ctx).Debugf("request error, error status: %d, error message: %s", httpResp.StatusCode, helps.SummarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
+		return nil, azureOpenAIHTTPError(httpResp.StatusCode, httpResp.Header, b)
+	}
+	return httpResp, nil
+}
+
+// Execute performs a non-streaming request to Azure OpenAI.
+func (e *AzureOpenAIExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+	responseFormat := cliproxyexecutor.ResponseFormatOrSource(opts)
+
+	reporter := helps.NewExecutorUsageReporter(ctx, e, baseModel, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	built, err := e.buildRequest(ctx, auth, req, opts, reporter, false)
+	if err != nil {
+		return resp, err
+	}
+	httpResp, err := e.send(ctx, auth, reporter, built, false)
+	if err != nil {
+		return resp, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("azure openai executor: close response body error: %v", errClose)
+		}
+	}()
+
+	var data []byte
+	if built.responses {
+		var completed []byte
+		err = e.readResponsesEvents(ctx, httpResp.Body, func(eventData []byte, terminal bool) bool {
+			if terminal {
+				completed = eventData
+			}
+			return true
+		})
+		if err != nil {
+			helps.RecordAPIResponseError(ctx, e.cfg, err)
+			return resp, err
+		}
+		if completed == nil {
+			err = statusErr{code: http.StatusBadGateway, msg: "azure openai executor: stream closed before response.completed"}
+			return resp, err
+		}
+		if detail, ok := helps.ParseCodexUsage(completed); ok {
+			reporter.Publish(ctx, detail)
+		}
+		data = completed
+	} else {
+		data, err = io.ReadAll(httpResp.Body)
+		if err != nil {
+			helps.RecordAPIResponseError(ctx, e.cfg, err)
+			return resp, err
+		}
+		helps.AppendAPIResponseChunk(ctx, e.cfg, data)
+		reporter.Publish(ctx, helps.ParseOpenAIUsage(data))
+	}
+	reporter.EnsurePublished(ctx)
+
+	var param any
+	out := sdktranslator.TranslateNonStream(ctx, built.to, responseFormat, req.Model, opts.OriginalRequest, built.body, data, &param)
+	if responseFormat == sdktranslator.FormatOpenAIResponse {
+		out = helps.EnsureResponsesUsageDetails(out)
+	}
+	resp = cliproxyexecutor.Response{Payload: out, Headers: httpResp.Header.Clone()}
+	return resp, nil
+}
+
+// ExecuteStream performs a streaming request to Azure OpenAI.
+func (e *AzureOpenAIExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (_ *cliproxyexecutor.StreamResult, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+	from := opts.SourceFormat
+	responseFormat := cliproxyexecutor.ResponseFormatOrSource(opts)
+
+	reporter := helps.NewExecutorUsageReporter(ctx, e, baseModel, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	built, err := e.buildRequest(ctx, auth, req, opts, reporter, true)
+	if err != nil {
+		return nil, err
+	}
+	httpResp, err := e.send(ctx, auth, reporter, built, true)
+	if err != nil {
+		return nil, err
+	}
+	originalPayload := req.Payload
+	if len(opts.OriginalRequest) > 0 {
+		originalPayload = opts.OriginalRequest
+	}
+
+	out := make(chan cliproxyexecutor.StreamChunk)
+	go func() {
+		defer close(out)
+		defer func() {
+			if errClose := httpResp.Body.Close(); errClose != nil {
+				log.Errorf("azure openai executor: close response body error: %v", errClose)
+			}
+		}()
+		claudeInputTokens := helps.NewClaudeInputTokenState(from, built.to, responseFormat, originalPayload)
+		var param any
+		var streamUsage helps.StreamUsageBuffer
+		defer streamUsage.Publish(ctx, reporter)
+
+		emit := func(eventData []byte) bool {
+			line := append([]byte("data: "), eventData...)
+			chunks := helps.TranslateStreamWithClaudeInputTokens(ctx, built.to, responseFormat, req.Model, opts.OriginalRequest, built.body, line, &param, claudeInputTokens)
+			for i := range chunks {
+				select {
+				case out <- cliproxyexecutor.StreamChunk{Payload: chunks[i]}:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		var errStream error
+		if built.responses {
+			errStream = e.readResponsesEvents(ctx, httpResp.Body, func(eventData []byte, terminal bool) bool {
+				if terminal {
+					if detail, ok := helps.ParseCodexUsage(eventData); ok {
+						streamUsage.Observe(detail, true)
+					}
+				}
+				return emit(eventData)
+			})
+		} else {
+			errStream = e.readChatEvents(ctx, httpResp.Body, &streamUsage, emit)
+		}
+		if errStream != nil && ctx.Err() == nil {
+			helps.RecordAPIResponseError(ctx, e.cfg, errStream)
+			reporter.PublishFailure(ctx, errStream)
+			select {
+			case out <- cliproxyexecutor.StreamChunk{Err: errStream}:
+			case <-ctx.Done():
+			}
+			return
+		}
+		streamUsage.Publish(ctx, reporter)
+		reporter.EnsurePublished(ctx)
+	}()
+	return &cliproxyexecutor.StreamResult{Headers: httpResp.Header.Clone(), Chunks: out}, nil
+}
+
+// readChatEvents reads a Chat Completions SSE stream and hands each data payload,
+// including the closing [DONE], to emit. A stream that ends without [DONE] is closed
+// with a synthetic one so translators can finish their output.
+func (e *AzureOpenAIExecutor) readChatEvents(ctx context.Context, body io.Reader, streamUsage *helps.StreamUsageBuffer, emit func([]byte) bool) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(nil, 52_428_800) // 50MB
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		helps.AppendAPIResponseChunk(ctx, e.cfg, line)
+		streamUsage.ObserveOpenAIStream(line)
+		trimmed := bytes.TrimSpace(line)
+		if !bytes.HasPrefix(trimmed, dataTag) {
+			continue
+		}
+		payload := bytes.TrimSpace(trimmed[len(dataTag):])
+		if len(payload) == 0 {
+			continue
+		}
+		if bytes.Equal(payload, []byte("[DONE]")) {
+			emit(payload)
+			return nil
+		}
+		if streamErr, isError := openAICompatStreamDataError(payload, ""); isError {
+			return azureOpenAIClassifyError(streamErr, payload)
+		}
+		if !emit(bytes.Clone(payload)) {
+			return nil
+		}
+	}
+	if errScan := scanner.Err(); errScan != nil {
+		return errScan
+	}
+	emit([]byte("[DONE]"))
+	return nil
+}
+
+// readResponsesEvents reads a Responses API SSE stream and hands each event payload to
+// emit. response.completed and response.incomplete are flagged as terminal, with their
+// output rebuilt from response.output_item.done events when upstream leaves it empty.
+// Failure events are returned as classified status errors.
+func (e *AzureOpenAIExecutor) readResponsesEvents(ctx context.Context, body io.Reader, emit func(eventData []byte, terminal bool) bool) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(nil, 52_428_800) // 50MB
+	outputItemsByIndex := make(map[int64][]byte)
+	var outputItemsFallback [][]byte
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		helps.AppendAPIResponseChunk(ctx, e.cfg, line)
+		if !bytes.HasPrefix(line, dataTag) {
+			continue
+		}
+		eventData := bytes.Clone(bytes.TrimSpace(line[len(dataTag):]))
+		if len(eventData) == 0 || bytes.Equal(eventData, []byte("[DONE]")) {
+			continue
+		}
+		if streamErr, terminalBody, ok := codexTerminalFailureErr(eventData); ok {
+			return azureOpenAIClassifyError(streamErr, terminalBody)
+		}
+		terminal := false
+		switch gjson.GetBytes(eventData, "type").String() {
+		case "response.output_item.done":
+			collectCodexOutputItemDone(eventData, outputItemsByIndex, &outputItemsFallback)
+		case "response.completed", "response.incomplete":
+			terminal = true
+			eventData = patchCodexCompletedOutput(eventData, outputItemsByIndex, outputItemsFallback)
+		}
+		if !emit(eventData, terminal) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// CountTokens estimates the token count locally; Azure OpenAI has no count endpoint.
+func (e *AzureOpenAIExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+	from := opts.SourceFormat
+	responseFormat := cliproxyexecutor.ResponseFormatOrSource(opts)
+	to := sdktranslator.FormatOpenAI
+	translated := helps.TranslateRequestWithCodexMultiAgentV2(ctx, opts.Headers, e.cfg, from, to, baseModel, bytes.Clone(req.Payload), false)
+
+	enc, err := helps.TokenizerForModel(baseModel)
+	if err != nil {
+		return cliproxyexecutor.Response{}, fmt.Errorf("azure openai executor: tokenizer init failed: %w", err)
+	}
+	count, err := helps.CountOpenAIChatTokens(enc, translated)
+	if err != nil {
+		return cliproxyexecutor.Response{}, fmt.Errorf("azure openai executor: token counting failed: %w", err)
+	}
+	usageJSON := helps.BuildOpenAIUsageJSON(count)
+	translatedUsage := sdktranslator.TranslateTokenCount(ctx, to, responseFormat, count, usageJSON)
+	return cliproxyexecutor.Response{Payload: translatedUsage}, nil
+}
+
+// azureContentFilterError is a request rejected by Azure's content filtering. The same
+// prompt is filtered on every credential sharing the policy, so it is request-scoped.
+// Per-credential request-scoped-errors rules still take precedence.
+type azureContentFilterError struct {
+	statusErr
+}
+
+func (azureContentFilterError) IsRequestScoped() bool {
+	return true
+}
+
+// isAzureContentFilterBody reports whether an Azure error body is a content-filter rejection.
+func isAzureContentFilterBody(body []byte) bool {
+	for _, path := range []string{"error.code", "error.innererror.code", "response.error.code"} {
+		switch gjson.GetBytes(body, path).String() {
+		case "content_filter", "ResponsibleAIPolicyViolation", "content_policy_violation":
+			return true
+		}
+	}
+	return false
+}
+
+// azureOpenAIClassifyError marks content-filter rejections as request-scoped.
+func azureOpenAIClassifyError(err statusErr, body []byte) error {
+	if isAzureContentFilterBody(body) {
+		if err.code < http.StatusBadRequest || err.code >= http.StatusInternalServerError {
+			err.code = http.StatusBadRequest
+		}
+		return azureContentFilterError{statusErr: err}
+	}
+	return err
+}
+
+// azureOpenAIHTTPError classifies a non-2xx Azure OpenAI response. Throttling responses
+// carry their back-off in retry-after-ms or Retry-After.
+func azureOpenAIHTTPError(status int, header http.Header, body []byte) error {
+	err := statusErr{code: status, msg: string(body)}
+	if ms, errParse := strconv.Atoi(strings.TrimSpace(header.Get("retry-after-ms"))); errParse == nil && ms > 0 {
+		retryAfter := time.Duration(ms) * time.Millisecond
+		err.retryAfter = &retryAfter
+	} else if seconds, errParse := strconv.Atoi(strings.TrimSpace(header.Get("Retry-After"))); errParse == nil && seconds > 0 {
+		retryAfter := time.Duration(seconds) * time.Second
+		err.retryAfter = &retryAfter
+	}
+	return azureOpenAIClassifyError(err, body)
+}
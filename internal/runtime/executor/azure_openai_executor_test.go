@@ -0,0 +1,228 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func azureOpenAITestConfig(endpoint string) *config.Config {
+	return &config.Config{AzureOpenAIKey: []config.AzureOpenAIKey{{
+		Endpoint:   endpoint,
+		APIVersion: "2024-10-21",
+		APIKey:     "azure-key",
+		Deployments: []config.AzureOpenAIDeployment{
+			{Name: "prod-gpt4o", Alias: "gpt-4o"},
+			{Name: "prod-gpt5", Alias: "gpt-5", WireAPI: config.AzureOpenAIWireAPIResponses},
+		},
+	}}}
+}
+
+func azureOpenAITestAuth(endpoint string) *cliproxyauth.Auth {
+	return &cliproxyauth.Auth{
+		ID:       "azure-auth",
+		Provider: "azure-openai",
+		Attributes: map[string]string{
+			"base_url":                        endpoint,
+			"api_version":                     "2024-10-21",
+			"api_key":                         "azure-key",
+			cliproxyauth.AttributeConfigIndex: "0",
+		},
+	}
+}
+
+func TestAzureOpenAIExecutorChatUsesDeploymentPath(t *testing.T) {
+	var gotPath, gotVersion, gotKey, gotAuthorization string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotVersion = r.URL.Query().Get("api-version")
+		gotKey = r.Header.Get("api-key")
+		gotAuthorization = r.Header.Get("Authorization")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o-2024-08-06","choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`))
+	}))
+	defer server.Close()
+
+	exec := NewAzureOpenAIExecutor(azureOpenAITestConfig(server.URL))
+	resp, err := exec.Execute(context.Background(), azureOpenAITestAuth(server.URL), cliproxyexecutor.Request{
+		Model:   "gpt-4o",
+		Payload: []byte(`{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatOpenAI})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if gotPath != "/openai/deployments/prod-gpt4o/chat/completions" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if gotVersion != "2024-10-21" {
+		t.Fatalf("api-version = %q", gotVersion)
+	}
+	if gotKey != "azure-key" || gotAuthorization != "" {
+		t.Fatalf("api-key = %q, Authorization = %q", gotKey, gotAuthorization)
+	}
+	if len(gotBody) == 0 {
+		t.Fatal("upstream body is empty")
+	}
+	if got := gjson.GetBytes(resp.Payload, "choices.0.message.content").String(); got != "hello" {
+		t.Fatalf("content = %q, payload %s", got, resp.Payload)
+	}
+}
+
+func TestAzureOpenAIExecutorResponsesClientsUseResponsesAPI(t *testing.T) {
+	var gotPath string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: response.output_item.done\n" +
+			`data: {"type":"response.output_item.done","output_index":0,"item":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"hello"}]}}` + "\n\n" +
+			"event: response.completed\n" +
+			`data: {"type":"response.completed","response":{"id":"resp_1","object":"response","status":"completed","model":"prod-gpt5","output":[],"usage":{"input_tokens":3,"output_tokens":1,"total_tokens":4}}}` + "\n\n"))
+	}))
+	defer server.Close()
+
+	exec := NewAzureOpenAIExecutor(azureOpenAITestConfig(server.URL))
+	resp, err := exec.Execute(context.Background(), azureOpenAITestAuth(server.URL), cliproxyexecutor.Request{
+		Model:   "gpt-5",
+		Payload: []byte(`{"model":"gpt-5","input":"hi"}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatOpenAIResponse})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if gotPath != "/openai/responses" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if got := gjson.GetBytes(gotBody, "model").String(); got != "prod-gpt5" {
+		t.Fatalf("upstream model = %q, want deployment name", got)
+	}
+	if !gjson.GetBytes(gotBody, "stream").Bool() {
+		t.Fatalf("upstream body must stream: %s", gotBody)
+	}
+	if got := gjson.GetBytes(resp.Payload, "output.0.content.0.text").String(); got != "hello" {
+		t.Fatalf("output text = %q, payload %s", got, resp.Payload)
+	}
+}
+
+func TestAzureOpenAIExecutorEntraIDToken(t *testing.T) {
+	var tokenRequests atomic.Int32
+	var gotForm, gotAuthorization, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/oauth2/v2.0/token") {
+			tokenRequests.Add(1)
+			body, _ := io.ReadAll(r.Body)
+			gotForm = string(body)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"token_type":"Bearer","expires_in":3599,"access_token":"entra-token"}`))
+			return
+		}
+		gotAuthorization = r.Header.Get("Authorization")
+		gotKey = r.Header.Get("api-key")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	auth := &cliproxyauth.Auth{
+		ID:       "azure-entra",
+		Provider: "azure-openai",
+		Attributes: map[string]string{
+			"base_url":       server.URL,
+			"tenant_id":      "tenant",
+			"client_id":      "client",
+			"client_secret":  "secret",
+			"authority_host": server.URL,
+		},
+	}
+	exec := NewAzureOpenAIExecutor(&config.Config{})
+	refreshed, err := exec.Refresh(context.Background(), auth)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if got, _ := refreshed.Metadata["access_token"].(string); got != "entra-token" {
+		t.Fatalf("metadata access_token = %q", got)
+	}
+	if expiry, ok := refreshed.ExpirationTime(); !ok || time.Until(expiry) < 50*time.Minute {
+		t.Fatalf("ExpirationTime() = %v, %v", expiry, ok)
+	}
+	for _, want := range []string{"grant_type=client_credentials", "client_id=client", "scope=https%3A%2F%2Fcognitiveservices.azure.com%2F.default"} {
+		if !strings.Contains(gotForm, want) {
+			t.Fatalf("token form = %q, missing %q", gotForm, want)
+		}
+	}
+
+	_, err = exec.Execute(context.Background(), refreshed, cliproxyexecutor.Request{
+		Model:   "gpt-4o",
+		Payload: []byte(`{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatOpenAI})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if gotAuthorization != "Bearer entra-token" || gotKey != "" {
+		t.Fatalf("Authorization = %q, api-key = %q", gotAuthorization, gotKey)
+	}
+	if got := tokenRequests.Load(); got != 1 {
+		t.Fatalf("token requests = %d, want refreshed token reused", got)
+	}
+}
+
+func TestAzureOpenAIExecutorEntraIDRejectedClientIsUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"AADSTS7000215: Invalid client secret provided."}`))
+	}))
+	defer server.Close()
+
+	exec := NewAzureOpenAIExecutor(&config.Config{})
+	_, err := exec.Refresh(context.Background(), &cliproxyauth.Auth{
+		ID:       "azure-entra",
+		Provider: "azure-openai",
+		Attributes: map[string]string{
+			"base_url":       server.URL,
+			"tenant_id":      "tenant",
+			"client_id":      "client",
+			"client_secret":  "wrong",
+			"authority_host": server.URL,
+		},
+	})
+	var status statusErr
+	if !errors.As(err, &status) || status.StatusCode() != http.StatusUnauthorized {
+		t.Fatalf("Refresh() error = %v, want 401", err)
+	}
+}
+
+func TestAzureOpenAIHTTPErrorContentFilterIsRequestScoped(t *testing.T) {
+	err := azureOpenAIHTTPError(http.StatusBadRequest, http.Header{}, []byte(`{"error":{"message":"The response was filtered","code":"content_filter","status":400,"innererror":{"code":"ResponsibleAIPolicyViolation"}}}`))
+	scoped, ok := err.(interface{ IsRequestScoped() bool })
+	if !ok || !scoped.IsRequestScoped() {
+		t.Fatalf("content filter error = %#v, want request-scoped", err)
+	}
+
+	header := http.Header{}
+	header.Set("retry-after-ms", "1500")
+	err = azureOpenAIHTTPError(http.StatusTooManyRequests, header, []byte(`{"error":{"code":"429","message":"Rate limit reached"}}`))
+	if _, scopedErr := err.(interface{ IsRequestScoped() bool }); scopedErr {
+		t.Fatalf("throttling error must not be request-scoped: %#v", err)
+	}
+	var status statusErr
+	if !errors.As(err, &status) || status.RetryAfter() == nil || *status.RetryAfter() != 1500*time.Millisecond {
+		t.Fatalf("throttling error = %#v, want retryAfter 1.5s", err)
+	}
+}
@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// awsEventStreamMaxMessageLength bounds a single event-stream frame; the AWS protocol
+// caps messages at 16 MiB.
+const awsEventStreamMaxMessageLength = 16 << 20
+
+var errAWSEventStreamChecksum = errors.New("aws event stream: checksum mismatch")
+
+// awsEventStreamMessage is one decoded frame of the application/vnd.amazon.eventstream
+// encoding used by Bedrock streaming responses. Only string-valued headers are kept.
+type awsEventStreamMessage struct {
+	Headers map[string]string
+	Payload []byte
+}
+
+// readAWSEventStreamMessage decodes the next frame from r. Each frame is a 12-byte
+// prelude (total length, headers length, prelude CRC32), the headers, the payload and a
+// trailing CRC32 of everything before it, all big-endian. It returns io.EOF when r ends
+// cleanly between frames.
+func readAWSEventStreamMessage(r io.Reader) (awsEventStreamMessage, error) {
+	var msg awsEventStreamMessage
+	prelude := make([]byte, 12)
+	if _, err := io.ReadFull(r, prelude); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return msg, fmt.Errorf("aws event stream: truncated prelude: %w", err)
+		}
+		return msg, err
+	}
+	totalLength := binary.BigEndian.Uint32(prelude[0:4])
+	headersLength := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return msg, errAWSEventStreamChecksum
+	}
+	if totalLength < 16 || totalLength > awsEventStreamMaxMessageLength || headersLength > totalLength-16 {
+		return msg, fmt.Errorf("aws event stream: invalid frame lengths (total %d, headers %d)", totalLength, headersLength)
+	}
+
+	frame := make([]byte, totalLength)
+	copy(frame, prelude)
+	if _, err := io.ReadFull(r, frame[12:]); err != nil {
+		return msg, fmt.Errorf("aws event stream: truncated frame: %w", err)
+	}
+	if crc32.ChecksumIEEE(frame[:totalLength-4]) != binary.BigEndian.Uint32(frame[totalLength-4:]) {
+		return msg, errAWSEventStreamChecksum
+	}
+
+	headers, err := parseAWSEventStreamHeaders(frame[12 : 12+headersLength])
+	if err != nil {
+		return msg, err
+	}
+	msg.Headers = headers
+	msg.Payload = frame[12+headersLength : totalLength-4]
+	return msg, nil
+}
+
+// parseAWSEventStreamHeaders decodes the header block. Each header is a one-byte name
+// length, the name, a one-byte value type and a type-dependent value.
+func parseAWSEventStreamHeaders(data []byte) (map[string]string, error) {
+	headers := make(map[string]string)
+	errTruncated := errors.New("aws event stream: truncated headers")
+	for len(data) > 0 {
+		nameLength := int(data[0])
+		if len(data) < 1+nameLength+1 {
+			return nil, errTruncated
+		}
+		name := string(data[1 : 1+nameLength])
+		valueType := data[1+nameLength]
+		data = data[2+nameLength:]
+
+		var size int
+		switch valueType {
+		case 0, 1: // bool true, bool false
+			size = 0
+		case 2: // byte
+			size = 1
+		case 3: // int16
+			size = 2
+		case 4: // int32
+			size = 4
+		case 5, 8: // int64, timestamp
+			size = 8
+		case 9: // uuid
+			size = 16
+		case 6, 7: // byte array, string
+			if len(data) < 2 {
+				return nil, errTruncated
+			}
+			valueLength := int(binary.BigEndian.Uint16(data[:2]))
+			if len(data) < 2+valueLength {
+				return nil, errTruncated
+			}
+			if valueType == 7 {
+				headers[name] = string(data[2 : 2+valueLength])
+			}
+			data = data[2+valueLength:]
+			continue
+		default:
+			return nil, fmt.Errorf("aws event stream: unknown header type %d", valueType)
+		}
+		if len(data) < size {
+			return nil, errTruncated
+		}
+		data = data[size:]
+	}
+	return headers, nil
+}
@@ -0,0 +1,608 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/runtime/executor/helps"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	// bedrockAnthropicVersion is the Messages API version Bedrock expects in InvokeModel bodies.
+	bedrockAnthropicVersion = "bedrock-2023-05-31"
+	bedrockSigningService   = "bedrock"
+)
+
+// BedrockExecutor is a stateless executor for AWS Bedrock. Anthropic models are called
+// through InvokeModel with a Messages API body; every other model goes through the
+// Converse API. Requests are signed with SigV4, or carry a Bedrock API key as a bearer token.
+type BedrockExecutor struct {
+	cfg *config.Config
+}
+
+// NewBedrockExecutor creates a new Bedrock executor.
+func NewBedrockExecutor(cfg *config.Config) *BedrockExecutor { return &BedrockExecutor{cfg: cfg} }
+
+// Identifier returns the executor identifier.
+func (e *BedrockExecutor) Identifier() string { return "bedrock" }
+
+// bedrockCredentials describes how a Bedrock auth authenticates and where it sends requests.
+type bedrockCredentials struct {
+	apiKey  string
+	aws     awsCredentials
+	region  string
+	baseURL string
+}
+
+func bedrockCreds(auth *cliproxyauth.Auth) bedrockCredentials {
+	creds := bedrockCredentials{region: config.DefaultBedrockRegion}
+	if auth == nil || auth.Attributes == nil {
+		return creds
+	}
+	attrs := auth.Attributes
+	if region := strings.TrimSpace(attrs["region"]); region != "" {
+		creds.region = region
+	}
+	if secret := attrs["secret_access_key"]; secret != "" {
+		creds.aws = awsCredentials{
+			AccessKeyID:     attrs["access_key_id"],
+			SecretAccessKey: secret,
+			SessionToken:    attrs["session_token"],
+		}
+	} else {
+		creds.apiKey = attrs["api_key"]
+	}
+	creds.baseURL = strings.TrimRight(strings.TrimSpace(attrs["base_url"]), "/")
+	if creds.baseURL == "" {
+		creds.baseURL = "https://bedrock-runtime." + creds.region + ".amazonaws.com"
+	}
+	return creds
+}
+
+// authorize adds the bearer API key or a SigV4 signature to req.
+func (c bedrockCredentials) authorize(req *http.Request, body []byte) {
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		return
+	}
+	signAWSRequestV4(req, body, c.aws, c.region, bedrockSigningService, time.Now())
+}
+
+// bedrockDatedClaudeModel matches Claude catalog IDs that carry a release date, which
+// is part of every Anthropic foundation model ID on Bedrock.
+var bedrockDatedClaudeModel = regexp.MustCompile(`^claude-[a-z0-9.-]+-\d{8}$`)
+
+// BedrockModelID maps a model name onto a Bedrock model ID. Names that already look
+// like Bedrock IDs, inference profiles or ARNs are used as-is, and dated Claude catalog
+// IDs map to their "anthropic.<model>-v1:0" foundation model IDs. The boolean is false
+// for other Claude names: their Bedrock ID cannot be derived and must be configured
+// under bedrock-api-key models.
+func BedrockModelID(model string) (string, bool) {
+	model = strings.TrimSpace(model)
+	if strings.Contains(model, ".") || strings.HasPrefix(model, "arn:") {
+		return model, true
+	}
+	if bedrockDatedClaudeModel.MatchString(model) {
+		return "anthropic." + model + "-v1:0", true
+	}
+	if strings.HasPrefix(model, "claude-") {
+		return "", false
+	}
+	return model, true
+}
+
+// bedrockIsAnthropicModel reports whether modelID accepts the Anthropic Messages body of InvokeModel.
+func bedrockIsAnthropicModel(modelID string) bool {
+	return strings.Contains(strings.ToLower(modelID), "anthropic.")
+}
+
+// PrepareRequest injects Bedrock credentials into the outgoing HTTP request.
+func (e *BedrockExecutor) PrepareRequest(req *http.Request, auth *cliproxyauth.Auth) error {
+	if req == nil {
+		return nil
+	}
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	util.ApplyCustomHeadersFromAttrs(req, attrs)
+	var body []byte
+	if req.GetBody != nil {
+		reader, errBody := req.GetBody()
+		if errBody != nil {
+			return errBody
+		}
+		body, errBody = io.ReadAll(reader)
+		if errBody != nil {
+			return errBody
+		}
+	}
+	bedrockCreds(auth).authorize(req, body)
+	return nil
+}
+
+// HttpRequest injects Bedrock credentials into the request and executes it.
+func (e *BedrockExecutor) HttpRequest(ctx context.Context, auth *cliproxyauth.Auth, req *http.Request) (*http.Response, error) {
+	if req == nil {
+		return nil, fmt.Errorf("bedrock executor: request is nil")
+	}
+	if ctx == nil {
+		ctx = req.Context()
+	}
+	httpReq := req.WithContext(ctx)
+	if err := e.PrepareRequest(httpReq, auth); err != nil {
+		return nil, err
+	}
+	httpClient := helps.NewProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	return httpClient.Do(httpReq)
+}
+
+// bedrockRequest is a translated request ready to send, together with the Anthropic
+// Messages body it was derived from, which response translation uses as the request payload.
+type bedrockRequest struct {
+	modelID    string
+	converse   bool
+	claudeBody []byte
+	body       []byte
+}
+
+// buildRequest translates the client payload to the Anthropic Messages format and, for
+// non-Anthropic models, on to a Converse request.
+func (e *BedrockExecutor) buildRequest(ctx context.Context, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, reporter *helps.UsageReporter, stream bool) (bedrockRequest, error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+	from := opts.SourceFormat
+	to := sdktranslator.FormatClaude
+	originalPayloadSource := req.Payload
+	if len(opts.OriginalRequest) > 0 {
+		originalPayloadSource = opts.OriginalRequest
+	}
+	originalTranslated := helps.TranslateRequestWithCodexMultiAgentV2(ctx, opts.Headers, e.cfg, from, to, baseModel, bytes.Clone(originalPayloadSource), stream)
+	body := helps.TranslateRequestWithCodexMultiAgentV2(ctx, opts.Headers, e.cfg, from, to, baseModel, bytes.Clone(req.Payload), stream)
+
+	body, err := helps.ApplyThinkingWithSourcePayload(body, req.Payload, originalPayloadSource, req.Model, from.String(), to.String(), e.Identifier())
+	if err != nil {
+		return bedrockRequest{}, err
+	}
+	requestedModel := helps.PayloadRequestedModel(opts, req.Model)
+	requestPath := helps.PayloadRequestPath(opts)
+	body = helps.ApplyPayloadConfigWithRequest(e.cfg, baseModel, to.String(), from.String(), "", body, originalTranslated, requestedModel, requestPath, opts.Headers)
+	reporter.SetTranslatedReasoningEffort(body, to.String())
+
+	modelID, ok := BedrockModelID(baseModel)
+	if !ok {
+		return bedrockRequest{}, statusErr{code: http.StatusBadRequest, msg: fmt.Sprintf("bedrock executor: model %q has no Bedrock model ID; map it under bedrock-api-key models", baseModel)}
+	}
+	out := bedrockRequest{modelID: modelID, claudeBody: body}
+	if !bedrockIsAnthropicModel(out.modelID) {
+		out.converse = true
+		out.body = sdktranslator.TranslateRequest(to, sdktranslator.FormatBedrockConverse, out.modelID, body, stream)
+		return out, nil
+	}
+	// InvokeModel takes the model and streaming mode from the URL and rejects them, and
+	// other fields Bedrock does not accept, in the body.
+	invokeBody := bytes.Clone(body)
+	for _, field := range []string{"model", "stream", "metadata", "service_tier"} {
+		invokeBody, _ = sjson.DeleteBytes(invokeBody, field)
+	}
+	invokeBody, err = sjson.SetBytes(invokeBody, "anthropic_version", bedrockAnthropicVersion)
+	if err != nil {
+		return bedrockRequest{}, fmt.Errorf("bedrock executor: failed to set anthropic_version: %w", err)
+	}
+	out.body = invokeBody
+	return out, nil
+}
+
+// send builds, signs, logs and executes the upstream request for the given operation.
+func (e *BedrockExecutor) send(ctx context.Context, auth *cliproxyauth.Auth, reporter *helps.UsageReporter, built bedrockRequest, stream bool) (*http.Response, error) {
+	operation := "invoke"
+	if built.converse {
+		operation = "converse"
+	}
+	if stream {
+		if built.converse {
+			operation = "converse-stream"
+		} else {
+			operation = "invoke-with-response-stream"
+		}
+	}
+	creds := bedrockCreds(auth)
+	rawPath := "/model/" + awsURIEncode(built.modelID, true) + "/" + operation
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, creds.baseURL+rawPath, bytes.NewReader(built.body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if stream {
+		httpReq.Header.Set("Accept", "application/vnd.amazon.eventstream")
+	} else {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	util.ApplyCustomHeadersFromAttrs(httpReq, attrs)
+	creds.authorize(httpReq, built.body)
+
+	var authID, authLabel, authType, authValue string
+	if auth != nil {
+		authID = auth.ID
+		authLabel = auth.Label
+		authType, authValue = auth.AccountInfo()
+	}
+	helps.RecordAPIRequest(ctx, e.cfg, helps.UpstreamRequestLog{
+		URL:       httpReq.URL.String(),
+		Method:    http.MethodPost,
+		Headers:   httpReq.Header.Clone(),
+		Body:      built.body,
+		Provider:  e.Identifier(),
+		AuthID:    authID,
+		AuthLabel: authLabel,
+		AuthType:  authType,
+		AuthValue: authValue,
+	})
+
+	httpClient := helps.NewProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	httpClient = reporter.TrackHTTPClient(httpClient)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		helps.RecordAPIResponseError(ctx, e.cfg, err)
+		return nil, err
+	}
+	helps.RecordAPIResponseMetadata(ctx, e.cfg, httpResp.StatusCode, httpResp.Header.Clone())
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		b, _ := io.ReadAll(httpResp.Body)
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("bedrock executor: close response body error: %v", errClose)
+		}
+		helps.AppendAPIResponseChunk(ctx, e.cfg, b)
+		helps.LogWithRequestID(ctx).Debugf("request error, error status: %d, error message: %s", httpResp.StatusCode, helps.SummarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
+		return nil, bedrockHTTPError(httpResp.StatusCode, httpResp.Header, b)
+	}
+	return httpResp, nil
+}
+
+// Execute performs a non-streaming request to Bedrock. Like the Claude executor, it streams
+// from upstream when the client expects a non-Claude format, because the Claude response
+// translators consume an SSE transcript.
+func (e *BedrockExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+	responseFormat := cliproxyexecutor.ResponseFormatOrSource(opts)
+	upstreamStream := responseFormat != sdktranslator.FormatClaude
+
+	reporter := helps.NewExecutorUsageReporter(ctx, e, baseModel, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	built, err := e.buildRequest(ctx, req, opts, reporter, upstreamStream)
+	if err != nil {
+		return resp, err
+	}
+	httpResp, err := e.send(ctx, auth, reporter, built, upstreamStream)
+	if err != nil {
+		return resp, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("bedrock executor: close response body error: %v", errClose)
+		}
+	}()
+
+	var data []byte
+	if upstreamStream {
+		var transcript bytes.Buffer
+		var streamUsage helps.StreamUsageBuffer
+		err = e.readClaudeEvents(ctx, httpResp.Body, built, req, opts, &streamUsage, func(event []byte) bool {
+			transcript.Write(event)
+			return true
+		})
+		if err != nil {
+			helps.RecordAPIResponseError(ctx, e.cfg, err)
+			return resp, err
+		}
+		streamUsage.Publish(ctx, reporter)
+		data = transcript.Bytes()
+	} else {
+		data, err = io.ReadAll(httpResp.Body)
+		if err != nil {
+			helps.RecordAPIResponseError(ctx, e.cfg, err)
+			return resp, err
+		}
+		helps.AppendAPIResponseChunk(ctx, e.cfg, data)
+		if built.converse {
+			reporter.Publish(ctx, helps.ParseBedrockConverseUsage(data))
+			var converseParam any
+			data = sdktranslator.TranslateNonStream(ctx, sdktranslator.FormatBedrockConverse, sdktranslator.FormatClaude, req.Model, opts.OriginalRequest, built.claudeBody, data, &converseParam)
+		} else {
+			reporter.Publish(ctx, helps.ParseClaudeUsage(data))
+			data, _ = sjson.SetBytes(data, "model", req.Model)
+		}
+	}
+	var param any
+	out := sdktranslator.TranslateNonStream(ctx, sdktranslator.FormatClaude, responseFormat, req.Model, opts.OriginalRequest, built.claudeBody, data, &param)
+	if responseFormat == sdktranslator.FormatOpenAIResponse {
+		out = helps.EnsureResponsesUsageDetails(out)
+	}
+	resp = cliproxyexecutor.Response{Payload: out, Headers: httpResp.Header.Clone()}
+	return resp, nil
+}
+
+// ExecuteStream performs a streaming request to Bedrock.
+func (e *BedrockExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (_ *cliproxyexecutor.StreamResult, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+	responseFormat := cliproxyexecutor.ResponseFormatOrSource(opts)
+
+	reporter := helps.NewExecutorUsageReporter(ctx, e, baseModel, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	built, err := e.buildRequest(ctx, req, opts, reporter, true)
+	if err != nil {
+		return nil, err
+	}
+	httpResp, err := e.send(ctx, auth, reporter, built, true)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan cliproxyexecutor.StreamChunk)
+	go func() {
+		defer close(out)
+		defer func() {
+			if errClose := httpResp.Body.Close(); errClose != nil {
+				log.Errorf("bedrock executor: close response body error: %v", errClose)
+			}
+		}()
+		var streamUsage helps.StreamUsageBuffer
+		defer streamUsage.Publish(ctx, reporter)
+
+		var param any
+		errStream := e.readClaudeEvents(ctx, httpResp.Body, built, req, opts, &streamUsage, func(event []byte) bool {
+			chunks := [][]byte{event}
+			if responseFormat != sdktranslator.FormatClaude {
+				chunks = nil
+				for _, line := range bytes.Split(bytes.TrimSuffix(event, []byte("\n")), []byte("\n")) {
+					chunks = append(chunks, sdktranslator.TranslateStream(ctx, sdktranslator.FormatClaude, responseFormat, req.Model, opts.OriginalRequest, built.claudeBody, bytes.Clone(line), &param)...)
+				}
+			}
+			for i := range chunks {
+				if responseFormat == sdktranslator.FormatOpenAIResponse {
+					chunks[i] = helps.EnsureResponsesUsageDetails(chunks[i])
+				}
+				select {
+				case out <- cliproxyexecutor.StreamChunk{Payload: chunks[i]}:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		})
+		if errStream != nil && ctx.Err() == nil {
+			helps.RecordAPIResponseError(ctx, e.cfg, errStream)
+			reporter.PublishFailure(ctx, errStream)
+			select {
+			case out <- cliproxyexecutor.StreamChunk{Err: errStream}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return &cliproxyexecutor.StreamResult{Headers: httpResp.Header.Clone(), Chunks: out}, nil
+}
+
+// readClaudeEvents decodes an InvokeModelWithResponseStream or ConverseStream event stream
+// and hands each event to emit as an Anthropic SSE event, stopping early when emit returns
+// false. Exception frames are returned as status errors so throttling cools the auth down.
+func (e *BedrockExecutor) readClaudeEvents(ctx context.Context, body io.Reader, built bedrockRequest, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, streamUsage *helps.StreamUsageBuffer, emit func([]byte) bool) error {
+	var claudeUsage bedrockClaudeStreamUsage
+	var converseParam any
+	for {
+		msg, errRead := readAWSEventStreamMessage(body)
+		if errRead == io.EOF {
+			break
+		}
+		if errRead != nil {
+			return errRead
+		}
+		switch msg.Headers[":message-type"] {
+		case "exception":
+			helps.AppendAPIResponseChunk(ctx, e.cfg, msg.Payload)
+			return bedrockStreamException(msg.Headers[":exception-type"], msg.Payload)
+		case "error":
+			return statusErr{code: http.StatusBadGateway, msg: msg.Headers[":error-code"] + ": " + msg.Headers[":error-message"]}
+		}
+
+		eventType := msg.Headers[":event-type"]
+		if !built.converse {
+			if eventType != "chunk" {
+				continue
+			}
+			event, errDecode := base64.StdEncoding.DecodeString(gjson.GetBytes(msg.Payload, "bytes").String())
+			if errDecode != nil {
+				return fmt.Errorf("bedrock executor: decode stream chunk: %w", errDecode)
+			}
+			helps.AppendAPIResponseChunk(ctx, e.cfg, event)
+			claudeUsage.observe(event)
+			if !emit(bedrockClaudeSSEEvent(event, req.Model)) {
+				return nil
+			}
+			continue
+		}
+
+		wrapped := []byte(`{}`)
+		wrapped, _ = sjson.SetRawBytes(wrapped, eventType, msg.Payload)
+		helps.AppendAPIResponseChunk(ctx, e.cfg, wrapped)
+		if eventType == "metadata" {
+			streamUsage.Observe(helps.ParseBedrockConverseUsage(msg.Payload), true)
+		}
+		for _, event := range sdktranslator.TranslateStream(ctx, sdktranslator.FormatBedrockConverse, sdktranslator.FormatClaude, req.Model, opts.OriginalRequest, built.claudeBody, wrapped, &converseParam) {
+			if !emit(event) {
+				return nil
+			}
+		}
+	}
+
+	if built.converse {
+		for _, event := range sdktranslator.TranslateStream(ctx, sdktranslator.FormatBedrockConverse, sdktranslator.FormatClaude, req.Model, opts.OriginalRequest, built.claudeBody, []byte("[DONE]"), &converseParam) {
+			if !emit(event) {
+				return nil
+			}
+		}
+	} else if claudeUsage.seen {
+		streamUsage.Observe(helps.ParseClaudeUsage(claudeUsage.raw), true)
+	}
+	return nil
+}
+
+// CountTokens estimates the token count locally; Bedrock has no count endpoint shared by all models.
+func (e *BedrockExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+	from := opts.SourceFormat
+	responseFormat := cliproxyexecutor.ResponseFormatOrSource(opts)
+	to := sdktranslator.FormatOpenAI
+	translated := helps.TranslateRequestWithCodexMultiAgentV2(ctx, opts.Headers, e.cfg, from, to, baseModel, bytes.Clone(req.Payload), false)
+
+	enc, err := helps.TokenizerForModel(baseModel)
+	if err != nil {
+		return cliproxyexecutor.Response{}, fmt.Errorf("bedrock executor: tokenizer init failed: %w", err)
+	}
+	count, err := helps.CountOpenAIChatTokens(enc, translated)
+	if err != nil {
+		return cliproxyexecutor.Response{}, fmt.Errorf("bedrock executor: token counting failed: %w", err)
+	}
+	usageJSON := helps.BuildOpenAIUsageJSON(count)
+	translatedUsage := sdktranslator.TranslateTokenCount(ctx, to, responseFormat, count, usageJSON)
+	return cliproxyexecutor.Response{Payload: translatedUsage}, nil
+}
+
+// Refresh is a no-op; Bedrock credentials come from static configuration.
+func (e *BedrockExecutor) Refresh(ctx context.Context, auth *cliproxyauth.Auth) (*cliproxyauth.Auth, error) {
+	log.Debugf("bedrock executor: refresh called")
+	_ = ctx
+	return auth, nil
+}
+
+// bedrockClaudeSSEEvent renders one InvokeModel stream event as an Anthropic SSE event,
+// restoring the client-facing model name and dropping Bedrock's invocation metrics.
+func bedrockClaudeSSEEvent(event []byte, model string) []byte {
+	eventType := gjson.GetBytes(event, "type").String()
+	switch eventType {
+	case "message_start":
+		event, _ = sjson.SetBytes(event, "message.model", model)
+	case "message_stop":
+		event, _ = sjson.DeleteBytes(event, "amazon-bedrock-invocationMetrics")
+	}
+	out := make([]byte, 0, len(event)+len(eventType)+17)
+	out = append(out, "event: "...)
+	out = append(out, eventType...)
+	out = append(out, "\ndata: "...)
+	out = append(out, event...)
+	out = append(out, "\n\n"...)
+	return out
+}
+
+// bedrockClaudeStreamUsage merges the usage reported by message_start (input and cache
+// tokens) with the usage reported by message_delta (output tokens).
+type bedrockClaudeStreamUsage struct {
+	raw  []byte
+	seen bool
+}
+
+func (u *bedrockClaudeStreamUsage) observe(event []byte) {
+	root := gjson.ParseBytes(event)
+	node := root.Get("usage")
+	if root.Get("type").String() == "message_start" {
+		node = root.Get("message.usage")
+	}
+	if !node.IsObject() {
+		return
+	}
+	if !u.seen {
+		u.raw = []byte(`{"usage":{}}`)
+		u.seen = true
+	}
+	node.ForEach(func(key, value gjson.Result) bool {
+		if value.Type == gjson.Number && value.Int() > 0 {
+			u.raw, _ = sjson.SetBytes(u.raw, "usage."+key.String(), value.Int())
+		}
+		return true
+	})
+}
+
+// bedrockExceptionStatus maps a Bedrock exception name onto the HTTP status it stands for.
+// Throttling and quota exceptions map to 429 so the auth manager cools the credential
+// down; capacity problems map to 503. It returns 0 for unknown names.
+func bedrockExceptionStatus(name string) int {
+	switch strings.ToLower(name) {
+	case "throttlingexception", "toomanyrequestsexception", "servicequotaexceededexception", "modelnotreadyexception":
+		return http.StatusTooManyRequests
+	case "serviceunavailableexception":
+		return http.StatusServiceUnavailable
+	case "internalserverexception":
+		return http.StatusInternalServerError
+	case "modeltimeoutexception":
+		return http.StatusRequestTimeout
+	case "validationexception":
+		return http.StatusBadRequest
+	case "accessdeniedexception":
+		return http.StatusForbidden
+	case "resourcenotfoundexception":
+		return http.StatusNotFound
+	case "modelstreamerrorexception", "modelerrorexception":
+		return http.StatusBadGateway
+	default:
+		return 0
+	}
+}
+
+// bedrockHTTPError classifies a non-2xx Bedrock response. The exception name comes from
+// the X-Amzn-ErrorType header ("ThrottlingException:http://...") or the body's __type.
+func bedrockHTTPError(status int, header http.Header, body []byte) statusErr {
+	errorType := header.Get("X-Amzn-ErrorType")
+	if errorType == "" {
+		errorType = gjson.GetBytes(body, "__type").String()
+		if idx := strings.LastIndex(errorType, "#"); idx >= 0 {
+			errorType = errorType[idx+1:]
+		}
+	}
+	if idx := strings.Index(errorType, ":"); idx >= 0 {
+		errorType = errorType[:idx]
+	}
+	code := status
+	if mapped := bedrockExceptionStatus(errorType); mapped == http.StatusTooManyRequests || mapped == http.StatusServiceUnavailable {
+		code = mapped
+	}
+	err := statusErr{code: code, msg: string(body)}
+	if seconds, errParse := strconv.Atoi(strings.TrimSpace(header.Get("Retry-After"))); errParse == nil && seconds > 0 {
+		retryAfter := time.Duration(seconds) * time.Second
+		err.retryAfter = &retryAfter
+	}
+	return err
+}
+
+// bedrockStreamException converts an exception frame received mid-stream into a status error.
+func bedrockStreamException(exceptionType string, payload []byte) statusErr {
+	code := bedrockExceptionStatus(exceptionType)
+	if code == 0 {
+		code = http.StatusBadGateway
+	}
+	msg := string(payload)
+	if msg == "" {
+		msg = exceptionType
+	}
+	return statusErr{code: code, msg: msg}
+}
@@ -0,0 +1,316 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+// encodeAWSEventStreamMessage frames payload with string headers the way Bedrock does.
+func encodeAWSEventStreamMessage(headers map[string]string, payload []byte) []byte {
+	var headerBlock bytes.Buffer
+	for name, value := range headers {
+		headerBlock.WriteByte(byte(len(name)))
+		headerBlock.WriteString(name)
+		headerBlock.WriteByte(7)
+		_ = binary.Write(&headerBlock, binary.BigEndian, uint16(len(value)))
+		headerBlock.WriteString(value)
+	}
+	totalLength := 12 + headerBlock.Len() + len(payload) + 4
+	frame := make([]byte, 0, totalLength)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(totalLength))
+	frame = binary.BigEndian.AppendUint32(frame, uint32(headerBlock.Len()))
+	frame = binary.BigEndian.AppendUint32(frame, crc32.ChecksumIEEE(frame[:8]))
+	frame = append(frame, headerBlock.Bytes()...)
+	frame = append(frame, payload...)
+	return binary.BigEndian.AppendUint32(frame, crc32.ChecksumIEEE(frame))
+}
+
+func bedrockEvent(eventType, payload string) []byte {
+	return encodeAWSEventStreamMessage(map[string]string{
+		":message-type": "event",
+		":event-type":   eventType,
+		":content-type": "application/json",
+	}, []byte(payload))
+}
+
+func bedrockTestAuth(baseURL string) *cliproxyauth.Auth {
+	return &cliproxyauth.Auth{
+		ID:       "bedrock-auth",
+		Provider: "bedrock",
+		Attributes: map[string]string{
+			"base_url":          baseURL,
+			"region":            "us-west-2",
+			"api_key":           "AKIDEXAMPLE",
+			"access_key_id":     "AKIDEXAMPLE",
+			"secret_access_key": "secret",
+			"session_token":     "session",
+		},
+	}
+}
+
+func TestSignAWSRequestV4MatchesReferenceSignature(t *testing.T) {
+	// Reference request and signature from the AWS Signature Version 4 documentation.
+	req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signAWSRequestV4(req, nil, awsCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}, "us-east-1", "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Fatalf("Authorization = %q, want %q", got, want)
+	}
+}
+
+func TestReadAWSEventStreamMessage(t *testing.T) {
+	frame := bedrockEvent("chunk", `{"bytes":"e30="}`)
+	stream := bytes.NewReader(append(bytes.Clone(frame), frame...))
+	for i := 0; i < 2; i++ {
+		msg, err := readAWSEventStreamMessage(stream)
+		if err != nil {
+			t.Fatalf("frame %d: error = %v", i, err)
+		}
+		if msg.Headers[":event-type"] != "chunk" || string(msg.Payload) != `{"bytes":"e30="}` {
+			t.Fatalf("frame %d = %+v", i, msg)
+		}
+	}
+	if _, err := readAWSEventStreamMessage(stream); err != io.EOF {
+		t.Fatalf("error after last frame = %v, want io.EOF", err)
+	}
+
+	corrupted := bytes.Clone(frame)
+	corrupted[len(corrupted)-6] ^= 0xff
+	if _, err := readAWSEventStreamMessage(bytes.NewReader(corrupted)); !errors.Is(err, errAWSEventStreamChecksum) {
+		t.Fatalf("corrupted frame error = %v, want checksum mismatch", err)
+	}
+}
+
+func TestBedrockModelID(t *testing.T) {
+	for input, want := range map[string]string{
+		"claude-sonnet-4-5-20250929":                    "anthropic.claude-sonnet-4-5-20250929-v1:0",
+		"us.anthropic.claude-sonnet-4-20250514-v1:0":    "us.anthropic.claude-sonnet-4-20250514-v1:0",
+		"meta.llama3-70b-instruct-v1:0":                 "meta.llama3-70b-instruct-v1:0",
+		"arn:aws:bedrock:us-east-1:1:inference-profile": "arn:aws:bedrock:us-east-1:1:inference-profile",
+	} {
+		if got, ok := BedrockModelID(input); !ok || got != want {
+			t.Fatalf("BedrockModelID(%q) = %q, %v, want %q", input, got, ok, want)
+		}
+	}
+	for _, input := range []string{"claude-sonnet-4-6", "claude-opus-4-6"} {
+		if got, ok := BedrockModelID(input); ok {
+			t.Fatalf("BedrockModelID(%q) = %q, want no derived ID", input, got)
+		}
+	}
+}
+
+func TestBedrockExecutorInvokeModelSignsRequest(t *testing.T) {
+	var gotPath, gotAuth, gotToken string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotAuth = r.Header.Get("Authorization")
+		gotToken = r.Header.Get("X-Amz-Security-Token")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","model":"anthropic.claude-sonnet-4-5-20250929-v1:0","content":[{"type":"text","text":"hello"}],"stop_reason":"end_turn","usage":{"input_tokens":3,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	exec := NewBedrockExecutor(&config.Config{})
+	resp, err := exec.Execute(context.Background(), bedrockTestAuth(server.URL), cliproxyexecutor.Request{
+		Model:   "claude-sonnet-4-5-20250929",
+		Payload: []byte(`{"model":"claude-sonnet-4-5","max_tokens":64,"metadata":{"user_id":"u"},"messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatClaude})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if gotPath != "/model/anthropic.claude-sonnet-4-5-20250929-v1%3A0/invoke" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(gotAuth, "/us-west-2/bedrock/aws4_request") {
+		t.Fatalf("Authorization = %q", gotAuth)
+	}
+	if gotToken != "session" {
+		t.Fatalf("X-Amz-Security-Token = %q, want session", gotToken)
+	}
+	if got := gjson.GetBytes(gotBody, "anthropic_version").String(); got != bedrockAnthropicVersion {
+		t.Fatalf("anthropic_version = %q, body %s", got, gotBody)
+	}
+	for _, field := range []string{"model", "stream", "metadata"} {
+		if gjson.GetBytes(gotBody, field).Exists() {
+			t.Fatalf("body must not carry %s: %s", field, gotBody)
+		}
+	}
+	if got := gjson.GetBytes(resp.Payload, "model").String(); got != "claude-sonnet-4-5-20250929" {
+		t.Fatalf("model = %q, want client model", got)
+	}
+	if got := gjson.GetBytes(resp.Payload, "content.0.text").String(); got != "hello" {
+		t.Fatalf("content = %q, payload %s", got, resp.Payload)
+	}
+}
+
+func TestBedrockExecutorExecuteStreamsUpstreamForOtherFormats(t *testing.T) {
+	events := []string{
+		`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"anthropic.claude","content":[],"usage":{"input_tokens":4,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"ok"}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":2}}`,
+		`{"type":"message_stop","amazon-bedrock-invocationMetrics":{"inputTokenCount":4,"outputTokenCount":2}}`,
+	}
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		for _, event := range events {
+			payload := `{"bytes":"` + base64.StdEncoding.EncodeToString([]byte(event)) + `"}`
+			_, _ = w.Write(bedrockEvent("chunk", payload))
+		}
+	}))
+	defer server.Close()
+
+	exec := NewBedrockExecutor(&config.Config{})
+	resp, err := exec.Execute(context.Background(), bedrockTestAuth(server.URL), cliproxyexecutor.Request{
+		Model:   "claude-sonnet-4-5-20250929",
+		Payload: []byte(`{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatOpenAI})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.HasSuffix(gotPath, "/invoke-with-response-stream") {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if got := gjson.GetBytes(resp.Payload, "choices.0.message.content").String(); got != "ok" {
+		t.Fatalf("content = %q, payload %s", got, resp.Payload)
+	}
+	if got := gjson.GetBytes(resp.Payload, "usage.completion_tokens").Int(); got != 2 {
+		t.Fatalf("completion_tokens = %d, payload %s", got, resp.Payload)
+	}
+}
+
+func TestBedrockExecutorConverseStreamTranslatesEvents(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotAuth = r.Header.Get("Authorization")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		for _, frame := range [][]byte{
+			bedrockEvent("messageStart", `{"role":"assistant"}`),
+			bedrockEvent("contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"Hel"}}`),
+			bedrockEvent("contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"lo"}}`),
+			bedrockEvent("contentBlockStop", `{"contentBlockIndex":0}`),
+			bedrockEvent("messageStop", `{"stopReason":"end_turn"}`),
+			bedrockEvent("metadata", `{"usage":{"inputTokens":5,"outputTokens":2,"totalTokens":7},"metrics":{"latencyMs":10}}`),
+		} {
+			_, _ = w.Write(frame)
+		}
+	}))
+	defer server.Close()
+
+	auth := bedrockTestAuth(server.URL)
+	auth.Attributes = map[string]string{"base_url": server.URL, "api_key": "bedrock-key"}
+	exec := NewBedrockExecutor(&config.Config{})
+	result, err := exec.ExecuteStream(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "meta.llama3-70b-instruct-v1:0",
+		Payload: []byte(`{"model":"llama","stream":true,"max_tokens":64,"messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatClaude, Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var stream bytes.Buffer
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("stream error = %v", chunk.Err)
+		}
+		stream.Write(chunk.Payload)
+	}
+
+	if gotPath != "/model/meta.llama3-70b-instruct-v1%3A0/converse-stream" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if gotAuth != "Bearer bedrock-key" {
+		t.Fatalf("Authorization = %q", gotAuth)
+	}
+	if got := gjson.GetBytes(gotBody, "messages.0.content.0.text").String(); got != "hi" {
+		t.Fatalf("converse body = %s", gotBody)
+	}
+	if got := gjson.GetBytes(gotBody, "inferenceConfig.maxTokens").Int(); got != 64 {
+		t.Fatalf("maxTokens = %d, body %s", got, gotBody)
+	}
+	out := stream.String()
+	for _, want := range []string{"event: message_start", `"text":"Hel"`, `"text":"lo"`, `"stop_reason":"end_turn"`, `"output_tokens":2`, "event: message_stop"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("stream missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestBedrockExecutorStreamThrottlingExceptionCoolsDown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(encodeAWSEventStreamMessage(map[string]string{
+			":message-type":   "exception",
+			":exception-type": "throttlingException",
+		}, []byte(`{"message":"Too many requests, please wait before trying again."}`)))
+	}))
+	defer server.Close()
+
+	exec := NewBedrockExecutor(&config.Config{})
+	result, err := exec.ExecuteStream(context.Background(), bedrockTestAuth(server.URL), cliproxyexecutor.Request{
+		Model:   "claude-sonnet-4-5-20250929",
+		Payload: []byte(`{"model":"claude-sonnet-4-5-20250929","stream":true,"max_tokens":64,"messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatClaude, Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var streamErr error
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+		}
+	}
+	var status statusErr
+	if !errors.As(streamErr, &status) || status.StatusCode() != http.StatusTooManyRequests {
+		t.Fatalf("stream error = %v, want 429 status error", streamErr)
+	}
+}
+
+func TestBedrockHTTPErrorClassifiesThrottling(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Amzn-ErrorType", "ServiceQuotaExceededException:http://internal.amazon.com/coral/com.amazon.bedrock/")
+	header.Set("Retry-After", "7")
+	err := bedrockHTTPError(http.StatusBadRequest, header, []byte(`{"message":"quota"}`))
+	if err.StatusCode() != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", err.StatusCode())
+	}
+	if err.RetryAfter() == nil || *err.RetryAfter() != 7*time.Second {
+		t.Fatalf("retryAfter = %v, want 7s", err.RetryAfter())
+	}
+
+	err = bedrockHTTPError(http.StatusBadRequest, http.Header{}, []byte(`{"__type":"com.amazon.coral.validate#ValidationException","message":"bad"}`))
+	if err.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("validation status = %d, want 400", err.StatusCode())
+	}
+}
@@ -73,6 +73,9 @@ func (e *GeminiExecutor) Identifier() string {
 
 // RequestToFormat reports the upstream request format used after auth selection.
 func (e *GeminiExecutor) RequestToFormat(req cliproxyexecutor.Request, opts cliproxyexecutor.Options) sdktranslator.Format {
+	if isOpenAIEmbeddingRequest(opts) {
+		return opts.SourceFormat
+	}
 	if strings.EqualFold(strings.TrimSpace(e.Identifier()), "gemini-interactions") && nativeInteractionsSourceFormat(opts.SourceFormat) {
 		return sdktranslator.FormatInteractions
 	}
@@ -126,6 +129,9 @@ func (e *GeminiExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, r
 	if opts.Alt == "responses/compact" {
 		return resp, statusErr{code: http.StatusNotImplemented, msg: "/responses/compact not supported"}
 	}
+	if isOpenAIEmbeddingRequest(opts) {
+		return e.executeEmbeddings(ctx, auth, req, opts)
+	}
 	if shouldExecuteNativeInteractions(auth, opts) {
 		return e.executeInteractions(ctx, auth, req, opts)
 	}
@@ -7,6 +7,7 @@ import (
 	multiagentv2 "github.com/router-for-me/CLIProxyAPI/v7/internal/client/codex/optimize-multi-agent-v2"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/tracing"
 	openaichatclaude "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/claude/openai/chat-completions"
 	responsesclaude "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/claude/openai/responses"
 	codexclaude "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/codex/claude"
@@ -32,6 +33,13 @@ func RewriteCodexMultiAgentV2Input(ctx context.Context, headers http.Header, pay
 // TranslateRequestWithCodexMultiAgentV2 normalizes official Codex multi-agent
 // input before translating it to a non-Codex target protocol.
 func TranslateRequestWithCodexMultiAgentV2(ctx context.Context, headers http.Header, cfg *config.Config, from, to sdktranslator.Format, model string, payload []byte, stream bool) []byte {
+	ctx, span := tracing.Start(ctx, "translate.request",
+		tracing.AttrSourceFormat.String(from.String()),
+		tracing.AttrTargetFormat.String(to.String()),
+		tracing.AttrModel.String(model),
+		tracing.AttrStream.Bool(stream),
+	)
+	defer span.End()
 	return multiagentv2.TranslateRequestWithCodexMultiAgentV2(ctx, headers, cfg, from, to, model, payload, stream)
 }
 
@@ -0,0 +1,84 @@
+package helps
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+// PayloadRuleMatch is a payload rule whose model entry names a request's model.
+type PayloadRuleMatch struct {
+	// Section is the payload config list holding the rule: default, default-raw,
+	// override, override-raw or filter.
+	Section string
+	// Index is the 1-based position of the rule in its section.
+	Index int
+	// Entry is the matching model entry. Its protocol, header and payload conditions
+	// are still evaluated per request.
+	Entry config.PayloadModelRule
+	// Params lists the parameter paths the rule writes or removes, sorted.
+	Params []string
+}
+
+// MatchingPayloadRules lists the payload rules that would be considered for a request
+// arriving in fromProtocol for model, where requestedModel is the client-visible name
+// before alias resolution. Only the model name and source protocol gates are checked,
+// so a listed rule may still be skipped at runtime by its remaining conditions.
+func MatchingPayloadRules(cfg *config.Config, model, requestedModel, fromProtocol string) []PayloadRuleMatch {
+	if cfg == nil {
+		return nil
+	}
+	candidates := payloadModelCandidates(model, requestedModel)
+	if len(candidates) == 0 {
+		return nil
+	}
+	var out []PayloadRuleMatch
+	collect := func(section string, index int, entries []config.PayloadModelRule, params []string) {
+		entry, ok := payloadModelEntryForCandidates(entries, fromProtocol, candidates)
+		if !ok {
+			return
+		}
+		sort.Strings(params)
+		out = append(out, PayloadRuleMatch{Section: section, Index: index + 1, Entry: entry, Params: params})
+	}
+	rules := cfg.Payload
+	for _, set := range []struct {
+		section string
+		rules   []config.PayloadRule
+	}{
+		{"default", rules.Default},
+		{"default-raw", rules.DefaultRaw},
+		{"override", rules.Override},
+		{"override-raw", rules.OverrideRaw},
+	} {
+		for i := range set.rules {
+			params := make([]string, 0, len(set.rules[i].Params))
+			for path := range set.rules[i].Params {
+				params = append(params, path)
+			}
+			collect(set.section, i, set.rules[i].Models, params)
+		}
+	}
+	for i := range rules.Filter {
+		collect("filter", i, rules.Filter[i].Models, append([]string(nil), rules.Filter[i].Params...))
+	}
+	return out
+}
+
+func payloadModelEntryForCandidates(entries []config.PayloadModelRule, fromProtocol string, models []string) (config.PayloadModelRule, bool) {
+	for _, model := range models {
+		for _, entry := range entries {
+			if strings.TrimSpace(entry.Name) == "" {
+				continue
+			}
+			if !payloadFromProtocolMatches(entry.FromProtocol, fromProtocol) {
+				continue
+			}
+			if matchModelPattern(entry.Name, model) {
+				return entry, true
+			}
+		}
+	}
+	return config.PayloadModelRule{}, false
+}
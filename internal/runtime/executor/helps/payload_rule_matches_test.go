@@ -0,0 +1,38 @@
+package helps
+
+import (
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+func TestMatchingPayloadRulesChecksModelAndSourceProtocol(t *testing.T) {
+	cfg := &config.Config{Payload: config.PayloadConfig{
+		Default: []config.PayloadRule{
+			{Models: []config.PayloadModelRule{{Name: "gpt-*"}}, Params: map[string]any{"b": 1, "a": 2}},
+		},
+		Override: []config.PayloadRule{
+			{Models: []config.PayloadModelRule{{Name: "claude-*"}}, Params: map[string]any{"x": 1}},
+			{Models: []config.PayloadModelRule{{Name: "team/*", FromProtocol: "openai-responses", Protocol: "codex"}}, Params: map[string]any{"y": 1}},
+		},
+		Filter: []config.PayloadFilterRule{
+			{Models: []config.PayloadModelRule{{Name: "*", FromProtocol: "claude"}}, Params: []string{"metadata"}},
+		},
+	}}
+
+	matches := MatchingPayloadRules(cfg, "gpt-5", "team/fast(high)", "responses")
+	if len(matches) != 2 {
+		t.Fatalf("matches = %+v, want default[1] and override[2]", matches)
+	}
+	if got := matches[0]; got.Section != "default" || got.Index != 1 || len(got.Params) != 2 || got.Params[0] != "a" {
+		t.Fatalf("first match = %+v", got)
+	}
+	if got := matches[1]; got.Section != "override" || got.Index != 2 || got.Entry.Protocol != "codex" {
+		t.Fatalf("second match = %+v", got)
+	}
+
+	matches = MatchingPayloadRules(cfg, "claude-sonnet-4", "", "claude")
+	if len(matches) != 2 || matches[0].Section != "override" || matches[1].Section != "filter" {
+		t.Fatalf("claude matches = %+v", matches)
+	}
+}
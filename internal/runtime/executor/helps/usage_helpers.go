@@ -15,6 +15,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/clienterror"
 	internallogging "github.com/router-for-me/CLIProxyAPI/v7/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/tracing"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
 	"github.com/tidwall/gjson"
@@ -36,6 +37,8 @@ type UsageReporter struct {
 	reasoning       string
 	serviceTier     string
 	generate        bool
+	fallbackHop     int
+	hedgeAttempt    int
 	requestedAt     time.Time
 	ttftMu          sync.RWMutex
 	ttft            time.Duration
@@ -65,16 +68,18 @@ func NewUsageReporter(ctx context.Context, provider, model string, auth *cliprox
 		alias = model
 	}
 	reporter := &UsageReporter{
-		provider:    provider,
-		model:       model,
-		alias:       strings.TrimSpace(alias),
-		requestedAt: time.Now(),
-		apiKey:      apiKey,
-		source:      resolveUsageSource(auth, apiKey),
-		authType:    resolveUsageAuthType(auth),
-		reasoning:   usage.ReasoningEffortFromContext(ctx),
-		serviceTier: usage.ServiceTierFromContext(ctx),
-		generate:    usage.GenerateFromContext(ctx),
+		provider:     provider,
+		model:        model,
+		alias:        strings.TrimSpace(alias),
+		requestedAt:  time.Now(),
+		apiKey:       apiKey,
+		source:       resolveUsageSource(auth, apiKey),
+		authType:     resolveUsageAuthType(auth),
+		reasoning:    usage.ReasoningEffortFromContext(ctx),
+		serviceTier:  usage.ServiceTierFromContext(ctx),
+		generate:     usage.GenerateFromContext(ctx),
+		fallbackHop:  usage.FallbackHopFromContext(ctx),
+		hedgeAttempt: usage.HedgeAttemptFromContext(ctx),
 	}
 	if auth != nil {
 		reporter.authID = auth.ID
@@ -142,10 +147,10 @@ func (r *UsageReporter) TrackHTTPClient(client *http.Client) *http.Client {
 	if transport == nil {
 		transport = http.DefaultTransport
 	}
-	tracked.Transport = usageTTFTRoundTripper{
+	tracked.Transport = tracing.WrapTransport(usageTTFTRoundTripper{
 		base:     transport,
 		reporter: r,
-	}
+	})
 	return &tracked
 }
 
@@ -263,6 +268,13 @@ func (r *UsageReporter) EnsurePublished(ctx context.Context) {
 
 func (r *UsageReporter) publishRecord(ctx context.Context, record usage.Record) {
 	record.ResponseHeaders = internallogging.GetResponseHeaders(ctx)
+	tracing.RecordUsage(ctx, record.Provider, record.Model, record.AuthIndex, tracing.Usage{
+		InputTokens:     record.Detail.InputTokens,
+		OutputTokens:    record.Detail.OutputTokens,
+		ReasoningTokens: record.Detail.ReasoningTokens,
+		CachedTokens:    record.Detail.CachedTokens,
+		TotalTokens:     record.Detail.TotalTokens,
+	})
 	usage.PublishRecord(ctx, record)
 }
 
@@ -296,6 +308,8 @@ func (r *UsageReporter) buildRecordForModel(model string, detail usage.Detail, f
 		ServiceTier:         r.serviceTier,
 		ResponseServiceTier: strings.TrimSpace(detail.ResponseServiceTier),
 		Generate:            usage.GenerateFlag(r.generate),
+		FallbackHop:         r.fallbackHop,
+		HedgeAttempt:        r.hedgeAttempt,
 		RequestedAt:         r.requestedAt,
 		Latency:             r.latency(),
 		TTFT:                r.ttftDuration(),
@@ -640,6 +654,18 @@ func parseOpenAIStyleUsageNode(usageNode gjson.Result) usage.Detail {
 	if reasoning.Exists() {
 		detail.ReasoningTokens = reasoning.Int()
 	}
+	detail.InputAudioTokens = firstExistingUsageNode(
+		usageNode,
+		"prompt_tokens_details.audio_tokens",
+		"input_tokens_details.audio_tokens",
+		"input_token_details.audio_tokens",
+	).Int()
+	detail.OutputAudioTokens = firstExistingUsageNode(
+		usageNode,
+		"completion_tokens_details.audio_tokens",
+		"output_tokens_details.audio_tokens",
+		"output_token_details.audio_tokens",
+	).Int()
 	if hasOpenAIStyleUsageBucketFields(usageNode) {
 		if inputNode.Exists() && outputNode.Exists() {
 			detail.TokenBreakdown = usage.NewSubsetTokenBreakdown(
@@ -717,6 +743,40 @@ func ParseClaudeStreamUsage(line []byte) (usage.Detail, bool) {
 	return parseClaudeUsageNode(usageNode), true
 }
 
+// ParseBedrockConverseUsage extracts usage from a Bedrock Converse response or a
+// ConverseStream metadata event. Like the Messages API, inputTokens excludes cached tokens.
+func ParseBedrockConverseUsage(data []byte) usage.Detail {
+	usageNode := gjson.ParseBytes(data).Get("usage")
+	if !usageNode.Exists() {
+		return usage.Detail{}
+	}
+	cacheReadTokens := usageNode.Get("cacheReadInputTokens").Int()
+	cacheWriteTokens := usageNode.Get("cacheWriteInputTokens").Int()
+	detail := usage.Detail{
+		InputTokens:         usageNode.Get("inputTokens").Int(),
+		OutputTokens:        usageNode.Get("outputTokens").Int(),
+		CachedTokens:        cacheReadTokens,
+		CacheReadTokens:     cacheReadTokens,
+		CacheCreationTokens: cacheWriteTokens,
+		TotalTokens:         usageNode.Get("totalTokens").Int(),
+	}
+	if detail.CachedTokens == 0 {
+		detail.CachedTokens = detail.CacheCreationTokens
+	}
+	if detail.TotalTokens == 0 {
+		detail.TotalTokens = detail.InputTokens + detail.OutputTokens + cacheReadTokens + cacheWriteTokens
+	}
+	detail.TokenBreakdown = usage.NewIndependentTokenBreakdown(
+		detail.InputTokens,
+		detail.CacheReadTokens,
+		detail.CacheCreationTokens,
+		detail.OutputTokens,
+		0,
+		detail.TotalTokens,
+	)
+	return detail
+}
+
 func parseClaudeUsageNode(usageNode gjson.Result) usage.Detail {
 	cacheReadTokens := usageNode.Get("cache_read_input_tokens").Int()
 	cacheCreationTokens := usageNode.Get("cache_creation_input_tokens").Int()
@@ -779,6 +839,8 @@ func parseGeminiFamilyUsageDetail(node gjson.Result) usage.Detail {
 		CachedTokens:    cachedTokens,
 		CacheReadTokens: cachedTokens,
 	}
+	detail.InputAudioTokens = geminiAudioModalityTokens(node.Get("promptTokensDetails"))
+	detail.OutputAudioTokens = geminiAudioModalityTokens(node.Get("candidatesTokensDetails"))
 	if !okInput {
 		detail.TokenBreakdown = invalidUsageTokenBreakdown(detail.TotalTokens)
 		return detail
@@ -803,6 +865,17 @@ func parseGeminiFamilyUsageDetail(node gjson.Result) usage.Detail {
 	return detail
 }
 
+// geminiAudioModalityTokens sums the AUDIO entries of a Gemini per-modality token list.
+func geminiAudioModalityTokens(details gjson.Result) int64 {
+	var total int64
+	for _, item := range details.Array() {
+		if strings.EqualFold(item.Get("modality").String(), "AUDIO") {
+			total += item.Get("tokenCount").Int()
+		}
+	}
+	return total
+}
+
 func parseInteractionsUsageDetail(node gjson.Result) usage.Detail {
 	cacheRead := firstExistingUsageNode(node, "cache_read_tokens", "cacheReadTokens")
 	toolUseTokens := firstExistingUsageNode(node, "tool_use_tokens", "total_tool_use_tokens", "toolUseTokens", "totalToolUseTokens").Int()
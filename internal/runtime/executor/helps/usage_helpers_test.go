@@ -43,6 +43,17 @@ func TestParseOpenAIUsageChatCompletions(t *testing.T) {
 	}
 }
 
+func TestParseUsageAudioTokens(t *testing.T) {
+	openAI := ParseOpenAIUsage([]byte(`{"usage":{"prompt_tokens":30,"completion_tokens":40,"total_tokens":70,"prompt_tokens_details":{"audio_tokens":20},"completion_tokens_details":{"audio_tokens":35}}}`))
+	if openAI.InputAudioTokens != 20 || openAI.OutputAudioTokens != 35 {
+		t.Fatalf("openai audio tokens = %d/%d", openAI.InputAudioTokens, openAI.OutputAudioTokens)
+	}
+	gemini := ParseGeminiUsage([]byte(`{"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":50,"totalTokenCount":62,"promptTokensDetails":[{"modality":"TEXT","tokenCount":12}],"candidatesTokensDetails":[{"modality":"AUDIO","tokenCount":50}]}}`))
+	if gemini.InputAudioTokens != 0 || gemini.OutputAudioTokens != 50 {
+		t.Fatalf("gemini audio tokens = %d/%d", gemini.InputAudioTokens, gemini.OutputAudioTokens)
+	}
+}
+
 func TestParseOpenAIUsageResponses(t *testing.T) {
 	data := []byte(`{"service_tier":"default","usage":{"input_tokens":10,"output_tokens":20,"total_tokens":30,"input_tokens_details":{"cached_tokens":7},"output_tokens_details":{"reasoning_tokens":9}}}`)
 	detail := ParseOpenAIUsage(data)
@@ -0,0 +1,160 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/runtime/executor/helps"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	openAIAudioSourceFormat = "openai-audio"
+
+	openAICompatAudioTranscriptionsPath = "/audio/transcriptions"
+	openAICompatAudioTranslationsPath   = "/audio/translations"
+	openAICompatAudioSpeechPath         = "/audio/speech"
+)
+
+func isOpenAIAudioRequest(opts cliproxyexecutor.Options) bool {
+	return strings.EqualFold(strings.TrimSpace(opts.SourceFormat.String()), openAIAudioSourceFormat)
+}
+
+// buildOpenAIAudioMultipart rebuilds the multipart upload described by an audio envelope
+// (see internal/translator/gemini/openai/audio) with the model replaced by the upstream name.
+func buildOpenAIAudioMultipart(envelope []byte, model string) ([]byte, string, error) {
+	fileData, errDecode := base64.StdEncoding.DecodeString(gjson.GetBytes(envelope, "file.data").String())
+	if errDecode != nil {
+		return nil, "", fmt.Errorf("decode audio file: %w", errDecode)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if errWrite := writer.WriteField("model", model); errWrite != nil {
+		return nil, "", fmt.Errorf("write model field failed: %w", errWrite)
+	}
+	fields := gjson.GetBytes(envelope, "fields").Map()
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if key != "model" && key != "file" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range fields[key].Array() {
+			if errWrite := writer.WriteField(key, value.String()); errWrite != nil {
+				return nil, "", fmt.Errorf("write form field %s failed: %w", key, errWrite)
+			}
+		}
+	}
+
+	filename := gjson.GetBytes(envelope, "file.filename").String()
+	if filename == "" {
+		filename = "audio"
+	}
+	mimeType := gjson.GetBytes(envelope, "file.mime_type").String()
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", multipart.FileContentDisposition("file", filename))
+	header.Set("Content-Type", mimeType)
+	part, errCreate := writer.CreatePart(header)
+	if errCreate != nil {
+		return nil, "", fmt.Errorf("create file field failed: %w", errCreate)
+	}
+	if _, errWrite := part.Write(fileData); errWrite != nil {
+		return nil, "", fmt.Errorf("write file field failed: %w", errWrite)
+	}
+	if errClose := writer.Close(); errClose != nil {
+		return nil, "", fmt.Errorf("close multipart writer failed: %w", errClose)
+	}
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
+
+// wrapOpenAIAudioResponse passes JSON bodies through and wraps anything else (plain-text
+// transcripts, synthesized audio) in the envelope the audio handlers unwrap.
+func wrapOpenAIAudioResponse(data []byte, contentType string) []byte {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if strings.EqualFold(mediaType, "application/json") && gjson.ValidBytes(data) {
+		return data
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	out := []byte(`{"object":"audio.response"}`)
+	out, _ = sjson.SetBytes(out, "content_type", contentType)
+	out, _ = sjson.SetBytes(out, "data", base64.StdEncoding.EncodeToString(data))
+	return out
+}
+
+// executeAudio forwards OpenAI audio requests to the compatible provider's /audio endpoints.
+func (e *OpenAICompatExecutor) executeAudio(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	reporter := helps.NewExecutorUsageReporter(ctx, e, baseModel, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	baseURL, apiKey := e.resolveCredentials(auth)
+	if baseURL == "" {
+		err = statusErr{code: http.StatusUnauthorized, msg: "missing provider baseURL"}
+		return resp, err
+	}
+
+	var payload []byte
+	contentType := "application/json"
+	endpointPath := openAICompatAudioTranscriptionsPath
+	switch strings.ToLower(gjson.GetBytes(req.Payload, "task").String()) {
+	case "speech":
+		endpointPath = openAICompatAudioSpeechPath
+		payload, _ = sjson.DeleteBytes(req.Payload, "task")
+		payload = e.overrideModel(payload, baseModel)
+	case "translation":
+		endpointPath = openAICompatAudioTranslationsPath
+		fallthrough
+	default:
+		payload, contentType, err = buildOpenAIAudioMultipart(req.Payload, baseModel)
+		if err != nil {
+			err = statusErr{code: http.StatusBadRequest, msg: err.Error()}
+			return resp, err
+		}
+	}
+
+	url := strings.TrimSuffix(baseURL, "/") + endpointPath
+	data, headers, err := postDirectUpstreamRequest(ctx, e.cfg, auth, e.Identifier(), url, payload, reporter, func(httpReq *http.Request) error {
+		httpReq.Header.Set("Content-Type", contentType)
+		if apiKey != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+		httpReq.Header.Set("User-Agent", "cli-proxy-openai-compat")
+		var attrs map[string]string
+		if auth != nil {
+			attrs = auth.Attributes
+		}
+		util.ApplyCustomHeadersFromAttrs(httpReq, attrs, opts.Headers)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+	if gjson.ValidBytes(data) {
+		reporter.Publish(ctx, helps.ParseOpenAIUsage(data))
+	}
+	reporter.EnsurePublished(ctx)
+	resp = cliproxyexecutor.Response{Payload: wrapOpenAIAudioResponse(data, headers.Get("Content-Type")), Headers: headers}
+	return resp, nil
+}
@@ -0,0 +1,125 @@
+package executor
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func audioOptions() cliproxyexecutor.Options {
+	return cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString(openAIAudioSourceFormat)}
+}
+
+func TestOpenAICompatExecutorAudioTranscriptionRebuildsMultipart(t *testing.T) {
+	var gotPath, gotModel, gotLanguage, gotFile, gotFileType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm() error = %v", err)
+			return
+		}
+		gotModel = r.FormValue("model")
+		gotLanguage = r.FormValue("language")
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("FormFile() error = %v", err)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		gotFile = string(data)
+		gotFileType = header.Header.Get("Content-Type")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"text":"hi","usage":{"type":"tokens","input_tokens":12,"input_token_details":{"audio_tokens":10,"text_tokens":2},"output_tokens":1,"total_tokens":13}}`))
+	}))
+	defer server.Close()
+
+	executor := NewOpenAICompatExecutor("openai-compatibility", &config.Config{})
+	auth := &cliproxyauth.Auth{Provider: "openai-compatibility", Attributes: map[string]string{"base_url": server.URL + "/v1", "api_key": "test"}}
+	payload := []byte(`{"model":"whisper-alias","task":"transcription","file":{"filename":"a.wav","mime_type":"audio/wav","data":"` + base64.StdEncoding.EncodeToString([]byte("RIFF")) + `"},"fields":{"model":["whisper-alias"],"language":["en"]}}`)
+	resp, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "whisper-1", Payload: payload}, audioOptions())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if gotPath != "/v1/audio/transcriptions" || gotModel != "whisper-1" || gotLanguage != "en" {
+		t.Fatalf("path = %q model = %q language = %q", gotPath, gotModel, gotLanguage)
+	}
+	if gotFile != "RIFF" || gotFileType != "audio/wav" {
+		t.Fatalf("file = %q type = %q", gotFile, gotFileType)
+	}
+	if gjson.GetBytes(resp.Payload, "text").String() != "hi" {
+		t.Fatalf("payload = %s", resp.Payload)
+	}
+}
+
+func TestOpenAICompatExecutorAudioSpeechWrapsBinary(t *testing.T) {
+	var gotPath string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write([]byte{0xff, 0xfb, 0x90})
+	}))
+	defer server.Close()
+
+	executor := NewOpenAICompatExecutor("openai-compatibility", &config.Config{})
+	auth := &cliproxyauth.Auth{Provider: "openai-compatibility", Attributes: map[string]string{"base_url": server.URL + "/v1", "api_key": "test"}}
+	payload := []byte(`{"model":"tts-alias","task":"speech","input":"hello","voice":"alloy"}`)
+	resp, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "tts-1", Payload: payload}, audioOptions())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if gotPath != "/v1/audio/speech" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if gjson.GetBytes(gotBody, "task").Exists() || gjson.GetBytes(gotBody, "model").String() != "tts-1" {
+		t.Fatalf("upstream body = %s", gotBody)
+	}
+	if gjson.GetBytes(resp.Payload, "object").String() != "audio.response" || gjson.GetBytes(resp.Payload, "content_type").String() != "audio/mpeg" {
+		t.Fatalf("payload = %s", resp.Payload)
+	}
+	decoded, _ := base64.StdEncoding.DecodeString(gjson.GetBytes(resp.Payload, "data").String())
+	if len(decoded) != 3 || decoded[0] != 0xff {
+		t.Fatalf("decoded = %v", decoded)
+	}
+}
+
+func TestGeminiExecutorAudioTranscriptionUsesTranslator(t *testing.T) {
+	var gotPath string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"bonjour"}]}}],"usageMetadata":{"promptTokenCount":25,"candidatesTokenCount":2,"totalTokenCount":27,"promptTokensDetails":[{"modality":"AUDIO","tokenCount":25}]}}`))
+	}))
+	defer server.Close()
+
+	executor := NewGeminiExecutor(&config.Config{})
+	auth := &cliproxyauth.Auth{Provider: "gemini", Attributes: map[string]string{"api_key": "gk", "base_url": server.URL}}
+	payload := []byte(`{"model":"gemini-2.5-flash","task":"transcription","file":{"filename":"a.wav","mime_type":"audio/wav","data":"UklGRg=="}}`)
+	opts := audioOptions()
+	opts.OriginalRequest = payload
+	resp, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "gemini-2.5-flash", Payload: payload}, opts)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if gotPath != "/v1beta/models/gemini-2.5-flash:generateContent" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if got := gjson.GetBytes(gotBody, "contents.0.parts.1.inlineData.mimeType").String(); got != "audio/wav" {
+		t.Fatalf("upstream body = %s", gotBody)
+	}
+	if gjson.GetBytes(resp.Payload, "text").String() != "bonjour" || gjson.GetBytes(resp.Payload, "usage.input_token_details.audio_tokens").Int() != 25 {
+		t.Fatalf("payload = %s", resp.Payload)
+	}
+}
@@ -0,0 +1,433 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/runtime/executor/helps"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	openAIEmbeddingSourceFormat = "openai-embedding"
+	openAICompatEmbeddingsPath  = "/embeddings"
+
+	// geminiEmbeddingBatchLimit is the maximum number of requests per batchEmbedContents call.
+	geminiEmbeddingBatchLimit = 100
+	// vertexEmbeddingBatchLimit is the maximum number of instances per Vertex predict call.
+	vertexEmbeddingBatchLimit = 250
+)
+
+// openAIEmbeddingRequest is the subset of an OpenAI embeddings request that is
+// translated for Gemini and Vertex upstreams.
+type openAIEmbeddingRequest struct {
+	Inputs         []string
+	Dimensions     int64
+	TaskType       string
+	EncodingFormat string
+}
+
+func isOpenAIEmbeddingRequest(opts cliproxyexecutor.Options) bool {
+	return strings.EqualFold(strings.TrimSpace(opts.SourceFormat.String()), openAIEmbeddingSourceFormat)
+}
+
+// parseOpenAIEmbeddingRequest extracts text inputs and options from an OpenAI embeddings payload.
+// Token-array inputs are rejected because Google embedding APIs only accept text.
+func parseOpenAIEmbeddingRequest(payload []byte) (openAIEmbeddingRequest, error) {
+	var out openAIEmbeddingRequest
+	if !gjson.ValidBytes(payload) {
+		return out, statusErr{code: http.StatusBadRequest, msg: "invalid embeddings request body"}
+	}
+	input := gjson.GetBytes(payload, "input")
+	switch {
+	case input.Type == gjson.String:
+		out.Inputs = []string{input.String()}
+	case input.IsArray():
+		for _, item := range input.Array() {
+			if item.Type != gjson.String {
+				return out, statusErr{code: http.StatusBadRequest, msg: "embeddings input must be a string or an array of strings for this model"}
+			}
+			out.Inputs = append(out.Inputs, item.String())
+		}
+	}
+	if len(out.Inputs) == 0 {
+		return out, statusErr{code: http.StatusBadRequest, msg: "embeddings input must not be empty"}
+	}
+	out.Dimensions = gjson.GetBytes(payload, "dimensions").Int()
+	out.TaskType = strings.TrimSpace(gjson.GetBytes(payload, "task_type").String())
+	out.EncodingFormat = strings.ToLower(strings.TrimSpace(gjson.GetBytes(payload, "encoding_format").String()))
+	if out.EncodingFormat != "" && out.EncodingFormat != "float" && out.EncodingFormat != "base64" {
+		return out, statusErr{code: http.StatusBadRequest, msg: fmt.Sprintf("unsupported encoding_format %q", out.EncodingFormat)}
+	}
+	return out, nil
+}
+
+func chunkEmbeddingInputs(inputs []string, size int) [][]string {
+	if size <= 0 {
+		size = len(inputs)
+	}
+	chunks := make([][]string, 0, (len(inputs)+size-1)/size)
+	for start := 0; start < len(inputs); start += size {
+		end := min(start+size, len(inputs))
+		chunks = append(chunks, inputs[start:end])
+	}
+	return chunks
+}
+
+// buildGeminiBatchEmbedRequest builds a Gemini batchEmbedContents body.
+func buildGeminiBatchEmbedRequest(model string, inputs []string, embedReq openAIEmbeddingRequest) ([]byte, error) {
+	requests := make([]map[string]any, 0, len(inputs))
+	for _, text := range inputs {
+		item := map[string]any{
+			"model":   "models/" + model,
+			"content": map[string]any{"parts": []map[string]any{{"text": text}}},
+		}
+		if embedReq.Dimensions > 0 {
+			item["outputDimensionality"] = embedReq.Dimensions
+		}
+		if embedReq.TaskType != "" {
+			item["taskType"] = strings.ToUpper(embedReq.TaskType)
+		}
+		requests = append(requests, item)
+	}
+	return json.Marshal(map[string]any{"requests": requests})
+}
+
+// buildVertexEmbedRequest builds a Vertex AI text embedding predict body.
+func buildVertexEmbedRequest(inputs []string, embedReq openAIEmbeddingRequest) ([]byte, error) {
+	instances := make([]map[string]any, 0, len(inputs))
+	for _, text := range inputs {
+		instance := map[string]any{"content": text}
+		if embedReq.TaskType != "" {
+			instance["task_type"] = strings.ToUpper(embedReq.TaskType)
+		}
+		instances = append(instances, instance)
+	}
+	body := map[string]any{"instances": instances}
+	if embedReq.Dimensions > 0 {
+		body["parameters"] = map[string]any{"outputDimensionality": embedReq.Dimensions}
+	}
+	return json.Marshal(body)
+}
+
+// parseGeminiBatchEmbedResponse returns the raw JSON value arrays from a batchEmbedContents response.
+func parseGeminiBatchEmbedResponse(data []byte, expected int) ([]string, error) {
+	embeddings := gjson.GetBytes(data, "embeddings").Array()
+	if len(embeddings) != expected {
+		return nil, statusErr{code: http.StatusBadGateway, msg: fmt.Sprintf("gemini embeddings: expected %d embeddings, got %d", expected, len(embeddings))}
+	}
+	vectors := make([]string, 0, len(embeddings))
+	for _, embedding := range embeddings {
+		vectors = append(vectors, embedding.Get("values").Raw)
+	}
+	return vectors, nil
+}
+
+// countEmbeddingInputTokens approximates the prompt tokens of inputs. batchEmbedContents
+// reports no usage, so Gemini embeddings are counted locally.
+func countEmbeddingInputTokens(model string, inputs []string) int64 {
+	enc, errTokenizer := helps.TokenizerForModel(model)
+	if errTokenizer != nil {
+		return 0
+	}
+	var tokens int64
+	for _, input := range inputs {
+		count, errCount := enc.Count(input)
+		if errCount != nil {
+			continue
+		}
+		tokens += int64(count)
+	}
+	return tokens
+}
+
+// parseVertexEmbedResponse returns the raw JSON value arrays and token count from a Vertex predict response.
+func parseVertexEmbedResponse(data []byte, expected int) ([]string, int64, error) {
+	predictions := gjson.GetBytes(data, "predictions").Array()
+	if len(predictions) != expected {
+		return nil, 0, statusErr{code: http.StatusBadGateway, msg: fmt.Sprintf("vertex embeddings: expected %d predictions, got %d", expected, len(predictions))}
+	}
+	vectors := make([]string, 0, len(predictions))
+	var tokens int64
+	for _, prediction := range predictions {
+		vectors = append(vectors, prediction.Get("embeddings.values").Raw)
+		tokens += prediction.Get("embeddings.statistics.token_count").Int()
+	}
+	return vectors, tokens, nil
+}
+
+// buildOpenAIEmbeddingResponse renders embedding vectors as an OpenAI embeddings list response.
+func buildOpenAIEmbeddingResponse(model string, vectors []string, promptTokens int64, encodingFormat string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"object":"list","data":[`)
+	for i, raw := range vectors {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if strings.TrimSpace(raw) == "" {
+			raw = "[]"
+		}
+		embedding := []byte(raw)
+		if encodingFormat == "base64" {
+			encoded, errEncode := encodeEmbeddingBase64(raw)
+			if errEncode != nil {
+				return nil, errEncode
+			}
+			embedding, _ = json.Marshal(encoded)
+		}
+		fmt.Fprintf(&buf, `{"object":"embedding","index":%d,"embedding":%s}`, i, embedding)
+	}
+	modelJSON, _ := json.Marshal(model)
+	fmt.Fprintf(&buf, `],"model":%s,"usage":{"prompt_tokens":%d,"total_tokens":%d}}`, modelJSON, promptTokens, promptTokens)
+	return buf.Bytes(), nil
+}
+
+// encodeEmbeddingBase64 packs a JSON float array as little-endian float32 values, matching OpenAI's base64 encoding.
+func encodeEmbeddingBase64(raw string) (string, error) {
+	var values []float64
+	if errUnmarshal := json.Unmarshal([]byte(raw), &values); errUnmarshal != nil {
+		return "", fmt.Errorf("decode embedding values: %w", errUnmarshal)
+	}
+	packed := make([]byte, 4*len(values))
+	for i, value := range values {
+		binary.LittleEndian.PutUint32(packed[i*4:], math.Float32bits(float32(value)))
+	}
+	return base64.StdEncoding.EncodeToString(packed), nil
+}
+
+// postDirectUpstreamRequest sends one non-translated upstream call (embeddings, audio)
+// and returns the response body and headers. prepare may override the JSON Content-Type.
+func postDirectUpstreamRequest(ctx context.Context, cfg *config.Config, auth *cliproxyauth.Auth, provider, url string, body []byte, reporter *helps.UsageReporter, prepare func(*http.Request) error) ([]byte, http.Header, error) {
+	httpReq, errNewReq := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if errNewReq != nil {
+		return nil, nil, errNewReq
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if errPrepare := prepare(httpReq); errPrepare != nil {
+		return nil, nil, errPrepare
+	}
+	var authID, authLabel, authType, authValue string
+	if auth != nil {
+		authID = auth.ID
+		authLabel = auth.Label
+		authType, authValue = auth.AccountInfo()
+	}
+	helps.RecordAPIRequest(ctx, cfg, helps.UpstreamRequestLog{
+		URL:       url,
+		Method:    http.MethodPost,
+		Headers:   httpReq.Header.Clone(),
+		Body:      body,
+		Provider:  provider,
+		AuthID:    authID,
+		AuthLabel: authLabel,
+		AuthType:  authType,
+		AuthValue: authValue,
+	})
+
+	httpClient := helps.NewProxyAwareHTTPClient(ctx, cfg, auth, 0)
+	httpClient = reporter.TrackHTTPClient(httpClient)
+	httpResp, errDo := httpClient.Do(httpReq)
+	if errDo != nil {
+		helps.RecordAPIResponseError(ctx, cfg, errDo)
+		return nil, nil, errDo
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("%s executor: close response body error: %v", provider, errClose)
+		}
+	}()
+	helps.RecordAPIResponseMetadata(ctx, cfg, httpResp.StatusCode, httpResp.Header.Clone())
+	data, errRead := io.ReadAll(httpResp.Body)
+	if errRead != nil {
+		helps.RecordAPIResponseError(ctx, cfg, errRead)
+		return nil, nil, errRead
+	}
+	helps.AppendAPIResponseChunk(ctx, cfg, data)
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		helps.LogWithRequestID(ctx).Debugf("request error, error status: %d, error message: %s", httpResp.StatusCode, helps.SummarizeErrorBody(httpResp.Header.Get("Content-Type"), data))
+		return nil, nil, statusErr{code: httpResp.StatusCode, msg: string(data)}
+	}
+	return data, httpResp.Header.Clone(), nil
+}
+
+// executeEmbeddings translates an OpenAI embeddings request to Gemini batchEmbedContents.
+func (e *GeminiExecutor) executeEmbeddings(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	reporter := helps.NewExecutorUsageReporter(ctx, e, baseModel, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	embedReq, err := parseOpenAIEmbeddingRequest(req.Payload)
+	if err != nil {
+		return resp, err
+	}
+	apiKey := geminiAPIKey(auth)
+	url := fmt.Sprintf("%s/%s/models/%s:batchEmbedContents", resolveGeminiBaseURL(auth), glAPIVersion, baseModel)
+	prepare := func(httpReq *http.Request) error {
+		if apiKey != "" {
+			httpReq.Header.Set("x-goog-api-key", apiKey)
+		}
+		applyGeminiHeaders(httpReq, auth, opts.Headers)
+		return nil
+	}
+
+	var vectors []string
+	var headers http.Header
+	for _, chunk := range chunkEmbeddingInputs(embedReq.Inputs, geminiEmbeddingBatchLimit) {
+		body, errBuild := buildGeminiBatchEmbedRequest(baseModel, chunk, embedReq)
+		if errBuild != nil {
+			return resp, errBuild
+		}
+		data, respHeaders, errPost := postDirectUpstreamRequest(ctx, e.cfg, auth, e.Identifier(), url, body, reporter, prepare)
+		if errPost != nil {
+			err = errPost
+			return resp, err
+		}
+		chunkVectors, errParse := parseGeminiBatchEmbedResponse(data, len(chunk))
+		if errParse != nil {
+			err = errParse
+			return resp, err
+		}
+		vectors = append(vectors, chunkVectors...)
+		headers = respHeaders
+	}
+	promptTokens := countEmbeddingInputTokens(baseModel, embedReq.Inputs)
+	return publishEmbeddingResponse(ctx, reporter, helps.PayloadRequestedModel(opts, req.Model), vectors, promptTokens, embedReq.EncodingFormat, headers)
+}
+
+// executeEmbeddings translates an OpenAI embeddings request to the Vertex AI text embedding predict API.
+func (e *GeminiVertexExecutor) executeEmbeddings(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	reporter := helps.NewExecutorUsageReporter(ctx, e, baseModel, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	embedReq, err := parseOpenAIEmbeddingRequest(req.Payload)
+	if err != nil {
+		return resp, err
+	}
+
+	var url string
+	var bearer string
+	apiKey, baseURL := vertexAPICreds(auth)
+	if apiKey == "" {
+		projectID, location, saJSON, errCreds := vertexCreds(auth)
+		if errCreds != nil {
+			return resp, errCreds
+		}
+		token, errTok := vertexAccessToken(ctx, e.cfg, auth, saJSON)
+		if errTok != nil {
+			log.Errorf("vertex executor: access token error: %v", errTok)
+			return resp, statusErr{code: http.StatusInternalServerError, msg: "internal server error"}
+		}
+		bearer = token
+		url = fmt.Sprintf("%s/%s/projects/%s/locations/%s/publishers/google/models/%s:predict", vertexBaseURL(location), vertexAPIVersion, projectID, location, baseModel)
+	} else {
+		if baseURL == "" {
+			baseURL = "https://aiplatform.googleapis.com"
+		}
+		url = fmt.Sprintf("%s/%s/publishers/google/models/%s:predict", baseURL, vertexAPIVersion, baseModel)
+	}
+	prepare := func(httpReq *http.Request) error {
+		if bearer != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+bearer)
+		} else if apiKey != "" {
+			httpReq.Header.Set("x-goog-api-key", apiKey)
+		}
+		applyGeminiHeaders(httpReq, auth, opts.Headers)
+		var attrs map[string]string
+		if auth != nil {
+			attrs = auth.Attributes
+		}
+		util.ApplyCustomHeadersFromAttrs(httpReq, attrs, opts.Headers)
+		return nil
+	}
+
+	// Gemini embedding models on Vertex accept a single instance per predict call.
+	batchLimit := vertexEmbeddingBatchLimit
+	if strings.HasPrefix(strings.ToLower(baseModel), "gemini-embedding") {
+		batchLimit = 1
+	}
+	var vectors []string
+	var promptTokens int64
+	var headers http.Header
+	for _, chunk := range chunkEmbeddingInputs(embedReq.Inputs, batchLimit) {
+		body, errBuild := buildVertexEmbedRequest(chunk, embedReq)
+		if errBuild != nil {
+			return resp, errBuild
+		}
+		data, respHeaders, errPost := postDirectUpstreamRequest(ctx, e.cfg, auth, e.Identifier(), url, body, reporter, prepare)
+		if errPost != nil {
+			err = errPost
+			return resp, err
+		}
+		chunkVectors, chunkTokens, errParse := parseVertexEmbedResponse(data, len(chunk))
+		if errParse != nil {
+			err = errParse
+			return resp, err
+		}
+		vectors = append(vectors, chunkVectors...)
+		promptTokens += chunkTokens
+		headers = respHeaders
+	}
+	return publishEmbeddingResponse(ctx, reporter, helps.PayloadRequestedModel(opts, req.Model), vectors, promptTokens, embedReq.EncodingFormat, headers)
+}
+
+func publishEmbeddingResponse(ctx context.Context, reporter *helps.UsageReporter, model string, vectors []string, promptTokens int64, encodingFormat string, headers http.Header) (cliproxyexecutor.Response, error) {
+	out, errBuild := buildOpenAIEmbeddingResponse(model, vectors, promptTokens, encodingFormat)
+	if errBuild != nil {
+		return cliproxyexecutor.Response{}, errBuild
+	}
+	reporter.Publish(ctx, helps.ParseOpenAIUsage(out))
+	reporter.EnsurePublished(ctx)
+	return cliproxyexecutor.Response{Payload: out, Headers: headers}, nil
+}
+
+// executeEmbeddings forwards an OpenAI embeddings request to the provider's /embeddings endpoint.
+func (e *OpenAICompatExecutor) executeEmbeddings(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	reporter := helps.NewExecutorUsageReporter(ctx, e, baseModel, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	baseURL, apiKey := e.resolveCredentials(auth)
+	if baseURL == "" {
+		err = statusErr{code: http.StatusUnauthorized, msg: "missing provider baseURL"}
+		return resp, err
+	}
+	payload := e.overrideModel(req.Payload, baseModel)
+	url := strings.TrimSuffix(baseURL, "/") + openAICompatEmbeddingsPath
+	data, headers, err := postDirectUpstreamRequest(ctx, e.cfg, auth, e.Identifier(), url, payload, reporter, func(httpReq *http.Request) error {
+		if apiKey != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+		httpReq.Header.Set("User-Agent", "cli-proxy-openai-compat")
+		var attrs map[string]string
+		if auth != nil {
+			attrs = auth.Attributes
+		}
+		util.ApplyCustomHeadersFromAttrs(httpReq, attrs, opts.Headers)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+	reporter.Publish(ctx, helps.ParseOpenAIUsage(data))
+	reporter.EnsurePublished(ctx)
+	resp = cliproxyexecutor.Response{Payload: data, Headers: headers}
+	return resp, nil
+}
@@ -0,0 +1,175 @@
+package executor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func embeddingOptions() cliproxyexecutor.Options {
+	return cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString(openAIEmbeddingSourceFormat)}
+}
+
+func TestGeminiExecutorEmbeddingsTranslatesBatch(t *testing.T) {
+	var gotPath, gotKey string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotKey = r.Header.Get("x-goog-api-key")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"embeddings":[{"values":[0.1,0.2]},{"values":[0.3,0.4]}]}`))
+	}))
+	defer server.Close()
+
+	executor := NewGeminiExecutor(&config.Config{})
+	auth := &cliproxyauth.Auth{Provider: "gemini", Attributes: map[string]string{"api_key": "gk", "base_url": server.URL}}
+	payload := []byte(`{"model":"gemini-embedding-001","input":["hello","world"],"dimensions":2}`)
+	resp, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "gemini-embedding-001", Payload: payload}, embeddingOptions())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if gotPath != "/v1beta/models/gemini-embedding-001:batchEmbedContents" || gotKey != "gk" {
+		t.Fatalf("path = %q key = %q", gotPath, gotKey)
+	}
+	if got := gjson.GetBytes(gotBody, "requests.1.content.parts.0.text").String(); got != "world" {
+		t.Fatalf("second request text = %q, body=%s", got, gotBody)
+	}
+	if got := gjson.GetBytes(gotBody, "requests.0.outputDimensionality").Int(); got != 2 {
+		t.Fatalf("outputDimensionality = %d", got)
+	}
+	if gjson.GetBytes(resp.Payload, "object").String() != "list" || gjson.GetBytes(resp.Payload, "data.1.index").Int() != 1 {
+		t.Fatalf("payload = %s", resp.Payload)
+	}
+	if got := gjson.GetBytes(resp.Payload, "data.1.embedding").Raw; got != "[0.3,0.4]" {
+		t.Fatalf("second embedding = %s", got)
+	}
+}
+
+func TestGeminiExecutorEmbeddingsCountsUsageLocally(t *testing.T) {
+	// batchEmbedContents responses carry no usageMetadata.
+	fixture, errRead := os.ReadFile(filepath.Join("testdata", "gemini_batch_embed_contents.json"))
+	if errRead != nil {
+		t.Fatalf("read fixture: %v", errRead)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(fixture)
+	}))
+	defer server.Close()
+
+	executor := NewGeminiExecutor(&config.Config{})
+	auth := &cliproxyauth.Auth{Provider: "gemini", Attributes: map[string]string{"api_key": "gk", "base_url": server.URL}}
+	inputs := []string{"What is the meaning of life?", "How much wood would a woodchuck chuck?"}
+	payload := []byte(`{"model":"gemini-embedding-001","input":["What is the meaning of life?","How much wood would a woodchuck chuck?"]}`)
+	resp, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "gemini-embedding-001", Payload: payload}, embeddingOptions())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := len(gjson.GetBytes(resp.Payload, "data.1.embedding").Array()); got != 4 {
+		t.Fatalf("second embedding has %d values, payload = %s", got, resp.Payload)
+	}
+	want := countEmbeddingInputTokens("gemini-embedding-001", inputs)
+	if want == 0 {
+		t.Fatal("countEmbeddingInputTokens() = 0")
+	}
+	if got := gjson.GetBytes(resp.Payload, "usage.prompt_tokens").Int(); got != want {
+		t.Fatalf("usage.prompt_tokens = %d, want %d", got, want)
+	}
+}
+
+func TestGeminiVertexExecutorEmbeddingsUsesPredictPerInput(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/v1/publishers/google/models/gemini-embedding-001:predict" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if n := len(gjson.GetBytes(body, "instances").Array()); n != 1 {
+			t.Errorf("instances = %d, want 1", n)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"predictions":[{"embeddings":{"values":[1.5],"statistics":{"token_count":3}}}]}`))
+	}))
+	defer server.Close()
+
+	executor := NewGeminiVertexExecutor(&config.Config{})
+	auth := &cliproxyauth.Auth{Provider: "vertex", Attributes: map[string]string{"api_key": "vk", "base_url": server.URL}}
+	payload := []byte(`{"model":"gemini-embedding-001","input":["a","b"]}`)
+	resp, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "gemini-embedding-001", Payload: payload}, embeddingOptions())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("predict calls = %d, want 2", calls)
+	}
+	if got := gjson.GetBytes(resp.Payload, "usage.prompt_tokens").Int(); got != 6 {
+		t.Fatalf("prompt_tokens = %d, payload=%s", got, resp.Payload)
+	}
+}
+
+func TestOpenAICompatExecutorEmbeddingsPassthrough(t *testing.T) {
+	var gotPath string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[],"model":"text-embedding-3-small","usage":{"prompt_tokens":4,"total_tokens":4}}`))
+	}))
+	defer server.Close()
+
+	executor := NewOpenAICompatExecutor("openai-compatibility", &config.Config{})
+	auth := &cliproxyauth.Auth{Provider: "openai-compatibility", Attributes: map[string]string{"base_url": server.URL + "/v1", "api_key": "test"}}
+	payload := []byte(`{"model":"small-embed","input":"hi","encoding_format":"base64"}`)
+	resp, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "text-embedding-3-small", Payload: payload}, embeddingOptions())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if gotPath != "/v1/embeddings" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if gjson.GetBytes(gotBody, "model").String() != "text-embedding-3-small" || gjson.GetBytes(gotBody, "encoding_format").String() != "base64" {
+		t.Fatalf("upstream body = %s", gotBody)
+	}
+	if gjson.GetBytes(resp.Payload, "usage.total_tokens").Int() != 4 {
+		t.Fatalf("payload = %s", resp.Payload)
+	}
+}
+
+func TestParseOpenAIEmbeddingRequestRejectsTokenArrays(t *testing.T) {
+	if _, err := parseOpenAIEmbeddingRequest([]byte(`{"input":[[1,2,3]]}`)); err == nil {
+		t.Fatal("expected token array input to be rejected")
+	}
+	if _, err := parseOpenAIEmbeddingRequest([]byte(`{"input":[]}`)); err == nil {
+		t.Fatal("expected empty input to be rejected")
+	}
+}
+
+func TestBuildOpenAIEmbeddingResponseBase64(t *testing.T) {
+	out, err := buildOpenAIEmbeddingResponse("m", []string{"[1,-0.5]"}, 0, "base64")
+	if err != nil {
+		t.Fatalf("buildOpenAIEmbeddingResponse() error = %v", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(gjson.GetBytes(out, "data.0.embedding").String())
+	if err != nil || len(decoded) != 8 {
+		t.Fatalf("decoded = %v, %v", decoded, err)
+	}
+	if got := math.Float32frombits(binary.LittleEndian.Uint32(decoded[4:])); got != -0.5 {
+		t.Fatalf("second value = %v", got)
+	}
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/access/ratelimit"
+)
+
+var _ ratelimit.StateStoreProvider = (*PostgresStore)(nil)
+var _ ratelimit.StateStore = (*postgresAPIKeyLimitStateStore)(nil)
+
+type postgresAPIKeyLimitStateStore struct {
+	store *PostgresStore
+	mu    sync.Mutex
+}
+
+// APIKeyLimitStateStore returns the PostgreSQL-backed store for per-client token budget counters.
+func (s *PostgresStore) APIKeyLimitStateStore() ratelimit.StateStore {
+	if s == nil {
+		return nil
+	}
+	return s.apiKeyLimits
+}
+
+func (s *postgresAPIKeyLimitStateStore) Load(ctx context.Context) (records []ratelimit.StateRecord, err error) {
+	if s == nil || s.store == nil || s.store.db == nil {
+		return nil, fmt.Errorf("postgres api key limit store: not initialized")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table := s.store.fullTableName(s.store.cfg.APIKeyLimitTable)
+	rows, errQuery := s.store.db.QueryContext(ctx, fmt.Sprintf("SELECT content FROM %s", table))
+	if errQuery != nil {
+		return nil, fmt.Errorf("postgres api key limit store: load state: %w", errQuery)
+	}
+	defer func() {
+		if errClose := rows.Close(); errClose != nil {
+			err = errors.Join(err, fmt.Errorf("postgres api key limit store: close state rows: %w", errClose))
+		}
+	}()
+
+	records = make([]ratelimit.StateRecord, 0)
+	for rows.Next() {
+		var content []byte
+		if errScan := rows.Scan(&content); errScan != nil {
+			return nil, fmt.Errorf("postgres api key limit store: scan state: %w", errScan)
+		}
+		var record ratelimit.StateRecord
+		if errUnmarshal := json.Unmarshal(content, &record); errUnmarshal != nil {
+			return nil, fmt.Errorf("postgres api key limit store: decode state: %w", errUnmarshal)
+		}
+		if strings.TrimSpace(record.KeyID) == "" {
+			continue
+		}
+		records = append(records, record)
+	}
+	if errRows := rows.Err(); errRows != nil {
+		return nil, fmt.Errorf("postgres api key limit store: iterate state: %w", errRows)
+	}
+	return records, nil
+}
+
+func (s *postgresAPIKeyLimitStateStore) Save(ctx context.Context, records []ratelimit.StateRecord) error {
+	if s == nil || s.store == nil || s.store.db == nil {
+		return fmt.Errorf("postgres api key limit store: not initialized")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, errBegin := s.store.db.BeginTx(ctx, nil)
+	if errBegin != nil {
+		return fmt.Errorf("postgres api key limit store: begin save: %w", errBegin)
+	}
+	table := s.store.fullTableName(s.store.cfg.APIKeyLimitTable)
+	upsertQuery := fmt.Sprintf(`
+		INSERT INTO %s AS target (key_id, content, created_at, updated_at)
+		VALUES ($1, $2, NOW(), $3)
+		ON CONFLICT (key_id) DO UPDATE SET
+			content = EXCLUDED.content,
+			updated_at = EXCLUDED.updated_at
+		WHERE target.updated_at <= EXCLUDED.updated_at
+	`, table)
+	for i := range records {
+		record := records[i]
+		keyID := strings.TrimSpace(record.KeyID)
+		if keyID == "" {
+			continue
+		}
+		record.UpdatedAt = normalizePostgresCooldownTime(record.UpdatedAt, time.Now())
+		content, errMarshal := json.Marshal(record)
+		if errMarshal != nil {
+			return rollbackPostgresAPIKeyLimitTransaction(tx, fmt.Errorf("postgres api key limit store: encode state: %w", errMarshal))
+		}
+		if _, errExec := tx.ExecContext(ctx, upsertQuery, keyID, content, record.UpdatedAt); errExec != nil {
+			return rollbackPostgresAPIKeyLimitTransaction(tx, fmt.Errorf("postgres api key limit store: save state: %w", errExec))
+		}
+	}
+	if errCommit := tx.Commit(); errCommit != nil {
+		return fmt.Errorf("postgres api key limit store: commit save: %w", errCommit)
+	}
+	return nil
+}
+
+func rollbackPostgresAPIKeyLimitTransaction(tx *sql.Tx, operationErr error) error {
+	if errRollback := tx.Rollback(); errRollback != nil && !errors.Is(errRollback, sql.ErrTxDone) {
+		return errors.Join(operationErr, fmt.Errorf("postgres api key limit store: rollback save: %w", errRollback))
+	}
+	return operationErr
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/audit"
+)
+
+var _ audit.StoreProvider = (*PostgresStore)(nil)
+var _ audit.Store = (*postgresAuditLogStore)(nil)
+
+type postgresAuditLogStore struct {
+	store *PostgresStore
+}
+
+// AuditLogStore returns the PostgreSQL-backed store for the audit log.
+func (s *PostgresStore) AuditLogStore() audit.Store {
+	if s == nil {
+		return nil
+	}
+	return s.auditLog
+}
+
+func (s *postgresAuditLogStore) Append(ctx context.Context, events []audit.Event) error {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	insertQuery := fmt.Sprintf("INSERT INTO %s (recorded_at, content) VALUES ($1, $2)", s.table())
+	for i := range events {
+		content, errMarshal := json.Marshal(events[i])
+		if errMarshal != nil {
+			return fmt.Errorf("postgres audit log store: encode event: %w", errMarshal)
+		}
+		if _, errExec := s.store.db.ExecContext(ctx, insertQuery, events[i].Timestamp.UTC(), content); errExec != nil {
+			return fmt.Errorf("postgres audit log store: insert event: %w", errExec)
+		}
+	}
+	return nil
+}
+
+func (s *postgresAuditLogStore) Scan(ctx context.Context, from, to time.Time, fn func(audit.Event) error) (err error) {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	query := fmt.Sprintf("SELECT content FROM %s WHERE 1 = 1", s.table())
+	args := make([]any, 0, 2)
+	if !from.IsZero() {
+		args = append(args, from.UTC())
+		query += fmt.Sprintf(" AND recorded_at >= $%d", len(args))
+	}
+	if !to.IsZero() {
+		args = append(args, to.UTC())
+		query += fmt.Sprintf(" AND recorded_at < $%d", len(args))
+	}
+	query += " ORDER BY recorded_at, id"
+
+	rows, errQuery := s.store.db.QueryContext(ctx, query, args...)
+	if errQuery != nil {
+		return fmt.Errorf("postgres audit log store: scan: %w", errQuery)
+	}
+	defer func() {
+		if errClose := rows.Close(); errClose != nil {
+			err = errors.Join(err, fmt.Errorf("postgres audit log store: close rows: %w", errClose))
+		}
+	}()
+	for rows.Next() {
+		var content []byte
+		if errScan := rows.Scan(&content); errScan != nil {
+			return fmt.Errorf("postgres audit log store: scan row: %w", errScan)
+		}
+		var event audit.Event
+		if errUnmarshal := json.Unmarshal(content, &event); errUnmarshal != nil {
+			return fmt.Errorf("postgres audit log store: decode event: %w", errUnmarshal)
+		}
+		if errFn := fn(event); errFn != nil {
+			return errFn
+		}
+	}
+	if errRows := rows.Err(); errRows != nil {
+		return fmt.Errorf("postgres audit log store: iterate rows: %w", errRows)
+	}
+	return nil
+}
+
+func (s *postgresAuditLogStore) DeleteBefore(ctx context.Context, cutoff time.Time) error {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, errExec := s.store.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE recorded_at < $1", s.table()), cutoff.UTC()); errExec != nil {
+		return fmt.Errorf("postgres audit log store: delete expired events: %w", errExec)
+	}
+	return nil
+}
+
+func (s *postgresAuditLogStore) ready() error {
+	if s == nil || s.store == nil || s.store.db == nil {
+		return fmt.Errorf("postgres audit log store: not initialized")
+	}
+	return nil
+}
+
+func (s *postgresAuditLogStore) table() string {
+	return s.store.fullTableName(s.store.cfg.AuditLogTable)
+}
@@ -0,0 +1,266 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/batch"
+)
+
+var _ batch.StoreProvider = (*PostgresStore)(nil)
+var _ batch.Store = (*postgresBatchStore)(nil)
+
+type postgresBatchStore struct {
+	store *PostgresStore
+}
+
+// BatchStore returns the PostgreSQL-backed store for batch files and jobs.
+func (s *PostgresStore) BatchStore() batch.Store {
+	if s == nil {
+		return nil
+	}
+	return s.batch
+}
+
+func (s *postgresBatchStore) SaveFile(ctx context.Context, file batch.File, content []byte) error {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	meta, errMarshal := json.Marshal(file)
+	if errMarshal != nil {
+		return fmt.Errorf("postgres batch store: encode file: %w", errMarshal)
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, content, data, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (id)
+		DO UPDATE SET content = EXCLUDED.content, data = EXCLUDED.data
+	`, s.table(s.store.cfg.BatchFileTable))
+	if _, errExec := s.store.db.ExecContext(s.context(ctx), query, file.ID, meta, content); errExec != nil {
+		return fmt.Errorf("postgres batch store: save file: %w", errExec)
+	}
+	return nil
+}
+
+func (s *postgresBatchStore) GetFile(ctx context.Context, id string) (batch.File, error) {
+	var file batch.File
+	if errReady := s.ready(); errReady != nil {
+		return file, errReady
+	}
+	var meta []byte
+	query := fmt.Sprintf("SELECT content FROM %s WHERE id = $1", s.table(s.store.cfg.BatchFileTable))
+	if errQuery := s.store.db.QueryRowContext(s.context(ctx), query, id).Scan(&meta); errQuery != nil {
+		if errors.Is(errQuery, sql.ErrNoRows) {
+			return file, batch.ErrNotFound
+		}
+		return file, fmt.Errorf("postgres batch store: get file: %w", errQuery)
+	}
+	if errUnmarshal := json.Unmarshal(meta, &file); errUnmarshal != nil {
+		return file, fmt.Errorf("postgres batch store: decode file: %w", errUnmarshal)
+	}
+	return file, nil
+}
+
+func (s *postgresBatchStore) FileContent(ctx context.Context, id string) ([]byte, error) {
+	if errReady := s.ready(); errReady != nil {
+		return nil, errReady
+	}
+	var data []byte
+	query := fmt.Sprintf("SELECT data FROM %s WHERE id = $1", s.table(s.store.cfg.BatchFileTable))
+	if errQuery := s.store.db.QueryRowContext(s.context(ctx), query, id).Scan(&data); errQuery != nil {
+		if errors.Is(errQuery, sql.ErrNoRows) {
+			return nil, batch.ErrNotFound
+		}
+		return nil, fmt.Errorf("postgres batch store: read file content: %w", errQuery)
+	}
+	return data, nil
+}
+
+func (s *postgresBatchStore) ListFiles(ctx context.Context) ([]batch.File, error) {
+	files := make([]batch.File, 0)
+	errList := s.scanContent(ctx, fmt.Sprintf("SELECT content FROM %s", s.table(s.store.cfg.BatchFileTable)), nil, func(content []byte) error {
+		var file batch.File
+		if errUnmarshal := json.Unmarshal(content, &file); errUnmarshal != nil {
+			return fmt.Errorf("postgres batch store: decode file: %w", errUnmarshal)
+		}
+		files = append(files, file)
+		return nil
+	})
+	return files, errList
+}
+
+func (s *postgresBatchStore) DeleteFile(ctx context.Context, id string) error {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", s.table(s.store.cfg.BatchFileTable))
+	result, errExec := s.store.db.ExecContext(s.context(ctx), query, id)
+	if errExec != nil {
+		return fmt.Errorf("postgres batch store: delete file: %w", errExec)
+	}
+	if affected, errAffected := result.RowsAffected(); errAffected == nil && affected == 0 {
+		return batch.ErrNotFound
+	}
+	return nil
+}
+
+func (s *postgresBatchStore) SaveJob(ctx context.Context, job batch.Job) error {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	content, errMarshal := json.Marshal(job)
+	if errMarshal != nil {
+		return fmt.Errorf("postgres batch store: encode job: %w", errMarshal)
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, content, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		ON CONFLICT (id)
+		DO UPDATE SET content = EXCLUDED.content, updated_at = NOW()
+	`, s.table(s.store.cfg.BatchJobTable))
+	if _, errExec := s.store.db.ExecContext(s.context(ctx), query, job.ID, content); errExec != nil {
+		return fmt.Errorf("postgres batch store: save job: %w", errExec)
+	}
+	return nil
+}
+
+func (s *postgresBatchStore) GetJob(ctx context.Context, id string) (batch.Job, error) {
+	var job batch.Job
+	if errReady := s.ready(); errReady != nil {
+		return job, errReady
+	}
+	var content []byte
+	query := fmt.Sprintf("SELECT content FROM %s WHERE id = $1", s.table(s.store.cfg.BatchJobTable))
+	if errQuery := s.store.db.QueryRowContext(s.context(ctx), query, id).Scan(&content); errQuery != nil {
+		if errors.Is(errQuery, sql.ErrNoRows) {
+			return job, batch.ErrNotFound
+		}
+		return job, fmt.Errorf("postgres batch store: get job: %w", errQuery)
+	}
+	if errUnmarshal := json.Unmarshal(content, &job); errUnmarshal != nil {
+		return job, fmt.Errorf("postgres batch store: decode job: %w", errUnmarshal)
+	}
+	return job, nil
+}
+
+func (s *postgresBatchStore) ListJobs(ctx context.Context) ([]batch.Job, error) {
+	jobs := make([]batch.Job, 0)
+	errList := s.scanContent(ctx, fmt.Sprintf("SELECT content FROM %s", s.table(s.store.cfg.BatchJobTable)), nil, func(content []byte) error {
+		var job batch.Job
+		if errUnmarshal := json.Unmarshal(content, &job); errUnmarshal != nil {
+			return fmt.Errorf("postgres batch store: decode job: %w", errUnmarshal)
+		}
+		jobs = append(jobs, job)
+		return nil
+	})
+	return jobs, errList
+}
+
+func (s *postgresBatchStore) AppendResults(ctx context.Context, jobID string, results []batch.Result) error {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	ctx = s.context(ctx)
+	tx, errBegin := s.store.db.BeginTx(ctx, nil)
+	if errBegin != nil {
+		return fmt.Errorf("postgres batch store: begin append: %w", errBegin)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (job_id, content) VALUES ($1, $2)", s.table(s.store.cfg.BatchResultTable))
+	for _, result := range results {
+		content, errMarshal := json.Marshal(result)
+		if errMarshal != nil {
+			return rollbackPostgresBatchTransaction(tx, fmt.Errorf("postgres batch store: encode result: %w", errMarshal))
+		}
+		if _, errExec := tx.ExecContext(ctx, query, jobID, content); errExec != nil {
+			return rollbackPostgresBatchTransaction(tx, fmt.Errorf("postgres batch store: insert result: %w", errExec))
+		}
+	}
+	if errCommit := tx.Commit(); errCommit != nil {
+		return fmt.Errorf("postgres batch store: commit append: %w", errCommit)
+	}
+	return nil
+}
+
+func (s *postgresBatchStore) Results(ctx context.Context, jobID string) ([]batch.Result, error) {
+	results := make([]batch.Result, 0)
+	query := fmt.Sprintf("SELECT content FROM %s WHERE job_id = $1 ORDER BY id", s.table(s.store.cfg.BatchResultTable))
+	errList := s.scanContent(ctx, query, []any{jobID}, func(content []byte) error {
+		var result batch.Result
+		if errUnmarshal := json.Unmarshal(content, &result); errUnmarshal != nil {
+			return fmt.Errorf("postgres batch store: decode result: %w", errUnmarshal)
+		}
+		results = append(results, result)
+		return nil
+	})
+	return results, errList
+}
+
+func (s *postgresBatchStore) DeleteResults(ctx context.Context, jobID string) error {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	query := fmt.Sprintf("DELETE FROM %s WHERE job_id = $1", s.table(s.store.cfg.BatchResultTable))
+	if _, errExec := s.store.db.ExecContext(s.context(ctx), query, jobID); errExec != nil {
+		return fmt.Errorf("postgres batch store: delete results: %w", errExec)
+	}
+	return nil
+}
+
+func (s *postgresBatchStore) scanContent(ctx context.Context, query string, args []any, fn func([]byte) error) (err error) {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	rows, errQuery := s.store.db.QueryContext(s.context(ctx), query, args...)
+	if errQuery != nil {
+		return fmt.Errorf("postgres batch store: query: %w", errQuery)
+	}
+	defer func() {
+		if errClose := rows.Close(); errClose != nil {
+			err = errors.Join(err, fmt.Errorf("postgres batch store: close rows: %w", errClose))
+		}
+	}()
+	for rows.Next() {
+		var content []byte
+		if errScan := rows.Scan(&content); errScan != nil {
+			return fmt.Errorf("postgres batch store: scan row: %w", errScan)
+		}
+		if errFn := fn(content); errFn != nil {
+			return errFn
+		}
+	}
+	if errRows := rows.Err(); errRows != nil {
+		return fmt.Errorf("postgres batch store: iterate rows: %w", errRows)
+	}
+	return nil
+}
+
+func (s *postgresBatchStore) ready() error {
+	if s == nil || s.store == nil || s.store.db == nil {
+		return fmt.Errorf("postgres batch store: not initialized")
+	}
+	return nil
+}
+
+func (s *postgresBatchStore) context(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+func (s *postgresBatchStore) table(name string) string {
+	return s.store.fullTableName(name)
+}
+
+func rollbackPostgresBatchTransaction(tx *sql.Tx, operationErr error) error {
+	if errRollback := tx.Rollback(); errRollback != nil && !errors.Is(errRollback, sql.ErrTxDone) {
+		return errors.Join(operationErr, fmt.Errorf("postgres batch store: rollback: %w", errRollback))
+	}
+	return operationErr
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/responsecache"
+)
+
+// responseCachePurgeInterval limits how often expired rows are deleted on write.
+const responseCachePurgeInterval = time.Minute
+
+var _ responsecache.StoreProvider = (*PostgresStore)(nil)
+var _ responsecache.Backend = (*postgresResponseCacheStore)(nil)
+
+type postgresResponseCacheStore struct {
+	store *PostgresStore
+
+	mu         sync.Mutex
+	lastPurged time.Time
+}
+
+// ResponseCacheStore returns the PostgreSQL-backed response cache store.
+func (s *PostgresStore) ResponseCacheStore() responsecache.Backend {
+	if s == nil {
+		return nil
+	}
+	return s.responseCache
+}
+
+func (s *postgresResponseCacheStore) Get(ctx context.Context, key string) (responsecache.Entry, bool, error) {
+	var entry responsecache.Entry
+	if errReady := s.ready(); errReady != nil {
+		return entry, false, errReady
+	}
+	var content []byte
+	query := fmt.Sprintf("SELECT content FROM %s WHERE key = $1 AND expires_at > NOW()", s.store.fullTableName(s.store.cfg.ResponseCacheTable))
+	if errQuery := s.store.db.QueryRowContext(ctx, query, key).Scan(&content); errQuery != nil {
+		if errors.Is(errQuery, sql.ErrNoRows) {
+			return entry, false, nil
+		}
+		return entry, false, fmt.Errorf("postgres response cache: get: %w", errQuery)
+	}
+	if errUnmarshal := json.Unmarshal(content, &entry); errUnmarshal != nil {
+		return entry, false, fmt.Errorf("postgres response cache: decode entry: %w", errUnmarshal)
+	}
+	return entry, true, nil
+}
+
+func (s *postgresResponseCacheStore) Set(ctx context.Context, key string, entry responsecache.Entry) error {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	content, errMarshal := json.Marshal(entry)
+	if errMarshal != nil {
+		return fmt.Errorf("postgres response cache: encode entry: %w", errMarshal)
+	}
+	table := s.store.fullTableName(s.store.cfg.ResponseCacheTable)
+	query := fmt.Sprintf(`
+		INSERT INTO %s (key, content, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key)
+		DO UPDATE SET content = EXCLUDED.content, expires_at = EXCLUDED.expires_at
+	`, table)
+	if _, errExec := s.store.db.ExecContext(ctx, query, key, content, entry.ExpiresAt.UTC()); errExec != nil {
+		return fmt.Errorf("postgres response cache: set: %w", errExec)
+	}
+	if s.purgeDue(time.Now()) {
+		if _, errPurge := s.store.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE expires_at <= NOW()", table)); errPurge != nil {
+			return fmt.Errorf("postgres response cache: purge expired: %w", errPurge)
+		}
+	}
+	return nil
+}
+
+func (s *postgresResponseCacheStore) purgeDue(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastPurged) < responseCachePurgeInterval {
+		return false
+	}
+	s.lastPurged = now
+	return true
+}
+
+func (s *postgresResponseCacheStore) ready() error {
+	if s == nil || s.store == nil || s.store.db == nil {
+		return fmt.Errorf("postgres response cache: not initialized")
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/responsestore"
+)
+
+// responsesPurgeInterval limits how often expired rows are deleted on write.
+const responsesPurgeInterval = time.Minute
+
+var _ responsestore.StoreProvider = (*PostgresStore)(nil)
+var _ responsestore.Backend = (*postgresResponsesStore)(nil)
+
+type postgresResponsesStore struct {
+	store *PostgresStore
+
+	mu         sync.Mutex
+	lastPurged time.Time
+}
+
+// ResponsesStore returns the PostgreSQL-backed store of Responses API responses.
+func (s *PostgresStore) ResponsesStore() responsestore.Backend {
+	if s == nil {
+		return nil
+	}
+	return s.responses
+}
+
+func (s *postgresResponsesStore) Get(ctx context.Context, id string) (responsestore.Record, bool, error) {
+	var record responsestore.Record
+	if errReady := s.ready(); errReady != nil {
+		return record, false, errReady
+	}
+	var content []byte
+	query := fmt.Sprintf("SELECT content FROM %s WHERE id = $1 AND expires_at > NOW()", s.store.fullTableName(s.store.cfg.ResponsesTable))
+	if errQuery := s.store.db.QueryRowContext(ctx, query, id).Scan(&content); errQuery != nil {
+		if errors.Is(errQuery, sql.ErrNoRows) {
+			return record, false, nil
+		}
+		return record, false, fmt.Errorf("postgres responses store: get: %w", errQuery)
+	}
+	if errUnmarshal := json.Unmarshal(content, &record); errUnmarshal != nil {
+		return record, false, fmt.Errorf("postgres responses store: decode record: %w", errUnmarshal)
+	}
+	return record, true, nil
+}
+
+func (s *postgresResponsesStore) Put(ctx context.Context, record responsestore.Record) error {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	content, errMarshal := json.Marshal(record)
+	if errMarshal != nil {
+		return fmt.Errorf("postgres responses store: encode record: %w", errMarshal)
+	}
+	table := s.store.fullTableName(s.store.cfg.ResponsesTable)
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, content, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (id)
+		DO UPDATE SET content = EXCLUDED.content, expires_at = EXCLUDED.expires_at
+	`, table)
+	if _, errExec := s.store.db.ExecContext(ctx, query, record.ID, content, record.ExpiresAt.UTC()); errExec != nil {
+		return fmt.Errorf("postgres responses store: put: %w", errExec)
+	}
+	if s.purgeDue(time.Now()) {
+		if _, errPurge := s.store.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE expires_at <= NOW()", table)); errPurge != nil {
+			return fmt.Errorf("postgres responses store: purge expired: %w", errPurge)
+		}
+	}
+	return nil
+}
+
+func (s *postgresResponsesStore) Delete(ctx context.Context, id string) (bool, error) {
+	if errReady := s.ready(); errReady != nil {
+		return false, errReady
+	}
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", s.store.fullTableName(s.store.cfg.ResponsesTable))
+	result, errExec := s.store.db.ExecContext(ctx, query, id)
+	if errExec != nil {
+		return false, fmt.Errorf("postgres responses store: delete: %w", errExec)
+	}
+	affected, errAffected := result.RowsAffected()
+	if errAffected != nil {
+		return false, fmt.Errorf("postgres responses store: delete: %w", errAffected)
+	}
+	return affected > 0, nil
+}
+
+func (s *postgresResponsesStore) purgeDue(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastPurged) < responsesPurgeInterval {
+		return false
+	}
+	s.lastPurged = now
+	return true
+}
+
+func (s *postgresResponsesStore) ready() error {
+	if s == nil || s.store == nil || s.store.db == nil {
+		return fmt.Errorf("postgres responses store: not initialized")
+	}
+	return nil
+}
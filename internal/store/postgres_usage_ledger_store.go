@@ -0,0 +1,358 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/usageledger"
+)
+
+var _ usageledger.StoreProvider = (*PostgresStore)(nil)
+var _ usageledger.Store = (*postgresUsageLedgerStore)(nil)
+var _ usageledger.Aggregator = (*postgresUsageLedgerStore)(nil)
+
+// usageLedgerDimensions are the entry dimensions kept in their own columns so
+// queries filter and group in SQL; content keeps the whole entry.
+var usageLedgerDimensions = []string{"key_id", "api_key", "model", "provider", "auth_index"}
+
+type postgresUsageLedgerStore struct {
+	store *PostgresStore
+}
+
+// ensureUsageLedgerSchema creates the ledger table and moves the dimensions of
+// rows written before they had columns out of content.
+func (s *PostgresStore) ensureUsageLedgerSchema(ctx context.Context) error {
+	table := s.fullTableName(s.cfg.UsageLedgerTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id BIGSERIAL PRIMARY KEY,
+			recorded_at TIMESTAMPTZ NOT NULL,
+			content JSONB NOT NULL
+		)
+	`, table)); err != nil {
+		return fmt.Errorf("postgres store: create usage ledger table: %w", err)
+	}
+	assignments := make([]string, 0, len(usageLedgerDimensions))
+	for _, column := range usageLedgerDimensions {
+		if _, err := s.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s TEXT", table, column)); err != nil {
+			return fmt.Errorf("postgres store: add usage ledger column %s: %w", column, err)
+		}
+		assignments = append(assignments, fmt.Sprintf("%s = COALESCE(content->>'%s', '')", column, column))
+	}
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET %s WHERE model IS NULL", table, strings.Join(assignments, ", "))); err != nil {
+		return fmt.Errorf("postgres store: backfill usage ledger columns: %w", err)
+	}
+	for _, index := range []struct{ name, columns string }{
+		{"recorded_at_idx", "recorded_at"},
+		{"key_id_idx", "key_id, recorded_at"},
+		{"model_idx", "LOWER(model), recorded_at"},
+		{"provider_idx", "LOWER(provider), recorded_at"},
+	} {
+		if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+			"CREATE INDEX IF NOT EXISTS %s ON %s (%s)",
+			quoteIdentifier(s.cfg.UsageLedgerTable+"_"+index.name), table, index.columns,
+		)); err != nil {
+			return fmt.Errorf("postgres store: create usage ledger index: %w", err)
+		}
+	}
+	return nil
+}
+
+// UsageLedgerStore returns the PostgreSQL-backed store for the usage ledger.
+func (s *PostgresStore) UsageLedgerStore() usageledger.Store {
+	if s == nil {
+		return nil
+	}
+	return s.usageLedger
+}
+
+func (s *postgresUsageLedgerStore) Append(ctx context.Context, entries []usageledger.Entry) error {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	tx, errBegin := s.store.db.BeginTx(ctx, nil)
+	if errBegin != nil {
+		return fmt.Errorf("postgres usage ledger store: begin append: %w", errBegin)
+	}
+	if errInsert := s.insertEntries(ctx, tx, entries); errInsert != nil {
+		return rollbackPostgresUsageLedgerTransaction(tx, errInsert)
+	}
+	if errCommit := tx.Commit(); errCommit != nil {
+		return fmt.Errorf("postgres usage ledger store: commit append: %w", errCommit)
+	}
+	return nil
+}
+
+func (s *postgresUsageLedgerStore) Scan(ctx context.Context, from, to time.Time, fn func(usageledger.Entry) error) (err error) {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	query := fmt.Sprintf("SELECT content FROM %s WHERE 1 = 1", s.table())
+	args := make([]any, 0, 2)
+	if !from.IsZero() {
+		args = append(args, from.UTC())
+		query += fmt.Sprintf(" AND recorded_at >= $%d", len(args))
+	}
+	if !to.IsZero() {
+		args = append(args, to.UTC())
+		query += fmt.Sprintf(" AND recorded_at < $%d", len(args))
+	}
+	query += " ORDER BY recorded_at"
+
+	rows, errQuery := s.store.db.QueryContext(ctx, query, args...)
+	if errQuery != nil {
+		return fmt.Errorf("postgres usage ledger store: scan: %w", errQuery)
+	}
+	defer func() {
+		if errClose := rows.Close(); errClose != nil {
+			err = errors.Join(err, fmt.Errorf("postgres usage ledger store: close rows: %w", errClose))
+		}
+	}()
+	for rows.Next() {
+		var content []byte
+		if errScan := rows.Scan(&content); errScan != nil {
+			return fmt.Errorf("postgres usage ledger store: scan row: %w", errScan)
+		}
+		var entry usageledger.Entry
+		if errUnmarshal := json.Unmarshal(content, &entry); errUnmarshal != nil {
+			return fmt.Errorf("postgres usage ledger store: decode entry: %w", errUnmarshal)
+		}
+		if errFn := fn(entry); errFn != nil {
+			return errFn
+		}
+	}
+	if errRows := rows.Err(); errRows != nil {
+		return fmt.Errorf("postgres usage ledger store: iterate rows: %w", errRows)
+	}
+	return nil
+}
+
+// AggregateScan filters and groups the entries selected by q in SQL and calls fn
+// with one summed entry per group.
+func (s *postgresUsageLedgerStore) AggregateScan(ctx context.Context, q usageledger.Query, bucket string, fn func(usageledger.Entry) error) (err error) {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	query, args := usageLedgerAggregateQuery(s.table(), q, bucket)
+	rows, errQuery := s.store.db.QueryContext(ctx, query, args...)
+	if errQuery != nil {
+		return fmt.Errorf("postgres usage ledger store: aggregate: %w", errQuery)
+	}
+	defer func() {
+		if errClose := rows.Close(); errClose != nil {
+			err = errors.Join(err, fmt.Errorf("postgres usage ledger store: close rows: %w", errClose))
+		}
+	}()
+	for rows.Next() {
+		var entry usageledger.Entry
+		var bucketStart sql.NullTime
+		if errScan := rows.Scan(
+			&bucketStart, &entry.KeyID, &entry.APIKey, &entry.Model, &entry.Provider, &entry.AuthIndex,
+			&entry.Requests, &entry.Failures, &entry.InputTokens, &entry.OutputTokens, &entry.ReasoningTokens,
+			&entry.CachedTokens, &entry.TotalTokens, &entry.InputAudioTokens, &entry.OutputAudioTokens,
+			&entry.LatencyMs, &entry.CostUSD, &entry.UnpricedRequests,
+		); errScan != nil {
+			return fmt.Errorf("postgres usage ledger store: scan aggregate row: %w", errScan)
+		}
+		if bucketStart.Valid {
+			// The bucket is a timestamp without time zone holding the UTC wall clock.
+			entry.Timestamp = time.Date(bucketStart.Time.Year(), bucketStart.Time.Month(), bucketStart.Time.Day(), bucketStart.Time.Hour(), 0, 0, 0, time.UTC)
+		}
+		if errFn := fn(entry); errFn != nil {
+			return errFn
+		}
+	}
+	if errRows := rows.Err(); errRows != nil {
+		return fmt.Errorf("postgres usage ledger store: iterate aggregate rows: %w", errRows)
+	}
+	return nil
+}
+
+// usageLedgerAggregateQuery builds the aggregate query of q. Dimensions outside
+// q.GroupBy select empty strings; groups are ordered by their latest entry so the
+// caller keeps the most recent label of a key.
+func usageLedgerAggregateQuery(table string, q usageledger.Query, bucket string) (string, []any) {
+	args := make([]any, 0, 8)
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	grouped := make(map[string]bool, len(q.GroupBy))
+	for _, group := range q.GroupBy {
+		grouped[group] = true
+	}
+	bucketExpr := "NULL::timestamp"
+	if grouped[usageledger.GroupBucket] {
+		unit := "day"
+		switch bucket {
+		case usageledger.BucketHour:
+			unit = "hour"
+		case usageledger.BucketMonth:
+			unit = "month"
+		}
+		bucketExpr = fmt.Sprintf("DATE_TRUNC('%s', recorded_at AT TIME ZONE 'UTC')", unit)
+	}
+	dimension := func(group, column string) string {
+		if grouped[group] {
+			return "COALESCE(" + column + ", '')"
+		}
+		return "''"
+	}
+	selected := []string{
+		bucketExpr,
+		dimension(usageledger.GroupAPIKey, "key_id"),
+		dimension(usageledger.GroupAPIKey, "api_key"),
+		dimension(usageledger.GroupModel, "model"),
+		dimension(usageledger.GroupProvider, "provider"),
+		dimension(usageledger.GroupAuth, "auth_index"),
+	}
+	sum := func(field string) string {
+		return fmt.Sprintf("COALESCE(SUM((content->>'%s')::BIGINT), 0)", field)
+	}
+	counters := []string{
+		"COALESCE(SUM(GREATEST(COALESCE((content->>'requests')::BIGINT, 0), 1)), 0)",
+		sum("failures"), sum("input_tokens"), sum("output_tokens"), sum("reasoning_tokens"),
+		sum("cached_tokens"), sum("total_tokens"), sum("input_audio_tokens"), sum("output_audio_tokens"),
+		sum("latency_ms"),
+		"COALESCE(SUM((content->>'cost_usd')::DOUBLE PRECISION), 0)",
+		sum("unpriced_requests"),
+	}
+
+	conditions := make([]string, 0, 6)
+	if !q.From.IsZero() {
+		conditions = append(conditions, "recorded_at >= "+arg(q.From.UTC()))
+	}
+	if !q.To.IsZero() {
+		conditions = append(conditions, "recorded_at < "+arg(q.To.UTC()))
+	}
+	if q.APIKey != "" {
+		raw := arg(q.APIKey)
+		keyID := arg(usageledger.KeyID(q.APIKey))
+		alias := arg(usageledger.DisplayKey(q.APIKey))
+		conditions = append(conditions, fmt.Sprintf(
+			"(api_key = %[1]s OR key_id = %[1]s OR (key_id <> '' AND key_id = %[2]s) OR (key_id = '' AND api_key = %[3]s))",
+			raw, keyID, alias,
+		))
+	}
+	if q.Model != "" {
+		conditions = append(conditions, "LOWER(model) = LOWER("+arg(q.Model)+")")
+	}
+	if q.Provider != "" {
+		conditions = append(conditions, "LOWER(provider) = LOWER("+arg(q.Provider)+")")
+	}
+	if q.Auth != "" {
+		conditions = append(conditions, "auth_index = "+arg(q.Auth))
+	}
+
+	query := fmt.Sprintf("SELECT %s, %s FROM %s", strings.Join(selected, ", "), strings.Join(counters, ", "), table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " GROUP BY 1, 2, 3, 4, 5, 6 ORDER BY MAX(recorded_at)"
+	return query, args
+}
+
+func (s *postgresUsageLedgerStore) Oldest(ctx context.Context) (time.Time, bool, error) {
+	if errReady := s.ready(); errReady != nil {
+		return time.Time{}, false, errReady
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var oldest sql.NullTime
+	if errQuery := s.store.db.QueryRowContext(ctx, fmt.Sprintf("SELECT MIN(recorded_at) FROM %s", s.table())).Scan(&oldest); errQuery != nil {
+		return time.Time{}, false, fmt.Errorf("postgres usage ledger store: find oldest entry: %w", errQuery)
+	}
+	if !oldest.Valid {
+		return time.Time{}, false, nil
+	}
+	return oldest.Time.UTC(), true, nil
+}
+
+func (s *postgresUsageLedgerStore) DeleteBefore(ctx context.Context, cutoff time.Time) error {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, errExec := s.store.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE recorded_at < $1", s.table()), cutoff.UTC()); errExec != nil {
+		return fmt.Errorf("postgres usage ledger store: delete expired entries: %w", errExec)
+	}
+	return nil
+}
+
+func (s *postgresUsageLedgerStore) ReplaceDay(ctx context.Context, day time.Time, entries []usageledger.Entry) error {
+	if errReady := s.ready(); errReady != nil {
+		return errReady
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	tx, errBegin := s.store.db.BeginTx(ctx, nil)
+	if errBegin != nil {
+		return fmt.Errorf("postgres usage ledger store: begin compaction: %w", errBegin)
+	}
+	start := day.UTC()
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE recorded_at >= $1 AND recorded_at < $2", s.table())
+	if _, errExec := tx.ExecContext(ctx, deleteQuery, start, start.AddDate(0, 0, 1)); errExec != nil {
+		return rollbackPostgresUsageLedgerTransaction(tx, fmt.Errorf("postgres usage ledger store: delete day: %w", errExec))
+	}
+	if errInsert := s.insertEntries(ctx, tx, entries); errInsert != nil {
+		return rollbackPostgresUsageLedgerTransaction(tx, errInsert)
+	}
+	if errCommit := tx.Commit(); errCommit != nil {
+		return fmt.Errorf("postgres usage ledger store: commit compaction: %w", errCommit)
+	}
+	return nil
+}
+
+func (s *postgresUsageLedgerStore) insertEntries(ctx context.Context, tx *sql.Tx, entries []usageledger.Entry) error {
+	insertQuery := fmt.Sprintf("INSERT INTO %s (recorded_at, key_id, api_key, model, provider, auth_index, content) VALUES ($1, $2, $3, $4, $5, $6, $7)", s.table())
+	for i := range entries {
+		entry := &entries[i]
+		content, errMarshal := json.Marshal(entry)
+		if errMarshal != nil {
+			return fmt.Errorf("postgres usage ledger store: encode entry: %w", errMarshal)
+		}
+		if _, errExec := tx.ExecContext(ctx, insertQuery, entry.Timestamp.UTC(), entry.KeyID, entry.APIKey, entry.Model, entry.Provider, entry.AuthIndex, content); errExec != nil {
+			return fmt.Errorf("postgres usage ledger store: insert entry: %w", errExec)
+		}
+	}
+	return nil
+}
+
+func (s *postgresUsageLedgerStore) ready() error {
+	if s == nil || s.store == nil || s.store.db == nil {
+		return fmt.Errorf("postgres usage ledger store: not initialized")
+	}
+	return nil
+}
+
+func (s *postgresUsageLedgerStore) table() string {
+	return s.store.fullTableName(s.store.cfg.UsageLedgerTable)
+}
+
+func rollbackPostgresUsageLedgerTransaction(tx *sql.Tx, operationErr error) error {
+	if errRollback := tx.Rollback(); errRollback != nil && !errors.Is(errRollback, sql.ErrTxDone) {
+		return errors.Join(operationErr, fmt.Errorf("postgres usage ledger store: rollback: %w", errRollback))
+	}
+	return operationErr
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/usageledger"
+)
+
+func TestUsageLedgerAggregateQueryFiltersAndGroupsInSQL(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	query, args := usageLedgerAggregateQuery(`"usage_ledger_store"`, usageledger.Query{
+		From:     from,
+		GroupBy:  []string{usageledger.GroupBucket, usageledger.GroupModel},
+		APIKey:   "sk-team-a",
+		Model:    "GPT-5",
+		Provider: "codex",
+	}, usageledger.BucketHour)
+
+	for _, want := range []string{
+		"DATE_TRUNC('hour', recorded_at AT TIME ZONE 'UTC')",
+		"COALESCE(model, '')",
+		"recorded_at >= $1",
+		"key_id = $3",
+		"LOWER(model) = LOWER($5)",
+		"LOWER(provider) = LOWER($6)",
+		"GROUP BY 1, 2, 3, 4, 5, 6 ORDER BY MAX(recorded_at)",
+	} {
+		if !strings.Contains(query, want) {
+			t.Fatalf("query missing %q:\n%s", want, query)
+		}
+	}
+	if strings.Contains(query, "COALESCE(provider, '')") || strings.Contains(query, "COALESCE(key_id, '')") {
+		t.Fatalf("query groups by dimensions outside GroupBy:\n%s", query)
+	}
+	wantArgs := []any{from, "sk-team-a", usageledger.KeyID("sk-team-a"), usageledger.DisplayKey("sk-team-a"), "GPT-5", "codex"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("args = %#v, want %#v", args, wantArgs)
+	}
+}
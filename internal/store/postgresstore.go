@@ -14,26 +14,44 @@ import (
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
+	baseauth "github.com/router-for-me/CLIProxyAPI/v7/internal/auth"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/authcrypt"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/misc"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
 )
 
 const (
-	defaultConfigTable   = "config_store"
-	defaultAuthTable     = "auth_store"
-	defaultCooldownTable = "cooldown_store"
-	defaultConfigKey     = "config"
+	defaultConfigTable        = "config_store"
+	defaultAuthTable          = "auth_store"
+	defaultCooldownTable      = "cooldown_store"
+	defaultAPIKeyLimitTable   = "api_key_limit_store"
+	defaultUsageLedgerTable   = "usage_ledger_store"
+	defaultAuditLogTable      = "audit_log_store"
+	defaultBatchFileTable     = "batch_file_store"
+	defaultBatchJobTable      = "batch_job_store"
+	defaultBatchResultTable   = "batch_result_store"
+	defaultResponseCacheTable = "response_cache_store"
+	defaultResponsesTable     = "responses_store"
+	defaultConfigKey          = "config"
 )
 
 // PostgresStoreConfig captures configuration required to initialize a Postgres-backed store.
 type PostgresStoreConfig struct {
-	DSN           string
-	Schema        string
-	ConfigTable   string
-	AuthTable     string
-	CooldownTable string
-	SpoolDir      string
+	DSN                string
+	Schema             string
+	ConfigTable        string
+	AuthTable          string
+	CooldownTable      string
+	APIKeyLimitTable   string
+	UsageLedgerTable   string
+	AuditLogTable      string
+	BatchFileTable     string
+	BatchJobTable      string
+	BatchResultTable   string
+	ResponseCacheTable string
+	ResponsesTable     string
+	SpoolDir           string
 }
 
 // PostgresStore persists configuration and authentication metadata using PostgreSQL as backend
@@ -45,6 +63,12 @@ type PostgresStore struct {
 	configPath    string
 	authDir       string
 	cooldownStore *postgresCooldownStateStore
+	apiKeyLimits  *postgresAPIKeyLimitStateStore
+	usageLedger   *postgresUsageLedgerStore
+	auditLog      *postgresAuditLogStore
+	batch         *postgresBatchStore
+	responseCache *postgresResponseCacheStore
+	responses     *postgresResponsesStore
 	mu            sync.Mutex
 }
 
@@ -64,6 +88,30 @@ func NewPostgresStore(ctx context.Context, cfg PostgresStoreConfig) (*PostgresSt
 	if cfg.CooldownTable == "" {
 		cfg.CooldownTable = defaultCooldownTable
 	}
+	if cfg.APIKeyLimitTable == "" {
+		cfg.APIKeyLimitTable = defaultAPIKeyLimitTable
+	}
+	if cfg.UsageLedgerTable == "" {
+		cfg.UsageLedgerTable = defaultUsageLedgerTable
+	}
+	if cfg.AuditLogTable == "" {
+		cfg.AuditLogTable = defaultAuditLogTable
+	}
+	if cfg.BatchFileTable == "" {
+		cfg.BatchFileTable = defaultBatchFileTable
+	}
+	if cfg.BatchJobTable == "" {
+		cfg.BatchJobTable = defaultBatchJobTable
+	}
+	if cfg.BatchResultTable == "" {
+		cfg.BatchResultTable = defaultBatchResultTable
+	}
+	if cfg.ResponseCacheTable == "" {
+		cfg.ResponseCacheTable = defaultResponseCacheTable
+	}
+	if cfg.ResponsesTable == "" {
+		cfg.ResponsesTable = defaultResponsesTable
+	}
 
 	spoolRoot := strings.TrimSpace(cfg.SpoolDir)
 	if spoolRoot == "" {
@@ -103,6 +151,12 @@ func NewPostgresStore(ctx context.Context, cfg PostgresStoreConfig) (*PostgresSt
 		authDir:    authDir,
 	}
 	store.cooldownStore = &postgresCooldownStateStore{store: store}
+	store.apiKeyLimits = &postgresAPIKeyLimitStateStore{store: store}
+	store.usageLedger = &postgresUsageLedgerStore{store: store}
+	store.auditLog = &postgresAuditLogStore{store: store}
+	store.batch = &postgresBatchStore{store: store}
+	store.responseCache = &postgresResponseCacheStore{store: store}
+	store.responses = &postgresResponsesStore{store: store}
 	return store, nil
 }
 
@@ -161,6 +215,106 @@ func (s *PostgresStore) EnsureSchema(ctx context.Context) error {
 	`, cooldownTable)); err != nil {
 		return fmt.Errorf("postgres store: create cooldown table: %w", err)
 	}
+	apiKeyLimitTable := s.fullTableName(s.cfg.APIKeyLimitTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			key_id TEXT PRIMARY KEY,
+			content JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`, apiKeyLimitTable)); err != nil {
+		return fmt.Errorf("postgres store: create api key limit table: %w", err)
+	}
+	if err := s.ensureUsageLedgerSchema(ctx); err != nil {
+		return err
+	}
+	auditLogTable := s.fullTableName(s.cfg.AuditLogTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id BIGSERIAL PRIMARY KEY,
+			recorded_at TIMESTAMPTZ NOT NULL,
+			content JSONB NOT NULL
+		)
+	`, auditLogTable)); err != nil {
+		return fmt.Errorf("postgres store: create audit log table: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS %s ON %s (recorded_at)",
+		quoteIdentifier(s.cfg.AuditLogTable+"_recorded_at_idx"), auditLogTable,
+	)); err != nil {
+		return fmt.Errorf("postgres store: create audit log index: %w", err)
+	}
+	batchFileTable := s.fullTableName(s.cfg.BatchFileTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id TEXT PRIMARY KEY,
+			content JSONB NOT NULL,
+			data BYTEA NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`, batchFileTable)); err != nil {
+		return fmt.Errorf("postgres store: create batch file table: %w", err)
+	}
+	batchJobTable := s.fullTableName(s.cfg.BatchJobTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id TEXT PRIMARY KEY,
+			content JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`, batchJobTable)); err != nil {
+		return fmt.Errorf("postgres store: create batch job table: %w", err)
+	}
+	batchResultTable := s.fullTableName(s.cfg.BatchResultTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id BIGSERIAL PRIMARY KEY,
+			job_id TEXT NOT NULL,
+			content JSONB NOT NULL
+		)
+	`, batchResultTable)); err != nil {
+		return fmt.Errorf("postgres store: create batch result table: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS %s ON %s (job_id)",
+		quoteIdentifier(s.cfg.BatchResultTable+"_job_id_idx"), batchResultTable,
+	)); err != nil {
+		return fmt.Errorf("postgres store: create batch result index: %w", err)
+	}
+	responseCacheTable := s.fullTableName(s.cfg.ResponseCacheTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			key TEXT PRIMARY KEY,
+			content BYTEA NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL
+		)
+	`, responseCacheTable)); err != nil {
+		return fmt.Errorf("postgres store: create response cache table: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS %s ON %s (expires_at)",
+		quoteIdentifier(s.cfg.ResponseCacheTable+"_expires_at_idx"), responseCacheTable,
+	)); err != nil {
+		return fmt.Errorf("postgres store: create response cache index: %w", err)
+	}
+	responsesTable := s.fullTableName(s.cfg.ResponsesTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id TEXT PRIMARY KEY,
+			content BYTEA NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL
+		)
+	`, responsesTable)); err != nil {
+		return fmt.Errorf("postgres store: create responses table: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS %s ON %s (expires_at)",
+		quoteIdentifier(s.cfg.ResponsesTable+"_expires_at_idx"), responsesTable,
+	)); err != nil {
+		return fmt.Errorf("postgres store: create responses index: %w", err)
+	}
 	return nil
 }
 
@@ -245,7 +399,7 @@ func (s *PostgresStore) Save(ctx context.Context, auth *cliproxyauth.Auth) (stri
 		if setter, ok := auth.Storage.(interface{ SetMetadata(map[string]any) }); ok {
 			setter.SetMetadata(auth.Metadata)
 		}
-		if err = auth.Storage.SaveTokenToFile(path); err != nil {
+		if err = baseauth.SaveToken(auth.Storage, path); err != nil {
 			return "", err
 		}
 	case auth.Metadata != nil:
@@ -255,14 +409,14 @@ func (s *PostgresStore) Save(ctx context.Context, auth *cliproxyauth.Auth) (stri
 			return "", fmt.Errorf("postgres store: marshal metadata: %w", errMarshal)
 		}
 		if existing, errRead := os.ReadFile(path); errRead == nil {
-			if jsonEqual(existing, raw) {
+			if authcrypt.UpToDate(existing, raw, jsonEqual) {
 				return path, nil
 			}
 		} else if errRead != nil && !errors.Is(errRead, fs.ErrNotExist) {
 			return "", fmt.Errorf("postgres store: read existing metadata: %w", errRead)
 		}
 		tmp := path + ".tmp"
-		if errWrite := os.WriteFile(tmp, raw, 0o600); errWrite != nil {
+		if errWrite := authcrypt.WriteFile(tmp, raw, 0o600); errWrite != nil {
 			return "", fmt.Errorf("postgres store: write temp auth file: %w", errWrite)
 		}
 		if errRename := os.Rename(tmp, path); errRename != nil {
@@ -317,8 +471,13 @@ func (s *PostgresStore) List(ctx context.Context) ([]*cliproxyauth.Auth, error)
 			log.WithError(errPath).Warnf("postgres store: skipping auth %s outside spool", id)
 			continue
 		}
+		plain, errOpen := authcrypt.Open([]byte(payload))
+		if errOpen != nil {
+			log.WithError(errOpen).Warnf("postgres store: skipping auth %s that cannot be decrypted", id)
+			continue
+		}
 		metadata := make(map[string]any)
-		if err = json.Unmarshal([]byte(payload), &metadata); err != nil {
+		if err = json.Unmarshal(plain, &metadata); err != nil {
 			log.WithError(err).Warnf("postgres store: skipping auth %s with invalid json", id)
 			continue
 		}
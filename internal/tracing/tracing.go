@@ -0,0 +1,259 @@
+// Package tracing records OpenTelemetry spans along the request path and exports them
+// over OTLP/HTTP. Tracing is off until Apply receives an enabled config; until then
+// every helper returns no-op spans, so instrumented code pays only a context lookup.
+//
+// A request produces one server span, created by the API middleware from any incoming
+// W3C traceparent, with child spans for access authentication, model routing, plugin
+// interceptors, translation, each credential attempt and each upstream HTTP exchange.
+// Span attributes carry provider, model, auth index and token usage; request and
+// response bodies, headers, query strings and credentials are never recorded.
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/buildinfo"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const instrumentationName = "github.com/router-for-me/CLIProxyAPI/v7"
+
+// shutdownTimeout bounds how long a replaced provider may spend flushing spans.
+const shutdownTimeout = 5 * time.Second
+
+// Span attribute keys shared by instrumented packages.
+const (
+	AttrProvider       = attribute.Key("cliproxy.provider")
+	AttrModel          = attribute.Key("cliproxy.model")
+	AttrRequestedModel = attribute.Key("cliproxy.requested_model")
+	AttrAuthIndex      = attribute.Key("cliproxy.auth.index")
+	AttrSourceFormat   = attribute.Key("cliproxy.source_format")
+	AttrTargetFormat   = attribute.Key("cliproxy.target_format")
+	AttrStream         = attribute.Key("cliproxy.stream")
+	AttrAttempt        = attribute.Key("cliproxy.attempt")
+
+	AttrInputTokens     = attribute.Key("gen_ai.usage.input_tokens")
+	AttrOutputTokens    = attribute.Key("gen_ai.usage.output_tokens")
+	AttrReasoningTokens = attribute.Key("gen_ai.usage.reasoning_tokens")
+	AttrCachedTokens    = attribute.Key("gen_ai.usage.cached_tokens")
+	AttrTotalTokens     = attribute.Key("gen_ai.usage.total_tokens")
+)
+
+// state is the active provider together with the config it was built from.
+type state struct {
+	cfg      config.TracingConfig
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+var (
+	applyMu sync.Mutex
+	current atomic.Pointer[state]
+
+	noopTracer = noop.NewTracerProvider().Tracer(instrumentationName)
+	propagator = propagation.TraceContext{}
+)
+
+// Apply installs a tracer provider for cfg, replacing and flushing the previous one.
+// Disabling tracing drops the provider; an unchanged config is a no-op.
+func Apply(cfg config.TracingConfig) error {
+	applyMu.Lock()
+	defer applyMu.Unlock()
+
+	previous := current.Load()
+	if previous != nil && reflect.DeepEqual(previous.cfg, cfg) {
+		return nil
+	}
+	if previous == nil && !cfg.Enable {
+		return nil
+	}
+
+	var next *state
+	if cfg.Enable {
+		provider, errProvider := newProvider(cfg)
+		if errProvider != nil {
+			return errProvider
+		}
+		next = &state{cfg: cfg, provider: provider, tracer: provider.Tracer(instrumentationName)}
+	}
+	current.Store(next)
+	if previous != nil {
+		go shutdownProvider(previous.provider)
+	}
+	return nil
+}
+
+func newProvider(cfg config.TracingConfig) (*sdktrace.TracerProvider, error) {
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(cfg.EffectiveEndpoint())}
+	if len(cfg.Headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+	exporter, errExporter := otlptracehttp.New(context.Background(), options...)
+	if errExporter != nil {
+		return nil, errExporter
+	}
+	res := resource.NewSchemaless(
+		attribute.String("service.name", cfg.EffectiveServiceName()),
+		attribute.String("service.version", buildinfo.Version),
+	)
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.EffectiveSampleRatio()))),
+	), nil
+}
+
+func shutdownProvider(provider *sdktrace.TracerProvider) {
+	if provider == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if errShutdown := provider.Shutdown(ctx); errShutdown != nil {
+		log.Debugf("tracing: failed to shut down tracer provider: %v", errShutdown)
+	}
+}
+
+// Shutdown flushes pending spans and disables tracing.
+func Shutdown(ctx context.Context) error {
+	applyMu.Lock()
+	previous := current.Swap(nil)
+	applyMu.Unlock()
+	if previous == nil {
+		return nil
+	}
+	return previous.provider.Shutdown(ctx)
+}
+
+// Enabled reports whether spans are currently recorded and exported.
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+// Tracer returns the active tracer, or a no-op tracer while tracing is disabled.
+func Tracer() trace.Tracer {
+	if st := current.Load(); st != nil {
+		return st.tracer
+	}
+	return noopTracer
+}
+
+// Start opens an internal span named name as a child of the span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on span, when non-nil, and ends it.
+func End(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+type requestSpanKey struct{}
+
+// StartRequest opens the server span for an incoming request. A W3C traceparent in
+// header makes the span a child of the caller's trace.
+func StartRequest(ctx context.Context, header http.Header, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if header != nil {
+		ctx = propagator.Extract(ctx, propagation.HeaderCarrier(header))
+	}
+	ctx, span := Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+	return context.WithValue(ctx, requestSpanKey{}, span), span
+}
+
+// RequestSpan returns the server span of the request ctx belongs to.
+func RequestSpan(ctx context.Context) trace.Span {
+	if ctx != nil {
+		if span, ok := ctx.Value(requestSpanKey{}).(trace.Span); ok && span != nil {
+			return span
+		}
+	}
+	return trace.SpanFromContext(context.Background())
+}
+
+// Inherit copies the active span and request span of src into dst, for handlers that
+// detach their execution context from the incoming request context.
+func Inherit(dst, src context.Context) context.Context {
+	if dst == nil || src == nil {
+		return dst
+	}
+	if span := trace.SpanFromContext(src); span.SpanContext().IsValid() {
+		dst = trace.ContextWithSpan(dst, span)
+	}
+	if span, ok := src.Value(requestSpanKey{}).(trace.Span); ok && span != nil {
+		dst = context.WithValue(dst, requestSpanKey{}, span)
+	}
+	return dst
+}
+
+// AddRequestEvent adds a named event to the request span of ctx.
+func AddRequestEvent(ctx context.Context, name string, attrs ...attribute.KeyValue) {
+	RequestSpan(ctx).AddEvent(name, trace.WithAttributes(attrs...))
+}
+
+// Usage is the token usage reported for one upstream call.
+type Usage struct {
+	InputTokens     int64
+	OutputTokens    int64
+	ReasoningTokens int64
+	CachedTokens    int64
+	TotalTokens     int64
+}
+
+// RecordUsage sets provider, model, auth index and token counts on the active span of
+// ctx and on its request span.
+func RecordUsage(ctx context.Context, provider, model, authIndex string, usage Usage) {
+	if ctx == nil || !Enabled() {
+		return
+	}
+	attrs := []attribute.KeyValue{
+		AttrInputTokens.Int64(usage.InputTokens),
+		AttrOutputTokens.Int64(usage.OutputTokens),
+		AttrTotalTokens.Int64(usage.TotalTokens),
+	}
+	if usage.ReasoningTokens > 0 {
+		attrs = append(attrs, AttrReasoningTokens.Int64(usage.ReasoningTokens))
+	}
+	if usage.CachedTokens > 0 {
+		attrs = append(attrs, AttrCachedTokens.Int64(usage.CachedTokens))
+	}
+	if provider != "" {
+		attrs = append(attrs, AttrProvider.String(provider))
+	}
+	if model != "" {
+		attrs = append(attrs, AttrModel.String(model))
+	}
+	if authIndex != "" {
+		attrs = append(attrs, AttrAuthIndex.String(authIndex))
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+	if span, ok := ctx.Value(requestSpanKey{}).(trace.Span); ok && span != nil {
+		span.SetAttributes(attrs...)
+	}
+}
@@ -0,0 +1,190 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func installRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	current.Store(&state{cfg: config.TracingConfig{Enable: true}, provider: provider, tracer: provider.Tracer(instrumentationName)})
+	t.Cleanup(func() {
+		current.Store(nil)
+		_ = provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+func endedSpan(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+	t.Fatalf("span %q not recorded", name)
+	return nil
+}
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestApplyTogglesProvider(t *testing.T) {
+	t.Cleanup(func() { _ = Shutdown(context.Background()) })
+
+	if errApply := Apply(config.TracingConfig{}); errApply != nil {
+		t.Fatalf("Apply(disabled) error = %v", errApply)
+	}
+	if Enabled() {
+		t.Fatal("Enabled() = true before tracing was configured")
+	}
+
+	cfg := config.TracingConfig{Enable: true, Endpoint: "127.0.0.1:1"}
+	if errApply := Apply(cfg); errApply != nil {
+		t.Fatalf("Apply(enabled) error = %v", errApply)
+	}
+	first := current.Load()
+	if first == nil || !Enabled() {
+		t.Fatal("Enabled() = false after enabling tracing")
+	}
+	if errApply := Apply(cfg); errApply != nil {
+		t.Fatalf("Apply(unchanged) error = %v", errApply)
+	}
+	if current.Load() != first {
+		t.Fatal("unchanged config replaced the tracer provider")
+	}
+
+	if errApply := Apply(config.TracingConfig{}); errApply != nil {
+		t.Fatalf("Apply(disabled) error = %v", errApply)
+	}
+	if Enabled() {
+		t.Fatal("Enabled() = true after disabling tracing")
+	}
+}
+
+func TestStartRequestContinuesIncomingTraceparent(t *testing.T) {
+	recorder := installRecorder(t)
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := StartRequest(context.Background(), header, "POST /v1/chat/completions")
+	span.End()
+
+	got := endedSpan(t, recorder, "POST /v1/chat/completions")
+	if traceID := got.SpanContext().TraceID().String(); traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace id = %s, want the incoming trace", traceID)
+	}
+	if parentID := got.Parent().SpanID().String(); parentID != "00f067aa0ba902b7" {
+		t.Fatalf("parent span id = %s, want the incoming span", parentID)
+	}
+}
+
+func TestWrapTransportRecordsUpstreamSpanWithoutQuery(t *testing.T) {
+	recorder := installRecorder(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data: ok\n\n"))
+	}))
+	defer server.Close()
+
+	ctx, parent := Start(context.Background(), "auth.attempt")
+	client := &http.Client{Transport: WrapTransport(WrapTransport(http.DefaultTransport))}
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/v1beta/models/gemini:streamGenerateContent?key=secret-key", nil)
+	if errReq != nil {
+		t.Fatalf("NewRequest() error = %v", errReq)
+	}
+	resp, errDo := client.Do(req)
+	if errDo != nil {
+		t.Fatalf("Do() error = %v", errDo)
+	}
+	if _, errRead := io.ReadAll(resp.Body); errRead != nil {
+		t.Fatalf("ReadAll() error = %v", errRead)
+	}
+	_ = resp.Body.Close()
+	parent.End()
+
+	upstream := 0
+	for _, span := range recorder.Ended() {
+		if span.Name() == "upstream POST" {
+			upstream++
+		}
+	}
+	if upstream != 1 {
+		t.Fatalf("upstream spans = %d, want 1 for nested wrappers", upstream)
+	}
+	span := endedSpan(t, recorder, "upstream POST")
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("upstream span is not a child of the attempt span")
+	}
+	if path, _ := spanAttr(span, "url.path"); path.AsString() != "/v1beta/models/gemini:streamGenerateContent" {
+		t.Fatalf("url.path = %q", path.AsString())
+	}
+	if status, _ := spanAttr(span, "http.response.status_code"); status.AsInt64() != http.StatusOK {
+		t.Fatalf("status = %d", status.AsInt64())
+	}
+	for _, kv := range span.Attributes() {
+		if strings.Contains(kv.Value.Emit(), "secret-key") {
+			t.Fatalf("attribute %s leaks the query string: %s", kv.Key, kv.Value.Emit())
+		}
+	}
+	if events := span.Events(); len(events) != 1 || events[0].Name != "first_byte" {
+		t.Fatalf("events = %+v, want first_byte", events)
+	}
+}
+
+func TestWrapTransportSkipsUntracedRequests(t *testing.T) {
+	recorder := installRecorder(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{Transport: WrapTransport(nil)}
+	resp, errGet := client.Get(server.URL)
+	if errGet != nil {
+		t.Fatalf("Get() error = %v", errGet)
+	}
+	_ = resp.Body.Close()
+	if spans := recorder.Ended(); len(spans) != 0 {
+		t.Fatalf("recorded %d spans for a request without a parent span", len(spans))
+	}
+}
+
+func TestRecordUsageAnnotatesRequestSpan(t *testing.T) {
+	recorder := installRecorder(t)
+
+	ctx, request := StartRequest(context.Background(), nil, "POST /v1/messages")
+	attemptCtx, attempt := Start(ctx, "auth.attempt")
+	RecordUsage(attemptCtx, "claude", "claude-sonnet-4-5", "3", Usage{InputTokens: 12, OutputTokens: 5, TotalTokens: 17})
+	attempt.End()
+	request.End()
+
+	for _, name := range []string{"auth.attempt", "POST /v1/messages"} {
+		span := endedSpan(t, recorder, name)
+		if input, _ := spanAttr(span, AttrInputTokens); input.AsInt64() != 12 {
+			t.Fatalf("%s input tokens = %d", name, input.AsInt64())
+		}
+		if index, _ := spanAttr(span, AttrAuthIndex); index.AsString() != "3" {
+			t.Fatalf("%s auth index = %q", name, index.AsString())
+		}
+		if _, ok := spanAttr(span, AttrReasoningTokens); ok {
+			t.Fatalf("%s has zero reasoning tokens recorded", name)
+		}
+	}
+}
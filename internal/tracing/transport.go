@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type upstreamSpanKey struct{}
+
+// WrapTransport returns a RoundTripper that records a client span for every upstream
+// HTTP exchange made within a traced request. The span stays open until the response
+// body is drained or closed, with a "first_byte" event on the first body read, so
+// streaming responses are covered end to end. Nested wrappers record a single span.
+func WrapTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if _, ok := base.(roundTripper); ok {
+		return base
+	}
+	return roundTripper{base: base}
+}
+
+type roundTripper struct {
+	base http.RoundTripper
+}
+
+func (t roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if !Enabled() || ctx.Value(upstreamSpanKey{}) != nil || !trace.SpanContextFromContext(ctx).IsValid() {
+		return t.base.RoundTrip(req)
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", req.Method),
+	}
+	if req.URL != nil {
+		// The query string is left out: some upstreams carry API keys there.
+		attrs = append(attrs,
+			attribute.String("server.address", req.URL.Hostname()),
+			attribute.String("url.path", req.URL.Path),
+		)
+	}
+	ctx, span := Tracer().Start(ctx, "upstream "+req.Method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	ctx = context.WithValue(ctx, upstreamSpanKey{}, struct{}{})
+
+	resp, errRoundTrip := t.base.RoundTrip(req.WithContext(ctx))
+	if errRoundTrip != nil {
+		End(span, errRoundTrip)
+		return resp, errRoundTrip
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	if resp.Body == nil || resp.Body == http.NoBody {
+		span.End()
+		return resp, nil
+	}
+	resp.Body = &tracedBody{ReadCloser: resp.Body, span: span}
+	return resp, nil
+}
+
+// tracedBody ends the upstream span once the response body is consumed.
+type tracedBody struct {
+	io.ReadCloser
+	span      trace.Span
+	firstByte sync.Once
+	end       sync.Once
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, errRead := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.firstByte.Do(func() { b.span.AddEvent("first_byte") })
+	}
+	if errRead != nil {
+		b.finish(errRead)
+	}
+	return n, errRead
+}
+
+func (b *tracedBody) Close() error {
+	errClose := b.ReadCloser.Close()
+	b.finish(nil)
+	return errClose
+}
+
+func (b *tracedBody) finish(err error) {
+	b.end.Do(func() {
+		if err != nil && err != io.EOF {
+			End(b.span, err)
+			return
+		}
+		b.span.End()
+	})
+}
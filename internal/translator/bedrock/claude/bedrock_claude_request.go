@@ -0,0 +1,247 @@
+// Package claude provides translation between Anthropic Messages payloads and the AWS
+// Bedrock Converse API. Requests map system prompts, message content blocks, tools and
+// sampling parameters onto their Converse equivalents; Converse responses and
+// ConverseStream events are mapped back onto Anthropic messages and SSE events.
+package claude
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertClaudeRequestToBedrockConverse transforms an Anthropic Messages request into a
+// Bedrock Converse request. The model and stream flag are not part of the Converse body;
+// the executor selects the model and the Converse or ConverseStream operation by URL.
+// Parameters Converse has no field for (top_k, thinking) are forwarded through
+// additionalModelRequestFields so Anthropic models still honour them.
+func ConvertClaudeRequestToBedrockConverse(_ string, inputRawJSON []byte, _ bool) []byte {
+	root := gjson.ParseBytes(inputRawJSON)
+	out := []byte(`{"messages":[]}`)
+
+	system := root.Get("system")
+	switch {
+	case system.Type == gjson.String && system.String() != "":
+		block := []byte(`{"text":""}`)
+		block, _ = sjson.SetBytes(block, "text", system.String())
+		out, _ = sjson.SetRawBytes(out, "system.-1", block)
+	case system.IsArray():
+		system.ForEach(func(_, part gjson.Result) bool {
+			if part.Get("type").String() != "text" || part.Get("text").String() == "" {
+				return true
+			}
+			block := []byte(`{"text":""}`)
+			block, _ = sjson.SetBytes(block, "text", part.Get("text").String())
+			out, _ = sjson.SetRawBytes(out, "system.-1", block)
+			if part.Get("cache_control").Exists() {
+				out, _ = sjson.SetRawBytes(out, "system.-1", []byte(`{"cachePoint":{"type":"default"}}`))
+			}
+			return true
+		})
+	}
+
+	root.Get("messages").ForEach(func(_, message gjson.Result) bool {
+		role := message.Get("role").String()
+		if role != "assistant" {
+			role = "user"
+		}
+		msg := []byte(`{"role":"","content":[]}`)
+		msg, _ = sjson.SetBytes(msg, "role", role)
+		content := message.Get("content")
+		if content.Type == gjson.String {
+			if content.String() != "" {
+				block := []byte(`{"text":""}`)
+				block, _ = sjson.SetBytes(block, "text", content.String())
+				msg, _ = sjson.SetRawBytes(msg, "content.-1", block)
+			}
+		} else {
+			content.ForEach(func(_, part gjson.Result) bool {
+				for _, block := range converseContentBlocks(part) {
+					msg, _ = sjson.SetRawBytes(msg, "content.-1", block)
+				}
+				return true
+			})
+		}
+		if len(gjson.GetBytes(msg, "content").Array()) == 0 {
+			return true
+		}
+		out, _ = sjson.SetRawBytes(out, "messages.-1", msg)
+		return true
+	})
+
+	if maxTokens := root.Get("max_tokens"); maxTokens.Exists() {
+		out, _ = sjson.SetBytes(out, "inferenceConfig.maxTokens", maxTokens.Int())
+	}
+	if temperature := root.Get("temperature"); temperature.Exists() {
+		out, _ = sjson.SetBytes(out, "inferenceConfig.temperature", temperature.Float())
+	}
+	if topP := root.Get("top_p"); topP.Exists() {
+		out, _ = sjson.SetBytes(out, "inferenceConfig.topP", topP.Float())
+	}
+	if stopSequences := root.Get("stop_sequences"); stopSequences.IsArray() && len(stopSequences.Array()) > 0 {
+		out, _ = sjson.SetRawBytes(out, "inferenceConfig.stopSequences", []byte(stopSequences.Raw))
+	}
+	if topK := root.Get("top_k"); topK.Exists() {
+		out, _ = sjson.SetBytes(out, "additionalModelRequestFields.top_k", topK.Int())
+	}
+	if thinking := root.Get("thinking"); thinking.IsObject() {
+		out, _ = sjson.SetRawBytes(out, "additionalModelRequestFields.thinking", []byte(thinking.Raw))
+	}
+
+	if tools := root.Get("tools"); tools.IsArray() && len(tools.Array()) > 0 {
+		tools.ForEach(func(_, tool gjson.Result) bool {
+			name := tool.Get("name").String()
+			if name == "" {
+				return true
+			}
+			spec := []byte(`{"toolSpec":{"name":"","inputSchema":{"json":{"type":"object"}}}}`)
+			spec, _ = sjson.SetBytes(spec, "toolSpec.name", name)
+			if description := tool.Get("description").String(); description != "" {
+				spec, _ = sjson.SetBytes(spec, "toolSpec.description", description)
+			}
+			if schema := tool.Get("input_schema"); schema.IsObject() {
+				spec, _ = sjson.SetRawBytes(spec, "toolSpec.inputSchema.json", []byte(schema.Raw))
+			}
+			out, _ = sjson.SetRawBytes(out, "toolConfig.tools.-1", spec)
+			return true
+		})
+		// Converse has no "none" choice; leaving toolChoice unset keeps the default of auto.
+		switch toolChoice := root.Get("tool_choice"); toolChoice.Get("type").String() {
+		case "auto":
+			out, _ = sjson.SetRawBytes(out, "toolConfig.toolChoice", []byte(`{"auto":{}}`))
+		case "any":
+			out, _ = sjson.SetRawBytes(out, "toolConfig.toolChoice", []byte(`{"any":{}}`))
+		case "tool":
+			out, _ = sjson.SetBytes(out, "toolConfig.toolChoice.tool.name", toolChoice.Get("name").String())
+		}
+	}
+
+	return out
+}
+
+// converseContentBlocks maps one Anthropic content block onto zero or more Converse content blocks.
+func converseContentBlocks(part gjson.Result) [][]byte {
+	var blocks [][]byte
+	switch part.Get("type").String() {
+	case "text":
+		if text := part.Get("text").String(); text != "" {
+			block := []byte(`{"text":""}`)
+			block, _ = sjson.SetBytes(block, "text", text)
+			blocks = append(blocks, block)
+		}
+	case "image":
+		if block, ok := converseImageBlock(part.Get("source")); ok {
+			blocks = append(blocks, block)
+		}
+	case "document":
+		source := part.Get("source")
+		if source.Get("type").String() != "base64" {
+			break
+		}
+		format := converseDocumentFormat(source.Get("media_type").String())
+		if format == "" {
+			break
+		}
+		name := part.Get("title").String()
+		if name == "" {
+			name = "document"
+		}
+		block := []byte(`{"document":{"format":"","name":"","source":{"bytes":""}}}`)
+		block, _ = sjson.SetBytes(block, "document.format", format)
+		block, _ = sjson.SetBytes(block, "document.name", name)
+		block, _ = sjson.SetBytes(block, "document.source.bytes", source.Get("data").String())
+		blocks = append(blocks, block)
+	case "tool_use":
+		block := []byte(`{"toolUse":{"toolUseId":"","name":"","input":{}}}`)
+		block, _ = sjson.SetBytes(block, "toolUse.toolUseId", part.Get("id").String())
+		block, _ = sjson.SetBytes(block, "toolUse.name", part.Get("name").String())
+		if input := part.Get("input"); input.IsObject() {
+			block, _ = sjson.SetRawBytes(block, "toolUse.input", []byte(input.Raw))
+		}
+		blocks = append(blocks, block)
+	case "tool_result":
+		block := []byte(`{"toolResult":{"toolUseId":"","content":[]}}`)
+		block, _ = sjson.SetBytes(block, "toolResult.toolUseId", part.Get("tool_use_id").String())
+		content := part.Get("content")
+		if content.Type == gjson.String {
+			item := []byte(`{"text":""}`)
+			item, _ = sjson.SetBytes(item, "text", content.String())
+			block, _ = sjson.SetRawBytes(block, "toolResult.content.-1", item)
+		} else {
+			content.ForEach(func(_, item gjson.Result) bool {
+				switch item.Get("type").String() {
+				case "text":
+					text := []byte(`{"text":""}`)
+					text, _ = sjson.SetBytes(text, "text", item.Get("text").String())
+					block, _ = sjson.SetRawBytes(block, "toolResult.content.-1", text)
+				case "image":
+					if image, ok := converseImageBlock(item.Get("source")); ok {
+						block, _ = sjson.SetRawBytes(block, "toolResult.content.-1", image)
+					}
+				}
+				return true
+			})
+		}
+		if len(gjson.GetBytes(block, "toolResult.content").Array()) == 0 {
+			block, _ = sjson.SetRawBytes(block, "toolResult.content.-1", []byte(`{"text":""}`))
+		}
+		if part.Get("is_error").Bool() {
+			block, _ = sjson.SetBytes(block, "toolResult.status", "error")
+		}
+		blocks = append(blocks, block)
+	case "thinking":
+		block := []byte(`{"reasoningContent":{"reasoningText":{"text":""}}}`)
+		block, _ = sjson.SetBytes(block, "reasoningContent.reasoningText.text", part.Get("thinking").String())
+		if signature := part.Get("signature").String(); signature != "" {
+			block, _ = sjson.SetBytes(block, "reasoningContent.reasoningText.signature", signature)
+		}
+		blocks = append(blocks, block)
+	case "redacted_thinking":
+		block := []byte(`{"reasoningContent":{"redactedContent":""}}`)
+		block, _ = sjson.SetBytes(block, "reasoningContent.redactedContent", part.Get("data").String())
+		blocks = append(blocks, block)
+	}
+	if len(blocks) > 0 && part.Get("cache_control").Exists() {
+		blocks = append(blocks, []byte(`{"cachePoint":{"type":"default"}}`))
+	}
+	return blocks
+}
+
+// converseImageBlock converts a base64 Anthropic image source. URL sources have no
+// Converse equivalent besides S3 locations and are dropped.
+func converseImageBlock(source gjson.Result) ([]byte, bool) {
+	if source.Get("type").String() != "base64" {
+		return nil, false
+	}
+	format := strings.TrimPrefix(strings.ToLower(source.Get("media_type").String()), "image/")
+	switch format {
+	case "jpg":
+		format = "jpeg"
+	case "png", "jpeg", "gif", "webp":
+	default:
+		return nil, false
+	}
+	block := []byte(`{"image":{"format":"","source":{"bytes":""}}}`)
+	block, _ = sjson.SetBytes(block, "image.format", format)
+	block, _ = sjson.SetBytes(block, "image.source.bytes", source.Get("data").String())
+	return block, true
+}
+
+// converseDocumentFormat maps a document media type onto a Converse document format.
+func converseDocumentFormat(mediaType string) string {
+	switch strings.ToLower(mediaType) {
+	case "application/pdf":
+		return "pdf"
+	case "text/plain":
+		return "txt"
+	case "text/csv":
+		return "csv"
+	case "text/html":
+		return "html"
+	case "text/markdown":
+		return "md"
+	default:
+		return ""
+	}
+}
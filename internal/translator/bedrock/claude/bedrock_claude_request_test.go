@@ -0,0 +1,54 @@
+package claude
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertClaudeRequestToBedrockConverse(t *testing.T) {
+	input := []byte(`{
+		"model":"claude",
+		"max_tokens":256,
+		"temperature":0.2,
+		"top_k":40,
+		"stop_sequences":["END"],
+		"system":[{"type":"text","text":"Be brief.","cache_control":{"type":"ephemeral"}}],
+		"messages":[
+			{"role":"user","content":[{"type":"text","text":"Weather?"},{"type":"image","source":{"type":"base64","media_type":"image/png","data":"aGk="}}]},
+			{"role":"assistant","content":[{"type":"thinking","thinking":"look it up","signature":"sig"},{"type":"tool_use","id":"tu_1","name":"weather","input":{"city":"Paris"}}]},
+			{"role":"user","content":[{"type":"tool_result","tool_use_id":"tu_1","content":"sunny","is_error":true}]}
+		],
+		"tools":[{"name":"weather","description":"Look up weather","input_schema":{"type":"object","properties":{"city":{"type":"string"}}}}],
+		"tool_choice":{"type":"tool","name":"weather"}
+	}`)
+
+	out := gjson.ParseBytes(ConvertClaudeRequestToBedrockConverse("claude", input, false))
+
+	checks := map[string]string{
+		"system.0.text":                                                 "Be brief.",
+		"system.1.cachePoint.type":                                      "default",
+		"messages.0.content.0.text":                                     "Weather?",
+		"messages.0.content.1.image.format":                             "png",
+		"messages.0.content.1.image.source.bytes":                       "aGk=",
+		"messages.1.content.0.reasoningContent.reasoningText.signature": "sig",
+		"messages.1.content.1.toolUse.toolUseId":                        "tu_1",
+		"messages.1.content.1.toolUse.input.city":                       "Paris",
+		"messages.2.content.0.toolResult.content.0.text":                "sunny",
+		"messages.2.content.0.toolResult.status":                        "error",
+		"inferenceConfig.maxTokens":                                     "256",
+		"inferenceConfig.stopSequences.0":                               "END",
+		"additionalModelRequestFields.top_k":                            "40",
+		"toolConfig.tools.0.toolSpec.name":                              "weather",
+		"toolConfig.tools.0.toolSpec.inputSchema.json.type":             "object",
+		"toolConfig.toolChoice.tool.name":                               "weather",
+	}
+	for path, want := range checks {
+		if got := out.Get(path).String(); got != want {
+			t.Errorf("%s = %q, want %q\n%s", path, got, want, out.Raw)
+		}
+	}
+	if out.Get("model").Exists() {
+		t.Errorf("converse body must not carry model: %s", out.Raw)
+	}
+}
@@ -0,0 +1,247 @@
+package claude
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	translatorcommon "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/common"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertBedrockConverseResponseToClaudeParams holds the streaming conversion state.
+type ConvertBedrockConverseResponseToClaudeParams struct {
+	MessageID      string
+	Model          string
+	MessageStarted bool
+	// Blocks records the Anthropic block type opened for each Converse content block index.
+	Blocks     map[int]string
+	StopReason string
+	Finished   bool
+}
+
+// ConvertBedrockConverseResponseToClaude converts one ConverseStream event into Anthropic SSE
+// events. The executor wraps each decoded event payload in an object keyed by its event type,
+// e.g. {"contentBlockDelta":{...}}. The metadata event, which carries usage, closes the message;
+// a trailing "[DONE]" closes it when the stream ended without one.
+func ConvertBedrockConverseResponseToClaude(_ context.Context, modelName string, _, _, rawJSON []byte, param *any) [][]byte {
+	if *param == nil {
+		*param = &ConvertBedrockConverseResponseToClaudeParams{
+			MessageID: newMessageID(),
+			Model:     modelName,
+			Blocks:    make(map[int]string),
+		}
+	}
+	state := (*param).(*ConvertBedrockConverseResponseToClaudeParams)
+	if state.Finished {
+		return nil
+	}
+
+	if bytes.Equal(bytes.TrimSpace(rawJSON), []byte("[DONE]")) {
+		var results [][]byte
+		state.startMessage(&results)
+		state.stopBlocks(&results)
+		state.finish(&results, gjson.Result{})
+		return results
+	}
+
+	root := gjson.ParseBytes(rawJSON)
+	var results [][]byte
+	switch {
+	case root.Get("messageStart").Exists():
+		state.startMessage(&results)
+	case root.Get("contentBlockStart").Exists():
+		event := root.Get("contentBlockStart")
+		state.startMessage(&results)
+		if toolUse := event.Get("start.toolUse"); toolUse.Exists() {
+			index := int(event.Get("contentBlockIndex").Int())
+			block := []byte(`{"type":"tool_use","id":"","name":"","input":{}}`)
+			block, _ = sjson.SetBytes(block, "id", toolUse.Get("toolUseId").String())
+			block, _ = sjson.SetBytes(block, "name", toolUse.Get("name").String())
+			state.startBlock(&results, index, "tool_use", block)
+		}
+	case root.Get("contentBlockDelta").Exists():
+		event := root.Get("contentBlockDelta")
+		state.startMessage(&results)
+		index := int(event.Get("contentBlockIndex").Int())
+		delta := event.Get("delta")
+		switch {
+		case delta.Get("text").Exists():
+			state.startBlock(&results, index, "text", []byte(`{"type":"text","text":""}`))
+			out := []byte(`{"type":"text_delta","text":""}`)
+			out, _ = sjson.SetBytes(out, "text", delta.Get("text").String())
+			state.delta(&results, index, out)
+		case delta.Get("toolUse").Exists():
+			out := []byte(`{"type":"input_json_delta","partial_json":""}`)
+			out, _ = sjson.SetBytes(out, "partial_json", delta.Get("toolUse.input").String())
+			state.delta(&results, index, out)
+		case delta.Get("reasoningContent.redactedContent").Exists():
+			block := []byte(`{"type":"redacted_thinking","data":""}`)
+			block, _ = sjson.SetBytes(block, "data", delta.Get("reasoningContent.redactedContent").String())
+			state.startBlock(&results, index, "redacted_thinking", block)
+		case delta.Get("reasoningContent").Exists():
+			state.startBlock(&results, index, "thinking", []byte(`{"type":"thinking","thinking":""}`))
+			if text := delta.Get("reasoningContent.text"); text.Exists() {
+				out := []byte(`{"type":"thinking_delta","thinking":""}`)
+				out, _ = sjson.SetBytes(out, "thinking", text.String())
+				state.delta(&results, index, out)
+			}
+			if signature := delta.Get("reasoningContent.signature"); signature.Exists() {
+				out := []byte(`{"type":"signature_delta","signature":""}`)
+				out, _ = sjson.SetBytes(out, "signature", signature.String())
+				state.delta(&results, index, out)
+			}
+		}
+	case root.Get("contentBlockStop").Exists():
+		index := int(root.Get("contentBlockStop.contentBlockIndex").Int())
+		state.stopBlock(&results, index)
+	case root.Get("messageStop").Exists():
+		state.startMessage(&results)
+		state.StopReason = claudeStopReason(root.Get("messageStop.stopReason").String())
+		state.stopBlocks(&results)
+	case root.Get("metadata").Exists():
+		state.startMessage(&results)
+		state.stopBlocks(&results)
+		state.finish(&results, root.Get("metadata.usage"))
+	}
+	return results
+}
+
+// ConvertBedrockConverseResponseToClaudeNonStream converts a Converse response into an
+// Anthropic message.
+func ConvertBedrockConverseResponseToClaudeNonStream(_ context.Context, modelName string, _, _, rawJSON []byte, _ *any) []byte {
+	root := gjson.ParseBytes(rawJSON)
+	out := []byte(`{"id":"","type":"message","role":"assistant","model":"","content":[],"stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":0,"output_tokens":0}}`)
+	out, _ = sjson.SetBytes(out, "id", newMessageID())
+	out, _ = sjson.SetBytes(out, "model", modelName)
+
+	root.Get("output.message.content").ForEach(func(_, part gjson.Result) bool {
+		var block []byte
+		switch {
+		case part.Get("text").Exists():
+			block = []byte(`{"type":"text","text":""}`)
+			block, _ = sjson.SetBytes(block, "text", part.Get("text").String())
+		case part.Get("toolUse").Exists():
+			block = []byte(`{"type":"tool_use","id":"","name":"","input":{}}`)
+			block, _ = sjson.SetBytes(block, "id", part.Get("toolUse.toolUseId").String())
+			block, _ = sjson.SetBytes(block, "name", part.Get("toolUse.name").String())
+			if input := part.Get("toolUse.input"); input.IsObject() {
+				block, _ = sjson.SetRawBytes(block, "input", []byte(input.Raw))
+			}
+		case part.Get("reasoningContent.redactedContent").Exists():
+			block = []byte(`{"type":"redacted_thinking","data":""}`)
+			block, _ = sjson.SetBytes(block, "data", part.Get("reasoningContent.redactedContent").String())
+		case part.Get("reasoningContent.reasoningText").Exists():
+			block = []byte(`{"type":"thinking","thinking":"","signature":""}`)
+			block, _ = sjson.SetBytes(block, "thinking", part.Get("reasoningContent.reasoningText.text").String())
+			block, _ = sjson.SetBytes(block, "signature", part.Get("reasoningContent.reasoningText.signature").String())
+		default:
+			return true
+		}
+		out, _ = sjson.SetRawBytes(out, "content.-1", block)
+		return true
+	})
+
+	out, _ = sjson.SetBytes(out, "stop_reason", claudeStopReason(root.Get("stopReason").String()))
+	out, _ = sjson.SetRawBytes(out, "usage", claudeUsage(root.Get("usage")))
+	return out
+}
+
+func (s *ConvertBedrockConverseResponseToClaudeParams) startMessage(results *[][]byte) {
+	if s.MessageStarted {
+		return
+	}
+	s.MessageStarted = true
+	out := []byte(`{"type":"message_start","message":{"id":"","type":"message","role":"assistant","model":"","content":[],"stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":0,"output_tokens":0}}}`)
+	out, _ = sjson.SetBytes(out, "message.id", s.MessageID)
+	out, _ = sjson.SetBytes(out, "message.model", s.Model)
+	*results = append(*results, translatorcommon.AppendSSEEventBytes(nil, "message_start", out, 2))
+}
+
+func (s *ConvertBedrockConverseResponseToClaudeParams) startBlock(results *[][]byte, index int, blockType string, block []byte) {
+	if _, ok := s.Blocks[index]; ok {
+		return
+	}
+	s.Blocks[index] = blockType
+	out := []byte(`{"type":"content_block_start","index":0,"content_block":{}}`)
+	out, _ = sjson.SetBytes(out, "index", index)
+	out, _ = sjson.SetRawBytes(out, "content_block", block)
+	*results = append(*results, translatorcommon.AppendSSEEventBytes(nil, "content_block_start", out, 2))
+}
+
+func (s *ConvertBedrockConverseResponseToClaudeParams) delta(results *[][]byte, index int, delta []byte) {
+	out := []byte(`{"type":"content_block_delta","index":0,"delta":{}}`)
+	out, _ = sjson.SetBytes(out, "index", index)
+	out, _ = sjson.SetRawBytes(out, "delta", delta)
+	*results = append(*results, translatorcommon.AppendSSEEventBytes(nil, "content_block_delta", out, 2))
+}
+
+func (s *ConvertBedrockConverseResponseToClaudeParams) stopBlock(results *[][]byte, index int) {
+	if _, ok := s.Blocks[index]; !ok {
+		return
+	}
+	delete(s.Blocks, index)
+	out := []byte(`{"type":"content_block_stop","index":0}`)
+	out, _ = sjson.SetBytes(out, "index", index)
+	*results = append(*results, translatorcommon.AppendSSEEventBytes(nil, "content_block_stop", out, 2))
+}
+
+// stopBlocks closes any blocks the upstream left open, in index order.
+func (s *ConvertBedrockConverseResponseToClaudeParams) stopBlocks(results *[][]byte) {
+	for len(s.Blocks) > 0 {
+		lowest := -1
+		for index := range s.Blocks {
+			if lowest < 0 || index < lowest {
+				lowest = index
+			}
+		}
+		s.stopBlock(results, lowest)
+	}
+}
+
+func (s *ConvertBedrockConverseResponseToClaudeParams) finish(results *[][]byte, usage gjson.Result) {
+	s.Finished = true
+	stopReason := s.StopReason
+	if stopReason == "" {
+		stopReason = "end_turn"
+	}
+	out := []byte(`{"type":"message_delta","delta":{"stop_reason":"","stop_sequence":null},"usage":{}}`)
+	out, _ = sjson.SetBytes(out, "delta.stop_reason", stopReason)
+	out, _ = sjson.SetRawBytes(out, "usage", claudeUsage(usage))
+	*results = append(*results, translatorcommon.AppendSSEEventBytes(nil, "message_delta", out, 2))
+	*results = append(*results, translatorcommon.AppendSSEEventBytes(nil, "message_stop", []byte(`{"type":"message_stop"}`), 2))
+}
+
+// claudeUsage maps a Converse usage object onto Anthropic usage fields.
+func claudeUsage(usage gjson.Result) []byte {
+	out := []byte(`{"input_tokens":0,"output_tokens":0}`)
+	out, _ = sjson.SetBytes(out, "input_tokens", usage.Get("inputTokens").Int())
+	out, _ = sjson.SetBytes(out, "output_tokens", usage.Get("outputTokens").Int())
+	if cacheRead := usage.Get("cacheReadInputTokens"); cacheRead.Exists() {
+		out, _ = sjson.SetBytes(out, "cache_read_input_tokens", cacheRead.Int())
+	}
+	if cacheWrite := usage.Get("cacheWriteInputTokens"); cacheWrite.Exists() {
+		out, _ = sjson.SetBytes(out, "cache_creation_input_tokens", cacheWrite.Int())
+	}
+	return out
+}
+
+// claudeStopReason maps a Converse stopReason onto the Anthropic stop_reason vocabulary.
+func claudeStopReason(stopReason string) string {
+	switch stopReason {
+	case "tool_use", "max_tokens", "stop_sequence":
+		return stopReason
+	case "guardrail_intervened", "content_filtered":
+		return "refusal"
+	case "model_context_window_exceeded":
+		return "model_context_window_exceeded"
+	default:
+		return "end_turn"
+	}
+}
+
+func newMessageID() string {
+	return "msg_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
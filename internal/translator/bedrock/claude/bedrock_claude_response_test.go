@@ -0,0 +1,64 @@
+package claude
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertBedrockConverseResponseToClaudeStreamsToolUse(t *testing.T) {
+	events := []string{
+		`{"messageStart":{"role":"assistant"}}`,
+		`{"contentBlockDelta":{"contentBlockIndex":0,"delta":{"reasoningContent":{"text":"hmm"}}}}`,
+		`{"contentBlockDelta":{"contentBlockIndex":0,"delta":{"reasoningContent":{"signature":"sig"}}}}`,
+		`{"contentBlockStop":{"contentBlockIndex":0}}`,
+		`{"contentBlockStart":{"contentBlockIndex":1,"start":{"toolUse":{"toolUseId":"tu_1","name":"weather"}}}}`,
+		`{"contentBlockDelta":{"contentBlockIndex":1,"delta":{"toolUse":{"input":"{\"city\":"}}}}`,
+		`{"contentBlockDelta":{"contentBlockIndex":1,"delta":{"toolUse":{"input":"\"Paris\"}"}}}}`,
+		`{"contentBlockStop":{"contentBlockIndex":1}}`,
+		`{"messageStop":{"stopReason":"tool_use"}}`,
+		`{"metadata":{"usage":{"inputTokens":10,"outputTokens":5,"cacheReadInputTokens":2}}}`,
+		`[DONE]`,
+	}
+	var param any
+	var out strings.Builder
+	for _, event := range events {
+		for _, chunk := range ConvertBedrockConverseResponseToClaude(context.Background(), "model-a", nil, nil, []byte(event), &param) {
+			out.Write(chunk)
+		}
+	}
+	stream := out.String()
+	for _, want := range []string{
+		`"model":"model-a"`,
+		`{"type":"thinking_delta","thinking":"hmm"}`,
+		`{"type":"signature_delta","signature":"sig"}`,
+		`"content_block":{"type":"tool_use","id":"tu_1","name":"weather","input":{}}`,
+		`"partial_json":"\"Paris\"}"`,
+		`"stop_reason":"tool_use"`,
+		`"cache_read_input_tokens":2`,
+	} {
+		if !strings.Contains(stream, want) {
+			t.Fatalf("stream missing %s:\n%s", want, stream)
+		}
+	}
+	if got := strings.Count(stream, "event: message_stop"); got != 1 {
+		t.Fatalf("message_stop count = %d, want 1:\n%s", got, stream)
+	}
+}
+
+func TestConvertBedrockConverseResponseToClaudeNonStream(t *testing.T) {
+	raw := []byte(`{"output":{"message":{"role":"assistant","content":[{"text":"Hi"},{"toolUse":{"toolUseId":"tu_1","name":"weather","input":{"city":"Paris"}}}]}},"stopReason":"tool_use","usage":{"inputTokens":7,"outputTokens":3,"totalTokens":10}}`)
+	out := gjson.ParseBytes(ConvertBedrockConverseResponseToClaudeNonStream(context.Background(), "model-a", nil, nil, raw, nil))
+
+	if got := out.Get("content.0.text").String(); got != "Hi" {
+		t.Fatalf("text = %q, out %s", got, out.Raw)
+	}
+	if got := out.Get("content.1.input.city").String(); got != "Paris" {
+		t.Fatalf("tool input = %q, out %s", got, out.Raw)
+	}
+	if out.Get("stop_reason").String() != "tool_use" || out.Get("usage.input_tokens").Int() != 7 || out.Get("usage.output_tokens").Int() != 3 {
+		t.Fatalf("unexpected message: %s", out.Raw)
+	}
+}
@@ -0,0 +1,19 @@
+package claude
+
+import (
+	. "github.com/router-for-me/CLIProxyAPI/v7/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/translator/translator"
+)
+
+func init() {
+	translator.Register(
+		Claude,
+		BedrockConverse,
+		ConvertClaudeRequestToBedrockConverse,
+		interfaces.TranslateResponse{
+			Stream:    ConvertBedrockConverseResponseToClaude,
+			NonStream: ConvertBedrockConverseResponseToClaudeNonStream,
+		},
+	)
+}
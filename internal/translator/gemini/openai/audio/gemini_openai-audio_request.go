@@ -0,0 +1,130 @@
+// Package audio translates the OpenAI audio endpoints (/v1/audio/transcriptions,
+// /v1/audio/translations and /v1/audio/speech) to Gemini generateContent requests
+// for audio-capable Gemini models.
+//
+// The handler wraps multipart uploads into a JSON envelope before routing:
+//
+//	{"model":"...","task":"transcription","file":{"filename":"a.wav","mime_type":"audio/wav","data":"<base64>"},
+//	 "language":"en","prompt":"...","response_format":"json","temperature":0}
+//
+// Speech requests keep the OpenAI JSON body and only gain "task":"speech".
+package audio
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	taskTranscription = "transcription"
+	taskTranslation   = "translation"
+	taskSpeech        = "speech"
+
+	defaultGeminiVoice = "Kore"
+)
+
+// openAIToGeminiVoices maps the OpenAI TTS voice names onto Gemini prebuilt voices
+// with a similar character. Unknown names are passed through so Gemini voices can
+// be requested directly.
+var openAIToGeminiVoices = map[string]string{
+	"alloy":   "Kore",
+	"ash":     "Charon",
+	"ballad":  "Aoede",
+	"coral":   "Leda",
+	"echo":    "Puck",
+	"fable":   "Orus",
+	"nova":    "Zephyr",
+	"onyx":    "Fenrir",
+	"sage":    "Kore",
+	"shimmer": "Aoede",
+	"verse":   "Puck",
+}
+
+// ConvertOpenAIAudioRequestToGemini converts an OpenAI audio envelope into a Gemini
+// generateContent request.
+//
+// Parameters:
+//   - modelName: The name of the model to use for the request
+//   - inputRawJSON: The audio envelope built by the OpenAI audio handlers
+//   - stream: Unused; audio endpoints are non-streaming
+//
+// Returns:
+//   - []byte: The transformed request data in Gemini API format
+func ConvertOpenAIAudioRequestToGemini(modelName string, inputRawJSON []byte, _ bool) []byte {
+	out := []byte(`{"contents":[{"role":"user","parts":[]}]}`)
+	out, _ = sjson.SetBytes(out, "model", modelName)
+
+	task := audioTask(inputRawJSON)
+	if task == taskSpeech {
+		return convertSpeechRequest(out, inputRawJSON)
+	}
+
+	out, _ = sjson.SetBytes(out, "contents.0.parts.-1.text", transcriptionInstruction(task, inputRawJSON))
+	mimeType := strings.TrimSpace(gjson.GetBytes(inputRawJSON, "file.mime_type").String())
+	if mimeType == "" {
+		mimeType = "audio/wav"
+	}
+	out, _ = sjson.SetBytes(out, "contents.0.parts.-1.inlineData", map[string]string{
+		"mimeType": mimeType,
+		"data":     gjson.GetBytes(inputRawJSON, "file.data").String(),
+	})
+	if temperature := gjson.GetBytes(inputRawJSON, "temperature"); temperature.Exists() && temperature.Type == gjson.Number {
+		out, _ = sjson.SetBytes(out, "generationConfig.temperature", temperature.Float())
+	}
+	return out
+}
+
+// audioTask returns the normalized task of an audio envelope, defaulting to transcription.
+func audioTask(rawJSON []byte) string {
+	switch strings.ToLower(strings.TrimSpace(gjson.GetBytes(rawJSON, "task").String())) {
+	case taskTranslation:
+		return taskTranslation
+	case taskSpeech:
+		return taskSpeech
+	default:
+		return taskTranscription
+	}
+}
+
+func transcriptionInstruction(task string, rawJSON []byte) string {
+	var b strings.Builder
+	if task == taskTranslation {
+		b.WriteString("Translate the speech in the attached audio into English. Respond with only the English translation, without commentary.")
+	} else {
+		b.WriteString("Transcribe the speech in the attached audio verbatim. Respond with only the transcript, without commentary.")
+		if language := strings.TrimSpace(gjson.GetBytes(rawJSON, "language").String()); language != "" {
+			b.WriteString(" The spoken language is ")
+			b.WriteString(language)
+			b.WriteString(".")
+		}
+	}
+	if prompt := strings.TrimSpace(gjson.GetBytes(rawJSON, "prompt").String()); prompt != "" {
+		b.WriteString(" Context for spelling and style: ")
+		b.WriteString(prompt)
+	}
+	return b.String()
+}
+
+func convertSpeechRequest(out, rawJSON []byte) []byte {
+	text := gjson.GetBytes(rawJSON, "input").String()
+	if instructions := strings.TrimSpace(gjson.GetBytes(rawJSON, "instructions").String()); instructions != "" {
+		text = instructions + ": " + text
+	}
+	out, _ = sjson.SetBytes(out, "contents.0.parts.-1.text", text)
+	out, _ = sjson.SetBytes(out, "generationConfig.responseModalities", []string{"AUDIO"})
+	out, _ = sjson.SetBytes(out, "generationConfig.speechConfig.voiceConfig.prebuiltVoiceConfig.voiceName", geminiVoiceName(gjson.GetBytes(rawJSON, "voice").String()))
+	return out
+}
+
+func geminiVoiceName(voice string) string {
+	voice = strings.TrimSpace(voice)
+	if voice == "" {
+		return defaultGeminiVoice
+	}
+	if mapped, ok := openAIToGeminiVoices[strings.ToLower(voice)]; ok {
+		return mapped
+	}
+	return voice
+}
@@ -0,0 +1,42 @@
+package audio
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOpenAIAudioRequestToGeminiTranscription(t *testing.T) {
+	input := []byte(`{"model":"gemini-2.5-flash","task":"transcription","file":{"filename":"a.mp3","mime_type":"audio/mpeg","data":"QUJD"},"language":"de","prompt":"Names: Anja","temperature":0.2}`)
+	out := ConvertOpenAIAudioRequestToGemini("gemini-2.5-flash", input, false)
+
+	text := gjson.GetBytes(out, "contents.0.parts.0.text").String()
+	if !strings.Contains(text, "Transcribe") || !strings.Contains(text, "language is de") || !strings.Contains(text, "Anja") {
+		t.Fatalf("instruction = %q", text)
+	}
+	if got := gjson.GetBytes(out, "contents.0.parts.1.inlineData.mimeType").String(); got != "audio/mpeg" {
+		t.Fatalf("mimeType = %q", got)
+	}
+	if got := gjson.GetBytes(out, "contents.0.parts.1.inlineData.data").String(); got != "QUJD" {
+		t.Fatalf("data = %q", got)
+	}
+	if got := gjson.GetBytes(out, "generationConfig.temperature").Float(); got != 0.2 {
+		t.Fatalf("temperature = %v", got)
+	}
+}
+
+func TestConvertOpenAIAudioRequestToGeminiSpeech(t *testing.T) {
+	input := []byte(`{"model":"gemini-2.5-flash-preview-tts","task":"speech","input":"Hello there","voice":"nova","instructions":"Say cheerfully"}`)
+	out := ConvertOpenAIAudioRequestToGemini("gemini-2.5-flash-preview-tts", input, false)
+
+	if got := gjson.GetBytes(out, "contents.0.parts.0.text").String(); got != "Say cheerfully: Hello there" {
+		t.Fatalf("text = %q", got)
+	}
+	if got := gjson.GetBytes(out, "generationConfig.responseModalities.0").String(); got != "AUDIO" {
+		t.Fatalf("responseModalities = %q", got)
+	}
+	if got := gjson.GetBytes(out, "generationConfig.speechConfig.voiceConfig.prebuiltVoiceConfig.voiceName").String(); got != "Zephyr" {
+		t.Fatalf("voiceName = %q", got)
+	}
+}
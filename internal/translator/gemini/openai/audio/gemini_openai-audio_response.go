@@ -0,0 +1,167 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const defaultGeminiPCMRate = 24000
+
+// ConvertGeminiResponseToOpenAIAudioNonStream converts a Gemini generateContent response
+// into the OpenAI audio response for the task recorded in the original envelope.
+//
+// JSON transcription formats are returned as-is. Plain-text transcripts and synthesized
+// speech are wrapped in an "audio.response" envelope carrying the content type and the
+// base64 body, which the audio handlers unwrap before writing to the client.
+// Gemini does not return segment timings, so srt and vtt degrade to plain text, and
+// speech is only available as wav or raw pcm.
+func ConvertGeminiResponseToOpenAIAudioNonStream(_ context.Context, _ string, originalRequestRawJSON, _, rawJSON []byte, _ *any) []byte {
+	responseFormat := strings.ToLower(strings.TrimSpace(gjson.GetBytes(originalRequestRawJSON, "response_format").String()))
+	task := audioTask(originalRequestRawJSON)
+	if task == taskSpeech {
+		return convertSpeechResponse(rawJSON, responseFormat)
+	}
+
+	text := strings.TrimSpace(geminiResponseText(rawJSON))
+	switch responseFormat {
+	case "text", "srt", "vtt":
+		return audioEnvelope("text/plain; charset=utf-8", []byte(text))
+	case "verbose_json":
+		out := []byte(`{}`)
+		if task == taskTranslation {
+			out, _ = sjson.SetBytes(out, "task", "translate")
+			out, _ = sjson.SetBytes(out, "language", "english")
+		} else {
+			out, _ = sjson.SetBytes(out, "task", "transcribe")
+			out, _ = sjson.SetBytes(out, "language", strings.TrimSpace(gjson.GetBytes(originalRequestRawJSON, "language").String()))
+		}
+		out, _ = sjson.SetBytes(out, "text", text)
+		return out
+	default:
+		out := []byte(`{}`)
+		out, _ = sjson.SetBytes(out, "text", text)
+		if usage := transcriptionUsage(rawJSON); usage != nil {
+			out, _ = sjson.SetRawBytes(out, "usage", usage)
+		}
+		return out
+	}
+}
+
+func geminiResponseText(rawJSON []byte) string {
+	var b strings.Builder
+	gjson.GetBytes(rawJSON, "candidates.0.content.parts").ForEach(func(_, part gjson.Result) bool {
+		if part.Get("thought").Bool() {
+			return true
+		}
+		b.WriteString(part.Get("text").String())
+		return true
+	})
+	return b.String()
+}
+
+func transcriptionUsage(rawJSON []byte) []byte {
+	usageNode := gjson.GetBytes(rawJSON, "usageMetadata")
+	if !usageNode.Exists() {
+		return nil
+	}
+	var audioTokens, textTokens int64
+	usageNode.Get("promptTokensDetails").ForEach(func(_, item gjson.Result) bool {
+		switch strings.ToUpper(item.Get("modality").String()) {
+		case "AUDIO":
+			audioTokens += item.Get("tokenCount").Int()
+		case "TEXT":
+			textTokens += item.Get("tokenCount").Int()
+		}
+		return true
+	})
+	out := []byte(`{"type":"tokens"}`)
+	out, _ = sjson.SetBytes(out, "input_tokens", usageNode.Get("promptTokenCount").Int())
+	out, _ = sjson.SetBytes(out, "input_token_details.text_tokens", textTokens)
+	out, _ = sjson.SetBytes(out, "input_token_details.audio_tokens", audioTokens)
+	out, _ = sjson.SetBytes(out, "output_tokens", usageNode.Get("candidatesTokenCount").Int())
+	out, _ = sjson.SetBytes(out, "total_tokens", usageNode.Get("totalTokenCount").Int())
+	return out
+}
+
+func convertSpeechResponse(rawJSON []byte, responseFormat string) []byte {
+	var pcm []byte
+	mimeType := ""
+	gjson.GetBytes(rawJSON, "candidates.0.content.parts").ForEach(func(_, part gjson.Result) bool {
+		inline := part.Get("inlineData")
+		if !inline.Exists() {
+			inline = part.Get("inline_data")
+		}
+		if !inline.Exists() {
+			return true
+		}
+		decoded, err := base64.StdEncoding.DecodeString(inline.Get("data").String())
+		if err != nil {
+			return true
+		}
+		if mimeType == "" {
+			mimeType = inline.Get("mimeType").String()
+			if mimeType == "" {
+				mimeType = inline.Get("mime_type").String()
+			}
+		}
+		pcm = append(pcm, decoded...)
+		return true
+	})
+	if responseFormat == "pcm" {
+		return audioEnvelope("audio/pcm", pcm)
+	}
+	return audioEnvelope("audio/wav", wavFromPCM(pcm, pcmSampleRate(mimeType)))
+}
+
+// pcmSampleRate reads the rate parameter of a Gemini PCM mime type such as
+// "audio/L16;codec=pcm;rate=24000".
+func pcmSampleRate(mimeType string) int {
+	for _, param := range strings.Split(mimeType, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(key, "rate") {
+			continue
+		}
+		if rate, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && rate > 0 {
+			return rate
+		}
+	}
+	return defaultGeminiPCMRate
+}
+
+// wavFromPCM wraps 16-bit little-endian mono PCM samples in a RIFF/WAVE header.
+func wavFromPCM(pcm []byte, sampleRate int) []byte {
+	const (
+		channels      = 1
+		bitsPerSample = 16
+	)
+	var buf bytes.Buffer
+	buf.Grow(44 + len(pcm))
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(36+len(pcm)))
+	buf.WriteString("WAVEfmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(1))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(channels))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*channels*bitsPerSample/8))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(channels*bitsPerSample/8))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(bitsPerSample))
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(pcm)))
+	buf.Write(pcm)
+	return buf.Bytes()
+}
+
+func audioEnvelope(contentType string, data []byte) []byte {
+	out := []byte(`{"object":"audio.response"}`)
+	out, _ = sjson.SetBytes(out, "content_type", contentType)
+	out, _ = sjson.SetBytes(out, "data", base64.StdEncoding.EncodeToString(data))
+	return out
+}
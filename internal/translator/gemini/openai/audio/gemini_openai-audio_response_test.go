@@ -0,0 +1,58 @@
+package audio
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertGeminiResponseToOpenAIAudioTranscriptionJSON(t *testing.T) {
+	original := []byte(`{"task":"transcription"}`)
+	response := []byte(`{"candidates":[{"content":{"parts":[{"text":"hello world\n"}]}}],"usageMetadata":{"promptTokenCount":30,"candidatesTokenCount":3,"totalTokenCount":33,"promptTokensDetails":[{"modality":"TEXT","tokenCount":10},{"modality":"AUDIO","tokenCount":20}]}}`)
+	out := ConvertGeminiResponseToOpenAIAudioNonStream(context.Background(), "", original, nil, response, nil)
+
+	if got := gjson.GetBytes(out, "text").String(); got != "hello world" {
+		t.Fatalf("text = %q", got)
+	}
+	if got := gjson.GetBytes(out, "usage.input_token_details.audio_tokens").Int(); got != 20 {
+		t.Fatalf("audio_tokens = %d, out=%s", got, out)
+	}
+	if got := gjson.GetBytes(out, "usage.total_tokens").Int(); got != 33 {
+		t.Fatalf("total_tokens = %d", got)
+	}
+}
+
+func TestConvertGeminiResponseToOpenAIAudioTranscriptionText(t *testing.T) {
+	original := []byte(`{"task":"translation","response_format":"text"}`)
+	response := []byte(`{"candidates":[{"content":{"parts":[{"text":"good morning"}]}}]}`)
+	out := ConvertGeminiResponseToOpenAIAudioNonStream(context.Background(), "", original, nil, response, nil)
+
+	if got := gjson.GetBytes(out, "object").String(); got != "audio.response" {
+		t.Fatalf("object = %q", got)
+	}
+	decoded, _ := base64.StdEncoding.DecodeString(gjson.GetBytes(out, "data").String())
+	if string(decoded) != "good morning" {
+		t.Fatalf("data = %q", decoded)
+	}
+}
+
+func TestConvertGeminiResponseToOpenAIAudioSpeechWAV(t *testing.T) {
+	pcm := []byte{1, 0, 2, 0, 3, 0, 4, 0}
+	original := []byte(`{"task":"speech","input":"hi"}`)
+	response := []byte(`{"candidates":[{"content":{"parts":[{"inlineData":{"mimeType":"audio/L16;codec=pcm;rate=16000","data":"` + base64.StdEncoding.EncodeToString(pcm) + `"}}]}}]}`)
+	out := ConvertGeminiResponseToOpenAIAudioNonStream(context.Background(), "", original, nil, response, nil)
+
+	if got := gjson.GetBytes(out, "content_type").String(); got != "audio/wav" {
+		t.Fatalf("content_type = %q", got)
+	}
+	wav, _ := base64.StdEncoding.DecodeString(gjson.GetBytes(out, "data").String())
+	if len(wav) != 44+len(pcm) || string(wav[:4]) != "RIFF" || string(wav[8:12]) != "WAVE" {
+		t.Fatalf("unexpected wav header: %v", wav)
+	}
+	if rate := binary.LittleEndian.Uint32(wav[24:28]); rate != 16000 {
+		t.Fatalf("sample rate = %d", rate)
+	}
+}
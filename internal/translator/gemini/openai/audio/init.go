@@ -0,0 +1,18 @@
+package audio
+
+import (
+	. "github.com/router-for-me/CLIProxyAPI/v7/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/translator/translator"
+)
+
+func init() {
+	translator.Register(
+		OpenAIAudio,
+		Gemini,
+		ConvertOpenAIAudioRequestToGemini,
+		interfaces.TranslateResponse{
+			NonStream: ConvertGeminiResponseToOpenAIAudioNonStream,
+		},
+	)
+}
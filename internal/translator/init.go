@@ -15,6 +15,7 @@ import (
 	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/gemini/claude"
 	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/gemini/gemini"
 	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/gemini/interactions"
+	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/gemini/openai/audio"
 	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/gemini/openai/chat-completions"
 	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/gemini/openai/responses"
 
@@ -24,9 +25,12 @@ import (
 	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/openai/gemini"
 	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/openai/interactions/chat-completions"
 	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/openai/interactions/responses"
+	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/openai/ollama"
 	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/openai/openai/chat-completions"
 	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/openai/openai/responses"
 
+	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/bedrock/claude"
+
 	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/antigravity/claude"
 	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/antigravity/gemini"
 	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/antigravity/interactions"
@@ -0,0 +1,252 @@
+// Package ollama provides request translation functionality for the Ollama native API to OpenAI.
+// It converts /api/chat and /api/generate payloads into OpenAI Chat Completions requests,
+// mapping message images to image_url parts, tool calls to OpenAI tool_calls with string
+// arguments, and Ollama sampling options to their OpenAI equivalents.
+package ollama
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertOllamaRequestToOpenAI parses an Ollama /api/chat or /api/generate request and
+// transforms it into an OpenAI Chat Completions request. Generate requests are detected by
+// a top-level prompt without messages; their system and prompt fields become messages.
+func ConvertOllamaRequestToOpenAI(modelName string, inputRawJSON []byte, stream bool) []byte {
+	root := gjson.ParseBytes(inputRawJSON)
+	out := []byte(`{"model":"","messages":[]}`)
+	out, _ = sjson.SetBytes(out, "model", modelName)
+	if stream {
+		out, _ = sjson.SetBytes(out, "stream", true)
+		out, _ = sjson.SetBytes(out, "stream_options.include_usage", true)
+	}
+
+	if IsGenerateRequest(inputRawJSON) {
+		out = appendGenerateMessages(out, root)
+	} else {
+		out = appendChatMessages(out, root.Get("messages"))
+	}
+
+	out = applyOllamaOptions(out, root.Get("options"))
+	out = applyOllamaFormat(out, root.Get("format"))
+	out = applyOllamaThink(out, root.Get("think"))
+
+	if tools := root.Get("tools"); tools.IsArray() && len(tools.Array()) > 0 {
+		tools.ForEach(func(_, tool gjson.Result) bool {
+			if !tool.Get("function").Exists() {
+				return true
+			}
+			item := []byte(`{"type":"function","function":{}}`)
+			item, _ = sjson.SetRawBytes(item, "function", []byte(tool.Get("function").Raw))
+			out, _ = sjson.SetRawBytes(out, "tools.-1", item)
+			return true
+		})
+	}
+
+	return out
+}
+
+// IsGenerateRequest reports whether an Ollama payload targets /api/generate rather than /api/chat.
+func IsGenerateRequest(rawJSON []byte) bool {
+	return !gjson.GetBytes(rawJSON, "messages").Exists() && gjson.GetBytes(rawJSON, "prompt").Exists()
+}
+
+func appendGenerateMessages(out []byte, root gjson.Result) []byte {
+	if system := root.Get("system").String(); system != "" {
+		msg := []byte(`{"role":"system","content":""}`)
+		msg, _ = sjson.SetBytes(msg, "content", system)
+		out, _ = sjson.SetRawBytes(out, "messages.-1", msg)
+	}
+	msg := []byte(`{"role":"user","content":""}`)
+	msg = setOllamaContent(msg, root.Get("prompt").String(), root.Get("images"))
+	out, _ = sjson.SetRawBytes(out, "messages.-1", msg)
+	return out
+}
+
+func appendChatMessages(out []byte, messages gjson.Result) []byte {
+	// Ollama tool calls carry no ids, so assign stable ones and hand them to the
+	// tool results that follow in order, preferring a match on tool_name.
+	type pendingCall struct {
+		id   string
+		name string
+	}
+	var pending []pendingCall
+
+	messages.ForEach(func(msgIndex, message gjson.Result) bool {
+		role := message.Get("role").String()
+		content := message.Get("content").String()
+		switch role {
+		case "assistant":
+			msg := []byte(`{"role":"assistant","content":""}`)
+			msg, _ = sjson.SetBytes(msg, "content", content)
+			if thinking := message.Get("thinking").String(); thinking != "" {
+				msg, _ = sjson.SetBytes(msg, "reasoning_content", thinking)
+			}
+			pending = pending[:0]
+			message.Get("tool_calls").ForEach(func(callIndex, call gjson.Result) bool {
+				id := call.Get("id").String()
+				if id == "" {
+					id = fmt.Sprintf("call_%d_%d", msgIndex.Int(), callIndex.Int())
+				}
+				name := call.Get("function.name").String()
+				item := []byte(`{"id":"","type":"function","function":{"name":"","arguments":""}}`)
+				item, _ = sjson.SetBytes(item, "id", id)
+				item, _ = sjson.SetBytes(item, "function.name", name)
+				item, _ = sjson.SetBytes(item, "function.arguments", ollamaArgumentsToString(call.Get("function.arguments")))
+				msg, _ = sjson.SetRawBytes(msg, "tool_calls.-1", item)
+				pending = append(pending, pendingCall{id: id, name: name})
+				return true
+			})
+			out, _ = sjson.SetRawBytes(out, "messages.-1", msg)
+		case "tool":
+			id := message.Get("tool_call_id").String()
+			if id == "" {
+				name := message.Get("tool_name").String()
+				match := -1
+				for i, call := range pending {
+					if name != "" && call.name == name {
+						match = i
+						break
+					}
+				}
+				if match < 0 && len(pending) > 0 {
+					match = 0
+				}
+				if match >= 0 {
+					id = pending[match].id
+					pending = append(pending[:match], pending[match+1:]...)
+				}
+			}
+			msg := []byte(`{"role":"tool","tool_call_id":"","content":""}`)
+			msg, _ = sjson.SetBytes(msg, "tool_call_id", id)
+			msg, _ = sjson.SetBytes(msg, "content", content)
+			out, _ = sjson.SetRawBytes(out, "messages.-1", msg)
+		default:
+			if role == "" {
+				role = "user"
+			}
+			msg := []byte(`{"role":"","content":""}`)
+			msg, _ = sjson.SetBytes(msg, "role", role)
+			msg = setOllamaContent(msg, content, message.Get("images"))
+			out, _ = sjson.SetRawBytes(out, "messages.-1", msg)
+		}
+		return true
+	})
+	return out
+}
+
+// setOllamaContent writes plain string content, or a multi-part array when base64 images are attached.
+func setOllamaContent(msg []byte, text string, images gjson.Result) []byte {
+	if !images.IsArray() || len(images.Array()) == 0 {
+		msg, _ = sjson.SetBytes(msg, "content", text)
+		return msg
+	}
+	msg, _ = sjson.SetRawBytes(msg, "content", []byte(`[]`))
+	if text != "" {
+		part := []byte(`{"type":"text","text":""}`)
+		part, _ = sjson.SetBytes(part, "text", text)
+		msg, _ = sjson.SetRawBytes(msg, "content.-1", part)
+	}
+	images.ForEach(func(_, image gjson.Result) bool {
+		data := strings.TrimSpace(image.String())
+		if data == "" {
+			return true
+		}
+		if !strings.HasPrefix(data, "data:") {
+			data = "data:" + ollamaImageMimeType(data) + ";base64," + data
+		}
+		part := []byte(`{"type":"image_url","image_url":{"url":""}}`)
+		part, _ = sjson.SetBytes(part, "image_url.url", data)
+		msg, _ = sjson.SetRawBytes(msg, "content.-1", part)
+		return true
+	})
+	return msg
+}
+
+// ollamaImageMimeType sniffs the media type of a base64 image, defaulting to PNG.
+func ollamaImageMimeType(data string) string {
+	head := data
+	if len(head) > 64 {
+		head = head[:64]
+	}
+	head = head[:len(head)/4*4]
+	decoded, errDecode := base64.StdEncoding.DecodeString(head)
+	if errDecode != nil || len(decoded) == 0 {
+		return "image/png"
+	}
+	mimeType := http.DetectContentType(decoded)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "image/png"
+	}
+	return mimeType
+}
+
+// ollamaArgumentsToString renders Ollama's object-valued tool arguments as the JSON string OpenAI expects.
+func ollamaArgumentsToString(arguments gjson.Result) string {
+	switch {
+	case !arguments.Exists():
+		return "{}"
+	case arguments.Type == gjson.String:
+		return arguments.String()
+	default:
+		return arguments.Raw
+	}
+}
+
+func applyOllamaOptions(out []byte, options gjson.Result) []byte {
+	if !options.IsObject() {
+		return out
+	}
+	for _, field := range [][2]string{
+		{"temperature", "temperature"},
+		{"top_p", "top_p"},
+		{"top_k", "top_k"},
+		{"seed", "seed"},
+		{"presence_penalty", "presence_penalty"},
+		{"frequency_penalty", "frequency_penalty"},
+	} {
+		if value := options.Get(field[0]); value.Exists() {
+			out, _ = sjson.SetRawBytes(out, field[1], []byte(value.Raw))
+		}
+	}
+	// num_predict of -1 (infinite) or -2 (fill context) has no OpenAI equivalent.
+	if numPredict := options.Get("num_predict"); numPredict.Exists() && numPredict.Int() > 0 {
+		out, _ = sjson.SetBytes(out, "max_tokens", numPredict.Int())
+	}
+	if stop := options.Get("stop"); stop.Exists() {
+		out, _ = sjson.SetRawBytes(out, "stop", []byte(stop.Raw))
+	}
+	return out
+}
+
+func applyOllamaFormat(out []byte, format gjson.Result) []byte {
+	switch {
+	case format.Type == gjson.String && format.String() == "json":
+		out, _ = sjson.SetRawBytes(out, "response_format", []byte(`{"type":"json_object"}`))
+	case format.IsObject():
+		responseFormat := []byte(`{"type":"json_schema","json_schema":{"name":"response","schema":{}}}`)
+		responseFormat, _ = sjson.SetRawBytes(responseFormat, "json_schema.schema", []byte(format.Raw))
+		out, _ = sjson.SetRawBytes(out, "response_format", responseFormat)
+	}
+	return out
+}
+
+// applyOllamaThink maps Ollama's think flag (a bool or an effort level) to reasoning_effort.
+func applyOllamaThink(out []byte, think gjson.Result) []byte {
+	switch think.Type {
+	case gjson.True:
+		out, _ = sjson.SetBytes(out, "reasoning_effort", "auto")
+	case gjson.False:
+		out, _ = sjson.SetBytes(out, "reasoning_effort", "none")
+	case gjson.String:
+		if level := strings.ToLower(strings.TrimSpace(think.String())); level != "" {
+			out, _ = sjson.SetBytes(out, "reasoning_effort", level)
+		}
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package ollama
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOllamaRequestToOpenAI_MapsImagesToolCallsAndOptions(t *testing.T) {
+	inputJSON := []byte(`{
+		"model": "llama3",
+		"messages": [
+			{"role": "user", "content": "what is in this picture?", "images": ["iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk"]},
+			{"role": "assistant", "content": "", "tool_calls": [
+				{"function": {"name": "lookup", "arguments": {"query": "cat"}}},
+				{"function": {"name": "weather", "arguments": {"city": "Paris"}}}
+			]},
+			{"role": "tool", "tool_name": "weather", "content": "sunny"},
+			{"role": "tool", "content": "a cat"}
+		],
+		"tools": [{"type": "function", "function": {"name": "lookup", "parameters": {"type": "object"}}}],
+		"options": {"temperature": 0.2, "num_predict": 128, "stop": ["\n\n"]},
+		"format": "json",
+		"think": true
+	}`)
+
+	out := ConvertOllamaRequestToOpenAI("llama3", inputJSON, true)
+	if got := gjson.GetBytes(out, "messages.0.content.1.image_url.url").String(); got != "data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk" {
+		t.Fatalf("image url = %q, output: %s", got, out)
+	}
+	if got := gjson.GetBytes(out, "messages.1.tool_calls.0.function.arguments").String(); got != `{"query": "cat"}` {
+		t.Fatalf("tool call arguments = %q, want the JSON object as a string", got)
+	}
+	lookupID := gjson.GetBytes(out, "messages.1.tool_calls.0.id").String()
+	weatherID := gjson.GetBytes(out, "messages.1.tool_calls.1.id").String()
+	if lookupID == "" || lookupID == weatherID {
+		t.Fatalf("tool call ids = %q, %q; want distinct ids", lookupID, weatherID)
+	}
+	if got := gjson.GetBytes(out, "messages.2.tool_call_id").String(); got != weatherID {
+		t.Fatalf("named tool result id = %q, want %q", got, weatherID)
+	}
+	if got := gjson.GetBytes(out, "messages.3.tool_call_id").String(); got != lookupID {
+		t.Fatalf("unnamed tool result id = %q, want %q", got, lookupID)
+	}
+	if got := gjson.GetBytes(out, "max_tokens").Int(); got != 128 {
+		t.Fatalf("max_tokens = %d, want 128", got)
+	}
+	if got := gjson.GetBytes(out, "response_format.type").String(); got != "json_object" {
+		t.Fatalf("response_format.type = %q, want json_object", got)
+	}
+	if got := gjson.GetBytes(out, "reasoning_effort").String(); got != "auto" {
+		t.Fatalf("reasoning_effort = %q, want auto", got)
+	}
+	if !gjson.GetBytes(out, "stream_options.include_usage").Bool() {
+		t.Fatalf("stream_options.include_usage not set: %s", out)
+	}
+	if got := gjson.GetBytes(out, "tools.0.function.name").String(); got != "lookup" {
+		t.Fatalf("tools.0.function.name = %q, want lookup", got)
+	}
+}
+
+func TestConvertOllamaRequestToOpenAI_GenerateUsesSystemAndPrompt(t *testing.T) {
+	out := ConvertOllamaRequestToOpenAI("llama3", []byte(`{"model":"llama3","system":"be brief","prompt":"hi","stream":false}`), false)
+	if got := gjson.GetBytes(out, "messages.0.role").String(); got != "system" {
+		t.Fatalf("messages.0.role = %q, want system", got)
+	}
+	if got := gjson.GetBytes(out, "messages.1.content").String(); got != "hi" {
+		t.Fatalf("messages.1.content = %q, want hi", got)
+	}
+	if gjson.GetBytes(out, "stream").Exists() {
+		t.Fatalf("non-stream request carries stream: %s", out)
+	}
+}
@@ -0,0 +1,228 @@
+// Package ollama provides response translation functionality for OpenAI to the Ollama native API.
+// Streaming chunks become Ollama NDJSON objects: content and thinking deltas are forwarded as
+// they arrive, tool call fragments are accumulated and emitted whole, and a final done object
+// carries the finish reason and token counts.
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertOpenAIResponseToOllamaParams holds the streaming conversion state.
+type ConvertOpenAIResponseToOllamaParams struct {
+	Model            string
+	ToolCalls        map[int]*ToolCallAccumulator
+	DoneReason       string
+	Finished         bool
+	Done             bool
+	PromptTokens     int64
+	CompletionTokens int64
+	HasUsage         bool
+}
+
+// ToolCallAccumulator holds the fragments of one streamed tool call.
+type ToolCallAccumulator struct {
+	Name      string
+	Arguments strings.Builder
+}
+
+// ConvertOpenAIResponseToOllama converts one OpenAI Chat Completions stream chunk into zero or
+// more Ollama stream objects. The "[DONE]" marker flushes the final done object when the
+// upstream did not report usage after its finish reason.
+func ConvertOpenAIResponseToOllama(_ context.Context, modelName string, originalRequestRawJSON, _, rawJSON []byte, param *any) [][]byte {
+	if *param == nil {
+		*param = &ConvertOpenAIResponseToOllamaParams{Model: modelName, ToolCalls: make(map[int]*ToolCallAccumulator)}
+	}
+	state := (*param).(*ConvertOpenAIResponseToOllamaParams)
+	generate := IsGenerateRequest(originalRequestRawJSON)
+
+	if bytes.HasPrefix(rawJSON, []byte("data:")) {
+		rawJSON = bytes.TrimSpace(rawJSON[5:])
+	}
+	if bytes.Equal(bytes.TrimSpace(rawJSON), []byte("[DONE]")) {
+		if state.Done {
+			return nil
+		}
+		return [][]byte{state.doneObject(generate)}
+	}
+	if state.Done {
+		return nil
+	}
+
+	root := gjson.ParseBytes(rawJSON)
+	if model := root.Get("model").String(); model != "" && state.Model == "" {
+		state.Model = model
+	}
+	if usage := root.Get("usage"); usage.Exists() && usage.Type != gjson.Null {
+		state.PromptTokens = usage.Get("prompt_tokens").Int()
+		state.CompletionTokens = usage.Get("completion_tokens").Int()
+		state.HasUsage = true
+	}
+
+	var outputs [][]byte
+	choice := root.Get("choices.0")
+	if delta := choice.Get("delta"); delta.Exists() {
+		content := delta.Get("content").String()
+		thinking := delta.Get("reasoning_content").String()
+		if content != "" || thinking != "" {
+			outputs = append(outputs, state.deltaObject(generate, content, thinking, nil))
+		}
+		delta.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+			index := int(call.Get("index").Int())
+			acc, ok := state.ToolCalls[index]
+			if !ok {
+				acc = &ToolCallAccumulator{}
+				state.ToolCalls[index] = acc
+			}
+			if name := call.Get("function.name").String(); name != "" {
+				acc.Name = name
+			}
+			acc.Arguments.WriteString(call.Get("function.arguments").String())
+			return true
+		})
+	}
+	if finishReason := choice.Get("finish_reason").String(); finishReason != "" {
+		state.Finished = true
+		state.DoneReason = ollamaDoneReason(finishReason)
+		if !generate && len(state.ToolCalls) > 0 {
+			outputs = append(outputs, state.deltaObject(false, "", "", state.toolCallsJSON()))
+		}
+	}
+	if state.Finished && state.HasUsage {
+		outputs = append(outputs, state.doneObject(generate))
+	}
+	return outputs
+}
+
+// ConvertOpenAIResponseToOllamaNonStream converts a complete OpenAI Chat Completions response
+// into a single Ollama chat or generate response.
+func ConvertOpenAIResponseToOllamaNonStream(_ context.Context, modelName string, originalRequestRawJSON, _, rawJSON []byte, _ *any) []byte {
+	root := gjson.ParseBytes(rawJSON)
+	generate := IsGenerateRequest(originalRequestRawJSON)
+	model := root.Get("model").String()
+	if model == "" {
+		model = modelName
+	}
+
+	out := ollamaBaseObject(model, generate)
+	message := root.Get("choices.0.message")
+	content := message.Get("content").String()
+	thinking := message.Get("reasoning_content").String()
+	if generate {
+		out, _ = sjson.SetBytes(out, "response", content)
+		if thinking != "" {
+			out, _ = sjson.SetBytes(out, "thinking", thinking)
+		}
+	} else {
+		out, _ = sjson.SetBytes(out, "message.content", content)
+		if thinking != "" {
+			out, _ = sjson.SetBytes(out, "message.thinking", thinking)
+		}
+		message.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+			item := ollamaToolCall(call.Get("function.name").String(), call.Get("function.arguments").String())
+			out, _ = sjson.SetRawBytes(out, "message.tool_calls.-1", item)
+			return true
+		})
+	}
+	out, _ = sjson.SetBytes(out, "done", true)
+	out, _ = sjson.SetBytes(out, "done_reason", ollamaDoneReason(root.Get("choices.0.finish_reason").String()))
+	if usage := root.Get("usage"); usage.Exists() {
+		out, _ = sjson.SetBytes(out, "prompt_eval_count", usage.Get("prompt_tokens").Int())
+		out, _ = sjson.SetBytes(out, "eval_count", usage.Get("completion_tokens").Int())
+	}
+	return out
+}
+
+func (s *ConvertOpenAIResponseToOllamaParams) deltaObject(generate bool, content, thinking string, toolCalls []byte) []byte {
+	out := ollamaBaseObject(s.Model, generate)
+	if generate {
+		out, _ = sjson.SetBytes(out, "response", content)
+		if thinking != "" {
+			out, _ = sjson.SetBytes(out, "thinking", thinking)
+		}
+	} else {
+		out, _ = sjson.SetBytes(out, "message.content", content)
+		if thinking != "" {
+			out, _ = sjson.SetBytes(out, "message.thinking", thinking)
+		}
+		if len(toolCalls) > 0 {
+			out, _ = sjson.SetRawBytes(out, "message.tool_calls", toolCalls)
+		}
+	}
+	out, _ = sjson.SetBytes(out, "done", false)
+	return out
+}
+
+func (s *ConvertOpenAIResponseToOllamaParams) doneObject(generate bool) []byte {
+	s.Done = true
+	out := ollamaBaseObject(s.Model, generate)
+	if generate {
+		out, _ = sjson.SetBytes(out, "response", "")
+	}
+	doneReason := s.DoneReason
+	if doneReason == "" {
+		doneReason = "stop"
+	}
+	out, _ = sjson.SetBytes(out, "done", true)
+	out, _ = sjson.SetBytes(out, "done_reason", doneReason)
+	if s.HasUsage {
+		out, _ = sjson.SetBytes(out, "prompt_eval_count", s.PromptTokens)
+		out, _ = sjson.SetBytes(out, "eval_count", s.CompletionTokens)
+	}
+	return out
+}
+
+func (s *ConvertOpenAIResponseToOllamaParams) toolCallsJSON() []byte {
+	indexes := make([]int, 0, len(s.ToolCalls))
+	for index := range s.ToolCalls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	out := []byte(`[]`)
+	for _, index := range indexes {
+		acc := s.ToolCalls[index]
+		out, _ = sjson.SetRawBytes(out, "-1", ollamaToolCall(acc.Name, acc.Arguments.String()))
+	}
+	return out
+}
+
+// ollamaBaseObject returns the fields shared by every Ollama chat or generate response object.
+func ollamaBaseObject(model string, generate bool) []byte {
+	out := []byte(`{"model":"","created_at":""}`)
+	if !generate {
+		out = []byte(`{"model":"","created_at":"","message":{"role":"assistant","content":""}}`)
+	}
+	out, _ = sjson.SetBytes(out, "model", model)
+	out, _ = sjson.SetBytes(out, "created_at", time.Now().UTC().Format(time.RFC3339Nano))
+	return out
+}
+
+// ollamaToolCall builds an Ollama tool call, whose arguments are a JSON object rather than a string.
+func ollamaToolCall(name, arguments string) []byte {
+	item := []byte(`{"function":{"name":"","arguments":{}}}`)
+	item, _ = sjson.SetBytes(item, "function.name", name)
+	arguments = strings.TrimSpace(arguments)
+	if arguments != "" && gjson.Valid(arguments) && gjson.Parse(arguments).IsObject() {
+		item, _ = sjson.SetRawBytes(item, "function.arguments", []byte(arguments))
+	}
+	return item
+}
+
+// ollamaDoneReason maps an OpenAI finish_reason onto Ollama's done_reason vocabulary.
+func ollamaDoneReason(finishReason string) string {
+	switch finishReason {
+	case "length":
+		return "length"
+	case "", "stop", "tool_calls", "function_call":
+		return "stop"
+	default:
+		return finishReason
+	}
+}
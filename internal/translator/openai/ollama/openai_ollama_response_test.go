@@ -0,0 +1,51 @@
+package ollama
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOpenAIResponseToOllama_StreamsContentToolCallsAndDone(t *testing.T) {
+	request := []byte(`{"model":"llama3","messages":[{"role":"user","content":"hi"}]}`)
+	var param any
+	var outputs [][]byte
+	for _, chunk := range []string{
+		`{"model":"gpt-x","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}`,
+		`{"model":"gpt-x","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"lookup","arguments":"{\"q\":"}}]}}]}`,
+		`{"model":"gpt-x","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"cat\"}"}}]}}]}`,
+		`{"model":"gpt-x","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		`{"model":"gpt-x","choices":[],"usage":{"prompt_tokens":7,"completion_tokens":3}}`,
+		`[DONE]`,
+	} {
+		outputs = append(outputs, ConvertOpenAIResponseToOllama(context.Background(), "llama3", request, nil, []byte(chunk), &param)...)
+	}
+
+	if len(outputs) != 3 {
+		t.Fatalf("got %d outputs, want content, tool calls and done: %q", len(outputs), outputs)
+	}
+	if got := gjson.GetBytes(outputs[0], "message.content").String(); got != "Hel" {
+		t.Fatalf("first content = %q, want Hel", got)
+	}
+	if got := gjson.GetBytes(outputs[1], "message.tool_calls.0.function.arguments.q").String(); got != "cat" {
+		t.Fatalf("tool call = %s, want object arguments", outputs[1])
+	}
+	done := gjson.ParseBytes(outputs[2])
+	if !done.Get("done").Bool() || done.Get("done_reason").String() != "stop" || done.Get("prompt_eval_count").Int() != 7 || done.Get("eval_count").Int() != 3 {
+		t.Fatalf("done object = %s", outputs[2])
+	}
+}
+
+func TestConvertOpenAIResponseToOllamaNonStream_Generate(t *testing.T) {
+	request := []byte(`{"model":"llama3","prompt":"hi"}`)
+	response := []byte(`{"model":"gpt-x","choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"length"}],"usage":{"prompt_tokens":2,"completion_tokens":1}}`)
+
+	out := gjson.ParseBytes(ConvertOpenAIResponseToOllamaNonStream(context.Background(), "llama3", request, nil, response, nil))
+	if out.Get("response").String() != "hello" || out.Get("message").Exists() {
+		t.Fatalf("generate response = %s", out.Raw)
+	}
+	if out.Get("done_reason").String() != "length" || out.Get("eval_count").Int() != 1 {
+		t.Fatalf("generate response = %s", out.Raw)
+	}
+}
@@ -33,6 +33,7 @@ type App struct {
 	authInput      textinput.Model
 	authError      string
 	authConnecting bool
+	identity       ManagementIdentity
 
 	dashboard dashboardModel
 	config    configTabModel
@@ -52,8 +53,9 @@ type App struct {
 }
 
 type authConnectMsg struct {
-	cfg map[string]any
-	err error
+	cfg      map[string]any
+	identity ManagementIdentity
+	err      error
 }
 
 // NewApp creates the root TUI application model.
@@ -136,6 +138,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		a.authError = ""
 		a.authenticated = true
+		a.identity = msg.identity
 		a.logsEnabled = a.standalone || isLogsEnabledFromConfig(msg.cfg)
 		a.refreshTabs()
 		a.initialized = [6]bool{}
@@ -401,6 +404,12 @@ func (a App) renderTabBar() string {
 
 func (a App) renderStatusBar() string {
 	left := strings.TrimRight(T("status_left"), " ")
+	if a.identity.Role != "" {
+		left += fmt.Sprintf(T("status_role"), a.identity.Role)
+		if a.identity.SecretsRedacted {
+			left += T("status_role_redacted")
+		}
+	}
 	right := strings.TrimRight(T("status_right"), " ")
 
 	width := a.width
@@ -479,7 +488,12 @@ func (a App) connectWithPassword(password string) tea.Cmd {
 	return func() tea.Msg {
 		a.client.SetSecretKey(password)
 		cfg, errGetConfig := a.client.GetConfig()
-		return authConnectMsg{cfg: cfg, err: errGetConfig}
+		if errGetConfig != nil {
+			return authConnectMsg{err: errGetConfig}
+		}
+		// Servers without scoped management keys do not serve whoami; the role is then omitted.
+		identity, _ := a.client.GetWhoAmI()
+		return authConnectMsg{cfg: cfg, identity: identity}
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -307,10 +308,38 @@ func (m authTabModel) renderDetail(f map[string]any) string {
 		sb.WriteString("\n")
 	}
 
+	for _, window := range quotaWindowLines(f) {
+		line := fmt.Sprintf("    │ %s %s",
+			labelStyle.Render(fmt.Sprintf("%-12s:", "Quota "+window.name)),
+			valueStyle.Render(window.value))
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
 	sb.WriteString("    └─────────────────────────────────────────────\n")
 	return sb.String()
 }
 
+// quotaWindowLines formats the upstream quota windows reported for an auth file.
+func quotaWindowLines(f map[string]any) []struct{ name, value string } {
+	windows, _ := f["quota_windows"].([]any)
+	lines := make([]struct{ name, value string }, 0, len(windows))
+	for _, raw := range windows {
+		window, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		name := getAnyString(window, "name")
+		used, _ := window["used_fraction"].(float64)
+		value := fmt.Sprintf("%.0f%% used", used*100)
+		if resetAt, errParse := time.Parse(time.RFC3339, getAnyString(window, "reset_at")); errParse == nil && resetAt.After(time.Now()) {
+			value += ", resets " + resetAt.Local().Format("2006-01-02 15:04")
+		}
+		lines = append(lines, struct{ name, value string }{name, value})
+	}
+	return lines
+}
+
 // getAnyString converts any value to its string representation.
 func getAnyString(m map[string]any, key string) string {
 	v, ok := m[key]
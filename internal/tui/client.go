@@ -120,6 +120,28 @@ func (c *Client) postJSON(path string, body any) error {
 	return nil
 }
 
+// ManagementIdentity describes the management key the client authenticates with.
+type ManagementIdentity struct {
+	Name            string
+	Role            string
+	SecretsRedacted bool
+}
+
+// GetWhoAmI fetches the name and role of the current management key.
+func (c *Client) GetWhoAmI() (ManagementIdentity, error) {
+	result, err := c.getJSON("/v0/management/whoami")
+	if err != nil {
+		return ManagementIdentity{}, err
+	}
+	identity := ManagementIdentity{}
+	identity.Name, _ = result["name"].(string)
+	identity.Role, _ = result["role"].(string)
+	if capabilities, ok := result["capabilities"].(map[string]any); ok {
+		identity.SecretsRedacted, _ = capabilities["secrets-redacted"].(bool)
+	}
+	return identity, nil
+}
+
 // GetConfig fetches the parsed config.
 func (c *Client) GetConfig() (map[string]any, error) {
 	return c.getJSON("/v0/management/config")
@@ -140,6 +162,26 @@ func (c *Client) PutConfigYAML(yamlContent string) error {
 	return err
 }
 
+// GetUsageSummary queries the usage ledger for the last 24 hours grouped by model.
+// The boolean is false when the ledger is disabled on the server.
+func (c *Client) GetUsageSummary() (map[string]any, bool, error) {
+	data, code, err := c.doRequest("GET", "/v0/management/usage/query?since=24h&group-by=model&limit=10", nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if code == http.StatusServiceUnavailable || code == http.StatusNotFound {
+		return nil, false, nil
+	}
+	if code >= 400 {
+		return nil, false, fmt.Errorf("HTTP %d: %s", code, strings.TrimSpace(string(data)))
+	}
+	var result map[string]any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, false, err
+	}
+	return result, true, nil
+}
+
 // GetAuthFiles lists auth credential files.
 // API returns {"files": [...]}.
 func (c *Client) GetAuthFiles() ([]map[string]any, error) {
@@ -235,23 +277,25 @@ func (c *Client) GetLogs(after int64, limit int) ([]string, int64, error) {
 }
 
 // GetAPIKeys fetches the list of API keys.
-// API returns {"api-keys": [...]}.
+// API returns {"api-keys": [...]} where each item is a key string or a key object.
 func (c *Client) GetAPIKeys() ([]string, error) {
 	wrapper, err := c.getJSON("/v0/management/api-keys")
 	if err != nil {
 		return nil, err
 	}
-	arr, ok := wrapper["api-keys"]
+	arr, ok := wrapper["api-keys"].([]any)
 	if !ok {
 		return nil, nil
 	}
-	raw, err := json.Marshal(arr)
-	if err != nil {
-		return nil, err
-	}
-	var result []string
-	if err := json.Unmarshal(raw, &result); err != nil {
-		return nil, err
+	result := make([]string, 0, len(arr))
+	for _, item := range arr {
+		switch v := item.(type) {
+		case string:
+			result = append(result, v)
+		case map[string]any:
+			key, _ := v["key"].(string)
+			result = append(result, key)
+		}
 	}
 	return result, nil
 }
@@ -24,12 +24,16 @@ type dashboardModel struct {
 	lastConfig    map[string]any
 	lastAuthFiles []map[string]any
 	lastAPIKeys   []string
+	lastUsage     map[string]any
+	usageEnabled  bool
 }
 
 type dashboardDataMsg struct {
 	config    map[string]any
 	authFiles []map[string]any
 	apiKeys   []string
+	usage     map[string]any
+	usageOn   bool
 	err       error
 }
 
@@ -47,6 +51,11 @@ func (m dashboardModel) fetchData() tea.Msg {
 	cfg, cfgErr := m.client.GetConfig()
 	authFiles, authErr := m.client.GetAuthFiles()
 	apiKeys, keysErr := m.client.GetAPIKeys()
+	usage, usageOn, usageErr := m.client.GetUsageSummary()
+	if usageErr != nil {
+		// The usage panel is optional; a failing query only hides it.
+		usage, usageOn = nil, false
+	}
 
 	var err error
 	for _, e := range []error{cfgErr, authErr, keysErr} {
@@ -55,7 +64,7 @@ func (m dashboardModel) fetchData() tea.Msg {
 			break
 		}
 	}
-	return dashboardDataMsg{config: cfg, authFiles: authFiles, apiKeys: apiKeys, err: err}
+	return dashboardDataMsg{config: cfg, authFiles: authFiles, apiKeys: apiKeys, usage: usage, usageOn: usageOn, err: err}
 }
 
 func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
@@ -77,6 +86,8 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 			m.lastConfig = msg.config
 			m.lastAuthFiles = msg.authFiles
 			m.lastAPIKeys = msg.apiKeys
+			m.lastUsage = msg.usage
+			m.usageEnabled = msg.usageOn
 
 			m.content = m.renderDashboard(msg.config, msg.authFiles, msg.apiKeys)
 		}
@@ -172,6 +183,9 @@ func (m dashboardModel) renderDashboard(cfg map[string]any, authFiles []map[stri
 	sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, card1, " ", card2))
 	sb.WriteString("\n\n")
 
+	// ━━━ Usage (last 24h) ━━━
+	m.renderUsage(&sb, cardStyle)
+
 	// ━━━ Current Config ━━━
 	sb.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorHighlight).Render(T("current_config")))
 	sb.WriteString("\n")
@@ -230,6 +244,55 @@ func (m dashboardModel) renderDashboard(cfg map[string]any, authFiles []map[stri
 	return sb.String()
 }
 
+// renderUsage draws the usage ledger panel from the /usage/query response.
+func (m dashboardModel) renderUsage(sb *strings.Builder, cardStyle lipgloss.Style) {
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorHighlight).Render(T("usage_24h")))
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("─", minInt(m.width, 60)))
+	sb.WriteString("\n")
+	if !m.usageEnabled || m.lastUsage == nil {
+		sb.WriteString(helpStyle.Render("  " + T("usage_disabled")))
+		sb.WriteString("\n\n")
+		return
+	}
+
+	totals, _ := m.lastUsage["totals"].(map[string]any)
+	requests := int64(getFloat(totals, "requests"))
+	failures := int64(getFloat(totals, "failures"))
+	tokens := int64(getFloat(totals, "total_tokens"))
+	requestsCard := cardStyle.Render(fmt.Sprintf(
+		"%s\n%s",
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("111")).Render(fmt.Sprintf("📈 %s", formatLargeNumber(requests))),
+		lipgloss.NewStyle().Foreground(colorMuted).Render(fmt.Sprintf("%s (%s %s)", T("total_requests"), formatLargeNumber(failures), T("failure_label"))),
+	))
+	tokensCard := cardStyle.Render(fmt.Sprintf(
+		"%s\n%s",
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Render(fmt.Sprintf("🔤 %s", formatLargeNumber(tokens))),
+		lipgloss.NewStyle().Foreground(colorMuted).Render(T("total_tokens")),
+	))
+	sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, requestsCard, " ", tokensCard))
+	sb.WriteString("\n")
+
+	rows, _ := m.lastUsage["rows"].([]any)
+	if len(rows) == 0 {
+		sb.WriteString("\n")
+		return
+	}
+	sb.WriteString(tableHeaderStyle.Render(fmt.Sprintf("  %-36s %10s %10s", T("model"), T("requests"), T("tokens"))))
+	sb.WriteString("\n")
+	for _, item := range rows {
+		row, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  %-36s %10s %10s\n",
+			truncate(getString(row, "model"), 36),
+			formatLargeNumber(int64(getFloat(row, "requests"))),
+			formatLargeNumber(int64(getFloat(row, "total_tokens")))))
+	}
+	sb.WriteString("\n")
+}
+
 func formatKV(key, value string) string {
 	return fmt.Sprintf("  %s %s\n", labelStyle.Render(key+":"), valueStyle.Render(value))
 }
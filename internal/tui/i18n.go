@@ -85,6 +85,8 @@ var zhStrings = map[string]string{
 	// ── Status bar ──
 	"status_left":                 " CLIProxyAPI 管理终端",
 	"status_right":                "Tab/Shift+Tab: 切换 • L: 语言 • q/Ctrl+C: 退出 ",
+	"status_role":                 " • 角色: %s",
+	"status_role_redacted":        "（密钥已隐藏）",
 	"initializing_tui":            "正在初始化...",
 	"auth_gate_title":             "🔐 连接管理 API",
 	"auth_gate_help":              " 请输入管理密码并按 Enter 连接",
@@ -113,6 +115,8 @@ var zhStrings = map[string]string{
 	"proxy_url":        "代理 URL",
 	"routing_strategy": "路由策略",
 	"model_stats":      "模型统计",
+	"usage_24h":        "用量（最近 24 小时）",
+	"usage_disabled":   "用量账本未启用（在配置中设置 usage-ledger.enable）",
 	"model":            "模型",
 	"requests":         "请求数",
 	"tokens":           "Tokens",
@@ -240,6 +244,8 @@ var enStrings = map[string]string{
 	// ── Status bar ──
 	"status_left":                 " CLIProxyAPI Management TUI",
 	"status_right":                "Tab/Shift+Tab: switch • L: lang • q/Ctrl+C: quit ",
+	"status_role":                 " • role: %s",
+	"status_role_redacted":        " (secrets redacted)",
 	"initializing_tui":            "Initializing...",
 	"auth_gate_title":             "🔐 Connect Management API",
 	"auth_gate_help":              " Enter management password and press Enter to connect",
@@ -268,6 +274,8 @@ var enStrings = map[string]string{
 	"proxy_url":        "Proxy URL",
 	"routing_strategy": "Routing Strategy",
 	"model_stats":      "Model Stats",
+	"usage_24h":        "Usage (last 24h)",
+	"usage_disabled":   "Usage ledger disabled (set usage-ledger.enable in config)",
 	"model":            "Model",
 	"requests":         "Requests",
 	"tokens":           "Tokens",
@@ -0,0 +1,290 @@
+package usageledger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	segmentPrefix     = "usage-"
+	segmentSuffix     = ".jsonl"
+	segmentDateLayout = "2006-01-02"
+)
+
+var _ Store = (*FileStore)(nil)
+
+// FileStore keeps ledger entries in one JSONL segment per UTC day.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore returns a store that writes segments under dir. The directory is
+// created on the first append.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Dir returns the segment directory.
+func (s *FileStore) Dir() string {
+	if s == nil {
+		return ""
+	}
+	return s.dir
+}
+
+// Append writes entries to the segments of their days.
+func (s *FileStore) Append(_ context.Context, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if errMkdir := os.MkdirAll(s.dir, 0o700); errMkdir != nil {
+		return fmt.Errorf("usage ledger: create directory: %w", errMkdir)
+	}
+	byDay := make(map[time.Time][]Entry)
+	days := make([]time.Time, 0, 1)
+	for _, entry := range entries {
+		day := startOfDay(entry.Timestamp)
+		if _, ok := byDay[day]; !ok {
+			days = append(days, day)
+		}
+		byDay[day] = append(byDay[day], entry)
+	}
+	for _, day := range days {
+		if errWrite := s.appendSegment(day, byDay[day]); errWrite != nil {
+			return errWrite
+		}
+	}
+	return nil
+}
+
+// Scan reads every segment overlapping [from, to) in day order.
+func (s *FileStore) Scan(ctx context.Context, from, to time.Time, fn func(Entry) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days, errDays := s.segmentDays()
+	if errDays != nil {
+		return errDays
+	}
+	for _, day := range days {
+		if !to.IsZero() && !day.Before(to) {
+			break
+		}
+		if !from.IsZero() && !day.AddDate(0, 0, 1).After(from) {
+			continue
+		}
+		if ctx != nil {
+			if errCtx := ctx.Err(); errCtx != nil {
+				return errCtx
+			}
+		}
+		errRead := s.readSegment(day, func(entry Entry) error {
+			if !from.IsZero() && entry.Timestamp.Before(from) {
+				return nil
+			}
+			if !to.IsZero() && !entry.Timestamp.Before(to) {
+				return nil
+			}
+			return fn(entry)
+		})
+		if errRead != nil {
+			return errRead
+		}
+	}
+	return nil
+}
+
+// Oldest returns the midnight of the oldest segment.
+func (s *FileStore) Oldest(context.Context) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days, errDays := s.segmentDays()
+	if errDays != nil || len(days) == 0 {
+		return time.Time{}, false, errDays
+	}
+	return days[0], true, nil
+}
+
+// DeleteBefore removes segments that end before cutoff and trims the segment containing it.
+func (s *FileStore) DeleteBefore(_ context.Context, cutoff time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days, errDays := s.segmentDays()
+	if errDays != nil {
+		return errDays
+	}
+	cutoffDay := startOfDay(cutoff)
+	for _, day := range days {
+		switch {
+		case day.Before(cutoffDay):
+			if errRemove := os.Remove(s.segmentPath(day)); errRemove != nil && !errors.Is(errRemove, fs.ErrNotExist) {
+				return fmt.Errorf("usage ledger: remove segment: %w", errRemove)
+			}
+		case day.Equal(cutoffDay) && !cutoff.Equal(cutoffDay):
+			kept := make([]Entry, 0)
+			errRead := s.readSegment(day, func(entry Entry) error {
+				if !entry.Timestamp.Before(cutoff) {
+					kept = append(kept, entry)
+				}
+				return nil
+			})
+			if errRead != nil {
+				return errRead
+			}
+			if errWrite := s.writeSegment(day, kept); errWrite != nil {
+				return errWrite
+			}
+		}
+	}
+	return nil
+}
+
+// ReplaceDay rewrites the segment of day through a temporary file.
+func (s *FileStore) ReplaceDay(_ context.Context, day time.Time, entries []Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writeSegment(startOfDay(day), entries)
+}
+
+func (s *FileStore) segmentPath(day time.Time) string {
+	return filepath.Join(s.dir, segmentPrefix+day.Format(segmentDateLayout)+segmentSuffix)
+}
+
+func (s *FileStore) segmentDays() ([]time.Time, error) {
+	items, errRead := os.ReadDir(s.dir)
+	if errRead != nil {
+		if errors.Is(errRead, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("usage ledger: list segments: %w", errRead)
+	}
+	days := make([]time.Time, 0, len(items))
+	for _, item := range items {
+		name := item.Name()
+		if item.IsDir() || !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		day, errParse := time.Parse(segmentDateLayout, strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix))
+		if errParse != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days, nil
+}
+
+func (s *FileStore) appendSegment(day time.Time, entries []Entry) (err error) {
+	file, errOpen := os.OpenFile(s.segmentPath(day), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if errOpen != nil {
+		return fmt.Errorf("usage ledger: open segment: %w", errOpen)
+	}
+	defer func() {
+		if errClose := file.Close(); errClose != nil && err == nil {
+			err = fmt.Errorf("usage ledger: close segment: %w", errClose)
+		}
+	}()
+	writer := bufio.NewWriter(file)
+	if errEncode := encodeEntries(writer, entries); errEncode != nil {
+		return errEncode
+	}
+	if errFlush := writer.Flush(); errFlush != nil {
+		return fmt.Errorf("usage ledger: write segment: %w", errFlush)
+	}
+	return nil
+}
+
+func (s *FileStore) writeSegment(day time.Time, entries []Entry) error {
+	path := s.segmentPath(day)
+	if len(entries) == 0 {
+		if errRemove := os.Remove(path); errRemove != nil && !errors.Is(errRemove, fs.ErrNotExist) {
+			return fmt.Errorf("usage ledger: remove segment: %w", errRemove)
+		}
+		return nil
+	}
+	if errMkdir := os.MkdirAll(s.dir, 0o700); errMkdir != nil {
+		return fmt.Errorf("usage ledger: create directory: %w", errMkdir)
+	}
+	tmp, errCreate := os.CreateTemp(s.dir, segmentPrefix+"*.tmp")
+	if errCreate != nil {
+		return fmt.Errorf("usage ledger: create temporary segment: %w", errCreate)
+	}
+	tmpPath := tmp.Name()
+	writer := bufio.NewWriter(tmp)
+	errWrite := encodeEntries(writer, entries)
+	if errWrite == nil {
+		errWrite = writer.Flush()
+	}
+	if errClose := tmp.Close(); errWrite == nil {
+		errWrite = errClose
+	}
+	if errWrite == nil {
+		errWrite = os.Rename(tmpPath, path)
+	}
+	if errWrite != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("usage ledger: rewrite segment: %w", errWrite)
+	}
+	return nil
+}
+
+func (s *FileStore) readSegment(day time.Time, fn func(Entry) error) (err error) {
+	file, errOpen := os.Open(s.segmentPath(day))
+	if errOpen != nil {
+		if errors.Is(errOpen, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("usage ledger: open segment: %w", errOpen)
+	}
+	defer func() {
+		if errClose := file.Close(); errClose != nil && err == nil {
+			err = fmt.Errorf("usage ledger: close segment: %w", errClose)
+		}
+	}()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		// A torn trailing line from a crash is skipped rather than failing the whole segment.
+		if errUnmarshal := json.Unmarshal(line, &entry); errUnmarshal != nil {
+			continue
+		}
+		if errFn := fn(entry); errFn != nil {
+			return errFn
+		}
+	}
+	if errScan := scanner.Err(); errScan != nil {
+		return fmt.Errorf("usage ledger: read segment: %w", errScan)
+	}
+	return nil
+}
+
+func encodeEntries(writer *bufio.Writer, entries []Entry) error {
+	encoder := json.NewEncoder(writer)
+	for _, entry := range entries {
+		if errEncode := encoder.Encode(entry); errEncode != nil {
+			return fmt.Errorf("usage ledger: encode entry: %w", errEncode)
+		}
+	}
+	return nil
+}
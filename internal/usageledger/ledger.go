@@ -0,0 +1,424 @@
+// Package usageledger persists completed usage records and answers aggregate
+// queries over them.
+//
+// The ledger consumes the same usage records that feed the usage queue and usage
+// plugins. Records are buffered in memory, appended to the configured store in
+// batches, and periodically pruned and rolled up into hourly rows.
+package usageledger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// flushInterval controls how often buffered records are appended to the store.
+	flushInterval = 5 * time.Second
+	// maintenanceInterval controls how often retention and compaction run.
+	maintenanceInterval = time.Hour
+	// flushBatchSize wakes the flush loop early once this many records are buffered.
+	flushBatchSize = 512
+	// maxPending caps the in-memory buffer while the store is failing.
+	maxPending = 50000
+)
+
+// ErrDisabled is returned by Query when the ledger is not enabled.
+var ErrDisabled = errors.New("usage ledger is disabled")
+
+// Entry is one persisted ledger row. Fresh rows describe a single request;
+// compacted rows sum every request of one hour with the same dimensions.
+// CostUSD is the priced spend; UnpricedRequests counts requests with tokens whose
+// model had no known price, so a spend report can flag incomplete totals.
+// KeyID is the non-reversible identity of the client key and APIKey its label or
+// mask; rows are attributed to KeyID because distinct keys can share a mask.
+type Entry struct {
+	Timestamp         time.Time `json:"timestamp"`
+	KeyID             string    `json:"key_id,omitempty"`
+	APIKey            string    `json:"api_key,omitempty"`
+	Model             string    `json:"model,omitempty"`
+	Provider          string    `json:"provider,omitempty"`
+	AuthIndex         string    `json:"auth_index,omitempty"`
+	AuthType          string    `json:"auth_type,omitempty"`
+	Requests          int64     `json:"requests"`
+	Failures          int64     `json:"failures,omitempty"`
+	InputTokens       int64     `json:"input_tokens,omitempty"`
+	OutputTokens      int64     `json:"output_tokens,omitempty"`
+	ReasoningTokens   int64     `json:"reasoning_tokens,omitempty"`
+	CachedTokens      int64     `json:"cached_tokens,omitempty"`
+	TotalTokens       int64     `json:"total_tokens,omitempty"`
+	InputAudioTokens  int64     `json:"input_audio_tokens,omitempty"`
+	OutputAudioTokens int64     `json:"output_audio_tokens,omitempty"`
+	LatencyMs         int64     `json:"latency_ms,omitempty"`
+	CostUSD           float64   `json:"cost_usd,omitempty"`
+	UnpricedRequests  int64     `json:"unpriced_requests,omitempty"`
+}
+
+// Store persists ledger entries. Day arguments are UTC midnights.
+type Store interface {
+	// Append persists entries.
+	Append(ctx context.Context, entries []Entry) error
+	// Scan calls fn for every entry with from <= Timestamp < to.
+	Scan(ctx context.Context, from, to time.Time, fn func(Entry) error) error
+	// Oldest returns the timestamp of the oldest entry. The boolean is false when the store is empty.
+	Oldest(ctx context.Context) (time.Time, bool, error)
+	// DeleteBefore drops every entry older than cutoff.
+	DeleteBefore(ctx context.Context, cutoff time.Time) error
+	// ReplaceDay atomically replaces every entry of day with entries.
+	ReplaceDay(ctx context.Context, day time.Time, entries []Entry) error
+}
+
+// Aggregator is implemented by stores that filter and group entries themselves.
+// AggregateScan calls fn with one summed entry per group of the entries q
+// selects, where a group is a bucket (of size bucket) and the q.GroupBy
+// dimensions. Dimensions outside q.GroupBy are empty and Timestamp is the bucket
+// start. Groups are passed in the order of their latest entry.
+type Aggregator interface {
+	AggregateScan(ctx context.Context, q Query, bucket string, fn func(Entry) error) error
+}
+
+// StoreProvider exposes a backend-specific usage ledger store.
+type StoreProvider interface {
+	UsageLedgerStore() Store
+}
+
+// Ledger buffers usage entries and writes them to the selected store.
+type Ledger struct {
+	mu       sync.Mutex
+	cfg      config.UsageLedgerConfig
+	dir      string
+	external Store
+	file     *FileStore
+	store    Store
+	pending  []Entry
+	stop     chan struct{}
+	kick     chan struct{}
+	// compactedThrough is the first day that has not been compacted yet by this process.
+	compactedThrough time.Time
+	warnedBackend    bool
+}
+
+// New constructs a disabled ledger.
+func New() *Ledger {
+	return &Ledger{kick: make(chan struct{}, 1)}
+}
+
+var defaultLedger = New()
+
+// Default returns the process-wide ledger fed by the usage plugin.
+func Default() *Ledger {
+	return defaultLedger
+}
+
+// Apply publishes the usage-ledger block. dir is the resolved directory of the file backend.
+func (l *Ledger) Apply(cfg config.UsageLedgerConfig, dir string) {
+	if l == nil {
+		return
+	}
+	if errFlush := l.Flush(context.Background()); errFlush != nil {
+		log.Warnf("usage ledger: failed to flush records before reconfiguring: %v", errFlush)
+	}
+	l.mu.Lock()
+	l.cfg = cfg
+	l.dir = dir
+	l.selectStoreLocked()
+	l.mu.Unlock()
+}
+
+// SetExternalStore attaches the store used by the postgres backend. Passing nil detaches it.
+func (l *Ledger) SetExternalStore(store Store) {
+	if l == nil {
+		return
+	}
+	if errFlush := l.Flush(context.Background()); errFlush != nil {
+		log.Warnf("usage ledger: failed to flush records before switching store: %v", errFlush)
+	}
+	l.mu.Lock()
+	l.external = store
+	l.warnedBackend = false
+	l.selectStoreLocked()
+	l.mu.Unlock()
+}
+
+// Enabled reports whether records are currently accepted.
+func (l *Ledger) Enabled() bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.store != nil
+}
+
+// Record buffers entry for the next flush.
+func (l *Ledger) Record(entry Entry) {
+	if l == nil {
+		return
+	}
+	if entry.Requests <= 0 {
+		entry.Requests = 1
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	entry.Timestamp = entry.Timestamp.UTC()
+
+	l.mu.Lock()
+	if l.store == nil {
+		l.mu.Unlock()
+		return
+	}
+	if len(l.pending) >= maxPending {
+		l.pending = l.pending[1:]
+	}
+	l.pending = append(l.pending, entry)
+	wake := len(l.pending) >= flushBatchSize
+	l.mu.Unlock()
+
+	if wake {
+		select {
+		case l.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush appends buffered entries to the store.
+func (l *Ledger) Flush(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	l.mu.Lock()
+	store := l.store
+	pending := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+	if store == nil || len(pending) == 0 {
+		return nil
+	}
+	if errAppend := store.Append(ctx, pending); errAppend != nil {
+		l.mu.Lock()
+		if l.store == store {
+			l.pending = append(pending, l.pending...)
+			if overflow := len(l.pending) - maxPending; overflow > 0 {
+				l.pending = l.pending[overflow:]
+			}
+		}
+		l.mu.Unlock()
+		return errAppend
+	}
+	return nil
+}
+
+// Stop flushes buffered entries and stops the background loop.
+func (l *Ledger) Stop(ctx context.Context) {
+	if l == nil {
+		return
+	}
+	if errFlush := l.Flush(ctx); errFlush != nil {
+		log.Warnf("usage ledger: failed to persist buffered records: %v", errFlush)
+	}
+	l.mu.Lock()
+	if l.stop != nil {
+		close(l.stop)
+		l.stop = nil
+	}
+	l.mu.Unlock()
+}
+
+// Maintain applies retention and compacts days older than compact-after-days.
+func (l *Ledger) Maintain(ctx context.Context, now time.Time) error {
+	if l == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if errFlush := l.Flush(ctx); errFlush != nil {
+		return errFlush
+	}
+	l.mu.Lock()
+	store := l.store
+	cfg := l.cfg
+	start := l.compactedThrough
+	l.mu.Unlock()
+	if store == nil {
+		return nil
+	}
+
+	today := startOfDay(now)
+	if cfg.RetentionDays > 0 {
+		if errDelete := store.DeleteBefore(ctx, today.AddDate(0, 0, -cfg.RetentionDays)); errDelete != nil {
+			return errDelete
+		}
+	}
+	if cfg.CompactAfterDays <= 0 {
+		return nil
+	}
+
+	limit := today.AddDate(0, 0, -cfg.CompactAfterDays)
+	if start.IsZero() {
+		oldest, ok, errOldest := store.Oldest(ctx)
+		if errOldest != nil {
+			return errOldest
+		}
+		if !ok {
+			return nil
+		}
+		start = startOfDay(oldest)
+	}
+	for day := start; day.Before(limit); day = day.AddDate(0, 0, 1) {
+		if errCompact := compactDay(ctx, store, day); errCompact != nil {
+			return errCompact
+		}
+	}
+	l.mu.Lock()
+	if l.store == store && limit.After(l.compactedThrough) {
+		l.compactedThrough = limit
+	}
+	l.mu.Unlock()
+	return nil
+}
+
+// compactDay rolls the entries of day up into one row per hour and dimension set.
+func compactDay(ctx context.Context, store Store, day time.Time) error {
+	type rollupKey struct {
+		hour      time.Time
+		keyID     string
+		apiKey    string
+		model     string
+		provider  string
+		authIndex string
+		authType  string
+	}
+	rows := make(map[rollupKey]*Entry)
+	order := make([]rollupKey, 0)
+	raw := 0
+	errScan := store.Scan(ctx, day, day.AddDate(0, 0, 1), func(entry Entry) error {
+		raw++
+		key := rollupKey{
+			hour:      entry.Timestamp.UTC().Truncate(time.Hour),
+			keyID:     entry.KeyID,
+			apiKey:    entry.APIKey,
+			model:     entry.Model,
+			provider:  entry.Provider,
+			authIndex: entry.AuthIndex,
+			authType:  entry.AuthType,
+		}
+		row, ok := rows[key]
+		if !ok {
+			row = &Entry{Timestamp: key.hour, KeyID: key.keyID, APIKey: key.apiKey, Model: key.model, Provider: key.provider, AuthIndex: key.authIndex, AuthType: key.authType}
+			rows[key] = row
+			order = append(order, key)
+		}
+		addEntry(row, entry)
+		return nil
+	})
+	if errScan != nil {
+		return errScan
+	}
+	if len(rows) >= raw {
+		return nil
+	}
+	compacted := make([]Entry, 0, len(order))
+	for _, key := range order {
+		compacted = append(compacted, *rows[key])
+	}
+	return store.ReplaceDay(ctx, day, compacted)
+}
+
+func addEntry(dst *Entry, src Entry) {
+	requests := src.Requests
+	if requests <= 0 {
+		requests = 1
+	}
+	dst.Requests += requests
+	dst.Failures += src.Failures
+	dst.InputTokens += src.InputTokens
+	dst.OutputTokens += src.OutputTokens
+	dst.ReasoningTokens += src.ReasoningTokens
+	dst.CachedTokens += src.CachedTokens
+	dst.TotalTokens += src.TotalTokens
+	dst.InputAudioTokens += src.InputAudioTokens
+	dst.OutputAudioTokens += src.OutputAudioTokens
+	dst.LatencyMs += src.LatencyMs
+	dst.CostUSD += src.CostUSD
+	dst.UnpricedRequests += src.UnpricedRequests
+}
+
+func (l *Ledger) selectStoreLocked() {
+	var next Store
+	if l.cfg.Enable {
+		if l.cfg.EffectiveBackend() == config.UsageLedgerBackendPostgres && l.external != nil {
+			next = l.external
+		} else {
+			if l.cfg.EffectiveBackend() == config.UsageLedgerBackendPostgres && !l.warnedBackend {
+				log.Warn("usage ledger: postgres backend requested but the Postgres store is not in use; falling back to local files")
+				l.warnedBackend = true
+			}
+			if l.file == nil || l.file.Dir() != l.dir {
+				l.file = NewFileStore(l.dir)
+			}
+			next = l.file
+		}
+	}
+	if next != l.store {
+		l.compactedThrough = time.Time{}
+	}
+	l.store = next
+
+	switch {
+	case next == nil && l.stop != nil:
+		close(l.stop)
+		l.stop = nil
+		l.pending = nil
+	case next != nil && l.stop == nil:
+		l.stop = make(chan struct{})
+		go l.loop(l.stop)
+	}
+}
+
+func (l *Ledger) loop(stop <-chan struct{}) {
+	flushTicker := time.NewTicker(flushInterval)
+	defer flushTicker.Stop()
+	maintenanceTicker := time.NewTicker(maintenanceInterval)
+	defer maintenanceTicker.Stop()
+
+	l.runMaintenance()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-flushTicker.C:
+			l.runFlush()
+		case <-l.kick:
+			l.runFlush()
+		case <-maintenanceTicker.C:
+			l.runMaintenance()
+		}
+	}
+}
+
+func (l *Ledger) runFlush() {
+	if errFlush := l.Flush(context.Background()); errFlush != nil {
+		log.Warnf("usage ledger: failed to persist records: %v", errFlush)
+	}
+}
+
+func (l *Ledger) runMaintenance() {
+	if errMaintain := l.Maintain(context.Background(), time.Now()); errMaintain != nil {
+		log.Warnf("usage ledger: retention/compaction failed: %v", errMaintain)
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	utc := t.UTC()
+	return time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC)
+}
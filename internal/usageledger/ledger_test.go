@@ -0,0 +1,205 @@
+package usageledger
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+func newTestLedger(t *testing.T, cfg config.UsageLedgerConfig) (*Ledger, string) {
+	t.Helper()
+	dir := t.TempDir()
+	ledger := New()
+	cfg.Enable = true
+	ledger.Apply(cfg, dir)
+	t.Cleanup(func() { ledger.Apply(config.UsageLedgerConfig{}, dir) })
+	return ledger, dir
+}
+
+func TestLedgerQueryGroupsByDimensions(t *testing.T) {
+	ledger, _ := newTestLedger(t, config.UsageLedgerConfig{})
+	base := time.Date(2026, 3, 1, 10, 15, 0, 0, time.UTC)
+	ledger.Record(Entry{Timestamp: base, APIKey: "team-a", Model: "gpt-5", Provider: "codex", AuthIndex: "a1", InputTokens: 10, OutputTokens: 5, TotalTokens: 15, LatencyMs: 100})
+	ledger.Record(Entry{Timestamp: base.Add(time.Hour), APIKey: "team-a", Model: "gpt-5", Provider: "codex", AuthIndex: "a1", TotalTokens: 30, LatencyMs: 300, Failures: 1})
+	ledger.Record(Entry{Timestamp: base.Add(24 * time.Hour), APIKey: "team-b", Model: "claude-sonnet-4-5", Provider: "claude", AuthIndex: "c1", TotalTokens: 7})
+
+	result, errQuery := ledger.Query(context.Background(), Query{GroupBy: []string{GroupModel}})
+	if errQuery != nil {
+		t.Fatalf("Query() error = %v", errQuery)
+	}
+	if len(result.Rows) != 2 || result.Rows[0].Model != "gpt-5" || result.Rows[0].Requests != 2 || result.Rows[0].TotalTokens != 45 {
+		t.Fatalf("rows = %#v", result.Rows)
+	}
+	if result.Rows[0].Failures != 1 || result.Rows[0].AvgLatencyMs != 200 {
+		t.Fatalf("gpt-5 row = %#v", result.Rows[0])
+	}
+	if result.Totals.Requests != 3 || result.Totals.TotalTokens != 52 {
+		t.Fatalf("totals = %#v", result.Totals)
+	}
+
+	byDay, errQuery := ledger.Query(context.Background(), Query{GroupBy: []string{GroupBucket, GroupAPIKey}, Bucket: BucketDay, Provider: "CODEX"})
+	if errQuery != nil {
+		t.Fatalf("Query() error = %v", errQuery)
+	}
+	if len(byDay.Rows) != 1 || byDay.Rows[0].Bucket != "2026-03-01T00:00:00Z" || byDay.Rows[0].APIKey != "team-a" {
+		t.Fatalf("bucketed rows = %#v", byDay.Rows)
+	}
+
+	windowed, errQuery := ledger.Query(context.Background(), Query{From: base.Add(30 * time.Minute), To: base.Add(2 * time.Hour)})
+	if errQuery != nil {
+		t.Fatalf("Query() error = %v", errQuery)
+	}
+	if windowed.Totals.Requests != 1 || windowed.Totals.TotalTokens != 30 {
+		t.Fatalf("windowed totals = %#v", windowed.Totals)
+	}
+}
+
+func TestLedgerGroupsKeysSharingAMask(t *testing.T) {
+	ledger, _ := newTestLedger(t, config.UsageLedgerConfig{})
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	first := entryFromRecord(context.Background(), coreusage.Record{APIKey: "sk-a-first-key-1234", Model: "gpt-5", RequestedAt: base})
+	second := entryFromRecord(context.Background(), coreusage.Record{APIKey: "sk-a-other-key-1234", Model: "gpt-5", RequestedAt: base})
+	if first.APIKey != second.APIKey || first.KeyID == second.KeyID {
+		t.Fatalf("entries = %#v / %#v, want the same mask and distinct key IDs", first, second)
+	}
+	ledger.Record(first)
+	ledger.Record(first)
+	ledger.Record(second)
+
+	result, errQuery := ledger.Query(context.Background(), Query{GroupBy: []string{GroupAPIKey}})
+	if errQuery != nil {
+		t.Fatalf("Query() error = %v", errQuery)
+	}
+	if len(result.Rows) != 2 || result.Rows[0].Requests != 2 || result.Rows[0].KeyID != first.KeyID || result.Rows[1].KeyID != second.KeyID {
+		t.Fatalf("rows = %#v", result.Rows)
+	}
+
+	filtered, errQuery := ledger.Query(context.Background(), Query{APIKey: "sk-a-other-key-1234"})
+	if errQuery != nil {
+		t.Fatalf("Query() error = %v", errQuery)
+	}
+	if filtered.Totals.Requests != 1 {
+		t.Fatalf("filtered totals = %#v", filtered.Totals)
+	}
+}
+
+type aggregatingStore struct {
+	*FileStore
+	groups []Entry
+}
+
+func (s *aggregatingStore) AggregateScan(_ context.Context, _ Query, _ string, fn func(Entry) error) error {
+	for _, group := range s.groups {
+		if errFn := fn(group); errFn != nil {
+			return errFn
+		}
+	}
+	return nil
+}
+
+func TestRunQueryUsesStoreAggregation(t *testing.T) {
+	store := &aggregatingStore{
+		FileStore: NewFileStore(t.TempDir()),
+		groups: []Entry{
+			{KeyID: "k1", APIKey: "old-label", Requests: 2, TotalTokens: 20, LatencyMs: 200},
+			{KeyID: "k1", APIKey: "new-label", Requests: 1, TotalTokens: 5, LatencyMs: 100},
+			{KeyID: "k2", APIKey: "other", Requests: 1, TotalTokens: 1},
+		},
+	}
+	if errAppend := store.Append(context.Background(), []Entry{{Timestamp: time.Now(), KeyID: "ignored", Requests: 1}}); errAppend != nil {
+		t.Fatalf("Append() error = %v", errAppend)
+	}
+
+	result, errQuery := RunQuery(context.Background(), store, Query{GroupBy: []string{GroupAPIKey}, Model: "gpt-5"})
+	if errQuery != nil {
+		t.Fatalf("RunQuery() error = %v", errQuery)
+	}
+	if len(result.Rows) != 2 || result.Rows[0].KeyID != "k1" || result.Rows[0].APIKey != "new-label" || result.Rows[0].Requests != 3 || result.Rows[0].AvgLatencyMs != 100 {
+		t.Fatalf("rows = %#v", result.Rows)
+	}
+	if result.Totals.Requests != 4 || result.Totals.TotalTokens != 26 {
+		t.Fatalf("totals = %#v", result.Totals)
+	}
+}
+
+func TestLedgerMaintainCompactsAndPrunes(t *testing.T) {
+	ledger, dir := newTestLedger(t, config.UsageLedgerConfig{RetentionDays: 30, CompactAfterDays: 2})
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	old := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	compactable := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		ledger.Record(Entry{Timestamp: compactable.Add(time.Duration(i) * time.Minute), APIKey: "k", Model: "gpt-5", Provider: "codex", TotalTokens: 10, LatencyMs: 50})
+	}
+	ledger.Record(Entry{Timestamp: compactable.Add(2 * time.Hour), APIKey: "k", Model: "gpt-5", Provider: "codex", TotalTokens: 1})
+	ledger.Record(Entry{Timestamp: old.AddDate(0, 0, -20), Model: "ancient", TotalTokens: 99})
+	ledger.Record(Entry{Timestamp: now, Model: "fresh", TotalTokens: 2})
+
+	if errMaintain := ledger.Maintain(context.Background(), now); errMaintain != nil {
+		t.Fatalf("Maintain() error = %v", errMaintain)
+	}
+
+	if _, errStat := os.Stat(filepath.Join(dir, "usage-2026-02-09.jsonl")); !os.IsNotExist(errStat) {
+		t.Fatalf("expired segment still present: %v", errStat)
+	}
+	data, errRead := os.ReadFile(filepath.Join(dir, "usage-2026-03-10.jsonl"))
+	if errRead != nil {
+		t.Fatalf("ReadFile() error = %v", errRead)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Fatalf("compacted segment has %d lines, want 2:\n%s", lines, data)
+	}
+
+	result, errQuery := ledger.Query(context.Background(), Query{From: time.Time{}, GroupBy: []string{GroupModel}})
+	if errQuery != nil {
+		t.Fatalf("Query() error = %v", errQuery)
+	}
+	if result.Totals.Requests != 7 || result.Totals.TotalTokens != 53 {
+		t.Fatalf("totals after maintenance = %#v", result.Totals)
+	}
+	for _, row := range result.Rows {
+		if row.Model == "gpt-5" && (row.Requests != 6 || row.AvgLatencyMs != 41.7) {
+			t.Fatalf("compacted row = %#v", row)
+		}
+	}
+}
+
+func TestLedgerDisabledDropsRecords(t *testing.T) {
+	ledger := New()
+	ledger.Record(Entry{Model: "gpt-5"})
+	if _, errQuery := ledger.Query(context.Background(), Query{}); errQuery != ErrDisabled {
+		t.Fatalf("Query() error = %v, want ErrDisabled", errQuery)
+	}
+}
+
+func TestResultWriteCSV(t *testing.T) {
+	result := &Result{
+		GroupBy: []string{GroupAPIKey, GroupModel},
+		Rows:    []Row{{APIKey: "team,a", Model: "gpt-5", Requests: 2, TotalTokens: 12, AvgLatencyMs: 12.25, CostUSD: 0.0125, UnpricedRequests: 1}},
+	}
+	var buf bytes.Buffer
+	if errWrite := result.WriteCSV(&buf); errWrite != nil {
+		t.Fatalf("WriteCSV() error = %v", errWrite)
+	}
+	want := "api-key,key-id,model,requests,failures,input_tokens,output_tokens,reasoning_tokens,cached_tokens,total_tokens,avg_latency_ms,cost_usd,unpriced_requests\n" +
+		"\"team,a\",,gpt-5,2,0,0,0,0,0,12,12.2,0.012500,1\n"
+	if buf.String() != want {
+		t.Fatalf("WriteCSV() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestParseGroupByRejectsUnknownDimension(t *testing.T) {
+	groups, errParse := ParseGroupBy("model, api_key,model,bucket")
+	if errParse != nil || strings.Join(groups, ",") != "model,api-key,bucket" {
+		t.Fatalf("ParseGroupBy() = %v, %v", groups, errParse)
+	}
+	if _, errParse = ParseGroupBy("region"); errParse == nil {
+		t.Fatal("ParseGroupBy(region) should fail")
+	}
+}
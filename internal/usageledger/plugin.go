@@ -0,0 +1,94 @@
+package usageledger
+
+import (
+	"context"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/access/keypolicy"
+	internallogging "github.com/router-for-me/CLIProxyAPI/v7/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	coresession "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/session"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+func init() {
+	coreusage.RegisterPlugin(&usagePlugin{})
+}
+
+// usagePlugin copies completed usage records into the default ledger.
+type usagePlugin struct{}
+
+func (p *usagePlugin) HandleUsage(ctx context.Context, record coreusage.Record) {
+	ledger := Default()
+	if !ledger.Enabled() {
+		return
+	}
+	ledger.Record(entryFromRecord(ctx, record))
+}
+
+func entryFromRecord(ctx context.Context, record coreusage.Record) Entry {
+	detail := coreusage.EnsureTokenBreakdownForProvider(record.Detail, record.Provider, record.ExecutorType)
+	total := detail.TotalTokens
+	if detail.TokenBreakdown.Valid() {
+		total = detail.TokenBreakdown.TotalTokens
+	}
+
+	failed := record.Failed
+	if !failed && ctx != nil {
+		failed = internallogging.GetResponseStatus(ctx) >= 400
+	}
+	entry := Entry{
+		Timestamp:         record.RequestedAt,
+		KeyID:             KeyID(record.APIKey),
+		APIKey:            DisplayKey(record.APIKey),
+		Model:             valueOrUnknown(record.Model),
+		Provider:          valueOrUnknown(record.Provider),
+		AuthIndex:         strings.TrimSpace(record.AuthIndex),
+		AuthType:          strings.TrimSpace(record.AuthType),
+		Requests:          1,
+		InputTokens:       detail.InputTokens,
+		OutputTokens:      detail.OutputTokens,
+		ReasoningTokens:   detail.ReasoningTokens,
+		CachedTokens:      detail.CachedTokens,
+		TotalTokens:       total,
+		InputAudioTokens:  detail.InputAudioTokens,
+		OutputAudioTokens: detail.OutputAudioTokens,
+		LatencyMs:         record.Latency.Milliseconds(),
+	}
+	if failed {
+		entry.Failures = 1
+	}
+	if record.Cost != nil {
+		entry.CostUSD = record.Cost.Total
+	} else if total > 0 {
+		entry.UnpricedRequests = 1
+	}
+	return entry
+}
+
+// KeyID returns the stable identity stored for a client key. It is the caller
+// scope hash, so raw keys never reach the ledger and keys sharing a mask stay apart.
+func KeyID(apiKey string) string {
+	return coresession.CallerScope(apiKey)
+}
+
+// DisplayKey returns the display name stored for a client key: its
+// api-keys label when set, otherwise the masked key.
+func DisplayKey(apiKey string) string {
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		return ""
+	}
+	if entry, ok := keypolicy.Default().Lookup(apiKey); ok && entry.Label != "" {
+		return entry.Label
+	}
+	return util.HideAPIKey(apiKey)
+}
+
+func valueOrUnknown(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
@@ -0,0 +1,403 @@
+package usageledger
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Group-by dimensions accepted by Query.
+const (
+	GroupAPIKey   = "api-key"
+	GroupModel    = "model"
+	GroupProvider = "provider"
+	GroupAuth     = "auth"
+	GroupBucket   = "bucket"
+)
+
+// Time bucket sizes accepted by Query. Buckets are aligned to UTC.
+const (
+	BucketHour  = "hour"
+	BucketDay   = "day"
+	BucketMonth = "month"
+)
+
+// Row orders accepted by Query.
+const (
+	SortTokens = "tokens"
+	SortCost   = "cost"
+)
+
+// Query selects and groups ledger entries.
+type Query struct {
+	// From and To bound the entry timestamps as [From, To). Zero values are open.
+	From time.Time
+	To   time.Time
+	// GroupBy lists the dimensions rows are grouped by, in output order.
+	GroupBy []string
+	// Bucket is the time bucket size used when GroupBy contains GroupBucket.
+	Bucket string
+	// Filters restrict entries to exact dimension values. Empty filters match everything.
+	APIKey   string
+	Model    string
+	Provider string
+	Auth     string
+	// Sort orders rows within a bucket: SortTokens (default) or SortCost.
+	Sort string
+	// Limit caps the number of returned rows. 0 returns every row.
+	Limit int
+}
+
+// Row is one aggregated result row. Rows grouped by api-key are keyed by KeyID;
+// APIKey carries the most recent label or mask recorded for that key.
+type Row struct {
+	Bucket            string  `json:"bucket,omitempty"`
+	KeyID             string  `json:"key_id,omitempty"`
+	APIKey            string  `json:"api_key,omitempty"`
+	Model             string  `json:"model,omitempty"`
+	Provider          string  `json:"provider,omitempty"`
+	Auth              string  `json:"auth,omitempty"`
+	Requests          int64   `json:"requests"`
+	Failures          int64   `json:"failures"`
+	InputTokens       int64   `json:"input_tokens"`
+	OutputTokens      int64   `json:"output_tokens"`
+	ReasoningTokens   int64   `json:"reasoning_tokens"`
+	CachedTokens      int64   `json:"cached_tokens"`
+	TotalTokens       int64   `json:"total_tokens"`
+	InputAudioTokens  int64   `json:"input_audio_tokens,omitempty"`
+	OutputAudioTokens int64   `json:"output_audio_tokens,omitempty"`
+	AvgLatencyMs      float64 `json:"avg_latency_ms"`
+	CostUSD           float64 `json:"cost_usd"`
+	UnpricedRequests  int64   `json:"unpriced_requests"`
+
+	latencyMs int64
+}
+
+// Result is the answer to a Query.
+type Result struct {
+	From    *time.Time `json:"from,omitempty"`
+	To      *time.Time `json:"to,omitempty"`
+	GroupBy []string   `json:"group_by"`
+	Bucket  string     `json:"bucket,omitempty"`
+	Rows    []Row      `json:"rows"`
+	Totals  Row        `json:"totals"`
+}
+
+// ParseGroupBy splits a comma-separated dimension list and validates every item.
+func ParseGroupBy(raw string) ([]string, error) {
+	groups := make([]string, 0, 5)
+	seen := make(map[string]struct{}, 5)
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		switch item {
+		case "":
+			continue
+		case "apikey", "api_key", "key":
+			item = GroupAPIKey
+		case "time", "time-bucket":
+			item = GroupBucket
+		case GroupAPIKey, GroupModel, GroupProvider, GroupAuth, GroupBucket:
+		default:
+			return nil, fmt.Errorf("unsupported group-by dimension %q", item)
+		}
+		if _, exists := seen[item]; exists {
+			continue
+		}
+		seen[item] = struct{}{}
+		groups = append(groups, item)
+	}
+	return groups, nil
+}
+
+// NormalizeSort validates a row order. Empty values select SortTokens.
+func NormalizeSort(raw string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", SortTokens:
+		return SortTokens, nil
+	case SortCost, "spend":
+		return SortCost, nil
+	default:
+		return "", fmt.Errorf("unsupported sort %q: expected tokens or cost", raw)
+	}
+}
+
+// NormalizeBucket validates a bucket size. Empty values select BucketDay.
+func NormalizeBucket(raw string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", BucketDay, "1d":
+		return BucketDay, nil
+	case BucketHour, "1h":
+		return BucketHour, nil
+	case BucketMonth:
+		return BucketMonth, nil
+	default:
+		return "", fmt.Errorf("unsupported bucket %q: expected hour, day or month", raw)
+	}
+}
+
+// Query flushes buffered entries and aggregates the matching ledger rows.
+func (l *Ledger) Query(ctx context.Context, q Query) (*Result, error) {
+	if l == nil {
+		return nil, ErrDisabled
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if errFlush := l.Flush(ctx); errFlush != nil {
+		return nil, errFlush
+	}
+	l.mu.Lock()
+	store := l.store
+	l.mu.Unlock()
+	if store == nil {
+		return nil, ErrDisabled
+	}
+	return RunQuery(ctx, store, q)
+}
+
+// RunQuery aggregates the entries of store selected by q.
+func RunQuery(ctx context.Context, store Store, q Query) (*Result, error) {
+	bucket := ""
+	if containsGroup(q.GroupBy, GroupBucket) {
+		normalized, errBucket := NormalizeBucket(q.Bucket)
+		if errBucket != nil {
+			return nil, errBucket
+		}
+		bucket = normalized
+	}
+
+	result := &Result{GroupBy: append([]string{}, q.GroupBy...), Bucket: bucket, Rows: make([]Row, 0)}
+	if !q.From.IsZero() {
+		from := q.From.UTC()
+		result.From = &from
+	}
+	if !q.To.IsZero() {
+		to := q.To.UTC()
+		result.To = &to
+	}
+
+	keyID, keyAlias := KeyID(q.APIKey), DisplayKey(q.APIKey)
+	scan := func(fn func(Entry) error) error {
+		return store.Scan(ctx, q.From, q.To, func(entry Entry) error {
+			if !q.matches(entry, keyID, keyAlias) {
+				return nil
+			}
+			return fn(entry)
+		})
+	}
+	if aggregator, ok := store.(Aggregator); ok {
+		scan = func(fn func(Entry) error) error {
+			return aggregator.AggregateScan(ctx, q, bucket, fn)
+		}
+	}
+	rows := make(map[string]*Row)
+	errScan := scan(func(entry Entry) error {
+		row := Row{}
+		for _, group := range q.GroupBy {
+			switch group {
+			case GroupBucket:
+				row.Bucket = bucketStart(entry.Timestamp, bucket).Format(time.RFC3339)
+			case GroupAPIKey:
+				row.KeyID = entry.KeyID
+				row.APIKey = entry.APIKey
+			case GroupModel:
+				row.Model = entry.Model
+			case GroupProvider:
+				row.Provider = entry.Provider
+			case GroupAuth:
+				row.Auth = entry.AuthIndex
+			}
+		}
+		key := strings.Join([]string{row.Bucket, row.keyIdentity(), row.Model, row.Provider, row.Auth}, "\x00")
+		existing, ok := rows[key]
+		if !ok {
+			existing = &row
+			rows[key] = existing
+		} else if row.APIKey != "" {
+			existing.APIKey = row.APIKey
+		}
+		existing.add(entry)
+		result.Totals.add(entry)
+		return nil
+	})
+	if errScan != nil {
+		return nil, errScan
+	}
+
+	for _, row := range rows {
+		row.finish()
+		result.Rows = append(result.Rows, *row)
+	}
+	result.Totals.finish()
+	sort.Slice(result.Rows, func(i, j int) bool {
+		a, b := result.Rows[i], result.Rows[j]
+		if a.Bucket != b.Bucket {
+			return a.Bucket < b.Bucket
+		}
+		if q.Sort == SortCost && a.CostUSD != b.CostUSD {
+			return a.CostUSD > b.CostUSD
+		}
+		if a.TotalTokens != b.TotalTokens {
+			return a.TotalTokens > b.TotalTokens
+		}
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return strings.Join([]string{a.APIKey, a.KeyID, a.Model, a.Provider, a.Auth}, "\x00") <
+			strings.Join([]string{b.APIKey, b.KeyID, b.Model, b.Provider, b.Auth}, "\x00")
+	})
+	if q.Limit > 0 && len(result.Rows) > q.Limit {
+		result.Rows = result.Rows[:q.Limit]
+	}
+	return result, nil
+}
+
+// WriteCSV writes the grouped dimensions followed by the counters, one row per line.
+// The api-key dimension is followed by a key-id column so keys sharing a mask stay distinct.
+func (r *Result) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := make([]string, 0, len(r.GroupBy)+11)
+	for _, group := range r.GroupBy {
+		header = append(header, group)
+		if group == GroupAPIKey {
+			header = append(header, "key-id")
+		}
+	}
+	header = append(header, "requests", "failures", "input_tokens", "output_tokens", "reasoning_tokens", "cached_tokens", "total_tokens", "avg_latency_ms", "cost_usd", "unpriced_requests")
+	if errWrite := writer.Write(header); errWrite != nil {
+		return errWrite
+	}
+	for _, row := range r.Rows {
+		record := make([]string, 0, len(header))
+		for _, group := range r.GroupBy {
+			record = append(record, row.dimension(group))
+			if group == GroupAPIKey {
+				record = append(record, row.KeyID)
+			}
+		}
+		record = append(record,
+			strconv.FormatInt(row.Requests, 10),
+			strconv.FormatInt(row.Failures, 10),
+			strconv.FormatInt(row.InputTokens, 10),
+			strconv.FormatInt(row.OutputTokens, 10),
+			strconv.FormatInt(row.ReasoningTokens, 10),
+			strconv.FormatInt(row.CachedTokens, 10),
+			strconv.FormatInt(row.TotalTokens, 10),
+			strconv.FormatFloat(row.AvgLatencyMs, 'f', 1, 64),
+			strconv.FormatFloat(row.CostUSD, 'f', 6, 64),
+			strconv.FormatInt(row.UnpricedRequests, 10),
+		)
+		if errWrite := writer.Write(record); errWrite != nil {
+			return errWrite
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// matches applies the filters. keyID and keyAlias are the stored identity and
+// display name of q.APIKey, so callers may filter by the raw key, its key ID or
+// its label. Entries recorded before key IDs existed only carry the display name.
+func (q Query) matches(entry Entry, keyID, keyAlias string) bool {
+	if q.APIKey != "" && !q.matchesKey(entry, keyID, keyAlias) {
+		return false
+	}
+	if q.Model != "" && !strings.EqualFold(entry.Model, q.Model) {
+		return false
+	}
+	if q.Provider != "" && !strings.EqualFold(entry.Provider, q.Provider) {
+		return false
+	}
+	if q.Auth != "" && entry.AuthIndex != q.Auth {
+		return false
+	}
+	return true
+}
+
+func (q Query) matchesKey(entry Entry, keyID, keyAlias string) bool {
+	if entry.APIKey == q.APIKey || entry.KeyID == q.APIKey {
+		return true
+	}
+	if entry.KeyID != "" {
+		return entry.KeyID == keyID
+	}
+	return entry.APIKey == keyAlias
+}
+
+func (r *Row) add(entry Entry) {
+	requests := entry.Requests
+	if requests <= 0 {
+		requests = 1
+	}
+	r.Requests += requests
+	r.Failures += entry.Failures
+	r.InputTokens += entry.InputTokens
+	r.OutputTokens += entry.OutputTokens
+	r.ReasoningTokens += entry.ReasoningTokens
+	r.CachedTokens += entry.CachedTokens
+	r.TotalTokens += entry.TotalTokens
+	r.InputAudioTokens += entry.InputAudioTokens
+	r.OutputAudioTokens += entry.OutputAudioTokens
+	r.latencyMs += entry.LatencyMs
+	r.CostUSD += entry.CostUSD
+	r.UnpricedRequests += entry.UnpricedRequests
+}
+
+func (r *Row) finish() {
+	if r.Requests > 0 {
+		r.AvgLatencyMs = math.Round(float64(r.latencyMs)/float64(r.Requests)*10) / 10
+	}
+	r.CostUSD = math.Round(r.CostUSD*1e6) / 1e6
+}
+
+// keyIdentity returns the value rows are grouped by on the api-key dimension.
+func (r Row) keyIdentity() string {
+	if r.KeyID != "" {
+		return r.KeyID
+	}
+	return r.APIKey
+}
+
+func (r Row) dimension(group string) string {
+	switch group {
+	case GroupBucket:
+		return r.Bucket
+	case GroupAPIKey:
+		return r.APIKey
+	case GroupModel:
+		return r.Model
+	case GroupProvider:
+		return r.Provider
+	case GroupAuth:
+		return r.Auth
+	default:
+		return ""
+	}
+}
+
+func bucketStart(t time.Time, bucket string) time.Time {
+	utc := t.UTC()
+	switch bucket {
+	case BucketHour:
+		return utc.Truncate(time.Hour)
+	case BucketMonth:
+		return time.Date(utc.Year(), utc.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return startOfDay(utc)
+	}
+}
+
+func containsGroup(groups []string, group string) bool {
+	for _, item := range groups {
+		if item == group {
+			return true
+		}
+	}
+	return false
+}
@@ -3,12 +3,14 @@
 package watcher
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"os"
 	"reflect"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/audit"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/watcher/diff"
@@ -70,7 +72,8 @@ func (w *Watcher) reloadConfigIfChanged() {
 		return
 	}
 	log.Infof("config file changed, reloading: %s", w.configPath)
-	if w.reloadConfig() {
+	external := !audit.Default().ConsumeManagedConfig(data)
+	if w.reloadConfigAudited(external) {
 		finalHash := newHash
 		if updatedData, errRead := os.ReadFile(w.configPath); errRead == nil && len(updatedData) > 0 {
 			sumUpdated := sha256.Sum256(updatedData)
@@ -86,6 +89,12 @@ func (w *Watcher) reloadConfigIfChanged() {
 }
 
 func (w *Watcher) reloadConfig() bool {
+	return w.reloadConfigAudited(false)
+}
+
+// reloadConfigAudited reloads the config file. When external is true the file was not
+// written by the management API and the resulting changes are recorded in the audit log.
+func (w *Watcher) reloadConfigAudited(external bool) bool {
 	log.Debug("=========================== CONFIG RELOAD ============================")
 	log.Debugf("starting config reload from: %s", w.configPath)
 
@@ -129,6 +138,13 @@ func (w *Watcher) reloadConfig() bool {
 			for _, d := range details {
 				log.Infof("  %s", d)
 			}
+			if external {
+				audit.Default().Record(context.Background(), audit.Event{
+					Source:  audit.SourceFileWatcher,
+					Path:    w.configPath,
+					Changes: details,
+				})
+			}
 		} else {
 			log.Debugf("no material config field changes detected")
 		}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -43,6 +44,87 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if oldCfg.RedisUsageQueueRetentionSeconds != newCfg.RedisUsageQueueRetentionSeconds {
 		changes = append(changes, fmt.Sprintf("redis-usage-queue-retention-seconds: %d -> %d", oldCfg.RedisUsageQueueRetentionSeconds, newCfg.RedisUsageQueueRetentionSeconds))
 	}
+	if oldCfg.UsageLedger.Enable != newCfg.UsageLedger.Enable {
+		changes = append(changes, fmt.Sprintf("usage-ledger.enable: %t -> %t", oldCfg.UsageLedger.Enable, newCfg.UsageLedger.Enable))
+	}
+	if oldCfg.UsageLedger.EffectiveBackend() != newCfg.UsageLedger.EffectiveBackend() {
+		changes = append(changes, fmt.Sprintf("usage-ledger.backend: %s -> %s", oldCfg.UsageLedger.EffectiveBackend(), newCfg.UsageLedger.EffectiveBackend()))
+	}
+	if strings.TrimSpace(oldCfg.UsageLedger.Dir) != strings.TrimSpace(newCfg.UsageLedger.Dir) {
+		changes = append(changes, fmt.Sprintf("usage-ledger.dir: %s -> %s", strings.TrimSpace(oldCfg.UsageLedger.Dir), strings.TrimSpace(newCfg.UsageLedger.Dir)))
+	}
+	if oldCfg.UsageLedger.RetentionDays != newCfg.UsageLedger.RetentionDays {
+		changes = append(changes, fmt.Sprintf("usage-ledger.retention-days: %d -> %d", oldCfg.UsageLedger.RetentionDays, newCfg.UsageLedger.RetentionDays))
+	}
+	if oldCfg.UsageLedger.CompactAfterDays != newCfg.UsageLedger.CompactAfterDays {
+		changes = append(changes, fmt.Sprintf("usage-ledger.compact-after-days: %d -> %d", oldCfg.UsageLedger.CompactAfterDays, newCfg.UsageLedger.CompactAfterDays))
+	}
+	if oldCfg.AuditLog.Enable != newCfg.AuditLog.Enable {
+		changes = append(changes, fmt.Sprintf("audit-log.enable: %t -> %t", oldCfg.AuditLog.Enable, newCfg.AuditLog.Enable))
+	}
+	if oldCfg.AuditLog.EffectiveBackend() != newCfg.AuditLog.EffectiveBackend() {
+		changes = append(changes, fmt.Sprintf("audit-log.backend: %s -> %s", oldCfg.AuditLog.EffectiveBackend(), newCfg.AuditLog.EffectiveBackend()))
+	}
+	if strings.TrimSpace(oldCfg.AuditLog.Dir) != strings.TrimSpace(newCfg.AuditLog.Dir) {
+		changes = append(changes, fmt.Sprintf("audit-log.dir: %s -> %s", strings.TrimSpace(oldCfg.AuditLog.Dir), strings.TrimSpace(newCfg.AuditLog.Dir)))
+	}
+	if oldCfg.AuditLog.RetentionDays != newCfg.AuditLog.RetentionDays {
+		changes = append(changes, fmt.Sprintf("audit-log.retention-days: %d -> %d", oldCfg.AuditLog.RetentionDays, newCfg.AuditLog.RetentionDays))
+	}
+	if oldCfg.AuditLog.ForwardToHome != newCfg.AuditLog.ForwardToHome {
+		changes = append(changes, fmt.Sprintf("audit-log.forward-to-home: %t -> %t", oldCfg.AuditLog.ForwardToHome, newCfg.AuditLog.ForwardToHome))
+	}
+	if oldCfg.Batch.Enable != newCfg.Batch.Enable {
+		changes = append(changes, fmt.Sprintf("batch.enable: %t -> %t", oldCfg.Batch.Enable, newCfg.Batch.Enable))
+	}
+	if strings.TrimSpace(oldCfg.Batch.Dir) != strings.TrimSpace(newCfg.Batch.Dir) {
+		changes = append(changes, fmt.Sprintf("batch.dir: %s -> %s", strings.TrimSpace(oldCfg.Batch.Dir), strings.TrimSpace(newCfg.Batch.Dir)))
+	}
+	if oldCfg.Batch.ConcurrencyPerCredential != newCfg.Batch.ConcurrencyPerCredential {
+		changes = append(changes, fmt.Sprintf("batch.concurrency-per-credential: %d -> %d", oldCfg.Batch.ConcurrencyPerCredential, newCfg.Batch.ConcurrencyPerCredential))
+	}
+	if oldCfg.Batch.MaxFileSizeMB != newCfg.Batch.MaxFileSizeMB {
+		changes = append(changes, fmt.Sprintf("batch.max-file-size-mb: %d -> %d", oldCfg.Batch.MaxFileSizeMB, newCfg.Batch.MaxFileSizeMB))
+	}
+	if oldCfg.ResponseCache.Enable != newCfg.ResponseCache.Enable {
+		changes = append(changes, fmt.Sprintf("response-cache.enable: %t -> %t", oldCfg.ResponseCache.Enable, newCfg.ResponseCache.Enable))
+	}
+	if oldCfg.ResponseCache.EffectiveBackend() != newCfg.ResponseCache.EffectiveBackend() {
+		changes = append(changes, fmt.Sprintf("response-cache.backend: %s -> %s", oldCfg.ResponseCache.EffectiveBackend(), newCfg.ResponseCache.EffectiveBackend()))
+	}
+	if strings.TrimSpace(oldCfg.ResponseCache.RedisURL) != strings.TrimSpace(newCfg.ResponseCache.RedisURL) {
+		changes = append(changes, "response-cache.redis-url: updated")
+	}
+	if oldCfg.ResponseCache.TTLSeconds != newCfg.ResponseCache.TTLSeconds {
+		changes = append(changes, fmt.Sprintf("response-cache.ttl-seconds: %d -> %d", oldCfg.ResponseCache.TTLSeconds, newCfg.ResponseCache.TTLSeconds))
+	}
+	if oldCfg.ResponseCache.MaxEntries != newCfg.ResponseCache.MaxEntries {
+		changes = append(changes, fmt.Sprintf("response-cache.max-entries: %d -> %d", oldCfg.ResponseCache.MaxEntries, newCfg.ResponseCache.MaxEntries))
+	}
+	if oldCfg.ResponseCache.MaxEntrySizeKB != newCfg.ResponseCache.MaxEntrySizeKB {
+		changes = append(changes, fmt.Sprintf("response-cache.max-entry-size-kb: %d -> %d", oldCfg.ResponseCache.MaxEntrySizeKB, newCfg.ResponseCache.MaxEntrySizeKB))
+	}
+	if oldCfg.ResponseCache.ShareAcrossKeys != newCfg.ResponseCache.ShareAcrossKeys {
+		changes = append(changes, fmt.Sprintf("response-cache.share-across-keys: %t -> %t", oldCfg.ResponseCache.ShareAcrossKeys, newCfg.ResponseCache.ShareAcrossKeys))
+	}
+	if oldCfg.ResponsesStore.Enable != newCfg.ResponsesStore.Enable {
+		changes = append(changes, fmt.Sprintf("responses-store.enable: %t -> %t", oldCfg.ResponsesStore.Enable, newCfg.ResponsesStore.Enable))
+	}
+	if oldCfg.ResponsesStore.EffectiveBackend() != newCfg.ResponsesStore.EffectiveBackend() {
+		changes = append(changes, fmt.Sprintf("responses-store.backend: %s -> %s", oldCfg.ResponsesStore.EffectiveBackend(), newCfg.ResponsesStore.EffectiveBackend()))
+	}
+	if strings.TrimSpace(oldCfg.ResponsesStore.Dir) != strings.TrimSpace(newCfg.ResponsesStore.Dir) {
+		changes = append(changes, fmt.Sprintf("responses-store.dir: %s -> %s", strings.TrimSpace(oldCfg.ResponsesStore.Dir), strings.TrimSpace(newCfg.ResponsesStore.Dir)))
+	}
+	if oldCfg.ResponsesStore.TTLSeconds != newCfg.ResponsesStore.TTLSeconds {
+		changes = append(changes, fmt.Sprintf("responses-store.ttl-seconds: %d -> %d", oldCfg.ResponsesStore.TTLSeconds, newCfg.ResponsesStore.TTLSeconds))
+	}
+	if oldCfg.ResponsesStore.MaxEntries != newCfg.ResponsesStore.MaxEntries {
+		changes = append(changes, fmt.Sprintf("responses-store.max-entries: %d -> %d", oldCfg.ResponsesStore.MaxEntries, newCfg.ResponsesStore.MaxEntries))
+	}
+	if oldCfg.ResponsesStore.MaxEntrySizeKB != newCfg.ResponsesStore.MaxEntrySizeKB {
+		changes = append(changes, fmt.Sprintf("responses-store.max-entry-size-kb: %d -> %d", oldCfg.ResponsesStore.MaxEntrySizeKB, newCfg.ResponsesStore.MaxEntrySizeKB))
+	}
 	if oldCfg.DisableCooling != newCfg.DisableCooling {
 		changes = append(changes, fmt.Sprintf("disable-cooling: %t -> %t", oldCfg.DisableCooling, newCfg.DisableCooling))
 	}
@@ -148,6 +230,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if oldCfg.Routing.Strategy != newCfg.Routing.Strategy {
 		changes = append(changes, fmt.Sprintf("routing.strategy: %s -> %s", oldCfg.Routing.Strategy, newCfg.Routing.Strategy))
 	}
+	if oldCfg.Routing.QuotaWindowThreshold != newCfg.Routing.QuotaWindowThreshold {
+		changes = append(changes, fmt.Sprintf("routing.quota-window-threshold: %v -> %v", oldCfg.Routing.QuotaWindowThreshold, newCfg.Routing.QuotaWindowThreshold))
+	}
 	if !reflect.DeepEqual(oldCfg.Payload, newCfg.Payload) {
 		changes = appendPayloadConfigChanges(changes, oldCfg.Payload, newCfg.Payload)
 	}
@@ -155,8 +240,14 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	// API keys (redacted) and counts
 	if len(oldCfg.APIKeys) != len(newCfg.APIKeys) {
 		changes = append(changes, fmt.Sprintf("api-keys count: %d -> %d", len(oldCfg.APIKeys), len(newCfg.APIKeys)))
-	} else if !reflect.DeepEqual(trimStrings(oldCfg.APIKeys), trimStrings(newCfg.APIKeys)) {
+	} else if !reflect.DeepEqual(trimStrings(config.ClientAPIKeyValues(oldCfg.APIKeys)), trimStrings(config.ClientAPIKeyValues(newCfg.APIKeys))) {
 		changes = append(changes, "api-keys: values updated (count unchanged, redacted)")
+	} else {
+		for i := range oldCfg.APIKeys {
+			if !reflect.DeepEqual(oldCfg.APIKeys[i], newCfg.APIKeys[i]) {
+				changes = append(changes, fmt.Sprintf("api-keys[%d]: policy updated", i))
+			}
+		}
 	}
 	if len(oldCfg.GeminiKey) != len(newCfg.GeminiKey) {
 		changes = append(changes, fmt.Sprintf("gemini-api-key count: %d -> %d", len(oldCfg.GeminiKey), len(newCfg.GeminiKey)))
@@ -262,6 +353,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if oldExcluded.hash != newExcluded.hash {
 				changes = append(changes, fmt.Sprintf("claude[%d].excluded-models: updated (%d -> %d entries)", i, oldExcluded.count, newExcluded.count))
 			}
+			if change := DescribeDiscoverModelsChange(o.DiscoverModels, n.DiscoverModels); change != "" {
+				changes = append(changes, fmt.Sprintf("claude[%d].discover-models: %s", i, change))
+			}
 			if o.RebuildMidSystemMessage != n.RebuildMidSystemMessage {
 				changes = append(changes, fmt.Sprintf("claude[%d].rebuild-mid-system-message: %t -> %t", i, o.RebuildMidSystemMessage, n.RebuildMidSystemMessage))
 			}
@@ -322,6 +416,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if oldExcluded.hash != newExcluded.hash {
 				changes = append(changes, fmt.Sprintf("codex[%d].excluded-models: updated (%d -> %d entries)", i, oldExcluded.count, newExcluded.count))
 			}
+			if change := DescribeDiscoverModelsChange(o.DiscoverModels, n.DiscoverModels); change != "" {
+				changes = append(changes, fmt.Sprintf("codex[%d].discover-models: %s", i, change))
+			}
 			changes = appendOptionalIntChange(changes, fmt.Sprintf("codex[%d].request-retry", i), o.RequestRetry, n.RequestRetry)
 		}
 	}
@@ -366,6 +463,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if oldExcluded.hash != newExcluded.hash {
 				changes = append(changes, fmt.Sprintf("xai[%d].excluded-models: updated (%d -> %d entries)", i, oldExcluded.count, newExcluded.count))
 			}
+			if change := DescribeDiscoverModelsChange(o.DiscoverModels, n.DiscoverModels); change != "" {
+				changes = append(changes, fmt.Sprintf("xai[%d].discover-models: %s", i, change))
+			}
 		}
 	}
 
@@ -375,6 +475,26 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if entries, _ := DiffOAuthModelAliasChanges(oldCfg.OAuthModelAlias, newCfg.OAuthModelAlias); len(entries) > 0 {
 		changes = append(changes, entries...)
 	}
+	if !reflect.DeepEqual(oldCfg.ModelFallbacks, newCfg.ModelFallbacks) {
+		changes = append(changes, fmt.Sprintf("model-fallbacks: updated (%d -> %d chains)", len(oldCfg.ModelFallbacks), len(newCfg.ModelFallbacks)))
+	}
+	if !reflect.DeepEqual(oldCfg.StreamHedging, newCfg.StreamHedging) {
+		changes = append(changes, fmt.Sprintf("stream-hedging: updated (%d -> %d rules)", len(oldCfg.StreamHedging), len(newCfg.StreamHedging)))
+	}
+	if !reflect.DeepEqual(oldCfg.ModelPricing, newCfg.ModelPricing) {
+		changes = append(changes, fmt.Sprintf("model-pricing: updated (%d -> %d overrides)", len(oldCfg.ModelPricing), len(newCfg.ModelPricing)))
+	}
+	if !reflect.DeepEqual(oldCfg.ContextGuard, newCfg.ContextGuard) {
+		changes = append(changes, fmt.Sprintf("context-guard: updated (enable %t -> %t, strategy %s -> %s)",
+			oldCfg.ContextGuard.Enable, newCfg.ContextGuard.Enable,
+			oldCfg.ContextGuard.EffectiveStrategy(), newCfg.ContextGuard.EffectiveStrategy()))
+	}
+	if !reflect.DeepEqual(oldCfg.ModelCatalog, newCfg.ModelCatalog) {
+		changes = append(changes, fmt.Sprintf("model-catalog: updated (%d -> %d sources, %d -> %d overlays, %d -> %d public keys)",
+			len(oldCfg.ModelCatalog.Sources), len(newCfg.ModelCatalog.Sources),
+			len(oldCfg.ModelCatalog.Overlays), len(newCfg.ModelCatalog.Overlays),
+			len(oldCfg.ModelCatalog.PublicKeys), len(newCfg.ModelCatalog.PublicKeys)))
+	}
 
 	// Remote management (never print the key)
 	if oldCfg.RemoteManagement.AllowRemote != newCfg.RemoteManagement.AllowRemote {
@@ -401,6 +521,12 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			changes = append(changes, "remote-management.secret-key: updated")
 		}
 	}
+	if keys := diffManagementKeys(oldCfg.RemoteManagement.Keys, newCfg.RemoteManagement.Keys); len(keys) > 0 {
+		changes = append(changes, keys...)
+	}
+	if plugins := diffPluginsConfig(oldCfg.Plugins, newCfg.Plugins); len(plugins) > 0 {
+		changes = append(changes, plugins...)
+	}
 
 	// OpenAI compatibility providers (summarized)
 	if compat := DiffOpenAICompatibility(oldCfg.OpenAICompatibility, newCfg.OpenAICompatibility); len(compat) > 0 {
@@ -447,6 +573,84 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 		}
 	}
 
+	// AWS Bedrock credentials
+	if len(oldCfg.BedrockKey) != len(newCfg.BedrockKey) {
+		changes = append(changes, fmt.Sprintf("bedrock-api-key count: %d -> %d", len(oldCfg.BedrockKey), len(newCfg.BedrockKey)))
+	} else {
+		for i := range oldCfg.BedrockKey {
+			o := oldCfg.BedrockKey[i]
+			n := newCfg.BedrockKey[i]
+			if o.Region != n.Region {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].region: %s -> %s", i, o.Region, n.Region))
+			}
+			if strings.TrimSpace(o.BaseURL) != strings.TrimSpace(n.BaseURL) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].base-url: %s -> %s", i, formatURL(o.BaseURL), formatURL(n.BaseURL)))
+			}
+			if strings.TrimSpace(o.ProxyURL) != strings.TrimSpace(n.ProxyURL) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].proxy-url: %s -> %s", i, formatProxyURL(o.ProxyURL), formatProxyURL(n.ProxyURL)))
+			}
+			if strings.TrimSpace(o.Prefix) != strings.TrimSpace(n.Prefix) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].prefix: %s -> %s", i, strings.TrimSpace(o.Prefix), strings.TrimSpace(n.Prefix)))
+			}
+			changes = appendOptionalBoolChange(changes, fmt.Sprintf("bedrock[%d].disable-cooling", i), o.DisableCooling, n.DisableCooling)
+			if o.APIKey != n.APIKey || o.AccessKeyID != n.AccessKeyID || o.SecretAccessKey != n.SecretAccessKey || o.SessionToken != n.SessionToken {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].credentials: updated", i))
+			}
+			oldModels := SummarizeClaudeModels(o.Models)
+			newModels := SummarizeClaudeModels(n.Models)
+			if oldModels.hash != newModels.hash {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].models: updated (%d -> %d entries)", i, oldModels.count, newModels.count))
+			}
+			oldExcluded := SummarizeExcludedModels(o.ExcludedModels)
+			newExcluded := SummarizeExcludedModels(n.ExcludedModels)
+			if oldExcluded.hash != newExcluded.hash {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].excluded-models: updated (%d -> %d entries)", i, oldExcluded.count, newExcluded.count))
+			}
+			if !equalStringMap(o.Headers, n.Headers) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].headers: updated", i))
+			}
+			changes = appendOptionalIntChange(changes, fmt.Sprintf("bedrock[%d].request-retry", i), o.RequestRetry, n.RequestRetry)
+		}
+	}
+
+	// Azure OpenAI resources
+	if len(oldCfg.AzureOpenAIKey) != len(newCfg.AzureOpenAIKey) {
+		changes = append(changes, fmt.Sprintf("azure-openai count: %d -> %d", len(oldCfg.AzureOpenAIKey), len(newCfg.AzureOpenAIKey)))
+	} else {
+		for i := range oldCfg.AzureOpenAIKey {
+			o := oldCfg.AzureOpenAIKey[i]
+			n := newCfg.AzureOpenAIKey[i]
+			if strings.TrimSpace(o.Endpoint) != strings.TrimSpace(n.Endpoint) {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].endpoint: %s -> %s", i, formatURL(o.Endpoint), formatURL(n.Endpoint)))
+			}
+			if o.APIVersion != n.APIVersion {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].api-version: %s -> %s", i, o.APIVersion, n.APIVersion))
+			}
+			if strings.TrimSpace(o.ProxyURL) != strings.TrimSpace(n.ProxyURL) {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].proxy-url: %s -> %s", i, formatProxyURL(o.ProxyURL), formatProxyURL(n.ProxyURL)))
+			}
+			if strings.TrimSpace(o.Prefix) != strings.TrimSpace(n.Prefix) {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].prefix: %s -> %s", i, strings.TrimSpace(o.Prefix), strings.TrimSpace(n.Prefix)))
+			}
+			changes = appendOptionalBoolChange(changes, fmt.Sprintf("azure-openai[%d].disable-cooling", i), o.DisableCooling, n.DisableCooling)
+			if o.APIKey != n.APIKey || o.TenantID != n.TenantID || o.ClientID != n.ClientID || o.ClientSecret != n.ClientSecret || o.AuthorityHost != n.AuthorityHost || o.Scope != n.Scope {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].credentials: updated", i))
+			}
+			if !reflect.DeepEqual(o.Deployments, n.Deployments) {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].deployments: updated (%d -> %d entries)", i, len(o.Deployments), len(n.Deployments)))
+			}
+			oldExcluded := SummarizeExcludedModels(o.ExcludedModels)
+			newExcluded := SummarizeExcludedModels(n.ExcludedModels)
+			if oldExcluded.hash != newExcluded.hash {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].excluded-models: updated (%d -> %d entries)", i, oldExcluded.count, newExcluded.count))
+			}
+			if !equalStringMap(o.Headers, n.Headers) {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].headers: updated", i))
+			}
+			changes = appendOptionalIntChange(changes, fmt.Sprintf("azure-openai[%d].request-retry", i), o.RequestRetry, n.RequestRetry)
+		}
+	}
+
 	return changes
 }
 
@@ -580,3 +784,78 @@ func formatURL(raw string) string {
 	}
 	return scheme + "://" + host
 }
+
+// diffManagementKeys summarizes remote-management.keys changes by key name. Key values
+// are not compared: plaintext keys are re-hashed with a fresh salt on every load.
+func diffManagementKeys(oldKeys, newKeys []config.ManagementKey) []string {
+	oldByName := make(map[string]config.ManagementKey, len(oldKeys))
+	for _, key := range oldKeys {
+		oldByName[key.Name] = key
+	}
+	changes := make([]string, 0)
+	seen := make(map[string]struct{}, len(newKeys))
+	for _, key := range newKeys {
+		seen[key.Name] = struct{}{}
+		prev, exists := oldByName[key.Name]
+		if !exists {
+			changes = append(changes, fmt.Sprintf("remote-management.keys[%s]: created (role %s)", key.Name, key.Role))
+			continue
+		}
+		if prev.Role != key.Role {
+			changes = append(changes, fmt.Sprintf("remote-management.keys[%s].role: %s -> %s", key.Name, prev.Role, key.Role))
+		}
+		if prev.ExpiresAt != key.ExpiresAt {
+			changes = append(changes, fmt.Sprintf("remote-management.keys[%s].expires-at: %q -> %q", key.Name, prev.ExpiresAt, key.ExpiresAt))
+		}
+		if !reflect.DeepEqual(prev.AllowedIPs, key.AllowedIPs) {
+			changes = append(changes, fmt.Sprintf("remote-management.keys[%s].allowed-ips: %v -> %v", key.Name, prev.AllowedIPs, key.AllowedIPs))
+		}
+	}
+	for _, key := range oldKeys {
+		if _, exists := seen[key.Name]; !exists {
+			changes = append(changes, fmt.Sprintf("remote-management.keys[%s]: deleted", key.Name))
+		}
+	}
+	return changes
+}
+
+// diffPluginsConfig summarizes plugin host and per-plugin instance changes. Plugin-owned
+// settings are not printed because they may hold credentials.
+func diffPluginsConfig(oldPlugins, newPlugins config.PluginsConfig) []string {
+	changes := make([]string, 0)
+	if oldPlugins.Enabled != newPlugins.Enabled {
+		changes = append(changes, fmt.Sprintf("plugins.enabled: %t -> %t", oldPlugins.Enabled, newPlugins.Enabled))
+	}
+	if strings.TrimSpace(oldPlugins.Dir) != strings.TrimSpace(newPlugins.Dir) {
+		changes = append(changes, fmt.Sprintf("plugins.dir: %s -> %s", strings.TrimSpace(oldPlugins.Dir), strings.TrimSpace(newPlugins.Dir)))
+	}
+	if !reflect.DeepEqual(trimStrings(oldPlugins.StoreSources), trimStrings(newPlugins.StoreSources)) {
+		changes = append(changes, fmt.Sprintf("plugins.store-sources: %d -> %d entries", len(oldPlugins.StoreSources), len(newPlugins.StoreSources)))
+	}
+	ids := make([]string, 0, len(oldPlugins.Configs)+len(newPlugins.Configs))
+	for id := range oldPlugins.Configs {
+		ids = append(ids, id)
+	}
+	for id := range newPlugins.Configs {
+		if _, exists := oldPlugins.Configs[id]; !exists {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		prev, hadPrev := oldPlugins.Configs[id]
+		next, hasNext := newPlugins.Configs[id]
+		switch {
+		case !hadPrev:
+			changes = append(changes, fmt.Sprintf("plugins.configs[%s]: added (enabled %s)", id, formatOptionalBool(next.Enabled)))
+		case !hasNext:
+			changes = append(changes, fmt.Sprintf("plugins.configs[%s]: removed", id))
+		default:
+			changes = appendOptionalBoolChange(changes, fmt.Sprintf("plugins.configs[%s].enabled", id), prev.Enabled, next.Enabled)
+			if prev.Priority != next.Priority {
+				changes = append(changes, fmt.Sprintf("plugins.configs[%s].priority: %d -> %d", id, prev.Priority, next.Priority))
+			}
+		}
+	}
+	return changes
+}
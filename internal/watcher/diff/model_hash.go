@@ -38,6 +38,16 @@ func ComputeGeminiModelsHash(models []config.GeminiModel) string {
 	return modelconfig.ComputeGeminiModelsHash(models)
 }
 
+// ComputeAzureOpenAIDeploymentsHash returns a stable hash for Azure OpenAI deployment mappings.
+func ComputeAzureOpenAIDeploymentsHash(deployments []config.AzureOpenAIDeployment) string {
+	if len(deployments) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(deployments)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // ComputeExcludedModelsHash returns a normalized hash for excluded model lists.
 func ComputeExcludedModelsHash(excluded []string) string {
 	if len(excluded) == 0 {
@@ -98,6 +98,9 @@ func describeOpenAICompatibilityUpdate(oldEntry, newEntry config.OpenAICompatibi
 	if !equalStringMap(oldEntry.Headers, newEntry.Headers) {
 		details = append(details, "headers updated")
 	}
+	if change := DescribeDiscoverModelsChange(oldEntry.DiscoverModels, newEntry.DiscoverModels); change != "" {
+		details = append(details, "discover-models "+change)
+	}
 	if len(details) == 0 {
 		return ""
 	}
@@ -127,6 +130,80 @@ func countOpenAIModels(models []config.OpenAICompatibilityModel) int {
 	return count
 }
 
+// ComputeDiscoverModelsHash returns a stable hash of the discover-models settings, or ""
+// when discovery is disabled. Config synthesis stores it on the auth so that a
+// settings change re-registers the credential's models.
+func ComputeDiscoverModelsHash(cfg *config.DiscoverModelsConfig) string {
+	if !cfg.Enabled() {
+		return ""
+	}
+	include := normalizeDiscoverGlobs(cfg.Include)
+	exclude := normalizeDiscoverGlobs(cfg.Exclude)
+	parts := []string{
+		fmt.Sprintf("interval=%d", int(cfg.EffectiveInterval().Seconds())),
+		"path=" + cfg.EffectivePath(""),
+		"include=" + strings.Join(include, ","),
+		"exclude=" + strings.Join(exclude, ","),
+		"alias=" + strings.TrimSpace(cfg.Alias),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// DescribeDiscoverModelsChange summarizes a discover-models settings change, or returns
+// "" when the effective settings are equal.
+func DescribeDiscoverModelsChange(oldCfg, newCfg *config.DiscoverModelsConfig) string {
+	if oldCfg.Enabled() != newCfg.Enabled() {
+		return fmt.Sprintf("%t -> %t", oldCfg.Enabled(), newCfg.Enabled())
+	}
+	if ComputeDiscoverModelsHash(oldCfg) != ComputeDiscoverModelsHash(newCfg) {
+		return "updated"
+	}
+	return ""
+}
+
+// DiffDiscoveredModels compares two discovered model sets, expressed as name/alias
+// pairs, and reports whether they differ together with a short summary. The order in
+// which the upstream lists models does not matter.
+func DiffDiscoveredModels(oldModels, newModels []config.OpenAICompatibilityModel) (bool, string) {
+	if ComputeOpenAICompatModelsHash(sortedDiscoveredModels(oldModels)) == ComputeOpenAICompatModelsHash(sortedDiscoveredModels(newModels)) {
+		return false, ""
+	}
+	oldSet := make(map[string]struct{}, len(oldModels))
+	for _, model := range oldModels {
+		oldSet[strings.ToLower(strings.TrimSpace(model.Alias))] = struct{}{}
+	}
+	added := 0
+	for _, model := range newModels {
+		key := strings.ToLower(strings.TrimSpace(model.Alias))
+		if _, ok := oldSet[key]; ok {
+			delete(oldSet, key)
+			continue
+		}
+		added++
+	}
+	return true, fmt.Sprintf("models %d -> %d (+%d, -%d)", countOpenAIModels(oldModels), countOpenAIModels(newModels), added, len(oldSet))
+}
+
+func sortedDiscoveredModels(models []config.OpenAICompatibilityModel) []config.OpenAICompatibilityModel {
+	sorted := append([]config.OpenAICompatibilityModel(nil), models...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i].Alias) < strings.ToLower(sorted[j].Alias)
+	})
+	return sorted
+}
+
+func normalizeDiscoverGlobs(patterns []string) []string {
+	out := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if trimmed := strings.ToLower(strings.TrimSpace(pattern)); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
 func openAICompatKey(entry config.OpenAICompatibility, index int) (string, string) {
 	name := strings.TrimSpace(entry.Name)
 	if name != "" {
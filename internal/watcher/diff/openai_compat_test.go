@@ -222,3 +222,31 @@ func TestOpenAICompatKeyUsesModelNameWhenAliasEmpty(t *testing.T) {
 		t.Fatalf("expected model-name fallback, got %s/%s", key, label)
 	}
 }
+
+func TestDiffOpenAICompatibilityDiscoverModels(t *testing.T) {
+	oldList := []config.OpenAICompatibility{{Name: "provider-a"}}
+	newList := []config.OpenAICompatibility{{Name: "provider-a", DiscoverModels: &config.DiscoverModelsConfig{Enable: true}}}
+	expectContains(t, DiffOpenAICompatibility(oldList, newList), "provider updated: provider-a (discover-models false -> true)")
+
+	updated := []config.OpenAICompatibility{{Name: "provider-a", DiscoverModels: &config.DiscoverModelsConfig{Enable: true, Include: []string{"gpt-*"}}}}
+	expectContains(t, DiffOpenAICompatibility(newList, updated), "provider updated: provider-a (discover-models updated)")
+
+	// Spelling out the defaults does not count as a change.
+	explicit := []config.OpenAICompatibility{{Name: "provider-a", DiscoverModels: &config.DiscoverModelsConfig{Enable: true, IntervalSeconds: 3600, Path: "models"}}}
+	if changes := DiffOpenAICompatibility(newList, explicit); len(changes) != 0 {
+		t.Fatalf("changes = %v, want none", changes)
+	}
+}
+
+func TestDiffDiscoveredModels(t *testing.T) {
+	oldModels := []config.OpenAICompatibilityModel{{Name: "a", Alias: "a"}, {Name: "b", Alias: "b"}}
+	newModels := []config.OpenAICompatibilityModel{{Name: "b", Alias: "b"}, {Name: "c", Alias: "c"}, {Name: "d", Alias: "d"}}
+
+	if changed, _ := DiffDiscoveredModels(oldModels, []config.OpenAICompatibilityModel{{Name: "b", Alias: "b"}, {Name: "a", Alias: "a"}}); changed {
+		t.Fatal("reordered set reported as changed")
+	}
+	changed, summary := DiffDiscoveredModels(oldModels, newModels)
+	if !changed || summary != "models 2 -> 3 (+2, -1)" {
+		t.Fatalf("changed = %t, summary = %q", changed, summary)
+	}
+}
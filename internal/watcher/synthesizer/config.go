@@ -56,6 +56,10 @@ func (s *ConfigSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth,
 	out = append(out, s.synthesizeOpenAICompat(ctx)...)
 	// Vertex-compat
 	out = append(out, s.synthesizeVertexCompat(ctx)...)
+	// AWS Bedrock
+	out = append(out, s.synthesizeBedrockKeys(ctx)...)
+	// Azure OpenAI
+	out = append(out, s.synthesizeAzureOpenAIKeys(ctx)...)
 
 	return out, nil
 }
@@ -172,6 +176,9 @@ func (s *ConfigSynthesizer) synthesizeClaudeKeys(ctx *SynthesisContext) []*corea
 		if hash := diff.ComputeClaudeModelsHash(ck.Models); hash != "" {
 			attrs["models_hash"] = hash
 		}
+		if hash := diff.ComputeDiscoverModelsHash(ck.DiscoverModels); hash != "" {
+			attrs["discover_models_hash"] = hash
+		}
 		addConfigHeadersToAttrs(ck.Headers, attrs)
 		proxyURL := strings.TrimSpace(ck.ProxyURL)
 		a := &coreauth.Auth{
@@ -247,6 +254,9 @@ func (s *ConfigSynthesizer) synthesizeCodexStyleKeys(ctx *SynthesisContext, entr
 		if hash := diff.ComputeCodexModelsHash(entry.Models); hash != "" {
 			attrs["models_hash"] = hash
 		}
+		if hash := diff.ComputeDiscoverModelsHash(entry.DiscoverModels); hash != "" {
+			attrs["discover_models_hash"] = hash
+		}
 		addConfigHeadersToAttrs(entry.Headers, attrs)
 		a := &coreauth.Auth{
 			ID:         id,
@@ -321,6 +331,9 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 			if hash := diff.ComputeOpenAICompatModelsHash(compat.Models); hash != "" {
 				attrs["models_hash"] = hash
 			}
+			if hash := diff.ComputeDiscoverModelsHash(compat.DiscoverModels); hash != "" {
+				attrs["discover_models_hash"] = hash
+			}
 			addConfigHeadersToAttrs(compat.Headers, attrs)
 			a := &coreauth.Auth{
 				ID:         id,
@@ -363,6 +376,9 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 			if hash := diff.ComputeOpenAICompatModelsHash(compat.Models); hash != "" {
 				attrs["models_hash"] = hash
 			}
+			if hash := diff.ComputeDiscoverModelsHash(compat.DiscoverModels); hash != "" {
+				attrs["discover_models_hash"] = hash
+			}
 			addConfigHeadersToAttrs(compat.Headers, attrs)
 			a := &coreauth.Auth{
 				ID:         id,
@@ -443,3 +459,144 @@ func (s *ConfigSynthesizer) synthesizeVertexCompat(ctx *SynthesisContext) []*cor
 	}
 	return out
 }
+
+// synthesizeBedrockKeys creates Auth entries for AWS Bedrock credentials.
+func (s *ConfigSynthesizer) synthesizeBedrockKeys(ctx *SynthesisContext) []*coreauth.Auth {
+	cfg := ctx.Config
+	now := ctx.Now
+	idGen := ctx.IDGenerator
+
+	out := make([]*coreauth.Auth, 0, len(cfg.BedrockKey))
+	for i := range cfg.BedrockKey {
+		bk := &cfg.BedrockKey[i]
+		key := bk.GetAPIKey()
+		if key == "" {
+			continue
+		}
+		base := strings.TrimSpace(bk.BaseURL)
+		proxyURL := strings.TrimSpace(bk.ProxyURL)
+		id, token := idGen.Next("bedrock:apikey", key, bk.Region, base, proxyURL)
+		attrs := map[string]string{
+			"source":       fmt.Sprintf("config:bedrock[%s]", token),
+			"api_key":      key,
+			"region":       bk.Region,
+			"config_index": strconv.Itoa(i),
+		}
+		if bk.APIKey == "" {
+			attrs["access_key_id"] = bk.AccessKeyID
+			attrs["secret_access_key"] = bk.SecretAccessKey
+			if bk.SessionToken != "" {
+				attrs["session_token"] = bk.SessionToken
+			}
+		}
+		if base != "" {
+			attrs["base_url"] = base
+		}
+		if bk.Priority != 0 {
+			attrs["priority"] = strconv.Itoa(bk.Priority)
+		}
+		addWeightToAttrs(bk.Weight, attrs)
+		if hash := diff.ComputeClaudeModelsHash(bk.Models); hash != "" {
+			attrs["models_hash"] = hash
+		}
+		addConfigHeadersToAttrs(bk.Headers, attrs)
+		metadata := map[string]any{}
+		if bk.DisableCooling != nil {
+			metadata["disable_cooling"] = *bk.DisableCooling
+		}
+		addRequestRetryToMetadata(bk.RequestRetry, metadata)
+		a := &coreauth.Auth{
+			ID:         id,
+			Provider:   "bedrock",
+			Label:      "bedrock-apikey",
+			Prefix:     strings.TrimSpace(bk.Prefix),
+			Status:     coreauth.StatusActive,
+			ProxyURL:   proxyURL,
+			Attributes: attrs,
+			Metadata:   metadata,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		ApplyAuthExcludedModelsMeta(a, cfg, bk.ExcludedModels, "apikey")
+		if len(a.Metadata) == 0 {
+			a.Metadata = nil
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// synthesizeAzureOpenAIKeys creates Auth entries for Azure OpenAI resources. Entries
+// authenticated through Entra ID are OAuth-kind so the auto-refresh loop keeps their
+// bearer token fresh.
+func (s *ConfigSynthesizer) synthesizeAzureOpenAIKeys(ctx *SynthesisContext) []*coreauth.Auth {
+	cfg := ctx.Config
+	now := ctx.Now
+	idGen := ctx.IDGenerator
+
+	out := make([]*coreauth.Auth, 0, len(cfg.AzureOpenAIKey))
+	for i := range cfg.AzureOpenAIKey {
+		ak := &cfg.AzureOpenAIKey[i]
+		key := ak.GetAPIKey()
+		if key == "" || ak.Endpoint == "" {
+			continue
+		}
+		proxyURL := strings.TrimSpace(ak.ProxyURL)
+		id, token := idGen.Next("azure-openai:apikey", key, ak.TenantID, ak.Endpoint, proxyURL)
+		attrs := map[string]string{
+			"source":       fmt.Sprintf("config:azure-openai[%s]", token),
+			"base_url":     ak.Endpoint,
+			"api_version":  ak.APIVersion,
+			"config_index": strconv.Itoa(i),
+		}
+		authKind := "apikey"
+		label := "azure-openai-apikey"
+		if ak.UsesEntraID() {
+			authKind = "oauth"
+			label = "azure-openai-entra"
+			attrs["tenant_id"] = ak.TenantID
+			attrs["client_id"] = ak.ClientID
+			attrs["client_secret"] = ak.ClientSecret
+			if ak.AuthorityHost != "" {
+				attrs["authority_host"] = ak.AuthorityHost
+			}
+			if ak.Scope != "" {
+				attrs["scope"] = ak.Scope
+			}
+		} else {
+			attrs["api_key"] = key
+		}
+		if ak.Priority != 0 {
+			attrs["priority"] = strconv.Itoa(ak.Priority)
+		}
+		addWeightToAttrs(ak.Weight, attrs)
+		if hash := diff.ComputeAzureOpenAIDeploymentsHash(ak.Deployments); hash != "" {
+			attrs["models_hash"] = hash
+		}
+		addConfigHeadersToAttrs(ak.Headers, attrs)
+		metadata := map[string]any{}
+		if ak.DisableCooling != nil {
+			metadata["disable_cooling"] = *ak.DisableCooling
+		}
+		addRequestRetryToMetadata(ak.RequestRetry, metadata)
+		addRequestScopedErrorsToMetadata(ak.RequestScopedErrors, metadata)
+		a := &coreauth.Auth{
+			ID:         id,
+			Provider:   "azure-openai",
+			Label:      label,
+			Prefix:     strings.TrimSpace(ak.Prefix),
+			Status:     coreauth.StatusActive,
+			ProxyURL:   proxyURL,
+			Attributes: attrs,
+			Metadata:   metadata,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		ApplyAuthExcludedModelsMeta(a, cfg, ak.ExcludedModels, authKind)
+		if len(a.Metadata) == 0 {
+			a.Metadata = nil
+		}
+		out = append(out, a)
+	}
+	return out
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/access/keypolicy"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/config"
+	"github.com/tidwall/sjson"
+	"golang.org/x/net/context"
+)
+
+// apiKeyPolicyFromContext returns the api-keys policy of the client that issued the request.
+func apiKeyPolicyFromContext(ctx context.Context) (config.ClientAPIKey, bool) {
+	if ctx == nil {
+		return config.ClientAPIKey{}, false
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok {
+		return config.ClientAPIKey{}, false
+	}
+	return apiKeyPolicyFromGin(ginCtx)
+}
+
+func apiKeyPolicyFromGin(c *gin.Context) (config.ClientAPIKey, bool) {
+	if c == nil {
+		return config.ClientAPIKey{}, false
+	}
+	value, exists := c.Get("userApiKey")
+	if !exists || value == nil {
+		return config.ClientAPIKey{}, false
+	}
+	return keypolicy.Default().Lookup(fmt.Sprint(value))
+}
+
+// applyAPIKeyModelPolicy checks modelName against the calling key's model allow-list
+// and returns the model name with the key's forced prefix applied.
+func applyAPIKeyModelPolicy(ctx context.Context, modelName string) (string, *interfaces.ErrorMessage) {
+	policy, ok := apiKeyPolicyFromContext(ctx)
+	if !ok {
+		return modelName, nil
+	}
+	routed, unscoped := policy.ScopeModel(modelName)
+	if !policy.AllowsModel(strings.TrimSpace(thinking.ParseSuffix(unscoped).ModelName)) {
+		return "", apiKeyPolicyError(fmt.Sprintf("model %s is not allowed for this API key", unscoped), "model_not_allowed")
+	}
+	return routed, nil
+}
+
+// filterAPIKeyPolicyProviders drops providers the calling key may not be routed to.
+func filterAPIKeyPolicyProviders(ctx context.Context, providers []string, modelName string) ([]string, *interfaces.ErrorMessage) {
+	policy, ok := apiKeyPolicyFromContext(ctx)
+	if !ok || len(policy.Providers) == 0 {
+		return providers, nil
+	}
+	allowed := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		if policy.AllowsProvider(provider) {
+			allowed = append(allowed, provider)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil, apiKeyPolicyError(fmt.Sprintf("model %s is not served by a provider allowed for this API key", modelName), "provider_not_allowed")
+	}
+	return allowed, nil
+}
+
+// FilterModelsForAPIKey removes models the calling key cannot use from a model
+// listing. Entries are identified by their "id" field, or "name" for Gemini listings.
+func (h *BaseAPIHandler) FilterModelsForAPIKey(c *gin.Context, models []map[string]any) []map[string]any {
+	policy, ok := apiKeyPolicyFromGin(c)
+	if !ok {
+		return models
+	}
+	prefix := strings.Trim(strings.TrimSpace(policy.Prefix), "/")
+	out := make([]map[string]any, 0, len(models))
+	for _, model := range models {
+		id, _ := model["id"].(string)
+		if id == "" {
+			name, _ := model["name"].(string)
+			id = strings.TrimPrefix(name, "models/")
+		}
+		if id == "" {
+			continue
+		}
+		unscoped := id
+		if prefix != "" {
+			rest, hasPrefix := strings.CutPrefix(id, prefix+"/")
+			if !hasPrefix {
+				continue
+			}
+			unscoped = rest
+		}
+		if !policy.AllowsModel(unscoped) {
+			continue
+		}
+		if len(policy.Providers) > 0 && !apiKeyPolicyAllowsAnyProvider(policy, util.GetProviderName(id)) {
+			continue
+		}
+		out = append(out, model)
+	}
+	return out
+}
+
+// FilterModelInfosForAPIKey is FilterModelsForAPIKey for registry model records.
+func (h *BaseAPIHandler) FilterModelInfosForAPIKey(c *gin.Context, models []*registry.ModelInfo) []*registry.ModelInfo {
+	if _, ok := apiKeyPolicyFromGin(c); !ok {
+		return models
+	}
+	entries := make([]map[string]any, 0, len(models))
+	index := make(map[string]*registry.ModelInfo, len(models))
+	for _, model := range models {
+		if model == nil {
+			continue
+		}
+		entries = append(entries, map[string]any{"id": model.ID})
+		index[model.ID] = model
+	}
+	filtered := h.FilterModelsForAPIKey(c, entries)
+	out := make([]*registry.ModelInfo, 0, len(filtered))
+	for _, entry := range filtered {
+		id, _ := entry["id"].(string)
+		out = append(out, index[id])
+	}
+	return out
+}
+
+func apiKeyPolicyAllowsAnyProvider(policy config.ClientAPIKey, providers []string) bool {
+	for _, provider := range providers {
+		if policy.AllowsProvider(provider) {
+			return true
+		}
+	}
+	return false
+}
+
+func apiKeyPolicyError(message, code string) *interfaces.ErrorMessage {
+	body := `{"error":{"message":"","type":"permission_error","code":""}}`
+	body, errSet := sjson.Set(body, "error.message", message)
+	if errSet == nil {
+		body, errSet = sjson.Set(body, "error.code", code)
+	}
+	if errSet != nil {
+		body = `{"error":{"message":"request not allowed for this API key","type":"permission_error","code":"` + code + `"}}`
+	}
+	return &interfaces.ErrorMessage{
+		StatusCode: http.StatusForbidden,
+		Error:      errors.New(body),
+	}
+}
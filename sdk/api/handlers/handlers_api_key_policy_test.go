@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/access/keypolicy"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v7/sdk/config"
+)
+
+func newAPIKeyPolicyGinContext(t *testing.T, apiKey string) *gin.Context {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	ginCtx.Set("userApiKey", apiKey)
+	return ginCtx
+}
+
+func setAPIKeyPolicies(t *testing.T, keys ...sdkconfig.ClientAPIKey) {
+	t.Helper()
+	keypolicy.Default().SetKeys(keys)
+	t.Cleanup(func() { keypolicy.Default().SetKeys(nil) })
+}
+
+func TestApplyAPIKeyModelPolicy(t *testing.T) {
+	setAPIKeyPolicies(t,
+		sdkconfig.ClientAPIKey{Key: "scoped", Models: []string{"gpt-5*"}, Prefix: "team-a"},
+		sdkconfig.ClientAPIKey{Key: "plain"},
+	)
+	scopedCtx := context.WithValue(context.Background(), "gin", newAPIKeyPolicyGinContext(t, "scoped"))
+
+	model, errMsg := applyAPIKeyModelPolicy(scopedCtx, "gpt-5(high)")
+	if errMsg != nil || model != "team-a/gpt-5(high)" {
+		t.Fatalf("applyAPIKeyModelPolicy(gpt-5(high)) = %q, %v", model, errMsg)
+	}
+	if _, errMsg = applyAPIKeyModelPolicy(scopedCtx, "claude-sonnet-4-5"); errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("applyAPIKeyModelPolicy(claude-sonnet-4-5) error = %v, want 403", errMsg)
+	}
+
+	plainCtx := context.WithValue(context.Background(), "gin", newAPIKeyPolicyGinContext(t, "plain"))
+	if model, errMsg = applyAPIKeyModelPolicy(plainCtx, "claude-sonnet-4-5"); errMsg != nil || model != "claude-sonnet-4-5" {
+		t.Fatalf("plain key model = %q, %v", model, errMsg)
+	}
+}
+
+func TestFilterAPIKeyPolicyProviders(t *testing.T) {
+	setAPIKeyPolicies(t, sdkconfig.ClientAPIKey{Key: "scoped", Providers: []string{"codex"}})
+	ctx := context.WithValue(context.Background(), "gin", newAPIKeyPolicyGinContext(t, "scoped"))
+
+	providers, errMsg := filterAPIKeyPolicyProviders(ctx, []string{"openai", "codex"}, "gpt-5")
+	if errMsg != nil || !reflect.DeepEqual(providers, []string{"codex"}) {
+		t.Fatalf("filterAPIKeyPolicyProviders() = %v, %v", providers, errMsg)
+	}
+	if _, errMsg = filterAPIKeyPolicyProviders(ctx, []string{"gemini"}, "gemini-2.5-pro"); errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("filterAPIKeyPolicyProviders(gemini) error = %v, want 403", errMsg)
+	}
+}
+
+func TestFilterModelsForAPIKey(t *testing.T) {
+	modelRegistry := registry.GetGlobalRegistry()
+	now := time.Now().Unix()
+	modelRegistry.RegisterClient("test-api-key-policy-codex", "codex", []*registry.ModelInfo{
+		{ID: "team-a/gpt-5", Created: now},
+		{ID: "team-a/gpt-4.1", Created: now},
+	})
+	modelRegistry.RegisterClient("test-api-key-policy-gemini", "gemini", []*registry.ModelInfo{
+		{ID: "team-a/gpt-5-gemini-hosted", Created: now},
+	})
+	t.Cleanup(func() {
+		modelRegistry.UnregisterClient("test-api-key-policy-codex")
+		modelRegistry.UnregisterClient("test-api-key-policy-gemini")
+	})
+	setAPIKeyPolicies(t, sdkconfig.ClientAPIKey{Key: "scoped", Models: []string{"gpt-5*"}, Providers: []string{"codex"}, Prefix: "team-a"})
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil)
+	models := []map[string]any{
+		{"id": "gpt-5"},
+		{"id": "team-a/gpt-5"},
+		{"id": "team-a/gpt-4.1"},
+		{"id": "team-a/gpt-5-gemini-hosted"},
+	}
+	filtered := handler.FilterModelsForAPIKey(newAPIKeyPolicyGinContext(t, "scoped"), models)
+	if len(filtered) != 1 || filtered[0]["id"] != "team-a/gpt-5" {
+		t.Fatalf("FilterModelsForAPIKey() = %v, want only team-a/gpt-5", filtered)
+	}
+	if unfiltered := handler.FilterModelsForAPIKey(newAPIKeyPolicyGinContext(t, "other"), models); len(unfiltered) != len(models) {
+		t.Fatalf("FilterModelsForAPIKey() for unrestricted key = %v", unfiltered)
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	. "github.com/router-for-me/CLIProxyAPI/v7/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	"github.com/tidwall/gjson"
+)
+
+// filterAudioProviders keeps the providers able to serve an OpenAI audio request for
+// modelName: Gemini/Vertex (through the openai-audio translator) and OpenAI-compatible
+// upstreams, and only where the model declares audio in the modality the task needs —
+// input for transcriptions and translations, output for speech. Other entry protocols
+// are returned unchanged.
+func filterAudioProviders(entryProtocol string, providers []string, modelName string, rawJSON []byte) ([]string, *interfaces.ErrorMessage) {
+	if entryProtocol != OpenAIAudio {
+		return providers, nil
+	}
+	baseModel := strings.TrimSpace(thinking.ParseSuffix(modelName).ModelName)
+	speech := strings.EqualFold(gjson.GetBytes(rawJSON, "task").String(), "speech")
+	modelRegistry := registry.GetGlobalRegistry()
+	allowed := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		info := modelRegistry.GetModelInfo(baseModel, strings.ToLower(strings.TrimSpace(provider)))
+		if info == nil || !audioProviderSupported(provider, info) {
+			continue
+		}
+		modalities := info.SupportedInputModalities
+		if speech {
+			modalities = info.SupportedOutputModalities
+		}
+		if hasAudioModality(modalities) {
+			allowed = append(allowed, provider)
+		}
+	}
+	if len(allowed) == 0 {
+		direction := "input"
+		if speech {
+			direction = "output"
+		}
+		return nil, &interfaces.ErrorMessage{
+			StatusCode: http.StatusBadRequest,
+			Error:      fmt.Errorf("model %s does not declare audio %s modality", modelName, direction),
+		}
+	}
+	return allowed, nil
+}
+
+func audioProviderSupported(provider string, info *registry.ModelInfo) bool {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case Gemini, "vertex":
+		return true
+	}
+	return info.Type == "openai-compatibility"
+}
+
+func hasAudioModality(modalities []string) bool {
+	for _, modality := range modalities {
+		if strings.EqualFold(strings.TrimSpace(modality), "audio") {
+			return true
+		}
+	}
+	return false
+}
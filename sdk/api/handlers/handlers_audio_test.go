@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+)
+
+func TestFilterAudioProvidersRequiresDeclaredModality(t *testing.T) {
+	modelRegistry := registry.GetGlobalRegistry()
+	modelRegistry.RegisterClient("test-audio-compat", "audio-compat", []*registry.ModelInfo{
+		{ID: "voice-model", Object: "model", Type: "openai-compatibility", SupportedInputModalities: []string{"text", "audio"}, SupportedOutputModalities: []string{"text"}},
+	})
+	modelRegistry.RegisterClient("test-audio-claude", "claude", []*registry.ModelInfo{
+		{ID: "voice-model", Object: "model", Type: "claude", SupportedInputModalities: []string{"audio"}},
+	})
+	t.Cleanup(func() {
+		modelRegistry.UnregisterClient("test-audio-compat")
+		modelRegistry.UnregisterClient("test-audio-claude")
+	})
+
+	transcription := []byte(`{"task":"transcription"}`)
+	providers, errMsg := filterAudioProviders("openai-audio", []string{"audio-compat", "claude"}, "voice-model", transcription)
+	if errMsg != nil || !reflect.DeepEqual(providers, []string{"audio-compat"}) {
+		t.Fatalf("filterAudioProviders(transcription) = %v, %v", providers, errMsg)
+	}
+	if _, errMsg = filterAudioProviders("openai-audio", []string{"audio-compat"}, "voice-model", []byte(`{"task":"speech"}`)); errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("filterAudioProviders(speech) error = %v, want 400", errMsg)
+	}
+	if providers, _ = filterAudioProviders("openai", []string{"claude"}, "voice-model", transcription); !reflect.DeepEqual(providers, []string{"claude"}) {
+		t.Fatalf("non-audio entry protocol providers = %v", providers)
+	}
+}
@@ -48,6 +48,11 @@ func (h *BaseAPIHandler) PrepareStreamModelRoute(ctx context.Context, handlerTyp
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	// Route the model the calling key is scoped to, as execution does. A model the key
+	// may not use is rejected by the execution itself.
+	if routed, errMsg := applyAPIKeyModelPolicy(ctx, modelName); errMsg == nil {
+		modelName = routed
+	}
 	decision := h.applyModelRouter(ctx, handlerType, modelName, rawJSON, true, modelExecutionOptions{})
 	ctx = context.WithValue(ctx, preparedModelRouteContextKey{}, decision)
 	hasOverride := strings.TrimSpace(decision.ExecutorPluginID) != "" || strings.TrimSpace(decision.Provider) != ""
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/contextguard"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	"golang.org/x/net/context"
+)
+
+// applyContextGuard checks a request against the context window of its model before
+// it is sent upstream. It returns the body to execute, which the guard may have
+// shortened, or a protocol-native context length error. Internal model executions,
+// including the guard's own summary requests, are not checked.
+func (h *BaseAPIHandler) applyContextGuard(ctx context.Context, entryProtocol, model, requestedModel string, providers []string, rawJSON []byte, execOptions modelExecutionOptions) ([]byte, *interfaces.ErrorMessage) {
+	guard := contextguard.Default()
+	if execOptions.InternalSource || !guard.Settings().Enable {
+		return rawJSON, nil
+	}
+	outcome, err := guard.Check(ctx, contextguard.Request{
+		Format:         sdktranslator.FromString(entryProtocol),
+		Model:          model,
+		RequestedModel: requestedModel,
+		Providers:      providers,
+		Payload:        rawJSON,
+	}, func(ctx context.Context, summaryModel, transcript string, maxTokens int) (string, error) {
+		return h.summarizeForContextGuard(ctx, summaryModel, transcript, maxTokens)
+	})
+	var overflow *contextguard.OverflowError
+	if errors.As(err, &overflow) {
+		return nil, &interfaces.ErrorMessage{
+			StatusCode:     overflow.StatusCode(),
+			Error:          overflow,
+			DirectResponse: true,
+			Body:           overflow.Body(),
+		}
+	}
+	if err != nil {
+		return rawJSON, nil
+	}
+	if value := outcome.HeaderValue(); value != "" {
+		if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil && ginCtx.Writer != nil {
+			ginCtx.Header(contextguard.Header, value)
+		}
+	}
+	return outcome.Payload, nil
+}
+
+// summarizeForContextGuard asks model for a summary of transcript through the regular
+// execution path, so the summary request is routed, logged and billed like any other.
+func (h *BaseAPIHandler) summarizeForContextGuard(ctx context.Context, model, transcript string, maxTokens int) (string, error) {
+	resp, errMsg := h.ExecuteModel(ctx, ModelExecutionRequest{
+		EntryProtocol: string(sdktranslator.FormatOpenAI),
+		ExitProtocol:  string(sdktranslator.FormatOpenAI),
+		Model:         model,
+		Body:          contextguard.SummaryRequest(model, transcript, maxTokens),
+	})
+	if errMsg != nil {
+		if errMsg.Error != nil {
+			return "", errMsg.Error
+		}
+		return "", fmt.Errorf("summary request failed with status %d", errMsg.StatusCode)
+	}
+	return contextguard.ParseSummaryResponse(resp.Body)
+}
@@ -8,8 +8,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/tracing"
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context"
 )
 
@@ -65,6 +68,7 @@ type requestLifecycleSkipHost interface {
 
 type requestLifecycleTracker struct {
 	once         sync.Once
+	firstChunk   sync.Once
 	ctx          context.Context
 	host         PluginInterceptorHost
 	skipPluginID string
@@ -98,6 +102,17 @@ func (t *requestLifecycleTracker) requestID() string {
 	return t.completion.RequestID
 }
 
+// markFirstChunk records the first stream chunk handed to the client on the request span.
+func (t *requestLifecycleTracker) markFirstChunk() {
+	if t == nil {
+		return
+	}
+	t.firstChunk.Do(func() {
+		tracing.AddRequestEvent(t.ctx, "stream.first_byte",
+			attribute.Int64("cliproxy.stream.ttfb_ms", time.Since(t.completion.StartedAt).Milliseconds()))
+	})
+}
+
 func (t *requestLifecycleTracker) complete(outcome pluginapi.RequestCompletionOutcome, statusCode int, err error) {
 	if t == nil {
 		return
@@ -110,6 +125,12 @@ func (t *requestLifecycleTracker) complete(outcome pluginapi.RequestCompletionOu
 		if err != nil {
 			completion.Error = err.Error()
 		}
+		if completion.Stream {
+			tracing.AddRequestEvent(t.ctx, "stream.complete",
+				attribute.String("cliproxy.outcome", string(outcome)),
+				attribute.Int("http.response.status_code", statusCode),
+				attribute.Int64("cliproxy.stream.duration_ms", completion.CompletedAt.Sub(completion.StartedAt).Milliseconds()))
+		}
 		if t.skipPluginID != "" {
 			if host, ok := t.host.(requestLifecycleSkipHost); ok {
 				host.CompleteRequestExcept(t.ctx, completion, t.skipPluginID)
@@ -387,7 +408,20 @@ func mergeRequestInterceptorHeaders(current, updates http.Header, clear []string
 	return out
 }
 
-func interceptRequestBeforeAuth(ctx context.Context, host PluginInterceptorHost, req pluginapi.RequestInterceptRequest, skipPluginID string) pluginapi.RequestInterceptResponse {
+// startInterceptorSpan opens the span covering one plugin interceptor stage.
+func startInterceptorSpan(ctx context.Context, stage, model string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "plugin.intercept",
+		attribute.String("cliproxy.plugin.stage", stage),
+		tracing.AttrModel.String(model),
+	)
+}
+
+func interceptRequestBeforeAuth(ctx context.Context, host PluginInterceptorHost, req pluginapi.RequestInterceptRequest, skipPluginID string) (resp pluginapi.RequestInterceptResponse) {
+	ctx, span := startInterceptorSpan(ctx, "request_before_auth", req.Model)
+	defer func() {
+		span.SetAttributes(attribute.Bool("cliproxy.plugin.terminated", resp.Terminate))
+		span.End()
+	}()
 	if skipPluginID != "" {
 		if skipper, ok := host.(pluginInterceptorSkipHost); ok {
 			return skipper.InterceptRequestBeforeAuthExcept(ctx, req, skipPluginID)
@@ -396,7 +430,12 @@ func interceptRequestBeforeAuth(ctx context.Context, host PluginInterceptorHost,
 	return host.InterceptRequestBeforeAuth(ctx, req)
 }
 
-func interceptRequestAfterAuth(ctx context.Context, host PluginInterceptorHost, req pluginapi.RequestInterceptRequest, skipPluginID string) pluginapi.RequestInterceptResponse {
+func interceptRequestAfterAuth(ctx context.Context, host PluginInterceptorHost, req pluginapi.RequestInterceptRequest, skipPluginID string) (resp pluginapi.RequestInterceptResponse) {
+	ctx, span := startInterceptorSpan(ctx, "request_after_auth", req.Model)
+	defer func() {
+		span.SetAttributes(attribute.Bool("cliproxy.plugin.terminated", resp.Terminate))
+		span.End()
+	}()
 	if skipPluginID != "" {
 		if skipper, ok := host.(pluginInterceptorSkipHost); ok {
 			return skipper.InterceptRequestAfterAuthExcept(ctx, req, skipPluginID)
@@ -406,6 +445,8 @@ func interceptRequestAfterAuth(ctx context.Context, host PluginInterceptorHost,
 }
 
 func interceptResponse(ctx context.Context, host PluginInterceptorHost, req pluginapi.ResponseInterceptRequest, skipPluginID string) pluginapi.ResponseInterceptResponse {
+	ctx, span := startInterceptorSpan(ctx, "response", req.Model)
+	defer span.End()
 	if skipPluginID != "" {
 		if skipper, ok := host.(pluginInterceptorSkipHost); ok {
 			return skipper.InterceptResponseExcept(ctx, req, skipPluginID)
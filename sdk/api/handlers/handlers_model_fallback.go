@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+)
+
+// setModelFallbackHeaders copies the model-fallbacks hop reported by the auth
+// manager onto the client response. The headers are set regardless of upstream
+// header passthrough so clients can always see which hop served them.
+func setModelFallbackHeaders(ctx context.Context, headers http.Header) {
+	hop := headers.Get(coreauth.ModelFallbackHopHeader)
+	if hop == "" || ctx == nil {
+		return
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil || ginCtx.Writer == nil {
+		return
+	}
+	ginCtx.Header(coreauth.ModelFallbackHopHeader, hop)
+	ginCtx.Header(coreauth.ModelFallbackModelHeader, headers.Get(coreauth.ModelFallbackModelHeader))
+}
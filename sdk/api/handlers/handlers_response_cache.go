@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/responsecache"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	coresession "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/session"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+	"golang.org/x/net/context"
+)
+
+// responseCacheProvider is the provider reported in usage records of cache hits.
+const responseCacheProvider = "response-cache"
+
+// responseCacheLookup caches the responses of one eligible request. The auth
+// manager consults it per attempt, once the credential and upstream model are
+// known, so the key covers the request that is actually sent upstream.
+type responseCacheLookup struct {
+	cache            *responsecache.Cache
+	scope            string
+	entryProtocol    string
+	responseProtocol string
+	path             string
+	alt              string
+	stream           bool
+	model            string
+	requestedModel   string
+	apiKey           string
+	ginCtx           *gin.Context
+
+	mu     sync.Mutex
+	status string
+}
+
+// prepareResponseCache returns the cache lookup of a request, or nil when the cache
+// is disabled or the request is not eligible.
+func prepareResponseCache(ctx context.Context, entryProtocol, responseProtocol, model, requestedModel string, req coreexecutor.Request, opts coreexecutor.Options) *responseCacheLookup {
+	cache := responsecache.Default()
+	if ctx == nil || !cache.Enabled() || !responsecache.Eligible(opts.Headers, req.Payload) {
+		return nil
+	}
+	ginCtx, _ := ctx.Value("gin").(*gin.Context)
+	apiKey := ""
+	path := ""
+	if ginCtx != nil {
+		if value, exists := ginCtx.Get("userApiKey"); exists && value != nil {
+			apiKey = fmt.Sprint(value)
+		}
+		if ginCtx.Request != nil && ginCtx.Request.URL != nil {
+			path = ginCtx.Request.URL.Path
+		}
+	}
+	scope := ""
+	if !cache.ShareAcrossKeys() {
+		scope = coresession.CallerScope(apiKey)
+	}
+	return &responseCacheLookup{
+		cache:            cache,
+		scope:            scope,
+		entryProtocol:    entryProtocol,
+		responseProtocol: responseProtocol,
+		path:             path,
+		alt:              opts.Alt,
+		stream:           opts.Stream,
+		model:            model,
+		requestedModel:   requestedModel,
+		apiKey:           apiKey,
+		ginCtx:           ginCtx,
+	}
+}
+
+// executeWithResponseCache executes a non-streaming request with lookup consulted
+// for every attempt.
+func (h *BaseAPIHandler) executeWithResponseCache(ctx context.Context, lookup *responseCacheLookup, providers []string, req coreexecutor.Request, opts coreexecutor.Options) (coreexecutor.Response, error) {
+	if lookup == nil {
+		return h.AuthManager.Execute(ctx, providers, req, opts)
+	}
+	resp, err := h.AuthManager.Execute(coreauth.WithResponseCache(ctx, lookup), providers, req, opts)
+	lookup.writeStatus()
+	return resp, err
+}
+
+// executeStreamWithResponseCache executes a streaming request with lookup
+// consulted for every attempt.
+func (h *BaseAPIHandler) executeStreamWithResponseCache(ctx context.Context, lookup *responseCacheLookup, providers []string, req coreexecutor.Request, opts coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	if lookup == nil {
+		return h.AuthManager.ExecuteStream(ctx, providers, req, opts)
+	}
+	result, err := h.AuthManager.ExecuteStream(coreauth.WithResponseCache(ctx, lookup), providers, req, opts)
+	lookup.writeStatus()
+	return result, err
+}
+
+// key returns the cache key of attempt.
+func (l *responseCacheLookup) key(attempt *coreauth.ResponseCacheAttempt) (string, bool) {
+	if attempt == nil {
+		return "", false
+	}
+	return responsecache.Key(responsecache.Request{
+		Scope:            l.scope,
+		EntryProtocol:    l.entryProtocol,
+		ResponseProtocol: l.responseProtocol,
+		Path:             l.path,
+		Provider:         attempt.Provider,
+		Model:            attempt.Model,
+		RequestedModel:   attempt.RequestedModel,
+		Rules:            attempt.Rules,
+		Alt:              l.alt,
+		Stream:           l.stream,
+		Body:             attempt.Payload,
+	})
+}
+
+// Lookup serves a non-streaming attempt from the cache.
+func (l *responseCacheLookup) Lookup(ctx context.Context, attempt *coreauth.ResponseCacheAttempt) (coreexecutor.Response, bool) {
+	key, ok := l.key(attempt)
+	if !ok {
+		return coreexecutor.Response{}, false
+	}
+	entry, ok := l.cache.Get(ctx, key)
+	if !ok || len(entry.Payload) == 0 {
+		return coreexecutor.Response{}, false
+	}
+	l.hit(ctx)
+	return coreexecutor.Response{Payload: cloneBytes(entry.Payload), Headers: cloneHeader(entry.Headers)}, true
+}
+
+// Store keeps the successful upstream response of attempt.
+func (l *responseCacheLookup) Store(ctx context.Context, attempt *coreauth.ResponseCacheAttempt, resp coreexecutor.Response) {
+	key, ok := l.key(attempt)
+	if !ok {
+		return
+	}
+	l.setStatus(responsecache.StatusMiss)
+	if len(resp.Payload) > 0 {
+		l.cache.Set(ctx, key, responsecache.Entry{Payload: cloneBytes(resp.Payload), Headers: cloneHeader(resp.Headers)})
+	}
+}
+
+// LookupStream replays a cached stream of attempt.
+func (l *responseCacheLookup) LookupStream(ctx context.Context, attempt *coreauth.ResponseCacheAttempt) (*coreexecutor.StreamResult, bool) {
+	key, ok := l.key(attempt)
+	if !ok {
+		return nil, false
+	}
+	entry, ok := l.cache.Get(ctx, key)
+	if !ok || len(entry.Chunks) == 0 {
+		return nil, false
+	}
+	l.hit(ctx)
+	chunks := make(chan coreexecutor.StreamChunk, len(entry.Chunks))
+	for _, chunk := range entry.Chunks {
+		chunks <- coreexecutor.StreamChunk{Payload: cloneBytes(chunk)}
+	}
+	close(chunks)
+	return &coreexecutor.StreamResult{Headers: cloneHeader(entry.Headers), Chunks: chunks}, true
+}
+
+// RecordStream records the upstream chunks of attempt and stores them once the
+// stream ends without error.
+func (l *responseCacheLookup) RecordStream(ctx context.Context, attempt *coreauth.ResponseCacheAttempt, result *coreexecutor.StreamResult) *coreexecutor.StreamResult {
+	key, ok := l.key(attempt)
+	if !ok || result == nil {
+		return result
+	}
+	l.setStatus(responsecache.StatusMiss)
+	recorded := *result
+	recorded.Chunks = l.record(ctx, key, result.Chunks, cloneHeader(result.Headers))
+	return &recorded
+}
+
+// record forwards chunks and stores them when the stream completes cleanly and
+// stays within the entry size limit.
+func (l *responseCacheLookup) record(ctx context.Context, key string, chunks <-chan coreexecutor.StreamChunk, headers http.Header) <-chan coreexecutor.StreamChunk {
+	if chunks == nil {
+		return nil
+	}
+	out := make(chan coreexecutor.StreamChunk)
+	maxSize := l.cache.MaxEntrySize()
+	go func() {
+		defer close(out)
+		var recorded [][]byte
+		size := 0
+		cacheable := true
+		for chunk := range chunks {
+			if chunk.Err != nil {
+				cacheable = false
+			} else if cacheable && len(chunk.Payload) > 0 {
+				size += len(chunk.Payload)
+				if size > maxSize {
+					cacheable = false
+					recorded = nil
+				} else {
+					recorded = append(recorded, cloneBytes(chunk.Payload))
+				}
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if cacheable && len(recorded) > 0 && ctx.Err() == nil {
+			l.cache.Set(ctx, key, responsecache.Entry{Chunks: recorded, Headers: headers})
+		}
+	}()
+	return out
+}
+
+// hit marks the response as served from the cache and publishes a usage record
+// flagged as cached so token budgets are not charged again.
+func (l *responseCacheLookup) hit(ctx context.Context) {
+	l.setStatus(responsecache.StatusHit)
+	alias := strings.TrimSpace(l.requestedModel)
+	if alias == "" {
+		alias = l.model
+	}
+	coreusage.PublishRecord(ctx, coreusage.Record{
+		Provider:    responseCacheProvider,
+		Model:       l.model,
+		Alias:       alias,
+		APIKey:      l.apiKey,
+		Source:      responseCacheProvider,
+		Generate:    coreusage.GenerateFlag(true),
+		Cached:      true,
+		RequestedAt: time.Now(),
+	})
+}
+
+func (l *responseCacheLookup) setStatus(status string) {
+	l.mu.Lock()
+	l.status = status
+	l.mu.Unlock()
+}
+
+// writeStatus reports the cache outcome in the response headers. Attempts may run
+// concurrently, so the header is written once execution returns.
+func (l *responseCacheLookup) writeStatus() {
+	l.mu.Lock()
+	status := l.status
+	l.mu.Unlock()
+	if status == "" || l.ginCtx == nil || l.ginCtx.Writer == nil {
+		return
+	}
+	l.ginCtx.Header(responsecache.Header, status)
+}
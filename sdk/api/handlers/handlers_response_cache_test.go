@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/responsecache"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v7/sdk/config"
+)
+
+type countingCacheExecutor struct {
+	executeCalls atomic.Int32
+	streamCalls  atomic.Int32
+}
+
+func (e *countingCacheExecutor) Identifier() string { return "codex" }
+
+func (e *countingCacheExecutor) Execute(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	e.executeCalls.Add(1)
+	return coreexecutor.Response{Payload: []byte(`{"id":"resp-1"}`), Headers: http.Header{"X-Upstream": {"1"}}}, nil
+}
+
+func (e *countingCacheExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	e.streamCalls.Add(1)
+	chunks := make(chan coreexecutor.StreamChunk, 2)
+	chunks <- coreexecutor.StreamChunk{Payload: []byte("a")}
+	chunks <- coreexecutor.StreamChunk{Payload: []byte("b")}
+	close(chunks)
+	return &coreexecutor.StreamResult{Chunks: chunks}, nil
+}
+
+func (e *countingCacheExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *countingCacheExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *countingCacheExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func newResponseCacheTestHandler(t *testing.T) (*BaseAPIHandler, *countingCacheExecutor) {
+	t.Helper()
+	responsecache.Default().Apply(internalconfig.ResponseCacheConfig{Enable: true})
+	t.Cleanup(func() { responsecache.Default().Apply(internalconfig.ResponseCacheConfig{}) })
+
+	executor := &countingCacheExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	auth := &coreauth.Auth{ID: "response-cache-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register(): %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "cache-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	return NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, manager), executor
+}
+
+func responseCacheTestContext(apiKey string) (context.Context, *httptest.ResponseRecorder) {
+	recorder := httptest.NewRecorder()
+	ginCtx, _ := gin.CreateTestContext(recorder)
+	ginCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	ginCtx.Set("userApiKey", apiKey)
+	return context.WithValue(context.Background(), "gin", ginCtx), recorder
+}
+
+func TestExecuteWithAuthManager_ServesDeterministicRequestsFromCache(t *testing.T) {
+	handler, executor := newResponseCacheTestHandler(t)
+	body := []byte(`{"model":"cache-model","temperature":0,"messages":[{"role":"user","content":"hi"}]}`)
+	reordered := []byte(`{"messages":[{"content":"hi","role":"user"}],"temperature":0,"model":"cache-model","user":"ci-1"}`)
+
+	ctx, recorder := responseCacheTestContext("key-a")
+	if _, _, errMsg := handler.ExecuteWithAuthManager(ctx, "openai", "cache-model", body, ""); errMsg != nil {
+		t.Fatalf("first request error: %+v", errMsg)
+	}
+	if got := recorder.Header().Get(responsecache.Header); got != responsecache.StatusMiss {
+		t.Fatalf("first request cache header = %q, want miss", got)
+	}
+
+	ctx, recorder = responseCacheTestContext("key-a")
+	resp, headers, errMsg := handler.ExecuteWithAuthManager(ctx, "openai", "cache-model", reordered, "")
+	if errMsg != nil {
+		t.Fatalf("second request error: %+v", errMsg)
+	}
+	if string(resp) != `{"id":"resp-1"}` {
+		t.Fatalf("cached response = %s", resp)
+	}
+	if got := recorder.Header().Get(responsecache.Header); got != responsecache.StatusHit {
+		t.Fatalf("second request cache header = %q, want hit", got)
+	}
+	if headers != nil && headers.Get("X-Upstream") != "" {
+		t.Fatalf("upstream headers leaked without passthrough: %v", headers)
+	}
+	if got := executor.executeCalls.Load(); got != 1 {
+		t.Fatalf("executor calls = %d, want 1", got)
+	}
+
+	ctx, _ = responseCacheTestContext("key-b")
+	if _, _, errMsg = handler.ExecuteWithAuthManager(ctx, "openai", "cache-model", body, ""); errMsg != nil {
+		t.Fatalf("other key request error: %+v", errMsg)
+	}
+	if got := executor.executeCalls.Load(); got != 2 {
+		t.Fatalf("executor calls after another key = %d, want 2", got)
+	}
+}
+
+func TestExecuteWithAuthManager_SkipsCacheForNonDeterministicRequests(t *testing.T) {
+	handler, executor := newResponseCacheTestHandler(t)
+	body := []byte(`{"model":"cache-model","temperature":0.7,"messages":[{"role":"user","content":"hi"}]}`)
+	for i := 0; i < 2; i++ {
+		ctx, recorder := responseCacheTestContext("key-a")
+		if _, _, errMsg := handler.ExecuteWithAuthManager(ctx, "openai", "cache-model", body, ""); errMsg != nil {
+			t.Fatalf("request %d error: %+v", i, errMsg)
+		}
+		if got := recorder.Header().Get(responsecache.Header); got != "" {
+			t.Fatalf("request %d cache header = %q, want none", i, got)
+		}
+	}
+	if got := executor.executeCalls.Load(); got != 2 {
+		t.Fatalf("executor calls = %d, want 2", got)
+	}
+}
+
+func TestExecuteStreamWithAuthManager_ReplaysCachedStream(t *testing.T) {
+	handler, executor := newResponseCacheTestHandler(t)
+	body := []byte(`{"model":"cache-model","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	for i := 0; i < 2; i++ {
+		ctx, _ := responseCacheTestContext("key-a")
+		ginCtx := ctx.Value("gin").(*gin.Context)
+		ginCtx.Request.Header.Set(responsecache.Header, "on")
+		dataChan, _, errChan := handler.ExecuteStreamWithAuthManager(ctx, "openai", "cache-model", body, "")
+		var got []byte
+		for chunk := range dataChan {
+			got = append(got, chunk...)
+		}
+		for msg := range errChan {
+			if msg != nil {
+				t.Fatalf("stream %d error: %+v", i, msg)
+			}
+		}
+		if string(got) != "ab" {
+			t.Fatalf("stream %d payload = %q, want ab", i, got)
+		}
+	}
+	if got := executor.streamCalls.Load(); got != 1 {
+		t.Fatalf("executor stream calls = %d, want 1", got)
+	}
+}
+
+func TestExecuteWithAuthManager_KeysCacheOnPayloadRules(t *testing.T) {
+	handler, executor := newResponseCacheTestHandler(t)
+	body := []byte(`{"model":"cache-model","temperature":0,"messages":[{"role":"user","content":"hi"}]}`)
+	execute := func(tier string) string {
+		t.Helper()
+		ctx, recorder := responseCacheTestContext("key-rules")
+		if tier != "" {
+			ctx.Value("gin").(*gin.Context).Request.Header.Set("X-Tier", tier)
+		}
+		if _, _, errMsg := handler.ExecuteWithAuthManager(ctx, "openai", "cache-model", body, ""); errMsg != nil {
+			t.Fatalf("request error: %+v", errMsg)
+		}
+		return recorder.Header().Get(responsecache.Header)
+	}
+
+	if got := execute(""); got != responsecache.StatusMiss {
+		t.Fatalf("first request cache header = %q, want miss", got)
+	}
+	handler.AuthManager.SetConfig(&internalconfig.Config{Payload: internalconfig.PayloadConfig{
+		Override: []internalconfig.PayloadRule{{
+			Models: []internalconfig.PayloadModelRule{{Name: "cache-model", Headers: map[string]string{"X-Tier": "pro"}}},
+			Params: map[string]any{"reasoning.effort": "high"},
+		}},
+	}})
+	if got := execute(""); got != responsecache.StatusMiss {
+		t.Fatalf("request under new payload rules cache header = %q, want miss", got)
+	}
+	if got := execute("pro"); got != responsecache.StatusMiss {
+		t.Fatalf("request matching a header-gated rule cache header = %q, want miss", got)
+	}
+	if got := execute("pro"); got != responsecache.StatusHit {
+		t.Fatalf("repeated request cache header = %q, want hit", got)
+	}
+	if got := executor.executeCalls.Load(); got != 3 {
+		t.Fatalf("executor calls = %d, want 3", got)
+	}
+}
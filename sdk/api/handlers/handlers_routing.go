@@ -11,9 +11,11 @@ import (
 	. "github.com/router-for-me/CLIProxyAPI/v7/internal/constant"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/tracing"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/net/context"
 )
 
@@ -156,6 +158,24 @@ func (h *BaseAPIHandler) providersForExecution(modelName, originalRequestedModel
 	return h.getRequestDetailsWithOptions(modelName, allowImageModel)
 }
 
+// resolveExecutionProviders resolves the providers and model serving a request inside a
+// "model.route" span.
+func (h *BaseAPIHandler) resolveExecutionProviders(ctx context.Context, modelName, originalRequestedModel string, allowImageModel bool, routeDecision modelRouteDecision, execOptions modelExecutionOptions) ([]string, string, *interfaces.ErrorMessage) {
+	_, span := tracing.Start(ctx, "model.route", tracing.AttrRequestedModel.String(originalRequestedModel))
+	providers, normalizedModel, errMsg := h.providersForExecution(modelName, originalRequestedModel, allowImageModel, routeDecision, execOptions)
+	span.SetAttributes(
+		tracing.AttrModel.String(normalizedModel),
+		attribute.StringSlice("cliproxy.providers", providers),
+		attribute.Bool("cliproxy.route.plugin", routeDecision.Provider != ""),
+	)
+	var errRoute error
+	if errMsg != nil {
+		errRoute = errMsg.Error
+	}
+	tracing.End(span, errRoute)
+	return providers, normalizedModel, errMsg
+}
+
 func (h *BaseAPIHandler) getRequestDetailsWithOptions(modelName string, allowImageModel bool) (providers []string, normalizedModel string, err *interfaces.ErrorMessage) {
 	resolvedModelName := modelName
 	initialSuffix := thinking.ParseSuffix(modelName)
@@ -331,7 +351,8 @@ func (h *BaseAPIHandler) applyModelRouter(ctx context.Context, handlerType, mode
 	meta := requestExecutionMetadata(ctx)
 	meta[coreexecutor.RequestedModelMetadataKey] = modelName
 	addModelExecutionSourceMetadata(meta, execOptions.InternalSource)
-	resp, ok := routeModel(ctx, host, pluginapi.ModelRouteRequest{
+	routerCtx, span := tracing.Start(ctx, "plugin.model_router", tracing.AttrRequestedModel.String(modelName))
+	resp, ok := routeModel(routerCtx, host, pluginapi.ModelRouteRequest{
 		SourceFormat:   handlerType,
 		RequestedModel: modelName,
 		Stream:         stream,
@@ -340,6 +361,8 @@ func (h *BaseAPIHandler) applyModelRouter(ctx context.Context, handlerType, mode
 		Body:           cloneBytes(rawJSON),
 		Metadata:       meta,
 	}, execOptions.SkipRouterPluginID)
+	span.SetAttributes(attribute.Bool("cliproxy.route.handled", ok && resp.Handled))
+	span.End()
 	if !ok || !resp.Handled {
 		return decision
 	}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
@@ -266,6 +269,13 @@ func (h *BaseAPIHandler) executeStreamWithAuthManager(ctx context.Context, handl
 
 func (h *BaseAPIHandler) executeStreamWithAuthManagerFormats(ctx context.Context, entryProtocol, exitProtocol, modelName string, rawJSON []byte, alt string, allowImageModel bool, execOptions modelExecutionOptions) (<-chan []byte, http.Header, <-chan *interfaces.ErrorMessage) {
 	originalRequestedModel := modelName
+	modelName, errMsg := applyAPIKeyModelPolicy(ctx, modelName)
+	if errMsg != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errMsg
+		close(errChan)
+		return nil, nil, errChan
+	}
 	routeDecision, preparedRoute := preparedModelRouteFromContext(ctx, execOptions.SkipRouterPluginID)
 	if !preparedRoute {
 		routeDecision = h.applyModelRouter(ctx, entryProtocol, modelName, rawJSON, true, execOptions)
@@ -280,7 +290,7 @@ func (h *BaseAPIHandler) executeStreamWithAuthManagerFormats(ctx context.Context
 	if routeDecision.ExecutorPluginID != "" {
 		return h.streamWithPluginExecutor(ctx, entryProtocol, responseProtocol, modelName, originalRequestedModel, rawJSON, alt, routeDecision.ExecutorPluginID, execOptions)
 	}
-	providers, normalizedModel, errMsg := h.providersForExecution(modelName, originalRequestedModel, allowImageModel, routeDecision, execOptions)
+	providers, normalizedModel, errMsg := h.resolveExecutionProviders(ctx, modelName, originalRequestedModel, allowImageModel, routeDecision, execOptions)
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		errChan <- errMsg
@@ -288,6 +298,18 @@ func (h *BaseAPIHandler) executeStreamWithAuthManagerFormats(ctx context.Context
 		return nil, nil, errChan
 	}
 	providers = adjustExecutionProvidersForEntryProtocol(entryProtocol, providers)
+	if providers, errMsg = filterAPIKeyPolicyProviders(ctx, providers, normalizedModel); errMsg != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errMsg
+		close(errChan)
+		return nil, nil, errChan
+	}
+	if rawJSON, errMsg = h.applyContextGuard(ctx, entryProtocol, normalizedModel, originalRequestedModel, providers, rawJSON, execOptions); errMsg != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errMsg
+		close(errChan)
+		return nil, nil, errChan
+	}
 	reqMeta := requestExecutionMetadata(ctx)
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = originalRequestedModel
 	addAuthSelectionModelMetadata(reqMeta, execOptions.AuthSelectionModel)
@@ -325,7 +347,8 @@ func (h *BaseAPIHandler) executeStreamWithAuthManagerFormats(ctx context.Context
 		close(errChan)
 		return nil, nil, errChan
 	}
-	streamResult, err := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
+	cacheLookup := prepareResponseCache(ctx, entryProtocol, responseProtocol, normalizedModel, originalRequestedModel, req, opts)
+	streamResult, err := h.executeStreamWithResponseCache(ctx, cacheLookup, providers, req, opts)
 	if err != nil {
 		err = enrichAuthSelectionError(err, providers, normalizedModel)
 		errMsg := executionErrorMessage(err)
@@ -343,6 +366,7 @@ func (h *BaseAPIHandler) executeStreamWithAuthManagerFormats(ctx context.Context
 		close(errChan)
 		return nil, nil, errChan
 	}
+	setModelFallbackHeaders(ctx, streamResult.Headers)
 	executedRequest := func() (coreexecutor.Request, coreexecutor.Options) {
 		return afterAuthCapture.apply(req, opts)
 	}
@@ -521,7 +545,7 @@ func (h *BaseAPIHandler) executeStreamWithAuthManagerFormats(ctx context.Context
 			break
 		}
 		bootstrapRetries++
-		retryResult, retryErr := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
+		retryResult, retryErr := h.executeStreamWithResponseCache(ctx, cacheLookup, providers, req, opts)
 		if retryErr != nil {
 			originalBootstrapErr := executionErrorMessage(bootstrapStreamErr)
 			if isAuthSelectionUnavailable(retryErr) && originalBootstrapErr.StatusCode >= http.StatusInternalServerError {
@@ -535,6 +559,7 @@ func (h *BaseAPIHandler) executeStreamWithAuthManagerFormats(ctx context.Context
 			bootstrapErr = executionErrorMessage(fmt.Errorf("auth manager returned nil stream"))
 			break
 		}
+		setModelFallbackHeaders(ctx, retryResult.Headers)
 		rawStreamHeaders = cloneHeader(retryResult.Headers)
 		baseStreamHeaders = cloneHeader(retryResult.Headers)
 		streamHeaderInitialized = false
@@ -595,12 +620,14 @@ func (h *BaseAPIHandler) executeStreamWithAuthManagerFormats(ctx context.Context
 		sendData := func(chunk []byte) bool {
 			if ctx == nil {
 				dataChan <- chunk
+				lifecycle.markFirstChunk()
 				return true
 			}
 			select {
 			case <-ctx.Done():
 				return false
 			case dataChan <- chunk:
+				lifecycle.markFirstChunk()
 				return true
 			}
 		}
@@ -703,6 +730,69 @@ func (h *BaseAPIHandler) executeStreamWithAuthManagerFormats(ctx context.Context
 	return dataChan, upstreamHeaders, errChan
 }
 
+// NDJSONContentType is the media type of newline-delimited JSON streams such as Ollama's.
+const NDJSONContentType = "application/x-ndjson"
+
+// SetNDJSONHeaders commits the response headers for a newline-delimited JSON stream.
+func SetNDJSONHeaders(c *gin.Context) {
+	c.Header("Content-Type", NDJSONContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Access-Control-Allow-Origin", "*")
+}
+
+// WriteNDJSONLine writes one JSON object followed by a newline. The payload is compacted so
+// embedded newlines cannot split the object across lines.
+func WriteNDJSONLine(w io.Writer, payload []byte) {
+	payload = bytes.TrimSpace(payload)
+	if len(payload) == 0 {
+		return
+	}
+	var compacted bytes.Buffer
+	if errCompact := json.Compact(&compacted, payload); errCompact == nil {
+		payload = compacted.Bytes()
+	}
+	_, _ = w.Write(payload)
+	_, _ = w.Write([]byte("\n"))
+}
+
+// ForwardNDJSONStream forwards stream chunks as newline-delimited JSON. convert maps each
+// upstream chunk to zero or more JSON objects and is called once with "[DONE]" when the
+// upstream closes cleanly so stateful converters can flush their final object. NDJSON has no
+// comment syntax, so keep-alive heartbeats are disabled; terminal errors are written as a
+// single {"error":"..."} line.
+func (h *BaseAPIHandler) ForwardNDJSONStream(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage, convert func(chunk []byte) [][]byte) {
+	noKeepAlive := time.Duration(0)
+	h.ForwardStream(c, flusher, cancel, data, errs, StreamForwardOptions{
+		KeepAliveInterval: &noKeepAlive,
+		WriteChunk: func(chunk []byte) {
+			for _, line := range convert(chunk) {
+				WriteNDJSONLine(c.Writer, line)
+			}
+		},
+		WriteTerminalError: func(errMsg *interfaces.ErrorMessage) {
+			if errMsg == nil {
+				return
+			}
+			status := http.StatusInternalServerError
+			if errMsg.StatusCode > 0 {
+				status = errMsg.StatusCode
+			}
+			errText := http.StatusText(status)
+			if errMsg.Error != nil && errMsg.Error.Error() != "" {
+				errText = errMsg.Error.Error()
+			}
+			body, _ := json.Marshal(map[string]string{"error": errText})
+			WriteNDJSONLine(c.Writer, body)
+		},
+		WriteDone: func() {
+			for _, line := range convert([]byte("[DONE]")) {
+				WriteNDJSONLine(c.Writer, line)
+			}
+		},
+	})
+}
+
 type sseJSONValidationState struct {
 	pending    []byte
 	pendingErr error
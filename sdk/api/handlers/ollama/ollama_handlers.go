@@ -0,0 +1,277 @@
+// Package ollama provides HTTP handlers for the Ollama native API.
+// It exposes /api/chat, /api/generate, /api/tags, /api/show and /api/version so Ollama
+// clients can use the proxy directly. Chat and generate requests are translated to OpenAI
+// Chat Completions, routed through the shared auth manager like any OpenAI request, and the
+// responses are translated back, streaming as newline-delimited JSON.
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/router-for-me/CLIProxyAPI/v7/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	_ "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/openai/ollama"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v7/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+// compatibleVersion is the Ollama server version reported by /api/version. Clients use it
+// to gate features such as tool calling and structured outputs.
+const compatibleVersion = "0.12.0"
+
+// OllamaAPIHandler contains the handlers for Ollama API endpoints.
+type OllamaAPIHandler struct {
+	*handlers.BaseAPIHandler
+}
+
+// NewOllamaAPIHandler creates a new Ollama API handlers instance.
+func NewOllamaAPIHandler(apiHandlers *handlers.BaseAPIHandler) *OllamaAPIHandler {
+	return &OllamaAPIHandler{
+		BaseAPIHandler: apiHandlers,
+	}
+}
+
+// HandlerType returns the identifier for this handler implementation.
+func (h *OllamaAPIHandler) HandlerType() string {
+	return Ollama
+}
+
+// Models returns the models exposed through the Ollama API, which share the OpenAI catalogue.
+func (h *OllamaAPIHandler) Models() []map[string]any {
+	return registry.GetGlobalRegistry().GetAvailableModels(OpenAI)
+}
+
+// Chat handles the /api/chat endpoint.
+func (h *OllamaAPIHandler) Chat(c *gin.Context) {
+	h.handleCompletion(c)
+}
+
+// Generate handles the /api/generate endpoint.
+func (h *OllamaAPIHandler) Generate(c *gin.Context) {
+	h.handleCompletion(c)
+}
+
+// Tags handles the /api/tags endpoint, listing the models available to the calling API key.
+func (h *OllamaAPIHandler) Tags(c *gin.Context) {
+	models := h.FilterModelsForAPIKey(c, h.Models())
+	entries := make([]gin.H, 0, len(models))
+	for _, model := range models {
+		id, _ := model["id"].(string)
+		if id == "" {
+			continue
+		}
+		entries = append(entries, gin.H{
+			"name":        id,
+			"model":       id,
+			"modified_at": modelModifiedAt(model),
+			"size":        0,
+			"digest":      "",
+			"details":     modelDetails(model),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"models": entries})
+}
+
+// Show handles the /api/show endpoint, describing one model and its capabilities.
+func (h *OllamaAPIHandler) Show(c *gin.Context) {
+	rawJSON, err := handlers.ReadRequestBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	name := strings.TrimSpace(gjson.GetBytes(rawJSON, "model").String())
+	if name == "" {
+		name = strings.TrimSpace(gjson.GetBytes(rawJSON, "name").String())
+	}
+	for _, model := range h.FilterModelsForAPIKey(c, h.Models()) {
+		if id, _ := model["id"].(string); id != name {
+			continue
+		}
+		info := registry.LookupModelInfo(name)
+		modelInfo := gin.H{"general.architecture": model["owned_by"]}
+		if info != nil && info.ContextLength > 0 {
+			modelInfo["general.context_length"] = info.ContextLength
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"modelfile":    "",
+			"parameters":   "",
+			"template":     "",
+			"details":      modelDetails(model),
+			"model_info":   modelInfo,
+			"capabilities": modelCapabilities(info),
+			"modified_at":  modelModifiedAt(model),
+		})
+		return
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("model '%s' not found", name)})
+}
+
+// Version handles the /api/version endpoint.
+func (h *OllamaAPIHandler) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"version": compatibleVersion})
+}
+
+// handleCompletion serves /api/chat and /api/generate. Ollama streams unless the request
+// explicitly sets "stream": false.
+func (h *OllamaAPIHandler) handleCompletion(c *gin.Context) {
+	rawJSON, err := handlers.ReadRequestBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	modelName := strings.TrimSpace(gjson.GetBytes(rawJSON, "model").String())
+	if modelName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "model is required"})
+		return
+	}
+	stream := gjson.GetBytes(rawJSON, "stream").Type != gjson.False
+	chatJSON := sdktranslator.TranslateRequestByFormatName(sdktranslator.FormatOllama, sdktranslator.FormatOpenAI, modelName, rawJSON, stream)
+	if stream {
+		h.handleStreamingResponse(c, rawJSON, chatJSON, modelName)
+	} else {
+		h.handleNonStreamingResponse(c, rawJSON, chatJSON, modelName)
+	}
+}
+
+// handleNonStreamingResponse executes the translated chat request and writes one Ollama response.
+func (h *OllamaAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSON, chatJSON []byte, modelName string) {
+	c.Header("Content-Type", "application/json")
+
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	stopKeepAlive := h.StartNonStreamingKeepAlive(c, cliCtx)
+	resp, upstreamHeaders, errMsg := h.ExecuteWithAuthManager(cliCtx, OpenAI, modelName, chatJSON, "")
+	stopKeepAlive()
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
+	var param any
+	out := sdktranslator.TranslateNonStreamByFormatName(cliCtx, sdktranslator.FormatOpenAI, sdktranslator.FormatOllama, modelName, rawJSON, chatJSON, resp, &param)
+	_, _ = c.Writer.Write(out)
+	cliCancel()
+}
+
+// handleStreamingResponse executes the translated chat request and streams Ollama NDJSON objects.
+func (h *OllamaAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON, chatJSON []byte, modelName string) {
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Streaming not supported"})
+		return
+	}
+
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	dataChan, upstreamHeaders, errChan := h.ExecuteStreamWithAuthManager(cliCtx, OpenAI, modelName, chatJSON, "")
+
+	var param any
+	convert := func(chunk []byte) [][]byte {
+		return sdktranslator.TranslateStreamByFormatName(cliCtx, sdktranslator.FormatOpenAI, sdktranslator.FormatOllama, modelName, rawJSON, chatJSON, chunk, &param)
+	}
+
+	// Peek at the first chunk to determine success or failure before setting headers
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			cliCancel(c.Request.Context().Err())
+			return
+		case errMsg, ok := <-errChan:
+			if !ok {
+				// Err channel closed cleanly; wait for data channel.
+				errChan = nil
+				continue
+			}
+			h.WriteErrorResponse(c, errMsg)
+			if errMsg != nil {
+				cliCancel(errMsg.Error)
+			} else {
+				cliCancel(nil)
+			}
+			return
+		case chunk, ok := <-dataChan:
+			if !ok {
+				if errMsg, hasPendingError := handlers.PendingStreamError(errChan); hasPendingError {
+					h.WriteErrorResponse(c, errMsg)
+					if errMsg != nil {
+						cliCancel(errMsg.Error)
+					} else {
+						cliCancel(nil)
+					}
+					return
+				}
+				handlers.SetNDJSONHeaders(c)
+				handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
+				for _, line := range convert([]byte("[DONE]")) {
+					handlers.WriteNDJSONLine(c.Writer, line)
+				}
+				flusher.Flush()
+				cliCancel(nil)
+				return
+			}
+
+			// Success! Commit to streaming headers.
+			handlers.SetNDJSONHeaders(c)
+			handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
+			for _, line := range convert(chunk) {
+				handlers.WriteNDJSONLine(c.Writer, line)
+			}
+			flusher.Flush()
+
+			h.ForwardNDJSONStream(c, flusher, func(err error) { cliCancel(err) }, dataChan, errChan, convert)
+			return
+		}
+	}
+}
+
+// modelDetails builds the Ollama details object for a registry model entry.
+func modelDetails(model map[string]any) gin.H {
+	family, _ := model["owned_by"].(string)
+	return gin.H{
+		"format":             "",
+		"family":             family,
+		"families":           []string{family},
+		"parameter_size":     "",
+		"quantization_level": "",
+	}
+}
+
+// modelModifiedAt reports the model's creation time in the RFC 3339 form Ollama clients parse.
+func modelModifiedAt(model map[string]any) string {
+	var created int64
+	switch v := model["created"].(type) {
+	case int64:
+		created = v
+	case int:
+		created = int64(v)
+	case float64:
+		created = int64(v)
+	}
+	if created <= 0 {
+		return time.Unix(0, 0).UTC().Format(time.RFC3339)
+	}
+	return time.Unix(created, 0).UTC().Format(time.RFC3339)
+}
+
+// modelCapabilities derives the Ollama capability list from registry metadata.
+func modelCapabilities(info *registry.ModelInfo) []string {
+	capabilities := []string{"completion", "tools"}
+	if info == nil {
+		return capabilities
+	}
+	for _, modality := range info.SupportedInputModalities {
+		if strings.EqualFold(modality, "image") {
+			capabilities = append(capabilities, "vision")
+			break
+		}
+	}
+	if info.Thinking != nil {
+		capabilities = append(capabilities, "thinking")
+	}
+	return capabilities
+}
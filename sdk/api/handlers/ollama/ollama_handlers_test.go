@@ -0,0 +1,130 @@
+package ollama
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v7/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+const ollamaTestModel = "ollama-test-model"
+
+type chatCompletionsTestExecutor struct{}
+
+func (chatCompletionsTestExecutor) Identifier() string { return "ollama-test-executor" }
+
+func (chatCompletionsTestExecutor) Execute(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{Payload: []byte(`{"model":"ollama-test-model","choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":1}}`)}, nil
+}
+
+func (chatCompletionsTestExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	chunks := make(chan coreexecutor.StreamChunk, 3)
+	chunks <- coreexecutor.StreamChunk{Payload: []byte(`{"model":"ollama-test-model","choices":[{"index":0,"delta":{"content":"hel"}}]}`)}
+	chunks <- coreexecutor.StreamChunk{Payload: []byte(`{"model":"ollama-test-model","choices":[{"index":0,"delta":{"content":"lo"}}]}`)}
+	chunks <- coreexecutor.StreamChunk{Payload: []byte(`{"model":"ollama-test-model","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`)}
+	close(chunks)
+	return &coreexecutor.StreamResult{Chunks: chunks}, nil
+}
+
+func (chatCompletionsTestExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (chatCompletionsTestExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, errors.New("not implemented")
+}
+
+func (chatCompletionsTestExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func newOllamaTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	executor := chatCompletionsTestExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	auth := &coreauth.Auth{ID: "ollama-test-auth", Provider: executor.Identifier(), Status: coreauth.StatusActive}
+	if _, errRegister := manager.Register(context.Background(), auth); errRegister != nil {
+		t.Fatalf("register auth: %v", errRegister)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: ollamaTestModel, OwnedBy: "test"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	h := NewOllamaAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, manager))
+	router := gin.New()
+	router.POST("/api/chat", h.Chat)
+	router.POST("/api/generate", h.Generate)
+	router.GET("/api/tags", h.Tags)
+	router.POST("/api/show", h.Show)
+	return router
+}
+
+func TestOllamaChatStreamsNDJSON(t *testing.T) {
+	router := newOllamaTestRouter(t)
+	request := httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(`{"model":"ollama-test-model","messages":[{"role":"user","content":"hi"}]}`))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+	if got := recorder.Header().Get("Content-Type"); got != handlers.NDJSONContentType {
+		t.Fatalf("Content-Type = %q, want %q", got, handlers.NDJSONContentType)
+	}
+	var content strings.Builder
+	var lines []gjson.Result
+	scanner := bufio.NewScanner(strings.NewReader(recorder.Body.String()))
+	for scanner.Scan() {
+		if !gjson.Valid(scanner.Text()) {
+			t.Fatalf("line %q is not a JSON object", scanner.Text())
+		}
+		line := gjson.Parse(scanner.Text())
+		content.WriteString(line.Get("message.content").String())
+		lines = append(lines, line)
+	}
+	if content.String() != "hello" {
+		t.Fatalf("streamed content = %q, want hello", content.String())
+	}
+	if last := lines[len(lines)-1]; !last.Get("done").Bool() || last.Get("done_reason").String() != "stop" {
+		t.Fatalf("last line = %s, want a done object", last.Raw)
+	}
+}
+
+func TestOllamaGenerateNonStreaming(t *testing.T) {
+	router := newOllamaTestRouter(t)
+	request := httptest.NewRequest(http.MethodPost, "/api/generate", strings.NewReader(`{"model":"ollama-test-model","prompt":"hi","stream":false}`))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	body := gjson.Parse(recorder.Body.String())
+	if recorder.Code != http.StatusOK || body.Get("response").String() != "hello" || !body.Get("done").Bool() || body.Get("eval_count").Int() != 1 {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestOllamaTagsAndShow(t *testing.T) {
+	router := newOllamaTestRouter(t)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/tags", nil))
+	if !strings.Contains(gjson.Get(recorder.Body.String(), "models.#.name").Raw, ollamaTestModel) {
+		t.Fatalf("tags = %s, want %s listed", recorder.Body.String(), ollamaTestModel)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/show", strings.NewReader(`{"model":"missing-model"}`)))
+	if recorder.Code != http.StatusNotFound || gjson.Get(recorder.Body.String(), "error").String() == "" {
+		t.Fatalf("show missing model: status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+}
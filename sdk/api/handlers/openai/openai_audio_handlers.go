@@ -0,0 +1,210 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	audioHandlerType = "openai-audio"
+
+	audioTaskTranscription = "transcription"
+	audioTaskTranslation   = "translation"
+	audioTaskSpeech        = "speech"
+
+	// audioResponseObject marks executor responses that carry a non-JSON body
+	// (plain-text transcripts, synthesized audio) as base64 with its content type.
+	audioResponseObject = "audio.response"
+)
+
+// AudioTranscriptions handles the multipart /v1/audio/transcriptions endpoint.
+func (h *OpenAIAPIHandler) AudioTranscriptions(c *gin.Context) {
+	h.handleAudioUpload(c, audioTaskTranscription)
+}
+
+// AudioTranslations handles the multipart /v1/audio/translations endpoint.
+func (h *OpenAIAPIHandler) AudioTranslations(c *gin.Context) {
+	h.handleAudioUpload(c, audioTaskTranslation)
+}
+
+// AudioSpeech handles the JSON /v1/audio/speech endpoint.
+func (h *OpenAIAPIHandler) AudioSpeech(c *gin.Context) {
+	rawJSON, err := handlers.ReadRequestBody(c)
+	if err != nil {
+		writeAudioBadRequest(c, fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	if !gjson.ValidBytes(rawJSON) {
+		writeAudioBadRequest(c, "Invalid request: body must be valid JSON")
+		return
+	}
+	model := strings.TrimSpace(gjson.GetBytes(rawJSON, "model").String())
+	if model == "" {
+		writeAudioBadRequest(c, "Invalid request: model is required")
+		return
+	}
+	if strings.TrimSpace(gjson.GetBytes(rawJSON, "input").String()) == "" {
+		writeAudioBadRequest(c, "Invalid request: input is required")
+		return
+	}
+	envelope, err := sjson.SetBytes(rawJSON, "task", audioTaskSpeech)
+	if err != nil {
+		writeAudioBadRequest(c, fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	h.executeAudio(c, model, envelope)
+}
+
+// handleAudioUpload wraps a multipart audio upload into the JSON envelope understood by
+// the openai-audio translator and the OpenAI-compatible executor.
+func (h *OpenAIAPIHandler) handleAudioUpload(c *gin.Context, task string) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		writeAudioBadRequest(c, fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	model := strings.TrimSpace(c.PostForm("model"))
+	if model == "" {
+		writeAudioBadRequest(c, "Invalid request: model is required")
+		return
+	}
+	files := form.File["file"]
+	if len(files) == 0 || files[0] == nil {
+		writeAudioBadRequest(c, "Invalid request: file is required")
+		return
+	}
+	fileHeader := files[0]
+	src, err := fileHeader.Open()
+	if err != nil {
+		writeAudioBadRequest(c, fmt.Sprintf("Invalid request: open upload file failed: %v", err))
+		return
+	}
+	data, err := io.ReadAll(src)
+	if errClose := src.Close(); errClose != nil {
+		log.Errorf("openai audio: close upload file error: %v", errClose)
+	}
+	if err != nil {
+		writeAudioBadRequest(c, fmt.Sprintf("Invalid request: read upload file failed: %v", err))
+		return
+	}
+	if len(data) == 0 {
+		writeAudioBadRequest(c, "Invalid request: file is empty")
+		return
+	}
+
+	envelope := []byte(`{}`)
+	envelope, _ = sjson.SetBytes(envelope, "model", model)
+	envelope, _ = sjson.SetBytes(envelope, "task", task)
+	envelope, _ = sjson.SetBytes(envelope, "file.filename", fileHeader.Filename)
+	envelope, _ = sjson.SetBytes(envelope, "file.mime_type", audioUploadMimeType(fileHeader.Filename, fileHeader.Header.Get("Content-Type")))
+	envelope, _ = sjson.SetBytes(envelope, "file.data", base64.StdEncoding.EncodeToString(data))
+	envelope, _ = sjson.SetBytes(envelope, "fields", form.Value)
+	for _, key := range []string{"language", "prompt", "response_format"} {
+		if value := strings.TrimSpace(c.PostForm(key)); value != "" {
+			envelope, _ = sjson.SetBytes(envelope, key, value)
+		}
+	}
+	if raw := strings.TrimSpace(c.PostForm("temperature")); raw != "" {
+		temperature, errParse := strconv.ParseFloat(raw, 64)
+		if errParse != nil {
+			writeAudioBadRequest(c, "Invalid request: temperature must be a number")
+			return
+		}
+		envelope, _ = sjson.SetBytes(envelope, "temperature", temperature)
+	}
+	h.executeAudio(c, model, envelope)
+}
+
+// executeAudio runs an audio envelope through the auth manager and writes the result.
+// Non-streaming keep-alives are not used because the body may be binary audio.
+func (h *OpenAIAPIHandler) executeAudio(c *gin.Context, model string, envelope []byte) {
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	resp, upstreamHeaders, errMsg := h.ExecuteWithAuthManager(cliCtx, audioHandlerType, model, envelope, "")
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		if errMsg.Error != nil {
+			cliCancel(errMsg.Error)
+		} else {
+			cliCancel(nil)
+		}
+		return
+	}
+
+	contentType := "application/json"
+	body := resp
+	if gjson.GetBytes(resp, "object").String() == audioResponseObject {
+		decoded, errDecode := base64.StdEncoding.DecodeString(gjson.GetBytes(resp, "data").String())
+		if errDecode != nil {
+			errMsg = &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: fmt.Errorf("decode audio response: %w", errDecode)}
+			h.WriteErrorResponse(c, errMsg)
+			cliCancel(errMsg.Error)
+			return
+		}
+		body = decoded
+		if value := strings.TrimSpace(gjson.GetBytes(resp, "content_type").String()); value != "" {
+			contentType = value
+		}
+	}
+
+	handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
+	c.Header("Content-Type", contentType)
+	_, _ = c.Writer.Write(body)
+	cliCancel(nil)
+}
+
+// audioExtensionMimeTypes covers the upload formats accepted by OpenAI's audio API, which
+// the standard library only resolves when the host has a mime.types database.
+var audioExtensionMimeTypes = map[string]string{
+	".flac": "audio/flac",
+	".m4a":  "audio/mp4",
+	".mp3":  "audio/mpeg",
+	".mp4":  "audio/mp4",
+	".mpeg": "audio/mpeg",
+	".mpga": "audio/mpeg",
+	".oga":  "audio/ogg",
+	".ogg":  "audio/ogg",
+	".wav":  "audio/wav",
+	".webm": "audio/webm",
+}
+
+// audioUploadMimeType prefers the part's declared type and falls back to the file extension.
+func audioUploadMimeType(filename, declared string) string {
+	declared = strings.TrimSpace(declared)
+	if declared != "" && !strings.EqualFold(declared, "application/octet-stream") {
+		return declared
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	if byExt, ok := audioExtensionMimeTypes[ext]; ok {
+		return byExt
+	}
+	if byExt := mime.TypeByExtension(ext); byExt != "" {
+		return byExt
+	}
+	if declared != "" {
+		return declared
+	}
+	return "application/octet-stream"
+}
+
+func writeAudioBadRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: message,
+			Type:    "invalid_request_error",
+		},
+	})
+}
@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v7/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestAudioHandlersRejectInvalidRequests(t *testing.T) {
+	handler := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/v1/audio/transcriptions", handler.AudioTranscriptions)
+	router.POST("/v1/audio/speech", handler.AudioSpeech)
+
+	multipartRequest := func(withModel, withFile bool) *http.Request {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		if withModel {
+			_ = writer.WriteField("model", "whisper-1")
+		}
+		if withFile {
+			part, _ := writer.CreateFormFile("file", "a.wav")
+			_, _ = part.Write([]byte("RIFF"))
+		}
+		_ = writer.Close()
+		req := httptest.NewRequest(http.MethodPost, "/v1/audio/transcriptions", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req
+	}
+
+	for _, tc := range []struct {
+		name    string
+		req     *http.Request
+		message string
+	}{
+		{name: "transcription without model", req: multipartRequest(false, true), message: "model is required"},
+		{name: "transcription without file", req: multipartRequest(true, false), message: "file is required"},
+		{name: "speech without input", req: httptest.NewRequest(http.MethodPost, "/v1/audio/speech", strings.NewReader(`{"model":"tts-1"}`)), message: "input is required"},
+	} {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, tc.req)
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", tc.name, resp.Code)
+		}
+		if msg := gjson.GetBytes(resp.Body.Bytes(), "error.message").String(); !strings.Contains(msg, tc.message) {
+			t.Fatalf("%s: message = %q, want %q", tc.name, msg, tc.message)
+		}
+	}
+}
+
+func TestAudioUploadMimeType(t *testing.T) {
+	if got := audioUploadMimeType("clip.mp3", "application/octet-stream"); got != "audio/mpeg" {
+		t.Fatalf("mp3 mime = %q", got)
+	}
+	if got := audioUploadMimeType("clip.bin", "audio/ogg"); got != "audio/ogg" {
+		t.Fatalf("declared mime = %q", got)
+	}
+}
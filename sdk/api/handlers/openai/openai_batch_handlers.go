@@ -0,0 +1,319 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/access/ratelimit"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/batch"
+	. "github.com/router-for-me/CLIProxyAPI/v7/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/logging"
+	responsesconverter "github.com/router-for-me/CLIProxyAPI/v7/internal/translator/openai/openai/responses"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+var _ batch.Executor = (*OpenAIAPIHandler)(nil)
+
+// FilesUpload handles the multipart POST /v1/files endpoint.
+func (h *OpenAIAPIHandler) FilesUpload(c *gin.Context) {
+	manager := batch.Default()
+	if !manager.Enabled() {
+		writeBatchError(c, batch.ErrDisabled)
+		return
+	}
+	maxSize := manager.MaxFileSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		writeBatchBadRequest(c, fmt.Sprintf("Invalid request: file is required: %v", err))
+		return
+	}
+	if fileHeader.Size > maxSize {
+		writeBatchBadRequest(c, fmt.Sprintf("Invalid request: file exceeds the %d MB limit", maxSize>>20))
+		return
+	}
+	src, err := fileHeader.Open()
+	if err != nil {
+		writeBatchBadRequest(c, fmt.Sprintf("Invalid request: open upload file failed: %v", err))
+		return
+	}
+	content, err := io.ReadAll(src)
+	if errClose := src.Close(); errClose != nil {
+		log.Errorf("openai files: close upload file error: %v", errClose)
+	}
+	if err != nil {
+		writeBatchBadRequest(c, fmt.Sprintf("Invalid request: read upload file failed: %v", err))
+		return
+	}
+
+	file, err := manager.CreateFile(c.Request.Context(), batchOwner(c), fileHeader.Filename, strings.TrimSpace(c.PostForm("purpose")), content)
+	if err != nil {
+		writeBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, fileView(file))
+}
+
+// FilesList handles GET /v1/files.
+func (h *OpenAIAPIHandler) FilesList(c *gin.Context) {
+	files, err := batch.Default().ListFiles(c.Request.Context(), batchOwner(c), strings.TrimSpace(c.Query("purpose")))
+	if err != nil {
+		writeBatchError(c, err)
+		return
+	}
+	data := make([]batch.File, 0, len(files))
+	for _, file := range files {
+		data = append(data, fileView(file))
+	}
+	c.JSON(http.StatusOK, gin.H{"object": "list", "data": data, "has_more": false})
+}
+
+// FilesRetrieve handles GET /v1/files/:file_id.
+func (h *OpenAIAPIHandler) FilesRetrieve(c *gin.Context) {
+	file, err := batch.Default().GetFile(c.Request.Context(), batchOwner(c), c.Param("file_id"))
+	if err != nil {
+		writeBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, fileView(file))
+}
+
+// FilesContent handles GET /v1/files/:file_id/content.
+func (h *OpenAIAPIHandler) FilesContent(c *gin.Context) {
+	_, content, err := batch.Default().FileContent(c.Request.Context(), batchOwner(c), c.Param("file_id"))
+	if err != nil {
+		writeBatchError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/octet-stream", content)
+}
+
+// FilesDelete handles DELETE /v1/files/:file_id.
+func (h *OpenAIAPIHandler) FilesDelete(c *gin.Context) {
+	id := c.Param("file_id")
+	if err := batch.Default().DeleteFile(c.Request.Context(), batchOwner(c), id); err != nil {
+		writeBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "object": "file", "deleted": true})
+}
+
+// BatchesCreate handles POST /v1/batches.
+func (h *OpenAIAPIHandler) BatchesCreate(c *gin.Context) {
+	rawJSON, err := handlers.ReadRequestBody(c)
+	if err != nil {
+		writeBatchBadRequest(c, fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	var params batch.CreateParams
+	if errUnmarshal := json.Unmarshal(rawJSON, &params); errUnmarshal != nil {
+		writeBatchBadRequest(c, fmt.Sprintf("Invalid request: %v", errUnmarshal))
+		return
+	}
+	job, err := batch.Default().CreateJob(c.Request.Context(), batchOwner(c), params)
+	if err != nil {
+		writeBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, jobView(job))
+}
+
+// BatchesList handles GET /v1/batches.
+func (h *OpenAIAPIHandler) BatchesList(c *gin.Context) {
+	limit := 20
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		parsed, errParse := strconv.Atoi(raw)
+		if errParse != nil || parsed < 1 || parsed > 100 {
+			writeBatchBadRequest(c, "Invalid request: limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+	jobs, hasMore, err := batch.Default().ListJobs(c.Request.Context(), batchOwner(c), strings.TrimSpace(c.Query("after")), limit)
+	if err != nil {
+		writeBatchError(c, err)
+		return
+	}
+	data := make([]batch.Job, 0, len(jobs))
+	for _, job := range jobs {
+		data = append(data, jobView(job))
+	}
+	response := gin.H{"object": "list", "data": data, "has_more": hasMore}
+	if len(data) > 0 {
+		response["first_id"] = data[0].ID
+		response["last_id"] = data[len(data)-1].ID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// BatchesRetrieve handles GET /v1/batches/:batch_id.
+func (h *OpenAIAPIHandler) BatchesRetrieve(c *gin.Context) {
+	job, err := batch.Default().GetJob(c.Request.Context(), batchOwner(c), c.Param("batch_id"))
+	if err != nil {
+		writeBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, jobView(job))
+}
+
+// BatchesCancel handles POST /v1/batches/:batch_id/cancel.
+func (h *OpenAIAPIHandler) BatchesCancel(c *gin.Context) {
+	job, err := batch.Default().CancelJob(c.Request.Context(), batchOwner(c), c.Param("batch_id"))
+	if err != nil {
+		writeBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, jobView(job))
+}
+
+// ExecuteBatchRequest replays one batch line through the regular non-streaming execution
+// path on behalf of owner, so the key's model policy, rate limits and usage accounting
+// apply as if the client had sent the request itself.
+func (h *OpenAIAPIHandler) ExecuteBatchRequest(ctx context.Context, owner, endpoint string, body []byte) batch.Outcome {
+	requestID := logging.GenerateRequestID()
+	handlerType := OpenAI
+	switch endpoint {
+	case "/v1/responses":
+		handlerType = OpenaiResponse
+	case "/v1/embeddings":
+		handlerType = embeddingsHandlerType
+	}
+	body, _ = sjson.DeleteBytes(body, "stream")
+	model := gjson.GetBytes(body, "model").String()
+	if handlerType == OpenAI && shouldTreatAsResponsesFormat(body) {
+		body = responsesconverter.ConvertOpenAIResponsesRequestToOpenAIChatCompletions(model, body, false)
+	}
+
+	release, rejection := ratelimit.Default().Acquire(owner, time.Now())
+	if rejection != nil {
+		return batch.Outcome{StatusCode: http.StatusTooManyRequests, RequestID: requestID, Throttled: true, RetryAfter: rejection.RetryAfter}
+	}
+	defer release()
+
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+	if errReq != nil {
+		return batch.Outcome{StatusCode: http.StatusInternalServerError, RequestID: requestID, Body: handlers.BuildErrorResponseBody(http.StatusInternalServerError, errReq.Error())}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	ginCtx := &gin.Context{Request: req}
+	if owner != "" {
+		ginCtx.Set("userApiKey", owner)
+	}
+	logging.SetGinRequestID(ginCtx, requestID)
+	execCtx := logging.WithRequestID(ctx, requestID)
+	execCtx = logging.WithEndpoint(execCtx, http.MethodPost+" "+endpoint)
+	execCtx = context.WithValue(execCtx, "gin", ginCtx)
+	execCtx = context.WithValue(execCtx, "handler", h)
+
+	resp, _, errMsg := h.ExecuteWithAuthManager(execCtx, handlerType, model, body, "")
+	if errMsg == nil {
+		return batch.Outcome{StatusCode: http.StatusOK, Body: resp, RequestID: requestID}
+	}
+	return batchErrorOutcome(errMsg, requestID)
+}
+
+// batchErrorOutcome converts an execution error into a batch outcome. 429s (cooling
+// credentials or exhausted upstream quota) throttle the job instead of failing the line.
+func batchErrorOutcome(errMsg *interfaces.ErrorMessage, requestID string) batch.Outcome {
+	status := errMsg.StatusCode
+	if status <= 0 {
+		status = http.StatusInternalServerError
+	}
+	if status == http.StatusTooManyRequests {
+		return batch.Outcome{StatusCode: status, RequestID: requestID, Throttled: true, RetryAfter: batchRetryAfter(errMsg)}
+	}
+	if errMsg.DirectResponse && len(errMsg.Body) > 0 {
+		return batch.Outcome{StatusCode: status, Body: errMsg.Body, RequestID: requestID}
+	}
+	errText := http.StatusText(status)
+	if errMsg.Error != nil {
+		if text := strings.TrimSpace(errMsg.Error.Error()); text != "" {
+			errText = text
+		}
+	}
+	return batch.Outcome{StatusCode: status, Body: handlers.BuildErrorResponseBody(status, errText), RequestID: requestID}
+}
+
+func batchRetryAfter(errMsg *interfaces.ErrorMessage) time.Duration {
+	values := errMsg.Addon.Values("Retry-After")
+	if errMsg.Error != nil {
+		values = append(values, coreauth.SafeResponseHeaders(errMsg.Error).Values("Retry-After")...)
+	}
+	for _, value := range values {
+		if seconds, errParse := strconv.Atoi(strings.TrimSpace(value)); errParse == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 0
+}
+
+// batchOwner returns the client API key that scopes files and batches.
+func batchOwner(c *gin.Context) string {
+	value, exists := c.Get("userApiKey")
+	if !exists || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func fileView(file batch.File) batch.File {
+	file.Owner = ""
+	return file
+}
+
+func jobView(job batch.Job) batch.Job {
+	job.Owner = ""
+	return job
+}
+
+func writeBatchError(c *gin.Context, err error) {
+	var invalid *batch.InvalidRequestError
+	switch {
+	case errors.As(err, &invalid):
+		writeBatchBadRequest(c, invalid.Message)
+	case errors.Is(err, batch.ErrDisabled):
+		c.JSON(http.StatusNotFound, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{
+				Message: "The Files and Batch APIs are not enabled on this server",
+				Type:    "invalid_request_error",
+			},
+		})
+	case errors.Is(err, batch.ErrNotFound):
+		c.JSON(http.StatusNotFound, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{
+				Message: "No such object",
+				Type:    "invalid_request_error",
+			},
+		})
+	default:
+		log.Errorf("openai batch: %v", err)
+		c.JSON(http.StatusInternalServerError, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{
+				Message: err.Error(),
+				Type:    "server_error",
+			},
+		})
+	}
+}
+
+func writeBatchBadRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: message,
+			Type:    "invalid_request_error",
+		},
+	})
+}
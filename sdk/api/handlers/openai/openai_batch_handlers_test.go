@@ -0,0 +1,62 @@
+package openai
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v7/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestBatchErrorOutcomeThrottlesOnTooManyRequests(t *testing.T) {
+	addon := http.Header{}
+	addon.Set("Retry-After", "7")
+	outcome := batchErrorOutcome(&interfaces.ErrorMessage{
+		StatusCode: http.StatusTooManyRequests,
+		Error:      errors.New("all credentials for model m are cooling down"),
+		Addon:      addon,
+	}, "rid")
+	if !outcome.Throttled || outcome.RetryAfter != 7*time.Second {
+		t.Fatalf("outcome = %+v, want throttled with 7s retry", outcome)
+	}
+
+	outcome = batchErrorOutcome(&interfaces.ErrorMessage{
+		StatusCode: http.StatusBadRequest,
+		Error:      errors.New("bad request"),
+	}, "rid")
+	if outcome.Throttled || outcome.StatusCode != http.StatusBadRequest {
+		t.Fatalf("outcome = %+v, want a 400 line", outcome)
+	}
+	if got := gjson.GetBytes(outcome.Body, "error.message").String(); got != "bad request" {
+		t.Fatalf("error message = %q", got)
+	}
+}
+
+func TestBatchEndpointsReturnNotFoundWhenDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil))
+	router := gin.New()
+	router.GET("/v1/batches", handler.BatchesList)
+	router.POST("/v1/files", handler.FilesUpload)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/v1/batches", nil),
+		httptest.NewRequest(http.MethodPost, "/v1/files", strings.NewReader("")),
+	} {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		if resp.Code != http.StatusNotFound {
+			t.Fatalf("%s %s: status = %d, want 404", req.Method, req.URL.Path, resp.Code)
+		}
+		if !strings.Contains(resp.Body.String(), "not enabled") {
+			t.Fatalf("%s %s: body = %s", req.Method, req.URL.Path, resp.Body.String())
+		}
+	}
+}
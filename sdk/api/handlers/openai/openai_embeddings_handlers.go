@@ -0,0 +1,82 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers"
+	"github.com/tidwall/gjson"
+)
+
+const embeddingsHandlerType = "openai-embedding"
+
+// Embeddings handles the /v1/embeddings endpoint.
+// Requests are routed through the auth manager like chat completions; Gemini and
+// Vertex providers translate them to their native embedding APIs while
+// openai-compatibility providers receive the request unchanged.
+func (h *OpenAIAPIHandler) Embeddings(c *gin.Context) {
+	rawJSON, err := handlers.ReadRequestBody(c)
+	if err != nil {
+		writeEmbeddingsBadRequest(c, fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	if !gjson.ValidBytes(rawJSON) {
+		writeEmbeddingsBadRequest(c, "Invalid request: body must be valid JSON")
+		return
+	}
+	model := strings.TrimSpace(gjson.GetBytes(rawJSON, "model").String())
+	if model == "" {
+		writeEmbeddingsBadRequest(c, "Invalid request: model is required")
+		return
+	}
+	if input := gjson.GetBytes(rawJSON, "input"); !input.Exists() || input.Type == gjson.Null {
+		writeEmbeddingsBadRequest(c, "Invalid request: input is required")
+		return
+	}
+	if !isEmbeddingsModel(model) {
+		writeEmbeddingsBadRequest(c, fmt.Sprintf("Model %s does not support /v1/embeddings.", model))
+		return
+	}
+
+	c.Header("Content-Type", "application/json")
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	stopKeepAlive := h.StartNonStreamingKeepAlive(c, cliCtx)
+	resp, upstreamHeaders, errMsg := h.ExecuteWithAuthManager(cliCtx, embeddingsHandlerType, model, rawJSON, "")
+	stopKeepAlive()
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		if errMsg.Error != nil {
+			cliCancel(errMsg.Error)
+		} else {
+			cliCancel(nil)
+		}
+		return
+	}
+
+	handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
+	_, _ = c.Writer.Write(resp)
+	cliCancel(nil)
+}
+
+// isEmbeddingsModel rejects models the registry knows are not embedding-capable.
+// Unknown models are left to routing so prefixed aliases still resolve.
+func isEmbeddingsModel(model string) bool {
+	info := registry.LookupModelInfo(model)
+	if info == nil {
+		return true
+	}
+	return registry.IsEmbeddingModel(info)
+}
+
+func writeEmbeddingsBadRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: message,
+			Type:    "invalid_request_error",
+		},
+	})
+}
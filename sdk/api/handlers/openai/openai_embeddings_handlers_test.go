@@ -0,0 +1,60 @@
+package openai
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v7/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestEmbeddingsRejectsInvalidRequests(t *testing.T) {
+	modelRegistry := registry.GetGlobalRegistry()
+	clientID := "test-embeddings-validation"
+	modelRegistry.RegisterClient(clientID, "openai-compatibility", []*registry.ModelInfo{
+		{ID: "compat-chat-only", Object: "model", OwnedBy: "compat", Type: "openai-compatibility"},
+	})
+	t.Cleanup(func() { modelRegistry.UnregisterClient(clientID) })
+
+	handler := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil))
+	for _, tc := range []struct {
+		body    string
+		message string
+	}{
+		{body: `{"input":"hi"}`, message: "model is required"},
+		{body: `{"model":"gemini-embedding-001"}`, message: "input is required"},
+		{body: `{"model":"compat-chat-only","input":"hi"}`, message: "does not support /v1/embeddings"},
+	} {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.POST("/v1/embeddings", handler.Embeddings)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(tc.body)))
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", tc.body, resp.Code)
+		}
+		if msg := gjson.GetBytes(resp.Body.Bytes(), "error.message").String(); !strings.Contains(msg, tc.message) {
+			t.Fatalf("%s: message = %q, want %q", tc.body, msg, tc.message)
+		}
+	}
+}
+
+func TestFilterEmbeddingModels(t *testing.T) {
+	modelRegistry := registry.GetGlobalRegistry()
+	clientID := "test-embeddings-model-filter"
+	modelRegistry.RegisterClient(clientID, "openai-compatibility", []*registry.ModelInfo{
+		{ID: "compat-embed", Object: "model", OwnedBy: "compat", Type: registry.OpenAIEmbeddingModelType},
+		{ID: "compat-chat", Object: "model", OwnedBy: "compat", Type: "openai-compatibility"},
+	})
+	t.Cleanup(func() { modelRegistry.UnregisterClient(clientID) })
+
+	filtered := filterEmbeddingModels([]map[string]any{{"id": "compat-embed"}, {"id": "compat-chat"}})
+	if len(filtered) != 1 || filtered[0]["id"] != "compat-embed" {
+		t.Fatalf("filtered = %#v", filtered)
+	}
+}
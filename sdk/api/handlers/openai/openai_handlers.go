@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/gin-gonic/gin"
@@ -60,12 +61,15 @@ func (h *OpenAIAPIHandler) Models() []map[string]any {
 // and specifications in OpenAI-compatible format.
 func (h *OpenAIAPIHandler) OpenAIModels(c *gin.Context) {
 	if _, ok := c.Request.URL.Query()["client_version"]; ok {
-		c.JSON(http.StatusOK, h.codexClientModelsResponse())
+		c.JSON(http.StatusOK, h.codexClientModelsResponseFor(h.FilterModelsForAPIKey(c, h.Models())))
 		return
 	}
 
-	// Get all available models
-	allModels := h.Models()
+	// Get the models available to the calling API key
+	allModels := h.FilterModelsForAPIKey(c, h.Models())
+	if modelType := strings.ToLower(strings.TrimSpace(c.Query("type"))); modelType == "embedding" || modelType == "embeddings" {
+		allModels = filterEmbeddingModels(allModels)
+	}
 
 	// Filter to only include the 4 required fields: id, object, created, owned_by
 	filteredModels := make([]map[string]any, len(allModels))
@@ -94,6 +98,18 @@ func (h *OpenAIAPIHandler) OpenAIModels(c *gin.Context) {
 	})
 }
 
+// filterEmbeddingModels keeps models that can be called through /v1/embeddings.
+func filterEmbeddingModels(models []map[string]any) []map[string]any {
+	filtered := make([]map[string]any, 0, len(models))
+	for _, model := range models {
+		id, _ := model["id"].(string)
+		if registry.IsEmbeddingModel(registry.LookupModelInfo(id)) {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
+
 // ChatCompletions handles the /v1/chat/completions endpoint.
 // It determines whether the request is for a streaming or non-streaming response
 // and calls the appropriate handler based on the model provider.
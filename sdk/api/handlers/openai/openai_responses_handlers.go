@@ -58,6 +58,11 @@ type responsesSSEFramer struct {
 	terminalError        *interfaces.ErrorMessage
 	failureEvent         string
 	dataFrames           int
+	// previousResponseID is set on response objects of the stream when the handler
+	// expanded previous_response_id before translation.
+	previousResponseID string
+	// completedResponse is the response object of the terminal completed event.
+	completedResponse []byte
 }
 
 func (f *responsesSSEFramer) WriteChunk(w io.Writer, chunk []byte) {
@@ -160,8 +165,16 @@ func (f *responsesSSEFramer) repairFrame(frame []byte) []byte {
 	switch eventType {
 	case "response.output_item.done":
 		f.recordOutputItem(payload)
-	case "response.completed":
-		repaired := f.repairCompletedPayload(payload)
+	case "response.created", "response.in_progress":
+		repaired := withPreviousResponseID(payload, "response.", f.previousResponseID)
+		if !bytes.Equal(repaired, payload) {
+			return responsesSSEFrameWithData(frame, repaired)
+		}
+	case "response.completed", "response.incomplete":
+		repaired := withPreviousResponseID(f.repairCompletedPayload(payload), "response.", f.previousResponseID)
+		if response := gjson.GetBytes(repaired, "response"); response.IsObject() {
+			f.completedResponse = []byte(response.Raw)
+		}
 		if !bytes.Equal(repaired, payload) {
 			return responsesSSEFrameWithData(frame, repaired)
 		}
@@ -478,7 +491,7 @@ func (h *OpenAIResponsesAPIHandler) Models() []map[string]any {
 func (h *OpenAIResponsesAPIHandler) OpenAIResponsesModels(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"object": "list",
-		"data":   h.Models(),
+		"data":   h.FilterModelsForAPIKey(c, h.Models()),
 	})
 }
 
@@ -531,13 +544,18 @@ func (h *OpenAIResponsesAPIHandler) Responses(c *gin.Context) {
 	}
 
 	rawJSON = h.prepareCodexMultiAgentV2Tools(c, rawJSON)
+	rawJSON, storeTurn, errMsg := prepareResponsesStoreTurn(c, rawJSON)
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		return
+	}
 
 	// Check if the client requested a streaming response.
 	streamResult := gjson.GetBytes(rawJSON, "stream")
 	if streamResult.Type == gjson.True {
-		h.handleStreamingResponse(c, rawJSON)
+		h.handleStreamingResponse(c, rawJSON, storeTurn)
 	} else {
-		h.handleNonStreamingResponse(c, rawJSON)
+		h.handleNonStreamingResponse(c, rawJSON, storeTurn)
 	}
 
 }
@@ -593,7 +611,8 @@ func (h *OpenAIResponsesAPIHandler) Compact(c *gin.Context) {
 // Parameters:
 //   - c: The Gin context containing the HTTP request and response
 //   - rawJSON: The raw JSON bytes of the OpenAIResponses-compatible request
-func (h *OpenAIResponsesAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSON []byte) {
+//   - storeTurn: The responses store state of the request, nil when nothing is stored
+func (h *OpenAIResponsesAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSON []byte, storeTurn *responsesStoreTurn) {
 	c.Header("Content-Type", "application/json")
 
 	modelName := gjson.GetBytes(rawJSON, "model").String()
@@ -607,6 +626,8 @@ func (h *OpenAIResponsesAPIHandler) handleNonStreamingResponse(c *gin.Context, r
 		cliCancel(errMsg.Error)
 		return
 	}
+	resp = storeTurn.patchResponse(resp)
+	storeTurn.saveResponse(c, resp)
 	handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
 	_, _ = c.Writer.Write(resp)
 	cliCancel()
@@ -619,7 +640,8 @@ func (h *OpenAIResponsesAPIHandler) handleNonStreamingResponse(c *gin.Context, r
 // Parameters:
 //   - c: The Gin context containing the HTTP request and response
 //   - rawJSON: The raw JSON bytes of the OpenAIResponses-compatible request
-func (h *OpenAIResponsesAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON []byte) {
+//   - storeTurn: The responses store state of the request, nil when nothing is stored
+func (h *OpenAIResponsesAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON []byte, storeTurn *responsesStoreTurn) {
 	// Get the http.Flusher interface to manually flush the response.
 	flusher, ok := c.Writer.(http.Flusher)
 	if !ok {
@@ -647,7 +669,8 @@ func (h *OpenAIResponsesAPIHandler) handleStreamingResponse(c *gin.Context, rawJ
 	if isCodexResponsesClientRequest(c) {
 		failureEvent = "response.failed"
 	}
-	framer := &responsesSSEFramer{failureEvent: failureEvent}
+	framer := &responsesSSEFramer{failureEvent: failureEvent, previousResponseID: storeTurn.previousID()}
+	defer storeTurn.saveStreamedResponse(c, framer)
 	var initialOutput bytes.Buffer
 
 	// Peek at the first complete SSE data frame.
@@ -0,0 +1,369 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/responsestore"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers"
+	coresession "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/session"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	responsesInputItemsDefaultLimit = 20
+	responsesInputItemsMaxLimit     = 100
+)
+
+// responsesStoreTurn carries the stored-response state of one Responses request.
+type responsesStoreTurn struct {
+	store *responsestore.Store
+	scope string
+	model string
+	// previousResponseID is set when previous_response_id was expanded from the store.
+	previousResponseID string
+	// input is the full input item array sent upstream.
+	input []byte
+	// save is false when the client sent "store": false or the chain is incomplete.
+	save bool
+}
+
+// responsesStoreScope returns the caller scope of the client API key of c.
+func responsesStoreScope(c *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	value, exists := c.Get("userApiKey")
+	if !exists || value == nil {
+		return ""
+	}
+	return coresession.CallerScope(fmt.Sprint(value))
+}
+
+// prepareResponsesStoreTurn expands previous_response_id from the responses store into
+// the full conversation and decides whether the response is stored. The request is
+// returned unchanged when the store is disabled or previous_response_id is unknown, so
+// upstreams that keep their own conversation state still resolve it.
+func prepareResponsesStoreTurn(c *gin.Context, rawJSON []byte) ([]byte, *responsesStoreTurn, *interfaces.ErrorMessage) {
+	store := responsestore.Default()
+	if !store.Enabled() {
+		return rawJSON, nil, nil
+	}
+	turn := &responsesStoreTurn{
+		store: store,
+		scope: responsesStoreScope(c),
+		model: gjson.GetBytes(rawJSON, "model").String(),
+		save:  gjson.GetBytes(rawJSON, "store").Type != gjson.False,
+	}
+	input := gjson.GetBytes(rawJSON, "input")
+
+	previousID := strings.TrimSpace(gjson.GetBytes(rawJSON, "previous_response_id").String())
+	if previousID == "" {
+		items, errItems := responsestore.InputItems(input)
+		if errItems != nil {
+			// Leave malformed input for upstream validation and do not store the result.
+			return rawJSON, nil, nil
+		}
+		turn.input = items
+		return rawJSON, turn, nil
+	}
+
+	record, ok := store.Get(responsesStoreContext(c), turn.scope, previousID)
+	if !ok {
+		return rawJSON, nil, nil
+	}
+	conversation, errConversation := responsestore.Conversation(record, input)
+	if errConversation != nil {
+		body, _ := sjson.SetBytes([]byte(`{"error":{"type":"invalid_request_error","param":"input"}}`), "error.message", errConversation.Error())
+		return nil, nil, &interfaces.ErrorMessage{
+			StatusCode: http.StatusBadRequest,
+			Error:      errors.New(string(body)),
+		}
+	}
+	expanded, errSet := sjson.SetRawBytes(rawJSON, "input", conversation)
+	if errSet != nil {
+		return nil, nil, &interfaces.ErrorMessage{
+			StatusCode: http.StatusBadRequest,
+			Error:      fmt.Errorf("failed to expand previous_response_id: %w", errSet),
+		}
+	}
+	expanded, _ = sjson.DeleteBytes(expanded, "previous_response_id")
+	if turn.model == "" && record.Model != "" {
+		expanded, _ = sjson.SetBytes(expanded, "model", record.Model)
+		turn.model = record.Model
+	}
+	turn.previousResponseID = previousID
+	turn.input = conversation
+	return expanded, turn, nil
+}
+
+// previousID returns the expanded previous_response_id, echoed back on the response.
+func (t *responsesStoreTurn) previousID() string {
+	if t == nil {
+		return ""
+	}
+	return t.previousResponseID
+}
+
+// patchResponse restores previous_response_id on a response object, which translators
+// cannot echo because it was removed from the request before translation.
+func (t *responsesStoreTurn) patchResponse(response []byte) []byte {
+	return withPreviousResponseID(response, "", t.previousID())
+}
+
+// saveResponse stores response, a Responses API response object, together with the
+// input items that produced it.
+func (t *responsesStoreTurn) saveResponse(c *gin.Context, response []byte) {
+	if t == nil || !t.save || len(response) == 0 {
+		return
+	}
+	root := gjson.ParseBytes(response)
+	id := strings.TrimSpace(root.Get("id").String())
+	if id == "" || !root.IsObject() {
+		return
+	}
+	output := root.Get("output")
+	outputRaw := []byte("[]")
+	if output.IsArray() {
+		outputRaw = []byte(output.Raw)
+	}
+	model := root.Get("model").String()
+	if model == "" {
+		model = t.model
+	}
+	t.store.Put(responsesStoreContext(c), responsestore.Record{
+		ID:       id,
+		Scope:    t.scope,
+		Model:    model,
+		Input:    t.input,
+		Output:   outputRaw,
+		Response: append([]byte(nil), response...),
+	})
+}
+
+// saveStreamedResponse stores the response captured from a completed stream.
+func (t *responsesStoreTurn) saveStreamedResponse(c *gin.Context, framer *responsesSSEFramer) {
+	if t == nil || framer == nil || framer.terminalError != nil {
+		return
+	}
+	t.saveResponse(c, framer.completedResponse)
+}
+
+// withPreviousResponseID sets previous_response_id on the response object at path.
+func withPreviousResponseID(payload []byte, path, previousID string) []byte {
+	if previousID == "" || len(payload) == 0 {
+		return payload
+	}
+	response := gjson.ParseBytes(payload)
+	if path != "" {
+		response = response.Get(strings.TrimSuffix(path, "."))
+	}
+	if !response.IsObject() {
+		return payload
+	}
+	updated, errSet := sjson.SetBytes(payload, path+"previous_response_id", previousID)
+	if errSet != nil {
+		return payload
+	}
+	return updated
+}
+
+// responsesStoreContext detaches store calls from client cancellation so a response
+// completed just before the client hung up is still stored.
+func responsesStoreContext(c *gin.Context) context.Context {
+	if c == nil || c.Request == nil {
+		return context.Background()
+	}
+	return context.WithoutCancel(c.Request.Context())
+}
+
+// GetResponse handles GET /v1/responses/{id} for responses kept by the responses store.
+func (h *OpenAIResponsesAPIHandler) GetResponse(c *gin.Context) {
+	record, ok := lookupStoredResponse(c)
+	if !ok {
+		return
+	}
+	response := []byte(record.Response)
+	if !gjson.ParseBytes(response).IsObject() {
+		response = []byte(`{"object":"response","status":"completed"}`)
+		response, _ = sjson.SetBytes(response, "id", record.ID)
+		response, _ = sjson.SetBytes(response, "created_at", record.CreatedAt.Unix())
+		response, _ = sjson.SetBytes(response, "model", record.Model)
+		response, _ = sjson.SetRawBytes(response, "output", record.Output)
+	}
+	c.Data(http.StatusOK, "application/json", response)
+}
+
+// DeleteResponse handles DELETE /v1/responses/{id}.
+func (h *OpenAIResponsesAPIHandler) DeleteResponse(c *gin.Context) {
+	record, ok := lookupStoredResponse(c)
+	if !ok {
+		return
+	}
+	if !responsestore.Default().Delete(responsesStoreContext(c), record.Scope, record.ID) {
+		writeStoredResponseNotFound(c, record.ID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": record.ID, "object": "response", "deleted": true})
+}
+
+// ResponseInputItems handles GET /v1/responses/{id}/input_items. The list covers the
+// full conversation sent upstream, including items inherited through
+// previous_response_id. It supports the order, limit and after query parameters.
+func (h *OpenAIResponsesAPIHandler) ResponseInputItems(c *gin.Context) {
+	record, ok := lookupStoredResponse(c)
+	if !ok {
+		return
+	}
+	order := strings.ToLower(strings.TrimSpace(c.DefaultQuery("order", "desc")))
+	if order != "asc" && order != "desc" {
+		writeResponsesStoreBadRequest(c, "order must be 'asc' or 'desc'")
+		return
+	}
+	limit := responsesInputItemsDefaultLimit
+	if rawLimit := strings.TrimSpace(c.Query("limit")); rawLimit != "" {
+		parsed, errLimit := strconv.Atoi(rawLimit)
+		if errLimit != nil || parsed < 1 || parsed > responsesInputItemsMaxLimit {
+			writeResponsesStoreBadRequest(c, fmt.Sprintf("limit must be between 1 and %d", responsesInputItemsMaxLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	items := gjson.ParseBytes(record.Input).Array()
+	if order == "desc" {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	if after := strings.TrimSpace(c.Query("after")); after != "" {
+		for i, item := range items {
+			if item.Get("id").String() == after {
+				items = items[i+1:]
+				break
+			}
+		}
+	}
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+
+	list := []byte(`{"object":"list","data":[],"first_id":null,"last_id":null,"has_more":false}`)
+	for _, item := range items {
+		list, _ = sjson.SetRawBytes(list, "data.-1", []byte(item.Raw))
+	}
+	if len(items) > 0 {
+		if id := items[0].Get("id").String(); id != "" {
+			list, _ = sjson.SetBytes(list, "first_id", id)
+		}
+		if id := items[len(items)-1].Get("id").String(); id != "" {
+			list, _ = sjson.SetBytes(list, "last_id", id)
+		}
+	}
+	list, _ = sjson.SetBytes(list, "has_more", hasMore)
+	c.Data(http.StatusOK, "application/json", list)
+}
+
+// lookupStoredResponse resolves the {id} path parameter for the calling key and writes
+// the error response when it cannot.
+func lookupStoredResponse(c *gin.Context) (responsestore.Record, bool) {
+	id := strings.TrimSpace(c.Param("id"))
+	store := responsestore.Default()
+	if !store.Enabled() {
+		c.JSON(http.StatusNotFound, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{
+				Message: "Stored responses are not enabled on this server",
+				Type:    "invalid_request_error",
+			},
+		})
+		return responsestore.Record{}, false
+	}
+	record, ok := store.Get(responsesStoreContext(c), responsesStoreScope(c), id)
+	if !ok {
+		writeStoredResponseNotFound(c, id)
+		return responsestore.Record{}, false
+	}
+	return record, true
+}
+
+func writeStoredResponseNotFound(c *gin.Context, id string) {
+	c.JSON(http.StatusNotFound, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: fmt.Sprintf("Response with id '%s' not found.", id),
+			Type:    "invalid_request_error",
+		},
+	})
+}
+
+func writeResponsesStoreBadRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: message,
+			Type:    "invalid_request_error",
+		},
+	})
+}
+
+// restoreResponsesWebsocketState rebuilds the conversation state of a websocket session
+// from the responses store, so a client that reconnects and continues with
+// previous_response_id does not have to resend the full conversation.
+func restoreResponsesWebsocketState(c *gin.Context, payload []byte) (lastRequest, lastOutput []byte, lastID string, ok bool) {
+	store := responsestore.Default()
+	previousID := strings.TrimSpace(gjson.GetBytes(payload, "previous_response_id").String())
+	if previousID == "" || !store.Enabled() {
+		return nil, nil, "", false
+	}
+	record, found := store.Get(responsesStoreContext(c), responsesStoreScope(c), previousID)
+	if !found {
+		return nil, nil, "", false
+	}
+	request := []byte(`{}`)
+	if record.Model != "" {
+		request, _ = sjson.SetBytes(request, "model", record.Model)
+	}
+	if instructions := gjson.GetBytes(record.Response, "instructions"); instructions.Type == gjson.String {
+		request, _ = sjson.SetBytes(request, "instructions", instructions.String())
+	}
+	input := []byte(record.Input)
+	if !gjson.ValidBytes(input) {
+		input = []byte("[]")
+	}
+	request, _ = sjson.SetRawBytes(request, "input", input)
+	output := []byte(record.Output)
+	if !gjson.ValidBytes(output) {
+		output = []byte("[]")
+	}
+	return request, output, record.ID, true
+}
+
+// saveResponsesWebsocketTurn stores a completed websocket turn. request is the full
+// request sent upstream and output the output items of the completed response.
+func saveResponsesWebsocketTurn(c *gin.Context, request, output []byte, responseID string) {
+	store := responsestore.Default()
+	responseID = strings.TrimSpace(responseID)
+	if responseID == "" || !store.Enabled() || gjson.GetBytes(request, "store").Type == gjson.False {
+		return
+	}
+	input := gjson.GetBytes(request, "input")
+	if !input.IsArray() {
+		return
+	}
+	if !gjson.ValidBytes(output) {
+		output = []byte("[]")
+	}
+	store.Put(responsesStoreContext(c), responsestore.Record{
+		ID:     responseID,
+		Scope:  responsesStoreScope(c),
+		Model:  gjson.GetBytes(request, "model").String(),
+		Input:  []byte(input.Raw),
+		Output: append([]byte(nil), output...),
+	})
+}
@@ -0,0 +1,157 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/responsestore"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/api/handlers"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	coresession "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/session"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v7/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+type storeCaptureExecutor struct {
+	payloads [][]byte
+}
+
+func (e *storeCaptureExecutor) Identifier() string { return "store-test-provider" }
+
+func (e *storeCaptureExecutor) Execute(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	e.payloads = append(e.payloads, append([]byte(nil), req.Payload...))
+	n := len(e.payloads)
+	return coreexecutor.Response{Payload: []byte(fmt.Sprintf(
+		`{"id":"resp_%d","object":"response","model":"store-model","previous_response_id":null,"output":[{"id":"msg_%d","type":"message","role":"assistant","content":[{"type":"output_text","text":"reply %d"}]}]}`,
+		n, n, n,
+	))}, nil
+}
+
+func (e *storeCaptureExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (e *storeCaptureExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *storeCaptureExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, errors.New("not implemented")
+}
+
+func (e *storeCaptureExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestOpenAIResponsesStoreExpandsPreviousResponseID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	responsestore.Default().Apply(config.ResponsesStoreConfig{Enable: true}, "")
+	t.Cleanup(func() { responsestore.Default().Apply(config.ResponsesStoreConfig{}, "") })
+
+	executor := &storeCaptureExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	auth := &coreauth.Auth{ID: "store-auth", Provider: executor.Identifier(), Status: coreauth.StatusActive}
+	if _, errRegister := manager.Register(context.Background(), auth); errRegister != nil {
+		t.Fatalf("Register auth: %v", errRegister)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "store-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	h := NewOpenAIResponsesAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, manager))
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userApiKey", c.GetHeader("X-Test-Key"))
+	})
+	router.POST("/v1/responses", h.Responses)
+	router.GET("/v1/responses/:id", h.GetResponse)
+	router.DELETE("/v1/responses/:id", h.DeleteResponse)
+	router.GET("/v1/responses/:id/input_items", h.ResponseInputItems)
+	serve := func(method, path, body, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Test-Key", key)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(http.MethodPost, "/v1/responses", `{"model":"store-model","input":"hello"}`, "key-a"); rec.Code != http.StatusOK {
+		t.Fatalf("first turn status = %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := serve(http.MethodPost, "/v1/responses", `{"model":"store-model","previous_response_id":"resp_1","input":[{"type":"message","role":"user","content":"again"}]}`, "key-a")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("second turn status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := gjson.Get(rec.Body.String(), "previous_response_id").String(); got != "resp_1" {
+		t.Fatalf("previous_response_id = %q, want resp_1", got)
+	}
+	sent := gjson.ParseBytes(executor.payloads[1])
+	if sent.Get("previous_response_id").Exists() {
+		t.Fatalf("previous_response_id forwarded upstream: %s", sent.Raw)
+	}
+	if items := sent.Get("input").Array(); len(items) != 3 || items[0].Get("content.0.text").String() != "hello" || items[1].Get("id").String() != "msg_1" || items[2].Get("content").String() != "again" {
+		t.Fatalf("expanded input = %s", sent.Get("input").Raw)
+	}
+
+	if rec = serve(http.MethodGet, "/v1/responses/resp_2", "", "key-b"); rec.Code != http.StatusNotFound {
+		t.Fatalf("other key GET status = %d, want 404", rec.Code)
+	}
+	rec = serve(http.MethodGet, "/v1/responses/resp_2", "", "key-a")
+	if rec.Code != http.StatusOK || gjson.Get(rec.Body.String(), "output.0.id").String() != "msg_2" {
+		t.Fatalf("GET status = %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = serve(http.MethodGet, "/v1/responses/resp_2/input_items?order=asc&limit=2", "", "key-a")
+	list := gjson.Parse(rec.Body.String())
+	if rec.Code != http.StatusOK || len(list.Get("data").Array()) != 2 || !list.Get("has_more").Bool() || list.Get("last_id").String() != "msg_1" {
+		t.Fatalf("input_items status = %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = serve(http.MethodGet, "/v1/responses/resp_2/input_items?after=msg_1&order=asc", "", "key-a")
+	if items := gjson.Get(rec.Body.String(), "data").Array(); len(items) != 1 || items[0].Get("content").String() != "again" {
+		t.Fatalf("input_items after msg_1 = %s", rec.Body.String())
+	}
+
+	if rec = serve(http.MethodDelete, "/v1/responses/resp_2", "", "key-a"); rec.Code != http.StatusOK || !gjson.Get(rec.Body.String(), "deleted").Bool() {
+		t.Fatalf("DELETE status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec = serve(http.MethodGet, "/v1/responses/resp_2", "", "key-a"); rec.Code != http.StatusNotFound {
+		t.Fatalf("GET after delete status = %d, want 404", rec.Code)
+	}
+
+	rec = serve(http.MethodPost, "/v1/responses", `{"model":"store-model","previous_response_id":"resp_unknown","store":false,"input":"hi"}`, "key-a")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unknown previous id status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := gjson.GetBytes(executor.payloads[2], "previous_response_id").String(); got != "resp_unknown" {
+		t.Fatalf("unknown previous_response_id not forwarded unchanged: %s", executor.payloads[2])
+	}
+	if _, ok := responsestore.Default().Get(context.Background(), coresession.CallerScope("key-a"), "resp_3"); ok {
+		t.Fatal("response stored despite store: false")
+	}
+}
+
+func TestResponsesSSEFramerRestoresPreviousResponseIDAndCapturesCompleted(t *testing.T) {
+	var output bytes.Buffer
+	framer := &responsesSSEFramer{previousResponseID: "resp_prev"}
+	framer.WriteChunk(&output, []byte("event: response.created\ndata: {\"type\":\"response.created\",\"response\":{\"id\":\"resp_next\",\"previous_response_id\":null}}\n\n"))
+	framer.WriteChunk(&output, []byte("event: response.output_item.done\ndata: {\"type\":\"response.output_item.done\",\"output_index\":0,\"item\":{\"id\":\"msg_1\",\"type\":\"message\"}}\n\n"))
+	framer.WriteChunk(&output, []byte("event: response.completed\ndata: {\"type\":\"response.completed\",\"response\":{\"id\":\"resp_next\",\"output\":[]}}\n\n"))
+
+	if strings.Count(output.String(), `"previous_response_id":"resp_prev"`) != 2 {
+		t.Fatalf("previous_response_id not restored on response events: %s", output.String())
+	}
+	completed := gjson.ParseBytes(framer.completedResponse)
+	if completed.Get("id").String() != "resp_next" || completed.Get("output.0.id").String() != "msg_1" || completed.Get("previous_response_id").String() != "resp_prev" {
+		t.Fatalf("completed response = %s", framer.completedResponse)
+	}
+}
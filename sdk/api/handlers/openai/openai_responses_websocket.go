@@ -480,6 +480,15 @@ func (h *OpenAIResponsesAPIHandler) ResponsesWebsocket(c *gin.Context) {
 			}
 		}
 
+		if !nativeWebsocketPassthrough && len(lastRequest) == 0 {
+			if restoredRequest, restoredOutput, restoredID, ok := restoreResponsesWebsocketState(c, payload); ok {
+				lastRequest = restoredRequest
+				lastResponseOutput = restoredOutput
+				lastResponseID = restoredID
+				lastResponsePendingToolCallIDs = nil
+			}
+		}
+
 		var requestJSON []byte
 		var updatedLastRequest []byte
 		var errMsg *interfaces.ErrorMessage
@@ -655,6 +664,7 @@ func (h *OpenAIResponsesAPIHandler) ResponsesWebsocket(c *gin.Context) {
 			lastResponseOutput = completedOutput
 			lastResponseID = strings.TrimSpace(completedResponseID)
 			lastResponsePendingToolCallIDs = append([]string(nil), completedPendingToolCallIDs...)
+			saveResponsesWebsocketTurn(c, nextLastRequest, completedOutput, lastResponseID)
 		}
 	}
 }
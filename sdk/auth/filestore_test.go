@@ -1,11 +1,18 @@
 package auth
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/authcrypt"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/responsestore"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/pluginapi"
 )
@@ -146,6 +153,34 @@ func TestFileTokenStoreSaveExistingMetadataSetsFileAttributes(t *testing.T) {
 	}
 }
 
+func TestFileTokenStoreListIgnoresStoredResponses(t *testing.T) {
+	baseDir := t.TempDir()
+	if errWrite := os.WriteFile(filepath.Join(baseDir, "codex.json"), []byte(`{"type":"codex"}`), 0o600); errWrite != nil {
+		t.Fatalf("write auth file: %v", errWrite)
+	}
+	responses := responsestore.New()
+	responses.Apply(config.ResponsesStoreConfig{Enable: true, Backend: config.ResponsesStoreBackendFile}, filepath.Join(baseDir, "responses"))
+	if !responses.Put(context.Background(), responsestore.Record{ID: "resp_1", Input: []byte(`[]`), Output: []byte(`[]`)}) {
+		t.Fatal("responses store rejected the record")
+	}
+	if entries, _ := os.ReadDir(filepath.Join(baseDir, "responses")); len(entries) != 1 {
+		t.Fatalf("stored response files = %d, want 1", len(entries))
+	}
+
+	store := NewFileTokenStore()
+	store.SetBaseDir(baseDir)
+	auths, errList := store.List(context.Background())
+	if errList != nil {
+		t.Fatalf("List() error = %v", errList)
+	}
+	if len(auths) != 1 || auths[0].ID != "codex.json" {
+		for _, auth := range auths {
+			t.Logf("listed auth id=%s provider=%s", auth.ID, auth.Provider)
+		}
+		t.Fatalf("List() returned %d auths, want only codex.json", len(auths))
+	}
+}
+
 func TestFileTokenStoreSaveRejectsInvalidWeight(t *testing.T) {
 	baseDir := t.TempDir()
 	store := NewFileTokenStore()
@@ -341,3 +376,87 @@ func (f fileStoreMultiAuthParserFunc) ParseAuth(context.Context, pluginapi.AuthP
 func (f fileStoreMultiAuthParserFunc) ParseAuths(ctx context.Context, req pluginapi.AuthParseRequest) ([]*cliproxyauth.Auth, bool, error) {
 	return f(ctx, req)
 }
+
+func TestFileTokenStoreSealsAndListsEncryptedAuthFiles(t *testing.T) {
+	ring, errParse := authcrypt.ParseKeyring(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	if errParse != nil {
+		t.Fatalf("ParseKeyring() error = %v", errParse)
+	}
+	authcrypt.SetKeyring(ring)
+	t.Cleanup(func() { authcrypt.SetKeyring(nil) })
+
+	baseDir := t.TempDir()
+	store := NewFileTokenStore()
+	store.SetBaseDir(baseDir)
+	auth := &cliproxyauth.Auth{
+		ID:       "claude-user.json",
+		FileName: "claude-user.json",
+		Metadata: map[string]any{"type": "claude", "access_token": "secret-token"},
+	}
+	path, errSave := store.Save(context.Background(), auth)
+	if errSave != nil {
+		t.Fatalf("Save() error = %v", errSave)
+	}
+	raw, errRead := os.ReadFile(path)
+	if errRead != nil {
+		t.Fatalf("read saved auth file: %v", errRead)
+	}
+	if !authcrypt.IsSealed(raw) || bytes.Contains(raw, []byte("secret-token")) {
+		t.Fatalf("saved auth file = %s, want it sealed", raw)
+	}
+
+	auths, errList := store.List(context.Background())
+	if errList != nil {
+		t.Fatalf("List() error = %v", errList)
+	}
+	if len(auths) != 1 || auths[0].Metadata["access_token"] != "secret-token" {
+		t.Fatalf("List() = %#v, want the decrypted auth", auths)
+	}
+}
+
+// marshalingTokenStorage renders its content in memory and fails when asked to write plaintext.
+type marshalingTokenStorage struct {
+	meta map[string]any
+}
+
+func (s *marshalingTokenStorage) SetMetadata(meta map[string]any) { s.meta = meta }
+
+func (s *marshalingTokenStorage) MarshalToken() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": "claude", "access_token": "secret-token", "disabled": s.meta["disabled"]})
+}
+
+func (s *marshalingTokenStorage) SaveTokenToFile(string) error {
+	return errors.New("plaintext write")
+}
+
+func TestFileTokenStoreSealsTokenStorageInMemory(t *testing.T) {
+	ring, errParse := authcrypt.ParseKeyring(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	if errParse != nil {
+		t.Fatalf("ParseKeyring() error = %v", errParse)
+	}
+	authcrypt.SetKeyring(ring)
+	t.Cleanup(func() { authcrypt.SetKeyring(nil) })
+
+	baseDir := t.TempDir()
+	store := NewFileTokenStore()
+	store.SetBaseDir(baseDir)
+	auth := &cliproxyauth.Auth{ID: "claude-user.json", FileName: "claude-user.json", Storage: &marshalingTokenStorage{}}
+	path, errSave := store.Save(context.Background(), auth)
+	if errSave != nil {
+		t.Fatalf("Save() error = %v", errSave)
+	}
+	raw, errRead := os.ReadFile(path)
+	if errRead != nil {
+		t.Fatalf("read saved auth file: %v", errRead)
+	}
+	if !authcrypt.IsSealed(raw) || bytes.Contains(raw, []byte("secret-token")) {
+		t.Fatalf("saved auth file = %s, want it sealed", raw)
+	}
+	entries, errDir := os.ReadDir(baseDir)
+	if errDir != nil {
+		t.Fatalf("read auth dir: %v", errDir)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("auth dir holds %d entries, want only the sealed file", len(entries))
+	}
+}
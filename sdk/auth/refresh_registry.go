@@ -12,8 +12,16 @@ func init() {
 	registerRefreshLead("antigravity", func() Authenticator { return NewAntigravityAuthenticator() })
 	registerRefreshLead("kimi", func() Authenticator { return NewKimiAuthenticator() })
 	registerRefreshLead("xai", func() Authenticator { return NewXAIAuthenticator() })
+	// Azure OpenAI Entra ID tokens come from client credentials in config.yaml, so there
+	// is no login authenticator; the executor's Refresh fetches a new token.
+	cliproxyauth.RegisterRefreshLeadProvider("azure-openai", func() *time.Duration {
+		return &azureOpenAIRefreshLead
+	})
 }
 
+// azureOpenAIRefreshLead refreshes Entra ID tokens, which live about an hour, five minutes early.
+var azureOpenAIRefreshLead = 5 * time.Minute
+
 func registerRefreshLead(provider string, factory func() Authenticator) {
 	cliproxyauth.RegisterRefreshLeadProvider(provider, func() *time.Duration {
 		if factory == nil {
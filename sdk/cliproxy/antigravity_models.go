@@ -28,7 +28,7 @@ type antigravityModelCapabilityHints struct {
 }
 
 func (s *Service) fetchAntigravityModelCapabilityHintsForAuth(ctx context.Context, auth *coreauth.Auth) antigravityModelCapabilityHints {
-	if auth == nil || auth.Metadata == nil {
+	if s.offlineModels || auth == nil || auth.Metadata == nil {
 		return antigravityModelCapabilityHints{}
 	}
 	accessToken, _ := auth.Metadata["access_token"].(string)
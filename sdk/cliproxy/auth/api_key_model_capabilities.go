@@ -180,6 +180,10 @@ func compileAPIKeyModelCapabilitiesForAuth(cfg *internalconfig.Config, auth *Aut
 		if entry := resolveVertexAPIKeyConfig(cfg, auth); entry != nil {
 			compileConfiguredModelCapabilities(out, entry.Models, "gemini")
 		}
+	case "bedrock":
+		if entry := resolveBedrockAPIKeyConfig(cfg, auth); entry != nil {
+			compileConfiguredModelCapabilities(out, entry.Models, "claude")
+		}
 	default:
 		providerKey, compatName := "", ""
 		if auth.Attributes != nil {
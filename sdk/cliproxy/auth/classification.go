@@ -45,6 +45,12 @@ func (a *Auth) AuthKind() string {
 	return ""
 }
 
+// ExecutorKey returns the provider key the conductor routes this auth under; for
+// OpenAI-compatible providers it is derived from the compatibility entry name.
+func (a *Auth) ExecutorKey() string {
+	return executorKeyFromAuth(a)
+}
+
 // AuthSourceKind returns where the Auth entry came from at runtime.
 func (a *Auth) AuthSourceKind() string {
 	if a == nil {
@@ -60,6 +60,9 @@ type Result struct {
 	Error *Error
 	// Options carries execution request options (headers, metadata, etc.) for result tracking.
 	Options cliproxyexecutor.Options
+	// Headers carries the upstream response headers of a successful execution so
+	// reported quota windows can be tracked.
+	Headers http.Header
 }
 
 // Selector chooses an auth candidate for execution.
@@ -143,9 +146,19 @@ type Manager struct {
 	// apiKeyModelRouting atomically publishes per-auth aliases and configured capabilities.
 	apiKeyModelRouting atomic.Value
 
+	// discoveredModelAliases holds per-auth alias -> upstream model mappings learned by
+	// model discovery. Configured aliases take precedence. Guarded by mu.
+	discoveredModelAliases map[string]map[string]string
+
 	// modelPoolOffsets tracks per-auth alias pool rotation state.
 	modelPoolOffsets map[string]int
 
+	// quotaPressure maps the credentials whose quota windows were above
+	// quotaPressureThreshold when last observed to when that pressure ends. A zero
+	// threshold means the map has not been built. Guarded by mu.
+	quotaPressure          map[string]time.Time
+	quotaPressureThreshold float64
+
 	// runtimeConfig stores the latest application config for request-time decisions.
 	// It is initialized in NewManager; never Load() before first Store().
 	runtimeConfig atomic.Value
@@ -726,6 +726,8 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 		auth.recordRecentRequest(now, result.Success)
 		if result.Success {
 			auth.Success++
+			auth.recordQuotaWindows(result.Headers, now)
+			m.noteQuotaPressureLocked(auth, now)
 		} else {
 			auth.Failed++
 		}
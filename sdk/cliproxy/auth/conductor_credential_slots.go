@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"sync"
+)
+
+// CredentialSlots caps how many requests of one caller run on the same credential at
+// once. Requests executed with WithCredentialSlots skip credentials whose slots are
+// full and wait for a slot when every eligible credential is busy.
+type CredentialSlots struct {
+	limit int
+
+	mu       sync.Mutex
+	inUse    map[string]int
+	released chan struct{}
+}
+
+// NewCredentialSlots returns slots allowing limit concurrent requests per credential.
+// A limit below one allows one.
+func NewCredentialSlots(limit int) *CredentialSlots {
+	if limit < 1 {
+		limit = 1
+	}
+	return &CredentialSlots{limit: limit, inUse: make(map[string]int), released: make(chan struct{})}
+}
+
+type credentialSlotsContextKey struct{}
+
+// WithCredentialSlots returns ctx whose requests are limited by slots.
+func WithCredentialSlots(ctx context.Context, slots *CredentialSlots) context.Context {
+	return context.WithValue(ctx, credentialSlotsContextKey{}, slots)
+}
+
+func credentialSlotsFromContext(ctx context.Context) *CredentialSlots {
+	if ctx == nil {
+		return nil
+	}
+	slots, _ := ctx.Value(credentialSlotsContextKey{}).(*CredentialSlots)
+	return slots
+}
+
+// available reports whether authID has a free slot. Nil slots allow every credential.
+func (s *CredentialSlots) available(authID string) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inUse[authID] < s.limit
+}
+
+// acquire takes a slot of authID. It returns false when the slots are full.
+func (s *CredentialSlots) acquire(authID string) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inUse[authID] >= s.limit {
+		return false
+	}
+	s.inUse[authID]++
+	return true
+}
+
+// release returns a slot taken by acquire. An empty authID is ignored.
+func (s *CredentialSlots) release(authID string) {
+	if s == nil || authID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inUse[authID] <= 0 {
+		return
+	}
+	if s.inUse[authID]--; s.inUse[authID] == 0 {
+		delete(s.inUse, authID)
+	}
+	close(s.released)
+	s.released = make(chan struct{})
+}
+
+// watch returns a channel closed on the next release and whether a slot is held on
+// a credential outside skip. Callers take it before picking so a release racing the
+// pick is not missed.
+func (s *CredentialSlots) watch(skip map[string]struct{}) (<-chan struct{}, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for authID := range s.inUse {
+		if _, ok := skip[authID]; !ok {
+			return s.released, true
+		}
+	}
+	return s.released, false
+}
+
+// waitForRelease blocks until released is closed. It returns false when ctx ends first.
+func waitForRelease(ctx context.Context, released <-chan struct{}) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-released:
+		return true
+	}
+}
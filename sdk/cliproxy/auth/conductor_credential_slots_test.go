@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+)
+
+func TestExecute_CredentialSlotsCapRequestsPerCredential(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+
+	var mu sync.Mutex
+	running := make(map[string]int)
+	peak := make(map[string]int)
+	m.RegisterExecutor(&mockCustomErrorExecutor{identifier: "claude", executeFn: func(_ context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+		mu.Lock()
+		running[auth.ID]++
+		if running[auth.ID] > peak[auth.ID] {
+			peak[auth.ID] = running[auth.ID]
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		running[auth.ID]--
+		mu.Unlock()
+		return cliproxyexecutor.Response{Payload: []byte(`{}`)}, nil
+	}})
+
+	reg := registry.GetGlobalRegistry()
+	for _, auth := range []*Auth{
+		{ID: "slots-a", Provider: "claude", Status: StatusActive},
+		{ID: "slots-b", Provider: "claude", Status: StatusActive},
+	} {
+		reg.RegisterClient(auth.ID, "claude", []*registry.ModelInfo{{ID: "slots-model"}})
+		authID := auth.ID
+		t.Cleanup(func() { reg.UnregisterClient(authID) })
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+	}
+
+	ctx := WithCredentialSlots(context.Background(), NewCredentialSlots(1))
+	var wg sync.WaitGroup
+	errs := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.Execute(ctx, []string{"claude"}, cliproxyexecutor.Request{Model: "slots-model"}, cliproxyexecutor.Options{}); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for authID, n := range peak {
+		if n > 1 {
+			t.Fatalf("credential %s ran %d requests at once, want at most 1", authID, n)
+		}
+	}
+}
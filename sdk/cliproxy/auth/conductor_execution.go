@@ -12,6 +12,7 @@ import (
 	"sync"
 
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/tracing"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
 	cliproxysession "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/session"
@@ -36,6 +37,13 @@ func claudeOAuthRequestCancellation(ctx context.Context, auth *Auth, err error)
 // Execute performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
 func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	ctx, span := startExecuteSpan(ctx, "auth.execute", providers, req, opts)
+	result, err := m.execute(ctx, providers, req, opts)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (m *Manager) execute(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	req, opts = cliproxysession.Enrich(req, opts)
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
@@ -47,42 +55,48 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxye
 	}
 
 	_, maxRetryCredentials, maxWait := m.retrySettings()
-
-	var lastErr error
-	retryModel := authSelectionModelFromOptions(opts, req.Model)
-	for attempt := 0; ; attempt++ {
-		resp, errExec := m.executeMixedOnce(ctx, normalized, req, opts, maxRetryCredentials)
-		if errExec == nil {
-			return resp, nil
-		}
-		if isRequestTerminatedError(errExec) || isRequestStopError(errExec) {
-			return cliproxyexecutor.Response{}, unwrapRequestStopError(errExec)
-		}
-		lastErr = errExec
-		wait, shouldRetry := m.shouldRetryAfterError(errExec, attempt, normalized, retryModel, maxWait)
-		if !shouldRetry {
-			break
-		}
-		if errWait := waitForCooldown(ctx, wait, maxWait); errWait != nil {
-			return cliproxyexecutor.Response{}, errWait
+	if hops := m.modelFallbackHopsFor(normalized, req, opts); len(hops) > 0 {
+		resp, hop, errFallback := executeModelFallbacks(ctx, hops, req, opts, func(hopCtx context.Context, hopProviders []string, hopReq cliproxyexecutor.Request, hopOpts cliproxyexecutor.Options, more bool) (cliproxyexecutor.Response, error) {
+			return retryAfterCooldownUnless(hopCtx, m, hopProviders, authSelectionModelFromOptions(hopOpts, hopReq.Model), maxWait, modelFallbackHandOff(more), func() (cliproxyexecutor.Response, error) {
+				return m.executeMixedOnce(hopCtx, hopProviders, hopReq, hopOpts, maxRetryCredentials)
+			})
+		})
+		if errFallback != nil {
+			return cliproxyexecutor.Response{}, errFallback
 		}
+		resp.Headers = markModelFallbackHop(resp.Headers, hop)
+		return resp, nil
 	}
-	if lastErr != nil {
-		lastErr = unwrapRequestStopError(lastErr)
-		if hasAntigravityProvider(normalized) && shouldAttemptAntigravityCreditsFallback(m, lastErr, normalized) {
-			if resp, ok, errCredits := m.tryAntigravityCreditsExecute(ctx, req, opts); errCredits != nil {
-				return cliproxyexecutor.Response{}, errCredits
-			} else if ok {
-				return resp, nil
-			}
-		}
+
+	resp, errExec := retryAfterCooldown(ctx, m, normalized, authSelectionModelFromOptions(opts, req.Model), maxWait, func() (cliproxyexecutor.Response, error) {
+		return m.executeMixedOnce(ctx, normalized, req, opts, maxRetryCredentials)
+	})
+	if errExec == nil {
+		return resp, nil
+	}
+	lastErr := unwrapRequestStopError(errExec)
+	if isRequestTerminatedError(errExec) || isRequestStopError(errExec) {
 		return cliproxyexecutor.Response{}, lastErr
 	}
-	return cliproxyexecutor.Response{}, &Error{Code: "auth_not_found", Message: "no auth available"}
+	if ctx.Err() == nil && hasAntigravityProvider(normalized) && shouldAttemptAntigravityCreditsFallback(m, lastErr, normalized) {
+		if resp, ok, errCredits := m.tryAntigravityCreditsExecute(ctx, req, opts); errCredits != nil {
+			return cliproxyexecutor.Response{}, errCredits
+		} else if ok {
+			return resp, nil
+		}
+	}
+	return cliproxyexecutor.Response{}, lastErr
 }
 
 // It supports multiple providers for the same model and round-robins the starting provider per model.
 func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	ctx, span := startExecuteSpan(ctx, "auth.execute_count", providers, req, opts)
+	result, err := m.executeCount(ctx, providers, req, opts)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (m *Manager) executeCount(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	req, opts = cliproxysession.Enrich(req, opts)
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
@@ -95,34 +109,22 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req clip
 
 	_, maxRetryCredentials, maxWait := m.retrySettings()
 
-	var lastErr error
-	retryModel := authSelectionModelFromOptions(opts, req.Model)
-	for attempt := 0; ; attempt++ {
-		resp, errExec := m.executeCountMixedOnce(ctx, normalized, req, opts, maxRetryCredentials)
-		if errExec == nil {
-			return resp, nil
-		}
-		if isRequestTerminatedError(errExec) || isRequestStopError(errExec) {
-			return cliproxyexecutor.Response{}, unwrapRequestStopError(errExec)
-		}
-		lastErr = errExec
-		wait, shouldRetry := m.shouldRetryAfterError(errExec, attempt, normalized, retryModel, maxWait)
-		if !shouldRetry {
-			break
-		}
-		if errWait := waitForCooldown(ctx, wait, maxWait); errWait != nil {
-			return cliproxyexecutor.Response{}, errWait
-		}
-	}
-	if lastErr != nil {
-		return cliproxyexecutor.Response{}, unwrapRequestStopError(lastErr)
-	}
-	return cliproxyexecutor.Response{}, &Error{Code: "auth_not_found", Message: "no auth available"}
+	resp, errExec := retryAfterCooldown(ctx, m, normalized, authSelectionModelFromOptions(opts, req.Model), maxWait, func() (cliproxyexecutor.Response, error) {
+		return m.executeCountMixedOnce(ctx, normalized, req, opts, maxRetryCredentials)
+	})
+	return resp, unwrapRequestStopError(errExec)
 }
 
 // ExecuteStream performs a streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
 func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+	ctx, span := startExecuteSpan(ctx, "auth.execute_stream", providers, req, opts)
+	result, err := m.executeStream(ctx, providers, req, opts)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (m *Manager) executeStream(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
 	req, opts = cliproxysession.Enrich(req, opts)
 	if m.HomeEnabled() {
 		if unlockSession := m.lockHomeWebsocketSession(ctx, opts); unlockSession != nil {
@@ -135,42 +137,47 @@ func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cli
 	}
 
 	_, maxRetryCredentials, maxWait := m.retrySettings()
-
-	var lastErr error
-	retryModel := authSelectionModelFromOptions(opts, req.Model)
-	for attempt := 0; ; attempt++ {
-		result, errStream := m.executeStreamMixedOnce(ctx, normalized, req, opts, maxRetryCredentials)
-		if errStream == nil {
-			return result, nil
-		}
-		if isRequestTerminatedError(errStream) || isRequestStopError(errStream) {
-			return nil, unwrapRequestStopError(errStream)
-		}
-		lastErr = errStream
-		wait, shouldRetry := m.shouldRetryAfterError(errStream, attempt, normalized, retryModel, maxWait)
-		if !shouldRetry {
-			break
-		}
-		if errWait := waitForCooldown(ctx, wait, maxWait); errWait != nil {
-			return nil, errWait
-		}
-	}
-	if lastErr != nil {
-		lastErr = unwrapRequestStopError(lastErr)
-		if hasAntigravityProvider(normalized) && shouldAttemptAntigravityCreditsFallback(m, lastErr, normalized) {
-			if result, ok, errCredits := m.tryAntigravityCreditsExecuteStream(ctx, req, opts); errCredits != nil {
-				return nil, errCredits
-			} else if ok {
-				return result, nil
+	if hops := m.modelFallbackHopsFor(normalized, req, opts); len(hops) > 0 {
+		result, hop, errFallback := executeModelFallbacks(ctx, hops, req, opts, func(hopCtx context.Context, hopProviders []string, hopReq cliproxyexecutor.Request, hopOpts cliproxyexecutor.Options, more bool) (*cliproxyexecutor.StreamResult, error) {
+			return retryAfterCooldownUnless(hopCtx, m, hopProviders, authSelectionModelFromOptions(hopOpts, hopReq.Model), maxWait, modelFallbackHandOff(more), func() (*cliproxyexecutor.StreamResult, error) {
+				return m.executeStreamHedgeable(hopCtx, hopProviders, hopReq, hopOpts, maxRetryCredentials)
+			})
+		})
+		if errFallback != nil {
+			var bootstrapErr *streamBootstrapError
+			if errors.As(errFallback, &bootstrapErr) && bootstrapErr != nil {
+				return streamErrorResult(bootstrapErr.Headers(), bootstrapErr.cause), nil
 			}
+			return nil, errFallback
 		}
-		var bootstrapErr *streamBootstrapError
-		if errors.As(lastErr, &bootstrapErr) && bootstrapErr != nil {
-			return streamErrorResult(bootstrapErr.Headers(), bootstrapErr.cause), nil
+		if result != nil {
+			result.Headers = markModelFallbackHop(result.Headers, hop)
 		}
+		return result, nil
+	}
+
+	result, errStream := retryAfterCooldown(ctx, m, normalized, authSelectionModelFromOptions(opts, req.Model), maxWait, func() (*cliproxyexecutor.StreamResult, error) {
+		return m.executeStreamHedgeable(ctx, normalized, req, opts, maxRetryCredentials)
+	})
+	if errStream == nil {
+		return result, nil
+	}
+	lastErr := unwrapRequestStopError(errStream)
+	if isRequestTerminatedError(errStream) || isRequestStopError(errStream) {
 		return nil, lastErr
 	}
-	return nil, &Error{Code: "auth_not_found", Message: "no auth available"}
+	if ctx.Err() == nil && hasAntigravityProvider(normalized) && shouldAttemptAntigravityCreditsFallback(m, lastErr, normalized) {
+		if result, ok, errCredits := m.tryAntigravityCreditsExecuteStream(ctx, req, opts); errCredits != nil {
+			return nil, errCredits
+		} else if ok {
+			return result, nil
+		}
+	}
+	var bootstrapErr *streamBootstrapError
+	if errors.As(lastErr, &bootstrapErr) && bootstrapErr != nil {
+		return streamErrorResult(bootstrapErr.Headers(), bootstrapErr.cause), nil
+	}
+	return nil, lastErr
 }
 
 type requestToFormatResolver interface {
@@ -221,7 +228,7 @@ func requestToFormat(provider string, executor ProviderExecutor, req cliproxyexe
 		}
 	}
 	source := opts.SourceFormat.String()
-	if source == "openai-image" || source == "openai-video" {
+	if source == "openai-image" || source == "openai-video" || source == "openai-embedding" {
 		return opts.SourceFormat
 	}
 	if opts.Alt == "responses/compact" && !opts.Stream {
@@ -241,6 +248,10 @@ func requestToFormat(provider string, executor ProviderExecutor, req cliproxyexe
 	case "antigravity":
 		return sdktranslator.FormatAntigravity
 	default:
+		// OpenAI-compatible providers receive audio envelopes untranslated.
+		if source == "openai-audio" {
+			return opts.SourceFormat
+		}
 		return sdktranslator.FormatOpenAI
 	}
 }
@@ -288,7 +299,15 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 	tried := make(map[string]struct{})
 	attempted := make(map[string]struct{})
 	var lastErr error
+	var attemptSpan *authAttemptSpan
+	defer func() { attemptSpan.end() }()
+	slots := credentialSlotsFromContext(ctx)
+	heldSlot := ""
+	defer func() { slots.release(heldSlot) }()
 	for {
+		attemptSpan.end()
+		slots.release(heldSlot)
+		heldSlot = ""
 		if !homeMode && maxRetryCredentials > 0 && len(attempted) >= maxRetryCredentials {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
@@ -299,13 +318,27 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 		if homeMode {
 			pickOpts = withHomeAuthCount(opts, homeAuthCount)
 		}
+		released, slotsHeld := slots.watch(tried)
 		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, pickOpts, tried)
+		if errPick == nil && !slots.acquire(auth.ID) {
+			// Another request of the caller took the last slot since the pick.
+			continue
+		}
+		if errPick != nil && slotsHeld {
+			// The untried credentials may only be busy with the caller's other requests.
+			if !waitForRelease(ctx, released) {
+				return cliproxyexecutor.Response{}, ctx.Err()
+			}
+			continue
+		}
 		if errPick != nil {
+			traceAuthUnavailable(ctx, errPick)
 			if shouldReturnLastErrorOnPickFailure(homeMode, lastErr, errPick) {
 				return cliproxyexecutor.Response{}, lastErr
 			}
 			return cliproxyexecutor.Response{}, errPick
 		}
+		heldSlot = auth.ID
 
 		entry := logEntryWithRequestID(ctx)
 		debugLogAuthSelection(entry, auth, provider, routeModel)
@@ -321,12 +354,15 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 
 		models, pooled, aliasResult, routing := m.preparedExecutionModelsWithAlias(auth, routeModel)
 		if len(models) == 0 {
+			traceAuthSkip(ctx, auth, provider, "no_execution_models")
 			continue
 		}
 		attempted[auth.ID] = struct{}{}
+		execCtx, attemptSpan = startAuthAttemptSpan(execCtx, auth, provider, routeModel, len(attempted))
 		var errPrepare error
 		auth, errPrepare = m.prepareRequestAuth(execCtx, executor, auth)
 		if errPrepare != nil {
+			attemptSpan.fail(errPrepare)
 			if errCancel := claudeOAuthRequestCancellation(execCtx, auth, errPrepare); errCancel != nil {
 				return cliproxyexecutor.Response{}, errCancel
 			}
@@ -353,6 +389,12 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 			if !restoreExecutionModel {
 				execReq = attachResolvedAPIKeyModelInfo(routing, execReq, auth, routeModel, upstreamModel)
 			}
+			cache, cacheAttempt := m.responseCacheAttempt(execCtx, provider, execReq, execOpts, routeModel)
+			if cache != nil {
+				if cached, ok := cache.Lookup(execCtx, cacheAttempt); ok {
+					return cached, nil
+				}
+			}
 			resp, errExec := executor.Execute(execCtx, auth, execReq, execOpts)
 			if errExec != nil {
 				if errCtx := execCtx.Err(); errCtx != nil {
@@ -374,6 +416,7 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 			}
 			result := Result{AuthID: auth.ID, Provider: provider, Model: resultModel, Success: errExec == nil, Options: execOpts}
 			if errExec != nil {
+				attemptSpan.fail(errExec)
 				result.Error = resultErrorFromError(errExec)
 				if ra := retryAfterFromError(errExec); ra != nil {
 					result.RetryAfter = ra
@@ -407,9 +450,13 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 				}
 				continue
 			}
+			result.Headers = resp.Headers
 			m.MarkResult(execCtx, result)
 			attemptAliasResult := resolveAttemptAliasResult(routing, auth, routeModel, upstreamModel, aliasResult)
 			rewriteForceMappedResponse(&resp, attemptAliasResult)
+			if cache != nil {
+				cache.Store(execCtx, cacheAttempt, resp)
+			}
 			return resp, nil
 		}
 		if authErr != nil {
@@ -448,7 +495,10 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 	tried := make(map[string]struct{})
 	attempted := make(map[string]struct{})
 	var lastErr error
+	var attemptSpan *authAttemptSpan
+	defer func() { attemptSpan.end() }()
 	for {
+		attemptSpan.end()
 		if !homeMode && maxRetryCredentials > 0 && len(attempted) >= maxRetryCredentials {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
@@ -461,6 +511,7 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 		}
 		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, pickOpts, tried)
 		if errPick != nil {
+			traceAuthUnavailable(ctx, errPick)
 			if shouldReturnLastErrorOnPickFailure(homeMode, lastErr, errPick) {
 				return cliproxyexecutor.Response{}, lastErr
 			}
@@ -481,12 +532,15 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 
 		models, pooled, aliasResult, routing := m.preparedExecutionModelsWithAlias(auth, routeModel)
 		if len(models) == 0 {
+			traceAuthSkip(ctx, auth, provider, "no_execution_models")
 			continue
 		}
 		attempted[auth.ID] = struct{}{}
+		execCtx, attemptSpan = startAuthAttemptSpan(execCtx, auth, provider, routeModel, len(attempted))
 		var errPrepare error
 		auth, errPrepare = m.prepareRequestAuth(execCtx, executor, auth)
 		if errPrepare != nil {
+			attemptSpan.fail(errPrepare)
 			if errCancel := claudeOAuthRequestCancellation(execCtx, auth, errPrepare); errCancel != nil {
 				return cliproxyexecutor.Response{}, errCancel
 			}
@@ -534,6 +588,7 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 			}
 			result := Result{AuthID: auth.ID, Provider: provider, Model: resultModel, Success: errExec == nil, Options: execOpts}
 			if errExec != nil {
+				attemptSpan.fail(errExec)
 				result.Error = resultErrorFromError(errExec)
 				if ra := retryAfterFromError(errExec); ra != nil {
 					result.RetryAfter = ra
@@ -571,6 +626,7 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 				}
 				continue
 			}
+			result.Headers = resp.Headers
 			m.MarkResult(execCtx, result)
 			attemptAliasResult := resolveAttemptAliasResult(routing, auth, routeModel, upstreamModel, aliasResult)
 			rewriteForceMappedResponse(&resp, attemptAliasResult)
@@ -614,7 +670,10 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 	attempted := make(map[string]struct{})
 	unauthorizedRefreshTried := make(map[string]struct{})
 	var lastErr error
+	var attemptSpan *authAttemptSpan
+	defer func() { attemptSpan.end() }()
 	for {
+		attemptSpan.end()
 		if !homeMode && maxRetryCredentials > 0 && len(attempted) >= maxRetryCredentials {
 			if lastErr != nil {
 				return nil, lastErr
@@ -642,6 +701,7 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 			auth, executor, provider, errPick = m.pickNextMixed(ctx, providers, routeModel, pickOpts, tried)
 		}
 		if errPick != nil {
+			traceAuthUnavailable(ctx, errPick)
 			if shouldReturnLastErrorOnPickFailure(homeMode, lastErr, errPick) {
 				return nil, lastErr
 			}
@@ -653,6 +713,15 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 			}
 			return nil, &Error{Code: "executor_not_found", Message: "executor not registered"}
 		}
+		if !streamHedgeClaimFromContext(ctx).claim(auth.ID) {
+			tried[auth.ID] = struct{}{}
+			traceAuthSkip(ctx, auth, provider, "hedge_auth_in_use")
+			if selection != nil {
+				selection.End("hedge_auth_in_use")
+				return nil, streamHedgeAuthInUseError()
+			}
+			continue
+		}
 		if selection != nil {
 			if _, refreshedAlready := unauthorizedRefreshTried[auth.ID]; refreshedAlready {
 				selection.End("repeated_refresh_auth")
@@ -695,6 +764,7 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 			aliasResult.OriginalAlias = responseAlias
 		}
 		if len(models) == 0 {
+			traceAuthSkip(ctx, auth, provider, "no_execution_models")
 			if selection != nil {
 				releaseAttempt()
 				if errEnd := m.endHomeSelectionBeforeRedispatch(ctx, selection, "no_execution_models"); errEnd != nil {
@@ -704,6 +774,7 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 			continue
 		}
 		attempted[auth.ID] = struct{}{}
+		execCtx, attemptSpan = startAuthAttemptSpan(execCtx, auth, provider, routeModel, len(attempted))
 		var errPrepare error
 		if selection != nil {
 			auth, errPrepare = m.prepareHomeRequestAuth(execCtx, executor, selection)
@@ -711,6 +782,7 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 			auth, errPrepare = m.prepareRequestAuth(execCtx, executor, auth)
 		}
 		if errPrepare != nil {
+			attemptSpan.fail(errPrepare)
 			if selection == nil {
 				if errCancel := claudeOAuthRequestCancellation(execCtx, auth, errPrepare); errCancel != nil {
 					return nil, errCancel
@@ -746,6 +818,7 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 		}
 		streamResult, errStream := m.executeStreamWithModelPool(execCtx, executor, auth, provider, execReq, execOpts, routeModel, streamExecutionModel, models, pooled, aliasResult, routing, !homeMode || selection != nil, selection != nil, unauthorizedRefreshTried)
 		if errStream != nil {
+			attemptSpan.fail(errStream)
 			if selection != nil {
 				releaseAttempt()
 				if errEnd := m.endHomeSelectionBeforeRedispatch(ctx, selection, "stream_start_failed"); errEnd != nil {
@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/logging"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+// Stream hedging attempt numbers, also reported to usage sinks.
+const (
+	streamHedgePrimaryAttempt = 1
+	streamHedgeSecondAttempt  = 2
+)
+
+// streamHedgeClaims tracks the credentials used by the attempts of one hedged
+// stream so the hedge never runs on a credential the primary already holds.
+type streamHedgeClaims struct {
+	mu     sync.Mutex
+	owners map[string]int
+}
+
+// streamHedgeClaim is the view of the shared claims held by one attempt. The zero
+// value allows every credential.
+type streamHedgeClaim struct {
+	claims  *streamHedgeClaims
+	attempt int
+}
+
+type streamHedgeClaimContextKey struct{}
+
+func withStreamHedgeClaim(ctx context.Context, claims *streamHedgeClaims, attempt int) context.Context {
+	return context.WithValue(ctx, streamHedgeClaimContextKey{}, streamHedgeClaim{claims: claims, attempt: attempt})
+}
+
+func streamHedgeClaimFromContext(ctx context.Context) streamHedgeClaim {
+	if ctx == nil {
+		return streamHedgeClaim{}
+	}
+	claim, _ := ctx.Value(streamHedgeClaimContextKey{}).(streamHedgeClaim)
+	return claim
+}
+
+// available reports whether authID is unclaimed or already claimed by this attempt.
+func (c streamHedgeClaim) available(authID string) bool {
+	if c.claims == nil {
+		return true
+	}
+	c.claims.mu.Lock()
+	defer c.claims.mu.Unlock()
+	owner, ok := c.claims.owners[authID]
+	return !ok || owner == c.attempt
+}
+
+// claim reserves authID for this attempt. It returns false when the sibling
+// attempt already holds it.
+func (c streamHedgeClaim) claim(authID string) bool {
+	if c.claims == nil {
+		return true
+	}
+	c.claims.mu.Lock()
+	defer c.claims.mu.Unlock()
+	if owner, ok := c.claims.owners[authID]; ok {
+		return owner == c.attempt
+	}
+	c.claims.owners[authID] = c.attempt
+	return true
+}
+
+func streamHedgeAuthInUseError() error {
+	return &Error{Code: "auth_not_found", Message: "no other credential available for the stream hedge", HTTPStatus: http.StatusServiceUnavailable}
+}
+
+// streamHedgeSelection captures the credential selected by one attempt so only the
+// winning attempt is reported through the caller's selected-auth callbacks.
+type streamHedgeSelection struct {
+	mu        sync.Mutex
+	authID    string
+	authIndex string
+}
+
+// captureSelection returns opts with its selected-auth callbacks redirected to s.
+func (s *streamHedgeSelection) captureSelection(opts cliproxyexecutor.Options) cliproxyexecutor.Options {
+	opts.Metadata = cloneRequestMetadata(opts.Metadata)
+	opts.Metadata[cliproxyexecutor.SelectedAuthCallbackMetadataKey] = func(authID string) {
+		s.mu.Lock()
+		s.authID = authID
+		s.mu.Unlock()
+	}
+	opts.Metadata[cliproxyexecutor.SelectedAuthIndexCallbackMetadataKey] = func(authIndex string) {
+		s.mu.Lock()
+		s.authIndex = authIndex
+		s.mu.Unlock()
+	}
+	return opts
+}
+
+// publish reports the captured credential through the callbacks in meta.
+func (s *streamHedgeSelection) publish(meta map[string]any) {
+	s.mu.Lock()
+	authID, authIndex := s.authID, s.authIndex
+	s.mu.Unlock()
+	if callback, ok := meta[cliproxyexecutor.SelectedAuthCallbackMetadataKey].(func(string)); ok && callback != nil && authID != "" {
+		callback(authID)
+	}
+	if callback, ok := meta[cliproxyexecutor.SelectedAuthIndexCallbackMetadataKey].(func(string)); ok && callback != nil && authIndex != "" {
+		callback(authIndex)
+	}
+}
+
+// streamHedgeOutcome is the bootstrap result of one attempt.
+type streamHedgeOutcome struct {
+	attempt   int
+	result    *cliproxyexecutor.StreamResult
+	err       error
+	selection *streamHedgeSelection
+	cancel    context.CancelFunc
+}
+
+// discard cancels the attempt and drains any stream it already started.
+func (o streamHedgeOutcome) discard() {
+	o.cancel()
+	if o.result != nil {
+		discardStreamChunks(o.result.Chunks)
+	}
+}
+
+// streamHedgingDelay returns the configured hedging delay for the request.
+func (m *Manager) streamHedgingDelay(ctx context.Context, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (time.Duration, bool) {
+	if streamHedgeClaimFromContext(ctx).claims != nil || cliproxyexecutor.DownstreamWebsocket(ctx) {
+		return 0, false
+	}
+	cfg := m.runtimeConfigSnapshot()
+	if cfg == nil || len(cfg.StreamHedging) == 0 {
+		return 0, false
+	}
+	route := logging.GetEndpoint(ctx)
+	if idx := strings.LastIndexByte(route, ' '); idx >= 0 {
+		route = route[idx+1:]
+	}
+	return cfg.StreamHedgingDelay(requestedModelAliasFromOptions(opts, req.Model), route)
+}
+
+// executeStreamHedgeable runs one streaming dispatch, hedging it when a
+// stream-hedging rule matches the request.
+func (m *Manager) executeStreamHedgeable(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, maxRetryCredentials int) (*cliproxyexecutor.StreamResult, error) {
+	if delay, ok := m.streamHedgingDelay(ctx, req, opts); ok {
+		return m.executeStreamHedged(ctx, providers, req, opts, maxRetryCredentials, delay)
+	}
+	return m.executeStreamMixedOnce(ctx, providers, req, opts, maxRetryCredentials)
+}
+
+// executeStreamHedged starts the primary attempt and, when it has not produced a
+// first chunk within delay, a hedge attempt on a different credential. The first
+// attempt to deliver a chunk is streamed to the caller and the other one is
+// cancelled, which ends its execution lifecycle. Each attempt records its own
+// result, so usage and cooldown accounting see both. In Home mode the hedge is
+// dispatched through Home like any other request and is therefore subject to the
+// credential concurrency limits.
+func (m *Manager) executeStreamHedged(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, maxRetryCredentials int, delay time.Duration) (*cliproxyexecutor.StreamResult, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	entry := logEntryWithRequestID(ctx)
+	claims := &streamHedgeClaims{owners: make(map[string]int, 2)}
+	outcomes := make(chan streamHedgeOutcome, 2)
+	cancels := make(map[int]context.CancelFunc, 2)
+	start := func(attempt int) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		cancels[attempt] = cancel
+		attemptCtx = coreusage.WithHedgeAttempt(withStreamHedgeClaim(attemptCtx, claims, attempt), attempt)
+		selection := &streamHedgeSelection{}
+		attemptOpts := selection.captureSelection(opts)
+		go func() {
+			result, errStream := m.executeStreamMixedOnce(attemptCtx, providers, req, attemptOpts, maxRetryCredentials)
+			outcomes <- streamHedgeOutcome{attempt: attempt, result: result, err: errStream, selection: selection, cancel: cancel}
+		}()
+	}
+
+	start(streamHedgePrimaryAttempt)
+	pending := 1
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	hedgeTimer := timer.C
+	errs := make(map[int]error, 2)
+	for {
+		select {
+		case <-hedgeTimer:
+			hedgeTimer = nil
+			start(streamHedgeSecondAttempt)
+			pending++
+			entry.Debugf("stream hedging: no first chunk after %s, starting hedge attempt", delay)
+		case outcome := <-outcomes:
+			pending--
+			if outcome.err == nil {
+				for attempt, cancel := range cancels {
+					if attempt != outcome.attempt {
+						cancel()
+					}
+				}
+				discardStreamHedgeOutcomes(outcomes, pending)
+				outcome.selection.publish(opts.Metadata)
+				if len(cancels) > 1 {
+					entry.Debugf("stream hedging: attempt %d delivered the first chunk", outcome.attempt)
+				}
+				return releaseStreamHedgeAttempt(ctx, outcome.result, outcome.cancel), nil
+			}
+			outcome.cancel()
+			errs[outcome.attempt] = outcome.err
+			if pending == 0 {
+				if errPrimary := errs[streamHedgePrimaryAttempt]; errPrimary != nil {
+					return nil, errPrimary
+				}
+				return nil, outcome.err
+			}
+		case <-ctx.Done():
+			for _, cancel := range cancels {
+				cancel()
+			}
+			discardStreamHedgeOutcomes(outcomes, pending)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// discardStreamHedgeOutcomes drains the outcomes of attempts that are still
+// bootstrapping once a winner is known.
+func discardStreamHedgeOutcomes(outcomes <-chan streamHedgeOutcome, pending int) {
+	if pending <= 0 {
+		return
+	}
+	go func() {
+		for i := 0; i < pending; i++ {
+			outcome := <-outcomes
+			outcome.discard()
+		}
+	}()
+}
+
+// releaseStreamHedgeAttempt forwards the winning stream and cancels its attempt
+// context once the stream ends or the caller goes away.
+func releaseStreamHedgeAttempt(ctx context.Context, result *cliproxyexecutor.StreamResult, cancel context.CancelFunc) *cliproxyexecutor.StreamResult {
+	if result == nil || result.Chunks == nil {
+		cancel()
+		return result
+	}
+	out := make(chan cliproxyexecutor.StreamChunk)
+	go func() {
+		defer close(out)
+		defer cancel()
+		for {
+			select {
+			case <-ctx.Done():
+				discardStreamChunks(result.Chunks)
+				return
+			case chunk, ok := <-result.Chunks:
+				if !ok {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					discardStreamChunks(result.Chunks)
+					return
+				case out <- chunk:
+				}
+			}
+		}
+	}()
+	return &cliproxyexecutor.StreamResult{Headers: result.Headers, Chunks: out}
+}
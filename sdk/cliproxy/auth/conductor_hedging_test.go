@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+func TestExecuteStream_HedgeServesFasterCredentialAndCancelsLoser(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetConfig(&internalconfig.Config{StreamHedging: []internalconfig.StreamHedgingRule{{Models: []string{"hedge-*"}, DelayMS: 20}}})
+
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	slowCancelled := make(chan struct{})
+	m.RegisterExecutor(&customStreamMockExecutor{streamFn: func(ctx context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+		mu.Lock()
+		attempts[auth.ID] = coreusage.HedgeAttemptFromContext(ctx)
+		mu.Unlock()
+		ch := make(chan cliproxyexecutor.StreamChunk, 1)
+		if auth.ID == "hedge-slow" {
+			go func() {
+				defer close(ch)
+				<-ctx.Done()
+				close(slowCancelled)
+			}()
+			return &cliproxyexecutor.StreamResult{Chunks: ch}, nil
+		}
+		ch <- cliproxyexecutor.StreamChunk{Payload: []byte(auth.ID)}
+		close(ch)
+		return &cliproxyexecutor.StreamResult{Chunks: ch}, nil
+	}})
+
+	reg := registry.GetGlobalRegistry()
+	for _, auth := range []*Auth{
+		{ID: "hedge-slow", Provider: "claude", Status: StatusActive, Attributes: map[string]string{"priority": "10"}},
+		{ID: "hedge-fast", Provider: "claude", Status: StatusActive},
+	} {
+		reg.RegisterClient(auth.ID, "claude", []*registry.ModelInfo{{ID: "hedge-model"}})
+		authID := auth.ID
+		t.Cleanup(func() { reg.UnregisterClient(authID) })
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+	}
+
+	var selected string
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{
+		cliproxyexecutor.SelectedAuthCallbackMetadataKey: func(authID string) { selected = authID },
+	}}
+	result, err := m.ExecuteStream(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "hedge-model"}, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var payload []byte
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("stream chunk error = %v", chunk.Err)
+		}
+		payload = append(payload, chunk.Payload...)
+	}
+	if string(payload) != "hedge-fast" {
+		t.Fatalf("payload = %q, want hedge-fast", payload)
+	}
+	if selected != "hedge-fast" {
+		t.Fatalf("selected auth = %q, want hedge-fast", selected)
+	}
+	select {
+	case <-slowCancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("losing attempt was not cancelled")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts["hedge-slow"] != 1 || attempts["hedge-fast"] != 2 {
+		t.Fatalf("hedge attempts = %v, want slow=1 fast=2", attempts)
+	}
+}
@@ -109,6 +109,18 @@ func (m *Manager) executeHome(ctx context.Context, providers []string, req clipr
 				selection.End("attempt_canceled")
 				return cliproxyexecutor.Response{}, errCtx
 			}
+			var cache ResponseCache
+			var cacheAttempt *ResponseCacheAttempt
+			if !countTokens {
+				cache, cacheAttempt = m.responseCacheAttempt(execCtx, selection.Provider, execReq, execOpts, routeModel)
+			}
+			if cache != nil {
+				if cached, ok := cache.Lookup(execCtx, cacheAttempt); ok {
+					releaseAttempt()
+					selection.End("response_cache_hit")
+					return cached, nil
+				}
+			}
 			var response cliproxyexecutor.Response
 			var errExecute error
 			var effectiveAuthMu sync.RWMutex
@@ -171,6 +183,9 @@ func (m *Manager) executeHome(ctx context.Context, providers []string, req clipr
 				releaseAttempt()
 				attemptAliasResult := resolveAttemptAliasResult(routing, preparedAuth, routeModel, upstreamModel, aliasResult)
 				rewriteForceMappedResponse(&response, attemptAliasResult)
+				if cache != nil {
+					cache.Store(execCtx, cacheAttempt, response)
+				}
 				if !m.retainHomeWebsocketSelection(ctx, opts, routeModel, selection) {
 					selection.End("completed")
 				}
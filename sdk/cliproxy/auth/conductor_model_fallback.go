@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/util"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+// Response headers reporting which model-fallbacks hop served a request.
+const (
+	ModelFallbackHopHeader   = "X-CPA-Fallback-Hop"
+	ModelFallbackModelHeader = "X-CPA-Fallback-Model"
+)
+
+// modelFallbackHop is one resolved target of a model-fallbacks chain.
+type modelFallbackHop struct {
+	// index is the 1-based position of the hop in the chain.
+	index     int
+	model     string
+	providers []string
+	authKind  string
+}
+
+// modelFallbackHops resolves the model-fallbacks chain configured for the requested
+// model. It returns nil when no chain applies.
+func (m *Manager) modelFallbackHops(providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) []modelFallbackHop {
+	cfg := m.runtimeConfigSnapshot()
+	if cfg == nil || len(cfg.ModelFallbacks) == 0 {
+		return nil
+	}
+	requested := requestedModelAliasFromOptions(opts, req.Model)
+	chain, ok := cfg.ModelFallbackChain(requested)
+	if !ok {
+		chain, ok = cfg.ModelFallbackChain(req.Model)
+	}
+	if !ok {
+		if parsed := thinking.ParseSuffix(requested); parsed.HasSuffix {
+			chain, ok = cfg.ModelFallbackChain(parsed.ModelName)
+		}
+	}
+	if !ok {
+		return nil
+	}
+	suffix := thinking.ParseSuffix(req.Model)
+	hops := make([]modelFallbackHop, 0, len(chain.Targets))
+	for i, target := range chain.Targets {
+		model := target.Model
+		switch {
+		case model == "":
+			model = req.Model
+		case suffix.HasSuffix && !thinking.ParseSuffix(model).HasSuffix:
+			model = fmt.Sprintf("%s(%s)", model, suffix.RawSuffix)
+		}
+		var hopProviders []string
+		switch {
+		case target.Provider != "":
+			hopProviders = []string{target.Provider}
+		case target.Model == "":
+			hopProviders = providers
+		default:
+			hopProviders = util.GetProviderName(thinking.ParseSuffix(model).ModelName)
+		}
+		hops = append(hops, modelFallbackHop{
+			index:     i + 1,
+			model:     model,
+			providers: m.normalizeProviders(hopProviders),
+			authKind:  normalizeAuthKind(target.AuthKind),
+		})
+	}
+	return hops
+}
+
+// prepare returns the context, request and options used to execute the hop. The
+// request model is replaced so executors translate the payload for the hop's
+// provider format, and auth selection follows the hop model instead of the
+// client-requested one.
+func (hop modelFallbackHop) prepare(ctx context.Context, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (context.Context, cliproxyexecutor.Request, cliproxyexecutor.Options) {
+	opts = ensureRequestedModelMetadata(opts, requestedModelAliasFromOptions(opts, req.Model))
+	delete(opts.Metadata, cliproxyexecutor.AuthSelectionModelMetadataKey)
+	req.Model = hop.model
+	ctx = coreusage.WithFallbackHop(ctx, hop.index)
+	if hop.authKind != "" {
+		ctx = withRequiredAuthKind(ctx, hop.authKind)
+	}
+	return ctx, req, opts
+}
+
+func (hop modelFallbackHop) String() string {
+	parts := []string{hop.model}
+	if len(hop.providers) > 0 {
+		parts = append(parts, "via "+strings.Join(hop.providers, ","))
+	}
+	if hop.authKind != "" {
+		parts = append(parts, "("+hop.authKind+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// modelFallbackHopsFor resolves the model-fallbacks chain for a request. Home mode
+// routes every request through the Home dispatcher, so fallbacks never apply there.
+func (m *Manager) modelFallbackHopsFor(providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) []modelFallbackHop {
+	if m.HomeEnabled() {
+		return nil
+	}
+	return m.modelFallbackHops(providers, req, opts)
+}
+
+// executeModelFallbacks runs the hops in order and returns the first success.
+// It moves to the next hop when the current one fails with an error that another
+// provider or credential kind could avoid; any other error is returned at once.
+// run is told whether a later hop remains so it only waits out cooldowns on the
+// last one.
+func executeModelFallbacks[T any](ctx context.Context, hops []modelFallbackHop, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, run func(context.Context, []string, cliproxyexecutor.Request, cliproxyexecutor.Options, bool) (T, error)) (T, modelFallbackHop, error) {
+	var zero T
+	var lastErr error
+	entry := logEntryWithRequestID(ctx)
+	for i, hop := range hops {
+		if len(hop.providers) == 0 {
+			lastErr = &Error{Code: "provider_not_found", Message: fmt.Sprintf("no provider for fallback model %s", hop.model), HTTPStatus: http.StatusBadGateway}
+			entry.Debugf("model fallback: hop %d (%s) has no provider", hop.index, hop)
+			continue
+		}
+		hopCtx, hopReq, hopOpts := hop.prepare(ctx, req, opts)
+		result, errExec := run(hopCtx, hop.providers, hopReq, hopOpts, hasLaterModelFallbackHop(hops[i+1:]))
+		if errExec == nil {
+			if hop.index > 1 {
+				entry.Infof("model fallback: served by hop %d (%s)", hop.index, hop)
+			}
+			return result, hop, nil
+		}
+		lastErr = unwrapRequestStopError(errExec)
+		if ctx != nil && ctx.Err() != nil {
+			return zero, hop, lastErr
+		}
+		if !shouldTryNextModelFallback(errExec) {
+			return zero, hop, lastErr
+		}
+		if i+1 < len(hops) {
+			entry.Infof("model fallback: hop %d (%s) failed, trying hop %d: %v", hop.index, hop, hops[i+1].index, lastErr)
+		}
+	}
+	if lastErr == nil {
+		lastErr = &Error{Code: "auth_not_found", Message: "no auth available"}
+	}
+	return zero, modelFallbackHop{}, lastErr
+}
+
+// hasLaterModelFallbackHop reports whether any of hops can be executed.
+func hasLaterModelFallbackHop(hops []modelFallbackHop) bool {
+	for _, hop := range hops {
+		if len(hop.providers) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// modelFallbackHandOff returns the errors a hop hands to the next one instead of
+// waiting them out, or nil on the last hop.
+func modelFallbackHandOff(more bool) func(error) bool {
+	if !more {
+		return nil
+	}
+	return shouldTryNextModelFallback
+}
+
+// shouldTryNextModelFallback reports whether err means the current hop cannot
+// serve the request right now: every credential is cooling down, the upstream is
+// over quota, or the upstream failed. Any other error, including failures without
+// a status and missing models, is returned to the caller.
+func shouldTryNextModelFallback(err error) bool {
+	if err == nil || isRequestTerminatedError(err) || isRequestStopError(err) || isRequestInvalidError(err) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var cooldownErr *modelCooldownError
+	if errors.As(err, &cooldownErr) {
+		return true
+	}
+	status := statusCodeFromError(err)
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// markModelFallbackHop reports the serving hop in the response headers.
+func markModelFallbackHop(headers http.Header, hop modelFallbackHop) http.Header {
+	if hop.index <= 0 {
+		return headers
+	}
+	if headers == nil {
+		headers = make(http.Header)
+	} else {
+		headers = headers.Clone()
+	}
+	headers.Set(ModelFallbackHopHeader, strconv.Itoa(hop.index))
+	headers.Set(ModelFallbackModelHeader, hop.model)
+	return headers
+}
@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executionregistry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/usage"
+)
+
+type modelFallbackCall struct {
+	authID string
+	model  string
+	hop    int
+}
+
+func newModelFallbackTestManager(t *testing.T, chain internalconfig.ModelFallback, fail map[string]int) (*Manager, *[]modelFallbackCall) {
+	t.Helper()
+	previous := quotaCooldownDisabled.Load()
+	quotaCooldownDisabled.Store(false)
+	t.Cleanup(func() { quotaCooldownDisabled.Store(previous) })
+
+	m := NewManager(nil, nil, nil)
+	cfg := &internalconfig.Config{ModelFallbacks: []internalconfig.ModelFallback{chain}}
+	cfg.SanitizeModelFallbacks()
+	m.SetConfig(cfg)
+
+	var mu sync.Mutex
+	calls := &[]modelFallbackCall{}
+	executeFn := func(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+		mu.Lock()
+		*calls = append(*calls, modelFallbackCall{authID: auth.ID, model: req.Model, hop: coreusage.FallbackHopFromContext(ctx)})
+		mu.Unlock()
+		if status := fail[auth.ID]; status != 0 {
+			return cliproxyexecutor.Response{}, &Error{HTTPStatus: status, Message: http.StatusText(status)}
+		}
+		return cliproxyexecutor.Response{Payload: []byte(auth.ID)}, nil
+	}
+	m.RegisterExecutor(&mockCustomErrorExecutor{identifier: "claude", executeFn: executeFn})
+	m.RegisterExecutor(&mockCustomErrorExecutor{identifier: "fallback-compat", executeFn: executeFn})
+
+	reg := registry.GetGlobalRegistry()
+	for _, auth := range []*Auth{
+		{ID: "fallback-claude-oauth", Provider: "claude", Status: StatusActive, Attributes: map[string]string{AttributeAuthKind: AuthKindOAuth}},
+		{ID: "fallback-claude-key", Provider: "claude", Status: StatusActive, Attributes: map[string]string{AttributeAuthKind: AuthKindAPIKey}},
+		{ID: "fallback-compat-key", Provider: "fallback-compat", Status: StatusActive, Attributes: map[string]string{AttributeAuthKind: AuthKindAPIKey}},
+	} {
+		model := "fallback-opus"
+		if auth.Provider == "fallback-compat" {
+			model = "fallback-gpt"
+		}
+		reg.RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: model}})
+		authID := auth.ID
+		t.Cleanup(func() { reg.UnregisterClient(authID) })
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+	}
+	return m, calls
+}
+
+func TestExecute_ModelFallbackMovesAcrossProvidersInOrder(t *testing.T) {
+	chain := internalconfig.ModelFallback{
+		Model: "fallback-opus",
+		Targets: []internalconfig.ModelFallbackTarget{
+			{Provider: "claude", AuthKind: "oauth"},
+			{Provider: "claude", AuthKind: "api-key"},
+			{Model: "fallback-gpt"},
+		},
+	}
+	m, calls := newModelFallbackTestManager(t, chain, map[string]int{
+		"fallback-claude-oauth": http.StatusTooManyRequests,
+		"fallback-claude-key":   http.StatusServiceUnavailable,
+	})
+
+	resp, err := m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "fallback-opus"}, cliproxyexecutor.Options{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if string(resp.Payload) != "fallback-compat-key" {
+		t.Fatalf("served by %q, want fallback-compat-key", resp.Payload)
+	}
+	if got := resp.Headers.Get(ModelFallbackHopHeader); got != "3" {
+		t.Fatalf("%s = %q, want 3", ModelFallbackHopHeader, got)
+	}
+	if got := resp.Headers.Get(ModelFallbackModelHeader); got != "fallback-gpt" {
+		t.Fatalf("%s = %q, want fallback-gpt", ModelFallbackModelHeader, got)
+	}
+
+	want := []modelFallbackCall{
+		{authID: "fallback-claude-oauth", model: "fallback-opus", hop: 1},
+		{authID: "fallback-claude-key", model: "fallback-opus", hop: 2},
+		{authID: "fallback-compat-key", model: "fallback-gpt", hop: 3},
+	}
+	if len(*calls) != len(want) {
+		t.Fatalf("calls = %+v, want %+v", *calls, want)
+	}
+	for i := range want {
+		if (*calls)[i] != want[i] {
+			t.Fatalf("call %d = %+v, want %+v", i, (*calls)[i], want[i])
+		}
+	}
+}
+
+func TestExecute_ModelFallbackStopsOnRequestFault(t *testing.T) {
+	chain := internalconfig.ModelFallback{
+		Model: "fallback-opus",
+		Targets: []internalconfig.ModelFallbackTarget{
+			{Provider: "claude", AuthKind: "oauth"},
+			{Model: "fallback-gpt"},
+		},
+	}
+	m, calls := newModelFallbackTestManager(t, chain, map[string]int{
+		"fallback-claude-oauth": http.StatusBadRequest,
+	})
+
+	if _, err := m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "fallback-opus"}, cliproxyexecutor.Options{}); err == nil {
+		t.Fatal("Execute() error = nil, want the request fault")
+	}
+	for _, call := range *calls {
+		if call.authID == "fallback-compat-key" {
+			t.Fatalf("request fault moved to the next hop: %+v", *calls)
+		}
+	}
+}
+
+func TestShouldTryNextModelFallback(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", &Error{HTTPStatus: http.StatusTooManyRequests}, true},
+		{"upstream failure", &Error{HTTPStatus: http.StatusBadGateway}, true},
+		{"cooldown", newModelCooldownError("fallback-opus", "claude", time.Minute), true},
+		{"no status", &Error{Code: "auth_not_found", Message: "no auth available"}, false},
+		{"not found", &Error{HTTPStatus: http.StatusNotFound}, false},
+		{"unauthorized", &Error{HTTPStatus: http.StatusUnauthorized}, false},
+		{"bad request", &Error{HTTPStatus: http.StatusBadRequest}, false},
+	}
+	for _, tc := range cases {
+		if got := shouldTryNextModelFallback(tc.err); got != tc.want {
+			t.Errorf("%s: shouldTryNextModelFallback() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestExecute_ModelFallbackHandsOffCooldownWithoutWaiting(t *testing.T) {
+	chain := internalconfig.ModelFallback{
+		Model: "fallback-opus",
+		Targets: []internalconfig.ModelFallbackTarget{
+			{Provider: "claude"},
+			{Model: "fallback-gpt"},
+		},
+	}
+	m, calls := newModelFallbackTestManager(t, chain, map[string]int{
+		"fallback-claude-oauth": http.StatusTooManyRequests,
+		"fallback-claude-key":   http.StatusTooManyRequests,
+	})
+	m.SetRetryConfig(3, time.Minute, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		start := time.Now()
+		resp, err := m.Execute(ctx, []string{"claude"}, cliproxyexecutor.Request{Model: "fallback-opus"}, cliproxyexecutor.Options{})
+		if err != nil {
+			t.Fatalf("Execute() %d error = %v", i, err)
+		}
+		if string(resp.Payload) != "fallback-compat-key" {
+			t.Fatalf("Execute() %d served by %q, want fallback-compat-key", i, resp.Payload)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("Execute() %d took %v waiting for the cooling hop", i, elapsed)
+		}
+	}
+	last := (*calls)[len(*calls)-1]
+	if last.authID != "fallback-compat-key" || last.hop != 2 {
+		t.Fatalf("last call = %+v, want fallback-compat-key on hop 2", last)
+	}
+}
+
+func TestExecute_ModelFallbackSkippedInHomeMode(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	cfg := &internalconfig.Config{
+		Home: internalconfig.HomeConfig{Enabled: true},
+		ModelFallbacks: []internalconfig.ModelFallback{{
+			Model:   "test",
+			Targets: []internalconfig.ModelFallbackTarget{{Provider: "home-execution"}, {Model: "fallback-gpt", Provider: "fallback-compat"}},
+		}},
+	}
+	cfg.SanitizeModelFallbacks()
+	manager.SetConfig(cfg)
+	manager.PublishHomeDispatch(homeExecutionDispatcher{}, executionregistry.New(), 1)
+	manager.RegisterExecutor(&homeExecutionExecutor{})
+
+	resp, err := manager.Execute(context.Background(), []string{"home-execution"}, cliproxyexecutor.Request{Model: "test"}, cliproxyexecutor.Options{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := resp.Headers.Get(ModelFallbackHopHeader); got != "" {
+		t.Fatalf("%s = %q in Home mode, want none", ModelFallbackHopHeader, got)
+	}
+	if hops := manager.modelFallbackHopsFor([]string{"home-execution"}, cliproxyexecutor.Request{Model: "test"}, cliproxyexecutor.Options{}); hops != nil {
+		t.Fatalf("modelFallbackHopsFor() = %v in Home mode, want none", hops)
+	}
+}
@@ -137,6 +137,10 @@ func extractRequestScopedErrorRules(auth *Auth, cfg *internalconfig.Config) []in
 		if index >= 0 && index < len(cfg.InteractionsKey) {
 			return cfg.InteractionsKey[index].RequestScopedErrors
 		}
+	case "azure-openai":
+		if index >= 0 && index < len(cfg.AzureOpenAIKey) {
+			return cfg.AzureOpenAIKey[index].RequestScopedErrors
+		}
 	}
 
 	return nil
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+)
+
+// ResponseCache serves and stores responses by the request an attempt sends
+// upstream. The manager consults it after picking a credential and resolving the
+// upstream model, so entries never cross credentials whose aliases or payload
+// rules produce a different upstream request.
+type ResponseCache interface {
+	// Lookup returns a cached response for attempt.
+	Lookup(ctx context.Context, attempt *ResponseCacheAttempt) (cliproxyexecutor.Response, bool)
+	// Store keeps the successful response of attempt.
+	Store(ctx context.Context, attempt *ResponseCacheAttempt, resp cliproxyexecutor.Response)
+	// LookupStream returns a cached stream for attempt.
+	LookupStream(ctx context.Context, attempt *ResponseCacheAttempt) (*cliproxyexecutor.StreamResult, bool)
+	// RecordStream returns result with its chunks recorded for attempt.
+	RecordStream(ctx context.Context, attempt *ResponseCacheAttempt, result *cliproxyexecutor.StreamResult) *cliproxyexecutor.StreamResult
+}
+
+// ResponseCacheAttempt describes the upstream request of one execution attempt.
+type ResponseCacheAttempt struct {
+	Provider string
+	// Model is the upstream model after credential aliasing.
+	Model string
+	// RequestedModel is the client-visible model payload rules may match as well.
+	RequestedModel string
+	// Payload is the request body after request interceptors.
+	Payload []byte
+	// Rules fingerprints the payload rules executors apply to Payload and the
+	// request headers those rules are gated on.
+	Rules string
+}
+
+type responseCacheContextKey struct{}
+
+// WithResponseCache returns ctx whose executions consult cache.
+func WithResponseCache(ctx context.Context, cache ResponseCache) context.Context {
+	return context.WithValue(ctx, responseCacheContextKey{}, cache)
+}
+
+func responseCacheFromContext(ctx context.Context) ResponseCache {
+	if ctx == nil {
+		return nil
+	}
+	cache, _ := ctx.Value(responseCacheContextKey{}).(ResponseCache)
+	return cache
+}
+
+// responseCacheAttempt describes the attempt of execReq on provider, or returns nil
+// when ctx carries no cache.
+func (m *Manager) responseCacheAttempt(ctx context.Context, provider string, execReq cliproxyexecutor.Request, execOpts cliproxyexecutor.Options, routeModel string) (ResponseCache, *ResponseCacheAttempt) {
+	cache := responseCacheFromContext(ctx)
+	if cache == nil {
+		return nil, nil
+	}
+	return cache, &ResponseCacheAttempt{
+		Provider:       provider,
+		Model:          execReq.Model,
+		RequestedModel: requestedModelAliasFromOptions(execOpts, routeModel),
+		Payload:        execReq.Payload,
+		Rules:          payloadRulesFingerprint(m.runtimeConfigSnapshot(), execOpts.Headers),
+	}
+}
+
+// payloadRulesFingerprint hashes the payload settings executors apply after
+// translation together with the header values the rules are gated on.
+func payloadRulesFingerprint(cfg *internalconfig.Config, headers http.Header) string {
+	if cfg == nil {
+		return ""
+	}
+	rules := cfg.Payload
+	encoded, errMarshal := json.Marshal(struct {
+		Payload                internalconfig.PayloadConfig
+		DisableImageGeneration internalconfig.DisableImageGenerationMode
+	}{rules, cfg.DisableImageGeneration})
+	if errMarshal != nil {
+		return ""
+	}
+	var gated []string
+	collect := func(models []internalconfig.PayloadModelRule) {
+		for _, model := range models {
+			for name := range model.Headers {
+				gated = append(gated, http.CanonicalHeaderKey(strings.TrimSpace(name)))
+			}
+		}
+	}
+	for _, group := range [][]internalconfig.PayloadRule{rules.Default, rules.DefaultRaw, rules.Override, rules.OverrideRaw} {
+		for _, rule := range group {
+			collect(rule.Models)
+		}
+	}
+	for _, rule := range rules.Filter {
+		collect(rule.Models)
+	}
+	slices.Sort(gated)
+	hash := sha256.New()
+	hash.Write(encoded)
+	for _, name := range slices.Compact(gated) {
+		hash.Write([]byte{0})
+		hash.Write([]byte(name))
+		for _, value := range headers.Values(name) {
+			hash.Write([]byte{0})
+			hash.Write([]byte(value))
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
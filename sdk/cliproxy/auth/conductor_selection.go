@@ -57,6 +57,8 @@ type authSelectionEligibility struct {
 	requiredKind     string
 	credentialPolicy string
 	disallowFreeAuth bool
+	hedge            streamHedgeClaim
+	slots            *CredentialSlots
 }
 
 func withRequiredAuthKind(ctx context.Context, requiredKind string) context.Context {
@@ -80,6 +82,8 @@ func authSelectionEligibilityForRequest(ctx context.Context, opts cliproxyexecut
 	if ctx != nil {
 		eligibility.requiredKind, _ = ctx.Value(requiredAuthKindContextKey{}).(string)
 		eligibility.credentialPolicy, _ = ctx.Value(credentialPolicyContextKey{}).(string)
+		eligibility.hedge = streamHedgeClaimFromContext(ctx)
+		eligibility.slots = credentialSlotsFromContext(ctx)
 	}
 	return eligibility
 }
@@ -94,6 +98,12 @@ func (e authSelectionEligibility) allows(auth *Auth) bool {
 	if e.credentialPolicy != "" && !credentialPolicyAllows(e.credentialPolicy, auth) {
 		return false
 	}
+	if !e.hedge.available(auth.ID) {
+		return false
+	}
+	if !e.slots.available(auth.ID) {
+		return false
+	}
 	return !e.disallowFreeAuth || !isFreeCodexAuth(auth)
 }
 
@@ -880,6 +890,39 @@ func jitteredCooldownWait(wait, maxWait time.Duration) time.Duration {
 	return wait + rand.N(jitterRange)
 }
 
+// retryAfterCooldown runs once until it succeeds or fails with an error that
+// shouldRetryAfterError does not retry, waiting out credential cooldowns between
+// attempts. Request stops and terminations are returned without retrying.
+func retryAfterCooldown[T any](ctx context.Context, m *Manager, providers []string, retryModel string, maxWait time.Duration, once func() (T, error)) (T, error) {
+	return retryAfterCooldownUnless(ctx, m, providers, retryModel, maxWait, nil, once)
+}
+
+// retryAfterCooldownUnless is retryAfterCooldown that returns errors for which
+// handOff reports true at once instead of waiting them out.
+func retryAfterCooldownUnless[T any](ctx context.Context, m *Manager, providers []string, retryModel string, maxWait time.Duration, handOff func(error) bool, once func() (T, error)) (T, error) {
+	var zero T
+	for attempt := 0; ; attempt++ {
+		result, errExec := once()
+		if errExec == nil {
+			return result, nil
+		}
+		if isRequestTerminatedError(errExec) || isRequestStopError(errExec) {
+			return zero, errExec
+		}
+		if handOff != nil && handOff(errExec) {
+			return zero, errExec
+		}
+		wait, shouldRetry := m.shouldRetryAfterError(errExec, attempt, providers, retryModel, maxWait)
+		if !shouldRetry {
+			return zero, errExec
+		}
+		traceCooldownRetry(ctx, attempt, wait, errExec)
+		if errWait := waitForCooldown(ctx, wait, maxWait); errWait != nil {
+			return zero, errWait
+		}
+	}
+}
+
 func waitForCooldown(ctx context.Context, wait, maxWait time.Duration) error {
 	if wait <= 0 {
 		return nil
@@ -1283,6 +1326,17 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 		auth, exec, _, err := m.pickNextViaHome(ctx, model, opts, tried)
 		return auth, exec, err
 	}
+	// Credentials close to an upstream quota window limit are only picked when no
+	// other credential is available.
+	if preferred, ok := m.triedWithQuotaPressure(tried); ok {
+		if auth, exec, errPick := m.pickNextLocal(ctx, provider, model, opts, preferred); errPick == nil {
+			return auth, exec, nil
+		}
+	}
+	return m.pickNextLocal(ctx, provider, model, opts, tried)
+}
+
+func (m *Manager) pickNextLocal(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, error) {
 	opts.Metadata[cliproxyexecutor.SessionAffinityProviderMetadataKey] = provider
 	opts.Metadata[cliproxyexecutor.SessionAffinityModelMetadataKey] = model
 
@@ -1451,6 +1505,15 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 	if m.HomeEnabled() {
 		return m.pickNextViaHome(ctx, model, opts, tried)
 	}
+	if preferred, ok := m.triedWithQuotaPressure(tried); ok {
+		if auth, exec, provider, errPick := m.pickNextMixedLocal(ctx, providers, model, opts, preferred); errPick == nil {
+			return auth, exec, provider, nil
+		}
+	}
+	return m.pickNextMixedLocal(ctx, providers, model, opts, tried)
+}
+
+func (m *Manager) pickNextMixedLocal(ctx context.Context, providers []string, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, string, error) {
 	opts.Metadata[cliproxyexecutor.SessionAffinityProviderMetadataKey] = "mixed"
 	opts.Metadata[cliproxyexecutor.SessionAffinityModelMetadataKey] = model
 
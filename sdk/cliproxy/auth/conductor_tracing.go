@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/tracing"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startExecuteSpan opens the span covering auth selection and execution of one request,
+// including every credential attempt and cooldown retry.
+func startExecuteSpan(ctx context.Context, name string, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name,
+		tracing.AttrModel.String(req.Model),
+		tracing.AttrSourceFormat.String(opts.SourceFormat.String()),
+		tracing.AttrStream.Bool(opts.Stream),
+		attribute.StringSlice("cliproxy.providers", providers),
+	)
+}
+
+// traceCooldownRetry records a retry round that waits for credentials to leave cooldown.
+func traceCooldownRetry(ctx context.Context, attempt int, wait time.Duration, err error) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	attrs := []attribute.KeyValue{
+		tracing.AttrAttempt.Int(attempt + 1),
+		attribute.Int64("cliproxy.cooldown.wait_ms", wait.Milliseconds()),
+	}
+	if err != nil {
+		attrs = append(attrs, attribute.Int("http.response.status_code", statusCodeFromError(err)))
+	}
+	span.AddEvent("auth.retry", trace.WithAttributes(attrs...))
+}
+
+// traceAuthSkip records a picked credential that was skipped without an upstream call.
+func traceAuthSkip(ctx context.Context, auth *Auth, provider, reason string) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	attrs := []attribute.KeyValue{
+		tracing.AttrProvider.String(provider),
+		attribute.String("cliproxy.skip_reason", reason),
+	}
+	if auth != nil {
+		attrs = append(attrs, tracing.AttrAuthIndex.String(auth.EnsureIndex()))
+	}
+	span.AddEvent("auth.skip", trace.WithAttributes(attrs...))
+}
+
+// traceAuthUnavailable records that no credential could be picked, typically because
+// every candidate is cooling down.
+func traceAuthUnavailable(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() || err == nil {
+		return
+	}
+	span.AddEvent("auth.unavailable", trace.WithAttributes(attribute.String("error.message", err.Error())))
+}
+
+// authAttemptSpan is the span of one credential attempt inside a selection loop.
+// A nil *authAttemptSpan is valid and ignores all calls.
+type authAttemptSpan struct {
+	span trace.Span
+	err  error
+}
+
+// startAuthAttemptSpan opens the span of an attempt with auth. The span carries the
+// auth index, never the credential itself.
+func startAuthAttemptSpan(ctx context.Context, auth *Auth, provider, model string, attempt int) (context.Context, *authAttemptSpan) {
+	ctx, span := tracing.Start(ctx, "auth.attempt",
+		tracing.AttrProvider.String(provider),
+		tracing.AttrModel.String(model),
+		tracing.AttrAuthIndex.String(auth.EnsureIndex()),
+		tracing.AttrAttempt.Int(attempt),
+	)
+	return ctx, &authAttemptSpan{span: span}
+}
+
+// fail records err as the outcome of the attempt.
+func (s *authAttemptSpan) fail(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.err = err
+	if code := statusCodeFromError(err); code > 0 {
+		s.span.SetAttributes(attribute.Int("http.response.status_code", code))
+	}
+}
+
+// end closes the span; later calls are no-ops.
+func (s *authAttemptSpan) end() {
+	if s == nil || s.span == nil {
+		return
+	}
+	tracing.End(s.span, s.err)
+	s.span = nil
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"strings"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+)
+
+// SetDiscoveredModelAliases replaces the alias -> upstream model mappings discovered
+// for authID and rebuilds the API-key alias table. Aliases already defined by the
+// credential's configured models keep their configured target. A nil or empty map
+// clears the discovered mappings.
+func (m *Manager) SetDiscoveredModelAliases(authID string, aliases map[string]string) {
+	if m == nil {
+		return
+	}
+	authID = strings.TrimSpace(authID)
+	if authID == "" {
+		return
+	}
+	normalized := make(map[string]string, len(aliases))
+	for alias, upstream := range aliases {
+		key := strings.ToLower(strings.TrimSpace(alias))
+		upstream = strings.TrimSpace(upstream)
+		if key == "" || upstream == "" {
+			continue
+		}
+		normalized[key] = upstream
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(normalized) == 0 {
+		if _, exists := m.discoveredModelAliases[authID]; !exists {
+			return
+		}
+		delete(m.discoveredModelAliases, authID)
+	} else {
+		if m.discoveredModelAliases == nil {
+			m.discoveredModelAliases = make(map[string]map[string]string)
+		}
+		m.discoveredModelAliases[authID] = normalized
+	}
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil {
+		cfg = &internalconfig.Config{}
+	}
+	m.rebuildAPIKeyModelAliasLocked(cfg)
+}
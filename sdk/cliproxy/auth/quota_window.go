@@ -0,0 +1,308 @@
+package auth
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// quotaWindowStaleAfter bounds how long a window without a reported reset time
+// keeps deprioritizing its credential after it was last observed.
+const quotaWindowStaleAfter = 10 * time.Minute
+
+// QuotaWindow is one upstream usage window reported in response headers, such as
+// Anthropic's unified 5h/7d windows or the Codex primary/secondary windows.
+type QuotaWindow struct {
+	// Name identifies the window, e.g. "5h", "7d", "primary" or "requests".
+	Name string `json:"name"`
+	// UsedFraction is the used share of the window between 0 and 1.
+	UsedFraction float64 `json:"used_fraction"`
+	// Limit is the window capacity for count-based windows.
+	Limit int64 `json:"limit,omitempty"`
+	// Remaining is the capacity left in count-based windows.
+	Remaining *int64 `json:"remaining,omitempty"`
+	// WindowSeconds is the window length when the upstream reports it.
+	WindowSeconds int64 `json:"window_seconds,omitempty"`
+	// Status is the upstream window status, e.g. "allowed" or "allowed_warning".
+	Status string `json:"status,omitempty"`
+	// ResetAt is when the window resets; zero when unknown.
+	ResetAt time.Time `json:"reset_at"`
+	// UpdatedAt is when the window was last observed.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// pressured reports whether the window is at or above threshold (0-1) and still current.
+func (w QuotaWindow) pressured(threshold float64, now time.Time) bool {
+	if w.UsedFraction < threshold {
+		return false
+	}
+	if !w.ResetAt.IsZero() {
+		return w.ResetAt.After(now)
+	}
+	return now.Sub(w.UpdatedAt) < quotaWindowStaleAfter
+}
+
+// ParseQuotaWindows extracts the usage windows reported by Anthropic unified
+// rate-limit headers, Codex x-codex-* usage headers and OpenAI-style
+// x-ratelimit-* headers (used by xAI and others). The result is sorted by name.
+func ParseQuotaWindows(headers http.Header, now time.Time) []QuotaWindow {
+	if len(headers) == 0 {
+		return nil
+	}
+	windows := make(map[string]*QuotaWindow)
+	window := func(name string) *QuotaWindow {
+		if w, ok := windows[name]; ok {
+			return w
+		}
+		w := &QuotaWindow{Name: name, UpdatedAt: now}
+		windows[name] = w
+		return w
+	}
+	used := make(map[string]bool)
+	for key, values := range headers {
+		if len(values) == 0 {
+			continue
+		}
+		value := strings.TrimSpace(values[0])
+		lower := strings.ToLower(key)
+		switch {
+		case strings.HasPrefix(lower, "anthropic-ratelimit-unified-"):
+			name, field, ok := cutQuotaWindowField(strings.TrimPrefix(lower, "anthropic-ratelimit-unified-"))
+			if !ok {
+				continue
+			}
+			switch field {
+			case "utilization":
+				if fraction, errParse := strconv.ParseFloat(value, 64); errParse == nil {
+					window(name).UsedFraction = clampQuotaFraction(fraction)
+					used[name] = true
+				}
+			case "reset":
+				if resetAt, okReset := parseQuotaResetTime(value); okReset {
+					window(name).ResetAt = resetAt
+				}
+			case "status":
+				window(name).Status = strings.ToLower(value)
+			}
+		case strings.HasPrefix(lower, "anthropic-ratelimit-"):
+			name, field, ok := cutQuotaWindowField(strings.TrimPrefix(lower, "anthropic-ratelimit-"))
+			if !ok {
+				continue
+			}
+			applyQuotaCountField(window, used, name, field, value, now)
+		case strings.HasPrefix(lower, "x-codex-"):
+			rest := strings.TrimPrefix(lower, "x-codex-")
+			name, field, ok := strings.Cut(rest, "-")
+			if !ok || (name != "primary" && name != "secondary") {
+				continue
+			}
+			switch field {
+			case "used-percent":
+				if percent, errParse := strconv.ParseFloat(value, 64); errParse == nil {
+					window(name).UsedFraction = clampQuotaFraction(percent / 100)
+					used[name] = true
+				}
+			case "window-minutes":
+				if minutes, errParse := strconv.ParseInt(value, 10, 64); errParse == nil && minutes > 0 {
+					window(name).WindowSeconds = minutes * 60
+				}
+			case "reset-at":
+				if resetAt, okReset := parseQuotaResetTime(value); okReset {
+					window(name).ResetAt = resetAt
+				}
+			case "reset-after-seconds":
+				if seconds, errParse := strconv.ParseFloat(value, 64); errParse == nil && seconds >= 0 && window(name).ResetAt.IsZero() {
+					window(name).ResetAt = now.Add(time.Duration(seconds * float64(time.Second)))
+				}
+			}
+		case strings.HasPrefix(lower, "x-ratelimit-"):
+			// x-ratelimit-limit-requests, x-ratelimit-remaining-tokens, x-ratelimit-reset-requests, ...
+			field, name, ok := strings.Cut(strings.TrimPrefix(lower, "x-ratelimit-"), "-")
+			if !ok {
+				continue
+			}
+			applyQuotaCountField(window, used, name, field, value, now)
+		}
+	}
+
+	out := make([]QuotaWindow, 0, len(windows))
+	for name, w := range windows {
+		if w.Limit > 0 && w.Remaining != nil {
+			w.UsedFraction = clampQuotaFraction(1 - float64(*w.Remaining)/float64(w.Limit))
+			used[name] = true
+		}
+		if !used[name] {
+			continue
+		}
+		out = append(out, *w)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// cutQuotaWindowField splits "5h-utilization" or "requests-remaining" at the last dash.
+func cutQuotaWindowField(rest string) (string, string, bool) {
+	idx := strings.LastIndexByte(rest, '-')
+	if idx <= 0 || idx == len(rest)-1 {
+		return "", "", false
+	}
+	return rest[:idx], rest[idx+1:], true
+}
+
+func applyQuotaCountField(window func(string) *QuotaWindow, used map[string]bool, name, field, value string, now time.Time) {
+	switch field {
+	case "limit":
+		if limit, errParse := strconv.ParseInt(value, 10, 64); errParse == nil && limit > 0 {
+			window(name).Limit = limit
+		}
+	case "remaining":
+		if remaining, errParse := strconv.ParseInt(value, 10, 64); errParse == nil && remaining >= 0 {
+			window(name).Remaining = &remaining
+		}
+	case "reset":
+		if resetAt, ok := parseQuotaResetTime(value); ok {
+			window(name).ResetAt = resetAt
+		} else if delay, errParse := time.ParseDuration(value); errParse == nil && delay >= 0 {
+			window(name).ResetAt = now.Add(delay)
+		} else if seconds, errParse := strconv.ParseFloat(value, 64); errParse == nil && seconds >= 0 {
+			window(name).ResetAt = now.Add(time.Duration(seconds * float64(time.Second)))
+		}
+	}
+}
+
+// parseQuotaResetTime parses unix seconds (values large enough to be a timestamp)
+// and RFC 3339 timestamps.
+func parseQuotaResetTime(value string) (time.Time, bool) {
+	if seconds, errParse := strconv.ParseFloat(value, 64); errParse == nil {
+		if seconds < 1e9 {
+			return time.Time{}, false
+		}
+		return time.Unix(int64(seconds), 0), true
+	}
+	if parsed, errParse := time.Parse(time.RFC3339, value); errParse == nil {
+		return parsed, true
+	}
+	return time.Time{}, false
+}
+
+func clampQuotaFraction(fraction float64) float64 {
+	return min(max(fraction, 0), 1)
+}
+
+func cloneQuotaWindows(windows []QuotaWindow) []QuotaWindow {
+	if len(windows) == 0 {
+		return nil
+	}
+	out := make([]QuotaWindow, len(windows))
+	copy(out, windows)
+	for i := range out {
+		if out[i].Remaining != nil {
+			remaining := *out[i].Remaining
+			out[i].Remaining = &remaining
+		}
+	}
+	return out
+}
+
+// quotaPressureUntil returns when the quota windows of the auth above threshold
+// stop counting, or zero when none is above it.
+func (a *Auth) quotaPressureUntil(threshold float64, now time.Time) time.Time {
+	var until time.Time
+	if a == nil || threshold <= 0 {
+		return until
+	}
+	for _, w := range a.QuotaWindows {
+		if !w.pressured(threshold, now) {
+			continue
+		}
+		end := w.ResetAt
+		if end.IsZero() {
+			end = w.UpdatedAt.Add(quotaWindowStaleAfter)
+		}
+		if end.After(until) {
+			until = end
+		}
+	}
+	return until
+}
+
+// quotaWindowThreshold returns the configured quota-window threshold as a fraction.
+func (m *Manager) quotaWindowThreshold() float64 {
+	cfg := m.runtimeConfigSnapshot()
+	if cfg == nil || cfg.Routing.QuotaWindowThreshold <= 0 {
+		return 0
+	}
+	return min(cfg.Routing.QuotaWindowThreshold, 100) / 100
+}
+
+// recordQuotaWindows stores the windows reported by a successful response. The
+// caller must hold m.mu.
+func (a *Auth) recordQuotaWindows(headers http.Header, now time.Time) {
+	if windows := ParseQuotaWindows(headers, now); len(windows) > 0 {
+		a.QuotaWindows = windows
+	}
+}
+
+// noteQuotaPressureLocked refreshes the quota pressure entry of auth. The caller
+// must hold m.mu for writing.
+func (m *Manager) noteQuotaPressureLocked(auth *Auth, now time.Time) {
+	if m.quotaPressureThreshold <= 0 || auth == nil {
+		return
+	}
+	if until := auth.quotaPressureUntil(m.quotaPressureThreshold, now); until.After(now) {
+		m.quotaPressure[auth.ID] = until
+		return
+	}
+	delete(m.quotaPressure, auth.ID)
+}
+
+// rebuildQuotaPressureLocked recomputes every quota pressure entry for threshold.
+// The caller must hold m.mu for writing.
+func (m *Manager) rebuildQuotaPressureLocked(threshold float64, now time.Time) {
+	m.quotaPressureThreshold = threshold
+	m.quotaPressure = make(map[string]time.Time)
+	for _, auth := range m.auths {
+		m.noteQuotaPressureLocked(auth, now)
+	}
+}
+
+// triedWithQuotaPressure returns tried extended with every credential whose quota
+// windows are above the configured threshold, so a first selection pass prefers
+// credentials with headroom. The boolean is false when nothing would be excluded.
+func (m *Manager) triedWithQuotaPressure(tried map[string]struct{}) (map[string]struct{}, bool) {
+	threshold := m.quotaWindowThreshold()
+	if threshold <= 0 {
+		return nil, false
+	}
+	now := time.Now()
+	m.mu.RLock()
+	if m.quotaPressureThreshold != threshold {
+		m.mu.RUnlock()
+		m.mu.Lock()
+		if m.quotaPressureThreshold != threshold {
+			m.rebuildQuotaPressureLocked(threshold, now)
+		}
+		m.mu.Unlock()
+		m.mu.RLock()
+	}
+	var extended map[string]struct{}
+	for id, until := range m.quotaPressure {
+		if _, already := tried[id]; already || !until.After(now) {
+			continue
+		}
+		if extended == nil {
+			extended = make(map[string]struct{}, len(tried)+1)
+			for triedID := range tried {
+				extended[triedID] = struct{}{}
+			}
+		}
+		extended[id] = struct{}{}
+	}
+	m.mu.RUnlock()
+	return extended, extended != nil
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+)
+
+func TestParseQuotaWindows(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	headers := http.Header{}
+	headers.Set("Anthropic-Ratelimit-Unified-5h-Utilization", "0.42")
+	headers.Set("Anthropic-Ratelimit-Unified-5h-Reset", "1800003600")
+	headers.Set("Anthropic-Ratelimit-Unified-5h-Status", "allowed")
+	headers.Set("Anthropic-Ratelimit-Unified-7d-Utilization", "0.95")
+	headers.Set("Anthropic-Ratelimit-Unified-Reset", "1800003600")
+	headers.Set("X-Codex-Primary-Used-Percent", "80")
+	headers.Set("X-Codex-Primary-Window-Minutes", "300")
+	headers.Set("X-Codex-Primary-Reset-After-Seconds", "120")
+	headers.Set("X-Ratelimit-Limit-Requests", "100")
+	headers.Set("X-Ratelimit-Remaining-Requests", "25")
+	headers.Set("X-Ratelimit-Reset-Requests", "6m0s")
+	headers.Set("X-Codex-Turn-State", "ignored")
+
+	windows := ParseQuotaWindows(headers, now)
+	byName := make(map[string]QuotaWindow, len(windows))
+	for _, window := range windows {
+		byName[window.Name] = window
+	}
+	if len(byName) != 4 {
+		t.Fatalf("ParseQuotaWindows() = %+v, want 5h, 7d, primary and requests", windows)
+	}
+	if w := byName["5h"]; w.UsedFraction != 0.42 || w.Status != "allowed" || !w.ResetAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("5h window = %+v", w)
+	}
+	if w := byName["primary"]; w.UsedFraction != 0.8 || w.WindowSeconds != 18000 || !w.ResetAt.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("primary window = %+v", w)
+	}
+	if w := byName["requests"]; w.UsedFraction != 0.75 || w.Limit != 100 || w.Remaining == nil || *w.Remaining != 25 || !w.ResetAt.Equal(now.Add(6*time.Minute)) {
+		t.Fatalf("requests window = %+v", w)
+	}
+}
+
+func TestManager_PickNext_DeprioritizesCredentialsNearQuotaLimit(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(nil, &FillFirstSelector{}, nil)
+	manager.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{QuotaWindowThreshold: 90}})
+	manager.RegisterExecutor(schedulerProviderTestExecutor{provider: "claude"})
+	registerSchedulerModels(t, "claude", "quota-window-model", "quota-a", "quota-b")
+
+	for _, id := range []string{"quota-a", "quota-b"} {
+		if _, errRegister := manager.Register(ctx, &Auth{ID: id, Provider: "claude"}); errRegister != nil {
+			t.Fatalf("register %s: %v", id, errRegister)
+		}
+	}
+	pick := func() string {
+		t.Helper()
+		got, _, errPick := manager.pickNext(ctx, "claude", "quota-window-model", cliproxyexecutor.Options{}, nil)
+		if errPick != nil {
+			t.Fatalf("pickNext() error = %v", errPick)
+		}
+		return got.ID
+	}
+	markWindow := func(id, utilization string) {
+		headers := http.Header{}
+		headers.Set("Anthropic-Ratelimit-Unified-5h-Utilization", utilization)
+		headers.Set("Anthropic-Ratelimit-Unified-5h-Reset", "4102444800")
+		manager.MarkResult(ctx, Result{AuthID: id, Provider: "claude", Model: "quota-window-model", Success: true, Headers: headers})
+	}
+
+	if got := pick(); got != "quota-a" {
+		t.Fatalf("pickNext() = %q, want quota-a before any quota is reported", got)
+	}
+	markWindow("quota-a", "0.93")
+	if got := pick(); got != "quota-b" {
+		t.Fatalf("pickNext() = %q, want quota-b while quota-a is near its limit", got)
+	}
+	if snapshot, _ := manager.GetByID("quota-a"); len(snapshot.QuotaWindows) != 1 || snapshot.QuotaWindows[0].UsedFraction != 0.93 {
+		t.Fatalf("quota-a windows = %+v", snapshot.QuotaWindows)
+	}
+	markWindow("quota-b", "0.97")
+	if got := pick(); got != "quota-a" {
+		t.Fatalf("pickNext() = %q, want quota-a when every credential is near its limit", got)
+	}
+}
+
+func TestManager_QuotaPressureTracksReportedWindows(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(nil, &FillFirstSelector{}, nil)
+	manager.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{QuotaWindowThreshold: 90}})
+	for _, id := range []string{"pressure-a", "pressure-b"} {
+		if _, errRegister := manager.Register(ctx, &Auth{ID: id, Provider: "claude"}); errRegister != nil {
+			t.Fatalf("register %s: %v", id, errRegister)
+		}
+	}
+	markWindow := func(id, utilization string) {
+		headers := http.Header{}
+		headers.Set("Anthropic-Ratelimit-Unified-5h-Utilization", utilization)
+		headers.Set("Anthropic-Ratelimit-Unified-5h-Reset", "4102444800")
+		manager.MarkResult(ctx, Result{AuthID: id, Provider: "claude", Model: "quota-window-model", Success: true, Headers: headers})
+	}
+	pressured := func() map[string]struct{} {
+		t.Helper()
+		got, _ := manager.triedWithQuotaPressure(nil)
+		return got
+	}
+
+	markWindow("pressure-a", "0.95")
+	if got := pressured(); len(got) != 1 {
+		t.Fatalf("pressured = %v, want pressure-a", got)
+	}
+	markWindow("pressure-b", "0.99")
+	markWindow("pressure-a", "0.20")
+	if got := pressured(); len(got) != 1 {
+		t.Fatalf("pressured = %v, want pressure-b", got)
+	} else if _, ok := got["pressure-b"]; !ok {
+		t.Fatalf("pressured = %v, want pressure-b", got)
+	}
+	manager.Remove(ctx, "pressure-b")
+	if got := pressured(); len(got) != 0 {
+		t.Fatalf("pressured after removal = %v, want none", got)
+	}
+
+	manager.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{QuotaWindowThreshold: 10}})
+	if got := pressured(); len(got) != 1 {
+		t.Fatalf("pressured under a lower threshold = %v, want pressure-a", got)
+	}
+}
@@ -715,20 +715,7 @@ func (m *modelScheduler) upsertEntryLocked(meta *scheduledAuthMeta, now time.Tim
 
 	entry.meta = meta
 	entry.auth = meta.auth
-	entry.nextRetryAt = time.Time{}
-	blocked, reason, next := isAuthBlockedForModel(meta.auth, m.modelKey, now)
-	switch {
-	case !blocked:
-		entry.state = scheduledStateReady
-	case reason == blockReasonCooldown:
-		entry.state = scheduledStateCooldown
-		entry.nextRetryAt = next
-	case reason == blockReasonDisabled:
-		entry.state = scheduledStateDisabled
-	default:
-		entry.state = scheduledStateBlocked
-		entry.nextRetryAt = next
-	}
+	entry.state, entry.nextRetryAt = scheduledStateForModel(meta.auth, m.modelKey, now)
 
 	if ok && previousState == entry.state && previousNextRetryAt.Equal(entry.nextRetryAt) && previousPriority == meta.priority && previousWebsocketEnabled == meta.websocketEnabled {
 		return
@@ -761,21 +748,7 @@ func (m *modelScheduler) promoteExpiredLocked(now time.Time) {
 		if entry.nextRetryAt.IsZero() || entry.nextRetryAt.After(now) {
 			continue
 		}
-		blocked, reason, next := isAuthBlockedForModel(entry.auth, m.modelKey, now)
-		switch {
-		case !blocked:
-			entry.state = scheduledStateReady
-			entry.nextRetryAt = time.Time{}
-		case reason == blockReasonCooldown:
-			entry.state = scheduledStateCooldown
-			entry.nextRetryAt = next
-		case reason == blockReasonDisabled:
-			entry.state = scheduledStateDisabled
-			entry.nextRetryAt = time.Time{}
-		default:
-			entry.state = scheduledStateBlocked
-			entry.nextRetryAt = next
-		}
+		entry.state, entry.nextRetryAt = scheduledStateForModel(entry.auth, m.modelKey, now)
 		changed = true
 	}
 	if changed {
@@ -783,6 +756,22 @@ func (m *modelScheduler) promoteExpiredLocked(now time.Time) {
 	}
 }
 
+// scheduledStateForModel classifies an auth for modelKey at now and returns the time
+// its state should be reevaluated, if any.
+func scheduledStateForModel(auth *Auth, modelKey string, now time.Time) (scheduledState, time.Time) {
+	blocked, reason, next := isAuthBlockedForModel(auth, modelKey, now)
+	switch {
+	case !blocked:
+		return scheduledStateReady, time.Time{}
+	case reason == blockReasonCooldown:
+		return scheduledStateCooldown, next
+	case reason == blockReasonDisabled:
+		return scheduledStateDisabled, time.Time{}
+	default:
+		return scheduledStateBlocked, next
+	}
+}
+
 // pickReadyLocked selects the next ready auth from the highest available priority bucket.
 func (m *modelScheduler) pickReadyLocked(preferWebsocket bool, strategy schedulerStrategy, predicate func(*scheduledAuth) bool) *Auth {
 	if m == nil {
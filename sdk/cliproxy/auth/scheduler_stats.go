@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"sort"
+	"time"
+)
+
+// SchedulerShardStats summarizes how auths participate in one provider/model scheduler shard.
+type SchedulerShardStats struct {
+	// Provider is the executor key that owns the shard.
+	Provider string
+	// Model is the canonical model key of the shard.
+	Model string
+	// Ready counts auths currently eligible for selection.
+	Ready int
+	// Cooldown counts auths waiting for a model cooldown to expire.
+	Cooldown int
+	// Blocked counts auths that are unavailable for other reasons (e.g. quota or auth errors).
+	Blocked int
+	// Disabled counts auths disabled for the model.
+	Disabled int
+}
+
+// SchedulerStats returns a snapshot of ready/cooldown/blocked counts for every materialized model shard.
+// Shards are built lazily on first selection, so models that were never requested are not reported.
+func (m *Manager) SchedulerStats() []SchedulerShardStats {
+	if m == nil || m.scheduler == nil {
+		return nil
+	}
+	return m.scheduler.stats(time.Now())
+}
+
+// stats collects shard counters while holding the scheduler mutex. It only reads
+// scheduler state.
+func (s *authScheduler) stats(now time.Time) []SchedulerShardStats {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]SchedulerShardStats, 0)
+	for providerKey, providerState := range s.providers {
+		if providerState == nil {
+			continue
+		}
+		for modelKey, shard := range providerState.modelShards {
+			if shard == nil {
+				continue
+			}
+			entry := SchedulerShardStats{Provider: providerKey, Model: modelKey}
+			for _, scheduled := range shard.entries {
+				if scheduled == nil || scheduled.auth == nil {
+					continue
+				}
+				// Entries whose retry time has passed are classified as the next selection
+				// would see them, without promoting them: a metrics scrape must not change
+				// scheduler state.
+				state := scheduled.state
+				if state != scheduledStateReady && !scheduled.nextRetryAt.IsZero() && !scheduled.nextRetryAt.After(now) {
+					state, _ = scheduledStateForModel(scheduled.auth, shard.modelKey, now)
+				}
+				switch state {
+				case scheduledStateReady:
+					entry.Ready++
+				case scheduledStateCooldown:
+					entry.Cooldown++
+				case scheduledStateDisabled:
+					entry.Disabled++
+				default:
+					entry.Blocked++
+				}
+			}
+			out = append(out, entry)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Provider == out[j].Provider {
+			return out[i].Model < out[j].Model
+		}
+		return out[i].Provider < out[j].Provider
+	})
+	return out
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/executor"
+)
+
+func TestSchedulerStats_CountsReadyAndCooldownPerShard(t *testing.T) {
+	t.Parallel()
+
+	model := "scheduler-stats-model"
+	registerSchedulerModels(t, "gemini", model, "stats-ready", "stats-cooldown")
+	scheduler := newSchedulerForTest(
+		&RoundRobinSelector{},
+		&Auth{ID: "stats-ready", Provider: "gemini"},
+		&Auth{
+			ID:       "stats-cooldown",
+			Provider: "gemini",
+			ModelStates: map[string]*ModelState{
+				model: {
+					Status:         StatusError,
+					Unavailable:    true,
+					NextRetryAfter: time.Now().Add(time.Hour),
+					Quota:          QuotaState{Exceeded: true, NextRecoverAt: time.Now().Add(time.Hour)},
+				},
+			},
+		},
+	)
+
+	if stats := scheduler.stats(time.Now()); len(stats) != 0 {
+		t.Fatalf("stats() before selection = %+v, want no materialized shards", stats)
+	}
+	if _, errPick := scheduler.pickSingle(context.Background(), "gemini", model, cliproxyexecutor.Options{}, nil); errPick != nil {
+		t.Fatalf("pickSingle() error = %v", errPick)
+	}
+
+	stats := scheduler.stats(time.Now())
+	if len(stats) != 1 {
+		t.Fatalf("stats() len = %d, want 1: %+v", len(stats), stats)
+	}
+	got := stats[0]
+	if got.Provider != "gemini" || got.Model != model {
+		t.Fatalf("stats()[0] shard = %s/%s, want gemini/%s", got.Provider, got.Model, model)
+	}
+	if got.Ready != 1 || got.Cooldown != 1 || got.Blocked != 0 || got.Disabled != 0 {
+		t.Fatalf("stats()[0] = %+v, want 1 ready and 1 cooldown", got)
+	}
+}
+
+func TestSchedulerStats_DoesNotPromoteExpiredEntries(t *testing.T) {
+	t.Parallel()
+
+	model := "scheduler-stats-readonly-model"
+	registerSchedulerModels(t, "gemini", model, "stats-readonly-ready", "stats-readonly-cooldown")
+	scheduler := newSchedulerForTest(
+		&RoundRobinSelector{},
+		&Auth{ID: "stats-readonly-ready", Provider: "gemini"},
+		&Auth{
+			ID:       "stats-readonly-cooldown",
+			Provider: "gemini",
+			ModelStates: map[string]*ModelState{
+				model: {
+					Status:         StatusError,
+					Unavailable:    true,
+					NextRetryAfter: time.Now().Add(time.Hour),
+					Quota:          QuotaState{Exceeded: true, NextRecoverAt: time.Now().Add(time.Hour)},
+				},
+			},
+		},
+	)
+	if _, errPick := scheduler.pickSingle(context.Background(), "gemini", model, cliproxyexecutor.Options{}, nil); errPick != nil {
+		t.Fatalf("pickSingle() error = %v", errPick)
+	}
+
+	later := time.Now().Add(2 * time.Hour)
+	stats := scheduler.stats(later)
+	if len(stats) != 1 || stats[0].Ready != 2 || stats[0].Cooldown != 0 {
+		t.Fatalf("stats(later) = %+v, want 2 ready", stats)
+	}
+
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+	entry := scheduler.providers["gemini"].modelShards[canonicalModelKey(model)].entries["stats-readonly-cooldown"]
+	if entry == nil || entry.state != scheduledStateCooldown {
+		t.Fatalf("cooldown entry after stats() = %+v, want it left in cooldown", entry)
+	}
+}
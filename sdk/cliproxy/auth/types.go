@@ -89,6 +89,8 @@ type Auth struct {
 	NextRetryAfter time.Time `json:"next_retry_after"`
 	// ModelStates tracks per-model runtime availability data.
 	ModelStates map[string]*ModelState `json:"model_states,omitempty"`
+	// QuotaWindows holds the upstream usage windows reported by the latest successful response.
+	QuotaWindows []QuotaWindow `json:"quota_windows,omitempty"`
 
 	// Runtime carries non-serialisable data used during execution (in-memory only).
 	Runtime any `json:"-"`
@@ -282,6 +284,7 @@ func (a *Auth) Clone() *Auth {
 			copyAuth.ModelStates[key] = state.Clone()
 		}
 	}
+	copyAuth.QuotaWindows = cloneQuotaWindows(a.QuotaWindows)
 	copyAuth.Runtime = a.Runtime
 	return &copyAuth
 }
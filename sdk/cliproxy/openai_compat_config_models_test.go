@@ -76,3 +76,35 @@ func joinModalities(modalities []string) string {
 	}
 	return out
 }
+
+func TestConfigModelsMarkEmbeddingModels(t *testing.T) {
+	compat := &config.OpenAICompatibility{
+		Name: "embedder",
+		Models: []config.OpenAICompatibilityModel{
+			{Name: "text-embedding-3-small", Alias: "small-embed", Embedding: true},
+			{Name: "chat-model", Alias: "chat"},
+		},
+	}
+	for _, model := range buildOpenAICompatibilityConfigModels(compat) {
+		switch model.ID {
+		case "small-embed":
+			if model.Type != registry.OpenAIEmbeddingModelType || model.Thinking != nil {
+				t.Fatalf("embedding model = %#v", model)
+			}
+		case "chat":
+			if registry.IsEmbeddingModel(model) {
+				t.Fatal("chat model should not be an embedding model")
+			}
+		}
+	}
+
+	gemini := &config.GeminiKey{Models: []config.GeminiModel{
+		{Name: "gemini-embedding-001", Alias: "embed"},
+		{Name: "gemini-2.5-flash", Alias: "flash"},
+	}}
+	for _, model := range buildGeminiConfigModels(gemini) {
+		if got := registry.IsEmbeddingModel(model); got != (model.ID == "embed") {
+			t.Fatalf("IsEmbeddingModel(%s) = %v", model.ID, got)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/metrics"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -19,6 +20,8 @@ type pprofServer struct {
 	addr    string
 	enabled bool
 	owner   uint64
+	// metrics optionally serves /metrics when the metrics listener is "pprof".
+	metrics http.Handler
 }
 
 func newPprofServer() *pprofServer {
@@ -38,10 +41,19 @@ func (s *Service) applyPprofConfigContext(ctx context.Context, cfg *config.Confi
 	}
 	if s.pprofServer == nil {
 		s.pprofServer = newPprofServer()
+		s.pprofServer.metrics = metrics.Handler(config.MetricsListenerPprof, s.metricsAuthSource)
 	}
 	return s.pprofServer.ApplyContext(ctx, cfg)
 }
 
+// metricsAuthSource returns the core auth manager sampled by scrape-time metrics.
+func (s *Service) metricsAuthSource() metrics.AuthSource {
+	if s == nil || s.coreManager == nil {
+		return nil
+	}
+	return s.coreManager
+}
+
 func (s *Service) shutdownPprof(ctx context.Context) error {
 	if s == nil || s.pprofServer == nil {
 		return nil
@@ -134,6 +146,9 @@ func (p *pprofServer) Shutdown(ctx context.Context) error {
 
 func (p *pprofServer) startServer(addr string, owner uint64) *http.Server {
 	mux := newPprofMux()
+	if p.metrics != nil {
+		mux.Handle("/metrics", p.metrics)
+	}
 	server := &http.Server{
 		Addr:              addr,
 		Handler:           mux,
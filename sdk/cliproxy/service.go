@@ -95,6 +95,12 @@ type Service struct {
 	// pluginHost owns dynamic plugin lifecycle and runtime capability adapters.
 	pluginHost *pluginhost.Host
 
+	// offlineModels keeps model registration from contacting upstreams (config dry runs).
+	offlineModels bool
+
+	// modelDiscovery holds upstream model catalogs fetched for discover-models.
+	modelDiscovery modelDiscovery
+
 	// shutdownOnce ensures shutdown is called only once.
 	shutdownOnce sync.Once
 
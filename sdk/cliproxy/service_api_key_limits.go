@@ -0,0 +1,37 @@
+package cliproxy
+
+import (
+	"context"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/access/ratelimit"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v7/sdk/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// configureAPIKeyLimitStateStore mirrors per-client token budget counters into the
+// token store when the backend supports it, so budgets survive restarts.
+func (s *Service) configureAPIKeyLimitStateStore(ctx context.Context) {
+	if s == nil || s.cfg == nil || s.cfg.Home.Enabled {
+		return
+	}
+	provider, ok := sdkAuth.GetTokenStore().(ratelimit.StateStoreProvider)
+	if !ok {
+		return
+	}
+	store := provider.APIKeyLimitStateStore()
+	if store == nil {
+		return
+	}
+	if errAttach := ratelimit.Default().SetStateStore(ctx, store); errAttach != nil {
+		log.Warnf("failed to restore api key limit counters: %v", errAttach)
+	}
+}
+
+// flushAPIKeyLimitStateStore persists pending token budget counters and detaches the store.
+func flushAPIKeyLimitStateStore(ctx context.Context) {
+	limiter := ratelimit.Default()
+	if errFlush := limiter.Flush(ctx); errFlush != nil {
+		log.Warnf("failed to persist api key limit counters: %v", errFlush)
+	}
+	_ = limiter.SetStateStore(ctx, nil)
+}
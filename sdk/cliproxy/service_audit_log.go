@@ -0,0 +1,21 @@
+package cliproxy
+
+import (
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/audit"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v7/sdk/auth"
+)
+
+// configureAuditLogStore offers the token store's audit backend to the audit trail.
+// It is only used when audit-log.backend is "postgres".
+func (s *Service) configureAuditLogStore() {
+	if s == nil || s.cfg == nil || s.cfg.Home.Enabled {
+		return
+	}
+	provider, ok := sdkAuth.GetTokenStore().(audit.StoreProvider)
+	if !ok {
+		return
+	}
+	if store := provider.AuditLogStore(); store != nil {
+		audit.Default().SetExternalStore(store)
+	}
+}
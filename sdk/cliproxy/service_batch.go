@@ -0,0 +1,35 @@
+package cliproxy
+
+import (
+	"context"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/batch"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v7/sdk/auth"
+)
+
+// configureBatchStore connects the batch manager to the token store: Postgres stores
+// keep jobs in their own tables, git and object stores mirror the local batch files.
+func (s *Service) configureBatchStore() {
+	if s == nil || s.cfg == nil || s.cfg.Home.Enabled {
+		return
+	}
+	tokenStore := sdkAuth.GetTokenStore()
+	if provider, ok := tokenStore.(batch.StoreProvider); ok {
+		if store := provider.BatchStore(); store != nil {
+			batch.Default().SetExternalStore(store)
+			return
+		}
+	}
+	mirror, ok := tokenStore.(interface {
+		batch.Persister
+		AuthDir() string
+	})
+	if ok {
+		batch.Default().SetPersister(mirror, mirror.AuthDir())
+	}
+}
+
+// stopBatch pauses running batch jobs and saves their progress before shutdown.
+func stopBatch(ctx context.Context) {
+	batch.Default().Stop(ctx)
+}
@@ -72,6 +72,12 @@ func (s *Service) Run(ctx context.Context) error {
 
 	s.applyRetryConfig(s.cfg)
 	s.configureCooldownStateStore(s.cfg)
+	s.configureAPIKeyLimitStateStore(ctx)
+	s.configureUsageLedgerStore()
+	s.configureAuditLogStore()
+	s.configureBatchStore()
+	s.configureResponseCacheStore()
+	s.configureResponsesStore()
 
 	s.registerPluginAuthParser()
 	if s.coreManager != nil && !homeEnabled {
@@ -198,6 +204,9 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 
 	s.registerModelRefreshCallback()
+	if !homeEnabled {
+		s.startModelDiscovery(ctx)
+	}
 
 	// Prefer core auth manager auto refresh if available.
 	if s.coreManager != nil && !homeEnabled {
@@ -346,6 +355,10 @@ func (s *Service) Shutdown(ctx context.Context) error {
 		}
 
 		usage.StopDefault()
+		flushAPIKeyLimitStateStore(ctx)
+		stopUsageLedger(ctx)
+		stopBatch(ctx)
+		stopResponseCache()
 	})
 	return shutdownErr
 }
@@ -0,0 +1,527 @@
+package cliproxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/watcher/diff"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v7/sdk/proxyutil"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// modelDiscoveryTick is how often credentials are checked for a due catalog fetch.
+	modelDiscoveryTick = 30 * time.Second
+	// modelDiscoveryRetryInterval bounds the wait before retrying a failed fetch.
+	modelDiscoveryRetryInterval = 5 * time.Minute
+	modelDiscoveryTimeout       = 30 * time.Second
+	modelDiscoveryMaxBodyBytes  = 8 << 20
+	// modelDiscoveryMaxPages bounds paginated catalogs (Anthropic has_more/last_id).
+	modelDiscoveryMaxPages      = 20
+	claudeModelDiscoveryPath    = "/v1/models"
+	claudeModelDiscoveryVersion = "2023-06-01"
+)
+
+// modelDiscovery holds the upstream model catalogs discovered per credential.
+type modelDiscovery struct {
+	mu      sync.Mutex
+	entries map[string]*discoveredModelSet
+}
+
+// discoveredModelSet is the latest discovered catalog of one credential.
+type discoveredModelSet struct {
+	// settings identifies the discover-models settings and endpoint the set was built with.
+	settings string
+	// models lists the discovered upstream IDs and their client-facing aliases.
+	models    []config.OpenAICompatibilityModel
+	nextFetch time.Time
+}
+
+// modelDiscoveryTarget describes where and how to fetch the catalog of one credential.
+type modelDiscoveryTarget struct {
+	discover *config.DiscoverModelsConfig
+	// kind is the provider family: "openai-compatibility", "claude", "codex" or "xai".
+	kind string
+	// provider expands {provider} in alias templates.
+	provider string
+	endpoint string
+	apiKey   string
+	headers  map[string]string
+	proxyURL string
+}
+
+func (t modelDiscoveryTarget) settings() string {
+	return diff.ComputeDiscoverModelsHash(t.discover) + "|" + t.endpoint
+}
+
+// upstreamCatalogModel is one entry of an upstream model listing.
+type upstreamCatalogModel struct {
+	id          string
+	displayName string
+}
+
+// startModelDiscovery runs the discovery loop until ctx is cancelled.
+func (s *Service) startModelDiscovery(ctx context.Context) {
+	if s == nil || s.coreManager == nil || s.offlineModels {
+		return
+	}
+	go func() {
+		s.runModelDiscovery(ctx, time.Now())
+		ticker := time.NewTicker(modelDiscoveryTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.runModelDiscovery(ctx, now)
+			}
+		}
+	}()
+}
+
+// runModelDiscovery fetches every catalog that is due and re-registers the models of
+// credentials whose discovered set changed.
+func (s *Service) runModelDiscovery(ctx context.Context, now time.Time) {
+	if s == nil || s.coreManager == nil || s.offlineModels {
+		return
+	}
+	seen := make(map[string]struct{})
+	for _, auth := range s.coreManager.List() {
+		if ctx.Err() != nil {
+			return
+		}
+		if auth == nil || auth.ID == "" {
+			continue
+		}
+		target, ok := s.modelDiscoveryTargetForAuth(auth)
+		if !ok {
+			continue
+		}
+		seen[auth.ID] = struct{}{}
+		if s.modelDiscoveryDue(auth.ID, target, now) {
+			s.discoverModelsForAuth(ctx, auth, target, now)
+		}
+	}
+	s.forgetStaleDiscoveredModels(seen)
+}
+
+func (s *Service) modelDiscoveryDue(authID string, target modelDiscoveryTarget, now time.Time) bool {
+	s.modelDiscovery.mu.Lock()
+	defer s.modelDiscovery.mu.Unlock()
+	entry := s.modelDiscovery.entries[authID]
+	return entry == nil || entry.settings != target.settings() || !now.Before(entry.nextFetch)
+}
+
+// discoverModelsForAuth fetches the catalog of auth and applies it. A failed fetch keeps
+// the previously discovered set registered.
+func (s *Service) discoverModelsForAuth(ctx context.Context, auth *coreauth.Auth, target modelDiscoveryTarget, now time.Time) {
+	interval := target.discover.EffectiveInterval()
+	status := registry.ModelDiscoveryStatus{Endpoint: target.endpoint}
+	if previous, ok := registry.GetModelDiscoveryStatus(auth.ID); ok {
+		status.SucceededAt = previous.SucceededAt
+		status.Upstream = previous.Upstream
+		status.Models = previous.Models
+	}
+
+	upstream, errFetch := s.fetchUpstreamModelCatalog(ctx, target)
+	finished := time.Now()
+	status.FetchedAt = finished
+	if errFetch != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		retry := interval
+		if retry > modelDiscoveryRetryInterval {
+			retry = modelDiscoveryRetryInterval
+		}
+		status.Error = errFetch.Error()
+		status.NextFetchAt = now.Add(retry)
+		registry.SetModelDiscoveryStatus(auth.ID, status)
+		s.modelDiscovery.mu.Lock()
+		entry := s.modelDiscovery.entries[auth.ID]
+		dropped := false
+		if entry == nil || entry.settings != target.settings() {
+			// A set built with other settings is no longer registered; drop it.
+			dropped = entry != nil && len(entry.models) > 0
+			entry = &discoveredModelSet{settings: target.settings()}
+			s.setDiscoveredModelSetLocked(auth.ID, entry)
+		}
+		entry.nextFetch = status.NextFetchAt
+		s.modelDiscovery.mu.Unlock()
+		if dropped {
+			s.coreManager.SetDiscoveredModelAliases(auth.ID, nil)
+		}
+		log.Warnf("model discovery for %s failed: %v", auth.ID, errFetch)
+		return
+	}
+
+	models := discoveredModelEntries(upstream, target)
+	status.SucceededAt = finished
+	status.NextFetchAt = now.Add(interval)
+	status.Upstream = len(upstream)
+	status.Models = len(models)
+	registry.SetModelDiscoveryStatus(auth.ID, status)
+
+	s.modelDiscovery.mu.Lock()
+	var previous []config.OpenAICompatibilityModel
+	settingsChanged := true
+	if entry := s.modelDiscovery.entries[auth.ID]; entry != nil {
+		previous = entry.models
+		settingsChanged = entry.settings != target.settings()
+	}
+	s.setDiscoveredModelSetLocked(auth.ID, &discoveredModelSet{
+		settings:  target.settings(),
+		models:    models,
+		nextFetch: status.NextFetchAt,
+	})
+	s.modelDiscovery.mu.Unlock()
+
+	changed, summary := diff.DiffDiscoveredModels(previous, models)
+	if changed {
+		log.Infof("model discovery for %s: %s", auth.ID, summary)
+	} else if !settingsChanged {
+		return
+	}
+	s.coreManager.SetDiscoveredModelAliases(auth.ID, discoveredModelAliases(models))
+	s.refreshModelRegistrationForAuth(auth)
+}
+
+func (s *Service) setDiscoveredModelSetLocked(authID string, entry *discoveredModelSet) {
+	if s.modelDiscovery.entries == nil {
+		s.modelDiscovery.entries = make(map[string]*discoveredModelSet)
+	}
+	s.modelDiscovery.entries[authID] = entry
+}
+
+// forgetStaleDiscoveredModels drops the sets of credentials that were removed or had
+// discovery turned off, and re-registers those that still exist without them.
+func (s *Service) forgetStaleDiscoveredModels(active map[string]struct{}) {
+	s.modelDiscovery.mu.Lock()
+	var stale []string
+	for authID, entry := range s.modelDiscovery.entries {
+		if _, ok := active[authID]; ok {
+			continue
+		}
+		delete(s.modelDiscovery.entries, authID)
+		registry.ClearModelDiscoveryStatus(authID)
+		if len(entry.models) > 0 {
+			stale = append(stale, authID)
+		}
+	}
+	s.modelDiscovery.mu.Unlock()
+
+	for _, authID := range stale {
+		s.coreManager.SetDiscoveredModelAliases(authID, nil)
+		if auth, ok := s.coreManager.GetByID(authID); ok && auth != nil {
+			s.refreshModelRegistrationForAuth(auth)
+		}
+	}
+}
+
+// appendDiscoveredModels adds the discovered models of a to models. Configured models
+// win when both use the same ID.
+func (s *Service) appendDiscoveredModels(a *coreauth.Auth, models []*ModelInfo) []*ModelInfo {
+	discovered := s.discoveredModelsForAuth(a)
+	if len(discovered) == 0 {
+		return models
+	}
+	seen := make(map[string]struct{}, len(models))
+	for _, model := range models {
+		if model != nil {
+			seen[strings.ToLower(strings.TrimSpace(model.ID))] = struct{}{}
+		}
+	}
+	out := append(make([]*ModelInfo, 0, len(models)+len(discovered)), models...)
+	for _, model := range discovered {
+		key := strings.ToLower(model.ID)
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, model)
+	}
+	return out
+}
+
+// discoveredModelsForAuth builds model infos for the discovered set of a, provided the
+// set was built with the credential's current discover-models settings.
+func (s *Service) discoveredModelsForAuth(a *coreauth.Auth) []*ModelInfo {
+	if s == nil || a == nil || a.ID == "" {
+		return nil
+	}
+	s.modelDiscovery.mu.Lock()
+	entry := s.modelDiscovery.entries[a.ID]
+	s.modelDiscovery.mu.Unlock()
+	if entry == nil || len(entry.models) == 0 {
+		return nil
+	}
+	target, ok := s.modelDiscoveryTargetForAuth(a)
+	if !ok || entry.settings != target.settings() {
+		return nil
+	}
+
+	var models []*ModelInfo
+	switch target.kind {
+	case "claude":
+		models = buildConfigModels(entry.models, "anthropic", "claude")
+	case "codex":
+		models = buildConfigModels(entry.models, "openai", "openai")
+	case "xai":
+		models = buildConfigModels(entry.models, "xai", "xai")
+	default:
+		models = buildOpenAICompatibilityConfigModels(&config.OpenAICompatibility{Name: target.provider, Models: entry.models})
+	}
+	for _, model := range models {
+		model.Discovered = true
+	}
+	return models
+}
+
+// modelDiscoveryTargetForAuth resolves the discover-models settings of a. Claude, Codex
+// and xAI API keys are only eligible with a custom base URL.
+func (s *Service) modelDiscoveryTargetForAuth(a *coreauth.Auth) (modelDiscoveryTarget, bool) {
+	if s == nil || s.cfg == nil || a == nil || a.Disabled {
+		return modelDiscoveryTarget{}, false
+	}
+	target := modelDiscoveryTarget{proxyURL: strings.TrimSpace(a.ProxyURL)}
+	if target.proxyURL == "" {
+		target.proxyURL = strings.TrimSpace(s.cfg.ProxyURL)
+	}
+	if a.Attributes != nil {
+		target.apiKey = strings.TrimSpace(a.Attributes["api_key"])
+	}
+	baseURL, fallbackPath := "", config.DefaultDiscoverModelsPath
+
+	if _, compatName, ok := openAICompatInfoFromAuth(a); ok {
+		compat := configEntryForAuthIndex(a, s.cfg.OpenAICompatibility)
+		if compat == nil || !strings.EqualFold(compat.Name, compatName) {
+			compat = nil
+			for i := range s.cfg.OpenAICompatibility {
+				if strings.EqualFold(s.cfg.OpenAICompatibility[i].Name, compatName) {
+					compat = &s.cfg.OpenAICompatibility[i]
+					break
+				}
+			}
+		}
+		if compat == nil || compat.Disabled {
+			return modelDiscoveryTarget{}, false
+		}
+		target.kind = "openai-compatibility"
+		target.provider = compat.Name
+		target.discover = compat.DiscoverModels
+		target.headers = compat.Headers
+		baseURL = compat.BaseURL
+	} else {
+		if a.AuthKind() != coreauth.AuthKindAPIKey {
+			return modelDiscoveryTarget{}, false
+		}
+		switch provider := strings.ToLower(strings.TrimSpace(a.Provider)); provider {
+		case "claude":
+			entry := s.resolveConfigClaudeKey(a)
+			if entry == nil {
+				return modelDiscoveryTarget{}, false
+			}
+			target.discover = entry.DiscoverModels
+			target.headers = entry.Headers
+			baseURL = entry.BaseURL
+			fallbackPath = claudeModelDiscoveryPath
+		case "codex", "xai":
+			var entry *config.CodexKey
+			if provider == "codex" {
+				entry = s.resolveConfigCodexKey(a)
+			} else {
+				entry = s.resolveConfigXAIKey(a)
+			}
+			if entry == nil {
+				return modelDiscoveryTarget{}, false
+			}
+			target.discover = entry.DiscoverModels
+			target.headers = entry.Headers
+			baseURL = entry.BaseURL
+		default:
+			return modelDiscoveryTarget{}, false
+		}
+		target.kind = strings.ToLower(strings.TrimSpace(a.Provider))
+		target.provider = target.kind
+	}
+
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if !target.discover.Enabled() || baseURL == "" {
+		return modelDiscoveryTarget{}, false
+	}
+	target.endpoint = baseURL + target.discover.EffectivePath(fallbackPath)
+	return target, true
+}
+
+// fetchUpstreamModelCatalog lists the models of target through the credential's proxy.
+func (s *Service) fetchUpstreamModelCatalog(ctx context.Context, target modelDiscoveryTarget) ([]upstreamCatalogModel, error) {
+	ctx, cancel := context.WithTimeout(ctx, modelDiscoveryTimeout)
+	defer cancel()
+
+	client := &http.Client{}
+	if target.proxyURL != "" {
+		transport, _, errProxy := proxyutil.BuildHTTPTransport(target.proxyURL)
+		if errProxy != nil {
+			return nil, fmt.Errorf("build proxy transport: %w", errProxy)
+		}
+		if transport != nil {
+			client.Transport = transport
+		}
+	}
+
+	var models []upstreamCatalogModel
+	afterID := ""
+	for page := 0; page < modelDiscoveryMaxPages; page++ {
+		endpoint := target.endpoint
+		if target.kind == "claude" {
+			endpoint = withModelDiscoveryQuery(endpoint, afterID)
+		}
+		body, errGet := getUpstreamModelCatalogPage(ctx, client, endpoint, target)
+		if errGet != nil {
+			return nil, errGet
+		}
+		pageModels, errParse := parseUpstreamModelCatalog(body)
+		if errParse != nil {
+			return nil, errParse
+		}
+		models = append(models, pageModels...)
+		root := gjson.ParseBytes(body)
+		afterID = strings.TrimSpace(root.Get("last_id").String())
+		if target.kind != "claude" || !root.Get("has_more").Bool() || afterID == "" {
+			break
+		}
+	}
+	return models, nil
+}
+
+func withModelDiscoveryQuery(endpoint, afterID string) string {
+	parsed, errParse := url.Parse(endpoint)
+	if errParse != nil {
+		return endpoint
+	}
+	query := parsed.Query()
+	query.Set("limit", "1000")
+	if afterID != "" {
+		query.Set("after_id", afterID)
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+func getUpstreamModelCatalogPage(ctx context.Context, client *http.Client, endpoint string, target modelDiscoveryTarget) ([]byte, error) {
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if errReq != nil {
+		return nil, fmt.Errorf("build request: %w", errReq)
+	}
+	req.Header.Set("Accept", "application/json")
+	if target.apiKey != "" {
+		if target.kind == "claude" {
+			req.Header.Set("x-api-key", target.apiKey)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+target.apiKey)
+		}
+	}
+	if target.kind == "claude" {
+		req.Header.Set("anthropic-version", claudeModelDiscoveryVersion)
+	}
+	for name, value := range target.headers {
+		if strings.TrimSpace(name) != "" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	resp, errDo := client.Do(req)
+	if errDo != nil {
+		return nil, errDo
+	}
+	body, errRead := io.ReadAll(io.LimitReader(resp.Body, modelDiscoveryMaxBodyBytes))
+	if errClose := resp.Body.Close(); errClose != nil {
+		log.Debugf("model discovery: close response body: %v", errClose)
+	}
+	if errRead != nil {
+		return nil, fmt.Errorf("read response: %w", errRead)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("GET %s returned status %d", endpoint, resp.StatusCode)
+	}
+	return body, nil
+}
+
+// parseUpstreamModelCatalog reads OpenAI ({"data":[...]}), Anthropic (the same with
+// display_name) and bare-array ({"models":[...]} or [...]) listings.
+func parseUpstreamModelCatalog(body []byte) ([]upstreamCatalogModel, error) {
+	if !gjson.ValidBytes(body) {
+		return nil, fmt.Errorf("response is not valid JSON")
+	}
+	root := gjson.ParseBytes(body)
+	list := root
+	if !list.IsArray() {
+		list = root.Get("data")
+		if !list.IsArray() {
+			list = root.Get("models")
+		}
+	}
+	if !list.IsArray() {
+		return nil, fmt.Errorf("response has no model list")
+	}
+	var models []upstreamCatalogModel
+	list.ForEach(func(_, item gjson.Result) bool {
+		model := upstreamCatalogModel{}
+		if item.Type == gjson.String {
+			model.id = strings.TrimSpace(item.String())
+		} else {
+			model.id = strings.TrimSpace(item.Get("id").String())
+			if model.id == "" {
+				model.id = strings.TrimSpace(item.Get("name").String())
+			}
+			model.displayName = strings.TrimSpace(item.Get("display_name").String())
+		}
+		if model.id != "" {
+			models = append(models, model)
+		}
+		return true
+	})
+	return models, nil
+}
+
+// discoveredModelEntries applies the include/exclude globs and the alias template. The
+// first model claiming an alias wins.
+func discoveredModelEntries(upstream []upstreamCatalogModel, target modelDiscoveryTarget) []config.OpenAICompatibilityModel {
+	out := make([]config.OpenAICompatibilityModel, 0, len(upstream))
+	seen := make(map[string]struct{}, len(upstream))
+	for _, model := range upstream {
+		if !target.discover.Matches(model.id) {
+			continue
+		}
+		alias := target.discover.AliasFor(model.id, target.provider)
+		key := strings.ToLower(alias)
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, config.OpenAICompatibilityModel{Name: model.id, Alias: alias, DisplayName: model.displayName})
+	}
+	return out
+}
+
+// discoveredModelAliases maps the aliases that differ from their upstream ID.
+func discoveredModelAliases(models []config.OpenAICompatibilityModel) map[string]string {
+	aliases := make(map[string]string)
+	for _, model := range models {
+		if !strings.EqualFold(model.Alias, model.Name) {
+			aliases[model.Alias] = model.Name
+		}
+	}
+	return aliases
+}
@@ -0,0 +1,138 @@
+package cliproxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/config"
+	internalregistry "github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
+)
+
+func TestModelDiscoveryRegistersFilteredCompatModels(t *testing.T) {
+	var listing atomic.Value
+	listing.Store(`{"object":"list","data":[{"id":"gpt-x"},{"id":"gpt-y"},{"id":"gpt-y-preview"},{"id":"embed-1"}]}`)
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.URL.Path != "/v1/models" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(listing.Load().(string)))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{OpenAICompatibility: []config.OpenAICompatibility{{
+		Name:          "acme",
+		BaseURL:       server.URL + "/v1",
+		APIKeyEntries: []config.OpenAICompatibilityAPIKey{{APIKey: "sk-test"}},
+		Models:        []config.OpenAICompatibilityModel{{Name: "gpt-x-pinned", Alias: "acme/gpt-x"}},
+		DiscoverModels: &config.DiscoverModelsConfig{
+			Enable:  true,
+			Include: []string{"gpt-*"},
+			Exclude: []string{"*-preview"},
+			Alias:   "{provider}/{id}",
+		},
+	}}}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.SetConfig(cfg)
+	auth := &coreauth.Auth{
+		ID:       "auth-discovery-acme",
+		Provider: "acme",
+		Status:   coreauth.StatusActive,
+		Attributes: map[string]string{
+			"source":       "config:acme[token]",
+			"api_key":      "sk-test",
+			"base_url":     server.URL + "/v1",
+			"compat_name":  "acme",
+			"provider_key": "acme",
+			"config_index": "0",
+		},
+	}
+	if _, errRegister := manager.Register(coreauth.WithSkipPersist(context.Background()), auth); errRegister != nil {
+		t.Fatalf("register auth: %v", errRegister)
+	}
+	service := &Service{cfg: cfg, coreManager: manager}
+	modelRegistry := internalregistry.GetGlobalRegistry()
+	t.Cleanup(func() {
+		modelRegistry.UnregisterClient(auth.ID)
+		internalregistry.ClearModelDiscoveryStatus(auth.ID)
+	})
+
+	now := time.Now()
+	service.runModelDiscovery(context.Background(), now)
+
+	models := registeredModelsByID(modelRegistry.GetModelsForClient(auth.ID))
+	if len(models) != 2 {
+		t.Fatalf("registered models = %v, want acme/gpt-x and acme/gpt-y", models)
+	}
+	if models["acme/gpt-x"] == nil || models["acme/gpt-x"].Discovered {
+		t.Fatalf("configured acme/gpt-x must win over the discovered model: %+v", models["acme/gpt-x"])
+	}
+	if models["acme/gpt-y"] == nil || !models["acme/gpt-y"].Discovered {
+		t.Fatalf("acme/gpt-y not registered as discovered: %+v", models["acme/gpt-y"])
+	}
+	if got := manager.ResolveExecutionModel(auth, "acme/gpt-y"); got != "gpt-y" {
+		t.Fatalf("discovered alias resolves to %q, want gpt-y", got)
+	}
+	if got := manager.ResolveExecutionModel(auth, "acme/gpt-x"); got != "gpt-x-pinned" {
+		t.Fatalf("configured alias resolves to %q, want gpt-x-pinned", got)
+	}
+	status, ok := internalregistry.GetModelDiscoveryStatus(auth.ID)
+	if !ok || status.Upstream != 4 || status.Models != 2 || status.Error != "" {
+		t.Fatalf("discovery status = %+v", status)
+	}
+
+	// A failed refresh keeps the discovered set registered.
+	failing.Store(true)
+	service.runModelDiscovery(context.Background(), now.Add(2*time.Hour))
+	if models = registeredModelsByID(modelRegistry.GetModelsForClient(auth.ID)); models["acme/gpt-y"] == nil {
+		t.Fatalf("discovered model dropped after a failed refresh: %v", models)
+	}
+	if status, _ = internalregistry.GetModelDiscoveryStatus(auth.ID); status.Error == "" {
+		t.Fatal("failed refresh not reported in the discovery status")
+	}
+
+	failing.Store(false)
+	listing.Store(`{"data":[{"id":"gpt-z"}]}`)
+	service.runModelDiscovery(context.Background(), now.Add(4*time.Hour))
+	models = registeredModelsByID(modelRegistry.GetModelsForClient(auth.ID))
+	if models["acme/gpt-y"] != nil || models["acme/gpt-z"] == nil {
+		t.Fatalf("registered models after catalog change = %v", models)
+	}
+}
+
+func TestParseUpstreamModelCatalogFormats(t *testing.T) {
+	for name, body := range map[string]string{
+		"openai":    `{"object":"list","data":[{"id":"a"},{"id":"b"}]}`,
+		"anthropic": `{"data":[{"id":"a","display_name":"A"},{"id":"b"}],"has_more":false}`,
+		"models":    `{"models":[{"name":"a"},{"name":"b"}]}`,
+		"array":     `["a","b"]`,
+	} {
+		models, errParse := parseUpstreamModelCatalog([]byte(body))
+		if errParse != nil || len(models) != 2 || models[0].id != "a" || models[1].id != "b" {
+			t.Errorf("%s: models = %+v, err = %v", name, models, errParse)
+		}
+	}
+	if _, errParse := parseUpstreamModelCatalog([]byte(`{"error":"nope"}`)); errParse == nil {
+		t.Error("listing without models parsed without error")
+	}
+}
+
+func registeredModelsByID(models []*internalregistry.ModelInfo) map[string]*internalregistry.ModelInfo {
+	out := make(map[string]*internalregistry.ModelInfo, len(models))
+	for _, model := range models {
+		if model != nil {
+			out[model.ID] = model
+		}
+	}
+	return out
+}
@@ -9,10 +9,22 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/constant"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/modelconfig"
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/runtime/executor"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v7/sdk/cliproxy/auth"
 	"github.com/router-for-me/CLIProxyAPI/v7/sdk/config"
 )
 
+// RegisterModelsOffline binds the models of auths in the global registry exactly as a
+// running service would, without contacting any upstream. Capabilities that are only
+// learned from live requests are left out and plugin-provided models are not included.
+// It backs the offline config validation command.
+func RegisterModelsOffline(ctx context.Context, cfg *config.Config, auths []*coreauth.Auth) {
+	s := &Service{cfg: cfg, offlineModels: true}
+	for _, a := range auths {
+		s.registerModelsForAuth(ctx, a)
+	}
+}
+
 // registerModelsForAuth (re)binds provider models in the global registry using the core auth ID as client identifier.
 func (s *Service) registerModelsForAuth(ctx context.Context, a *coreauth.Auth) {
 	s.registerModelsForAuthWithCache(ctx, a, nil)
@@ -113,6 +125,25 @@ func (s *Service) registerModelsForAuthWithCache(ctx context.Context, a *coreaut
 				excluded = entry.ExcludedModels
 			}
 		}
+		models = s.appendDiscoveredModels(a, models)
+		models = applyExcludedModels(models, excluded)
+	case "bedrock":
+		// Bedrock serves the dated Claude catalogue unless the credential lists its own
+		// model IDs; undated Claude models have no derivable Bedrock ID.
+		models = bedrockCatalogModels(registry.GetClaudeModels())
+		if entry := s.resolveConfigBedrockKey(a); entry != nil {
+			if len(entry.Models) > 0 {
+				models = buildConfigModels(entry.Models, "anthropic", "claude")
+			}
+			excluded = entry.ExcludedModels
+		}
+		models = applyExcludedModels(models, excluded)
+	case "azure-openai":
+		// Azure serves only what has been deployed, so the catalogue is the deployment map.
+		if entry := s.resolveConfigAzureOpenAIKey(a); entry != nil {
+			models = buildConfigModels(entry.Deployments, "azure", "openai")
+			excluded = entry.ExcludedModels
+		}
 		models = applyExcludedModels(models, excluded)
 	case "codex":
 		if authKind == "apikey" {
@@ -120,6 +151,7 @@ func (s *Service) registerModelsForAuthWithCache(ctx context.Context, a *coreaut
 				models = buildCodexConfigModels(entry)
 				excluded = entry.ExcludedModels
 			}
+			models = s.appendDiscoveredModels(a, models)
 			models = applyExcludedModels(models, excluded)
 			break
 		}
@@ -154,6 +186,7 @@ func (s *Service) registerModelsForAuthWithCache(ctx context.Context, a *coreaut
 				excluded = entry.ExcludedModels
 			}
 		}
+		models = s.appendDiscoveredModels(a, models)
 		models = applyExcludedModels(models, excluded)
 	default:
 		// Handle OpenAI-compatibility providers by name using config
@@ -199,7 +232,7 @@ func (s *Service) registerModelsForAuthWithCache(ctx context.Context, a *coreaut
 					return false
 				}
 				isCompatAuth = true
-				ms := buildOpenAICompatibilityConfigModels(compat)
+				ms := s.appendDiscoveredModels(a, buildOpenAICompatibilityConfigModels(compat))
 				if providerKey == "" {
 					providerKey = "openai-compatibility"
 				}
@@ -224,7 +257,7 @@ func (s *Service) registerModelsForAuthWithCache(ctx context.Context, a *coreaut
 				if providerKey == "" {
 					providerKey = "openai-compatibility"
 				}
-				ms := cached.models
+				ms := s.appendDiscoveredModels(a, cached.models)
 				if len(ms) > 0 {
 					ms = s.appendPluginModels(providerKey, ms)
 					s.registerResolvedModelsForAuth(a, providerKey, applyModelPrefixes(ms, a.Prefix, s.cfg.ForceModelPrefix))
@@ -480,6 +513,71 @@ func (s *Service) resolveConfigVertexCompatKey(auth *coreauth.Auth) *config.Vert
 	return nil
 }
 
+// bedrockCatalogModels keeps the models whose Bedrock model ID can be derived.
+func bedrockCatalogModels(models []*ModelInfo) []*ModelInfo {
+	out := make([]*ModelInfo, 0, len(models))
+	for _, model := range models {
+		if model == nil {
+			continue
+		}
+		if _, ok := executor.BedrockModelID(model.ID); ok {
+			out = append(out, model)
+		}
+	}
+	return out
+}
+
+func (s *Service) resolveConfigBedrockKey(auth *coreauth.Auth) *config.BedrockKey {
+	if auth == nil || s.cfg == nil {
+		return nil
+	}
+	if entry := configEntryForAuthIndex(auth, s.cfg.BedrockKey); entry != nil {
+		return entry
+	}
+	var attrKey, attrRegion string
+	if auth.Attributes != nil {
+		attrKey = strings.TrimSpace(auth.Attributes["api_key"])
+		attrRegion = strings.TrimSpace(auth.Attributes["region"])
+	}
+	if attrKey == "" {
+		return nil
+	}
+	for i := range s.cfg.BedrockKey {
+		entry := &s.cfg.BedrockKey[i]
+		if entry.GetAPIKey() == attrKey && (attrRegion == "" || entry.Region == attrRegion) {
+			return entry
+		}
+	}
+	return nil
+}
+
+func (s *Service) resolveConfigAzureOpenAIKey(auth *coreauth.Auth) *config.AzureOpenAIKey {
+	if auth == nil || s.cfg == nil {
+		return nil
+	}
+	if entry := configEntryForAuthIndex(auth, s.cfg.AzureOpenAIKey); entry != nil {
+		return entry
+	}
+	var attrBase, attrIdentity string
+	if auth.Attributes != nil {
+		attrBase = strings.TrimSpace(auth.Attributes["base_url"])
+		attrIdentity = strings.TrimSpace(auth.Attributes["api_key"])
+		if attrIdentity == "" {
+			attrIdentity = strings.TrimSpace(auth.Attributes["client_id"])
+		}
+	}
+	if attrIdentity == "" {
+		return nil
+	}
+	for i := range s.cfg.AzureOpenAIKey {
+		entry := &s.cfg.AzureOpenAIKey[i]
+		if entry.GetAPIKey() == attrIdentity && strings.EqualFold(entry.Endpoint, attrBase) {
+			return entry
+		}
+	}
+	return nil
+}
+
 func (s *Service) resolveConfigCodexKey(auth *coreauth.Auth) *config.CodexKey {
 	if s == nil || s.cfg == nil {
 		return nil
@@ -674,6 +772,11 @@ type modelCompatEntry interface {
 	GetIsCompat() bool
 }
 
+type modelModalitiesEntry interface {
+	GetInputModalities() []string
+	GetOutputModalities() []string
+}
+
 func buildConfiguredModelInfo(model modelEntry, ownedBy, modelType string, created int64, fallbackDisplayName string, userDefined bool) *ModelInfo {
 	name := strings.TrimSpace(model.GetName())
 	alias := strings.TrimSpace(model.GetAlias())
@@ -708,6 +811,10 @@ func buildConfiguredModelInfo(model modelEntry, ownedBy, modelType string, creat
 	if compatModel, okCompat := any(model).(modelCompatEntry); okCompat {
 		info.IsCompat = compatModel.GetIsCompat()
 	}
+	if modalitiesModel, okModalities := any(model).(modelModalitiesEntry); okModalities {
+		info.SupportedInputModalities = normalizeCompatConfigModalities(modalitiesModel.GetInputModalities())
+		info.SupportedOutputModalities = normalizeCompatConfigModalities(modalitiesModel.GetOutputModalities())
+	}
 	return info
 }
 
@@ -722,13 +829,15 @@ func buildOpenAICompatibilityConfigModels(compat *config.OpenAICompatibility) []
 		modelType := "openai-compatibility"
 		if model.Image {
 			modelType = registry.OpenAIImageModelType
+		} else if model.Embedding {
+			modelType = registry.OpenAIEmbeddingModelType
 		}
 		info := buildConfiguredModelInfo(model, compat.Name, modelType, now, strings.TrimSpace(model.Alias), false)
 		if info == nil {
 			continue
 		}
 		thinkingSupport := model.Thinking
-		if thinkingSupport == nil && !model.Image {
+		if thinkingSupport == nil && !model.Image && !model.Embedding {
 			thinkingSupport = &registry.ThinkingSupport{Levels: []string{"low", "medium", "high"}}
 		}
 		info.Thinking = modelconfig.NormalizeThinkingSupport(thinkingSupport)
@@ -794,14 +903,44 @@ func buildVertexCompatConfigModels(entry *config.VertexCompatKey) []*ModelInfo {
 	if entry == nil {
 		return nil
 	}
-	return buildConfigModels(entry.Models, "google", "vertex")
+	return markGoogleEmbeddingModels(buildConfigModels(entry.Models, "google", "vertex"), entry.Models)
 }
 
 func buildGeminiConfigModels(entry *config.GeminiKey) []*ModelInfo {
 	if entry == nil {
 		return nil
 	}
-	return buildConfigModels(entry.Models, "google", "gemini")
+	return markGoogleEmbeddingModels(buildConfigModels(entry.Models, "google", "gemini"), entry.Models)
+}
+
+// markGoogleEmbeddingModels advertises embedContent support on configured Gemini and
+// Vertex aliases whose upstream model is a text embedding model.
+func markGoogleEmbeddingModels[T modelEntry](models []*ModelInfo, entries []T) []*ModelInfo {
+	embeddingAliases := make(map[string]struct{})
+	for i := range entries {
+		name := strings.TrimSpace(entries[i].GetName())
+		if !registry.IsEmbeddingModelName(name) {
+			continue
+		}
+		alias := strings.TrimSpace(entries[i].GetAlias())
+		if alias == "" {
+			alias = name
+		}
+		embeddingAliases[strings.ToLower(alias)] = struct{}{}
+	}
+	if len(embeddingAliases) == 0 {
+		return models
+	}
+	for _, info := range models {
+		if info == nil {
+			continue
+		}
+		if _, ok := embeddingAliases[strings.ToLower(info.ID)]; ok {
+			info.SupportedGenerationMethods = registry.EmbeddingGenerationMethods()
+			info.Thinking = nil
+		}
+	}
+	return models
 }
 
 func buildClaudeConfigModels(entry *config.ClaudeKey) []*ModelInfo {
@@ -0,0 +1,26 @@
+package cliproxy
+
+import (
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/responsecache"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v7/sdk/auth"
+)
+
+// configureResponseCacheStore offers the token store's cache backend to the response
+// cache. It is only used when response-cache.backend is "postgres".
+func (s *Service) configureResponseCacheStore() {
+	if s == nil || s.cfg == nil || s.cfg.Home.Enabled {
+		return
+	}
+	provider, ok := sdkAuth.GetTokenStore().(responsecache.StoreProvider)
+	if !ok {
+		return
+	}
+	if store := provider.ResponseCacheStore(); store != nil {
+		responsecache.Default().SetExternalStore(store)
+	}
+}
+
+// stopResponseCache releases response cache backend connections before shutdown.
+func stopResponseCache() {
+	responsecache.Default().Stop()
+}
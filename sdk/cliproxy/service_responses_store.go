@@ -0,0 +1,21 @@
+package cliproxy
+
+import (
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/responsestore"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v7/sdk/auth"
+)
+
+// configureResponsesStore offers the token store's responses backend to the responses
+// store. It is only used when responses-store.backend is "postgres".
+func (s *Service) configureResponsesStore() {
+	if s == nil || s.cfg == nil || s.cfg.Home.Enabled {
+		return
+	}
+	provider, ok := sdkAuth.GetTokenStore().(responsestore.StoreProvider)
+	if !ok {
+		return
+	}
+	if store := provider.ResponsesStore(); store != nil {
+		responsestore.Default().SetExternalStore(store)
+	}
+}
@@ -0,0 +1,28 @@
+package cliproxy
+
+import (
+	"context"
+
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/usageledger"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v7/sdk/auth"
+)
+
+// configureUsageLedgerStore offers the token store's ledger backend to the usage
+// ledger. It is only used when usage-ledger.backend is "postgres".
+func (s *Service) configureUsageLedgerStore() {
+	if s == nil || s.cfg == nil || s.cfg.Home.Enabled {
+		return
+	}
+	provider, ok := sdkAuth.GetTokenStore().(usageledger.StoreProvider)
+	if !ok {
+		return
+	}
+	if store := provider.UsageLedgerStore(); store != nil {
+		usageledger.Default().SetExternalStore(store)
+	}
+}
+
+// stopUsageLedger persists buffered ledger records before shutdown.
+func stopUsageLedger(ctx context.Context) {
+	usageledger.Default().Stop(ctx)
+}
@@ -0,0 +1,64 @@
+package usage
+
+import (
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Cost sources reported on Cost.Source.
+const (
+	// CostSourceCatalog marks costs priced from the models catalog.
+	CostSourceCatalog = "catalog"
+	// CostSourceConfig marks costs priced from a model-pricing override.
+	CostSourceConfig = "config"
+	// CostSourceResponseCache marks records served by the response cache, which cost nothing.
+	CostSourceResponseCache = "response-cache"
+)
+
+// Cost is the spend of one usage record in USD, split by token bucket.
+type Cost struct {
+	Input       float64 `json:"input"`
+	CachedInput float64 `json:"cached_input"`
+	CacheWrite  float64 `json:"cache_write"`
+	Output      float64 `json:"output"`
+	Reasoning   float64 `json:"reasoning"`
+	Total       float64 `json:"total"`
+	// Source names where the prices came from.
+	Source string `json:"source"`
+}
+
+// CostCalculator prices a usage record. It returns nil when no price is known.
+type CostCalculator func(record Record) *Cost
+
+var costCalculator atomic.Pointer[CostCalculator]
+
+// SetCostCalculator installs the calculator used to price records that are published
+// without a cost. Passing nil disables cost accounting.
+func SetCostCalculator(calculator CostCalculator) {
+	if calculator == nil {
+		costCalculator.Store(nil)
+		return
+	}
+	costCalculator.Store(&calculator)
+}
+
+// withCost fills Record.Cost from the installed calculator unless the publisher set it.
+// A panicking calculator leaves the record unpriced.
+func withCost(record Record) (out Record) {
+	if record.Cost != nil {
+		return record
+	}
+	calculator := costCalculator.Load()
+	if calculator == nil || *calculator == nil {
+		return record
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("usage: cost calculator panic recovered: %v", r)
+			out = record
+		}
+	}()
+	record.Cost = (*calculator)(record)
+	return record
+}
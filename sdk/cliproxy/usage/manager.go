@@ -44,13 +44,25 @@ type Record struct {
 	// Generate reports whether the client requested actual generation.
 	// nil or true means generation is enabled; only an explicit false disables generation.
 	// Use GenerateFlag to set the value and GenerateEnabled to read it with the default.
-	Generate    *bool
-	RequestedAt time.Time
-	Latency     time.Duration
-	TTFT        time.Duration
-	Failed      bool
-	Fail        Failure
-	Detail      Detail
+	Generate *bool
+	// Cached reports that the response was served from the response cache without an
+	// upstream call. Token budgets ignore cached records.
+	Cached bool
+	// FallbackHop is the 1-based hop of the model-fallbacks chain that served the
+	// request. Zero when no fallback chain applied.
+	FallbackHop int
+	// HedgeAttempt is 1 for the primary attempt of a hedged stream and 2 for the
+	// hedge. Zero when the request was not hedged.
+	HedgeAttempt int
+	RequestedAt  time.Time
+	Latency      time.Duration
+	TTFT         time.Duration
+	Failed       bool
+	Fail         Failure
+	Detail       Detail
+	// Cost is the priced spend of the record. The manager fills it from the
+	// installed CostCalculator before delivery; nil means no price is known.
+	Cost *Cost
 	// ResponseHeaders stores a snapshot of upstream response headers for usage sinks.
 	ResponseHeaders http.Header
 }
@@ -70,6 +82,8 @@ type Detail struct {
 	CacheReadTokens     int64
 	CacheCreationTokens int64
 	TotalTokens         int64
+	InputAudioTokens    int64
+	OutputAudioTokens   int64
 	TokenBreakdown      TokenBreakdown
 	ResponseServiceTier string
 }
@@ -78,6 +92,8 @@ type requestedModelAliasContextKey struct{}
 type reasoningEffortContextKey struct{}
 type serviceTierContextKey struct{}
 type generateContextKey struct{}
+type fallbackHopContextKey struct{}
+type hedgeAttemptContextKey struct{}
 
 // WithRequestedModelAlias stores the client-requested model name for usage sinks.
 func WithRequestedModelAlias(ctx context.Context, alias string) context.Context {
@@ -195,6 +211,46 @@ func GenerateFromContext(ctx context.Context) bool {
 	}
 }
 
+// WithFallbackHop stores the model-fallbacks hop serving the request for usage sinks.
+func WithFallbackHop(ctx context.Context, hop int) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if hop <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, fallbackHopContextKey{}, hop)
+}
+
+// FallbackHopFromContext returns the model-fallbacks hop stored in ctx, or zero.
+func FallbackHopFromContext(ctx context.Context) int {
+	if ctx == nil {
+		return 0
+	}
+	hop, _ := ctx.Value(fallbackHopContextKey{}).(int)
+	return hop
+}
+
+// WithHedgeAttempt stores the stream-hedging attempt number for usage sinks.
+func WithHedgeAttempt(ctx context.Context, attempt int) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if attempt <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, hedgeAttemptContextKey{}, attempt)
+}
+
+// HedgeAttemptFromContext returns the stream-hedging attempt stored in ctx, or zero.
+func HedgeAttemptFromContext(ctx context.Context) int {
+	if ctx == nil {
+		return 0
+	}
+	attempt, _ := ctx.Value(hedgeAttemptContextKey{}).(int)
+	return attempt
+}
+
 // GenerateFlag returns a pointer suitable for Record.Generate.
 func GenerateFlag(generate bool) *bool {
 	return &generate
@@ -350,11 +406,12 @@ func (m *Manager) dispatch(item queueItem) {
 	if len(plugins) == 0 {
 		return
 	}
+	record := withCost(item.record)
 	for _, plugin := range plugins {
 		if plugin == nil {
 			continue
 		}
-		safeInvoke(plugin, item.ctx, item.record)
+		safeInvoke(plugin, item.ctx, record)
 	}
 }
 
@@ -11,6 +11,8 @@ type SDKConfig = internalconfig.SDKConfig
 type Config = internalconfig.Config
 
 type StreamingConfig = internalconfig.StreamingConfig
+type APIKeyLimit = internalconfig.APIKeyLimit
+type ClientAPIKey = internalconfig.ClientAPIKey
 type ClaudeCodeConfig = internalconfig.ClaudeCodeConfig
 type TLSConfig = internalconfig.TLSConfig
 type RemoteManagement = internalconfig.RemoteManagement
@@ -25,6 +27,9 @@ type CodexKey = internalconfig.CodexKey
 type XAIKey = internalconfig.XAIKey
 type XAIModel = internalconfig.XAIModel
 type ClaudeKey = internalconfig.ClaudeKey
+type BedrockKey = internalconfig.BedrockKey
+type AzureOpenAIKey = internalconfig.AzureOpenAIKey
+type AzureOpenAIDeployment = internalconfig.AzureOpenAIDeployment
 type VertexCompatKey = internalconfig.VertexCompatKey
 type VertexCompatModel = internalconfig.VertexCompatModel
 type OpenAICompatibility = internalconfig.OpenAICompatibility
@@ -56,3 +61,11 @@ func SaveConfigPreserveCommentsUpdateNestedScalar(configFile string, path []stri
 func NormalizeCommentIndentation(data []byte) []byte {
 	return internalconfig.NormalizeCommentIndentation(data)
 }
+
+func ClientAPIKeyValues(entries []ClientAPIKey) []string {
+	return internalconfig.ClientAPIKeyValues(entries)
+}
+
+func ClientAPIKeysFromStrings(keys []string) []ClientAPIKey {
+	return internalconfig.ClientAPIKeysFromStrings(keys)
+}
@@ -1353,6 +1353,8 @@ type UsageRecord struct {
 	Failure UsageFailure
 	// Detail contains token usage counters.
 	Detail UsageDetail
+	// Cost contains the priced spend of the request. It is nil when the model has no known price.
+	Cost *UsageCost
 	// ResponseHeaders contains selected upstream response headers.
 	ResponseHeaders http.Header
 }
@@ -1381,4 +1383,26 @@ type UsageDetail struct {
 	CacheCreationTokens int64
 	// TotalTokens is the total token count.
 	TotalTokens int64
+	// InputAudioTokens is the audio portion of InputTokens.
+	InputAudioTokens int64
+	// OutputAudioTokens is the audio portion of OutputTokens.
+	OutputAudioTokens int64
+}
+
+// UsageCost contains the spend of a request in USD, split by token bucket.
+type UsageCost struct {
+	// Input is the cost of uncached input tokens.
+	Input float64
+	// CachedInput is the cost of input tokens read from the prompt cache.
+	CachedInput float64
+	// CacheWrite is the cost of input tokens written to the prompt cache.
+	CacheWrite float64
+	// Output is the cost of non-reasoning output tokens.
+	Output float64
+	// Reasoning is the cost of reasoning tokens.
+	Reasoning float64
+	// Total is the sum of every bucket.
+	Total float64
+	// Source names where the prices came from: "catalog", "config" or "response-cache".
+	Source string
 }
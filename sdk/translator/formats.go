@@ -2,11 +2,13 @@ package translator
 
 // Common format identifiers exposed for SDK users.
 const (
-	FormatOpenAI         Format = "openai"
-	FormatOpenAIResponse Format = "openai-response"
-	FormatClaude         Format = "claude"
-	FormatGemini         Format = "gemini"
-	FormatCodex          Format = "codex"
-	FormatAntigravity    Format = "antigravity"
-	FormatInteractions   Format = "interactions"
+	FormatOpenAI          Format = "openai"
+	FormatOpenAIResponse  Format = "openai-response"
+	FormatClaude          Format = "claude"
+	FormatGemini          Format = "gemini"
+	FormatCodex           Format = "codex"
+	FormatAntigravity     Format = "antigravity"
+	FormatInteractions    Format = "interactions"
+	FormatOllama          Format = "ollama"
+	FormatBedrockConverse Format = "bedrock-converse"
 )
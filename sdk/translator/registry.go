@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/router-for-me/CLIProxyAPI/v7/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v7/internal/tracing"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -291,6 +292,12 @@ func TranslateStream(ctx context.Context, from, to Format, model string, origina
 
 // TranslateNonStream is a helper on the default registry.
 func TranslateNonStream(ctx context.Context, from, to Format, model string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []byte {
+	ctx, span := tracing.Start(ctx, "translate.response",
+		tracing.AttrSourceFormat.String(from.String()),
+		tracing.AttrTargetFormat.String(to.String()),
+		tracing.AttrModel.String(model),
+	)
+	defer span.End()
 	return defaultRegistry.TranslateNonStream(ctx, from, to, model, originalRequestRawJSON, requestRawJSON, rawJSON, param)
 }
 